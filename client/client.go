@@ -0,0 +1,218 @@
+// Package client is a Go client for the inventory gRPC API.
+//
+// It wraps the Inventory gRPC service with idiomatic Go methods and types, so services consuming it
+// don't need to deal with protobuf messages. Calls without a deadline get a default timeout,
+// and calls failing with codes.Unavailable are retried with exponential backoff.
+//
+// Calls are made through client interceptors providing resilient defaults:
+//
+//   - Retries are limited by a retry budget shared by the calls of the Client, so a struggling server
+//     isn't overloaded with retries.
+//   - Methods can hedge calls, sending another attempt if the previous ones haven't responded after a delay,
+//     which cuts the tail latency of idempotent calls. Hedging is configured per method with WithMethodConfig.
+//   - A circuit breaker fails calls fast with ErrCircuitOpen after consecutive failures, until the server
+//     has had time to recover.
+//
+// Errors returned by the server are gRPC status errors, so status.Code(err) returns their code,
+// such as codes.InvalidArgument for invalid parameters.
+//
+// Example:
+//
+//	c, err := client.New("localhost:8082")
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	product, err := c.GetProduct(ctx, "product-id")
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Default settings of the Client.
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultMaxAttempts = 3
+	DefaultBackoff     = 100 * time.Millisecond
+	DefaultMaxBackoff  = 2 * time.Second
+
+	DefaultRetryBudgetTokens = 10
+	DefaultRetryBudgetRatio  = 0.1
+
+	DefaultBreakerFailures = 5
+	DefaultBreakerCooldown = 5 * time.Second
+)
+
+// Client of the inventory gRPC API.
+type Client struct {
+	conn      *grpc.ClientConn
+	inventory apipb.InventoryClient
+
+	dialOptions []grpc.DialOption
+	timeout     time.Duration
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	methods     map[string]MethodConfig
+
+	budget  *retryBudget
+	breaker *circuitBreaker
+
+	sleep func(ctx context.Context, d time.Duration) error
+	now   func() time.Time
+}
+
+var _ API = (*Client)(nil) // Check if methods expected by API are implemented correctly.
+
+// Option for configuring the Client.
+type Option func(*Client)
+
+// WithDialOptions sets options for connecting to the server, such as transport credentials.
+// Connections are insecure (plaintext) unless credentials are set.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *Client) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
+}
+
+// WithTimeout sets the timeout of calls made with a context without a deadline (default: DefaultTimeout).
+// The timeout covers the retries of a call too. Zero disables it.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithRetries sets the maximum number of attempts of a call failing with codes.Unavailable (default: DefaultMaxAttempts),
+// and the backoff before the first retry, which doubles for each retry up to maxBackoff.
+// One attempt disables retries.
+func WithRetries(maxAttempts int, backoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = max(maxAttempts, 1)
+		c.backoff = backoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithMethodConfig sets the retry and hedging policies of a method, such as apipb.Inventory_GetProduct_FullMethodName,
+// rather than the ones set by WithRetries.
+// Methods that aren't idempotent, such as CreateProductReview, are never retried or hedged.
+func WithMethodConfig(method string, config MethodConfig) Option {
+	return func(c *Client) {
+		config.MaxAttempts = max(config.MaxAttempts, 1)
+		c.methods[method] = config
+	}
+}
+
+// WithRetryBudget limits the retries and hedged attempts of the calls (default: DefaultRetryBudgetTokens and DefaultRetryBudgetRatio).
+// The budget has maxTokens, and each failed attempt takes a token, while each successful one gives back ratio tokens.
+// Calls aren't retried or hedged while half of the tokens or less are left.
+// Zero maxTokens disables the budget.
+func WithRetryBudget(maxTokens int, ratio float64) Option {
+	return func(c *Client) {
+		c.budget = newRetryBudget(maxTokens, ratio)
+	}
+}
+
+// WithCircuitBreaker opens the circuit after a number of consecutive failed attempts, failing calls with ErrCircuitOpen
+// for the cooldown, and then lets a single attempt through to check if the server recovered, closing it if it succeeds
+// (default: DefaultBreakerFailures and DefaultBreakerCooldown).
+// Zero failures disables the circuit breaker.
+func WithCircuitBreaker(failures int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.breaker = newCircuitBreaker(failures, cooldown)
+	}
+}
+
+// New creates a Client of the inventory gRPC API served on the target, such as "localhost:8082".
+// Connections are established lazily, and the Client must be closed once it's no longer used.
+func New(target string, opts ...Option) (*Client, error) {
+	c := newClient(opts...)
+	conn, err := grpc.NewClient(target, append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, c.dialOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+	c.inventory = apipb.NewInventoryClient(c.intercept(conn))
+	return c, nil
+}
+
+// NewFromConn creates a Client using an existing connection, which isn't closed by Close.
+// Dial options are ignored.
+func NewFromConn(conn grpc.ClientConnInterface, opts ...Option) *Client {
+	c := newClient(opts...)
+	c.inventory = apipb.NewInventoryClient(c.intercept(conn))
+	return c
+}
+
+func newClient(opts ...Option) *Client {
+	c := &Client{
+		timeout:     DefaultTimeout,
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     DefaultBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		methods:     map[string]MethodConfig{},
+		budget:      newRetryBudget(DefaultRetryBudgetTokens, DefaultRetryBudgetRatio),
+		breaker:     newCircuitBreaker(DefaultBreakerFailures, DefaultBreakerCooldown),
+		sleep:       sleep,
+		now:         time.Now,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Close the connection of the Client, if it was created by New.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// call the API, setting the default timeout.
+// Retries, hedging, and circuit breaking are left to the interceptors of the connection.
+func call[T any](ctx context.Context, c *Client, fn func(ctx context.Context) (T, error)) (T, error) {
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+// sleep for the duration, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// notFound returns nil if the error is a codes.NotFound status error, and the error otherwise.
+// Getters return a nil value if what they get isn't found, as inventory.API does.
+func notFound(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+// errNoProduct is returned if the server responds without a product.
+var errNoProduct = errors.New("response has no product")
@@ -0,0 +1,379 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeInventory server, stalling the first calls until they're canceled, and failing the next ones with codes.Unavailable.
+type fakeInventory struct {
+	apipb.UnimplementedInventoryServer
+
+	stalled     int32
+	unavailable int32
+	calls       atomic.Int32
+	deadline    atomic.Bool
+}
+
+func (f *fakeInventory) fail(ctx context.Context) error {
+	_, ok := ctx.Deadline()
+	f.deadline.Store(ok)
+	n := f.calls.Add(1)
+	if n <= f.stalled {
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if n <= f.stalled+f.unavailable {
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	return nil
+}
+
+func (f *fakeInventory) GetProduct(ctx context.Context, req *apipb.GetProductRequest) (*apipb.GetProductResponse, error) {
+	if err := f.fail(ctx); err != nil {
+		return nil, err
+	}
+	if req.Id != "product" {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	return &apipb.GetProductResponse{
+		Id:         req.Id,
+		Name:       "Product",
+		Price:      100,
+		Locale:     req.Locale,
+		CreatedAt:  "2024-06-01 12:00:00.5 +0000 UTC",
+		ModifiedAt: "2024-06-02 12:00:00 +0000 UTC",
+		PriceBreakdown: &apipb.PriceBreakdown{
+			Net:     100,
+			Tax:     20,
+			Gross:   120,
+			TaxRate: 2000,
+		},
+	}, nil
+}
+
+func (f *fakeInventory) CreateProduct(ctx context.Context, req *apipb.CreateProductRequest) (*apipb.CreateProductResponse, error) {
+	if err := f.fail(ctx); err != nil {
+		return nil, err
+	}
+	st, err := status.New(codes.AlreadyExists, "product already exists").WithDetails(&apipb.Product{
+		Id:   req.Id,
+		Name: "Existing",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nil, st.Err()
+}
+
+func (f *fakeInventory) CreateProductReview(ctx context.Context, req *apipb.CreateProductReviewRequest) (*apipb.CreateProductReviewResponse, error) {
+	if err := f.fail(ctx); err != nil {
+		return nil, err
+	}
+	return &apipb.CreateProductReviewResponse{Id: "review"}, nil
+}
+
+// newTestClient creates a Client of an in-memory server.
+func newTestClient(t *testing.T, srv apipb.InventoryServer, opts ...Option) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	apipb.RegisterInventoryServer(s, srv)
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			t.Errorf("cannot serve: %v", err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	c, err := New("passthrough:///bufnet", append([]Option{
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		})),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if err := c.Close(); err != nil {
+			t.Errorf("Client.Close() error = %v", err)
+		}
+	})
+	// Don't wait between retries.
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		return ctx.Err()
+	}
+	return c
+}
+
+func TestGetProduct(t *testing.T) {
+	t.Parallel()
+	c := newTestClient(t, &fakeInventory{})
+	got, err := c.GetLocalizedProduct(context.Background(), "product", "pt-BR")
+	if err != nil {
+		t.Fatalf("Client.GetLocalizedProduct() error = %v", err)
+	}
+	want := &Product{
+		ID:         "product",
+		Name:       "Product",
+		Price:      100,
+		Locale:     "pt-BR",
+		CreatedAt:  time.Date(2024, 6, 1, 12, 0, 0, 5e8, time.UTC),
+		ModifiedAt: time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC),
+		PriceBreakdown: &PriceBreakdown{
+			Net:     100,
+			Tax:     20,
+			Gross:   120,
+			TaxRate: 2000,
+		},
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("Client.GetLocalizedProduct() = %v", cmp.Diff(want, got))
+	}
+
+	// Not found products are nil.
+	if got, err := c.GetProduct(context.Background(), "unknown"); err != nil || got != nil {
+		t.Errorf("Client.GetProduct() = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestCreateProductExisting(t *testing.T) {
+	t.Parallel()
+	c := newTestClient(t, &fakeInventory{})
+	got, err := c.CreateProduct(context.Background(), CreateProductParams{
+		ID:   "product",
+		Name: "Product",
+	})
+	if err != nil {
+		t.Fatalf("Client.CreateProduct() error = %v", err)
+	}
+	want := &CreateProductResult{
+		Product: &Product{
+			ID:   "product",
+			Name: "Existing",
+		},
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("Client.CreateProduct() = %v", cmp.Diff(want, got))
+	}
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		unavailable int32
+		opts        []Option
+		call        func(c *Client) error
+		wantCalls   int32
+		wantCode    codes.Code
+	}{
+		{
+			name:        "recovers",
+			unavailable: 2,
+			call: func(c *Client) error {
+				_, err := c.GetProduct(context.Background(), "product")
+				return err
+			},
+			wantCalls: 3,
+		},
+		{
+			name:        "gives_up",
+			unavailable: 5,
+			call: func(c *Client) error {
+				_, err := c.GetProduct(context.Background(), "product")
+				return err
+			},
+			wantCalls: 3,
+			wantCode:  codes.Unavailable,
+		},
+		{
+			name:        "disabled",
+			unavailable: 1,
+			opts:        []Option{WithRetries(1, 0, 0)},
+			call: func(c *Client) error {
+				_, err := c.GetProduct(context.Background(), "product")
+				return err
+			},
+			wantCalls: 1,
+			wantCode:  codes.Unavailable,
+		},
+		{
+			name: "not_retried_on_other_errors",
+			call: func(c *Client) error {
+				_, err := c.GetProductBySKU(context.Background(), "sku")
+				return err
+			},
+			wantCalls: 0, // Unimplemented on the fake server.
+			wantCode:  codes.Unimplemented,
+		},
+		{
+			name:        "not_idempotent",
+			unavailable: 1,
+			call: func(c *Client) error {
+				_, err := c.CreateProductReview(context.Background(), CreateProductReviewParams{ProductID: "product"})
+				return err
+			},
+			wantCalls: 1,
+			wantCode:  codes.Unavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := &fakeInventory{unavailable: tt.unavailable}
+			c := newTestClient(t, srv, tt.opts...)
+			err := tt.call(c)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("error code = %v, want %v (error: %v)", code, tt.wantCode, err)
+			}
+			if calls := srv.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+	srv := &fakeInventory{}
+	c := newTestClient(t, srv)
+	if _, err := c.GetProduct(context.Background(), "product"); err != nil {
+		t.Fatalf("Client.GetProduct() error = %v", err)
+	}
+	if !srv.deadline.Load() {
+		t.Error("call without a deadline should have the default timeout")
+	}
+
+	srv = &fakeInventory{}
+	c = newTestClient(t, srv, WithTimeout(0))
+	if _, err := c.GetProduct(context.Background(), "product"); err != nil {
+		t.Fatalf("Client.GetProduct() error = %v", err)
+	}
+	if srv.deadline.Load() {
+		t.Error("call without a deadline should have no timeout when it's disabled")
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	t.Parallel()
+	srv := &fakeInventory{unavailable: 100}
+	c := newTestClient(t, srv, WithRetryBudget(4, 0.5), WithCircuitBreaker(0, 0))
+	// The first call is retried once, leaving half of the tokens, so the second one isn't retried.
+	for range 2 {
+		if _, err := c.GetProduct(context.Background(), "product"); status.Code(err) != codes.Unavailable {
+			t.Errorf("Client.GetProduct() error = %v, want codes.Unavailable", err)
+		}
+	}
+	if calls := srv.calls.Load(); calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+}
+
+func TestHedging(t *testing.T) {
+	t.Parallel()
+	srv := &fakeInventory{stalled: 1}
+	c := newTestClient(t, srv, WithMethodConfig(apipb.Inventory_GetProduct_FullMethodName, MethodConfig{
+		MaxAttempts:  2,
+		HedgingDelay: 10 * time.Millisecond,
+	}))
+	got, err := c.GetProduct(context.Background(), "product")
+	if err != nil {
+		t.Fatalf("Client.GetProduct() error = %v", err)
+	}
+	if got == nil || got.ID != "product" {
+		t.Errorf("Client.GetProduct() = %v, want the product of the hedged attempt", got)
+	}
+	if calls := srv.calls.Load(); calls != 2 {
+		t.Errorf("got %d calls, want 2", calls)
+	}
+
+	// Calls that aren't idempotent aren't hedged.
+	srv = &fakeInventory{stalled: 1}
+	c = newTestClient(t, srv, WithTimeout(50*time.Millisecond), WithMethodConfig(apipb.Inventory_CreateProductReview_FullMethodName, MethodConfig{
+		MaxAttempts:  2,
+		HedgingDelay: time.Millisecond,
+	}))
+	if _, err := c.CreateProductReview(context.Background(), CreateProductReviewParams{ProductID: "product"}); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Client.CreateProductReview() error = %v, want codes.DeadlineExceeded", err)
+	}
+	if calls := srv.calls.Load(); calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	srv := &fakeInventory{unavailable: 3}
+	c := newTestClient(t, srv, WithRetries(1, 0, 0), WithCircuitBreaker(2, time.Minute))
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	getProduct := func(wantCode codes.Code, wantCalls int32) {
+		t.Helper()
+		_, err := c.GetProduct(context.Background(), "product")
+		if code := status.Code(err); code != wantCode {
+			t.Errorf("Client.GetProduct() error = %v, want %v", err, wantCode)
+		}
+		if calls := srv.calls.Load(); calls != wantCalls {
+			t.Errorf("got %d calls, want %d", calls, wantCalls)
+		}
+	}
+	getProduct(codes.Unavailable, 1)
+	getProduct(codes.Unavailable, 2)
+
+	// The circuit is open, so the call fails fast.
+	if _, err := c.GetProduct(context.Background(), "product"); err != ErrCircuitOpen {
+		t.Errorf("Client.GetProduct() error = %v, want %v", err, ErrCircuitOpen)
+	}
+	getProduct(codes.Unavailable, 2)
+
+	// After the cooldown, a call probes the server, which is still unavailable, opening the circuit again.
+	now = now.Add(time.Minute)
+	getProduct(codes.Unavailable, 3)
+	getProduct(codes.Unavailable, 3)
+
+	// The server recovered, closing the circuit.
+	now = now.Add(time.Minute)
+	getProduct(codes.OK, 4)
+	getProduct(codes.OK, 5)
+}
+
+func TestCircuitBreakerQuotaFailure(t *testing.T) {
+	t.Parallel()
+	quota, err := status.New(codes.ResourceExhausted, "quota exceeded").WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{Subject: "tenant:acme"}},
+	})
+	if err != nil {
+		t.Fatalf("cannot add error details: %v", err)
+	}
+	b := newCircuitBreaker(2, time.Minute)
+	now := time.Now()
+
+	// A tenant exceeding its quota says nothing about the server.
+	for range 3 {
+		b.record(now, quota.Err())
+	}
+	if !b.allow(now) {
+		t.Error("circuitBreaker.allow() = false after quota failures, want true")
+	}
+
+	// An overloaded server does.
+	for range 2 {
+		b.record(now, status.Error(codes.ResourceExhausted, "overloaded"))
+	}
+	if b.allow(now) {
+		t.Error("circuitBreaker.allow() = true after the server was overloaded, want false")
+	}
+}
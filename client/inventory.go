@@ -0,0 +1,373 @@
+package client
+
+import (
+	"context"
+
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// API of the inventory, implemented by Client.
+// Consumers should depend on it rather than on Client, so they can replace it with a mock in tests.
+type API interface {
+	// SearchProducts by name or SKU prefix.
+	SearchProducts(ctx context.Context, params SearchProductsParams) (*SearchProductsResponse, error)
+
+	// CreateProduct creates a product.
+	// If a product with the same ID already exists, it returns the existing product with Created set to false.
+	CreateProduct(ctx context.Context, params CreateProductParams) (*CreateProductResult, error)
+
+	// UpdateProduct updates a product, returning it.
+	UpdateProduct(ctx context.Context, params UpdateProductParams) (*Product, error)
+
+	// DeleteProduct deletes a product.
+	DeleteProduct(ctx context.Context, params DeleteProductParams) error
+
+	// GetProduct returns a product, or nil if it isn't found.
+	GetProduct(ctx context.Context, id string) (*Product, error)
+
+	// GetLocalizedProduct returns a product with its name and description translated to the locale, such as pt-BR,
+	// falling back to less specific locales, or nil if it isn't found.
+	GetLocalizedProduct(ctx context.Context, id, locale string) (*Product, error)
+
+	// GetProductBySKU returns a product by its SKU, or nil if it isn't found.
+	GetProductBySKU(ctx context.Context, sku string) (*Product, error)
+
+	// QuoteProducts returns the price of quantities of products, with taxes applied.
+	QuoteProducts(ctx context.Context, lines []QuoteLine) (*Quote, error)
+
+	// UpsertProductTranslation creates or replaces the translation of a product to a locale.
+	UpsertProductTranslation(ctx context.Context, t ProductTranslation) error
+
+	// DeleteProductTranslation deletes the translation of a product to a locale.
+	DeleteProductTranslation(ctx context.Context, productID, locale string) error
+
+	// ListTrendingProducts returns a page of the most viewed products, starting from 1.
+	ListTrendingProducts(ctx context.Context, page int) ([]*Product, error)
+
+	// ListRecentProducts returns a page of the most recently added products, starting from 1.
+	ListRecentProducts(ctx context.Context, page int) ([]*Product, error)
+
+	// CreateProductReview creates a review of a product, returning its ID.
+	// It isn't retried, as a retry would create a duplicate review if the first attempt was received.
+	CreateProductReview(ctx context.Context, params CreateProductReviewParams) (string, error)
+
+	// UpdateProductReview updates a review.
+	UpdateProductReview(ctx context.Context, params UpdateProductReviewParams) error
+
+	// DeleteProductReview deletes a review.
+	DeleteProductReview(ctx context.Context, id string) error
+
+	// GetProductReview returns a review, or nil if it isn't found.
+	GetProductReview(ctx context.Context, id string) (*Review, error)
+}
+
+// SearchProducts by name or SKU prefix.
+func (c *Client) SearchProducts(ctx context.Context, params SearchProductsParams) (*SearchProductsResponse, error) {
+	req := &apipb.SearchProductsRequest{
+		QueryString: params.QueryString,
+		SkuPrefix:   params.SKUPrefix,
+		MinPrice:    params.MinPrice,
+		MaxPrice:    params.MaxPrice,
+		Page:        page(params.Page),
+	}
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.SearchProductsResponse, error) {
+		return c.inventory.SearchProducts(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SearchProductsResponse{
+		Items: productsFromProto(resp.Items),
+		Total: int(resp.Total),
+	}, nil
+}
+
+// CreateProduct creates a product.
+// If a product with the same ID already exists, it returns the existing product with Created set to false.
+func (c *Client) CreateProduct(ctx context.Context, params CreateProductParams) (*CreateProductResult, error) {
+	req := &apipb.CreateProductRequest{
+		Id:          params.ID,
+		Name:        params.Name,
+		Description: params.Description,
+		Price:       params.Price,
+		Status:      params.Status,
+		Sku:         params.SKU,
+		Gtin:        params.GTIN,
+		TaxClass:    params.TaxClass,
+	}
+	// Retrying is safe, as a product with the same ID isn't created twice.
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.CreateProductResponse, error) {
+		return c.inventory.CreateProduct(ctx, req)
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		// The existing product is sent as an error detail.
+		for _, d := range status.Convert(err).Details() {
+			if p, ok := d.(*apipb.Product); ok {
+				return &CreateProductResult{
+					Product: productFromProto(p),
+				}, nil
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.Product == nil {
+		return nil, errNoProduct
+	}
+	return &CreateProductResult{
+		Product: productFromProto(resp.Product),
+		Created: true,
+	}, nil
+}
+
+// UpdateProduct updates a product, returning it.
+func (c *Client) UpdateProduct(ctx context.Context, params UpdateProductParams) (*Product, error) {
+	req := &apipb.UpdateProductRequest{
+		Id:          params.ID,
+		Name:        params.Name,
+		Description: params.Description,
+		Price:       params.Price,
+		Status:      params.Status,
+		Sku:         params.SKU,
+		Gtin:        params.GTIN,
+		TaxClass:    params.TaxClass,
+	}
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.UpdateProductResponse, error) {
+		return c.inventory.UpdateProduct(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Product == nil {
+		return nil, errNoProduct
+	}
+	return productFromProto(resp.Product), nil
+}
+
+// DeleteProduct deletes a product.
+func (c *Client) DeleteProduct(ctx context.Context, params DeleteProductParams) error {
+	req := &apipb.DeleteProductRequest{
+		Id:    params.ID,
+		Force: params.Force,
+	}
+	_, err := call(ctx, c, func(ctx context.Context) (*apipb.DeleteProductResponse, error) {
+		return c.inventory.DeleteProduct(ctx, req)
+	})
+	return err
+}
+
+// GetProduct returns a product, or nil if it isn't found.
+func (c *Client) GetProduct(ctx context.Context, id string) (*Product, error) {
+	return c.GetLocalizedProduct(ctx, id, "")
+}
+
+// GetLocalizedProduct returns a product with its name and description translated to the locale, such as pt-BR,
+// falling back to less specific locales, or nil if it isn't found.
+func (c *Client) GetLocalizedProduct(ctx context.Context, id, locale string) (*Product, error) {
+	req := &apipb.GetProductRequest{
+		Id:     id,
+		Locale: locale,
+	}
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.GetProductResponse, error) {
+		return c.inventory.GetProduct(ctx, req)
+	})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return getProductFromProto(resp), nil
+}
+
+// GetProductBySKU returns a product by its SKU, or nil if it isn't found.
+func (c *Client) GetProductBySKU(ctx context.Context, sku string) (*Product, error) {
+	req := &apipb.GetProductBySKURequest{
+		Sku: sku,
+	}
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.GetProductResponse, error) {
+		return c.inventory.GetProductBySKU(ctx, req)
+	})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return getProductFromProto(resp), nil
+}
+
+// QuoteProducts returns the price of quantities of products, with taxes applied.
+func (c *Client) QuoteProducts(ctx context.Context, lines []QuoteLine) (*Quote, error) {
+	req := &apipb.QuoteProductsRequest{
+		Lines: make([]*apipb.QuoteLine, 0, len(lines)),
+	}
+	for _, l := range lines {
+		req.Lines = append(req.Lines, &apipb.QuoteLine{
+			ProductId: l.ProductID,
+			Quantity:  int32(l.Quantity),
+		})
+	}
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.QuoteProductsResponse, error) {
+		return c.inventory.QuoteProducts(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	quote := &Quote{
+		Lines: make([]QuotedLine, 0, len(resp.Lines)),
+		Net:   resp.Net,
+		Tax:   resp.Tax,
+		Gross: resp.Gross,
+	}
+	for _, l := range resp.Lines {
+		quote.Lines = append(quote.Lines, QuotedLine{
+			ProductID: l.ProductId,
+			Quantity:  int(l.Quantity),
+			UnitPrice: l.UnitPrice,
+			Total:     priceBreakdownFromProto(l.Total),
+		})
+	}
+	return quote, nil
+}
+
+// UpsertProductTranslation creates or replaces the translation of a product to a locale.
+func (c *Client) UpsertProductTranslation(ctx context.Context, t ProductTranslation) error {
+	req := &apipb.UpsertProductTranslationRequest{
+		ProductId:   t.ProductID,
+		Locale:      t.Locale,
+		Name:        t.Name,
+		Description: t.Description,
+	}
+	_, err := call(ctx, c, func(ctx context.Context) (*apipb.UpsertProductTranslationResponse, error) {
+		return c.inventory.UpsertProductTranslation(ctx, req)
+	})
+	return err
+}
+
+// DeleteProductTranslation deletes the translation of a product to a locale.
+func (c *Client) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	req := &apipb.DeleteProductTranslationRequest{
+		ProductId: productID,
+		Locale:    locale,
+	}
+	_, err := call(ctx, c, func(ctx context.Context) (*apipb.DeleteProductTranslationResponse, error) {
+		return c.inventory.DeleteProductTranslation(ctx, req)
+	})
+	return err
+}
+
+// ListTrendingProducts returns a page of the most viewed products, starting from 1.
+func (c *Client) ListTrendingProducts(ctx context.Context, p int) ([]*Product, error) {
+	req := &apipb.ListProductsRequest{
+		Page: page(p),
+	}
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.ListProductsResponse, error) {
+		return c.inventory.ListTrendingProducts(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productsFromProto(resp.Items), nil
+}
+
+// ListRecentProducts returns a page of the most recently added products, starting from 1.
+func (c *Client) ListRecentProducts(ctx context.Context, p int) ([]*Product, error) {
+	req := &apipb.ListProductsRequest{
+		Page: page(p),
+	}
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.ListProductsResponse, error) {
+		return c.inventory.ListRecentProducts(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productsFromProto(resp.Items), nil
+}
+
+// CreateProductReview creates a review of a product, returning its ID.
+// It isn't retried, as a retry would create a duplicate review if the first attempt was received.
+func (c *Client) CreateProductReview(ctx context.Context, params CreateProductReviewParams) (string, error) {
+	req := &apipb.CreateProductReviewRequest{
+		ProductId:   params.ProductID,
+		ReviewerId:  params.ReviewerID,
+		Score:       int32(params.Score),
+		Title:       params.Title,
+		Description: params.Description,
+		Language:    params.Language,
+		Attachments: attachmentsProto(params.Attachments),
+	}
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.CreateProductReviewResponse, error) {
+		return c.inventory.CreateProductReview(ctx, req)
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Id, nil
+}
+
+// UpdateProductReview updates a review.
+func (c *Client) UpdateProductReview(ctx context.Context, params UpdateProductReviewParams) error {
+	req := &apipb.UpdateProductReviewRequest{
+		Id:          params.ID,
+		Title:       params.Title,
+		Description: params.Description,
+		Language:    params.Language,
+	}
+	if params.Score != nil {
+		score := int32(*params.Score)
+		req.Score = &score
+	}
+	if params.Attachments != nil {
+		req.Attachments = &apipb.ReviewAttachments{
+			Items: attachmentsProto(*params.Attachments),
+		}
+	}
+	_, err := call(ctx, c, func(ctx context.Context) (*apipb.UpdateProductReviewResponse, error) {
+		return c.inventory.UpdateProductReview(ctx, req)
+	})
+	return err
+}
+
+// DeleteProductReview deletes a review.
+func (c *Client) DeleteProductReview(ctx context.Context, id string) error {
+	req := &apipb.DeleteProductReviewRequest{
+		Id: id,
+	}
+	_, err := call(ctx, c, func(ctx context.Context) (*apipb.DeleteProductReviewResponse, error) {
+		return c.inventory.DeleteProductReview(ctx, req)
+	})
+	return err
+}
+
+// GetProductReview returns a review, or nil if it isn't found.
+func (c *Client) GetProductReview(ctx context.Context, id string) (*Review, error) {
+	req := &apipb.GetProductReviewRequest{
+		Id: id,
+	}
+	resp, err := call(ctx, c, func(ctx context.Context) (*apipb.GetProductReviewResponse, error) {
+		return c.inventory.GetProductReview(ctx, req)
+	})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &Review{
+		ID:          resp.Id,
+		ProductID:   resp.ProductId,
+		ReviewerID:  resp.ReviewerId,
+		Score:       int(resp.Score),
+		Title:       resp.Title,
+		Description: resp.Description,
+		Language:    resp.Language,
+		Flagged:     resp.Flagged,
+		Attachments: attachmentsFromProto(resp.Attachments),
+		CreatedAt:   parseTime(resp.CreatedAt),
+		ModifiedAt:  parseTime(resp.ModifiedAt),
+	}, nil
+}
+
+// page of a listing, starting from 1, or nil for the first page.
+func page(p int) *int32 {
+	if p <= 1 {
+		return nil
+	}
+	v := int32(p)
+	return &v
+}
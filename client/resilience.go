@@ -0,0 +1,296 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// MethodConfig of the retry and hedging policies of a method.
+type MethodConfig struct {
+	// MaxAttempts of a call, including the first one. One attempt disables retries and hedging.
+	MaxAttempts int
+
+	// HedgingDelay before sending another attempt of a call while the previous ones haven't responded, up to MaxAttempts.
+	// The first response that isn't a codes.Unavailable error is used, and the other attempts are canceled.
+	// Zero disables hedging, so attempts failing with codes.Unavailable are retried with backoff instead.
+	HedgingDelay time.Duration
+}
+
+// ErrCircuitOpen is returned by calls failed fast while the circuit breaker is open.
+// It has the codes.Unavailable status code, but isn't retried.
+var ErrCircuitOpen = status.Error(codes.Unavailable, "circuit breaker is open")
+
+// nonIdempotent methods aren't retried or hedged, as they'd be repeated if an attempt was received before failing,
+// such as CreateProductReview creating duplicate reviews.
+var nonIdempotent = map[string]bool{
+	apipb.Inventory_CreateProductReview_FullMethodName: true,
+}
+
+// interceptedConn makes the calls of a connection through a chain of interceptors.
+type interceptedConn struct {
+	grpc.ClientConnInterface
+	interceptors []grpc.UnaryClientInterceptor
+}
+
+// intercept the calls of the connection with the retry and circuit breaker interceptors of the Client.
+// The circuit breaker is the innermost one, so each attempt of a call is checked and recorded by it.
+func (c *Client) intercept(conn grpc.ClientConnInterface) grpc.ClientConnInterface {
+	return interceptedConn{
+		ClientConnInterface: conn,
+		interceptors:        []grpc.UnaryClientInterceptor{c.retryInterceptor, c.breakerInterceptor},
+	}
+}
+
+// Invoke a method through the interceptors.
+func (cc interceptedConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	conn, _ := cc.ClientConnInterface.(*grpc.ClientConn)
+	return cc.invoker(0)(ctx, method, args, reply, conn, opts...)
+}
+
+// invoker calling the interceptors from the i-th one, and then the connection.
+func (cc interceptedConn) invoker(i int) grpc.UnaryInvoker {
+	if i == len(cc.interceptors) {
+		return func(ctx context.Context, method string, args, reply any, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return cc.ClientConnInterface.Invoke(ctx, method, args, reply, opts...)
+		}
+	}
+	return func(ctx context.Context, method string, args, reply any, conn *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return cc.interceptors[i](ctx, method, args, reply, conn, cc.invoker(i+1), opts...)
+	}
+}
+
+// retryInterceptor retries or hedges the attempts of idempotent calls failing with codes.Unavailable,
+// within the retry budget.
+func (c *Client) retryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	config, ok := c.methods[method]
+	if !ok {
+		config = MethodConfig{MaxAttempts: c.maxAttempts}
+	}
+	if nonIdempotent[method] {
+		config = MethodConfig{MaxAttempts: 1}
+	}
+	attempt := func(ctx context.Context, reply any) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		c.budget.record(err)
+		return err
+	}
+	if msg, ok := reply.(proto.Message); ok && config.HedgingDelay > 0 && config.MaxAttempts > 1 {
+		return c.hedge(ctx, config, msg, attempt)
+	}
+	backoff := c.backoff
+	for n := 1; ; n++ {
+		err := attempt(ctx, reply)
+		if !retryable(err) || n >= config.MaxAttempts || !c.budget.allow() {
+			return err
+		}
+		// Full jitter spreads the retries of clients that failed at the same time.
+		if serr := c.sleep(ctx, rand.N(backoff+1)); serr != nil {
+			return err
+		}
+		backoff = min(backoff*2, c.maxBackoff)
+	}
+}
+
+// hedge a call, sending another attempt after the hedging delay, or as soon as an attempt fails with codes.Unavailable,
+// and using the first response that isn't a codes.Unavailable error.
+func (c *Client) hedge(ctx context.Context, config MethodConfig, reply proto.Message, attempt func(ctx context.Context, reply any) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // Cancel the attempts that are still pending.
+	type result struct {
+		reply proto.Message
+		err   error
+	}
+	var (
+		results = make(chan result, config.MaxAttempts)
+		sent    int
+		pending int
+		delay   <-chan time.Time
+	)
+	send := func() {
+		r := reply.ProtoReflect().New().Interface()
+		go func() {
+			results <- result{r, attempt(ctx, r)}
+		}()
+		sent++
+		pending++
+		delay = nil
+		if sent < config.MaxAttempts {
+			delay = time.After(config.HedgingDelay)
+		}
+	}
+	send()
+	var err error
+	for pending > 0 {
+		select {
+		case <-delay:
+			if c.budget.allow() {
+				send()
+			}
+		case res := <-results:
+			pending--
+			if !retryable(res.err) {
+				if res.err == nil {
+					proto.Merge(reply, res.reply)
+				}
+				return res.err
+			}
+			err = res.err
+			if sent < config.MaxAttempts && c.budget.allow() {
+				send()
+			}
+		}
+	}
+	return err
+}
+
+// retryable errors of attempts, which might succeed if tried again.
+func retryable(err error) bool {
+	return status.Code(err) == codes.Unavailable && !errors.Is(err, ErrCircuitOpen)
+}
+
+// retryBudget limits the retries of the calls of a Client, so a struggling server isn't overloaded with them.
+// Failed attempts take a token, successful ones give back a ratio of a token, and retries are allowed
+// while more than half of the tokens are left.
+type retryBudget struct {
+	mu        sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+// newRetryBudget returns a retry budget, or nil, which allows every retry, if maxTokens is zero.
+func newRetryBudget(maxTokens int, ratio float64) *retryBudget {
+	if maxTokens <= 0 {
+		return nil
+	}
+	return &retryBudget{
+		tokens:    float64(maxTokens),
+		maxTokens: float64(maxTokens),
+		ratio:     ratio,
+	}
+}
+
+// allow retrying a call.
+func (b *retryBudget) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens > b.maxTokens/2
+}
+
+// record the result of an attempt.
+// Attempts failed fast by the circuit breaker or canceled, such as hedged attempts, aren't recorded.
+func (b *retryBudget) record(err error) {
+	if b == nil || errors.Is(err, ErrCircuitOpen) || status.Code(err) == codes.Canceled {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if retryable(err) {
+		b.tokens = max(b.tokens-1, 0)
+	} else {
+		b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+	}
+}
+
+// breakerInterceptor fails attempts fast with ErrCircuitOpen while the circuit breaker is open.
+func (c *Client) breakerInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if !c.breaker.allow(c.now()) {
+		return ErrCircuitOpen
+	}
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	c.breaker.record(c.now(), err)
+	return err
+}
+
+// circuitBreaker opens after consecutive failed attempts, and lets a single attempt through after the cooldown
+// to probe if the server recovered, closing if it succeeds, or opening again otherwise.
+type circuitBreaker struct {
+	failures int
+	cooldown time.Duration
+
+	mu          sync.Mutex
+	consecutive int       // Consecutive failed attempts.
+	openedAt    time.Time // When the circuit opened, if it's open.
+	probing     bool      // If an attempt is probing the server after the cooldown.
+}
+
+// newCircuitBreaker returns a circuit breaker, or nil, which is always closed, if failures is zero.
+func newCircuitBreaker(failures int, cooldown time.Duration) *circuitBreaker {
+	if failures <= 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		failures: failures,
+		cooldown: cooldown,
+	}
+}
+
+// allow an attempt, unless the circuit is open.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.consecutive < b.failures {
+		return true
+	}
+	if b.probing || now.Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record the result of an attempt.
+// Failures are the errors of an unhealthy or overloaded server, and other errors are responses of a healthy one.
+func (b *circuitBreaker) record(now time.Time, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch status.Code(err) {
+	case codes.ResourceExhausted:
+		if isQuotaFailure(err) {
+			// The quota of the tenant is exhausted, rather than the server.
+			b.consecutive = 0
+			break
+		}
+		fallthrough
+	case codes.Unavailable, codes.DeadlineExceeded:
+		b.consecutive++
+		if b.consecutive >= b.failures {
+			b.openedAt = now
+		}
+	case codes.Canceled:
+		// Canceled by the caller, so it says nothing about the server.
+	default:
+		b.consecutive = 0
+	}
+	b.probing = false
+}
+
+// isQuotaFailure returns whether the error carries a QuotaFailure error detail, as when a tenant exceeds its quota.
+func isQuotaFailure(err error) bool {
+	st, _ := status.FromError(err)
+	for _, d := range st.Details() {
+		if _, ok := d.(*errdetails.QuotaFailure); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+package client
+
+import (
+	"strings"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+)
+
+// Product of the inventory.
+type Product struct {
+	ID          string
+	Name        string
+	Description string
+	Price       int64
+	Status      string
+	SKU         string
+	GTIN        string
+	TaxClass    string
+	CreatedAt   time.Time
+	ModifiedAt  time.Time
+
+	// Slug, Locale, Available, and PriceBreakdown are only set by GetProduct, GetLocalizedProduct, and GetProductBySKU.
+	Slug      string
+	Locale    string
+	Available int64
+
+	// PriceBreakdown of the price into its net value and taxes, if taxes are configured.
+	PriceBreakdown *PriceBreakdown
+}
+
+// PriceBreakdown of a price into its net value and taxes.
+type PriceBreakdown struct {
+	Net   int64
+	Tax   int64
+	Gross int64
+
+	// TaxRate in basis points (hundredths of a percent), such as 2000 for 20%.
+	TaxRate int
+}
+
+// SearchProductsParams is used when searching products.
+type SearchProductsParams struct {
+	// QueryString or SKUPrefix is required.
+	QueryString string
+	SKUPrefix   string
+
+	// MinPrice and MaxPrice filter products by price, if set.
+	MinPrice *int64
+	MaxPrice *int64
+
+	// Page of results, starting from 1.
+	Page int
+}
+
+// SearchProductsResponse has a page of products found and the total number of products found.
+type SearchProductsResponse struct {
+	Items []*Product
+	Total int
+}
+
+// CreateProductParams is used when creating a product.
+type CreateProductParams struct {
+	ID          string
+	Name        string
+	Description string
+	Price       int64
+
+	// Status of the product: draft, active (default), or discontinued.
+	Status string
+
+	// SKU and GTIN are optional, and unique across products.
+	SKU  string
+	GTIN string
+
+	// TaxClass of the product. Defaults to the default tax class.
+	TaxClass string
+}
+
+// CreateProductResult has the created product, or the existing one with the same ID.
+type CreateProductResult struct {
+	Product *Product
+
+	// Created is false if a product with the same ID already existed.
+	Created bool
+}
+
+// UpdateProductParams to update a product. Only the fields set are updated.
+type UpdateProductParams struct {
+	ID          string
+	Name        *string
+	Description *string
+	Price       *int64
+	Status      *string
+
+	// SKU and GTIN replace the existing ones. An empty string removes them.
+	SKU  *string
+	GTIN *string
+
+	// TaxClass replaces the existing one. An empty string sets the default tax class.
+	TaxClass *string
+}
+
+// DeleteProductParams to delete a product.
+type DeleteProductParams struct {
+	ID string
+
+	// Force deletion of a product with reviews, deleting its reviews too.
+	Force bool
+}
+
+// QuoteLine requesting a quantity of a product.
+type QuoteLine struct {
+	ProductID string
+	Quantity  int
+}
+
+// QuotedLine with the price of a quantity of a product.
+type QuotedLine struct {
+	ProductID string
+	Quantity  int
+	UnitPrice int64
+
+	// Total of the line, with its taxes.
+	Total PriceBreakdown
+}
+
+// Quote of products, with the totals of its lines.
+type Quote struct {
+	Lines []QuotedLine
+	Net   int64
+	Tax   int64
+	Gross int64
+}
+
+// ProductTranslation of the name and description of a product to a locale.
+type ProductTranslation struct {
+	ProductID   string
+	Locale      string
+	Name        string
+	Description string
+}
+
+// Review of a product.
+type Review struct {
+	ID          string
+	ProductID   string
+	ReviewerID  string
+	Score       int
+	Title       string
+	Description string
+	Language    string
+	Flagged     bool
+	Attachments []Attachment
+	CreatedAt   time.Time
+	ModifiedAt  time.Time
+}
+
+// Attachment of a review, such as a photo of the product.
+type Attachment struct {
+	URL         string
+	ContentType string
+}
+
+// CreateProductReviewParams is used when creating a review.
+type CreateProductReviewParams struct {
+	ProductID   string
+	ReviewerID  string
+	Score       int
+	Title       string
+	Description string
+
+	// Language is the ISO 639-1 code of the language of the review, detected if empty.
+	Language    string
+	Attachments []Attachment
+}
+
+// UpdateProductReviewParams to update a review. Only the fields set are updated.
+type UpdateProductReviewParams struct {
+	ID          string
+	Score       *int
+	Title       *string
+	Description *string
+	Language    *string
+
+	// Attachments replace the existing ones, if set.
+	Attachments *[]Attachment
+}
+
+// timeLayout of the timestamps of the API, which are formatted with time.Time.String.
+const timeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// parseTime of the API, returning the zero time if it's invalid.
+func parseTime(s string) time.Time {
+	s, _, _ = strings.Cut(s, " m=") // Monotonic clock reading.
+	t, _ := time.Parse(timeLayout, s)
+	return t
+}
+
+func productFromProto(p *apipb.Product) *Product {
+	return &Product{
+		ID:          p.Id,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Status:      p.Status,
+		SKU:         p.Sku,
+		GTIN:        p.Gtin,
+		TaxClass:    p.TaxClass,
+		CreatedAt:   parseTime(p.CreatedAt),
+		ModifiedAt:  parseTime(p.ModifiedAt),
+	}
+}
+
+func productsFromProto(pp []*apipb.Product) []*Product {
+	products := make([]*Product, 0, len(pp))
+	for _, p := range pp {
+		products = append(products, productFromProto(p))
+	}
+	return products
+}
+
+func getProductFromProto(p *apipb.GetProductResponse) *Product {
+	product := &Product{
+		ID:          p.Id,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Status:      p.Status,
+		SKU:         p.Sku,
+		GTIN:        p.Gtin,
+		TaxClass:    p.TaxClass,
+		CreatedAt:   parseTime(p.CreatedAt),
+		ModifiedAt:  parseTime(p.ModifiedAt),
+		Slug:        p.Slug,
+		Locale:      p.Locale,
+		Available:   p.Available,
+	}
+	if p.PriceBreakdown != nil {
+		b := priceBreakdownFromProto(p.PriceBreakdown)
+		product.PriceBreakdown = &b
+	}
+	return product
+}
+
+func priceBreakdownFromProto(b *apipb.PriceBreakdown) PriceBreakdown {
+	if b == nil {
+		return PriceBreakdown{}
+	}
+	return PriceBreakdown{
+		Net:     b.Net,
+		Tax:     b.Tax,
+		Gross:   b.Gross,
+		TaxRate: int(b.TaxRate),
+	}
+}
+
+func attachmentsProto(aa []Attachment) []*apipb.ReviewAttachment {
+	var pb []*apipb.ReviewAttachment
+	for _, a := range aa {
+		pb = append(pb, &apipb.ReviewAttachment{
+			Url:         a.URL,
+			ContentType: a.ContentType,
+		})
+	}
+	return pb
+}
+
+func attachmentsFromProto(pb []*apipb.ReviewAttachment) []Attachment {
+	var aa []Attachment
+	for _, a := range pb {
+		aa = append(aa, Attachment{
+			URL:         a.Url,
+			ContentType: a.ContentType,
+		})
+	}
+	return aa
+}
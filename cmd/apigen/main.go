@@ -0,0 +1,220 @@
+// Command apigen generates OpenAPI and TypeScript client artifacts from the protobuf definitions of the API,
+// so consumers that don't use the Go packages stay in sync with the gRPC contract.
+//
+// It wraps protoc, which must be on the PATH along with the following plugins:
+//
+//	go install github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2@latest
+//	npm install --global ts-proto # Installs protoc-gen-ts_proto.
+//
+// Usage:
+//
+//	go run ./cmd/apigen [-out api] [-check] internal/apiv1/api.proto
+//
+// The artifacts are written to subdirectories of the output directory, replacing the previous ones:
+// openapi/api.swagger.json, and ts/api.ts.
+// With -check, nothing is written, and it exits with status 3 if the artifacts are outdated, such as in CI.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	out   = flag.String("out", "api", "Output directory of the artifacts")
+	check = flag.Bool("check", false, "Check if the artifacts in the output directory are up to date, without writing them")
+)
+
+// generator of artifacts with a protoc plugin.
+type generator struct {
+	// dir of the artifacts, relative to the output directory.
+	dir string
+
+	// plugin binary, looked up on the PATH.
+	plugin string
+
+	// args of protoc to run the plugin, given the directory to write to.
+	args func(dir string) []string
+}
+
+// generators of the artifacts.
+var generators = []generator{
+	{
+		dir:    "openapi",
+		plugin: "protoc-gen-openapiv2",
+		args: func(dir string) []string {
+			return []string{
+				"--openapiv2_out=" + dir,
+				// Without HTTP annotations, methods are mapped to POST /{package}.{service}/{method},
+				// and int64 fields are strings, following the proto3 JSON mapping.
+				"--openapiv2_opt=generate_unbound_methods=true,allow_merge=true,merge_file_name=api",
+			}
+		},
+	},
+	{
+		dir:    "ts",
+		plugin: "protoc-gen-ts_proto",
+		args: func(dir string) []string {
+			return []string{
+				"--ts_proto_out=" + dir,
+				// forceLong=string matches the OpenAPI representation of int64 fields.
+				"--ts_proto_opt=esModuleInterop=true,forceLong=string,outputServices=default",
+			}
+		},
+	},
+}
+
+// errOutdated is returned by the check if the artifacts are outdated.
+var errOutdated = errors.New("generated API artifacts are outdated: run go generate ./internal/apiv1")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: apigen [-out dir] [-check] file.proto...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	switch err := run(ctx, *out, *check, flag.Args()); {
+	case errors.Is(err, errOutdated):
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(3)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "apigen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, out string, check bool, protos []string) error {
+	if !check {
+		return generate(ctx, out, protos)
+	}
+	tmp, err := os.MkdirTemp("", "apigen")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := generate(ctx, tmp, protos); err != nil {
+		return err
+	}
+	var outdated bool
+	for _, g := range generators {
+		diff, err := compareDirs(filepath.Join(tmp, g.dir), filepath.Join(out, g.dir))
+		if err != nil {
+			return err
+		}
+		for _, name := range diff {
+			fmt.Fprintf(os.Stderr, "%s: outdated\n", filepath.Join(out, g.dir, name))
+			outdated = true
+		}
+	}
+	if outdated {
+		return errOutdated
+	}
+	return nil
+}
+
+// generate the artifacts of the protobuf files in the output directory.
+func generate(ctx context.Context, out string, protos []string) error {
+	protoc, err := exec.LookPath("protoc")
+	if err != nil {
+		return fmt.Errorf("protoc not found: %w", err)
+	}
+	for _, g := range generators {
+		plugin, err := exec.LookPath(g.plugin)
+		if err != nil {
+			return fmt.Errorf("%s not found (see go doc ./cmd/apigen): %w", g.plugin, err)
+		}
+		dir := filepath.Join(out, g.dir)
+		// Remove the previous artifacts, so artifacts of removed definitions don't linger.
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		args := []string{"--plugin=" + g.plugin + "=" + plugin}
+		var paths, files []string
+		for _, p := range protos {
+			paths = append(paths, filepath.Dir(p))
+			files = append(files, filepath.Base(p))
+		}
+		slices.Sort(paths)
+		for _, p := range slices.Compact(paths) {
+			args = append(args, "--proto_path="+p)
+		}
+		args = append(args, g.args(dir)...)
+		args = append(args, files...)
+		cmd := exec.CommandContext(ctx, protoc, args...) // #nosec G204
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("cannot generate %s artifacts: %w: %s", g.dir, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return nil
+}
+
+// compareDirs returns the names of the files that differ between the directories, or only exist in one of them.
+func compareDirs(want, got string) ([]string, error) {
+	wantFiles, err := readDir(want)
+	if err != nil {
+		return nil, err
+	}
+	gotFiles, err := readDir(got)
+	if err != nil {
+		return nil, err
+	}
+	var diff []string
+	for name, w := range wantFiles {
+		if g, ok := gotFiles[name]; !ok || !bytes.Equal(w, g) {
+			diff = append(diff, name)
+		}
+	}
+	for name := range gotFiles {
+		if _, ok := wantFiles[name]; !ok {
+			diff = append(diff, name)
+		}
+	}
+	slices.Sort(diff)
+	return diff, nil
+}
+
+// readDir returns the contents of the files in a directory tree by their relative paths.
+// A directory that doesn't exist has no files.
+func readDir(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return fs.SkipAll
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(path) // #nosec G304
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = b
+		return nil
+	})
+	return files, err
+}
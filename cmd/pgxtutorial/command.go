@@ -0,0 +1,468 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/api"
+	"github.com/henvic/pgxtutorial/internal/apitest"
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/datagen"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/memdb"
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	"github.com/henvic/pgxtutorial/internal/postgres"
+	"github.com/henvic/pgxtutorial/internal/replay"
+	"github.com/henvic/pgxtutorial/internal/schemadoc"
+	"github.com/henvic/pgxtutorial/internal/snapshot"
+	"github.com/jackc/pgx/v5/tracelog"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// command runs the subcommand given by the arguments.
+func command(args []string) error {
+	switch {
+	case len(args) >= 2 && args[0] == "schema" && args[1] == "doc":
+		return schemaDoc(args[2:])
+	case len(args) >= 1 && args[0] == "verify":
+		return verify(args[1:])
+	case len(args) >= 2 && args[0] == "dlq" && args[1] == "list":
+		return dlqList(args[2:])
+	case len(args) >= 2 && args[0] == "dlq" && args[1] == "inspect":
+		return dlqInspect(args[2:])
+	case len(args) >= 2 && args[0] == "dlq" && args[1] == "replay":
+		return dlqReplay(args[2:])
+	case len(args) >= 1 && args[0] == "datagen":
+		return datagenLoad(args[1:])
+	case len(args) >= 1 && args[0] == "dump":
+		return dump(args[1:])
+	case len(args) >= 1 && args[0] == "restore":
+		return restore(args[1:])
+	case len(args) >= 1 && args[0] == "mockserver":
+		return mockServer(args[1:])
+	case len(args) >= 1 && args[0] == "replay":
+		return replayRequests(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+	}
+}
+
+// schemaDoc writes a data dictionary of the database to the standard output.
+func schemaDoc(args []string) error {
+	fs := flag.NewFlagSet("schema doc", flag.ExitOnError)
+	format := fs.String("format", "markdown", "Output format: markdown or html")
+	schema := fs.String("schema", "public", "Database schema to document")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelWarn, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create pgx pool: %w", err)
+	}
+	defer pool.Close()
+
+	tables, err := schemadoc.Tables(ctx, pool, *schema)
+	if err != nil {
+		return err
+	}
+	return schemadoc.Write(os.Stdout, tables, *format)
+}
+
+// errFindings is returned by verify when integrity checks find inconsistent rows.
+var errFindings = errors.New("integrity checks found inconsistent rows")
+
+// verify runs integrity checks on the database, printing the findings.
+func verify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelWarn, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create pgx pool: %w", err)
+	}
+	defer pool.Close()
+
+	findings, err := postgres.NewDB(pool, slog.Default()).Verify(ctx)
+	if err != nil {
+		return err
+	}
+	for _, f := range findings {
+		fmt.Printf("%s: %d rows: %s\n", f.Check, f.Rows, f.Description)
+	}
+	if len(findings) != 0 {
+		return errFindings
+	}
+	return nil
+}
+
+// dlqList prints the events on the dead letter queue of the outbox, the oldest first.
+func dlqList(args []string) error {
+	fs := flag.NewFlagSet("dlq list", flag.ExitOnError)
+	limit := fs.Int("limit", 100, fmt.Sprintf("Maximum number of dead letters to list, up to %d", outbox.MaxDeadLetters))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *limit < 1 || *limit > outbox.MaxDeadLetters {
+		return fmt.Errorf("limit must be between 1 and %d", outbox.MaxDeadLetters)
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelWarn, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create pgx pool: %w", err)
+	}
+	defer pool.Close()
+
+	db := postgres.NewDB(pool, slog.Default())
+	letters, err := db.ListDeadLetters(ctx, *limit)
+	if err != nil {
+		return err
+	}
+	total, err := db.CountDeadLetters(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range letters {
+		fmt.Printf("%d\t%s\t%d attempts\t%s\t%s\n", d.ID, d.Topic, d.Attempts, d.DeadLetteredAt.Format(time.RFC3339), d.LastError)
+	}
+	fmt.Printf("%d of %d dead letters\n", len(letters), total)
+	return nil
+}
+
+// dlqInspect prints an event on the dead letter queue of the outbox, with the reason it failed.
+func dlqInspect(args []string) error {
+	fs := flag.NewFlagSet("dlq inspect", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: dlq inspect <id>")
+	}
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid dead letter ID %q", fs.Arg(0))
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelWarn, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create pgx pool: %w", err)
+	}
+	defer pool.Close()
+
+	d, err := postgres.NewDB(pool, slog.Default()).GetDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("id: %d\n", d.ID)
+	fmt.Printf("topic: %s\n", d.Topic)
+	fmt.Printf("created at: %s\n", d.CreatedAt.Format(time.RFC3339))
+	fmt.Printf("dead lettered at: %s\n", d.DeadLetteredAt.Format(time.RFC3339))
+	fmt.Printf("attempts: %d\n", d.Attempts)
+	fmt.Printf("last error: %s\n", d.LastError)
+	fmt.Printf("payload: %s\n", d.Payload)
+	return nil
+}
+
+// dlqReplay moves events from the dead letter queue back to the outbox, all of them or none.
+func dlqReplay(args []string) error {
+	fs := flag.NewFlagSet("dlq replay", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: dlq replay <id>...")
+	}
+	ids := make([]int64, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid dead letter ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelWarn, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create pgx pool: %w", err)
+	}
+	defer pool.Close()
+
+	if err := postgres.NewDB(pool, slog.Default()).ReplayDeadLetters(ctx, ids); err != nil {
+		return err
+	}
+	fmt.Printf("%d dead letters replayed\n", len(ids))
+	return nil
+}
+
+// dump the products and reviews of the database to a compressed archive.
+func dump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: dump <archive.zip>")
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelWarn, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create pgx pool: %w", err)
+	}
+	defer pool.Close()
+
+	// Refuse to overwrite an existing archive.
+	f, err := os.OpenFile(fs.Arg(0), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	m, err := snapshot.Dump(ctx, pool, f, progress())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		if rerr := os.Remove(fs.Arg(0)); rerr != nil {
+			fmt.Fprintf(os.Stderr, "cannot remove incomplete archive: %v\n", rerr)
+		}
+		return err
+	}
+	for _, t := range m.Tables {
+		fmt.Fprintf(os.Stderr, "%s: %d rows\n", t.Name, t.Rows)
+	}
+	fmt.Fprintf(os.Stderr, "dumped schema version %d to %s\n", m.SchemaVersion, fs.Arg(0))
+	return nil
+}
+
+// restore the products and reviews of an archive created by dump to an empty database with the same schema version.
+func restore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: restore <archive.zip>")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelWarn, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create pgx pool: %w", err)
+	}
+	defer pool.Close()
+
+	m, err := snapshot.Restore(ctx, pool, f, fi.Size(), progress())
+	if err != nil {
+		return err
+	}
+	for _, t := range m.Tables {
+		fmt.Fprintf(os.Stderr, "%s: %d rows\n", t.Name, t.Rows)
+	}
+	fmt.Fprintf(os.Stderr, "restored archive created at %s\n", m.CreatedAt.Format(time.RFC3339))
+	return nil
+}
+
+// progress of dump and restore printed to the standard error at most once a second.
+func progress() snapshot.Progress {
+	var last time.Time
+	return func(table string, done, total int64) {
+		if time.Since(last) < time.Second {
+			return
+		}
+		last = time.Now()
+		if total < 0 {
+			fmt.Fprintf(os.Stderr, "%s: %d MB\n", table, done>>20)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "%s: %d of %d MB (%d%%)\n", table, done>>20, total>>20, done*100/max(total, 1))
+	}
+}
+
+// datagenLoad loads synthetic products and reviews for load testing.
+func datagenLoad(args []string) error {
+	fs := flag.NewFlagSet("datagen", flag.ExitOnError)
+	products := fs.Int("products", 100000, "Number of products to generate")
+	reviews := fs.Float64("reviews", 5, "Average number of reviews per product")
+	seed := fs.Uint64("seed", 1, "Seed of the data: the same seed generates the same data, for reproducible benchmarks")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *products < 1 || *reviews < 0 {
+		return errors.New("products must be positive, and reviews can't be negative")
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelWarn, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create pgx pool: %w", err)
+	}
+	defer pool.Close()
+
+	g := &datagen.Generator{
+		Seed:              *seed,
+		ReviewsPerProduct: *reviews,
+	}
+	start := time.Now()
+	stats, err := g.Load(ctx, pool, *products, func(table string, rows int64) {
+		if rows%100000 == 0 {
+			fmt.Fprintf(os.Stderr, "%s: %d rows\n", table, rows)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "loaded %d products and %d reviews in %v\n", stats.Products, stats.Reviews, time.Since(start).Round(time.Second))
+	return nil
+}
+
+// mockServer serves the gRPC and HTTP APIs backed by an in-memory database with deterministic seed data,
+// so clients can be developed against the API without PostgreSQL.
+// Database calls can be slowed down or failed to simulate an unreliable database.
+func mockServer(args []string) error {
+	fs := flag.NewFlagSet("mockserver", flag.ExitOnError)
+	httpAddr := fs.String("http", "localhost:8080", "HTTP service address to listen on")
+	grpcAddr := fs.String("grpc", "localhost:8082", "gRPC service address to listen on")
+	probeAddr := fs.String("probe", "localhost:6060", "Probe service address to listen on")
+	products := fs.Int("products", 1000, "Number of products to seed the database with")
+	reviews := fs.Float64("reviews", 5, "Average number of reviews per product")
+	seed := fs.Uint64("seed", 1, "Seed of the data: the same seed generates the same data")
+	latency := fs.Duration("latency", 0, "Latency added to each database call")
+	errorRate := fs.Float64("error-rate", 0, "Fraction of the database calls failed as unavailable, from 0 to 1")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *products < 0 || *reviews < 0 {
+		return errors.New("products and reviews can't be negative")
+	}
+	if *latency < 0 || *errorRate < 0 || *errorRate > 1 {
+		return errors.New("latency can't be negative, and the error rate must be from 0 to 1")
+	}
+
+	db := memdb.New()
+	db.Seed(&datagen.Generator{
+		Seed:              *seed,
+		ReviewsPerProduct: *reviews,
+	}, *products)
+	faults := apitest.Faults{"*": {
+		Latency:   *latency,
+		ErrorRate: *errorRate,
+	}}
+	s := &api.Server{
+		HTTPAddress:  *httpAddr,
+		GRPCAddress:  *grpcAddr,
+		ProbeAddress: *probeAddr,
+		Log:          slog.Default(),
+		Tracer:       tracenoop.NewTracerProvider(),
+		Meter:        metricnoop.NewMeterProvider(),
+		Propagator:   propagation.TraceContext{},
+		Inventory:    inventory.NewService(faults.DB(db)),
+	}
+	slog.Info("serving mock server", slog.Int("products", *products), slog.Uint64("seed", *seed))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ec := make(chan error, 1)
+	go func() {
+		ec <- s.Run(context.Background())
+	}()
+	select {
+	case err := <-ec:
+		return err
+	case <-ctx.Done():
+		haltCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.Shutdown(haltCtx)
+		return <-ec
+	}
+}
+
+// errMismatches is returned by replayRequests when responses differ from the recorded ones.
+var errMismatches = errors.New("replayed responses differ from the recorded ones")
+
+// replayRequests issues the requests recorded on the files again against a target, printing how its responses
+// and latencies compare with the recorded ones.
+func replayRequests(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	target := fs.String("target", "", "Base URL of the HTTP API to replay the requests against (example: http://localhost:8080)")
+	methods := fs.String("methods", "GET,HEAD", "Comma-separated list of the methods of the requests replayed, so requests modifying data aren't repeated unless asked for")
+	concurrency := fs.Int("concurrency", 1, "Requests replayed at once")
+	compareBodies := fs.Bool("compare-bodies", false, "Compare the response bodies with the recorded ones, besides their status")
+	timeout := fs.Duration("timeout", 10*time.Second, "Timeout of each request")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *target == "" || fs.NArg() == 0 {
+		return errors.New("usage: replay -target <url> [flags] <files>")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	r := &replay.Replayer{
+		BaseURL:       *target,
+		Client:        &http.Client{Timeout: *timeout},
+		Methods:       strings.Split(*methods, ","),
+		Concurrency:   *concurrency,
+		CompareBodies: *compareBodies,
+	}
+	report, err := r.Replay(ctx, fs.Args()...)
+	if report != nil {
+		fmt.Printf("replayed: %d, skipped: %d, failed: %d, status mismatches: %d, body mismatches: %d\n",
+			report.Replayed, report.Skipped, report.Failed, report.StatusMismatches, report.BodyMismatches)
+		fmt.Printf("recorded latency: p50 %v, p95 %v, p99 %v\n", report.Recorded.P50, report.Recorded.P95, report.Recorded.P99)
+		fmt.Printf("replayed latency: p50 %v, p95 %v, p99 %v\n", report.Latency.P50, report.Latency.P95, report.Latency.P99)
+		for _, m := range report.Mismatches {
+			fmt.Println(m)
+		}
+	}
+	switch {
+	case err != nil:
+		return err
+	case report.Failed != 0 || report.StatusMismatches != 0 || report.BodyMismatches != 0:
+		return errMismatches
+	}
+	return nil
+}
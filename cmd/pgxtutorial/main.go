@@ -2,95 +2,154 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	_ "expvar" // #nosec G108
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
-	"net/http"
 	_ "net/http/pprof" // #nosec G108
 	"os"
 	"os/signal"
 	"runtime"
 	"runtime/debug"
-	"sync"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/felixge/fgprof"
-	"github.com/henvic/pgxtutorial/internal/api"
-	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/app"
+	"github.com/henvic/pgxtutorial/internal/buildinfo"
 	"github.com/henvic/pgxtutorial/internal/inventory"
-	"github.com/henvic/pgxtutorial/internal/postgres"
+	"github.com/henvic/pgxtutorial/internal/slo"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
-	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
-	"go.opentelemetry.io/otel/metric"
-	"go.opentelemetry.io/otel/metric/noop"
-	"go.opentelemetry.io/otel/propagation"
-	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
-	"go.opentelemetry.io/otel/sdk/resource"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
-	tracenoop "go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/automaxprocs/maxprocs"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
 	httpAddr  = flag.String("http", "localhost:8080", "HTTP service address to listen for incoming requests on")
 	grpcAddr  = flag.String("grpc", "localhost:8082", "gRPC service address to listen for incoming requests on")
 	probeAddr = flag.String("probe", "localhost:6060", "probe (inspection) HTTP service address")
+	addr      = flag.String("addr", "", "Address to serve both gRPC and HTTP on, using HTTP/2 cleartext (h2c) for gRPC, instead of -http and -grpc")
 	version   = flag.Bool("version", false, "Print build info")
+	mode      = flag.String("mode", "all", "Subsystems to start: serve (API only), worker (background workers only, such as the outbox relay), or all")
+
+	trustedProxies = flag.String("trusted-proxies", "", "Comma-separated list of IP addresses and CIDR prefixes of trusted proxies, such as load balancers, to resolve the client IP address from their Forwarded and X-Forwarded-For headers (example: 10.0.0.0/8)")
+
+	httpEnvelope = flag.Bool("http-envelope", false, "Wrap HTTP responses in an envelope, as in {\"data\": ..., \"meta\": ...} or {\"error\": ...}")
+
+	searchView        = flag.Bool("search-view", false, "Search products using the product_search materialized view")
+	searchViewRefresh = flag.Duration("search-view-refresh", time.Minute, "Refresh interval of the product_search materialized view")
+	listingCache      = flag.Duration("listing-cache", time.Minute, "Duration to cache trending and recent product listings for (0 disables caching)")
+	searchCache       = flag.Duration("search-cache", 5*time.Second, "Duration to cache product searches for (0 disables caching)")
+	searchCacheStale  = flag.Duration("search-cache-stale", 30*time.Second, "Duration to serve expired product searches for while they're revalidated in the background")
+
+	searchWindowTotal = flag.Bool("search-window-total", false, "Count the products found by a search with a window function, in the same statement as the page of products, rather than with a separate COUNT statement")
+
+	productCache       = flag.Duration("product-cache", 0, "Duration to cache products read by ID for (0 disables caching)")
+	productCacheWarmup = flag.Int("product-cache-warmup", 0, "Number of trending products read into the product cache on startup, before serving requests (0 disables warming; requires -product-cache)")
+
+	contentReject = flag.String("content-reject", "", "Case-insensitive regular expression of review content to reject (example: \\b(scam|fraud)\\b)")
+	contentFlag   = flag.String("content-flag", "", "Case-insensitive regular expression of review content to flag for moderation (example: https?://)")
+
+	reviewQuota       = flag.Int("review-quota", 0, "Maximum number of reviews a reviewer can create within the review quota period (0 disables the quota)")
+	reviewQuotaPeriod = flag.Duration("review-quota-period", time.Hour, "Period of the review quota")
+
+	taxRates = flag.String("tax-rates", "", "Comma-separated list of tax classes and their rates as percentages, with the default tax class unnamed (example: =20,reduced=5.5,exempt=0)")
+
+	replicas   = flag.String("replicas", "", "Comma-separated list of connection strings of read replicas")
+	hedgeAfter = flag.Duration("hedge-after", 0, "Latency threshold for hedging read queries to a second replica (0 disables hedging)")
+
+	batchPoolSize = flag.Int("batch-pool-size", 0, "Maximum connections of a separate database pool for background work, such as the workers, so it can't starve API requests (0 uses the same pool)")
+
+	poolSizeInterval = flag.Duration("pool-size-interval", 0, "Interval between checks of the utilization of the database pools, closing idle connections after sustained low traffic and opening them on saturation, within the minimum and maximum connections of each pool (0 disables it)")
+	poolShrinkAfter  = flag.Duration("pool-shrink-after", 5*time.Minute, "Duration of low utilization of a database pool after which the number of connections it keeps open is halved")
+
+	schemaCheck = flag.Bool("schema-check", true, "Refuse to start if the database schema version is incompatible, unless in read-only mode")
+	readOnly    = flag.Bool("read-only", false, "Reject requests that modify data, such as when only read replicas are available")
+
+	explain = flag.String("explain", "", "Comma-separated list of statements to capture EXPLAIN ANALYZE plans for debugging (example: SearchProducts,GetProductReviews or *)")
+
+	leakDetector = flag.Duration("leak-detector", 0, "Report database connections and transactions held longer than this, with the stack traces of where they were acquired, for debugging (0 disables it)")
+
+	sloAvailability = flag.Float64("slo-availability", 0, "Availability objective of gRPC methods, such as 0.999 (0 disables SLO tracking)")
+	sloLatency      = flag.Duration("slo-latency", 0, "Latency objective of gRPC methods (0 disables the latency objective)")
+	sloWindow       = flag.Duration("slo-window", time.Hour, "Rolling window of the SLO, of at least a minute")
+	sloMinCalls     = flag.Int64("slo-min-calls", slo.DefaultMinCalls, "Calls to a gRPC method within the SLO window for its error budget to count as exhausted")
+	sloReadiness    = flag.Bool("slo-readiness", false, "Report the gRPC server as not serving while the error budget is exhausted")
+
+	concurrencyPerConn = flag.Float64("concurrency-per-conn", 0, "Limit of in-flight requests of each HTTP route and gRPC method, as a multiple of the maximum database pool connections, beyond which requests are rejected with 503 or ResourceExhausted (0 disables load shedding)")
+
+	outboxInterval    = flag.Duration("outbox-interval", 0, "Interval between runs of the outbox relay, which publishes events to the log (0 disables the relay)")
+	outboxBatchSize   = flag.Int("outbox-batch-size", 100, "Maximum number of events published by each run of the outbox relay")
+	outboxMaxAttempts = flag.Int("outbox-max-attempts", 10, "Attempts to publish an event before moving it to the dead letter queue (0 retries it indefinitely)")
+
+	outboxRate        = flag.Float64("outbox-rate", 0, "Events per second published to each topic by the outbox relay, slowed down while publishing them fails or is slow (0 doesn't limit the rate)")
+	outboxConcurrency = flag.Int("outbox-concurrency", 0, "Events published to each topic at once, out of order if more than one (0 publishes them one at a time, in order)")
+	outboxSlowPublish = flag.Duration("outbox-slow-publish", 0, "Time publishing an event takes for its topic to be slowed down, as if it failed (0 only slows down on failures)")
+
+	reviewSummaryInterval  = flag.Duration("review-summary-interval", 0, "Interval between runs of the job summarizing the reviews of products (0 disables the job)")
+	reviewSummaryBatchSize = flag.Int("review-summary-batch-size", 100, "Maximum number of products whose reviews are summarized by each run of the job")
+
+	recentlyViewedTTL   = flag.Duration("recently-viewed-ttl", 30*24*time.Hour, "Time products are kept on the recently viewed lists of users")
+	recentlyViewedPrune = flag.Duration("recently-viewed-prune-interval", 0, "Interval between prunings of the products viewed longer than -recently-viewed-ttl ago (0 disables pruning)")
+
+	cdcSlot        = flag.String("cdc-slot", "", "Logical replication slot streaming the changes of products and reviews to the log, created if it doesn't exist (empty disables change data capture)")
+	cdcPublication = flag.String("cdc-publication", "pgxtutorial_cdc", "Publication of the tables streamed by the logical replication slot, created if it doesn't exist")
+
+	environment           = flag.String("environment", app.EnvironmentProduction, "Environment the application runs in, such as production or staging")
+	faultInjection        = flag.String("fault-injection", "", "Semicolon-separated list of rules injecting latency, errors, or dropped connections into requests for chaos testing, refused in production (example: percent=5,error=unavailable;endpoint=/product/,percent=10,latency=2s)")
+	faultInjectionHeaders = flag.Bool("fault-injection-headers", false, "Inject the faults requested by the X-Fault-Injection header of requests, such as latency=500ms,error=unavailable or drop, refused in production")
+
+	captureDir        = flag.String("capture-dir", "", "Directory to record a sample of the HTTP requests and responses to, anonymized, to replay them against another environment with the replay command (empty disables recording)")
+	captureSampleRate = flag.Float64("capture-sample-rate", 0.01, "Fraction of the HTTP requests recorded to -capture-dir, from 0 to 1")
+
+	usageInterval = flag.Duration("usage-interval", 0, "Interval between the writes of the requests and bytes of the callers identified by the trusted proxies with the X-Tenant-ID and X-API-Key-ID headers, served at /usage (0 disables usage accounting)")
+
+	quotas                    = flag.Bool("quotas", false, "Enforce the quotas of the products and reviews of each tenant, adjusted by the admin at /admin/quotas/{tenant}")
+	quotaMaxProducts          = flag.Int("quota-max-products", 0, "Default maximum number of products of a tenant, with -quotas (0 is unlimited)")
+	quotaMaxReviewsPerProduct = flag.Int("quota-max-reviews-per-product", 0, "Default maximum number of reviews of each product of a tenant, with -quotas (0 is unlimited)")
+
+	profilingInterval = flag.Duration("profiling-interval", time.Minute, "Interval between profiles pushed to PROFILING_URL")
 
 	buildInfo, _ = debug.ReadBuildInfo()
+	build        = buildinfo.Read()
 )
 
-// buildInfoTelemetry for OpenTelemetry.
-func buildInfoTelemetry() []attribute.KeyValue {
-	attrs := []attribute.KeyValue{
-		semconv.ServiceName("api"),
-		semconv.ServiceVersion("1.0.0"),
-		attribute.Key("build.go").String(runtime.Version()),
-	}
-	for _, s := range buildInfo.Settings {
-		switch s.Key {
-		case "vcs.revision", "vcs.time":
-			attrs = append(attrs, attribute.Key("build."+s.Key).String(s.Value))
-		case "vcs.modified":
-			attrs = append(attrs, attribute.Key("build.vcs.modified").Bool(s.Value == "true"))
-		}
-	}
-	return attrs
-}
-
 func main() {
 	flag.Parse()
 	if *version {
 		fmt.Println(buildInfo)
 		os.Exit(2)
 	}
-
-	p := program{
-		log: slog.Default(),
+	if flag.NArg() != 0 {
+		switch err := command(flag.Args()); {
+		case errors.Is(err, errFindings), errors.Is(err, errMismatches):
+			// A distinct exit code lets cron jobs and CI tell findings apart from failures.
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(3)
+		case err != nil:
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	haltTelemetry, err := p.telemetry()
+	// Build info is added to every log record to identify the version of the binary across the fleet.
+	log := slog.Default().With(build.LogAttr())
+	log.Info("starting", slog.String("time", build.Time), slog.Bool("modified", build.Modified))
+
+	tel, err := app.NewTelemetry(log, build)
 	if err != nil {
-		p.log.Error("cannot initialize telemetry", slog.Any("error", err))
+		log.Error("cannot initialize telemetry", slog.Any("error", err))
 		os.Exit(1)
 	}
 	// Setting catch-all global OpenTelemetry providers.
-	otel.SetTracerProvider(p.tracer)
-	otel.SetTextMapPropagator(p.propagator)
-	otel.SetMeterProvider(p.meter)
+	otel.SetTracerProvider(tel.Tracer)
+	otel.SetTextMapPropagator(tel.Propagator)
+	otel.SetMeterProvider(tel.Meter)
 	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
-		p.log.Error("irremediable OpenTelemetry event", slog.Any("error", err))
+		log.Error("irremediable OpenTelemetry event", slog.Any("error", err))
 	}))
 
 	defer func() {
@@ -98,7 +157,7 @@ func main() {
 			os.Exit(1)
 		}
 	}()
-	defer haltTelemetry()
+	defer tel.Shutdown()
 
 	_, span := otel.Tracer("main").Start(context.Background(), "main")
 	defer func() {
@@ -119,141 +178,116 @@ func main() {
 		span.End()
 	}()
 
-	if err = p.run(); err != nil {
-		p.log.Error("application terminated by error", slog.Any("error", err))
+	if err = run(tel); err != nil {
+		log.Error("application terminated by error", slog.Any("error", err))
 	}
 }
 
-type program struct {
-	log        *slog.Logger
-	tracer     trace.TracerProvider
-	propagator propagation.TextMapPropagator
-	meter      metric.MeterProvider
-}
-
-func (p *program) run() error {
-	// Set GOMAXPROCS to match Linux container CPU quota on Linux.
-	if runtime.GOOS == "linux" {
-		if _, err := maxprocs.Set(maxprocs.Logger(p.log.Info)); err != nil {
-			p.log.Error("cannot set GOMAXPROCS", slog.Any("error", err))
-		}
-	}
-
-	// Register fgprof HTTP handler, a sampling Go profiler.
-	http.DefaultServeMux.Handle("/debug/fgprof", fgprof.Handler())
-
-	pgxLogLevel, err := database.LogLevelFromEnv()
+// config of the application from the flags and environment variables.
+func config() (app.Config, error) {
+	m, err := app.ParseMode(*mode)
 	if err != nil {
-		return fmt.Errorf("cannot get pgx logging level: %w", err)
+		return app.Config{}, err
 	}
-	pgPool, err := database.NewPGXPool(context.Background(), "", &database.PGXStdLogger{
-		Logger: p.log,
-	}, pgxLogLevel, p.tracer)
-	if err != nil {
-		return fmt.Errorf("cannot create pgx pool: %w", err)
+	c := app.Config{
+		Mode:                   m,
+		HTTPAddress:            *httpAddr,
+		GRPCAddress:            *grpcAddr,
+		ProbeAddress:           *probeAddr,
+		Address:                *addr,
+		TrustedProxies:         *trustedProxies,
+		HTTPEnvelope:           *httpEnvelope,
+		AdminToken:             os.Getenv("ADMIN_TOKEN"),
+		HedgeAfter:             *hedgeAfter,
+		BatchPoolSize:          *batchPoolSize,
+		PoolSizeInterval:       *poolSizeInterval,
+		PoolShrinkAfter:        *poolShrinkAfter,
+		SchemaCheck:            *schemaCheck,
+		ReadOnly:               *readOnly,
+		SearchView:             *searchView,
+		SearchViewRefresh:      *searchViewRefresh,
+		SearchWindowTotal:      *searchWindowTotal,
+		LeakDetector:           *leakDetector,
+		ReviewerIDKeys:         os.Getenv("REVIEWER_ID_KEYS"),
+		CostPriceKeys:          os.Getenv("COST_PRICE_KEYS"),
+		ListingCache:           *listingCache,
+		ProductCache:           *productCache,
+		ProductCacheWarmup:     *productCacheWarmup,
+		SearchCache:            *searchCache,
+		SearchCacheStale:       *searchCacheStale,
+		ContentReject:          *contentReject,
+		ContentFlag:            *contentFlag,
+		ReviewQuota:            *reviewQuota,
+		ReviewQuotaPeriod:      *reviewQuotaPeriod,
+		TaxRates:               *taxRates,
+		SLOAvailability:        *sloAvailability,
+		SLOLatency:             *sloLatency,
+		SLOWindow:              *sloWindow,
+		SLOMinCalls:            *sloMinCalls,
+		SLOReadiness:           *sloReadiness,
+		ConcurrencyPerConn:     *concurrencyPerConn,
+		ProfilingURL:           os.Getenv("PROFILING_URL"),
+		ProfilingInterval:      *profilingInterval,
+		OutboxInterval:         *outboxInterval,
+		OutboxBatchSize:        *outboxBatchSize,
+		OutboxMaxAttempts:      *outboxMaxAttempts,
+		OutboxRate:             *outboxRate,
+		OutboxConcurrency:      *outboxConcurrency,
+		OutboxSlowPublish:      *outboxSlowPublish,
+		ReviewSummaryInterval:  *reviewSummaryInterval,
+		ReviewSummaryBatchSize: *reviewSummaryBatchSize,
+		RecentlyViewedTTL:      *recentlyViewedTTL,
+		RecentlyViewedPrune:    *recentlyViewedPrune,
+		CDCSlot:                *cdcSlot,
+		CDCPublication:         *cdcPublication,
+		Environment:            *environment,
+		FaultInjection:         *faultInjection,
+		FaultInjectionHeaders:  *faultInjectionHeaders,
+		CaptureDir:             *captureDir,
+		CaptureSampleRate:      *captureSampleRate,
+		UsageInterval:          *usageInterval,
+		Quotas:                 *quotas,
+		DefaultQuota:           inventory.Quota{MaxProducts: *quotaMaxProducts, MaxReviewsPerProduct: *quotaMaxReviewsPerProduct},
+		ShutdownGracePeriod:    3 * time.Second,
 	}
-	defer pgPool.Close()
-
-	s := &api.Server{
-		Inventory:    inventory.NewService(postgres.NewDB(pgPool, p.log)),
-		Log:          p.log,
-		Tracer:       p.tracer,
-		Meter:        p.meter,
-		Propagator:   p.propagator,
-		HTTPAddress:  *httpAddr,
-		GRPCAddress:  *grpcAddr,
-		ProbeAddress: *probeAddr,
+	if *replicas != "" {
+		c.Replicas = strings.Split(*replicas, ",")
 	}
-	ec := make(chan error, 1)
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	go func() {
-		ec <- s.Run(context.Background())
-	}()
-
-	// Waits for an internal error that shutdowns the server.
-	// Otherwise, wait for a SIGINT or SIGTERM and tries to shutdown the server gracefully.
-	// After a shutdown signal, HTTP requests taking longer than the specified grace period are forcibly closed.
-	select {
-	case err = <-ec:
-	case <-ctx.Done():
-		fmt.Println()
-		haltCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
-		s.Shutdown(haltCtx)
-		stop()
-		err = <-ec
+	if *explain != "" {
+		c.Explain = strings.Split(*explain, ",")
 	}
-	if err != nil {
-		return fmt.Errorf("application terminated by error: %w", err)
+	if l := os.Getenv("PROFILING_LABELS"); l != "" {
+		c.ProfilingLabels = map[string]string{}
+		for _, kv := range strings.Split(l, ",") {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return app.Config{}, errors.New("PROFILING_LABELS must be in the key=value format")
+			}
+			c.ProfilingLabels[k] = v
+		}
 	}
-	return nil
+	return c, nil
 }
 
-// telemetry initializes OpenTelemetry tracing and metrics providers.
-func (p *program) telemetry() (halt func(), err error) {
-	p.propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
-	var (
-		tr sdktrace.SpanExporter
-		mt sdkmetric.Exporter
-	)
-
-	// OTEL_EXPORTER can be used to configure whether to use the OpenTelemetry gRPC exporter protocol, stdout, or noop.
-	switch exporter, ok := os.LookupEnv("OTEL_EXPORTER"); {
-	case exporter == "stdout":
-		// Tip: Use stdouttrace.WithPrettyPrint() to print spans in human readable format.
-		if tr, err = stdouttrace.New(); err != nil {
-			return nil, fmt.Errorf("stdouttrace: %w", err)
-		}
-		if mt, err = stdoutmetric.New(stdoutmetric.WithEncoder(json.NewEncoder(os.Stdout))); err != nil {
-			return nil, fmt.Errorf("stdoutmetric: %w", err)
-		}
-	case exporter == "otlp":
-		if tr, err = otlptracegrpc.New(context.Background(), otlptracegrpc.WithTLSCredentials(insecure.NewCredentials())); err != nil {
-			return nil, fmt.Errorf("otlptracegrpc: %w", err)
-		}
-
-		if mt, err = otlpmetricgrpc.New(context.Background(), otlpmetricgrpc.WithTLSCredentials(insecure.NewCredentials())); err != nil {
-			return nil, fmt.Errorf("otlpmetricgrpc: %w", err)
+func run(tel *app.Telemetry) error {
+	// Set GOMAXPROCS to match Linux container CPU quota on Linux.
+	if runtime.GOOS == "linux" {
+		if _, err := maxprocs.Set(maxprocs.Logger(tel.Log.Info)); err != nil {
+			tel.Log.Error("cannot set GOMAXPROCS", slog.Any("error", err))
 		}
-	case ok:
-		p.log.Warn("unknown OTEL_EXPORTER value")
-		fallthrough
-	default:
-		p.tracer = tracenoop.NewTracerProvider()
-
-		p.meter = noop.NewMeterProvider()
-		return func() {}, nil
 	}
 
-	res, err := resource.New(context.Background(),
-		resource.WithAttributes(buildInfoTelemetry()...))
+	c, err := config()
 	if err != nil {
-		return nil, fmt.Errorf("cannot initialize tracer resource: %w", err)
+		return err
 	}
+	a := app.New(c, build, tel)
+	defer a.Close()
+	a.StartProbes()
 
-	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithResource(res), sdktrace.WithBatcher(tr))
-	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(mt)))
-	p.tracer = tp
-	p.meter = mp
-
-	// The following function will be called when the graceful shutdown starts.
-	return func() {
-		haltCtx, cancel := context.WithTimeout(context.Background(), time.Second)
-		defer cancel()
-		var w sync.WaitGroup
-		w.Add(2)
-		go func() {
-			defer w.Done()
-			if err := tp.Shutdown(haltCtx); err != nil {
-				p.log.Error("telemetry tracer shutdown", slog.Any("error", err))
-			}
-		}()
-		go func() {
-			defer w.Done()
-			if err := mp.Shutdown(haltCtx); err != nil {
-				p.log.Error("telemetry meter shutdown", slog.Any("error", err))
-			}
-		}()
-		w.Wait()
-	}, nil
+	// Waits for an internal error that shutdowns the server.
+	// Otherwise, wait for a SIGINT or SIGTERM and tries to shutdown the server gracefully.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return a.Run(ctx)
 }
@@ -0,0 +1,506 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AdminGRPC services for administrative operations.
+type AdminGRPC struct {
+	apipb.UnimplementedInventoryAdminServer
+	Inventory inventory.API
+
+	// DeadLetters of the outbox, if set.
+	DeadLetters outbox.DeadLetterQueue
+
+	// Token that must be sent as "authorization: Bearer <token>" metadata.
+	Token string
+}
+
+// authorize checks if the request carries the admin token.
+func (a *AdminGRPC) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(v, "Bearer ")
+		if ok && a.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid admin token")
+}
+
+// PurgeReviewerData deletes or anonymizes all reviews of a reviewer.
+func (a *AdminGRPC) PurgeReviewerData(ctx context.Context, req *apipb.PurgeReviewerDataRequest) (*apipb.PurgeReviewerDataResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	res, err := a.Inventory.PurgeReviewerData(ctx, inventory.PurgeReviewerDataParams{
+		ReviewerID: req.ReviewerId,
+		Anonymize:  req.Anonymize,
+	})
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.PurgeReviewerDataResponse{
+		Reviews: int32(res.Reviews),
+	}, nil
+}
+
+// GetProductAt returns a product as it was at a past moment.
+func (a *AdminGRPC) GetProductAt(ctx context.Context, req *apipb.GetProductAtRequest) (*apipb.GetProductAtResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	at, err := time.Parse(time.RFC3339, req.At)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid time: must be in RFC 3339 format")
+	}
+	p, err := a.Inventory.GetProductAt(ctx, req.Id, at)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	if p == nil {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	return &apipb.GetProductAtResponse{
+		Product: productProto(p),
+	}, nil
+}
+
+// GetProductHistory returns the versions of a product.
+func (a *AdminGRPC) GetProductHistory(ctx context.Context, req *apipb.GetProductHistoryRequest) (*apipb.HistoryResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	versions, err := a.Inventory.ProductHistory(ctx, req.Id)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return historyProto(versions)
+}
+
+// GetReviewHistory returns the versions of a product review.
+func (a *AdminGRPC) GetReviewHistory(ctx context.Context, req *apipb.GetReviewHistoryRequest) (*apipb.HistoryResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	versions, err := a.Inventory.ReviewHistory(ctx, req.Id)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return historyProto(versions)
+}
+
+// CreateSupplier creates a new supplier.
+func (a *AdminGRPC) CreateSupplier(ctx context.Context, req *apipb.CreateSupplierRequest) (*apipb.CreateSupplierResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	s, err := a.Inventory.CreateSupplier(ctx, inventory.CreateSupplierParams{
+		ID:    req.Id,
+		Name:  req.Name,
+		Email: req.Email,
+	})
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.CreateSupplierResponse{
+		Supplier: supplierProto(s),
+	}, nil
+}
+
+// UpdateSupplier updates an existing supplier.
+func (a *AdminGRPC) UpdateSupplier(ctx context.Context, req *apipb.UpdateSupplierRequest) (*apipb.UpdateSupplierResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	s, err := a.Inventory.UpdateSupplier(ctx, inventory.UpdateSupplierParams{
+		ID:    req.Id,
+		Name:  req.Name,
+		Email: req.Email,
+	})
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.UpdateSupplierResponse{
+		Supplier: supplierProto(s),
+	}, nil
+}
+
+// DeleteSupplier deletes a supplier, along with its relations to products.
+func (a *AdminGRPC) DeleteSupplier(ctx context.Context, req *apipb.DeleteSupplierRequest) (*apipb.DeleteSupplierResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.Inventory.DeleteSupplier(ctx, req.Id); err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.DeleteSupplierResponse{}, nil
+}
+
+// GetSupplier returns a supplier.
+func (a *AdminGRPC) GetSupplier(ctx context.Context, req *apipb.GetSupplierRequest) (*apipb.GetSupplierResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	s, err := a.Inventory.GetSupplier(ctx, req.Id)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	if s == nil {
+		return nil, status.Error(codes.NotFound, "supplier not found")
+	}
+	return &apipb.GetSupplierResponse{
+		Supplier: supplierProto(s),
+	}, nil
+}
+
+func supplierProto(s *inventory.Supplier) *apipb.Supplier {
+	return &apipb.Supplier{
+		Id:         s.ID,
+		Name:       s.Name,
+		Email:      s.Email,
+		CreatedAt:  s.CreatedAt.String(),
+		ModifiedAt: s.ModifiedAt.String(),
+	}
+}
+
+// SetProductSupplier adds a supplier to a product, or replaces its lead time and cost.
+func (a *AdminGRPC) SetProductSupplier(ctx context.Context, req *apipb.SetProductSupplierRequest) (*apipb.SetProductSupplierResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.Inventory.SetProductSupplier(ctx, inventory.ProductSupplier{
+		ProductID:  req.ProductId,
+		SupplierID: req.SupplierId,
+		LeadTime:   time.Duration(req.LeadTimeDays) * 24 * time.Hour,
+		Cost:       int(req.Cost),
+	}); err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.SetProductSupplierResponse{}, nil
+}
+
+// RemoveProductSupplier removes a supplier from a product.
+func (a *AdminGRPC) RemoveProductSupplier(ctx context.Context, req *apipb.RemoveProductSupplierRequest) (*apipb.RemoveProductSupplierResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.Inventory.RemoveProductSupplier(ctx, req.ProductId, req.SupplierId); err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.RemoveProductSupplierResponse{}, nil
+}
+
+// ListProductSuppliers returns the suppliers of a product.
+func (a *AdminGRPC) ListProductSuppliers(ctx context.Context, req *apipb.ListProductSuppliersRequest) (*apipb.ListProductSuppliersResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	suppliers, err := a.Inventory.ListProductSuppliers(ctx, req.ProductId)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	resp := &apipb.ListProductSuppliersResponse{
+		Suppliers: make([]*apipb.ProductSupplier, 0, len(suppliers)),
+	}
+	for _, s := range suppliers {
+		resp.Suppliers = append(resp.Suppliers, &apipb.ProductSupplier{
+			ProductId:    s.ProductID,
+			SupplierId:   s.SupplierID,
+			LeadTimeDays: int32(s.LeadTime / (24 * time.Hour)),
+			Cost:         int64(s.Cost),
+			ModifiedAt:   s.ModifiedAt.String(),
+		})
+	}
+	return resp, nil
+}
+
+// ListSupplierProducts returns the products of a supplier, regardless of their status.
+func (a *AdminGRPC) ListSupplierProducts(ctx context.Context, req *apipb.ListSupplierProductsRequest) (*apipb.ListProductsResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	products, err := a.Inventory.ListSupplierProducts(ctx, inventory.ListSupplierProductsParams{
+		SupplierID: req.SupplierId,
+		Pagination: listPagination(req.Page),
+	})
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return listProductsProto(products), nil
+}
+
+// CreateWarehouse creates a new warehouse.
+func (a *AdminGRPC) CreateWarehouse(ctx context.Context, req *apipb.CreateWarehouseRequest) (*apipb.CreateWarehouseResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	w, err := a.Inventory.CreateWarehouse(ctx, inventory.CreateWarehouseParams{
+		ID:   req.Id,
+		Name: req.Name,
+	})
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.CreateWarehouseResponse{
+		Warehouse: warehouseProto(w),
+	}, nil
+}
+
+// ListWarehouses returns all warehouses.
+func (a *AdminGRPC) ListWarehouses(ctx context.Context, req *apipb.ListWarehousesRequest) (*apipb.ListWarehousesResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	warehouses, err := a.Inventory.ListWarehouses(ctx)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	resp := &apipb.ListWarehousesResponse{
+		Warehouses: make([]*apipb.Warehouse, 0, len(warehouses)),
+	}
+	for _, w := range warehouses {
+		resp.Warehouses = append(resp.Warehouses, warehouseProto(w))
+	}
+	return resp, nil
+}
+
+func warehouseProto(w *inventory.Warehouse) *apipb.Warehouse {
+	return &apipb.Warehouse{
+		Id:         w.ID,
+		Name:       w.Name,
+		CreatedAt:  w.CreatedAt.String(),
+		ModifiedAt: w.ModifiedAt.String(),
+	}
+}
+
+// SetProductStock sets the quantity of a product in stock at a warehouse.
+func (a *AdminGRPC) SetProductStock(ctx context.Context, req *apipb.SetProductStockRequest) (*apipb.SetProductStockResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.Inventory.SetProductStock(ctx, inventory.SetProductStockParams{
+		ProductID:   req.ProductId,
+		WarehouseID: req.WarehouseId,
+		Quantity:    int(req.Quantity),
+	}); err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.SetProductStockResponse{}, nil
+}
+
+// TransferStock moves available units of a product from a warehouse to another.
+func (a *AdminGRPC) TransferStock(ctx context.Context, req *apipb.TransferStockRequest) (*apipb.TransferStockResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.Inventory.TransferStock(ctx, inventory.TransferStockParams{
+		ProductID: req.ProductId,
+		From:      req.FromWarehouseId,
+		To:        req.ToWarehouseId,
+		Quantity:  int(req.Quantity),
+	}); err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.TransferStockResponse{}, nil
+}
+
+// GetProductStock returns the stock levels of a product at each warehouse stocking it.
+func (a *AdminGRPC) GetProductStock(ctx context.Context, req *apipb.GetProductStockRequest) (*apipb.GetProductStockResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	levels, err := a.Inventory.GetProductStock(ctx, req.ProductId)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	resp := &apipb.GetProductStockResponse{
+		Levels: make([]*apipb.StockLevel, 0, len(levels)),
+	}
+	for _, l := range levels {
+		resp.Levels = append(resp.Levels, &apipb.StockLevel{
+			WarehouseId: l.WarehouseID,
+			Quantity:    int32(l.Quantity),
+			Reserved:    int32(l.Reserved),
+			ModifiedAt:  l.ModifiedAt.String(),
+		})
+		resp.Available += int64(l.Available())
+	}
+	return resp, nil
+}
+
+// deadLetters returns the dead letter queue, or an error if it isn't set.
+func (a *AdminGRPC) deadLetters() (outbox.DeadLetterQueue, error) {
+	if a.DeadLetters == nil {
+		return nil, status.Error(codes.Unimplemented, "dead letter queue is not available")
+	}
+	return a.DeadLetters, nil
+}
+
+// ListDeadLetters returns the events the outbox relay stopped trying to publish, the oldest first.
+func (a *AdminGRPC) ListDeadLetters(ctx context.Context, req *apipb.ListDeadLettersRequest) (*apipb.ListDeadLettersResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	q, err := a.deadLetters()
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.Limit)
+	switch {
+	case limit == 0:
+		limit = 100
+	case limit < 0 || limit > outbox.MaxDeadLetters:
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", outbox.MaxDeadLetters)
+	}
+	letters, err := q.ListDeadLetters(ctx, limit)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	total, err := q.CountDeadLetters(ctx)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	resp := &apipb.ListDeadLettersResponse{
+		DeadLetters: make([]*apipb.DeadLetter, 0, len(letters)),
+		Total:       int64(total),
+	}
+	for _, d := range letters {
+		resp.DeadLetters = append(resp.DeadLetters, deadLetterProto(d))
+	}
+	return resp, nil
+}
+
+// GetDeadLetter returns an event the outbox relay stopped trying to publish, with the reason it failed.
+func (a *AdminGRPC) GetDeadLetter(ctx context.Context, req *apipb.GetDeadLetterRequest) (*apipb.GetDeadLetterResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	q, err := a.deadLetters()
+	if err != nil {
+		return nil, err
+	}
+	d, err := q.GetDeadLetter(ctx, req.Id)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.GetDeadLetterResponse{
+		DeadLetter: deadLetterProto(*d),
+	}, nil
+}
+
+// ReplayDeadLetters moves events back to the outbox to be published again.
+// Either all events are replayed, or none is.
+func (a *AdminGRPC) ReplayDeadLetters(ctx context.Context, req *apipb.ReplayDeadLettersRequest) (*apipb.ReplayDeadLettersResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	q, err := a.deadLetters()
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Ids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing dead letter IDs")
+	}
+	if err := q.ReplayDeadLetters(ctx, req.Ids); err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.ReplayDeadLettersResponse{}, nil
+}
+
+// DeleteProducts deletes the products matching a filter in batches.
+// Deleting more than 100 products requires the confirmation token returned by a dry run.
+func (a *AdminGRPC) DeleteProducts(ctx context.Context, req *apipb.DeleteProductsRequest) (*apipb.DeleteProductsResponse, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	filter := inventory.ProductFilter{
+		Tenant:      req.Tenant,
+		Status:      inventory.ProductStatus(req.Status),
+		WithReviews: req.WithReviews,
+	}
+	if req.ModifiedBefore != "" {
+		t, err := time.Parse(time.RFC3339, req.ModifiedBefore)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid modified_before time: must be in RFC 3339 format")
+		}
+		filter.ModifiedBefore = t
+	}
+	res, err := a.Inventory.DeleteProducts(ctx, inventory.DeleteProductsParams{
+		Filter:       filter,
+		Limit:        int(req.Limit),
+		DryRun:       req.DryRun,
+		Confirmation: req.Confirmation,
+	})
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.DeleteProductsResponse{
+		Matched:      int32(res.Matched),
+		Deleted:      int32(res.Deleted),
+		Reviews:      int32(res.Reviews),
+		Confirmation: res.Confirmation,
+	}, nil
+}
+
+func deadLetterProto(d outbox.DeadLetter) *apipb.DeadLetter {
+	return &apipb.DeadLetter{
+		Id:             d.ID,
+		Topic:          d.Topic,
+		Payload:        string(d.Payload),
+		Attempts:       int32(d.Attempts),
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt.String(),
+		DeadLetteredAt: d.DeadLetteredAt.String(),
+	}
+}
+
+func historyProto(versions []inventory.Version) (*apipb.HistoryResponse, error) {
+	resp := &apipb.HistoryResponse{
+		Versions: make([]*apipb.Version, 0, len(versions)),
+	}
+	for _, v := range versions {
+		pv := &apipb.Version{
+			Operation: v.Operation,
+			ChangedAt: v.ChangedAt.String(),
+		}
+		var err error
+		if v.Data != nil {
+			if pv.Data, err = jsonString(v.Data); err != nil {
+				return nil, err
+			}
+		}
+		for _, c := range v.Changes {
+			pc := &apipb.Change{Field: c.Field}
+			if pc.Old, err = jsonString(c.Old); err != nil {
+				return nil, err
+			}
+			if pc.New, err = jsonString(c.New); err != nil {
+				return nil, err
+			}
+			pv.Changes = append(pv.Changes, pc)
+		}
+		resp.Versions = append(resp.Versions, pv)
+	}
+	return resp, nil
+}
+
+// jsonString encodes a value as JSON, or returns an empty string for nil.
+func jsonString(v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
@@ -7,12 +7,20 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"net/netip"
 	sync "sync"
 	"time"
 
 	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"github.com/henvic/pgxtutorial/internal/buildinfo"
+	"github.com/henvic/pgxtutorial/internal/faultinject"
 	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/loadshed"
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	"github.com/henvic/pgxtutorial/internal/replay"
+	"github.com/henvic/pgxtutorial/internal/slo"
 	"github.com/henvic/pgxtutorial/internal/telemetry"
+	"github.com/henvic/pgxtutorial/internal/usage"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/metric"
@@ -24,29 +32,103 @@ import (
 	"google.golang.org/grpc/reflection"
 )
 
+// Runner is a background worker managed by the Server, such as the outbox relay.
+type Runner interface {
+	// Run the worker until Shutdown is called.
+	// An error shuts down the Server, as for the listeners.
+	Run(ctx context.Context) error
+
+	// Shutdown the worker gracefully, draining its pending work until the context is done.
+	Shutdown(ctx context.Context)
+}
+
 // Server for the API.
 type Server struct {
+	// HTTPAddress and GRPCAddress to listen on. A listener without an address isn't started,
+	// such as for running only the workers.
 	HTTPAddress  string
 	GRPCAddress  string
 	ProbeAddress string
 
+	// Address to serve both gRPC and HTTP on, multiplexed as HTTP/2 cleartext (h2c),
+	// such as for deploying behind an L4 load balancer. If set, HTTPAddress and GRPCAddress are ignored.
+	Address string
+
+	// TrustedProxies in front of the server, such as load balancers, whose Forwarded and X-Forwarded-For headers
+	// are used to resolve the IP address of the client, and whose X-User-ID, X-Tenant-ID, and X-API-Key-ID headers
+	// identify the user and the caller authenticated by them. Headers from other peers are ignored.
+	TrustedProxies []netip.Prefix
+
+	// HTTPEnvelope wraps HTTP responses in an envelope, as in {"data": ..., "meta": ...} or {"error": ...}.
+	HTTPEnvelope bool
+
+	// SearchCacheMaxAge and SearchCacheStale set the Cache-Control header of HTTP product searches,
+	// if SearchCacheMaxAge is set, such as to match the cache of the inventory.
+	SearchCacheMaxAge time.Duration
+	SearchCacheStale  time.Duration
+
 	Log        *slog.Logger
 	Tracer     trace.TracerProvider
 	Meter      metric.MeterProvider
 	Propagator propagation.TextMapPropagator
 
-	Inventory *inventory.Service
+	Inventory inventory.API
+
+	// AdminToken authorizes calls to the InventoryAdmin gRPC service,
+	// and HTTP requests for the usage of any tenant or to adjust the quotas of the tenants.
+	// The service is only registered if the token is set.
+	AdminToken string
+
+	// DeadLetters of the outbox, inspected and replayed by the InventoryAdmin gRPC service, if set.
+	DeadLetters outbox.DeadLetterQueue
+
+	// BuildInfo exposed by the Build gRPC service.
+	BuildInfo buildinfo.Info
+
+	// SLO tracks the objectives of the gRPC methods, if set.
+	SLO *slo.Tracker
+
+	// SLOReadiness reports the gRPC server as not serving while the error budget of any method is exhausted,
+	// so traffic is routed to healthier instances.
+	SLOReadiness bool
+
+	// Limiter of the in-flight requests of each HTTP route and gRPC method, shedding the excess load, if set.
+	Limiter *loadshed.Limiter
+
+	// Faults injected into HTTP requests and gRPC calls, if set, such as for chaos testing.
+	// It must only be set outside of production.
+	Faults *faultinject.Injector
 
-	grpc  *grpcServer
-	http  *httpServer
-	probe *probeServer
+	// Recorder of a sample of the HTTP requests and responses, if set, to replay them against another environment.
+	// It must also be run as one of the Workers.
+	Recorder *replay.Recorder
+
+	// Usage of the callers identified by the trusted proxies, with their X-Tenant-ID and X-API-Key-ID headers,
+	// is accounted on the meter and served by the HTTP API, if set. It must also be run as one of the Workers.
+	Usage *usage.Meter
+
+	// Quotas of the tenants, adjusted by the admin with the HTTP API, if set.
+	Quotas inventory.QuotaStore
+
+	// Workers run along with the listeners.
+	// They're shut down after the listeners, so they can drain the work of the last requests.
+	Workers []Runner
+
+	grpc   *grpcServer
+	http   *httpServer
+	shared *sharedServer
+	probe  *probeServer
+	doc    *apiDoc
 
 	stopFn sync.Once
 }
 
-// Run starts the HTTP and gRPC servers.
+// Run starts the HTTP and gRPC servers, the probe server, and the workers.
 func (s *Server) Run(ctx context.Context) (err error) {
-	var ec = make(chan error, 3) // gRPC, HTTP, debug servers
+	var (
+		ec       = make(chan error, 3+len(s.Workers)) // gRPC, HTTP, debug servers, and workers
+		services int
+	)
 	ctx, cancel := context.WithCancel(ctx)
 
 	tel := telemetry.NewProvider(
@@ -55,32 +137,94 @@ func (s *Server) Run(ctx context.Context) (err error) {
 		s.Meter.Meter("api"),
 		s.Propagator)
 
-	s.grpc = &grpcServer{
-		inventory: s.Inventory,
-		tel:       *tel,
+	s.doc = &apiDoc{}
+	if s.Address != "" || s.HTTPAddress != "" {
+		s.doc.routes = HTTPRoutes()
 	}
-	s.http = &httpServer{
-		inventory: s.Inventory,
-		tel:       *tel,
+	newGRPCServer := func() *grpcServer {
+		return &grpcServer{
+			inventory:      s.Inventory,
+			adminToken:     s.AdminToken,
+			deadLetters:    s.DeadLetters,
+			buildInfo:      s.BuildInfo,
+			slo:            s.SLO,
+			readiness:      s.SLOReadiness,
+			trustedProxies: s.TrustedProxies,
+			limiter:        s.Limiter,
+			faults:         s.Faults,
+			usage:          s.Usage,
+			doc:            s.doc,
+			tel:            tel,
+		}
 	}
-	s.probe = &probeServer{
-		tel: *tel,
+	newHTTPServer := func() *httpServer {
+		return &httpServer{
+			inventory:      s.Inventory,
+			trustedProxies: s.TrustedProxies,
+			limiter:        s.Limiter,
+			faults:         s.Faults,
+			recorder:       s.Recorder,
+			usage:          s.Usage,
+			quotas:         s.Quotas,
+			adminToken:     s.AdminToken,
+			envelope:       s.HTTPEnvelope,
+			cacheMaxAge:    s.SearchCacheMaxAge,
+			cacheStale:     s.SearchCacheStale,
+			tel:            tel,
+		}
 	}
-
-	go func() {
-		err := s.grpc.Run(ctx, s.GRPCAddress, otelgrpc.WithMeterProvider(s.Meter), otelgrpc.WithTracerProvider(s.Tracer), otelgrpc.WithPropagators(s.Propagator))
-		if err != nil {
-			err = fmt.Errorf("gRPC server error: %w", err)
+	switch {
+	case s.Address != "":
+		s.shared = &sharedServer{
+			grpc: newGRPCServer(),
+			http: newHTTPServer(),
 		}
-		ec <- err
-	}()
-	go func() {
-		err := s.http.Run(ctx, s.HTTPAddress, otelhttp.WithMeterProvider(s.Meter), otelhttp.WithTracerProvider(s.Tracer), otelhttp.WithPropagators(s.Propagator))
-		if err != nil {
-			err = fmt.Errorf("HTTP server error: %w", err)
+	default:
+		if s.GRPCAddress != "" {
+			s.grpc = newGRPCServer()
 		}
-		ec <- err
-	}()
+		if s.HTTPAddress != "" {
+			s.http = newHTTPServer()
+		}
+	}
+	s.probe = &probeServer{
+		doc: s.doc,
+		tel: tel,
+	}
+	grpcOptions := []otelgrpc.Option{otelgrpc.WithMeterProvider(s.Meter), otelgrpc.WithTracerProvider(s.Tracer), otelgrpc.WithPropagators(s.Propagator)}
+	httpOptions := []otelhttp.Option{otelhttp.WithMeterProvider(s.Meter), otelhttp.WithTracerProvider(s.Tracer), otelhttp.WithPropagators(s.Propagator)}
+
+	if s.shared != nil {
+		services++
+		go func() {
+			err := s.shared.Run(ctx, s.Address, grpcOptions, httpOptions)
+			if err != nil {
+				err = fmt.Errorf("gRPC and HTTP server error: %w", err)
+			}
+			ec <- err
+		}()
+	}
+	if s.grpc != nil {
+		services++
+		go func() {
+			err := s.grpc.Run(ctx, s.GRPCAddress, grpcOptions...)
+			if err != nil {
+				err = fmt.Errorf("gRPC server error: %w", err)
+			}
+			ec <- err
+		}()
+	}
+	if s.http != nil {
+		services++
+		go func() {
+			err := s.http.Run(ctx, s.HTTPAddress, httpOptions...)
+			if err != nil {
+				err = fmt.Errorf("HTTP server error: %w", err)
+			}
+			ec <- err
+		}()
+	}
+	services++
 	go func() {
 		err := s.probe.Run(ctx, s.ProbeAddress)
 		if err != nil {
@@ -88,10 +232,20 @@ func (s *Server) Run(ctx context.Context) (err error) {
 		}
 		ec <- err
 	}()
+	for _, w := range s.Workers {
+		services++
+		go func() {
+			err := w.Run(ctx)
+			if err != nil {
+				err = fmt.Errorf("worker error: %w", err)
+			}
+			ec <- err
+		}()
+	}
 
 	// Wait for the services to exit.
 	var es []error
-	for i := 0; i < cap(ec); i++ {
+	for i := 0; i < services; i++ {
 		if err := <-ec; err != nil {
 			es = append(es, err)
 			// If one of the services returns by a reason other than parent context canceled,
@@ -108,19 +262,49 @@ func (s *Server) Run(ctx context.Context) (err error) {
 	return errors.Join(es...)
 }
 
-// Shutdown HTTP and gRPC servers.
+// Shutdown HTTP and gRPC servers, and then the workers.
 func (s *Server) Shutdown(ctx context.Context) {
 	// Don't try to start a graceful shutdown multiple times.
 	s.stopFn.Do(func() {
-		s.http.Shutdown(ctx)
-		s.grpc.Shutdown(ctx)
+		if s.http != nil {
+			s.http.Shutdown(ctx)
+		}
+		if s.grpc != nil {
+			s.grpc.Shutdown(ctx)
+		}
+		if s.shared != nil {
+			s.shared.Shutdown(ctx)
+		}
+
+		// Listeners are shut down first, so no new work is created while the workers drain.
+		var wg sync.WaitGroup
+		for _, w := range s.Workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w.Shutdown(ctx)
+			}()
+		}
+		wg.Wait()
+
+		// The probe server is kept until the end for profiling the shutdown.
 		s.probe.Shutdown(ctx)
 	})
 }
 
 type httpServer struct {
-	inventory *inventory.Service
-	tel       telemetry.Provider
+	inventory      inventory.API
+	trustedProxies []netip.Prefix
+	limiter        *loadshed.Limiter
+	faults         *faultinject.Injector
+	recorder       *replay.Recorder
+	usage          *usage.Meter
+	quotas         inventory.QuotaStore
+	adminToken     string
+	envelope       bool
+	cacheMaxAge    time.Duration
+	cacheStale     time.Duration
+	tel            telemetry.Provider
 
 	middleware func(http.Handler) http.Handler
 	http       *http.Server
@@ -128,16 +312,9 @@ type httpServer struct {
 
 // Run HTTP server.
 func (s *httpServer) Run(ctx context.Context, address string, otelOptions ...otelhttp.Option) error {
-	handler := NewHTTPServer(s.inventory, s.tel)
-
-	// Inject middleware, if the middleware field is set.
-	if s.middleware != nil {
-		handler = s.middleware(handler)
-	}
-
 	s.http = &http.Server{
 		Addr:    address,
-		Handler: otelhttp.NewHandler(handler, "api", otelOptions...),
+		Handler: s.handler(otelOptions...),
 
 		ReadHeaderTimeout: 5 * time.Second, // mitigate risk of Slowloris Attack
 	}
@@ -148,6 +325,47 @@ func (s *httpServer) Run(ctx context.Context, address string, otelOptions ...ote
 	return nil
 }
 
+// handler of the HTTP API.
+func (s *httpServer) handler(otelOptions ...otelhttp.Option) http.Handler {
+	var opts []HTTPOption
+	if s.envelope {
+		opts = append(opts, WithEnvelope())
+	}
+	if s.cacheMaxAge > 0 {
+		opts = append(opts, WithSearchCacheControl(s.cacheMaxAge, s.cacheStale))
+	}
+	if s.adminToken != "" {
+		opts = append(opts, WithAdminToken(s.adminToken))
+	}
+	if s.usage != nil {
+		opts = append(opts, WithUsage(s.usage.Store))
+	}
+	if s.quotas != nil {
+		opts = append(opts, WithQuotas(s.quotas))
+	}
+	mux := NewHTTPServer(s.inventory, s.tel, opts...)
+	var handler http.Handler = mux
+	if s.limiter != nil {
+		handler = loadSheddingHandler(s.limiter, mux)
+	}
+
+	// Inject middleware, if the middleware field is set.
+	if s.middleware != nil {
+		handler = s.middleware(handler)
+	}
+	if s.faults != nil {
+		handler = s.faults.Handler(handler)
+	}
+	if s.recorder != nil {
+		handler = s.recorder.Handler(handler)
+	}
+	if s.usage != nil {
+		handler = usageHandler(s.usage, handler)
+	}
+	handler = callerHandler(s.trustedProxies, userHandler(s.trustedProxies, handler))
+	return otelhttp.NewHandler(baggageHandler(clientIPHandler(s.trustedProxies, handler)), "api", otelOptions...)
+}
+
 // Shutdown HTTP server.
 func (s *httpServer) Shutdown(ctx context.Context) {
 	s.tel.Logger().Info("shutting down HTTP server")
@@ -158,42 +376,153 @@ func (s *httpServer) Shutdown(ctx context.Context) {
 	}
 }
 
+// loadSheddingHandler rejects requests with 503 Service Unavailable while their route is at its concurrency limit.
+// Requests not matching a route aren't limited.
+func loadSheddingHandler(limiter *loadshed.Limiter, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			release, ok := limiter.Acquire(pattern)
+			if !ok {
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "Server overloaded, try again later", http.StatusServiceUnavailable)
+				return
+			}
+			defer release()
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// baggageHandler extracts the telemetry.BaggageKeys request attributes.
+// It must be wrapped by the otelhttp handler, which extracts the baggage and starts the span.
+func baggageHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(telemetry.ContextWithBaggageAttributes(r.Context(), telemetry.BaggageKeys...)))
+	})
+}
+
 type grpcServer struct {
-	inventory *inventory.Service
-	grpc      *grpc.Server
-	health    *health.Server
-	tel       telemetry.Provider
+	inventory      inventory.API
+	adminToken     string
+	deadLetters    outbox.DeadLetterQueue
+	buildInfo      buildinfo.Info
+	slo            *slo.Tracker
+	readiness      bool
+	trustedProxies []netip.Prefix
+	limiter        *loadshed.Limiter
+	faults         *faultinject.Injector
+	usage          *usage.Meter
+	doc            *apiDoc
+	grpc           *grpc.Server
+	health         *health.Server
+	tel            telemetry.Provider
 }
 
 // Run gRPC server.
 func (s *grpcServer) Run(ctx context.Context, address string, oo ...otelgrpc.Option) error {
-	s.health = health.NewServer()
-
 	var lc net.ListenConfig
 	lis, err := lc.Listen(ctx, "tcp", address)
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
+	s.setup(ctx, oo...)
+	s.tel.Logger().Info("gRPC server listening", slog.Any("address", lis.Addr()))
+	if err := s.grpc.Serve(lis); err != nil {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// setup the gRPC server and its services.
+func (s *grpcServer) setup(ctx context.Context, oo ...otelgrpc.Option) {
+	s.health = health.NewServer()
+	interceptors := []grpc.UnaryServerInterceptor{
+		clientIPUnaryInterceptor(s.trustedProxies),
+		userUnaryInterceptor(s.trustedProxies),
+		callerUnaryInterceptor(s.trustedProxies),
+		baggageUnaryInterceptor,
+	}
+	var streamInterceptors []grpc.StreamServerInterceptor
+	// Calls are accounted before faults are injected and the load is shed, as callers are charged for rejected calls too.
+	if s.usage != nil {
+		interceptors = append(interceptors, usageUnaryInterceptor(s.usage))
+		streamInterceptors = append(streamInterceptors, usageStreamInterceptor(s.usage, s.trustedProxies))
+	}
+	if s.faults != nil {
+		interceptors = append(interceptors, s.faults.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, s.faults.StreamServerInterceptor())
+	}
+	// Shed calls are rejected before being recorded on the SLO tracker, as they don't reach the handlers.
+	if s.limiter != nil {
+		interceptors = append(interceptors, loadSheddingUnaryInterceptor(s.limiter))
+	}
+	if s.slo != nil {
+		interceptors = append(interceptors, sloUnaryInterceptor(s.slo))
+	}
+	interceptors = append(interceptors, recoveryUnaryInterceptor(s.tel.Logger()))
+	if s.limiter != nil {
+		streamInterceptors = append(streamInterceptors, loadSheddingStreamInterceptor(s.limiter))
+	}
+	streamInterceptors = append(streamInterceptors, recoveryStreamInterceptor(s.tel.Logger()))
 	s.grpc = grpc.NewServer(
 		grpc.StatsHandler(otelgrpc.NewServerHandler(oo...)),
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
 	)
 	reflection.Register(s.grpc)
 	grpc_health_v1.RegisterHealthServer(s.grpc, s.health)
 	apipb.RegisterInventoryServer(s.grpc, &InventoryGRPC{
 		Inventory: s.inventory,
+		Log:       s.tel.Logger(),
 	})
+	apipb.RegisterBuildServer(s.grpc, &BuildGRPC{
+		Info: s.buildInfo,
+	})
+	if s.adminToken != "" {
+		apipb.RegisterInventoryAdminServer(s.grpc, &AdminGRPC{
+			Inventory:   s.inventory,
+			DeadLetters: s.deadLetters,
+			Token:       s.adminToken,
+		})
+	}
+	if s.doc != nil {
+		s.doc.setServices(s.grpc.GetServiceInfo())
+	}
 	s.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
-	s.tel.Logger().Info("gRPC server listening", slog.Any("address", lis.Addr()))
-	if err := s.grpc.Serve(lis); err != nil {
-		return fmt.Errorf("failed to serve: %w", err)
+	if s.slo != nil && s.readiness {
+		go s.sloReadiness(ctx)
+	}
+}
+
+// sloReadiness updates the serving status according to the error budget, until the context is canceled.
+func (s *grpcServer) sloReadiness(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	serving := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		switch exhausted := s.slo.Exhausted(); {
+		case len(exhausted) != 0 && serving:
+			s.tel.Logger().Warn("error budget exhausted, reporting as not serving", slog.Any("methods", exhausted))
+			s.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+			serving = false
+		case len(exhausted) == 0 && !serving:
+			s.tel.Logger().Info("error budget recovered, reporting as serving")
+			s.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+			serving = true
+		}
 	}
-	return nil
 }
 
 // Shutdown gRPC server.
 func (s *grpcServer) Shutdown(ctx context.Context) {
 	s.tel.Logger().Info("shutting down gRPC server")
-	s.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	// Shutdown sets the serving status to NOT_SERVING, and ignores later updates from sloReadiness.
+	s.health.Shutdown()
 	done := make(chan struct{}, 1)
 	go func() {
 		if s.grpc != nil {
@@ -211,17 +540,23 @@ func (s *grpcServer) Shutdown(ctx context.Context) {
 	}
 }
 
-// probeServer runs an HTTP server exposing pprof endpoints.
+// probeServer runs an HTTP server exposing pprof endpoints, the API index, and the event catalog.
 type probeServer struct {
 	http *http.Server
+	doc  *apiDoc
 	tel  telemetry.Provider
 }
 
 // Run HTTP pprof server.
 func (s *probeServer) Run(ctx context.Context, address string) error {
-	// Use http.DefaultServeMux, rather than defining a custom mux.
+	// Serve the API index and the event catalog, and leave everything else to http.DefaultServeMux, where the profilers are registered.
+	mux := http.NewServeMux()
+	mux.Handle("GET /_api", s.doc)
+	mux.HandleFunc("GET /_events", serveEventCatalog)
+	mux.Handle("/", http.DefaultServeMux)
 	s.http = &http.Server{
-		Addr: address,
+		Addr:    address,
+		Handler: mux,
 
 		ReadHeaderTimeout: 5 * time.Second, // mitigate risk of Slowloris Attack
 	}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/henvic/pgxtutorial/internal/events"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// apiDoc serves an index of the gRPC services and HTTP routes of the API, to discover it without external tools.
+// It's served at /_api on the probe server, as HTML, or as JSON if requested by the Accept header.
+type apiDoc struct {
+	services atomic.Pointer[[]rpcService]
+	routes   []string
+}
+
+// apiIndex of the API.
+type apiIndex struct {
+	Services []rpcService `json:"services"`
+	Routes   []string     `json:"routes"`
+}
+
+// rpcService exposed by the gRPC server.
+type rpcService struct {
+	Name       string      `json:"name"`
+	Deprecated bool        `json:"deprecated,omitempty"`
+	Methods    []rpcMethod `json:"methods"`
+}
+
+// rpcMethod of a gRPC service.
+type rpcMethod struct {
+	Name            string `json:"name"`
+	Request         string `json:"request,omitempty"`
+	Response        string `json:"response,omitempty"`
+	ClientStreaming bool   `json:"client_streaming,omitempty"`
+	ServerStreaming bool   `json:"server_streaming,omitempty"`
+	Deprecated      bool   `json:"deprecated,omitempty"`
+}
+
+// setServices describes the services registered on a gRPC server, once it's set up.
+// The request and response types and the deprecation annotations are taken from the descriptors
+// registered by the generated code, which are the ones served by the gRPC reflection service.
+func (d *apiDoc) setServices(info map[string]grpc.ServiceInfo) {
+	services := make([]rpcService, 0, len(info))
+	for name, si := range info {
+		service := rpcService{Name: name}
+		sd := findService(name)
+		if sd != nil {
+			opts, _ := sd.Options().(*descriptorpb.ServiceOptions)
+			service.Deprecated = opts.GetDeprecated()
+		}
+		for _, mi := range si.Methods {
+			method := rpcMethod{
+				Name:            mi.Name,
+				ClientStreaming: mi.IsClientStream,
+				ServerStreaming: mi.IsServerStream,
+			}
+			if sd != nil {
+				if md := sd.Methods().ByName(protoreflect.Name(mi.Name)); md != nil {
+					method.Request = string(md.Input().FullName())
+					method.Response = string(md.Output().FullName())
+					opts, _ := md.Options().(*descriptorpb.MethodOptions)
+					method.Deprecated = opts.GetDeprecated()
+				}
+			}
+			service.Methods = append(service.Methods, method)
+		}
+		slices.SortFunc(service.Methods, func(a, b rpcMethod) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		services = append(services, service)
+	}
+	slices.SortFunc(services, func(a, b rpcService) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	d.services.Store(&services)
+}
+
+// findService descriptor by its full name, or nil if it's not registered.
+func findService(name string) protoreflect.ServiceDescriptor {
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil
+	}
+	sd, _ := d.(protoreflect.ServiceDescriptor)
+	return sd
+}
+
+func (d *apiDoc) index() apiIndex {
+	index := apiIndex{
+		Services: []rpcService{},
+		Routes:   d.routes,
+	}
+	if services := d.services.Load(); services != nil {
+		index.Services = *services
+	}
+	if index.Routes == nil {
+		index.Routes = []string{}
+	}
+	return index
+}
+
+func (d *apiDoc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		_ = enc.Encode(d.index())
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = apiDocHTML.Execute(w, d.index())
+}
+
+// serveEventCatalog serves the catalog of the events emitted as JSON, for integrators to discover them.
+// It's served at /_events on the probe server.
+func serveEventCatalog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	_ = enc.Encode(events.NewCatalog())
+}
+
+var apiDocHTML = htmltemplate.Must(htmltemplate.New("api").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>API</title></head>
+<body>
+<h1>API</h1>
+<h2>gRPC services</h2>
+{{range .Services}}<h3 id="{{.Name}}">{{.Name}}{{if .Deprecated}} (deprecated){{end}}</h3>
+<table>
+<tr><th>Method</th><th>Request</th><th>Response</th></tr>
+{{range .Methods}}<tr><td>{{if .Deprecated}}<del>{{.Name}}</del> (deprecated){{else}}{{.Name}}{{end}}</td><td>{{if .ClientStreaming}}stream {{end}}<code>{{.Request}}</code></td><td>{{if .ServerStreaming}}stream {{end}}<code>{{.Response}}</code></td></tr>
+{{end}}</table>
+{{else}}<p>The gRPC server isn't running.</p>
+{{end}}<h2>HTTP routes</h2>
+{{with .Routes}}<ul>
+{{range .}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{else}}<p>The HTTP server isn't running.</p>
+{{end}}</body>
+</html>
+`))
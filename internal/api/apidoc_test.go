@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"github.com/henvic/pgxtutorial/internal/events"
+	"google.golang.org/grpc"
+)
+
+func TestAPIDoc(t *testing.T) {
+	t.Parallel()
+	server := grpc.NewServer()
+	apipb.RegisterInventoryServer(server, &InventoryGRPC{})
+	doc := &apiDoc{routes: HTTPRoutes()}
+	doc.setServices(server.GetServiceInfo())
+
+	req := httptest.NewRequest(http.MethodGet, "/_api", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	doc.ServeHTTP(w, req)
+	var index apiIndex
+	if err := json.Unmarshal(w.Body.Bytes(), &index); err != nil {
+		t.Fatalf("cannot decode API index: %v", err)
+	}
+	if len(index.Services) != 1 || index.Services[0].Name != "api.v1.Inventory" {
+		t.Fatalf("unexpected services: %+v", index.Services)
+	}
+	i := slices.IndexFunc(index.Services[0].Methods, func(m rpcMethod) bool { return m.Name == "GetProduct" })
+	if i == -1 {
+		t.Fatal("GetProduct method not listed")
+	}
+	if m := index.Services[0].Methods[i]; m.Request != "api.v1.GetProductRequest" || m.Response != "api.v1.GetProductResponse" {
+		t.Errorf("unexpected GetProduct method: %+v", m)
+	}
+	if !slices.Contains(index.Routes, "GET /products") {
+		t.Errorf("GET /products route not listed: %v", index.Routes)
+	}
+
+	w = httptest.NewRecorder()
+	doc.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_api", nil))
+	if body := w.Body.String(); !strings.Contains(body, "<h3 id=\"api.v1.Inventory\">") || !strings.Contains(body, "GET /products") {
+		t.Errorf("unexpected HTML index: %s", body)
+	}
+}
+
+func TestEventCatalog(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	serveEventCatalog(w, httptest.NewRequest(http.MethodGet, "/_events", nil))
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var catalog events.Catalog
+	if err := json.Unmarshal(w.Body.Bytes(), &catalog); err != nil {
+		t.Fatalf("cannot decode event catalog: %v", err)
+	}
+	i := slices.IndexFunc(catalog.Types, func(typ events.Type) bool { return typ.Topic == "order.paid" })
+	if i == -1 {
+		t.Fatalf("order.paid event not listed: %+v", catalog.Types)
+	}
+	if typ := catalog.Types[i]; typ.Channel != events.ChannelOutbox || len(typ.Fields) == 0 || len(typ.Schema) == 0 {
+		t.Errorf("unexpected order.paid event: %+v", typ)
+	}
+}
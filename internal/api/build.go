@@ -0,0 +1,25 @@
+package api
+
+import (
+	"context"
+
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"github.com/henvic/pgxtutorial/internal/buildinfo"
+)
+
+// BuildGRPC services exposing metadata about the running binary.
+type BuildGRPC struct {
+	apipb.UnimplementedBuildServer
+	Info buildinfo.Info
+}
+
+// GetBuildInfo returns the build info.
+func (b *BuildGRPC) GetBuildInfo(ctx context.Context, req *apipb.GetBuildInfoRequest) (*apipb.GetBuildInfoResponse, error) {
+	return &apipb.GetBuildInfoResponse{
+		Version:   b.Info.Version,
+		Revision:  b.Info.Revision,
+		Time:      b.Info.Time,
+		Modified:  b.Info.Modified,
+		GoVersion: b.Info.GoVersion,
+	}, nil
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/henvic/pgxtutorial/internal/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ParseTrustedProxies parses a comma-separated list of IP addresses and CIDR prefixes of trusted proxies,
+// such as "10.0.0.0/8,192.0.2.1".
+func ParseTrustedProxies(s string) ([]netip.Prefix, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var proxies []netip.Prefix
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// clientIP resolves the IP address of the client of a request from the address of the peer,
+// and the Forwarded (RFC 7239) or X-Forwarded-For headers appended by the trusted proxies.
+// Forwarded takes precedence over X-Forwarded-For.
+//
+// The headers are read from right to left, as the leftmost entries might be forged by the client:
+// the first address that isn't of a trusted proxy is the client's.
+// If an entry can't be parsed, the address of the last trusted proxy is returned.
+func clientIP(trusted []netip.Prefix, remote netip.Addr, forwarded, xff []string) netip.Addr {
+	addr := remote.Unmap()
+	if !isTrustedProxy(trusted, addr) {
+		return addr
+	}
+	hops := forwardedFor(forwarded)
+	if len(forwarded) == 0 {
+		hops = headerList(xff)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, ok := parseHostAddr(hops[i])
+		if !ok {
+			return addr
+		}
+		addr = hop
+		if !isTrustedProxy(trusted, addr) {
+			return addr
+		}
+	}
+	return addr
+}
+
+// isTrustedProxy checks if the address is of a trusted proxy.
+func isTrustedProxy(trusted []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerList splits the comma-separated values of a header.
+func headerList(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, e := range strings.Split(v, ",") {
+			list = append(list, strings.TrimSpace(e))
+		}
+	}
+	return list
+}
+
+// forwardedFor returns the "for" parameters of the elements of Forwarded headers.
+// Elements without it are returned as empty, so they aren't skipped.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range headerList(values) {
+		var hop string
+		for _, pair := range strings.Split(element, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			if strings.EqualFold(k, "for") {
+				hop = strings.Trim(v, `"`)
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// parseHostAddr parses an IP address, optionally with a port, and with IPv6 addresses in brackets if so.
+// Obfuscated identifiers and "unknown" aren't addresses.
+func parseHostAddr(s string) (netip.Addr, bool) {
+	if ap, err := netip.ParseAddrPort(s); err == nil {
+		return ap.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// clientIPHandler sets the client IP address of the request, resolved from the trusted proxies, on its context.
+func clientIPHandler(trusted []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote, ok := parseHostAddr(r.RemoteAddr)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ip := clientIP(trusted, remote, r.Header.Values("Forwarded"), r.Header.Values("X-Forwarded-For"))
+		next.ServeHTTP(w, r.WithContext(telemetry.ContextWithClientIP(r.Context(), ip)))
+	})
+}
+
+// clientIPUnaryInterceptor sets the client IP address of the call, resolved from the trusted proxies, on its context.
+func clientIPUnaryInterceptor(trusted []netip.Prefix) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return handler(ctx, req)
+		}
+		remote, ok := parseHostAddr(p.Addr.String())
+		if !ok {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		ip := clientIP(trusted, remote, md.Get("forwarded"), md.Get("x-forwarded-for"))
+		return handler(telemetry.ContextWithClientIP(ctx, ip), req)
+	}
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		s       string
+		want    []netip.Prefix
+		wantErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "prefixes_and_addresses",
+			s:    "10.0.0.0/8, 192.0.2.1,2001:db8::/32,10.1.2.3/16",
+			want: []netip.Prefix{
+				netip.MustParsePrefix("10.0.0.0/8"),
+				netip.MustParsePrefix("192.0.2.1/32"),
+				netip.MustParsePrefix("2001:db8::/32"),
+				netip.MustParsePrefix("10.1.0.0/16"),
+			},
+		},
+		{
+			name:    "invalid_address",
+			s:       "10.0.0.0/8,localhost",
+			wantErr: `invalid trusted proxy "localhost": ParseAddr("localhost"): unable to parse IP`,
+		},
+		{
+			name:    "invalid_prefix",
+			s:       "10.0.0.0/33",
+			wantErr: `invalid trusted proxy "10.0.0.0/33": netip.ParsePrefix("10.0.0.0/33"): prefix length out of range`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseTrustedProxies(tt.s)
+			if err == nil && tt.wantErr != "" || err != nil && err.Error() != tt.wantErr {
+				t.Errorf("ParseTrustedProxies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseTrustedProxies() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseTrustedProxies() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+	tests := []struct {
+		name      string
+		trusted   []netip.Prefix
+		remote    string
+		forwarded []string
+		xff       []string
+		want      string
+	}{
+		{
+			name:   "direct",
+			remote: "203.0.113.7",
+			want:   "203.0.113.7",
+		},
+		{
+			name:   "untrusted_peer",
+			remote: "203.0.113.7",
+			xff:    []string{"198.51.100.1"},
+			want:   "203.0.113.7",
+		},
+		{
+			name:    "no_trusted_proxies",
+			trusted: []netip.Prefix{},
+			remote:  "10.0.0.1",
+			xff:     []string{"198.51.100.1"},
+			want:    "10.0.0.1",
+		},
+		{
+			name:   "x_forwarded_for",
+			remote: "10.0.0.1",
+			xff:    []string{"198.51.100.1"},
+			want:   "198.51.100.1",
+		},
+		{
+			name:   "x_forwarded_for_spoofed",
+			remote: "10.0.0.1",
+			xff:    []string{"192.0.2.99, 198.51.100.1", "10.0.0.2"},
+			want:   "198.51.100.1",
+		},
+		{
+			name:   "x_forwarded_for_all_trusted",
+			remote: "10.0.0.1",
+			xff:    []string{"10.0.0.3, 10.0.0.2"},
+			want:   "10.0.0.3",
+		},
+		{
+			name:   "x_forwarded_for_invalid",
+			remote: "10.0.0.1",
+			xff:    []string{"198.51.100.1, garbage, 10.0.0.2"},
+			want:   "10.0.0.2",
+		},
+		{
+			name:   "ipv4_mapped_peer",
+			remote: "::ffff:10.0.0.1",
+			xff:    []string{"198.51.100.1"},
+			want:   "198.51.100.1",
+		},
+		{
+			name:      "forwarded",
+			remote:    "10.0.0.1",
+			forwarded: []string{`for=192.0.2.99;proto=https, for="[2001:db8:cafe::17]:4711"`, "For=198.51.100.1:443;by=10.0.0.1"},
+			xff:       []string{"192.0.2.1"},
+			want:      "198.51.100.1",
+		},
+		{
+			name:      "forwarded_ipv6",
+			remote:    "2001:db8::1",
+			forwarded: []string{`for="[2001:db9::17]:4711"`},
+			want:      "2001:db9::17",
+		},
+		{
+			name:      "forwarded_obfuscated",
+			remote:    "10.0.0.1",
+			forwarded: []string{"for=_hidden, for=10.0.0.2"},
+			want:      "10.0.0.2",
+		},
+		{
+			name:      "forwarded_without_for",
+			remote:    "10.0.0.1",
+			forwarded: []string{"proto=https"},
+			xff:       []string{"198.51.100.1"},
+			want:      "10.0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			proxies := trusted
+			if tt.trusted != nil {
+				proxies = tt.trusted
+			}
+			got := clientIP(proxies, netip.MustParseAddr(tt.remote), tt.forwarded, tt.xff)
+			if want := netip.MustParseAddr(tt.want); got != want {
+				t.Errorf("clientIP() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -3,22 +3,28 @@ package api
 import (
 	"context"
 	"errors"
+	"log/slog"
 
 	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
 	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // InventoryGRPC services.
 type InventoryGRPC struct {
 	apipb.UnimplementedInventoryServer
-	Inventory *inventory.Service
+	Inventory inventory.API
+	Log       *slog.Logger
 }
 
 func (i *InventoryGRPC) SearchProducts(ctx context.Context, req *apipb.SearchProductsRequest) (*apipb.SearchProductsResponse, error) {
 	params := inventory.SearchProductsParams{
 		QueryString: req.QueryString,
+		SKUPrefix:   req.SkuPrefix,
 	}
 	if req.MinPrice != nil {
 		params.MinPrice = int(*req.MinPrice)
@@ -41,12 +47,7 @@ func (i *InventoryGRPC) SearchProducts(ctx context.Context, req *apipb.SearchPro
 
 	items := []*apipb.Product{}
 	for _, p := range products.Items {
-		items = append(items, &apipb.Product{
-			Id:          p.ID,
-			Price:       int64(p.Price),
-			Name:        p.Name,
-			Description: p.Description,
-		})
+		items = append(items, productProto(p))
 	}
 	return &apipb.SearchProductsResponse{
 		Total: int32(products.Total),
@@ -54,17 +55,176 @@ func (i *InventoryGRPC) SearchProducts(ctx context.Context, req *apipb.SearchPro
 	}, nil
 }
 
+// Batching of the products streamed by SearchProductsStream.
+const (
+	defaultStreamBatchSize = 100
+	maxStreamBatchSize     = 1000
+
+	// maxStreamChunkBytes limits the size of each response, well below the 4 MiB gRPC clients receive by default,
+	// so a batch of products with long descriptions doesn't exceed it.
+	maxStreamChunkBytes = 1 << 20
+)
+
+// SearchProductsStream streams the products found by a search in batches, as they're read from the database,
+// rather than reading them all into memory first. Up to inventory.MaxExportedProducts products are streamed.
+//
+// Sending a batch blocks while the client isn't ready to receive it, as per HTTP/2 flow control,
+// which in turn stops reading products from the database until it is,
+// so a slow client doesn't make the server buffer the export.
+func (i *InventoryGRPC) SearchProductsStream(req *apipb.SearchProductsStreamRequest, stream apipb.Inventory_SearchProductsStreamServer) error {
+	size := int(req.BatchSize)
+	switch {
+	case size == 0:
+		size = defaultStreamBatchSize
+	case size < 0 || size > maxStreamBatchSize:
+		return status.Errorf(codes.InvalidArgument, "batch size must be between 1 and %d", maxStreamBatchSize)
+	}
+	params := inventory.SearchProductsParams{
+		QueryString: req.QueryString,
+		SKUPrefix:   req.SkuPrefix,
+		Text:        req.Text,
+		Pagination:  inventory.Pagination{Limit: int(req.Limit)},
+	}
+	if req.MinPrice != nil {
+		params.MinPrice = int(*req.MinPrice)
+	}
+	if req.MaxPrice != nil {
+		params.MaxPrice = int(*req.MaxPrice)
+	}
+	b := &productBatcher{send: stream.Send, size: size}
+	err := i.Inventory.ExportProducts(stream.Context(), params, b.add)
+	if err == nil {
+		err = b.flush()
+	}
+	if err != nil {
+		return grpcAPIError(err)
+	}
+	return nil
+}
+
+// productBatcher groups products into responses of up to size products, and maxStreamChunkBytes.
+type productBatcher struct {
+	send func(*apipb.SearchProductsStreamResponse) error
+	size int
+
+	items []*apipb.Product
+	bytes int
+}
+
+// add a product to the batch, sending the batch once it's full.
+func (b *productBatcher) add(p *inventory.Product) error {
+	item := productProto(p)
+	n := proto.Size(item)
+	if len(b.items) != 0 && b.bytes+n > maxStreamChunkBytes {
+		if err := b.flush(); err != nil {
+			return err
+		}
+	}
+	b.items = append(b.items, item)
+	b.bytes += n
+	if len(b.items) == b.size {
+		return b.flush()
+	}
+	return nil
+}
+
+// flush sends the products added since the last batch was sent, if any.
+func (b *productBatcher) flush() error {
+	if len(b.items) == 0 {
+		return nil
+	}
+	err := b.send(&apipb.SearchProductsStreamResponse{Items: b.items})
+	b.items, b.bytes = nil, 0
+	return err
+}
+
 // CreateProduct on the inventory.
 func (i *InventoryGRPC) CreateProduct(ctx context.Context, req *apipb.CreateProductRequest) (*apipb.CreateProductResponse, error) {
-	if err := i.Inventory.CreateProduct(ctx, inventory.CreateProductParams{
+	res, err := i.Inventory.CreateProduct(ctx, inventory.CreateProductParams{
 		ID:          req.Id,
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       int(req.Price),
-	}); err != nil {
+		Status:      inventory.ProductStatus(req.Status),
+		SKU:         req.Sku,
+		GTIN:        req.Gtin,
+		TaxClass:    req.TaxClass,
+	})
+	if err != nil {
 		return nil, grpcAPIError(err)
 	}
-	return &apipb.CreateProductResponse{}, nil
+	if !res.Created {
+		// Return the existing product as an error detail, so clients don't need another round trip to get it.
+		st, err := status.New(codes.AlreadyExists, "product already exists").WithDetails(productProto(res.Product))
+		if err != nil {
+			return nil, status.Error(codes.AlreadyExists, "product already exists")
+		}
+		return nil, st.Err()
+	}
+	return &apipb.CreateProductResponse{
+		Product: productProto(res.Product),
+	}, nil
+}
+
+// ListTrendingProducts returns the most viewed products.
+func (i *InventoryGRPC) ListTrendingProducts(ctx context.Context, req *apipb.ListProductsRequest) (*apipb.ListProductsResponse, error) {
+	products, err := i.Inventory.ListTrendingProducts(ctx, inventory.ListTrendingProductsParams{
+		Pagination: listPagination(req.Page),
+	})
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return listProductsProto(products), nil
+}
+
+// ListRecentProducts returns the most recently added products.
+func (i *InventoryGRPC) ListRecentProducts(ctx context.Context, req *apipb.ListProductsRequest) (*apipb.ListProductsResponse, error) {
+	products, err := i.Inventory.ListRecentProducts(ctx, inventory.ListRecentProductsParams{
+		Pagination: listPagination(req.Page),
+	})
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return listProductsProto(products), nil
+}
+
+// listPagination returns the pagination of a product listing page, starting from 1.
+func listPagination(page *int32) inventory.Pagination {
+	const pp = 20
+	p := 1
+	if page != nil {
+		p = int(*page)
+	}
+	return inventory.Pagination{
+		Limit:  pp,
+		Offset: pp * (p - 1),
+	}
+}
+
+func listProductsProto(products *inventory.ListProductsResponse) *apipb.ListProductsResponse {
+	items := []*apipb.Product{}
+	for _, p := range products.Items {
+		items = append(items, productProto(p))
+	}
+	return &apipb.ListProductsResponse{
+		Items: items,
+	}
+}
+
+// productProto converts a product to its protobuf representation.
+func productProto(p *inventory.Product) *apipb.Product {
+	return &apipb.Product{
+		Id:          p.ID,
+		Price:       int64(p.Price),
+		Name:        p.Name,
+		Description: p.Description,
+		Status:      string(p.Status),
+		CreatedAt:   p.CreatedAt.String(),
+		ModifiedAt:  p.ModifiedAt.String(),
+		Sku:         p.SKU,
+		Gtin:        p.GTIN,
+		TaxClass:    p.TaxClass,
+	}
 }
 
 // UpdateProduct on the inventory.
@@ -73,20 +233,33 @@ func (i *InventoryGRPC) UpdateProduct(ctx context.Context, req *apipb.UpdateProd
 		ID:          req.Id,
 		Name:        req.Name,
 		Description: req.Description,
+		SKU:         req.Sku,
+		GTIN:        req.Gtin,
+		TaxClass:    req.TaxClass,
 	}
 	if req.Price != nil {
 		price := int(*req.Price)
 		params.Price = &price
 	}
-	if err := i.Inventory.UpdateProduct(ctx, params); err != nil {
+	if req.Status != nil {
+		s := inventory.ProductStatus(*req.Status)
+		params.Status = &s
+	}
+	product, err := i.Inventory.UpdateProduct(ctx, params)
+	if err != nil {
 		return nil, grpcAPIError(err)
 	}
-	return &apipb.UpdateProductResponse{}, nil
+	return &apipb.UpdateProductResponse{
+		Product: productProto(product),
+	}, nil
 }
 
 // DeleteProduct on the inventory.
 func (i *InventoryGRPC) DeleteProduct(ctx context.Context, req *apipb.DeleteProductRequest) (*apipb.DeleteProductResponse, error) {
-	if err := i.Inventory.DeleteProduct(ctx, req.Id); err != nil {
+	if err := i.Inventory.DeleteProduct(ctx, inventory.DeleteProductParams{
+		ID:    req.Id,
+		Force: req.Force,
+	}); err != nil {
 		return nil, grpcAPIError(err)
 	}
 	return &apipb.DeleteProductResponse{}, nil
@@ -94,21 +267,128 @@ func (i *InventoryGRPC) DeleteProduct(ctx context.Context, req *apipb.DeleteProd
 
 // GetProduct on the inventory.
 func (i *InventoryGRPC) GetProduct(ctx context.Context, req *apipb.GetProductRequest) (*apipb.GetProductResponse, error) {
-	product, err := i.Inventory.GetProduct(ctx, req.Id)
+	if _, _, err := inventory.ParseID(req.Id); err != nil {
+		return nil, grpcAPIError(err)
+	}
+	var (
+		product *inventory.Product
+		err     error
+	)
+	if req.Locale != "" {
+		product, err = i.Inventory.GetLocalizedProduct(ctx, req.Id, []string{req.Locale})
+	} else {
+		product, err = i.Inventory.GetProduct(ctx, req.Id)
+	}
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	if product == nil {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	recordProductView(ctx, i.Inventory, i.Log, product.ID)
+	recordRecentlyViewed(ctx, i.Inventory, i.Log, product.ID)
+	return getProductResponse(product), nil
+}
+
+// GetProductBySKU on the inventory.
+func (i *InventoryGRPC) GetProductBySKU(ctx context.Context, req *apipb.GetProductBySKURequest) (*apipb.GetProductResponse, error) {
+	product, err := i.Inventory.GetProductBySKU(ctx, req.Sku)
 	if err != nil {
 		return nil, grpcAPIError(err)
 	}
 	if product == nil {
 		return nil, status.Error(codes.NotFound, "product not found")
 	}
-	return &apipb.GetProductResponse{
+	return getProductResponse(product), nil
+}
+
+// QuoteProducts returns the price of quantities of products, with taxes applied.
+func (i *InventoryGRPC) QuoteProducts(ctx context.Context, req *apipb.QuoteProductsRequest) (*apipb.QuoteProductsResponse, error) {
+	lines := make([]inventory.QuoteLine, 0, len(req.Lines))
+	for _, l := range req.Lines {
+		lines = append(lines, inventory.QuoteLine{
+			ProductID: l.ProductId,
+			Quantity:  int(l.Quantity),
+		})
+	}
+	quote, err := i.Inventory.QuoteProducts(ctx, lines)
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	resp := &apipb.QuoteProductsResponse{
+		Lines: make([]*apipb.QuotedLine, 0, len(quote.Lines)),
+		Net:   int64(quote.Net),
+		Tax:   int64(quote.Tax),
+		Gross: int64(quote.Gross),
+	}
+	for _, l := range quote.Lines {
+		resp.Lines = append(resp.Lines, &apipb.QuotedLine{
+			ProductId: l.ProductID,
+			Quantity:  int32(l.Quantity),
+			UnitPrice: int64(l.UnitPrice),
+			Total:     priceBreakdownProto(l.Total),
+		})
+	}
+	return resp, nil
+}
+
+func getProductResponse(product *inventory.Product) *apipb.GetProductResponse {
+	resp := &apipb.GetProductResponse{
 		Id:          product.ID,
 		Price:       int64(product.Price),
 		Name:        product.Name,
 		Description: product.Description,
 		CreatedAt:   product.CreatedAt.String(),
 		ModifiedAt:  product.ModifiedAt.String(),
-	}, nil
+		Status:      string(product.Status),
+		Locale:      product.Locale,
+		Slug:        product.Slug,
+		Sku:         product.SKU,
+		Gtin:        product.GTIN,
+		Available:   int64(product.Available),
+		TaxClass:    product.TaxClass,
+	}
+	if product.PriceBreakdown != nil {
+		resp.PriceBreakdown = priceBreakdownProto(*product.PriceBreakdown)
+	}
+	if product.ReviewSummary != nil {
+		resp.ReviewSummary = product.ReviewSummary.Text
+	}
+	return resp
+}
+
+func priceBreakdownProto(b inventory.PriceBreakdown) *apipb.PriceBreakdown {
+	return &apipb.PriceBreakdown{
+		Net:     int64(b.Net),
+		Tax:     int64(b.Tax),
+		Gross:   int64(b.Gross),
+		TaxRate: int32(b.Rate),
+	}
+}
+
+// UpsertProductTranslation creates or replaces the translation of a product to a locale.
+func (i *InventoryGRPC) UpsertProductTranslation(ctx context.Context, req *apipb.UpsertProductTranslationRequest) (*apipb.UpsertProductTranslationResponse, error) {
+	err := i.Inventory.UpsertProductTranslation(ctx, inventory.ProductTranslation{
+		ProductID:   req.ProductId,
+		Locale:      req.Locale,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if errors.Is(err, inventory.ErrTranslationNoProduct) {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	if err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.UpsertProductTranslationResponse{}, nil
+}
+
+// DeleteProductTranslation deletes the translation of a product to a locale.
+func (i *InventoryGRPC) DeleteProductTranslation(ctx context.Context, req *apipb.DeleteProductTranslationRequest) (*apipb.DeleteProductTranslationResponse, error) {
+	if err := i.Inventory.DeleteProductTranslation(ctx, req.ProductId, req.Locale); err != nil {
+		return nil, grpcAPIError(err)
+	}
+	return &apipb.DeleteProductTranslationResponse{}, nil
 }
 
 // CreateProductReview on the inventory.
@@ -119,6 +399,8 @@ func (i *InventoryGRPC) CreateProductReview(ctx context.Context, req *apipb.Crea
 		Score:       int(req.Score),
 		Title:       req.Title,
 		Description: req.Description,
+		Language:    req.Language,
+		Attachments: attachments(req.Attachments),
 	})
 	if err != nil {
 		return nil, grpcAPIError(err)
@@ -133,11 +415,16 @@ func (i *InventoryGRPC) UpdateProductReview(ctx context.Context, req *apipb.Upda
 		ID:          req.Id,
 		Title:       req.Title,
 		Description: req.Description,
+		Language:    req.Language,
 	}
 	if req.Score != nil {
 		score := int(*req.Score)
 		params.Score = &score
 	}
+	if req.Attachments != nil {
+		a := attachments(req.Attachments.Items)
+		params.Attachments = &a
+	}
 	if err := i.Inventory.UpdateProductReview(ctx, params); err != nil {
 		return nil, grpcAPIError(err)
 	}
@@ -152,6 +439,9 @@ func (i *InventoryGRPC) DeleteProductReview(ctx context.Context, req *apipb.Dele
 }
 
 func (i *InventoryGRPC) GetProductReview(ctx context.Context, req *apipb.GetProductReviewRequest) (*apipb.GetProductReviewResponse, error) {
+	if _, _, err := inventory.ParseID(req.Id); err != nil {
+		return nil, grpcAPIError(err)
+	}
 	review, err := i.Inventory.GetProductReview(ctx, req.Id)
 	if err != nil {
 		return nil, grpcAPIError(err)
@@ -168,9 +458,37 @@ func (i *InventoryGRPC) GetProductReview(ctx context.Context, req *apipb.GetProd
 		Description: review.Description,
 		CreatedAt:   review.CreatedAt.String(),
 		ModifiedAt:  review.ModifiedAt.String(),
+		Language:    review.Language,
+		Flagged:     review.Flagged,
+		Sentiment:   review.Sentiment,
+		Attachments: attachmentsProto(review.Attachments),
 	}, nil
 }
 
+// attachments of a review from their protobuf representation.
+func attachments(pb []*apipb.ReviewAttachment) []inventory.Attachment {
+	var aa []inventory.Attachment
+	for _, a := range pb {
+		aa = append(aa, inventory.Attachment{
+			URL:         a.Url,
+			ContentType: a.ContentType,
+		})
+	}
+	return aa
+}
+
+// attachmentsProto returns the protobuf representation of the attachments of a review.
+func attachmentsProto(aa []inventory.Attachment) []*apipb.ReviewAttachment {
+	var pb []*apipb.ReviewAttachment
+	for _, a := range aa {
+		pb = append(pb, &apipb.ReviewAttachment{
+			Url:         a.URL,
+			ContentType: a.ContentType,
+		})
+	}
+	return pb
+}
+
 // grpcAPIError wraps an error with gRPC API codes, when possible.
 func grpcAPIError(err error) error {
 	switch {
@@ -178,9 +496,68 @@ func grpcAPIError(err error) error {
 		return status.Error(codes.DeadlineExceeded, err.Error())
 	case err == context.Canceled:
 		return status.Error(codes.Canceled, err.Error())
+	case errors.As(err, new(*inventory.FieldError)):
+		return fieldError(err)
 	case errors.As(err, &inventory.ValidationError{}):
 		return status.Errorf(codes.InvalidArgument, err.Error())
+	case errors.As(err, new(*inventory.HasDependentsError)), errors.Is(err, inventory.ErrReadOnly),
+		errors.Is(err, inventory.ErrNoProductHistory),
+		errors.Is(err, inventory.ErrInsufficientStock), errors.Is(err, inventory.ErrStockReserved),
+		errors.As(err, new(*inventory.UnavailableProductError)), errors.As(err, new(*inventory.ConfirmationRequiredError)):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.As(err, &inventory.ConflictError{}),
+		errors.Is(err, inventory.ErrSupplierExists), errors.Is(err, inventory.ErrWarehouseExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, inventory.ErrSupplierNotFound), errors.Is(err, inventory.ErrSupplierNoProduct),
+		errors.Is(err, inventory.ErrWarehouseNotFound), errors.Is(err, inventory.ErrStockNoProduct),
+		errors.Is(err, outbox.ErrDeadLetterNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, inventory.ErrTooManyReviews):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.As(err, new(*inventory.QuotaExceededError)):
+		return quotaError(err)
+	case errors.Is(err, errors.ErrUnsupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.As(err, new(*inventory.InfrastructureError)):
+		return status.Error(codes.Unavailable, err.Error())
 	default:
 		return err
 	}
 }
+
+// fieldError returns an InvalidArgument error with the invalid field as a BadRequest error detail.
+func fieldError(err error) error {
+	var fe *inventory.FieldError
+	errors.As(err, &fe)
+	st, derr := status.New(codes.InvalidArgument, err.Error()).WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{
+				Field:       fe.Field,
+				Description: fe.Message,
+			},
+		},
+	})
+	if derr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return st.Err()
+}
+
+// quotaError returns a ResourceExhausted error with the exceeded quota as a QuotaFailure error detail,
+// so clients can tell it apart from an overloaded server.
+func quotaError(err error) error {
+	var qe *inventory.QuotaExceededError
+	errors.As(err, &qe)
+	st, derr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{
+			{
+				Subject:     "tenant:" + qe.Tenant,
+				Description: qe.Error(),
+			},
+		},
+	})
+	if derr != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return st.Err()
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCAPIError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{context.Canceled, codes.Canceled},
+		{inventory.ValidationError{}, codes.InvalidArgument},
+		{&inventory.FieldError{Field: "title", Message: "missing review title"}, codes.InvalidArgument},
+		{inventory.ErrDuplicateSKU, codes.AlreadyExists},
+		{inventory.ErrDuplicateGTIN, codes.AlreadyExists},
+		{inventory.ErrSupplierExists, codes.AlreadyExists},
+		{&inventory.ConfirmationRequiredError{Matched: 250}, codes.FailedPrecondition},
+		{&inventory.QuotaExceededError{Tenant: "acme", Resource: inventory.QuotaProducts, Limit: 100}, codes.ResourceExhausted},
+		{&inventory.InfrastructureError{Message: "cannot get product from database", Err: errors.New("connection refused")}, codes.Unavailable},
+		{errors.New("cannot get product from database"), codes.Unknown},
+	}
+	for _, tc := range tests {
+		if got := status.Code(grpcAPIError(tc.err)); got != tc.want {
+			t.Errorf("grpcAPIError(%v) code = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestGRPCAPIErrorFieldViolation(t *testing.T) {
+	t.Parallel()
+	err := grpcAPIError(fmt.Errorf("cannot update review: %w", &inventory.FieldError{Field: "description", Message: "review description is longer than 10000 characters"}))
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("grpcAPIError() code = %v, want %v", st.Code(), codes.InvalidArgument)
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("grpcAPIError() details = %v, want a BadRequest", details)
+	}
+	br, ok := details[0].(*errdetails.BadRequest)
+	if !ok || len(br.FieldViolations) != 1 {
+		t.Fatalf("grpcAPIError() details = %v, want a BadRequest", details)
+	}
+	if fv := br.FieldViolations[0]; fv.Field != "description" || fv.Description != "review description is longer than 10000 characters" {
+		t.Errorf("grpcAPIError() field violation = %v, want description", fv)
+	}
+}
+
+func TestGRPCAPIErrorQuotaFailure(t *testing.T) {
+	t.Parallel()
+	err := grpcAPIError(&inventory.QuotaExceededError{Tenant: "acme", Resource: inventory.QuotaProducts, Limit: 100})
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Errorf("grpcAPIError() code = %v, want %v", st.Code(), codes.ResourceExhausted)
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("grpcAPIError() details = %v, want a QuotaFailure", details)
+	}
+	qf, ok := details[0].(*errdetails.QuotaFailure)
+	if !ok || len(qf.Violations) != 1 {
+		t.Fatalf("grpcAPIError() details = %v, want a QuotaFailure", details)
+	}
+	if v := qf.Violations[0]; v.Subject != "tenant:acme" {
+		t.Errorf("grpcAPIError() quota violation = %v, want tenant:acme", v)
+	}
+}
+
+func TestProductBatcher(t *testing.T) {
+	t.Parallel()
+	var got []int
+	b := &productBatcher{
+		send: func(resp *apipb.SearchProductsStreamResponse) error {
+			got = append(got, len(resp.Items))
+			return nil
+		},
+		size: 2,
+	}
+	for _, p := range []*inventory.Product{
+		{ID: "desk"},
+		{ID: "chair"},
+		{ID: "lamp"},
+		{ID: "rug", Description: strings.Repeat("x", maxStreamChunkBytes-100)}, // Doesn't fit on the batch with the lamp.
+		{ID: "shelf"},
+	} {
+		if err := b.add(p); err != nil {
+			t.Fatalf("productBatcher.add() error = %v", err)
+		}
+	}
+	if err := b.flush(); err != nil {
+		t.Fatalf("productBatcher.flush() error = %v", err)
+	}
+	if err := b.flush(); err != nil {
+		t.Fatalf("productBatcher.flush() of empty batch error = %v", err)
+	}
+	if want := []int{2, 1, 1, 1}; !slices.Equal(got, want) {
+		t.Errorf("productBatcher sent batches of %v products, want %v", got, want)
+	}
+}
+
+func TestProductBatcherSendError(t *testing.T) {
+	t.Parallel()
+	b := &productBatcher{
+		send: func(*apipb.SearchProductsStreamResponse) error { return context.Canceled },
+		size: 1,
+	}
+	if err := b.add(&inventory.Product{ID: "desk"}); err != context.Canceled {
+		t.Errorf("productBatcher.add() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSearchProductsStreamBatchSize(t *testing.T) {
+	t.Parallel()
+	var i InventoryGRPC
+	for _, size := range []int32{-1, maxStreamBatchSize + 1} {
+		err := i.SearchProductsStream(&apipb.SearchProductsStreamRequest{QueryString: "desk", BatchSize: size}, nil)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("InventoryGRPC.SearchProductsStream() with batch size %d error = %v, want InvalidArgument", size, err)
+		}
+	}
+}
@@ -1,68 +1,168 @@
 package api
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/henvic/pgxtutorial/internal/inventory"
 	"github.com/henvic/pgxtutorial/internal/telemetry"
+	"github.com/henvic/pgxtutorial/internal/usage"
 )
 
 // NewHTTPServer creates an HTTP server for the API.
-func NewHTTPServer(i *inventory.Service, tel telemetry.Provider) http.Handler {
+//
+// Responses are JSON objects with snake_case field names, and timestamps formatted as RFC 3339 in UTC.
+func NewHTTPServer(i inventory.API, tel telemetry.Provider, opts ...HTTPOption) *http.ServeMux {
 	s := &HTTPServer{
 		inventory: i,
 		tel:       tel,
 	}
+	for _, o := range opts {
+		o(s)
+	}
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /product/", s.handleGetProduct)
-	mux.HandleFunc("GET /review/", s.handleGetProductReview)
+	for _, route := range s.routes() {
+		mux.HandleFunc(route.pattern, route.handler)
+	}
 	return mux
 }
 
+// httpRoute of the API.
+type httpRoute struct {
+	pattern string
+	handler http.HandlerFunc
+}
+
+func (s *HTTPServer) routes() []httpRoute {
+	return []httpRoute{
+		{"GET /product/", s.handleGetProduct},
+		{"GET /p/{slug}", s.handleGetProductBySlug},
+		{"GET /product/{id}/reviews", s.handleGetProductReviews},
+		{"GET /review/", s.handleGetProductReview},
+		{"GET /products", s.handleSearchProducts},
+		{"GET /products/trending", s.handleListTrendingProducts},
+		{"GET /products/recent", s.handleListRecentProducts},
+		{"GET /products/similar", s.handleFindSimilarProducts},
+		{"GET /recently-viewed", s.handleListRecentlyViewed},
+		{"GET /usage", s.handleGetUsage},
+		{"GET /usage/export", s.handleExportUsage},
+		{"GET /admin/quotas/{tenant}", s.handleGetQuota},
+		{"PUT /admin/quotas/{tenant}", s.handlePutQuota},
+		{"DELETE /admin/quotas/{tenant}", s.handleDeleteQuota},
+	}
+}
+
+// HTTPRoutes of the API, as http.ServeMux patterns.
+func HTTPRoutes() []string {
+	var patterns []string
+	for _, route := range (&HTTPServer{}).routes() {
+		patterns = append(patterns, route.pattern)
+	}
+	return patterns
+}
+
 // HTTPServer exposes inventory.Service via HTTP.
 type HTTPServer struct {
-	inventory *inventory.Service
+	inventory inventory.API
 	tel       telemetry.Provider
+	envelope  bool
+
+	// searchCacheControl is the Cache-Control header of product searches, if set.
+	searchCacheControl string
+
+	// usage of the callers, queried with their tenant, or any with the adminToken, if set.
+	usage usage.Store
+
+	// quotas of the tenants, managed with the adminToken, if set.
+	quotas inventory.QuotaStore
+
+	// adminToken authorizes the requests of the admin, if set.
+	adminToken string
 }
 
 func (s *HTTPServer) handleGetProduct(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Path[len("/product/"):]
 	if id == "" || strings.ContainsRune(id, '/') {
-		http.NotFound(w, r)
+		s.writeError(w, "404 page not found", http.StatusNotFound)
+		return
+	}
+	if _, _, err := inventory.ParseID(id); err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	review, err := s.inventory.GetProduct(r.Context(), id)
+	var (
+		review *inventory.Product
+		err    error
+	)
+	w.Header().Add("Vary", "Accept-Language")
+	if locales := acceptLanguage(r.Header.Get("Accept-Language")); len(locales) != 0 {
+		review, err = s.inventory.GetLocalizedProduct(r.Context(), id, locales)
+	} else {
+		review, err = s.inventory.GetProduct(r.Context(), id)
+	}
 	switch {
 	case err == context.Canceled, err == context.DeadlineExceeded:
 		return
 	case err != nil:
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
 		s.tel.Logger().Error("internal server error getting product",
-			slog.Any("code", http.StatusInternalServerError),
+			slog.Any("code", code),
 			slog.Any("error", err),
 		)
 	case review == nil:
-		http.Error(w, "Product not found", http.StatusNotFound)
+		s.writeError(w, "Product not found", http.StatusNotFound)
 	default:
-		w.Header().Set("Content-Type", "application/json")
-		enc := json.NewEncoder(w)
-		enc.SetIndent("", "\t")
-		if err := enc.Encode(review); err != nil {
-			s.tel.Logger().Info("cannot json encode product request",
-				slog.Any("error", err),
-			)
+		recordProductView(r.Context(), s.inventory, s.tel.Logger(), id)
+		recordRecentlyViewed(r.Context(), s.inventory, s.tel.Logger(), id)
+		if review.Locale != "" {
+			w.Header().Set("Content-Language", review.Locale)
 		}
+		s.writeJSON(w, r, productJSONOf(review, true), nil)
+	}
+}
+
+// handleGetProductBySlug gets a product by its slug, redirecting to its current slug if it's a previous one.
+func (s *HTTPServer) handleGetProductBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	product, err := s.inventory.GetProductBySlug(r.Context(), slug)
+	switch {
+	case err == context.Canceled, err == context.DeadlineExceeded:
+		return
+	case err != nil:
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
+		s.tel.Logger().Error("internal server error getting product by slug",
+			slog.Any("code", code),
+			slog.Any("error", err),
+		)
+	case product == nil:
+		s.writeError(w, "Product not found", http.StatusNotFound)
+	case product.Slug != slug:
+		http.Redirect(w, r, "/p/"+url.PathEscape(product.Slug), http.StatusMovedPermanently)
+	default:
+		recordProductView(r.Context(), s.inventory, s.tel.Logger(), product.ID)
+		recordRecentlyViewed(r.Context(), s.inventory, s.tel.Logger(), product.ID)
+		s.writeJSON(w, r, productJSONOf(product, true), nil)
 	}
 }
 
 func (s *HTTPServer) handleGetProductReview(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Path[len("/review/"):]
 	if id == "" || strings.ContainsRune(id, '/') {
-		http.NotFound(w, r)
+		s.writeError(w, "404 page not found", http.StatusNotFound)
+		return
+	}
+	if _, _, err := inventory.ParseID(id); err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	review, err := s.inventory.GetProductReview(r.Context(), id)
@@ -70,21 +170,414 @@ func (s *HTTPServer) handleGetProductReview(w http.ResponseWriter, r *http.Reque
 	case err == context.Canceled, err == context.DeadlineExceeded:
 		return
 	case err != nil:
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
 		s.tel.Logger().Error("internal server error getting review",
-			slog.Any("code", http.StatusInternalServerError),
+			slog.Any("code", code),
 			slog.Any("error", err),
 		)
 	case review == nil:
-		http.Error(w, "Review not found", http.StatusNotFound)
+		s.writeError(w, "Review not found", http.StatusNotFound)
+	default:
+		s.writeJSON(w, r, reviewJSONOf(review), nil)
+	}
+}
+
+// handleGetProductReviews lists the reviews of a product, optionally filtered by the language,
+// min_sentiment, and max_sentiment query parameters, and sorted by the order query parameter.
+// With estimate_total=true, the total number of reviews might be an estimate.
+func (s *HTTPServer) handleGetProductReviews(w http.ResponseWriter, r *http.Request) {
+	pagination, ok := s.pagination(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	params := inventory.ProductReviewsParams{
+		ProductID:  r.PathValue("id"),
+		Language:   query.Get("language"),
+		OrderBy:    inventory.ReviewOrder(query.Get("order")),
+		Pagination: pagination,
+	}
+	if v := query.Get("min_sentiment"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			s.writeError(w, "Invalid min_sentiment", http.StatusBadRequest)
+			return
+		}
+		params.MinSentiment = &f
+	}
+	if v := query.Get("max_sentiment"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			s.writeError(w, "Invalid max_sentiment", http.StatusBadRequest)
+			return
+		}
+		params.MaxSentiment = &f
+	}
+	if v := query.Get("estimate_total"); v != "" {
+		var err error
+		if params.EstimateTotal, err = strconv.ParseBool(v); err != nil {
+			s.writeError(w, "Invalid estimate_total", http.StatusBadRequest)
+			return
+		}
+	}
+	reviews, err := s.inventory.GetProductReviews(r.Context(), params)
+	switch {
+	case err == context.Canceled, err == context.DeadlineExceeded:
+		return
+	case errors.As(err, &inventory.ValidationError{}):
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
+		s.tel.Logger().Error("internal server error listing reviews",
+			slog.Any("code", code),
+			slog.Any("error", err),
+		)
+	default:
+		items := make([]reviewJSON, 0, len(reviews.Reviews))
+		for _, review := range reviews.Reviews {
+			items = append(items, reviewJSONOf(review))
+		}
+		s.writePage(w, r, pagination, items, len(items), &reviews.Total, reviews.TotalIsEstimate)
+	}
+}
+
+// handleSearchProducts searches products by the q, sku_prefix, or text query parameters,
+// optionally filtered by the min_price and max_price query parameters, and sorted by the order query parameter.
+// With price_bands=true, the metadata has the number of products found by price band.
+// With estimate_total=true, the total number of products found might be an estimate.
+// With view=summary, only the ID, name, price, and rating of the products are returned.
+// Products sorted by name follow the alphabetical order of the languages of the Accept-Language header.
+func (s *HTTPServer) handleSearchProducts(w http.ResponseWriter, r *http.Request) {
+	pagination, ok := s.pagination(w, r)
+	if !ok {
+		return
+	}
+	view, ok := s.productView(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	params := inventory.SearchProductsParams{
+		QueryString: query.Get("q"),
+		SKUPrefix:   query.Get("sku_prefix"),
+		Text:        query.Get("text"),
+		OrderBy:     inventory.ProductOrder(query.Get("order")),
+		Pagination:  pagination,
+		View:        view,
+	}
+	if params.OrderBy == inventory.ProductsByName {
+		w.Header().Add("Vary", "Accept-Language")
+		params.Locales = acceptLanguage(r.Header.Get("Accept-Language"))
+	}
+	var err error
+	if v := query.Get("min_price"); v != "" {
+		if params.MinPrice, err = strconv.Atoi(v); err != nil {
+			s.writeError(w, "Invalid min_price", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("max_price"); v != "" {
+		if params.MaxPrice, err = strconv.Atoi(v); err != nil {
+			s.writeError(w, "Invalid max_price", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("price_bands"); v != "" {
+		if params.CountPriceBands, err = strconv.ParseBool(v); err != nil {
+			s.writeError(w, "Invalid price_bands", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("estimate_total"); v != "" {
+		if params.EstimateTotal, err = strconv.ParseBool(v); err != nil {
+			s.writeError(w, "Invalid estimate_total", http.StatusBadRequest)
+			return
+		}
+	}
+	ctx, cacheStatus := inventory.WithCacheStatus(r.Context())
+	products, err := s.inventory.SearchProducts(ctx, params)
+	if status := cacheStatus(); status != "" {
+		w.Header().Set("X-Cache", string(status))
+	}
+	switch {
+	case err == context.Canceled, err == context.DeadlineExceeded:
+		return
+	case errors.As(err, &inventory.ValidationError{}):
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
+		s.tel.Logger().Error("internal server error searching products",
+			slog.Any("code", code),
+			slog.Any("error", err),
+		)
+	default:
+		items := productsJSONOf(products.Items, view)
+		if s.searchCacheControl != "" {
+			w.Header().Set("Cache-Control", s.searchCacheControl)
+		}
+		meta := searchProductsMetaJSON{
+			paginationJSON: s.pageMeta(w, r, pagination, len(products.Items), &products.Total, products.TotalIsEstimate),
+		}
+		for _, pb := range products.PriceBands {
+			meta.PriceBands = append(meta.PriceBands, priceBandJSON(pb))
+		}
+		s.writeJSON(w, r, items, meta)
+	}
+}
+
+// handleFindSimilarProducts lists the products with a name similar to the name query parameter, the most similar first,
+// to detect likely duplicates before creating a product.
+// The tenant, threshold, and limit query parameters are optional.
+func (s *HTTPServer) handleFindSimilarProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := inventory.FindSimilarProductsParams{
+		Tenant: query.Get("tenant"),
+		Name:   query.Get("name"),
+	}
+	var err error
+	if v := query.Get("threshold"); v != "" {
+		if params.Threshold, err = strconv.ParseFloat(v, 64); err != nil {
+			s.writeError(w, "Invalid threshold", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if params.Limit, err = strconv.Atoi(v); err != nil {
+			s.writeError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	similar, err := s.inventory.FindSimilarProducts(r.Context(), params)
+	switch {
+	case err == context.Canceled, err == context.DeadlineExceeded:
+		return
+	case errors.As(err, &inventory.ValidationError{}):
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
+		s.tel.Logger().Error("internal server error finding similar products",
+			slog.Any("code", code),
+			slog.Any("error", err),
+		)
 	default:
-		w.Header().Set("Content-Type", "application/json")
-		enc := json.NewEncoder(w)
-		enc.SetIndent("", "\t")
-		if err := enc.Encode(review); err != nil {
-			s.tel.Logger().Info("cannot json encode review request",
-				slog.Any("error", err),
-			)
+		items := make([]similarProductJSON, 0, len(similar))
+		for _, sp := range similar {
+			items = append(items, similarProductJSON{
+				Product:    productJSONOf(sp.Product, false),
+				Similarity: sp.Similarity,
+			})
+		}
+		s.writeJSON(w, r, items, nil)
+	}
+}
+
+func (s *HTTPServer) handleListTrendingProducts(w http.ResponseWriter, r *http.Request) {
+	pagination, ok := s.pagination(w, r)
+	if !ok {
+		return
+	}
+	view, ok := s.productView(w, r)
+	if !ok {
+		return
+	}
+	products, err := s.inventory.ListTrendingProducts(r.Context(), inventory.ListTrendingProductsParams{
+		Pagination: pagination,
+		View:       view,
+	})
+	s.writeProducts(w, r, pagination, view, products, err)
+}
+
+func (s *HTTPServer) handleListRecentProducts(w http.ResponseWriter, r *http.Request) {
+	pagination, ok := s.pagination(w, r)
+	if !ok {
+		return
+	}
+	view, ok := s.productView(w, r)
+	if !ok {
+		return
+	}
+	products, err := s.inventory.ListRecentProducts(r.Context(), inventory.ListRecentProductsParams{
+		Pagination: pagination,
+		View:       view,
+	})
+	s.writeProducts(w, r, pagination, view, products, err)
+}
+
+// handleListRecentlyViewed lists the products recently viewed by the authenticated user, the most recent first.
+func (s *HTTPServer) handleListRecentlyViewed(w http.ResponseWriter, r *http.Request) {
+	userID := userIDFromContext(r.Context())
+	if userID == "" {
+		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	pagination, ok := s.pagination(w, r)
+	if !ok {
+		return
+	}
+	view, ok := s.productView(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	products, err := s.inventory.ListRecentlyViewed(r.Context(), inventory.ListRecentlyViewedParams{
+		UserID:     userID,
+		Pagination: pagination,
+		View:       view,
+	})
+	s.writeProducts(w, r, pagination, view, products, err)
+}
+
+// acceptLanguage returns the language tags of an Accept-Language header, in order of preference.
+// Tags with a malformed or zero quality value, and the wildcard, are left out.
+func acceptLanguage(header string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		l := language{
+			tag: strings.TrimSpace(tag),
+			q:   1,
+		}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if l.q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if l.tag != "" && l.tag != "*" && l.q > 0 {
+			languages = append(languages, l)
 		}
 	}
+	slices.SortStableFunc(languages, func(a, b language) int {
+		return cmp.Compare(b.q, a.q)
+	})
+	tags := make([]string, 0, len(languages))
+	for _, l := range languages {
+		tags = append(tags, l.tag)
+	}
+	return tags
+}
+
+// pagination reads the limit and offset query parameters, writing a bad request response if they're invalid.
+func (s *HTTPServer) pagination(w http.ResponseWriter, r *http.Request) (p inventory.Pagination, ok bool) {
+	const defaultLimit, maxLimit = 20, 100
+	p.Limit = defaultLimit
+	var err error
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if p.Limit, err = strconv.Atoi(v); err != nil || p.Limit < 1 || p.Limit > maxLimit {
+			s.writeError(w, "Invalid limit", http.StatusBadRequest)
+			return p, false
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if p.Offset, err = strconv.Atoi(v); err != nil || p.Offset < 0 {
+			s.writeError(w, "Invalid offset", http.StatusBadRequest)
+			return p, false
+		}
+	}
+	return p, true
+}
+
+// productView reads the view query parameter of a listing of products, writing a bad request response if it's invalid.
+// Listings return the full view of products by default.
+func (s *HTTPServer) productView(w http.ResponseWriter, r *http.Request) (view inventory.ProductView, ok bool) {
+	switch view = inventory.ProductView(r.URL.Query().Get("view")); view {
+	case "", inventory.ProductViewFull, inventory.ProductViewSummary:
+		return view, true
+	}
+	s.writeError(w, "Invalid view", http.StatusBadRequest)
+	return view, false
+}
+
+func (s *HTTPServer) writeProducts(w http.ResponseWriter, r *http.Request, p inventory.Pagination, view inventory.ProductView, products *inventory.ListProductsResponse, err error) {
+	switch {
+	case err == context.Canceled, err == context.DeadlineExceeded:
+		return
+	case err != nil:
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
+		s.tel.Logger().Error("internal server error listing products",
+			slog.Any("code", code),
+			slog.Any("error", err),
+		)
+	default:
+		s.writePage(w, r, p, productsJSONOf(products.Items, view), len(products.Items), nil, false)
+	}
+}
+
+// writePage of a listing, with its pagination in the metadata, and the Link header (RFC 8288, formerly RFC 5988)
+// with the next and previous pages. The total number of results, if known, is also set in the X-Total-Count header,
+// or in the X-Total-Count-Estimate header if it's an estimate.
+func (s *HTTPServer) writePage(w http.ResponseWriter, r *http.Request, p inventory.Pagination, items any, count int, total *int, estimate bool) {
+	s.writeJSON(w, r, items, s.pageMeta(w, r, p, count, total, estimate))
+}
+
+// pageMeta sets the Link and X-Total-Count headers of a page of a listing, as written by writePage,
+// and returns its pagination metadata.
+func (s *HTTPServer) pageMeta(w http.ResponseWriter, r *http.Request, p inventory.Pagination, count int, total *int, estimate bool) paginationJSON {
+	var links []string
+	link := func(offset int, rel string) {
+		u := *r.URL
+		q := u.Query()
+		q.Set("limit", strconv.Itoa(p.Limit))
+		q.Set("offset", strconv.Itoa(offset))
+		u.RawQuery = q.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel))
+	}
+	// Without the total, or with an estimate of it, a full page might have a next one.
+	exact := total != nil && !estimate
+	if (exact && p.Offset+count < *total) || (!exact && count == p.Limit) {
+		link(p.Offset+p.Limit, "next")
+	}
+	if p.Offset > 0 {
+		link(max(p.Offset-p.Limit, 0), "prev")
+	}
+	if len(links) != 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	switch {
+	case exact:
+		w.Header().Set("X-Total-Count", strconv.Itoa(*total))
+	case total != nil:
+		w.Header().Set("X-Total-Count-Estimate", strconv.Itoa(*total))
+	}
+	return paginationJSON{
+		Total:           total,
+		TotalIsEstimate: total != nil && estimate,
+		Limit:           p.Limit,
+		Offset:          p.Offset,
+		Count:           count,
+	}
+}
+
+// recordProductView counts a view of a product, logging if it fails.
+// Views aren't counted while the service is in read-only mode.
+func recordProductView(ctx context.Context, i inventory.API, log *slog.Logger, id string) {
+	switch err := i.RecordProductView(ctx, id); {
+	case err == nil, errors.Is(err, inventory.ErrReadOnly), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+	default:
+		log.Warn("cannot record product view", slog.String("id", id), slog.Any("error", err))
+	}
+}
+
+// errorCode of an error not handled otherwise: 409 Conflict if it conflicts with existing data,
+// 403 Forbidden if it exceeds the quota of the tenant, 503 Service Unavailable if a dependency of the service failed, as retrying might succeed,
+// or 500 Internal Server Error otherwise.
+func errorCode(err error) int {
+	switch {
+	case errors.As(err, &inventory.ConflictError{}):
+		return http.StatusConflict
+	case errors.Is(err, inventory.ErrQuotaExceeded):
+		return http.StatusForbidden
+	case errors.As(err, new(*inventory.InfrastructureError)):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
 }
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestErrorCode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{inventory.ErrDuplicateSKU, http.StatusConflict},
+		{inventory.ErrDuplicateGTIN, http.StatusConflict},
+		{fmt.Errorf("cannot create product: %w", &inventory.QuotaExceededError{Tenant: "acme", Resource: inventory.QuotaProducts, Limit: 100}), http.StatusForbidden},
+		{&inventory.InfrastructureError{Message: "cannot get product from database", Err: errors.New("connection refused")}, http.StatusServiceUnavailable},
+		{errors.New("cannot get product from database"), http.StatusInternalServerError},
+	}
+	for _, tc := range tests {
+		if got := errorCode(tc.err); got != tc.want {
+			t.Errorf("errorCode(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
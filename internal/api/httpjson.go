@@ -0,0 +1,325 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/usage"
+)
+
+// HTTPOption for the HTTP server of the API.
+type HTTPOption func(*HTTPServer)
+
+// WithEnvelope wraps HTTP responses in an envelope, as in {"data": ..., "meta": ...} or {"error": {"message": ...}},
+// instead of responding with the resource itself, or a plain text error.
+func WithEnvelope() HTTPOption {
+	return func(s *HTTPServer) {
+		s.envelope = true
+	}
+}
+
+// WithSearchCacheControl sets the Cache-Control header of product searches, so clients and shared caches
+// can reuse responses for maxAge, and serve them for up to stale longer while they're revalidated.
+func WithSearchCacheControl(maxAge, stale time.Duration) HTTPOption {
+	return func(s *HTTPServer) {
+		s.searchCacheControl = fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d",
+			int(maxAge.Seconds()), int(stale.Seconds()))
+	}
+}
+
+// WithAdminToken authorizes requests as the admin with "Authorization: Bearer <token>".
+func WithAdminToken(token string) HTTPOption {
+	return func(s *HTTPServer) {
+		s.adminToken = token
+	}
+}
+
+// WithUsage serves the usage of the callers from the store, by their tenant.
+// Requests authorized as the admin can query the usage of any tenant.
+func WithUsage(store usage.Store) HTTPOption {
+	return func(s *HTTPServer) {
+		s.usage = store
+	}
+}
+
+// WithQuotas serves the quotas of the tenants from the store to requests authorized as the admin,
+// so they can be adjusted on /admin/quotas/{tenant}.
+func WithQuotas(store inventory.QuotaStore) HTTPOption {
+	return func(s *HTTPServer) {
+		s.quotas = store
+	}
+}
+
+// jsonTime is formatted as RFC 3339 in UTC, regardless of the location of the time.
+type jsonTime time.Time
+
+// MarshalJSON implements json.Marshaler.
+func (t jsonTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t).UTC().Format(time.RFC3339Nano))
+}
+
+// productJSON is the wire format of a product.
+type productJSON struct {
+	ID             string              `json:"id"`
+	Name           string              `json:"name"`
+	Description    string              `json:"description"`
+	Price          int                 `json:"price"`
+	Status         string              `json:"status"`
+	Slug           string              `json:"slug"`
+	SKU            string              `json:"sku,omitempty"`
+	GTIN           string              `json:"gtin,omitempty"`
+	TaxClass       string              `json:"tax_class,omitempty"`
+	PriceBreakdown *priceBreakdownJSON `json:"price_breakdown,omitempty"`
+	Available      *int                `json:"available,omitempty"`
+	Locale         string              `json:"locale,omitempty"`
+	ReviewSummary  *reviewSummaryJSON  `json:"review_summary,omitempty"`
+	CreatedAt      jsonTime            `json:"created_at"`
+	ModifiedAt     jsonTime            `json:"modified_at"`
+}
+
+// productSummaryJSON is the wire format of the summary view of a product.
+type productSummaryJSON struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Price  int     `json:"price"`
+	Rating float64 `json:"rating"`
+}
+
+// similarProductJSON is the wire format of a product with a similar name, from the most similar, 1, to 0.
+type similarProductJSON struct {
+	Product    productJSON `json:"product"`
+	Similarity float64     `json:"similarity"`
+}
+
+// reviewSummaryJSON is the wire format of the review summary of a product.
+type reviewSummaryJSON struct {
+	Text        string   `json:"text"`
+	Reviews     int      `json:"reviews"`
+	GeneratedAt jsonTime `json:"generated_at"`
+}
+
+// priceBreakdownJSON is the wire format of a price breakdown.
+type priceBreakdownJSON struct {
+	Net   int `json:"net"`
+	Tax   int `json:"tax"`
+	Gross int `json:"gross"`
+
+	// TaxRate in basis points (hundredths of a percent), such as 2000 for 20%.
+	TaxRate int `json:"tax_rate"`
+}
+
+// productJSONOf a product. Available is only set for a single product, as for GetProduct.
+func productJSONOf(p *inventory.Product, single bool) productJSON {
+	j := productJSON{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Status:      string(p.Status),
+		Slug:        p.Slug,
+		SKU:         p.SKU,
+		GTIN:        p.GTIN,
+		TaxClass:    p.TaxClass,
+		Locale:      p.Locale,
+		CreatedAt:   jsonTime(p.CreatedAt),
+		ModifiedAt:  jsonTime(p.ModifiedAt),
+	}
+	if b := p.PriceBreakdown; b != nil {
+		j.PriceBreakdown = &priceBreakdownJSON{
+			Net:     b.Net,
+			Tax:     b.Tax,
+			Gross:   b.Gross,
+			TaxRate: int(b.Rate),
+		}
+	}
+	if single {
+		j.Available = &p.Available
+	}
+	if rs := p.ReviewSummary; rs != nil {
+		j.ReviewSummary = &reviewSummaryJSON{
+			Text:        rs.Text,
+			Reviews:     rs.Reviews,
+			GeneratedAt: jsonTime(rs.GeneratedAt),
+		}
+	}
+	return j
+}
+
+// productsJSONOf returns the wire format of the view of a list of products.
+func productsJSONOf(products []*inventory.Product, view inventory.ProductView) any {
+	if view == inventory.ProductViewSummary {
+		items := make([]productSummaryJSON, 0, len(products))
+		for _, p := range products {
+			items = append(items, productSummaryJSON{
+				ID:     p.ID,
+				Name:   p.Name,
+				Price:  p.Price,
+				Rating: p.Rating,
+			})
+		}
+		return items
+	}
+	items := make([]productJSON, 0, len(products))
+	for _, p := range products {
+		items = append(items, productJSONOf(p, false))
+	}
+	return items
+}
+
+// reviewJSON is the wire format of a product review.
+type reviewJSON struct {
+	ID          string           `json:"id"`
+	ProductID   string           `json:"product_id"`
+	ReviewerID  string           `json:"reviewer_id"`
+	Score       int              `json:"score"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Language    string           `json:"language,omitempty"`
+	Flagged     bool             `json:"flagged"`
+	Sentiment   *float64         `json:"sentiment,omitempty"`
+	Attachments []attachmentJSON `json:"attachments"`
+	CreatedAt   jsonTime         `json:"created_at"`
+	ModifiedAt  jsonTime         `json:"modified_at"`
+}
+
+// attachmentJSON is the wire format of a review attachment.
+type attachmentJSON struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+}
+
+func reviewJSONOf(r *inventory.ProductReview) reviewJSON {
+	attachments := make([]attachmentJSON, 0, len(r.Attachments))
+	for _, a := range r.Attachments {
+		attachments = append(attachments, attachmentJSON{
+			URL:         a.URL,
+			ContentType: a.ContentType,
+		})
+	}
+	return reviewJSON{
+		ID:          r.ID,
+		ProductID:   r.ProductID,
+		ReviewerID:  r.ReviewerID,
+		Score:       r.Score,
+		Title:       r.Title,
+		Description: r.Description,
+		Language:    r.Language,
+		Flagged:     r.Flagged,
+		Sentiment:   r.Sentiment,
+		Attachments: attachments,
+		CreatedAt:   jsonTime(r.CreatedAt),
+		ModifiedAt:  jsonTime(r.ModifiedAt),
+	}
+}
+
+// paginationJSON is the metadata of a page of a listing.
+type paginationJSON struct {
+	// Total number of results, if known.
+	Total *int `json:"total,omitempty"`
+
+	// TotalIsEstimate is set when the Total is an estimate, as requested with estimate_total=true.
+	TotalIsEstimate bool `json:"total_is_estimate,omitempty"`
+
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Count  int `json:"count"`
+}
+
+// searchProductsMetaJSON is the metadata of a page of products found by a search.
+type searchProductsMetaJSON struct {
+	paginationJSON
+
+	// PriceBands with products found, if requested.
+	PriceBands []priceBandJSON `json:"price_bands,omitempty"`
+}
+
+// priceBandJSON is the wire format of an inventory.PriceBand.
+type priceBandJSON struct {
+	Band     int `json:"band"`
+	MinPrice int `json:"min_price"`
+	MaxPrice int `json:"max_price,omitempty"` // Omitted for the last band, which has no upper limit.
+	Count    int `json:"count"`
+}
+
+// envelopeJSON of a response, when the HTTP server is created WithEnvelope.
+type envelopeJSON struct {
+	Data  any        `json:"data,omitempty"`
+	Error *errorJSON `json:"error,omitempty"`
+	Meta  any        `json:"meta,omitempty"`
+}
+
+// errorJSON is the wire format of an error.
+type errorJSON struct {
+	Message string `json:"message"`
+}
+
+// writeJSON response with the data, and its metadata if the response is wrapped in an envelope.
+// The response has an ETag of its content, and is 304 Not Modified if the request has it in If-None-Match.
+func (s *HTTPServer) writeJSON(w http.ResponseWriter, r *http.Request, data, meta any) {
+	var v any = data
+	if s.envelope {
+		v = envelopeJSON{Data: data, Meta: meta}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(v); err != nil {
+		s.writeError(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		s.tel.Logger().Error("cannot json encode response",
+			slog.Any("error", err),
+		)
+		return
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := buf.WriteTo(w); err != nil {
+		s.tel.Logger().Info("cannot write response",
+			slog.Any("error", err),
+		)
+	}
+}
+
+// etagMatch reports whether an If-None-Match header matches the entity tag, using the weak comparison.
+func etagMatch(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError response, as a plain text message unless the response is wrapped in an envelope.
+func (s *HTTPServer) writeError(w http.ResponseWriter, message string, code int) {
+	if !s.envelope {
+		http.Error(w, message, code)
+		return
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(envelopeJSON{Error: &errorJSON{Message: message}}); err != nil {
+		s.tel.Logger().Info("cannot json encode error response",
+			slog.Any("error", err),
+		)
+	}
+}
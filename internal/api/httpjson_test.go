@@ -0,0 +1,418 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/telemetry/telemetrytest"
+)
+
+// fakeInventory implements the methods of inventory.API used by the HTTP server.
+type fakeInventory struct {
+	inventory.API
+}
+
+func (fakeInventory) GetProduct(ctx context.Context, id string) (*inventory.Product, error) {
+	switch id {
+	case "product":
+	case "unavailable":
+		return nil, &inventory.InfrastructureError{Message: "cannot get product from database", Err: errors.New("connection refused")}
+	default:
+		return nil, nil
+	}
+	return &inventory.Product{
+		ID:          "product",
+		Name:        "Product",
+		Description: "A product",
+		Price:       120,
+		Status:      inventory.ProductStatusActive,
+		Slug:        "product",
+		SKU:         "SKU-1",
+		PriceBreakdown: &inventory.PriceBreakdown{
+			Net:   100,
+			Tax:   20,
+			Gross: 120,
+			Rate:  2000,
+		},
+		Available:  3,
+		CreatedAt:  time.Date(2024, 6, 1, 12, 0, 0, 5e8, time.FixedZone("BRT", -3*60*60)),
+		ModifiedAt: time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+func (fakeInventory) RecordProductView(ctx context.Context, id string) error {
+	return nil
+}
+
+func (fakeInventory) ListRecentProducts(ctx context.Context, params inventory.ListRecentProductsParams) (*inventory.ListProductsResponse, error) {
+	return &inventory.ListProductsResponse{
+		Items: []*inventory.Product{
+			{
+				ID:         "product",
+				Name:       "Product",
+				Status:     inventory.ProductStatusDraft,
+				Slug:       "product",
+				CreatedAt:  time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+				ModifiedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+	}, nil
+}
+
+func (fakeInventory) SearchProducts(ctx context.Context, params inventory.SearchProductsParams) (*inventory.SearchProductsResponse, error) {
+	if params.QueryString == "" && params.SKUPrefix == "" && params.Text == "" {
+		return nil, inventory.ValidationError{}
+	}
+	resp := &inventory.SearchProductsResponse{Total: 3, TotalIsEstimate: params.EstimateTotal}
+	if params.CountPriceBands {
+		resp.PriceBands = []inventory.PriceBand{inventory.NewPriceBand(0, 2), inventory.NewPriceBand(4, 1)}
+	}
+	for i := params.Pagination.Offset; i < min(params.Pagination.Offset+params.Pagination.Limit, resp.Total); i++ {
+		resp.Items = append(resp.Items, &inventory.Product{
+			ID:         fmt.Sprintf("product%d", i),
+			Name:       "Product",
+			Status:     inventory.ProductStatusActive,
+			Slug:       fmt.Sprintf("product-%d", i),
+			CreatedAt:  time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			ModifiedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		})
+	}
+	return resp, nil
+}
+
+func TestHTTPServerJSON(t *testing.T) {
+	t.Parallel()
+	const product = `{
+	"id": "product",
+	"name": "Product",
+	"description": "A product",
+	"price": 120,
+	"status": "active",
+	"slug": "product",
+	"sku": "SKU-1",
+	"price_breakdown": {
+		"net": 100,
+		"tax": 20,
+		"gross": 120,
+		"tax_rate": 2000
+	},
+	"available": 3,
+	"created_at": "2024-06-01T15:00:00.5Z",
+	"modified_at": "2024-06-02T12:00:00Z"
+}
+`
+	tests := []struct {
+		name     string
+		envelope bool
+		target   string
+		wantCode int
+		wantType string
+		wantLink string
+		wantBody string
+	}{
+		{
+			name:     "product",
+			target:   "/product/product",
+			wantCode: http.StatusOK,
+			wantType: "application/json",
+			wantBody: product,
+		},
+		{
+			name:     "not_found",
+			target:   "/product/unknown",
+			wantCode: http.StatusNotFound,
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "Product not found\n",
+		},
+		{
+			name:     "unavailable",
+			target:   "/product/unavailable",
+			wantCode: http.StatusServiceUnavailable,
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "Service Unavailable\n",
+		},
+		{
+			name:     "envelope_list",
+			envelope: true,
+			target:   "/products/recent?limit=10",
+			wantCode: http.StatusOK,
+			wantType: "application/json",
+			wantBody: `{
+	"data": [
+		{
+			"id": "product",
+			"name": "Product",
+			"description": "",
+			"price": 0,
+			"status": "draft",
+			"slug": "product",
+			"created_at": "2024-06-01T12:00:00Z",
+			"modified_at": "2024-06-01T12:00:00Z"
+		}
+	],
+	"meta": {
+		"limit": 10,
+		"offset": 0,
+		"count": 1
+	}
+}
+`,
+		},
+		{
+			name:     "envelope_not_found",
+			envelope: true,
+			target:   "/product/unknown",
+			wantCode: http.StatusNotFound,
+			wantType: "application/json",
+			wantBody: `{
+	"error": {
+		"message": "Product not found"
+	}
+}
+`,
+		},
+		{
+			name:     "envelope_bad_request",
+			envelope: true,
+			target:   "/products/recent?limit=-1",
+			wantCode: http.StatusBadRequest,
+			wantType: "application/json",
+			wantBody: `{
+	"error": {
+		"message": "Invalid limit"
+	}
+}
+`,
+		},
+		{
+			name:     "envelope_page",
+			envelope: true,
+			target:   "/products?q=product&limit=1&offset=1",
+			wantCode: http.StatusOK,
+			wantType: "application/json",
+			wantLink: `</products?limit=1&offset=2&q=product>; rel="next", </products?limit=1&offset=0&q=product>; rel="prev"`,
+			wantBody: `{
+	"data": [
+		{
+			"id": "product1",
+			"name": "Product",
+			"description": "",
+			"price": 0,
+			"status": "active",
+			"slug": "product-1",
+			"created_at": "2024-06-01T12:00:00Z",
+			"modified_at": "2024-06-01T12:00:00Z"
+		}
+	],
+	"meta": {
+		"total": 3,
+		"limit": 1,
+		"offset": 1,
+		"count": 1
+	}
+}
+`,
+		},
+		{
+			name:     "envelope_price_bands",
+			envelope: true,
+			target:   "/products?text=product&price_bands=true&limit=1",
+			wantCode: http.StatusOK,
+			wantType: "application/json",
+			wantLink: `</products?limit=1&offset=1&price_bands=true&text=product>; rel="next"`,
+			wantBody: `{
+	"data": [
+		{
+			"id": "product0",
+			"name": "Product",
+			"description": "",
+			"price": 0,
+			"status": "active",
+			"slug": "product-0",
+			"created_at": "2024-06-01T12:00:00Z",
+			"modified_at": "2024-06-01T12:00:00Z"
+		}
+	],
+	"meta": {
+		"total": 3,
+		"limit": 1,
+		"offset": 0,
+		"count": 1,
+		"price_bands": [
+			{
+				"band": 0,
+				"min_price": 0,
+				"max_price": 999,
+				"count": 2
+			},
+			{
+				"band": 4,
+				"min_price": 50000,
+				"count": 1
+			}
+		]
+	}
+}
+`,
+		},
+		{
+			name:     "envelope_estimate_total",
+			envelope: true,
+			target:   "/products?q=product&estimate_total=true&limit=1&offset=2",
+			wantCode: http.StatusOK,
+			wantType: "application/json",
+			// The estimated total might be too low, so the last page of the estimate links to the next one.
+			wantLink: `</products?estimate_total=true&limit=1&offset=3&q=product>; rel="next", </products?estimate_total=true&limit=1&offset=1&q=product>; rel="prev"`,
+			wantBody: `{
+	"data": [
+		{
+			"id": "product2",
+			"name": "Product",
+			"description": "",
+			"price": 0,
+			"status": "active",
+			"slug": "product-2",
+			"created_at": "2024-06-01T12:00:00Z",
+			"modified_at": "2024-06-01T12:00:00Z"
+		}
+	],
+	"meta": {
+		"total": 3,
+		"total_is_estimate": true,
+		"limit": 1,
+		"offset": 2,
+		"count": 1
+	}
+}
+`,
+		},
+		{
+			name:     "invalid_estimate_total",
+			target:   "/products?q=product&estimate_total=maybe",
+			wantCode: http.StatusBadRequest,
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "Invalid estimate_total\n",
+		},
+		{
+			name:     "invalid_price_bands",
+			target:   "/products?q=product&price_bands=maybe",
+			wantCode: http.StatusBadRequest,
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "Invalid price_bands\n",
+		},
+		{
+			name:     "last_page",
+			target:   "/products?q=product&limit=2&offset=2",
+			wantCode: http.StatusOK,
+			wantType: "application/json",
+			wantLink: `</products?limit=2&offset=0&q=product>; rel="prev"`,
+			wantBody: `[
+	{
+		"id": "product2",
+		"name": "Product",
+		"description": "",
+		"price": 0,
+		"status": "active",
+		"slug": "product-2",
+		"created_at": "2024-06-01T12:00:00Z",
+		"modified_at": "2024-06-01T12:00:00Z"
+	}
+]
+`,
+		},
+		{
+			name:     "summary_view",
+			target:   "/products/recent?limit=10&view=summary",
+			wantCode: http.StatusOK,
+			wantType: "application/json",
+			wantBody: `[
+	{
+		"id": "product",
+		"name": "Product",
+		"price": 0,
+		"rating": 0
+	}
+]
+`,
+		},
+		{
+			name:     "invalid_view",
+			target:   "/products?q=product&view=compact",
+			wantCode: http.StatusBadRequest,
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "Invalid view\n",
+		},
+		{
+			name:     "recently_viewed_unauthenticated",
+			target:   "/recently-viewed",
+			wantCode: http.StatusUnauthorized,
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "Unauthorized\n",
+		},
+		{
+			name:     "invalid_search",
+			target:   "/products?min_price=1",
+			wantCode: http.StatusBadRequest,
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var opts []HTTPOption
+			if tt.envelope {
+				opts = append(opts, WithEnvelope())
+			}
+			mux := NewHTTPServer(fakeInventory{}, telemetrytest.Discard(), opts...)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantCode {
+				t.Errorf("got status code %d, want %d", rec.Code, tt.wantCode)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("got Content-Type %q, want %q", got, tt.wantType)
+			}
+			if got := rec.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("got Link %q, want %q", got, tt.wantLink)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("got body:\n%s\nwant:\n%s", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHTTPServerETag(t *testing.T) {
+	t.Parallel()
+	mux := NewHTTPServer(fakeInventory{}, telemetrytest.Discard(), WithSearchCacheControl(5*time.Second, 30*time.Second))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/products?q=product", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status code %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got, want := rec.Header().Get("Cache-Control"), "public, max-age=5, stale-while-revalidate=30"; got != want {
+		t.Errorf("got Cache-Control %q, want %q", got, want)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/products?q=product", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("got status code %d with %d bytes, want %d without a body", rec.Code, rec.Body.Len(), http.StatusNotModified)
+	}
+	if got := rec.Header().Get("ETag"); got != etag {
+		t.Errorf("got ETag %q, want %q", got, etag)
+	}
+}
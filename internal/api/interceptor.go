@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/loadshed"
+	"github.com/henvic/pgxtutorial/internal/slo"
+	"github.com/henvic/pgxtutorial/internal/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// baggageUnaryInterceptor extracts the telemetry.BaggageKeys request attributes.
+func baggageUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(telemetry.ContextWithBaggageAttributes(ctx, telemetry.BaggageKeys...), req)
+}
+
+// recoveryUnaryInterceptor recovers from panics in handlers, logging them with the stack trace,
+// and returns an Internal error instead of crashing the server.
+func recoveryUnaryInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.ErrorContext(ctx, "gRPC handler panic",
+					slog.String("method", info.FullMethod),
+					slog.Any("panic", r),
+					slog.String("stack_trace", string(debug.Stack())),
+				)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// loadSheddingUnaryInterceptor rejects calls with ResourceExhausted while their method is at its concurrency limit.
+// Health checks aren't limited, as failing them would take the server out of rotation.
+func loadSheddingUnaryInterceptor(limiter *loadshed.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+			return handler(ctx, req)
+		}
+		release, ok := limiter.Acquire(info.FullMethod)
+		if !ok {
+			return nil, status.Error(codes.ResourceExhausted, "server overloaded, try again later")
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// recoveryStreamInterceptor is the recoveryUnaryInterceptor of streaming calls.
+func recoveryStreamInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.ErrorContext(ss.Context(), "gRPC handler panic",
+					slog.String("method", info.FullMethod),
+					slog.Any("panic", r),
+					slog.String("stack_trace", string(debug.Stack())),
+				)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// loadSheddingStreamInterceptor is the loadSheddingUnaryInterceptor of streaming calls,
+// which hold their slot until the stream ends.
+func loadSheddingStreamInterceptor(limiter *loadshed.Limiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+			return handler(srv, ss)
+		}
+		release, ok := limiter.Acquire(info.FullMethod)
+		if !ok {
+			return status.Error(codes.ResourceExhausted, "server overloaded, try again later")
+		}
+		defer release()
+		return handler(srv, ss)
+	}
+}
+
+// sloUnaryInterceptor records calls on the SLO tracker.
+// Calls failing due to the server, including panics, consume the error budget, while client errors don't.
+func sloUnaryInterceptor(tracker *slo.Tracker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		switch status.Code(err) {
+		case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded:
+			tracker.Record(info.FullMethod, true, time.Since(start))
+		default:
+			tracker.Record(info.FullMethod, false, time.Since(start))
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// quotaJSON is the wire format of the quota of a tenant. Zero limits are unlimited.
+type quotaJSON struct {
+	Tenant               string `json:"tenant"`
+	MaxProducts          int    `json:"max_products"`
+	MaxReviewsPerProduct int    `json:"max_reviews_per_product"`
+}
+
+// maxQuotaBodyBytes of the request body setting a quota.
+const maxQuotaBodyBytes = 1 << 10
+
+// authorizeQuotas checks if the quotas are served, and if the request is authorized with the admin token.
+func (s *HTTPServer) authorizeQuotas(w http.ResponseWriter, r *http.Request) bool {
+	switch {
+	case s.quotas == nil:
+		s.writeError(w, "404 page not found", http.StatusNotFound)
+		return false
+	case !s.authorizeAdmin(r):
+		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// quotaFailed writes the error of a quota request, if any, and returns whether the request failed.
+func (s *HTTPServer) quotaFailed(w http.ResponseWriter, err error, msg string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+	case errors.As(err, &inventory.ValidationError{}):
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+	default:
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
+		s.tel.Logger().Error("internal server error "+msg,
+			slog.Any("code", code),
+			slog.Any("error", err),
+		)
+	}
+	return true
+}
+
+// handleGetQuota returns the quota set for a tenant, or 404 Not Found if the default quota applies.
+func (s *HTTPServer) handleGetQuota(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeQuotas(w, r) {
+		return
+	}
+	q, err := s.quotas.GetTenantQuota(r.Context(), r.PathValue("tenant"))
+	if s.quotaFailed(w, err, "getting quota") {
+		return
+	}
+	if q == nil {
+		s.writeError(w, "No quota set for tenant: the default quota applies", http.StatusNotFound)
+		return
+	}
+	s.writeJSON(w, r, quotaJSON{
+		Tenant:               q.Tenant,
+		MaxProducts:          q.MaxProducts,
+		MaxReviewsPerProduct: q.MaxReviewsPerProduct,
+	}, nil)
+}
+
+// handlePutQuota sets the quota of a tenant from a JSON body with its max_products and max_reviews_per_product,
+// replacing the one set before, if any.
+func (s *HTTPServer) handlePutQuota(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeQuotas(w, r) {
+		return
+	}
+	var body quotaJSON
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxQuotaBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		s.writeError(w, "Invalid quota: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := inventory.TenantQuota{
+		Tenant: r.PathValue("tenant"),
+		Quota: inventory.Quota{
+			MaxProducts:          body.MaxProducts,
+			MaxReviewsPerProduct: body.MaxReviewsPerProduct,
+		},
+	}
+	if s.quotaFailed(w, q.Validate(), "setting quota") {
+		return
+	}
+	if s.quotaFailed(w, s.quotas.SetTenantQuota(r.Context(), q), "setting quota") {
+		return
+	}
+	s.writeJSON(w, r, quotaJSON{
+		Tenant:               q.Tenant,
+		MaxProducts:          q.MaxProducts,
+		MaxReviewsPerProduct: q.MaxReviewsPerProduct,
+	}, nil)
+}
+
+// handleDeleteQuota deletes the quota set for a tenant, so the default quota applies again.
+func (s *HTTPServer) handleDeleteQuota(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeQuotas(w, r) {
+		return
+	}
+	if s.quotaFailed(w, s.quotas.DeleteTenantQuota(r.Context(), r.PathValue("tenant")), "deleting quota") {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
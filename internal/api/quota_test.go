@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/telemetry/telemetrytest"
+)
+
+// fakeQuotaStore keeps the quotas in memory.
+type fakeQuotaStore struct {
+	mu     sync.Mutex
+	quotas map[string]inventory.TenantQuota
+}
+
+func (s *fakeQuotaStore) GetTenantQuota(ctx context.Context, tenant string) (*inventory.TenantQuota, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.quotas[tenant]
+	if !ok {
+		return nil, nil
+	}
+	return &q, nil
+}
+
+func (s *fakeQuotaStore) SetTenantQuota(ctx context.Context, q inventory.TenantQuota) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.quotas == nil {
+		s.quotas = map[string]inventory.TenantQuota{}
+	}
+	s.quotas[q.Tenant] = q
+	return nil
+}
+
+func (s *fakeQuotaStore) DeleteTenantQuota(ctx context.Context, tenant string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.quotas, tenant)
+	return nil
+}
+
+func TestHTTPServerQuotas(t *testing.T) {
+	t.Parallel()
+	store := &fakeQuotaStore{}
+	h := NewHTTPServer(fakeInventory{}, telemetrytest.Discard(), WithQuotas(store), WithAdminToken("secret"))
+	do := func(method, target, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	steps := []struct {
+		name     string
+		method   string
+		target   string
+		token    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "unauthorized",
+			method:   http.MethodPut,
+			target:   "/admin/quotas/acme",
+			token:    "wrong",
+			body:     `{"max_products":100}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "default",
+			method:   http.MethodGet,
+			target:   "/admin/quotas/acme",
+			token:    "secret",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "set",
+			method:   http.MethodPut,
+			target:   "/admin/quotas/acme",
+			token:    "secret",
+			body:     `{"max_products":100,"max_reviews_per_product":10}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"tenant":"acme","max_products":100,"max_reviews_per_product":10}`,
+		},
+		{
+			name:     "get",
+			method:   http.MethodGet,
+			target:   "/admin/quotas/acme",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `{"tenant":"acme","max_products":100,"max_reviews_per_product":10}`,
+		},
+		{
+			name:     "negative",
+			method:   http.MethodPut,
+			target:   "/admin/quotas/acme",
+			token:    "secret",
+			body:     `{"max_products":-1}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown_field",
+			method:   http.MethodPut,
+			target:   "/admin/quotas/acme",
+			token:    "secret",
+			body:     `{"max_reviews":10}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid_tenant",
+			method:   http.MethodPut,
+			target:   "/admin/quotas/acme:local",
+			token:    "secret",
+			body:     `{"max_products":100}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "delete",
+			method:   http.MethodDelete,
+			target:   "/admin/quotas/acme",
+			token:    "secret",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "deleted",
+			method:   http.MethodGet,
+			target:   "/admin/quotas/acme",
+			token:    "secret",
+			wantCode: http.StatusNotFound,
+		},
+	}
+	// The steps depend on each other, so they run in order.
+	for _, step := range steps {
+		w := do(step.method, step.target, step.token, step.body)
+		if w.Code != step.wantCode {
+			t.Fatalf("%s: status = %d, want %d: %s", step.name, w.Code, step.wantCode, w.Body)
+		}
+		if step.wantBody == "" {
+			continue
+		}
+		var b bytes.Buffer
+		if err := json.Compact(&b, w.Body.Bytes()); err != nil {
+			t.Fatalf("%s: cannot compact response: %v", step.name, err)
+		}
+		if got := b.String(); got != step.wantBody {
+			t.Errorf("%s: body = %s, want %s", step.name, got, step.wantBody)
+		}
+	}
+
+	// Quotas aren't served without a store.
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin/quotas/acme", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	NewHTTPServer(fakeInventory{}, telemetrytest.Discard(), WithAdminToken("secret")).ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status without quota store = %d, want 404", w.Code)
+	}
+}
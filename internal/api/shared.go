@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// sharedServer serves gRPC and HTTP on a single port, multiplexed as HTTP/2 cleartext (h2c),
+// so both are reachable through a single L4 load balancer target.
+//
+// HTTP/1.1 requests are served by the HTTP API, and so are HTTP/2 requests,
+// except the ones with a gRPC content-type, which are served by the gRPC server.
+type sharedServer struct {
+	grpc *grpcServer
+	http *httpServer
+
+	server *http.Server
+
+	// inflight requests, tracked as h2c connections are hijacked from the http.Server,
+	// so its Shutdown doesn't wait for them.
+	inflight sync.WaitGroup
+}
+
+// Run the shared server.
+func (s *sharedServer) Run(ctx context.Context, address string, grpcOptions []otelgrpc.Option, httpOptions []otelhttp.Option) error {
+	var lc net.ListenConfig
+	lis, err := lc.Listen(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	s.grpc.setup(ctx, grpcOptions...)
+	api := s.http.handler(httpOptions...)
+	mux := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inflight.Add(1)
+		defer s.inflight.Done()
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			s.grpc.grpc.ServeHTTP(w, r)
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+
+	h2s := &http2.Server{}
+	s.server = &http.Server{
+		Handler: h2c.NewHandler(mux, h2s),
+
+		ReadHeaderTimeout: 5 * time.Second, // mitigate risk of Slowloris Attack
+	}
+	// Register h2s on the http.Server, so its Shutdown sends GOAWAY to the h2c connections.
+	if err := http2.ConfigureServer(s.server, h2s); err != nil {
+		return fmt.Errorf("cannot configure HTTP/2: %w", err)
+	}
+	s.http.tel.Logger().Info("gRPC and HTTP server listening", slog.Any("address", lis.Addr()))
+	if err := s.server.Serve(lis); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown the shared server, waiting for the requests in progress until the context is done.
+func (s *sharedServer) Shutdown(ctx context.Context) {
+	s.http.tel.Logger().Info("shutting down gRPC and HTTP server")
+	if s.server == nil {
+		return
+	}
+	// Shutdown sets the serving status to NOT_SERVING, and ignores later updates from sloReadiness.
+	s.grpc.health.Shutdown()
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.http.tel.Logger().Error("graceful shutdown of gRPC and HTTP server failed", slog.Any("error", err))
+	}
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.http.tel.Logger().Error("graceful shutdown of gRPC and HTTP server timed out", slog.Any("error", ctx.Err()))
+	}
+	// GracefulStop isn't supported for gRPC served through ServeHTTP, and the requests already finished.
+	s.grpc.grpc.Stop()
+}
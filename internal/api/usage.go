@@ -0,0 +1,326 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/usage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// tenantIDHeader and apiKeyIDHeader identify the tenant and the API key of the caller of a request, as authenticated
+// by a trusted proxy, such as an API gateway. As for userIDHeader, they're ignored on requests from other peers.
+const (
+	tenantIDHeader = "X-Tenant-ID"
+	apiKeyIDHeader = "X-API-Key-ID"
+)
+
+// caller of a request, identified by its tenant and API key.
+type caller struct {
+	tenant string
+	apiKey string
+}
+
+// callerCtx key.
+type callerCtx struct{}
+
+// contextWithCaller returns a copy of the context with the caller authenticated by a trusted proxy.
+func contextWithCaller(ctx context.Context, c caller) context.Context {
+	return context.WithValue(ctx, callerCtx{}, c)
+}
+
+// callerFromContext returns the caller authenticated by a trusted proxy, or the zero value if it isn't identified.
+func callerFromContext(ctx context.Context) caller {
+	c, _ := ctx.Value(callerCtx{}).(caller)
+	return c
+}
+
+// callerHandler sets the caller identified by a trusted proxy on the context of the request.
+func callerHandler(trusted []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := caller{tenant: r.Header.Get(tenantIDHeader), apiKey: r.Header.Get(apiKeyIDHeader)}
+		if remote, ok := parseHostAddr(r.RemoteAddr); !ok || c == (caller{}) || !isTrustedProxy(trusted, remote) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(contextWithCaller(r.Context(), c)))
+	})
+}
+
+// grpcCaller returns the caller identified by a trusted proxy with the x-tenant-id and x-api-key-id metadata of a call.
+func grpcCaller(ctx context.Context, trusted []netip.Prefix) (c caller, ok bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return caller{}, false
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("x-tenant-id"); len(v) != 0 {
+		c.tenant = v[0]
+	}
+	if v := md.Get("x-api-key-id"); len(v) != 0 {
+		c.apiKey = v[0]
+	}
+	if remote, ok := parseHostAddr(p.Addr.String()); !ok || c == (caller{}) || !isTrustedProxy(trusted, remote) {
+		return caller{}, false
+	}
+	return c, true
+}
+
+// callerUnaryInterceptor sets the caller identified by a trusted proxy on the context of the call.
+func callerUnaryInterceptor(trusted []netip.Prefix) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if c, ok := grpcCaller(ctx, trusted); ok {
+			ctx = contextWithCaller(ctx, c)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// usageHandler accounts the requests of identified callers, and the bytes of their bodies, on the meter.
+// It must be wrapped by callerHandler.
+func usageHandler(meter *usage.Meter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := callerFromContext(r.Context())
+		if c == (caller{}) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var body *countingReader
+		if r.Body != nil {
+			body = &countingReader{ReadCloser: r.Body}
+			r.Body = body
+		}
+		cw := &countingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		var bytesIn int64
+		if body != nil {
+			bytesIn = body.n
+		}
+		meter.Record(c.tenant, c.apiKey, bytesIn, cw.n)
+	})
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// countingResponseWriter counts the bytes written to a response body.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countingResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+// Flush the response, if supported.
+func (w *countingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the ResponseWriter, for http.ResponseController.
+func (w *countingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// usageUnaryInterceptor accounts the calls of identified callers, and the size of their messages, on the meter.
+// It must come after callerUnaryInterceptor.
+func usageUnaryInterceptor(meter *usage.Meter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		c := callerFromContext(ctx)
+		if c == (caller{}) {
+			return handler(ctx, req)
+		}
+		resp, err := handler(ctx, req)
+		meter.Record(c.tenant, c.apiKey, messageSize(req), messageSize(resp))
+		return resp, err
+	}
+}
+
+// usageStreamInterceptor accounts the streams of callers identified by a trusted proxy,
+// and the size of the messages they receive and send, on the meter.
+func usageStreamInterceptor(meter *usage.Meter, trusted []netip.Prefix) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		c, ok := grpcCaller(ss.Context(), trusted)
+		if !ok {
+			return handler(srv, ss)
+		}
+		cs := &countingServerStream{ServerStream: ss}
+		err := handler(srv, cs)
+		meter.Record(c.tenant, c.apiKey, cs.in, cs.out)
+		return err
+	}
+}
+
+// countingServerStream counts the size of the messages received and sent on a stream.
+type countingServerStream struct {
+	grpc.ServerStream
+	in, out int64
+}
+
+func (s *countingServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.in += messageSize(m)
+	}
+	return err
+}
+
+func (s *countingServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.out += messageSize(m)
+	}
+	return err
+}
+
+// messageSize of a protobuf message, or 0 for other values, such as the nil response of a failed call.
+func messageSize(m any) int64 {
+	if msg, ok := m.(proto.Message); ok {
+		return int64(proto.Size(msg))
+	}
+	return 0
+}
+
+// authorizeAdmin checks if the request carries the admin token as "Authorization: Bearer <token>".
+func (s *HTTPServer) authorizeAdmin(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1
+}
+
+// usageQuery parses the query of the usage of the caller from the from, to, and api_key query parameters,
+// with the period defaulting to the current month. Requests authorized with the admin token can query
+// the usage of any tenant with the tenant query parameter, or of all tenants without it.
+func (s *HTTPServer) usageQuery(w http.ResponseWriter, r *http.Request) (q usage.Query, ok bool) {
+	if s.usage == nil {
+		s.writeError(w, "404 page not found", http.StatusNotFound)
+		return q, false
+	}
+	query := r.URL.Query()
+	switch c := callerFromContext(r.Context()); {
+	case s.authorizeAdmin(r):
+		q.Tenant = query.Get("tenant")
+	case c.tenant != "":
+		q.Tenant = c.tenant
+	default:
+		s.writeError(w, "Unauthorized", http.StatusUnauthorized)
+		return q, false
+	}
+	q.APIKey = query.Get("api_key")
+
+	now := time.Now().UTC()
+	q.From = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	q.To = now
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			s.writeError(w, "Invalid "+p.name+": must be in RFC 3339 format", http.StatusBadRequest)
+			return q, false
+		}
+		*p.t = t
+	}
+	if err := q.Validate(); err != nil {
+		s.writeError(w, err.Error(), http.StatusBadRequest)
+		return q, false
+	}
+	return q, true
+}
+
+// usageJSON is the wire format of a usage record.
+type usageJSON struct {
+	Tenant   string   `json:"tenant"`
+	APIKey   string   `json:"api_key"`
+	Hour     jsonTime `json:"hour"`
+	Requests int64    `json:"requests"`
+	BytesIn  int64    `json:"bytes_in"`
+	BytesOut int64    `json:"bytes_out"`
+}
+
+// handleGetUsage lists the hourly usage of the caller, as queried by usageQuery.
+func (s *HTTPServer) handleGetUsage(w http.ResponseWriter, r *http.Request) {
+	q, ok := s.usageQuery(w, r)
+	if !ok {
+		return
+	}
+	records, err := s.usage.ListUsage(r.Context(), q)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return
+	case err != nil:
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
+		s.tel.Logger().Error("internal server error listing usage",
+			slog.Any("code", code),
+			slog.Any("error", err),
+		)
+		return
+	}
+	items := make([]usageJSON, 0, len(records))
+	for _, u := range records {
+		items = append(items, usageJSON{
+			Tenant:   u.Tenant,
+			APIKey:   u.APIKey,
+			Hour:     jsonTime(u.Hour),
+			Requests: u.Requests,
+			BytesIn:  u.BytesIn,
+			BytesOut: u.BytesOut,
+		})
+	}
+	s.writeJSON(w, r, items, nil)
+}
+
+// handleExportUsage exports the hourly usage of the caller, as queried by usageQuery, as CSV.
+func (s *HTTPServer) handleExportUsage(w http.ResponseWriter, r *http.Request) {
+	q, ok := s.usageQuery(w, r)
+	if !ok {
+		return
+	}
+	records, err := s.usage.ListUsage(r.Context(), q)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return
+	case err != nil:
+		code := errorCode(err)
+		s.writeError(w, http.StatusText(code), code)
+		s.tel.Logger().Error("internal server error exporting usage",
+			slog.Any("code", code),
+			slog.Any("error", err),
+		)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="usage.csv"`)
+	if err := usage.WriteCSV(w, records); err != nil {
+		s.tel.Logger().Warn("cannot write usage export", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/telemetry/telemetrytest"
+	"github.com/henvic/pgxtutorial/internal/usage"
+)
+
+// fakeUsageStore records the queries and written usage.
+type fakeUsageStore struct {
+	mu      sync.Mutex
+	queries []usage.Query
+	records []usage.Record
+}
+
+func (s *fakeUsageStore) AddUsage(ctx context.Context, records []usage.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *fakeUsageStore) ListUsage(ctx context.Context, q usage.Query) ([]usage.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, q)
+	return []usage.Record{
+		{Tenant: q.Tenant, APIKey: "key1", Hour: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), Requests: 3, BytesIn: 15, BytesOut: 170},
+	}, nil
+}
+
+func TestUsageHandler(t *testing.T) {
+	t.Parallel()
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	store := &fakeUsageStore{}
+	meter := &usage.Meter{Store: store, Log: slog.Default()}
+	h := callerHandler(trusted, usageHandler(meter, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append(body, "!"...)) // #nosec G104
+	})))
+	for _, remote := range []string{"10.0.0.1:4000", "203.0.113.7:4000"} {
+		req := httptest.NewRequest(http.MethodPost, "/product", strings.NewReader("hello"))
+		req.RemoteAddr = remote
+		req.Header.Set(tenantIDHeader, "acme")
+		req.Header.Set(apiKeyIDHeader, "key1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	// Requests without a caller aren't accounted.
+	req := httptest.NewRequest(http.MethodGet, "/product/1", nil)
+	req.RemoteAddr = "10.0.0.1:4000"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	meter.Shutdown(context.Background())
+	if err := meter.Run(context.Background()); err != nil {
+		t.Fatalf("Meter.Run() error = %v", err)
+	}
+	if len(store.records) != 1 {
+		t.Fatalf("accounted %d records, want 1", len(store.records))
+	}
+	// The request from the untrusted peer isn't accounted.
+	if r := store.records[0]; r.Tenant != "acme" || r.APIKey != "key1" || r.Requests != 1 || r.BytesIn != 5 || r.BytesOut != 6 {
+		t.Errorf("accounted usage = %+v, want 1 request of acme with key1, 5 bytes in, 6 bytes out", r)
+	}
+}
+
+func TestHTTPServerUsage(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		target     string
+		tenant     string
+		token      string
+		wantCode   int
+		wantTenant string
+		wantBody   string
+	}{
+		{
+			name:       "tenant",
+			target:     "/usage?from=2024-06-01T00:00:00Z&to=2024-07-01T00:00:00Z",
+			tenant:     "acme",
+			wantCode:   http.StatusOK,
+			wantTenant: "acme",
+			wantBody:   `[{"tenant":"acme","api_key":"key1","hour":"2024-06-01T12:00:00Z","requests":3,"bytes_in":15,"bytes_out":170}]`,
+		},
+		{
+			name:       "other_tenant",
+			target:     "/usage?tenant=globex",
+			tenant:     "acme",
+			wantCode:   http.StatusOK,
+			wantTenant: "acme",
+		},
+		{
+			name:       "admin",
+			target:     "/usage/export?tenant=globex",
+			token:      "secret",
+			wantCode:   http.StatusOK,
+			wantTenant: "globex",
+			wantBody:   "tenant,api_key,hour,requests,bytes_in,bytes_out\nglobex,key1,2024-06-01T12:00:00Z,3,15,170\n",
+		},
+		{
+			name:     "invalid_admin_token",
+			target:   "/usage",
+			token:    "wrong",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "unauthorized",
+			target:   "/usage/export",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "invalid_range",
+			target:   "/usage?from=2024-07-01T00:00:00Z&to=2024-06-01T00:00:00Z",
+			tenant:   "acme",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid_time",
+			target:   "/usage?from=yesterday",
+			tenant:   "acme",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := &fakeUsageStore{}
+			h := NewHTTPServer(fakeInventory{}, telemetrytest.Discard(), WithUsage(store), WithAdminToken("secret"))
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.tenant != "" {
+				req = req.WithContext(contextWithCaller(req.Context(), caller{tenant: tt.tenant}))
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if len(store.queries) != 1 || store.queries[0].Tenant != tt.wantTenant {
+				t.Errorf("queries = %+v, want the usage of %q", store.queries, tt.wantTenant)
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			got := w.Body.String()
+			if strings.HasPrefix(tt.target, "/usage?") {
+				var b bytes.Buffer
+				if err := json.Compact(&b, w.Body.Bytes()); err != nil {
+					t.Fatalf("cannot compact response: %v", err)
+				}
+				got = b.String()
+			}
+			if got != tt.wantBody {
+				t.Errorf("body = %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+
+	// Usage isn't served without a store.
+	w := httptest.NewRecorder()
+	NewHTTPServer(fakeInventory{}, telemetrytest.Discard()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/usage", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status without usage store = %d, want 404", w.Code)
+	}
+}
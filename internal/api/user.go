@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// userIDHeader identifies the user of a request, as authenticated by a trusted proxy, such as an API gateway.
+// The header (or the x-user-id gRPC metadata) is ignored on requests from other peers, as clients could forge it.
+const userIDHeader = "X-User-ID"
+
+// userIDCtx key.
+type userIDCtx struct{}
+
+// contextWithUserID returns a copy of the context with the ID of the authenticated user.
+func contextWithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDCtx{}, id)
+}
+
+// userIDFromContext returns the ID of the authenticated user, or an empty string if the request isn't authenticated.
+func userIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(userIDCtx{}).(string)
+	return id
+}
+
+// userHandler sets the ID of the user authenticated by a trusted proxy on the context of the request.
+func userHandler(trusted []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(userIDHeader)
+		if remote, ok := parseHostAddr(r.RemoteAddr); !ok || id == "" || !isTrustedProxy(trusted, remote) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(contextWithUserID(r.Context(), id)))
+	})
+}
+
+// userUnaryInterceptor sets the ID of the user authenticated by a trusted proxy on the context of the call.
+func userUnaryInterceptor(trusted []netip.Prefix) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		ids := md.Get("x-user-id")
+		if remote, ok := parseHostAddr(p.Addr.String()); !ok || len(ids) == 0 || ids[0] == "" || !isTrustedProxy(trusted, remote) {
+			return handler(ctx, req)
+		}
+		return handler(contextWithUserID(ctx, ids[0]), req)
+	}
+}
+
+// recentlyViewedTimeout of recording a product on the recently viewed list of a user.
+const recentlyViewedTimeout = 5 * time.Second
+
+// recordRecentlyViewed adds a product to the recently viewed list of the authenticated user, if any.
+// It's recorded in the background, so it doesn't slow down or fail the request, logging if it fails.
+func recordRecentlyViewed(ctx context.Context, i inventory.API, log *slog.Logger, id string) {
+	userID := userIDFromContext(ctx)
+	if userID == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recentlyViewedTimeout)
+		defer cancel()
+		switch err := i.RecordRecentlyViewed(ctx, inventory.RecentlyViewedParams{
+			UserID:    userID,
+			ProductID: id,
+		}); {
+		case err == nil, errors.Is(err, inventory.ErrReadOnly):
+		default:
+			log.Warn("cannot record recently viewed product", slog.String("id", id), slog.Any("error", err))
+		}
+	}()
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestUserHandler(t *testing.T) {
+	t.Parallel()
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	tests := []struct {
+		name   string
+		remote string
+		user   string
+		want   string
+	}{
+		{
+			name:   "trusted_proxy",
+			remote: "10.0.0.1:4000",
+			user:   "alice",
+			want:   "alice",
+		},
+		{
+			name:   "untrusted_peer",
+			remote: "203.0.113.7:4000",
+			user:   "alice",
+		},
+		{
+			name:   "unauthenticated",
+			remote: "10.0.0.1:4000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got string
+			h := userHandler(trusted, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = userIDFromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/recently-viewed", nil)
+			req.RemoteAddr = tt.remote
+			if tt.user != "" {
+				req.Header.Set(userIDHeader, tt.user)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("got user %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
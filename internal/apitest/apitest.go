@@ -0,0 +1,956 @@
+// Package apitest injects faults into inventory service and database calls,
+// to deterministically test how callers handle slow calls, cancellation, and deadlines,
+// or to simulate an unreliable database, failing a fraction of the calls, such as on the mock server.
+//
+// Example:
+//
+//	faults := apitest.Faults{"SearchProducts": {DeadlineExceeded: true}}
+//	s := inventory.Chain(inventory.NewService(faults.DB(db)), faults.Middleware())
+package apitest
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// Fault injected into a call.
+type Fault struct {
+	// Latency before the call. The wait is cut short if the context is done.
+	Latency time.Duration
+
+	// Cancel the context of the call.
+	Cancel bool
+
+	// DeadlineExceeded sets a deadline in the past on the context of the call.
+	DeadlineExceeded bool
+
+	// ErrorRate is the fraction of the calls failed with Err, from 0 to 1, after the latency.
+	ErrorRate float64
+
+	// Err of the failed calls (default: ErrInjected).
+	Err error
+}
+
+// ErrInjected is returned by calls failed by the ErrorRate of a Fault, as if the database were unavailable.
+var ErrInjected = &inventory.InfrastructureError{
+	Message: "injected fault",
+	Err:     errors.New("injected fault"),
+}
+
+// Faults by method name. The fault of the "*" key applies to methods without a fault of their own.
+type Faults map[string]Fault
+
+// inject the fault of the method, returning the context to use for the call, or the error to fail it with.
+// The returned cancel function must be called once the call returns.
+func (f Faults) inject(ctx context.Context, method string) (context.Context, context.CancelFunc, error) {
+	fault, ok := f[method]
+	if !ok {
+		fault = f["*"]
+	}
+	cancel := context.CancelFunc(func() {})
+	switch {
+	case fault.Cancel:
+		ctx, cancel = context.WithCancel(ctx)
+		cancel()
+	case fault.DeadlineExceeded:
+		ctx, cancel = context.WithDeadline(ctx, time.Unix(0, 0))
+	}
+	if fault.Latency > 0 {
+		t := time.NewTimer(fault.Latency)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+		}
+	}
+	if fault.ErrorRate > 0 && rand.Float64() < fault.ErrorRate { // #nosec G404
+		if fault.Err != nil {
+			return ctx, cancel, fault.Err
+		}
+		return ctx, cancel, ErrInjected
+	}
+	return ctx, cancel, nil
+}
+
+// Middleware injects the faults into calls to the inventory API, such as the ones made by the API handlers.
+func (f Faults) Middleware() inventory.ServiceMiddleware {
+	return func(next inventory.API) inventory.API {
+		return api{next: next, faults: f}
+	}
+}
+
+// DB injects the faults into calls to the database.
+func (f Faults) DB(db inventory.DB) inventory.DB {
+	return database{next: db, faults: f}
+}
+
+// api with injected faults.
+type api struct {
+	next   inventory.API
+	faults Faults
+}
+
+func (a api) CreateProduct(ctx context.Context, params inventory.CreateProductParams) (*inventory.CreateProductResult, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "CreateProduct")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.CreateProduct(ctx, params)
+}
+
+func (a api) UpdateProduct(ctx context.Context, params inventory.UpdateProductParams) (*inventory.Product, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "UpdateProduct")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.UpdateProduct(ctx, params)
+}
+
+func (a api) DeleteProduct(ctx context.Context, params inventory.DeleteProductParams) error {
+	ctx, cancel, err := a.faults.inject(ctx, "DeleteProduct")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.DeleteProduct(ctx, params)
+}
+
+func (a api) SyncProducts(ctx context.Context, params inventory.SyncProductsParams) (*inventory.SyncProductsResult, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "SyncProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.SyncProducts(ctx, params)
+}
+
+func (a api) DeleteProducts(ctx context.Context, params inventory.DeleteProductsParams) (*inventory.DeleteProductsResult, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "DeleteProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.DeleteProducts(ctx, params)
+}
+
+func (a api) GetProduct(ctx context.Context, id string) (*inventory.Product, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetProduct")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetProduct(ctx, id)
+}
+
+func (a api) GetProductAt(ctx context.Context, id string, at time.Time) (*inventory.Product, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetProductAt")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetProductAt(ctx, id, at)
+}
+
+func (a api) GetProductBySlug(ctx context.Context, slug string) (*inventory.Product, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetProductBySlug")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetProductBySlug(ctx, slug)
+}
+
+func (a api) GetProductBySKU(ctx context.Context, sku string) (*inventory.Product, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetProductBySKU")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetProductBySKU(ctx, sku)
+}
+
+func (a api) QuoteProducts(ctx context.Context, lines []inventory.QuoteLine) (*inventory.Quote, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "QuoteProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.QuoteProducts(ctx, lines)
+}
+
+func (a api) GetLocalizedProduct(ctx context.Context, id string, locales []string) (*inventory.Product, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetLocalizedProduct")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetLocalizedProduct(ctx, id, locales)
+}
+
+func (a api) UpsertProductTranslation(ctx context.Context, params inventory.ProductTranslation) error {
+	ctx, cancel, err := a.faults.inject(ctx, "UpsertProductTranslation")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.UpsertProductTranslation(ctx, params)
+}
+
+func (a api) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	ctx, cancel, err := a.faults.inject(ctx, "DeleteProductTranslation")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.DeleteProductTranslation(ctx, productID, locale)
+}
+
+func (a api) ProductHistory(ctx context.Context, id string) ([]inventory.Version, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "ProductHistory")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.ProductHistory(ctx, id)
+}
+
+func (a api) SearchProducts(ctx context.Context, params inventory.SearchProductsParams) (*inventory.SearchProductsResponse, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "SearchProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.SearchProducts(ctx, params)
+}
+
+func (a api) ExportProducts(ctx context.Context, params inventory.SearchProductsParams, fn func(*inventory.Product) error) error {
+	ctx, cancel, err := a.faults.inject(ctx, "ExportProducts")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.ExportProducts(ctx, params, fn)
+}
+
+func (a api) FindSimilarProducts(ctx context.Context, params inventory.FindSimilarProductsParams) ([]inventory.SimilarProduct, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "FindSimilarProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.FindSimilarProducts(ctx, params)
+}
+
+func (a api) GetProductStats(ctx context.Context, id string) (*inventory.ProductStats, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetProductStats")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetProductStats(ctx, id)
+}
+
+func (a api) AddFavorite(ctx context.Context, params inventory.FavoriteParams) error {
+	ctx, cancel, err := a.faults.inject(ctx, "AddFavorite")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.AddFavorite(ctx, params)
+}
+
+func (a api) RemoveFavorite(ctx context.Context, params inventory.FavoriteParams) error {
+	ctx, cancel, err := a.faults.inject(ctx, "RemoveFavorite")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.RemoveFavorite(ctx, params)
+}
+
+func (a api) ListFavorites(ctx context.Context, params inventory.ListFavoritesParams) (*inventory.ListFavoritesResponse, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "ListFavorites")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.ListFavorites(ctx, params)
+}
+
+func (a api) RecordProductView(ctx context.Context, id string) error {
+	ctx, cancel, err := a.faults.inject(ctx, "RecordProductView")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.RecordProductView(ctx, id)
+}
+
+func (a api) ListTrendingProducts(ctx context.Context, params inventory.ListTrendingProductsParams) (*inventory.ListProductsResponse, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "ListTrendingProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.ListTrendingProducts(ctx, params)
+}
+
+func (a api) ListRecentProducts(ctx context.Context, params inventory.ListRecentProductsParams) (*inventory.ListProductsResponse, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "ListRecentProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.ListRecentProducts(ctx, params)
+}
+
+func (a api) RecordRecentlyViewed(ctx context.Context, params inventory.RecentlyViewedParams) error {
+	ctx, cancel, err := a.faults.inject(ctx, "RecordRecentlyViewed")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.RecordRecentlyViewed(ctx, params)
+}
+
+func (a api) ListRecentlyViewed(ctx context.Context, params inventory.ListRecentlyViewedParams) (*inventory.ListProductsResponse, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "ListRecentlyViewed")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.ListRecentlyViewed(ctx, params)
+}
+
+func (a api) CreateSupplier(ctx context.Context, params inventory.CreateSupplierParams) (*inventory.Supplier, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "CreateSupplier")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.CreateSupplier(ctx, params)
+}
+
+func (a api) UpdateSupplier(ctx context.Context, params inventory.UpdateSupplierParams) (*inventory.Supplier, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "UpdateSupplier")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.UpdateSupplier(ctx, params)
+}
+
+func (a api) DeleteSupplier(ctx context.Context, id string) error {
+	ctx, cancel, err := a.faults.inject(ctx, "DeleteSupplier")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.DeleteSupplier(ctx, id)
+}
+
+func (a api) GetSupplier(ctx context.Context, id string) (*inventory.Supplier, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetSupplier")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetSupplier(ctx, id)
+}
+
+func (a api) SetProductSupplier(ctx context.Context, params inventory.ProductSupplier) error {
+	ctx, cancel, err := a.faults.inject(ctx, "SetProductSupplier")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.SetProductSupplier(ctx, params)
+}
+
+func (a api) RemoveProductSupplier(ctx context.Context, productID, supplierID string) error {
+	ctx, cancel, err := a.faults.inject(ctx, "RemoveProductSupplier")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.RemoveProductSupplier(ctx, productID, supplierID)
+}
+
+func (a api) ListProductSuppliers(ctx context.Context, productID string) ([]*inventory.ProductSupplier, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "ListProductSuppliers")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.ListProductSuppliers(ctx, productID)
+}
+
+func (a api) ListSupplierProducts(ctx context.Context, params inventory.ListSupplierProductsParams) (*inventory.ListProductsResponse, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "ListSupplierProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.ListSupplierProducts(ctx, params)
+}
+
+func (a api) CreateWarehouse(ctx context.Context, params inventory.CreateWarehouseParams) (*inventory.Warehouse, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "CreateWarehouse")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.CreateWarehouse(ctx, params)
+}
+
+func (a api) ListWarehouses(ctx context.Context) ([]*inventory.Warehouse, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "ListWarehouses")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.ListWarehouses(ctx)
+}
+
+func (a api) SetProductStock(ctx context.Context, params inventory.SetProductStockParams) error {
+	ctx, cancel, err := a.faults.inject(ctx, "SetProductStock")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.SetProductStock(ctx, params)
+}
+
+func (a api) TransferStock(ctx context.Context, params inventory.TransferStockParams) error {
+	ctx, cancel, err := a.faults.inject(ctx, "TransferStock")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.TransferStock(ctx, params)
+}
+
+func (a api) GetProductStock(ctx context.Context, productID string) ([]*inventory.StockLevel, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetProductStock")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetProductStock(ctx, productID)
+}
+
+func (a api) CreateProductReview(ctx context.Context, params inventory.CreateProductReviewParams) (string, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "CreateProductReview")
+	defer cancel()
+	if err != nil {
+		return "", err
+	}
+	return a.next.CreateProductReview(ctx, params)
+}
+
+func (a api) UpdateProductReview(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+	ctx, cancel, err := a.faults.inject(ctx, "UpdateProductReview")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.UpdateProductReview(ctx, params)
+}
+
+func (a api) DeleteProductReview(ctx context.Context, id string) error {
+	ctx, cancel, err := a.faults.inject(ctx, "DeleteProductReview")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return a.next.DeleteProductReview(ctx, id)
+}
+
+func (a api) GetProductReview(ctx context.Context, id string) (*inventory.ProductReview, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetProductReview")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetProductReview(ctx, id)
+}
+
+func (a api) GetProductReviews(ctx context.Context, params inventory.ProductReviewsParams) (*inventory.ProductReviewsResponse, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "GetProductReviews")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.GetProductReviews(ctx, params)
+}
+
+func (a api) ReviewHistory(ctx context.Context, id string) ([]inventory.Version, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "ReviewHistory")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.ReviewHistory(ctx, id)
+}
+
+func (a api) PurgeReviewerData(ctx context.Context, params inventory.PurgeReviewerDataParams) (*inventory.PurgeReviewerDataResult, error) {
+	ctx, cancel, err := a.faults.inject(ctx, "PurgeReviewerData")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return a.next.PurgeReviewerData(ctx, params)
+}
+
+// database with injected faults.
+type database struct {
+	next   inventory.DB
+	faults Faults
+}
+
+func (d database) CreateProduct(ctx context.Context, params inventory.CreateProductParams) (*inventory.CreateProductResult, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "CreateProduct")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.CreateProduct(ctx, params)
+}
+
+func (d database) UpdateProduct(ctx context.Context, params inventory.UpdateProductParams) (*inventory.Product, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "UpdateProduct")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.UpdateProduct(ctx, params)
+}
+
+func (d database) GetProduct(ctx context.Context, id string) (*inventory.Product, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProduct")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProduct(ctx, id)
+}
+
+func (d database) GetProductBySlug(ctx context.Context, slug string) (*inventory.Product, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProductBySlug")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProductBySlug(ctx, slug)
+}
+
+func (d database) GetProductBySKU(ctx context.Context, sku string) (*inventory.Product, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProductBySKU")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProductBySKU(ctx, sku)
+}
+
+func (d database) GetProducts(ctx context.Context, ids []string) ([]*inventory.Product, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProducts(ctx, ids)
+}
+
+func (d database) GetProductAt(ctx context.Context, id string, at time.Time) (*inventory.Product, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProductAt")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProductAt(ctx, id, at)
+}
+
+func (d database) UpsertProductTranslation(ctx context.Context, params inventory.ProductTranslation) error {
+	ctx, cancel, err := d.faults.inject(ctx, "UpsertProductTranslation")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.UpsertProductTranslation(ctx, params)
+}
+
+func (d database) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	ctx, cancel, err := d.faults.inject(ctx, "DeleteProductTranslation")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.DeleteProductTranslation(ctx, productID, locale)
+}
+
+func (d database) GetProductTranslation(ctx context.Context, productID string, locales []string) (*inventory.ProductTranslation, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProductTranslation")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProductTranslation(ctx, productID, locales)
+}
+
+func (d database) ProductHistory(ctx context.Context, id string) ([]inventory.Version, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ProductHistory")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ProductHistory(ctx, id)
+}
+
+func (d database) SearchProducts(ctx context.Context, params inventory.SearchProductsParams) (*inventory.SearchProductsResponse, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "SearchProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.SearchProducts(ctx, params)
+}
+
+func (d database) ExportProducts(ctx context.Context, params inventory.SearchProductsParams, fn func(*inventory.Product) error) error {
+	ctx, cancel, err := d.faults.inject(ctx, "ExportProducts")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.ExportProducts(ctx, params, fn)
+}
+
+func (d database) FindSimilarProducts(ctx context.Context, params inventory.FindSimilarProductsParams) ([]inventory.SimilarProduct, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "FindSimilarProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.FindSimilarProducts(ctx, params)
+}
+
+func (d database) ListTenantProducts(ctx context.Context, params inventory.ListTenantProductsParams) ([]*inventory.Product, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ListTenantProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ListTenantProducts(ctx, params)
+}
+
+func (d database) ApplyProductChanges(ctx context.Context, changes []inventory.ProductChange) error {
+	ctx, cancel, err := d.faults.inject(ctx, "ApplyProductChanges")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.ApplyProductChanges(ctx, changes)
+}
+
+func (d database) CountProducts(ctx context.Context, filter inventory.ProductFilter, limit int) (int, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "CountProducts")
+	defer cancel()
+	if err != nil {
+		return 0, err
+	}
+	return d.next.CountProducts(ctx, filter, limit)
+}
+
+func (d database) DeleteProducts(ctx context.Context, filter inventory.ProductFilter, limit int) (*inventory.DeleteProductsResult, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "DeleteProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.DeleteProducts(ctx, filter, limit)
+}
+
+func (d database) GetProductStats(ctx context.Context, id string) (*inventory.ProductStats, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProductStats")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProductStats(ctx, id)
+}
+
+func (d database) AddFavorite(ctx context.Context, params inventory.FavoriteParams) error {
+	ctx, cancel, err := d.faults.inject(ctx, "AddFavorite")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.AddFavorite(ctx, params)
+}
+
+func (d database) RemoveFavorite(ctx context.Context, params inventory.FavoriteParams) error {
+	ctx, cancel, err := d.faults.inject(ctx, "RemoveFavorite")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.RemoveFavorite(ctx, params)
+}
+
+func (d database) ListFavorites(ctx context.Context, params inventory.ListFavoritesParams) (*inventory.ListFavoritesResponse, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ListFavorites")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ListFavorites(ctx, params)
+}
+
+func (d database) RecordProductView(ctx context.Context, id string) error {
+	ctx, cancel, err := d.faults.inject(ctx, "RecordProductView")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.RecordProductView(ctx, id)
+}
+
+func (d database) ListTrendingProducts(ctx context.Context, params inventory.ListTrendingProductsParams) (*inventory.ListProductsResponse, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ListTrendingProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ListTrendingProducts(ctx, params)
+}
+
+func (d database) ListRecentProducts(ctx context.Context, params inventory.ListRecentProductsParams) (*inventory.ListProductsResponse, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ListRecentProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ListRecentProducts(ctx, params)
+}
+
+func (d database) RecordRecentlyViewed(ctx context.Context, params inventory.RecentlyViewedParams) error {
+	ctx, cancel, err := d.faults.inject(ctx, "RecordRecentlyViewed")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.RecordRecentlyViewed(ctx, params)
+}
+
+func (d database) ListRecentlyViewed(ctx context.Context, params inventory.ListRecentlyViewedParams) (*inventory.ListProductsResponse, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ListRecentlyViewed")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ListRecentlyViewed(ctx, params)
+}
+
+func (d database) CreateSupplier(ctx context.Context, params inventory.CreateSupplierParams) (*inventory.Supplier, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "CreateSupplier")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.CreateSupplier(ctx, params)
+}
+
+func (d database) UpdateSupplier(ctx context.Context, params inventory.UpdateSupplierParams) (*inventory.Supplier, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "UpdateSupplier")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.UpdateSupplier(ctx, params)
+}
+
+func (d database) DeleteSupplier(ctx context.Context, id string) error {
+	ctx, cancel, err := d.faults.inject(ctx, "DeleteSupplier")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.DeleteSupplier(ctx, id)
+}
+
+func (d database) GetSupplier(ctx context.Context, id string) (*inventory.Supplier, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetSupplier")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetSupplier(ctx, id)
+}
+
+func (d database) SetProductSupplier(ctx context.Context, params inventory.ProductSupplier) error {
+	ctx, cancel, err := d.faults.inject(ctx, "SetProductSupplier")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.SetProductSupplier(ctx, params)
+}
+
+func (d database) RemoveProductSupplier(ctx context.Context, productID, supplierID string) error {
+	ctx, cancel, err := d.faults.inject(ctx, "RemoveProductSupplier")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.RemoveProductSupplier(ctx, productID, supplierID)
+}
+
+func (d database) ListProductSuppliers(ctx context.Context, productID string) ([]*inventory.ProductSupplier, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ListProductSuppliers")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ListProductSuppliers(ctx, productID)
+}
+
+func (d database) ListSupplierProducts(ctx context.Context, params inventory.ListSupplierProductsParams) (*inventory.ListProductsResponse, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ListSupplierProducts")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ListSupplierProducts(ctx, params)
+}
+
+func (d database) CreateWarehouse(ctx context.Context, params inventory.CreateWarehouseParams) (*inventory.Warehouse, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "CreateWarehouse")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.CreateWarehouse(ctx, params)
+}
+
+func (d database) ListWarehouses(ctx context.Context) ([]*inventory.Warehouse, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ListWarehouses")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ListWarehouses(ctx)
+}
+
+func (d database) SetProductStock(ctx context.Context, params inventory.SetProductStockParams) error {
+	ctx, cancel, err := d.faults.inject(ctx, "SetProductStock")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.SetProductStock(ctx, params)
+}
+
+func (d database) TransferStock(ctx context.Context, params inventory.TransferStockParams) error {
+	ctx, cancel, err := d.faults.inject(ctx, "TransferStock")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.TransferStock(ctx, params)
+}
+
+func (d database) GetProductStock(ctx context.Context, productID string) ([]*inventory.StockLevel, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProductStock")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProductStock(ctx, productID)
+}
+
+func (d database) DeleteProduct(ctx context.Context, params inventory.DeleteProductParams) error {
+	ctx, cancel, err := d.faults.inject(ctx, "DeleteProduct")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.DeleteProduct(ctx, params)
+}
+
+func (d database) CreateProductReview(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+	ctx, cancel, err := d.faults.inject(ctx, "CreateProductReview")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.CreateProductReview(ctx, params)
+}
+
+func (d database) UpdateProductReview(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+	ctx, cancel, err := d.faults.inject(ctx, "UpdateProductReview")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.UpdateProductReview(ctx, params)
+}
+
+func (d database) GetProductReview(ctx context.Context, id string) (*inventory.ProductReview, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProductReview")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProductReview(ctx, id)
+}
+
+func (d database) GetProductReviews(ctx context.Context, params inventory.ProductReviewsParams) (*inventory.ProductReviewsResponse, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "GetProductReviews")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.GetProductReviews(ctx, params)
+}
+
+func (d database) CountReviewerReviews(ctx context.Context, reviewerID string, since time.Time) (int, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "CountReviewerReviews")
+	defer cancel()
+	if err != nil {
+		return 0, err
+	}
+	return d.next.CountReviewerReviews(ctx, reviewerID, since)
+}
+
+func (d database) ReviewHistory(ctx context.Context, id string) ([]inventory.Version, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "ReviewHistory")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.ReviewHistory(ctx, id)
+}
+
+func (d database) DeleteProductReview(ctx context.Context, id string) error {
+	ctx, cancel, err := d.faults.inject(ctx, "DeleteProductReview")
+	defer cancel()
+	if err != nil {
+		return err
+	}
+	return d.next.DeleteProductReview(ctx, id)
+}
+
+func (d database) PurgeReviewerData(ctx context.Context, params inventory.PurgeReviewerDataParams) (*inventory.PurgeReviewerDataResult, error) {
+	ctx, cancel, err := d.faults.inject(ctx, "PurgeReviewerData")
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	return d.next.PurgeReviewerData(ctx, params)
+}
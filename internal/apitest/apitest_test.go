@@ -0,0 +1,84 @@
+package apitest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/apitest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// contextDB returns the error of the context of the calls.
+type contextDB struct {
+	inventory.DB
+}
+
+func (contextDB) GetProduct(ctx context.Context, id string) (*inventory.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &inventory.Product{ID: id}, nil
+}
+
+var errBroken = errors.New("broken")
+
+func TestFaults(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		faults  apitest.Faults
+		wantErr error
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:    "canceled_db",
+			faults:  apitest.Faults{"GetProduct": {Cancel: true}},
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "deadline_exceeded_any",
+			faults:  apitest.Faults{"*": {DeadlineExceeded: true}},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "error_rate",
+			faults:  apitest.Faults{"GetProduct": {ErrorRate: 1}},
+			wantErr: apitest.ErrInjected,
+		},
+		{
+			name:    "error_rate_err",
+			faults:  apitest.Faults{"*": {ErrorRate: 1, Err: errBroken}},
+			wantErr: errBroken,
+		},
+		{
+			name:   "other_method",
+			faults: apitest.Faults{"SearchProducts": {Cancel: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := inventory.NewService(tt.faults.DB(contextDB{}))
+			if _, err := s.GetProduct(context.Background(), "product"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Service.GetProduct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFaultsMiddlewareLatency(t *testing.T) {
+	t.Parallel()
+	faults := apitest.Faults{"GetProduct": {Latency: time.Hour}}
+	s := inventory.Chain(inventory.NewService(contextDB{}), faults.Middleware())
+
+	// The latency is cut short by the deadline of the request, which the call then observes.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.GetProduct(ctx, "product"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Service.GetProduct() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -19,15 +19,22 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	Inventory_SearchProducts_FullMethodName      = "/api.v1.Inventory/SearchProducts"
-	Inventory_CreateProduct_FullMethodName       = "/api.v1.Inventory/CreateProduct"
-	Inventory_UpdateProduct_FullMethodName       = "/api.v1.Inventory/UpdateProduct"
-	Inventory_DeleteProduct_FullMethodName       = "/api.v1.Inventory/DeleteProduct"
-	Inventory_GetProduct_FullMethodName          = "/api.v1.Inventory/GetProduct"
-	Inventory_CreateProductReview_FullMethodName = "/api.v1.Inventory/CreateProductReview"
-	Inventory_UpdateProductReview_FullMethodName = "/api.v1.Inventory/UpdateProductReview"
-	Inventory_DeleteProductReview_FullMethodName = "/api.v1.Inventory/DeleteProductReview"
-	Inventory_GetProductReview_FullMethodName    = "/api.v1.Inventory/GetProductReview"
+	Inventory_SearchProducts_FullMethodName           = "/api.v1.Inventory/SearchProducts"
+	Inventory_SearchProductsStream_FullMethodName     = "/api.v1.Inventory/SearchProductsStream"
+	Inventory_CreateProduct_FullMethodName            = "/api.v1.Inventory/CreateProduct"
+	Inventory_UpdateProduct_FullMethodName            = "/api.v1.Inventory/UpdateProduct"
+	Inventory_DeleteProduct_FullMethodName            = "/api.v1.Inventory/DeleteProduct"
+	Inventory_GetProduct_FullMethodName               = "/api.v1.Inventory/GetProduct"
+	Inventory_GetProductBySKU_FullMethodName          = "/api.v1.Inventory/GetProductBySKU"
+	Inventory_QuoteProducts_FullMethodName            = "/api.v1.Inventory/QuoteProducts"
+	Inventory_UpsertProductTranslation_FullMethodName = "/api.v1.Inventory/UpsertProductTranslation"
+	Inventory_DeleteProductTranslation_FullMethodName = "/api.v1.Inventory/DeleteProductTranslation"
+	Inventory_ListTrendingProducts_FullMethodName     = "/api.v1.Inventory/ListTrendingProducts"
+	Inventory_ListRecentProducts_FullMethodName       = "/api.v1.Inventory/ListRecentProducts"
+	Inventory_CreateProductReview_FullMethodName      = "/api.v1.Inventory/CreateProductReview"
+	Inventory_UpdateProductReview_FullMethodName      = "/api.v1.Inventory/UpdateProductReview"
+	Inventory_DeleteProductReview_FullMethodName      = "/api.v1.Inventory/DeleteProductReview"
+	Inventory_GetProductReview_FullMethodName         = "/api.v1.Inventory/GetProductReview"
 )
 
 // InventoryClient is the client API for Inventory service.
@@ -37,10 +44,18 @@ const (
 // Inventory gRPC API service.
 type InventoryClient interface {
 	SearchProducts(ctx context.Context, in *SearchProductsRequest, opts ...grpc.CallOption) (*SearchProductsResponse, error)
+	// SearchProductsStream streams the products found by a search in chunks, as they're read, for exports too large for a single response.
+	SearchProductsStream(ctx context.Context, in *SearchProductsStreamRequest, opts ...grpc.CallOption) (Inventory_SearchProductsStreamClient, error)
 	CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*CreateProductResponse, error)
 	UpdateProduct(ctx context.Context, in *UpdateProductRequest, opts ...grpc.CallOption) (*UpdateProductResponse, error)
 	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error)
 	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*GetProductResponse, error)
+	GetProductBySKU(ctx context.Context, in *GetProductBySKURequest, opts ...grpc.CallOption) (*GetProductResponse, error)
+	QuoteProducts(ctx context.Context, in *QuoteProductsRequest, opts ...grpc.CallOption) (*QuoteProductsResponse, error)
+	UpsertProductTranslation(ctx context.Context, in *UpsertProductTranslationRequest, opts ...grpc.CallOption) (*UpsertProductTranslationResponse, error)
+	DeleteProductTranslation(ctx context.Context, in *DeleteProductTranslationRequest, opts ...grpc.CallOption) (*DeleteProductTranslationResponse, error)
+	ListTrendingProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
+	ListRecentProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
 	CreateProductReview(ctx context.Context, in *CreateProductReviewRequest, opts ...grpc.CallOption) (*CreateProductReviewResponse, error)
 	UpdateProductReview(ctx context.Context, in *UpdateProductReviewRequest, opts ...grpc.CallOption) (*UpdateProductReviewResponse, error)
 	DeleteProductReview(ctx context.Context, in *DeleteProductReviewRequest, opts ...grpc.CallOption) (*DeleteProductReviewResponse, error)
@@ -65,6 +80,39 @@ func (c *inventoryClient) SearchProducts(ctx context.Context, in *SearchProducts
 	return out, nil
 }
 
+func (c *inventoryClient) SearchProductsStream(ctx context.Context, in *SearchProductsStreamRequest, opts ...grpc.CallOption) (Inventory_SearchProductsStreamClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Inventory_ServiceDesc.Streams[0], Inventory_SearchProductsStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &inventorySearchProductsStreamClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Inventory_SearchProductsStreamClient interface {
+	Recv() (*SearchProductsStreamResponse, error)
+	grpc.ClientStream
+}
+
+type inventorySearchProductsStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *inventorySearchProductsStreamClient) Recv() (*SearchProductsStreamResponse, error) {
+	m := new(SearchProductsStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *inventoryClient) CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*CreateProductResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateProductResponse)
@@ -105,6 +153,66 @@ func (c *inventoryClient) GetProduct(ctx context.Context, in *GetProductRequest,
 	return out, nil
 }
 
+func (c *inventoryClient) GetProductBySKU(ctx context.Context, in *GetProductBySKURequest, opts ...grpc.CallOption) (*GetProductResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProductResponse)
+	err := c.cc.Invoke(ctx, Inventory_GetProductBySKU_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) QuoteProducts(ctx context.Context, in *QuoteProductsRequest, opts ...grpc.CallOption) (*QuoteProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuoteProductsResponse)
+	err := c.cc.Invoke(ctx, Inventory_QuoteProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) UpsertProductTranslation(ctx context.Context, in *UpsertProductTranslationRequest, opts ...grpc.CallOption) (*UpsertProductTranslationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpsertProductTranslationResponse)
+	err := c.cc.Invoke(ctx, Inventory_UpsertProductTranslation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) DeleteProductTranslation(ctx context.Context, in *DeleteProductTranslationRequest, opts ...grpc.CallOption) (*DeleteProductTranslationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteProductTranslationResponse)
+	err := c.cc.Invoke(ctx, Inventory_DeleteProductTranslation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) ListTrendingProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProductsResponse)
+	err := c.cc.Invoke(ctx, Inventory_ListTrendingProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryClient) ListRecentProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProductsResponse)
+	err := c.cc.Invoke(ctx, Inventory_ListRecentProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *inventoryClient) CreateProductReview(ctx context.Context, in *CreateProductReviewRequest, opts ...grpc.CallOption) (*CreateProductReviewResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateProductReviewResponse)
@@ -152,10 +260,18 @@ func (c *inventoryClient) GetProductReview(ctx context.Context, in *GetProductRe
 // Inventory gRPC API service.
 type InventoryServer interface {
 	SearchProducts(context.Context, *SearchProductsRequest) (*SearchProductsResponse, error)
+	// SearchProductsStream streams the products found by a search in chunks, as they're read, for exports too large for a single response.
+	SearchProductsStream(*SearchProductsStreamRequest, Inventory_SearchProductsStreamServer) error
 	CreateProduct(context.Context, *CreateProductRequest) (*CreateProductResponse, error)
 	UpdateProduct(context.Context, *UpdateProductRequest) (*UpdateProductResponse, error)
 	DeleteProduct(context.Context, *DeleteProductRequest) (*DeleteProductResponse, error)
 	GetProduct(context.Context, *GetProductRequest) (*GetProductResponse, error)
+	GetProductBySKU(context.Context, *GetProductBySKURequest) (*GetProductResponse, error)
+	QuoteProducts(context.Context, *QuoteProductsRequest) (*QuoteProductsResponse, error)
+	UpsertProductTranslation(context.Context, *UpsertProductTranslationRequest) (*UpsertProductTranslationResponse, error)
+	DeleteProductTranslation(context.Context, *DeleteProductTranslationRequest) (*DeleteProductTranslationResponse, error)
+	ListTrendingProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error)
+	ListRecentProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error)
 	CreateProductReview(context.Context, *CreateProductReviewRequest) (*CreateProductReviewResponse, error)
 	UpdateProductReview(context.Context, *UpdateProductReviewRequest) (*UpdateProductReviewResponse, error)
 	DeleteProductReview(context.Context, *DeleteProductReviewRequest) (*DeleteProductReviewResponse, error)
@@ -170,6 +286,9 @@ type UnimplementedInventoryServer struct {
 func (UnimplementedInventoryServer) SearchProducts(context.Context, *SearchProductsRequest) (*SearchProductsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchProducts not implemented")
 }
+func (UnimplementedInventoryServer) SearchProductsStream(*SearchProductsStreamRequest, Inventory_SearchProductsStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SearchProductsStream not implemented")
+}
 func (UnimplementedInventoryServer) CreateProduct(context.Context, *CreateProductRequest) (*CreateProductResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateProduct not implemented")
 }
@@ -182,6 +301,24 @@ func (UnimplementedInventoryServer) DeleteProduct(context.Context, *DeleteProduc
 func (UnimplementedInventoryServer) GetProduct(context.Context, *GetProductRequest) (*GetProductResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetProduct not implemented")
 }
+func (UnimplementedInventoryServer) GetProductBySKU(context.Context, *GetProductBySKURequest) (*GetProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductBySKU not implemented")
+}
+func (UnimplementedInventoryServer) QuoteProducts(context.Context, *QuoteProductsRequest) (*QuoteProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QuoteProducts not implemented")
+}
+func (UnimplementedInventoryServer) UpsertProductTranslation(context.Context, *UpsertProductTranslationRequest) (*UpsertProductTranslationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertProductTranslation not implemented")
+}
+func (UnimplementedInventoryServer) DeleteProductTranslation(context.Context, *DeleteProductTranslationRequest) (*DeleteProductTranslationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProductTranslation not implemented")
+}
+func (UnimplementedInventoryServer) ListTrendingProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrendingProducts not implemented")
+}
+func (UnimplementedInventoryServer) ListRecentProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRecentProducts not implemented")
+}
 func (UnimplementedInventoryServer) CreateProductReview(context.Context, *CreateProductReviewRequest) (*CreateProductReviewResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateProductReview not implemented")
 }
@@ -225,6 +362,27 @@ func _Inventory_SearchProducts_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Inventory_SearchProductsStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchProductsStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InventoryServer).SearchProductsStream(m, &inventorySearchProductsStreamServer{ServerStream: stream})
+}
+
+type Inventory_SearchProductsStreamServer interface {
+	Send(*SearchProductsStreamResponse) error
+	grpc.ServerStream
+}
+
+type inventorySearchProductsStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *inventorySearchProductsStreamServer) Send(m *SearchProductsStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Inventory_CreateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateProductRequest)
 	if err := dec(in); err != nil {
@@ -297,6 +455,114 @@ func _Inventory_GetProduct_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Inventory_GetProductBySKU_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductBySKURequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).GetProductBySKU(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_GetProductBySKU_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).GetProductBySKU(ctx, req.(*GetProductBySKURequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_QuoteProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuoteProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).QuoteProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_QuoteProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).QuoteProducts(ctx, req.(*QuoteProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_UpsertProductTranslation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertProductTranslationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).UpsertProductTranslation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_UpsertProductTranslation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).UpsertProductTranslation(ctx, req.(*UpsertProductTranslationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_DeleteProductTranslation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProductTranslationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).DeleteProductTranslation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_DeleteProductTranslation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).DeleteProductTranslation(ctx, req.(*DeleteProductTranslationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_ListTrendingProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).ListTrendingProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_ListTrendingProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).ListTrendingProducts(ctx, req.(*ListProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Inventory_ListRecentProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServer).ListRecentProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Inventory_ListRecentProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServer).ListRecentProducts(ctx, req.(*ListProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Inventory_CreateProductReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateProductReviewRequest)
 	if err := dec(in); err != nil {
@@ -396,6 +662,30 @@ var Inventory_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetProduct",
 			Handler:    _Inventory_GetProduct_Handler,
 		},
+		{
+			MethodName: "GetProductBySKU",
+			Handler:    _Inventory_GetProductBySKU_Handler,
+		},
+		{
+			MethodName: "QuoteProducts",
+			Handler:    _Inventory_QuoteProducts_Handler,
+		},
+		{
+			MethodName: "UpsertProductTranslation",
+			Handler:    _Inventory_UpsertProductTranslation_Handler,
+		},
+		{
+			MethodName: "DeleteProductTranslation",
+			Handler:    _Inventory_DeleteProductTranslation_Handler,
+		},
+		{
+			MethodName: "ListTrendingProducts",
+			Handler:    _Inventory_ListTrendingProducts_Handler,
+		},
+		{
+			MethodName: "ListRecentProducts",
+			Handler:    _Inventory_ListRecentProducts_Handler,
+		},
 		{
 			MethodName: "CreateProductReview",
 			Handler:    _Inventory_CreateProductReview_Handler,
@@ -413,6 +703,964 @@ var Inventory_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Inventory_GetProductReview_Handler,
 		},
 	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SearchProductsStream",
+			Handler:       _Inventory_SearchProductsStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api.proto",
+}
+
+const (
+	InventoryAdmin_PurgeReviewerData_FullMethodName     = "/api.v1.InventoryAdmin/PurgeReviewerData"
+	InventoryAdmin_GetProductAt_FullMethodName          = "/api.v1.InventoryAdmin/GetProductAt"
+	InventoryAdmin_GetProductHistory_FullMethodName     = "/api.v1.InventoryAdmin/GetProductHistory"
+	InventoryAdmin_GetReviewHistory_FullMethodName      = "/api.v1.InventoryAdmin/GetReviewHistory"
+	InventoryAdmin_CreateSupplier_FullMethodName        = "/api.v1.InventoryAdmin/CreateSupplier"
+	InventoryAdmin_UpdateSupplier_FullMethodName        = "/api.v1.InventoryAdmin/UpdateSupplier"
+	InventoryAdmin_DeleteSupplier_FullMethodName        = "/api.v1.InventoryAdmin/DeleteSupplier"
+	InventoryAdmin_GetSupplier_FullMethodName           = "/api.v1.InventoryAdmin/GetSupplier"
+	InventoryAdmin_SetProductSupplier_FullMethodName    = "/api.v1.InventoryAdmin/SetProductSupplier"
+	InventoryAdmin_RemoveProductSupplier_FullMethodName = "/api.v1.InventoryAdmin/RemoveProductSupplier"
+	InventoryAdmin_ListProductSuppliers_FullMethodName  = "/api.v1.InventoryAdmin/ListProductSuppliers"
+	InventoryAdmin_ListSupplierProducts_FullMethodName  = "/api.v1.InventoryAdmin/ListSupplierProducts"
+	InventoryAdmin_CreateWarehouse_FullMethodName       = "/api.v1.InventoryAdmin/CreateWarehouse"
+	InventoryAdmin_ListWarehouses_FullMethodName        = "/api.v1.InventoryAdmin/ListWarehouses"
+	InventoryAdmin_SetProductStock_FullMethodName       = "/api.v1.InventoryAdmin/SetProductStock"
+	InventoryAdmin_TransferStock_FullMethodName         = "/api.v1.InventoryAdmin/TransferStock"
+	InventoryAdmin_GetProductStock_FullMethodName       = "/api.v1.InventoryAdmin/GetProductStock"
+	InventoryAdmin_ListDeadLetters_FullMethodName       = "/api.v1.InventoryAdmin/ListDeadLetters"
+	InventoryAdmin_GetDeadLetter_FullMethodName         = "/api.v1.InventoryAdmin/GetDeadLetter"
+	InventoryAdmin_ReplayDeadLetters_FullMethodName     = "/api.v1.InventoryAdmin/ReplayDeadLetters"
+	InventoryAdmin_DeleteProducts_FullMethodName        = "/api.v1.InventoryAdmin/DeleteProducts"
+)
+
+// InventoryAdminClient is the client API for InventoryAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// InventoryAdmin gRPC API service for administrative operations.
+// Calls must be authorized with the admin token.
+type InventoryAdminClient interface {
+	PurgeReviewerData(ctx context.Context, in *PurgeReviewerDataRequest, opts ...grpc.CallOption) (*PurgeReviewerDataResponse, error)
+	GetProductAt(ctx context.Context, in *GetProductAtRequest, opts ...grpc.CallOption) (*GetProductAtResponse, error)
+	GetProductHistory(ctx context.Context, in *GetProductHistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error)
+	GetReviewHistory(ctx context.Context, in *GetReviewHistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error)
+	CreateSupplier(ctx context.Context, in *CreateSupplierRequest, opts ...grpc.CallOption) (*CreateSupplierResponse, error)
+	UpdateSupplier(ctx context.Context, in *UpdateSupplierRequest, opts ...grpc.CallOption) (*UpdateSupplierResponse, error)
+	DeleteSupplier(ctx context.Context, in *DeleteSupplierRequest, opts ...grpc.CallOption) (*DeleteSupplierResponse, error)
+	GetSupplier(ctx context.Context, in *GetSupplierRequest, opts ...grpc.CallOption) (*GetSupplierResponse, error)
+	SetProductSupplier(ctx context.Context, in *SetProductSupplierRequest, opts ...grpc.CallOption) (*SetProductSupplierResponse, error)
+	RemoveProductSupplier(ctx context.Context, in *RemoveProductSupplierRequest, opts ...grpc.CallOption) (*RemoveProductSupplierResponse, error)
+	ListProductSuppliers(ctx context.Context, in *ListProductSuppliersRequest, opts ...grpc.CallOption) (*ListProductSuppliersResponse, error)
+	ListSupplierProducts(ctx context.Context, in *ListSupplierProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
+	CreateWarehouse(ctx context.Context, in *CreateWarehouseRequest, opts ...grpc.CallOption) (*CreateWarehouseResponse, error)
+	ListWarehouses(ctx context.Context, in *ListWarehousesRequest, opts ...grpc.CallOption) (*ListWarehousesResponse, error)
+	SetProductStock(ctx context.Context, in *SetProductStockRequest, opts ...grpc.CallOption) (*SetProductStockResponse, error)
+	TransferStock(ctx context.Context, in *TransferStockRequest, opts ...grpc.CallOption) (*TransferStockResponse, error)
+	GetProductStock(ctx context.Context, in *GetProductStockRequest, opts ...grpc.CallOption) (*GetProductStockResponse, error)
+	ListDeadLetters(ctx context.Context, in *ListDeadLettersRequest, opts ...grpc.CallOption) (*ListDeadLettersResponse, error)
+	GetDeadLetter(ctx context.Context, in *GetDeadLetterRequest, opts ...grpc.CallOption) (*GetDeadLetterResponse, error)
+	ReplayDeadLetters(ctx context.Context, in *ReplayDeadLettersRequest, opts ...grpc.CallOption) (*ReplayDeadLettersResponse, error)
+	DeleteProducts(ctx context.Context, in *DeleteProductsRequest, opts ...grpc.CallOption) (*DeleteProductsResponse, error)
+}
+
+type inventoryAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInventoryAdminClient(cc grpc.ClientConnInterface) InventoryAdminClient {
+	return &inventoryAdminClient{cc}
+}
+
+func (c *inventoryAdminClient) PurgeReviewerData(ctx context.Context, in *PurgeReviewerDataRequest, opts ...grpc.CallOption) (*PurgeReviewerDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeReviewerDataResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_PurgeReviewerData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) GetProductAt(ctx context.Context, in *GetProductAtRequest, opts ...grpc.CallOption) (*GetProductAtResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProductAtResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_GetProductAt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) GetProductHistory(ctx context.Context, in *GetProductHistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HistoryResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_GetProductHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) GetReviewHistory(ctx context.Context, in *GetReviewHistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HistoryResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_GetReviewHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) CreateSupplier(ctx context.Context, in *CreateSupplierRequest, opts ...grpc.CallOption) (*CreateSupplierResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSupplierResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_CreateSupplier_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) UpdateSupplier(ctx context.Context, in *UpdateSupplierRequest, opts ...grpc.CallOption) (*UpdateSupplierResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateSupplierResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_UpdateSupplier_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) DeleteSupplier(ctx context.Context, in *DeleteSupplierRequest, opts ...grpc.CallOption) (*DeleteSupplierResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSupplierResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_DeleteSupplier_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) GetSupplier(ctx context.Context, in *GetSupplierRequest, opts ...grpc.CallOption) (*GetSupplierResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSupplierResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_GetSupplier_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) SetProductSupplier(ctx context.Context, in *SetProductSupplierRequest, opts ...grpc.CallOption) (*SetProductSupplierResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetProductSupplierResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_SetProductSupplier_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) RemoveProductSupplier(ctx context.Context, in *RemoveProductSupplierRequest, opts ...grpc.CallOption) (*RemoveProductSupplierResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveProductSupplierResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_RemoveProductSupplier_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) ListProductSuppliers(ctx context.Context, in *ListProductSuppliersRequest, opts ...grpc.CallOption) (*ListProductSuppliersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProductSuppliersResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_ListProductSuppliers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) ListSupplierProducts(ctx context.Context, in *ListSupplierProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProductsResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_ListSupplierProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) CreateWarehouse(ctx context.Context, in *CreateWarehouseRequest, opts ...grpc.CallOption) (*CreateWarehouseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateWarehouseResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_CreateWarehouse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) ListWarehouses(ctx context.Context, in *ListWarehousesRequest, opts ...grpc.CallOption) (*ListWarehousesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWarehousesResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_ListWarehouses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) SetProductStock(ctx context.Context, in *SetProductStockRequest, opts ...grpc.CallOption) (*SetProductStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetProductStockResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_SetProductStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) TransferStock(ctx context.Context, in *TransferStockRequest, opts ...grpc.CallOption) (*TransferStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransferStockResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_TransferStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) GetProductStock(ctx context.Context, in *GetProductStockRequest, opts ...grpc.CallOption) (*GetProductStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProductStockResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_GetProductStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) ListDeadLetters(ctx context.Context, in *ListDeadLettersRequest, opts ...grpc.CallOption) (*ListDeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDeadLettersResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_ListDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) GetDeadLetter(ctx context.Context, in *GetDeadLetterRequest, opts ...grpc.CallOption) (*GetDeadLetterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDeadLetterResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_GetDeadLetter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) ReplayDeadLetters(ctx context.Context, in *ReplayDeadLettersRequest, opts ...grpc.CallOption) (*ReplayDeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplayDeadLettersResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_ReplayDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryAdminClient) DeleteProducts(ctx context.Context, in *DeleteProductsRequest, opts ...grpc.CallOption) (*DeleteProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteProductsResponse)
+	err := c.cc.Invoke(ctx, InventoryAdmin_DeleteProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InventoryAdminServer is the server API for InventoryAdmin service.
+// All implementations must embed UnimplementedInventoryAdminServer
+// for forward compatibility
+//
+// InventoryAdmin gRPC API service for administrative operations.
+// Calls must be authorized with the admin token.
+type InventoryAdminServer interface {
+	PurgeReviewerData(context.Context, *PurgeReviewerDataRequest) (*PurgeReviewerDataResponse, error)
+	GetProductAt(context.Context, *GetProductAtRequest) (*GetProductAtResponse, error)
+	GetProductHistory(context.Context, *GetProductHistoryRequest) (*HistoryResponse, error)
+	GetReviewHistory(context.Context, *GetReviewHistoryRequest) (*HistoryResponse, error)
+	CreateSupplier(context.Context, *CreateSupplierRequest) (*CreateSupplierResponse, error)
+	UpdateSupplier(context.Context, *UpdateSupplierRequest) (*UpdateSupplierResponse, error)
+	DeleteSupplier(context.Context, *DeleteSupplierRequest) (*DeleteSupplierResponse, error)
+	GetSupplier(context.Context, *GetSupplierRequest) (*GetSupplierResponse, error)
+	SetProductSupplier(context.Context, *SetProductSupplierRequest) (*SetProductSupplierResponse, error)
+	RemoveProductSupplier(context.Context, *RemoveProductSupplierRequest) (*RemoveProductSupplierResponse, error)
+	ListProductSuppliers(context.Context, *ListProductSuppliersRequest) (*ListProductSuppliersResponse, error)
+	ListSupplierProducts(context.Context, *ListSupplierProductsRequest) (*ListProductsResponse, error)
+	CreateWarehouse(context.Context, *CreateWarehouseRequest) (*CreateWarehouseResponse, error)
+	ListWarehouses(context.Context, *ListWarehousesRequest) (*ListWarehousesResponse, error)
+	SetProductStock(context.Context, *SetProductStockRequest) (*SetProductStockResponse, error)
+	TransferStock(context.Context, *TransferStockRequest) (*TransferStockResponse, error)
+	GetProductStock(context.Context, *GetProductStockRequest) (*GetProductStockResponse, error)
+	ListDeadLetters(context.Context, *ListDeadLettersRequest) (*ListDeadLettersResponse, error)
+	GetDeadLetter(context.Context, *GetDeadLetterRequest) (*GetDeadLetterResponse, error)
+	ReplayDeadLetters(context.Context, *ReplayDeadLettersRequest) (*ReplayDeadLettersResponse, error)
+	DeleteProducts(context.Context, *DeleteProductsRequest) (*DeleteProductsResponse, error)
+	mustEmbedUnimplementedInventoryAdminServer()
+}
+
+// UnimplementedInventoryAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedInventoryAdminServer struct {
+}
+
+func (UnimplementedInventoryAdminServer) PurgeReviewerData(context.Context, *PurgeReviewerDataRequest) (*PurgeReviewerDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeReviewerData not implemented")
+}
+func (UnimplementedInventoryAdminServer) GetProductAt(context.Context, *GetProductAtRequest) (*GetProductAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductAt not implemented")
+}
+func (UnimplementedInventoryAdminServer) GetProductHistory(context.Context, *GetProductHistoryRequest) (*HistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductHistory not implemented")
+}
+func (UnimplementedInventoryAdminServer) GetReviewHistory(context.Context, *GetReviewHistoryRequest) (*HistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReviewHistory not implemented")
+}
+func (UnimplementedInventoryAdminServer) CreateSupplier(context.Context, *CreateSupplierRequest) (*CreateSupplierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSupplier not implemented")
+}
+func (UnimplementedInventoryAdminServer) UpdateSupplier(context.Context, *UpdateSupplierRequest) (*UpdateSupplierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSupplier not implemented")
+}
+func (UnimplementedInventoryAdminServer) DeleteSupplier(context.Context, *DeleteSupplierRequest) (*DeleteSupplierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSupplier not implemented")
+}
+func (UnimplementedInventoryAdminServer) GetSupplier(context.Context, *GetSupplierRequest) (*GetSupplierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSupplier not implemented")
+}
+func (UnimplementedInventoryAdminServer) SetProductSupplier(context.Context, *SetProductSupplierRequest) (*SetProductSupplierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetProductSupplier not implemented")
+}
+func (UnimplementedInventoryAdminServer) RemoveProductSupplier(context.Context, *RemoveProductSupplierRequest) (*RemoveProductSupplierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveProductSupplier not implemented")
+}
+func (UnimplementedInventoryAdminServer) ListProductSuppliers(context.Context, *ListProductSuppliersRequest) (*ListProductSuppliersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProductSuppliers not implemented")
+}
+func (UnimplementedInventoryAdminServer) ListSupplierProducts(context.Context, *ListSupplierProductsRequest) (*ListProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSupplierProducts not implemented")
+}
+func (UnimplementedInventoryAdminServer) CreateWarehouse(context.Context, *CreateWarehouseRequest) (*CreateWarehouseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWarehouse not implemented")
+}
+func (UnimplementedInventoryAdminServer) ListWarehouses(context.Context, *ListWarehousesRequest) (*ListWarehousesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWarehouses not implemented")
+}
+func (UnimplementedInventoryAdminServer) SetProductStock(context.Context, *SetProductStockRequest) (*SetProductStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetProductStock not implemented")
+}
+func (UnimplementedInventoryAdminServer) TransferStock(context.Context, *TransferStockRequest) (*TransferStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransferStock not implemented")
+}
+func (UnimplementedInventoryAdminServer) GetProductStock(context.Context, *GetProductStockRequest) (*GetProductStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductStock not implemented")
+}
+func (UnimplementedInventoryAdminServer) ListDeadLetters(context.Context, *ListDeadLettersRequest) (*ListDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeadLetters not implemented")
+}
+func (UnimplementedInventoryAdminServer) GetDeadLetter(context.Context, *GetDeadLetterRequest) (*GetDeadLetterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeadLetter not implemented")
+}
+func (UnimplementedInventoryAdminServer) ReplayDeadLetters(context.Context, *ReplayDeadLettersRequest) (*ReplayDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayDeadLetters not implemented")
+}
+func (UnimplementedInventoryAdminServer) DeleteProducts(context.Context, *DeleteProductsRequest) (*DeleteProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProducts not implemented")
+}
+func (UnimplementedInventoryAdminServer) mustEmbedUnimplementedInventoryAdminServer() {}
+
+// UnsafeInventoryAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InventoryAdminServer will
+// result in compilation errors.
+type UnsafeInventoryAdminServer interface {
+	mustEmbedUnimplementedInventoryAdminServer()
+}
+
+func RegisterInventoryAdminServer(s grpc.ServiceRegistrar, srv InventoryAdminServer) {
+	s.RegisterService(&InventoryAdmin_ServiceDesc, srv)
+}
+
+func _InventoryAdmin_PurgeReviewerData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeReviewerDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).PurgeReviewerData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_PurgeReviewerData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).PurgeReviewerData(ctx, req.(*PurgeReviewerDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_GetProductAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).GetProductAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_GetProductAt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).GetProductAt(ctx, req.(*GetProductAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_GetProductHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).GetProductHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_GetProductHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).GetProductHistory(ctx, req.(*GetProductHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_GetReviewHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReviewHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).GetReviewHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_GetReviewHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).GetReviewHistory(ctx, req.(*GetReviewHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_CreateSupplier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSupplierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).CreateSupplier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_CreateSupplier_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).CreateSupplier(ctx, req.(*CreateSupplierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_UpdateSupplier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSupplierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).UpdateSupplier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_UpdateSupplier_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).UpdateSupplier(ctx, req.(*UpdateSupplierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_DeleteSupplier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSupplierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).DeleteSupplier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_DeleteSupplier_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).DeleteSupplier(ctx, req.(*DeleteSupplierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_GetSupplier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSupplierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).GetSupplier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_GetSupplier_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).GetSupplier(ctx, req.(*GetSupplierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_SetProductSupplier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetProductSupplierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).SetProductSupplier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_SetProductSupplier_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).SetProductSupplier(ctx, req.(*SetProductSupplierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_RemoveProductSupplier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveProductSupplierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).RemoveProductSupplier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_RemoveProductSupplier_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).RemoveProductSupplier(ctx, req.(*RemoveProductSupplierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_ListProductSuppliers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductSuppliersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).ListProductSuppliers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_ListProductSuppliers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).ListProductSuppliers(ctx, req.(*ListProductSuppliersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_ListSupplierProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSupplierProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).ListSupplierProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_ListSupplierProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).ListSupplierProducts(ctx, req.(*ListSupplierProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_CreateWarehouse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWarehouseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).CreateWarehouse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_CreateWarehouse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).CreateWarehouse(ctx, req.(*CreateWarehouseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_ListWarehouses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWarehousesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).ListWarehouses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_ListWarehouses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).ListWarehouses(ctx, req.(*ListWarehousesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_SetProductStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetProductStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).SetProductStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_SetProductStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).SetProductStock(ctx, req.(*SetProductStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_TransferStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).TransferStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_TransferStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).TransferStock(ctx, req.(*TransferStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_GetProductStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).GetProductStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_GetProductStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).GetProductStock(ctx, req.(*GetProductStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_ListDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).ListDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_ListDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).ListDeadLetters(ctx, req.(*ListDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_GetDeadLetter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeadLetterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).GetDeadLetter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_GetDeadLetter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).GetDeadLetter(ctx, req.(*GetDeadLetterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_ReplayDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).ReplayDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_ReplayDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).ReplayDeadLetters(ctx, req.(*ReplayDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryAdmin_DeleteProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryAdminServer).DeleteProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryAdmin_DeleteProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryAdminServer).DeleteProducts(ctx, req.(*DeleteProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InventoryAdmin_ServiceDesc is the grpc.ServiceDesc for InventoryAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InventoryAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.v1.InventoryAdmin",
+	HandlerType: (*InventoryAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PurgeReviewerData",
+			Handler:    _InventoryAdmin_PurgeReviewerData_Handler,
+		},
+		{
+			MethodName: "GetProductAt",
+			Handler:    _InventoryAdmin_GetProductAt_Handler,
+		},
+		{
+			MethodName: "GetProductHistory",
+			Handler:    _InventoryAdmin_GetProductHistory_Handler,
+		},
+		{
+			MethodName: "GetReviewHistory",
+			Handler:    _InventoryAdmin_GetReviewHistory_Handler,
+		},
+		{
+			MethodName: "CreateSupplier",
+			Handler:    _InventoryAdmin_CreateSupplier_Handler,
+		},
+		{
+			MethodName: "UpdateSupplier",
+			Handler:    _InventoryAdmin_UpdateSupplier_Handler,
+		},
+		{
+			MethodName: "DeleteSupplier",
+			Handler:    _InventoryAdmin_DeleteSupplier_Handler,
+		},
+		{
+			MethodName: "GetSupplier",
+			Handler:    _InventoryAdmin_GetSupplier_Handler,
+		},
+		{
+			MethodName: "SetProductSupplier",
+			Handler:    _InventoryAdmin_SetProductSupplier_Handler,
+		},
+		{
+			MethodName: "RemoveProductSupplier",
+			Handler:    _InventoryAdmin_RemoveProductSupplier_Handler,
+		},
+		{
+			MethodName: "ListProductSuppliers",
+			Handler:    _InventoryAdmin_ListProductSuppliers_Handler,
+		},
+		{
+			MethodName: "ListSupplierProducts",
+			Handler:    _InventoryAdmin_ListSupplierProducts_Handler,
+		},
+		{
+			MethodName: "CreateWarehouse",
+			Handler:    _InventoryAdmin_CreateWarehouse_Handler,
+		},
+		{
+			MethodName: "ListWarehouses",
+			Handler:    _InventoryAdmin_ListWarehouses_Handler,
+		},
+		{
+			MethodName: "SetProductStock",
+			Handler:    _InventoryAdmin_SetProductStock_Handler,
+		},
+		{
+			MethodName: "TransferStock",
+			Handler:    _InventoryAdmin_TransferStock_Handler,
+		},
+		{
+			MethodName: "GetProductStock",
+			Handler:    _InventoryAdmin_GetProductStock_Handler,
+		},
+		{
+			MethodName: "ListDeadLetters",
+			Handler:    _InventoryAdmin_ListDeadLetters_Handler,
+		},
+		{
+			MethodName: "GetDeadLetter",
+			Handler:    _InventoryAdmin_GetDeadLetter_Handler,
+		},
+		{
+			MethodName: "ReplayDeadLetters",
+			Handler:    _InventoryAdmin_ReplayDeadLetters_Handler,
+		},
+		{
+			MethodName: "DeleteProducts",
+			Handler:    _InventoryAdmin_DeleteProducts_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api.proto",
+}
+
+const (
+	Build_GetBuildInfo_FullMethodName = "/api.v1.Build/GetBuildInfo"
+)
+
+// BuildClient is the client API for Build service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Build gRPC API service exposing metadata about the running binary.
+type BuildClient interface {
+	GetBuildInfo(ctx context.Context, in *GetBuildInfoRequest, opts ...grpc.CallOption) (*GetBuildInfoResponse, error)
+}
+
+type buildClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBuildClient(cc grpc.ClientConnInterface) BuildClient {
+	return &buildClient{cc}
+}
+
+func (c *buildClient) GetBuildInfo(ctx context.Context, in *GetBuildInfoRequest, opts ...grpc.CallOption) (*GetBuildInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBuildInfoResponse)
+	err := c.cc.Invoke(ctx, Build_GetBuildInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BuildServer is the server API for Build service.
+// All implementations must embed UnimplementedBuildServer
+// for forward compatibility
+//
+// Build gRPC API service exposing metadata about the running binary.
+type BuildServer interface {
+	GetBuildInfo(context.Context, *GetBuildInfoRequest) (*GetBuildInfoResponse, error)
+	mustEmbedUnimplementedBuildServer()
+}
+
+// UnimplementedBuildServer must be embedded to have forward compatible implementations.
+type UnimplementedBuildServer struct {
+}
+
+func (UnimplementedBuildServer) GetBuildInfo(context.Context, *GetBuildInfoRequest) (*GetBuildInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBuildInfo not implemented")
+}
+func (UnimplementedBuildServer) mustEmbedUnimplementedBuildServer() {}
+
+// UnsafeBuildServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BuildServer will
+// result in compilation errors.
+type UnsafeBuildServer interface {
+	mustEmbedUnimplementedBuildServer()
+}
+
+func RegisterBuildServer(s grpc.ServiceRegistrar, srv BuildServer) {
+	s.RegisterService(&Build_ServiceDesc, srv)
+}
+
+func _Build_GetBuildInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBuildInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BuildServer).GetBuildInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Build_GetBuildInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BuildServer).GetBuildInfo(ctx, req.(*GetBuildInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Build_ServiceDesc is the grpc.ServiceDesc for Build service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Build_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.v1.Build",
+	HandlerType: (*BuildServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBuildInfo",
+			Handler:    _Build_GetBuildInfo_Handler,
+		},
+	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api.proto",
 }
@@ -0,0 +1,315 @@
+package apiv1
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/apiv1/apipb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var update = flag.Bool("update", false, "Update the golden descriptor set with the current descriptors, if they're backward compatible")
+
+// golden descriptor set of the API, as released to clients.
+const golden = "testdata/api.binpb"
+
+// TestBackwardCompatibility checks that the API is backward compatible with the golden descriptor set,
+// so clients built with it keep working.
+//
+// Changes that break clients belong to a new version of the API, such as api.v2.
+// Compatible changes, such as new fields, require updating the golden descriptor set with:
+// go test ./internal/apiv1 -update
+func TestBackwardCompatibility(t *testing.T) {
+	b, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("cannot read golden descriptor set: %v", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(b, &set); err != nil {
+		t.Fatalf("cannot unmarshal golden descriptor set: %v", err)
+	}
+	current := protodesc.ToFileDescriptorProto(apipb.File_api_proto)
+	i := slices.IndexFunc(set.File, func(f *descriptorpb.FileDescriptorProto) bool {
+		return f.GetName() == current.GetName()
+	})
+	if i == -1 {
+		t.Fatalf("golden descriptor set has no %s", current.GetName())
+	}
+	for _, c := range breakingChanges(set.File[i], current) {
+		t.Errorf("breaking change: %s", c)
+	}
+	// Additions are found as breaking changes of the current descriptors to the golden ones.
+	if t.Failed() || len(breakingChanges(current, set.File[i])) == 0 {
+		return
+	}
+	if !*update {
+		t.Fatalf("golden descriptor set is outdated: update it with go test ./internal/apiv1 -update")
+	}
+	set.File[i] = current
+	if b, err = (proto.MarshalOptions{Deterministic: true}).Marshal(&set); err != nil {
+		t.Fatalf("cannot marshal descriptor set: %v", err)
+	}
+	if err := os.WriteFile(golden, b, 0o644); err != nil { // #nosec G306
+		t.Fatalf("cannot write golden descriptor set: %v", err)
+	}
+}
+
+func TestBreakingChanges(t *testing.T) {
+	t.Parallel()
+	base := func() *descriptorpb.FileDescriptorProto {
+		return &descriptorpb.FileDescriptorProto{
+			Name:    proto.String("api.proto"),
+			Package: proto.String("api.v1"),
+			MessageType: []*descriptorpb.DescriptorProto{
+				{
+					Name: proto.String("Product"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						{Name: proto.String("id"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+						{Name: proto.String("price"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum()},
+					},
+				},
+			},
+			EnumType: []*descriptorpb.EnumDescriptorProto{
+				{
+					Name: proto.String("Status"),
+					Value: []*descriptorpb.EnumValueDescriptorProto{
+						{Name: proto.String("STATUS_UNSPECIFIED"), Number: proto.Int32(0)},
+						{Name: proto.String("STATUS_ACTIVE"), Number: proto.Int32(1)},
+					},
+				},
+			},
+			Service: []*descriptorpb.ServiceDescriptorProto{
+				{
+					Name: proto.String("Inventory"),
+					Method: []*descriptorpb.MethodDescriptorProto{
+						{Name: proto.String("GetProduct"), InputType: proto.String(".api.v1.Product"), OutputType: proto.String(".api.v1.Product")},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(f *descriptorpb.FileDescriptorProto)
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			change: func(f *descriptorpb.FileDescriptorProto) {},
+		},
+		{
+			name: "compatible_additions",
+			change: func(f *descriptorpb.FileDescriptorProto) {
+				f.MessageType[0].Field = append(f.MessageType[0].Field, &descriptorpb.FieldDescriptorProto{
+					Name: proto.String("name"), Number: proto.Int32(3), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				})
+				f.MessageType = append(f.MessageType, &descriptorpb.DescriptorProto{Name: proto.String("Review")})
+				f.EnumType[0].Value = append(f.EnumType[0].Value, &descriptorpb.EnumValueDescriptorProto{
+					Name: proto.String("STATUS_DRAFT"), Number: proto.Int32(2),
+				})
+				f.Service[0].Method = append(f.Service[0].Method, &descriptorpb.MethodDescriptorProto{
+					Name: proto.String("DeleteProduct"), InputType: proto.String(".api.v1.Product"), OutputType: proto.String(".api.v1.Product"),
+				})
+			},
+		},
+		{
+			name: "reserved_field_removed",
+			change: func(f *descriptorpb.FileDescriptorProto) {
+				f.MessageType[0].Field = f.MessageType[0].Field[:1]
+				f.MessageType[0].ReservedRange = []*descriptorpb.DescriptorProto_ReservedRange{
+					{Start: proto.Int32(2), End: proto.Int32(3)},
+				}
+			},
+		},
+		{
+			name: "field_removed",
+			change: func(f *descriptorpb.FileDescriptorProto) {
+				f.MessageType[0].Field = f.MessageType[0].Field[:1]
+			},
+			want: []string{"field api.v1.Product.price (2) removed without being reserved"},
+		},
+		{
+			name: "field_changed",
+			change: func(f *descriptorpb.FileDescriptorProto) {
+				f.MessageType[0].Field[0].Name = proto.String("uuid")
+				f.MessageType[0].Field[1].Type = descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+				f.MessageType[0].Field[1].Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+				f.MessageType[0].Field[1].Proto3Optional = proto.Bool(true)
+			},
+			want: []string{
+				"field api.v1.Product.id (1) renamed to uuid",
+				"field api.v1.Product.price (2) changed type from TYPE_INT64 to TYPE_INT32",
+				"field api.v1.Product.price (2) changed label from LABEL_OPTIONAL to LABEL_REPEATED",
+				"field api.v1.Product.price (2) changed presence",
+			},
+		},
+		{
+			name: "removals",
+			change: func(f *descriptorpb.FileDescriptorProto) {
+				f.MessageType = nil
+				f.EnumType[0].Value = f.EnumType[0].Value[:1]
+				f.Service[0].Method = nil
+			},
+			want: []string{
+				"message api.v1.Product removed",
+				"enum value api.v1.Status.STATUS_ACTIVE (1) removed without being reserved",
+				"method api.v1.Inventory.GetProduct removed",
+			},
+		},
+		{
+			name: "method_changed",
+			change: func(f *descriptorpb.FileDescriptorProto) {
+				f.Service[0].Method[0].InputType = proto.String(".api.v1.GetProductRequest")
+				f.Service[0].Method[0].ServerStreaming = proto.Bool(true)
+			},
+			want: []string{
+				"method api.v1.Inventory.GetProduct changed request from .api.v1.Product to .api.v1.GetProductRequest",
+				"method api.v1.Inventory.GetProduct changed streaming",
+			},
+		},
+		{
+			name: "package_and_service",
+			change: func(f *descriptorpb.FileDescriptorProto) {
+				f.Package = proto.String("api.v2")
+				f.Service = nil
+			},
+			want: []string{"package changed from api.v1 to api.v2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := base()
+			tt.change(f)
+			if got := breakingChanges(base(), f); !slices.Equal(got, tt.want) {
+				t.Errorf("breakingChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// breakingChanges of a file, which would break clients built with its old version,
+// on the wire, in the JSON mapping, or in the generated code.
+func breakingChanges(old, cur *descriptorpb.FileDescriptorProto) []string {
+	var changes []string
+	if old.GetPackage() != cur.GetPackage() {
+		// Everything would be reported as removed otherwise.
+		return []string{fmt.Sprintf("package changed from %s to %s", old.GetPackage(), cur.GetPackage())}
+	}
+	changes = append(changes, messageChanges(old.GetPackage(), old.MessageType, cur.MessageType)...)
+	changes = append(changes, enumChanges(old.GetPackage(), old.EnumType, cur.EnumType)...)
+	for _, svc := range old.Service {
+		name := old.GetPackage() + "." + svc.GetName()
+		i := slices.IndexFunc(cur.Service, func(s *descriptorpb.ServiceDescriptorProto) bool { return s.GetName() == svc.GetName() })
+		if i == -1 {
+			changes = append(changes, fmt.Sprintf("service %s removed", name))
+			continue
+		}
+		for _, om := range svc.Method {
+			mname := name + "." + om.GetName()
+			j := slices.IndexFunc(cur.Service[i].Method, func(m *descriptorpb.MethodDescriptorProto) bool { return m.GetName() == om.GetName() })
+			if j == -1 {
+				changes = append(changes, fmt.Sprintf("method %s removed", mname))
+				continue
+			}
+			nm := cur.Service[i].Method[j]
+			if om.GetInputType() != nm.GetInputType() {
+				changes = append(changes, fmt.Sprintf("method %s changed request from %s to %s", mname, om.GetInputType(), nm.GetInputType()))
+			}
+			if om.GetOutputType() != nm.GetOutputType() {
+				changes = append(changes, fmt.Sprintf("method %s changed response from %s to %s", mname, om.GetOutputType(), nm.GetOutputType()))
+			}
+			if om.GetClientStreaming() != nm.GetClientStreaming() || om.GetServerStreaming() != nm.GetServerStreaming() {
+				changes = append(changes, fmt.Sprintf("method %s changed streaming", mname))
+			}
+		}
+	}
+	return changes
+}
+
+// messageChanges of messages, including nested ones, in a scope such as the package.
+func messageChanges(scope string, old, cur []*descriptorpb.DescriptorProto) []string {
+	var changes []string
+	for _, om := range old {
+		name := scope + "." + om.GetName()
+		i := slices.IndexFunc(cur, func(m *descriptorpb.DescriptorProto) bool { return m.GetName() == om.GetName() })
+		if i == -1 {
+			changes = append(changes, fmt.Sprintf("message %s removed", name))
+			continue
+		}
+		nm := cur[i]
+		for _, of := range om.Field {
+			fname := fmt.Sprintf("%s.%s (%d)", name, of.GetName(), of.GetNumber())
+			j := slices.IndexFunc(nm.Field, func(f *descriptorpb.FieldDescriptorProto) bool { return f.GetNumber() == of.GetNumber() })
+			if j == -1 {
+				reserved := slices.ContainsFunc(nm.ReservedRange, func(r *descriptorpb.DescriptorProto_ReservedRange) bool {
+					return r.GetStart() <= of.GetNumber() && of.GetNumber() < r.GetEnd() // End is exclusive.
+				})
+				if !reserved {
+					changes = append(changes, fmt.Sprintf("field %s removed without being reserved", fname))
+				}
+				continue
+			}
+			nf := nm.Field[j]
+			if of.GetName() != nf.GetName() {
+				changes = append(changes, fmt.Sprintf("field %s renamed to %s", fname, nf.GetName()))
+			}
+			if of.GetType() != nf.GetType() || of.GetTypeName() != nf.GetTypeName() {
+				changes = append(changes, fmt.Sprintf("field %s changed type from %s to %s", fname, fieldType(of), fieldType(nf)))
+			}
+			if of.GetLabel() != nf.GetLabel() {
+				changes = append(changes, fmt.Sprintf("field %s changed label from %s to %s", fname, of.GetLabel(), nf.GetLabel()))
+			}
+			if of.GetProto3Optional() != nf.GetProto3Optional() {
+				changes = append(changes, fmt.Sprintf("field %s changed presence", fname))
+			}
+		}
+		changes = append(changes, messageChanges(name, om.NestedType, nm.NestedType)...)
+		changes = append(changes, enumChanges(name, om.EnumType, nm.EnumType)...)
+	}
+	return changes
+}
+
+// enumChanges of enums in a scope such as the package.
+func enumChanges(scope string, old, cur []*descriptorpb.EnumDescriptorProto) []string {
+	var changes []string
+	for _, oe := range old {
+		name := scope + "." + oe.GetName()
+		i := slices.IndexFunc(cur, func(e *descriptorpb.EnumDescriptorProto) bool { return e.GetName() == oe.GetName() })
+		if i == -1 {
+			changes = append(changes, fmt.Sprintf("enum %s removed", name))
+			continue
+		}
+		ne := cur[i]
+		for _, ov := range oe.Value {
+			vname := fmt.Sprintf("%s.%s (%d)", name, ov.GetName(), ov.GetNumber())
+			j := slices.IndexFunc(ne.Value, func(v *descriptorpb.EnumValueDescriptorProto) bool { return v.GetNumber() == ov.GetNumber() })
+			if j == -1 {
+				reserved := slices.ContainsFunc(ne.ReservedRange, func(r *descriptorpb.EnumDescriptorProto_EnumReservedRange) bool {
+					return r.GetStart() <= ov.GetNumber() && ov.GetNumber() <= r.GetEnd() // End is inclusive.
+				})
+				if !reserved {
+					changes = append(changes, fmt.Sprintf("enum value %s removed without being reserved", vname))
+				}
+				continue
+			}
+			if ov.GetName() != ne.Value[j].GetName() {
+				changes = append(changes, fmt.Sprintf("enum value %s renamed to %s", vname, ne.Value[j].GetName()))
+			}
+		}
+	}
+	return changes
+}
+
+// fieldType returns the type of a field, with the name of its message or enum type, if any.
+func fieldType(f *descriptorpb.FieldDescriptorProto) string {
+	if f.GetTypeName() != "" {
+		return f.GetType().String() + " " + f.GetTypeName()
+	}
+	return f.GetType().String()
+}
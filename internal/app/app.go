@@ -0,0 +1,674 @@
+// Package app builds the components of the program, such as the database, services, workers, and servers,
+// so binaries can be composed of the ones they need.
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felixge/fgprof"
+	"github.com/henvic/pgxtutorial/internal/api"
+	"github.com/henvic/pgxtutorial/internal/buildinfo"
+	"github.com/henvic/pgxtutorial/internal/cdc"
+	"github.com/henvic/pgxtutorial/internal/contentfilter"
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/faultinject"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/langdetect"
+	"github.com/henvic/pgxtutorial/internal/loadshed"
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	"github.com/henvic/pgxtutorial/internal/postgres"
+	"github.com/henvic/pgxtutorial/internal/profiling"
+	"github.com/henvic/pgxtutorial/internal/replay"
+	"github.com/henvic/pgxtutorial/internal/sentiment"
+	"github.com/henvic/pgxtutorial/internal/slo"
+	"github.com/henvic/pgxtutorial/internal/summary"
+	"github.com/henvic/pgxtutorial/internal/usage"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// App builds the components of the program from its Config.
+//
+// Components are built on first use, along with the components they depend on,
+// and are released by Close in the reverse order.
+// An App must not be used concurrently.
+type App struct {
+	config Config
+	build  buildinfo.Info
+	tel    *Telemetry
+
+	db        *postgres.DB
+	maxConns  int32
+	inventory inventory.API
+	workers   []api.Runner
+	sizers    []api.Runner
+
+	closers []func()
+}
+
+// New App.
+func New(config Config, build buildinfo.Info, tel *Telemetry) *App {
+	return &App{
+		config: config,
+		build:  build,
+		tel:    tel,
+	}
+}
+
+// Close releases the components built.
+func (a *App) Close() {
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		a.closers[i]()
+	}
+	a.closers = nil
+}
+
+// StartProbes registers the build info and the fgprof profiler on the probe server,
+// and starts pushing profiles if a ProfilingURL is set.
+// It must be called only once, as the probe server is process-wide.
+func (a *App) StartProbes() {
+	http.DefaultServeMux.Handle("/version", a.build)
+	expvar.Publish("build", expvar.Func(func() any { return a.build }))
+
+	if a.config.ProfilingURL != "" {
+		labels := map[string]string{"version": a.build.Version}
+		for k, v := range a.config.ProfilingLabels {
+			labels[k] = v
+		}
+		pusher := &profiling.Pusher{
+			URL:         a.config.ProfilingURL,
+			Application: "api",
+			Labels:      labels,
+			Interval:    a.config.ProfilingInterval,
+			Duration:    10 * time.Second,
+			Client:      &http.Client{Timeout: 30 * time.Second},
+			Log:         a.tel.Log,
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		a.closers = append(a.closers, cancel)
+		go pusher.Run(ctx)
+	}
+
+	// Register fgprof HTTP handler, a sampling Go profiler.
+	http.DefaultServeMux.Handle("/debug/fgprof", fgprof.Handler())
+}
+
+// DB connects to the database and its replicas.
+func (a *App) DB(ctx context.Context) (postgres.DB, error) {
+	if a.db != nil {
+		return *a.db, nil
+	}
+	pgxLogLevel, err := database.LogLevelFromEnv()
+	if err != nil {
+		return postgres.DB{}, fmt.Errorf("cannot get pgx logging level: %w", err)
+	}
+	poolOptions := []database.PoolOption{database.WithTypes(postgres.Types()...)}
+	var dbOptions []postgres.Option
+	if a.config.ReadOnly {
+		poolOptions = append(poolOptions, database.WithReadOnly())
+		dbOptions = append(dbOptions, postgres.WithReadOnly())
+	}
+	pgPool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+		Logger: a.tel.Log,
+	}, pgxLogLevel, a.tel.Tracer, poolOptions...)
+	if err != nil {
+		return postgres.DB{}, fmt.Errorf("cannot create pgx pool: %w", err)
+	}
+	a.closers = append(a.closers, pgPool.Close)
+	a.maxConns = pgPool.Config().MaxConns
+	expvar.Publish("pgxpool", database.PoolStats(pgPool))
+	a.sizePool(pgPool)
+
+	if a.config.SchemaCheck {
+		err := postgres.CheckSchemaVersion(ctx, pgPool)
+		var sve *postgres.SchemaVersionError
+		switch {
+		case errors.As(err, &sve) && a.config.ReadOnly:
+			// Reads are expected to work during a rollout, as migrations should be backward compatible.
+			a.tel.Log.Warn("starting in read-only mode with an incompatible database schema", slog.Any("error", err))
+		case err != nil:
+			return postgres.DB{}, err
+		}
+	}
+
+	if a.config.BatchPoolSize > 0 {
+		batchPool, err := database.NewPGXPool(ctx, "", &database.PGXStdLogger{
+			Logger: a.tel.Log,
+		}, pgxLogLevel, a.tel.Tracer, append(poolOptions, database.WithMaxConns(int32(a.config.BatchPoolSize)))...)
+		if err != nil {
+			return postgres.DB{}, fmt.Errorf("cannot create pgx pool for batch work: %w", err)
+		}
+		a.closers = append(a.closers, batchPool.Close)
+		expvar.Publish("pgxpool.batch", database.PoolStats(batchPool))
+		dbOptions = append(dbOptions, postgres.WithBatchPool(batchPool))
+	}
+
+	if len(a.config.Replicas) != 0 {
+		var replicaPools []*pgxpool.Pool
+		for _, connString := range a.config.Replicas {
+			pool, err := database.NewPGXPool(ctx, connString, &database.PGXStdLogger{
+				Logger: a.tel.Log,
+			}, pgxLogLevel, a.tel.Tracer, poolOptions...)
+			if err != nil {
+				return postgres.DB{}, fmt.Errorf("cannot create pgx pool for replica: %w", err)
+			}
+			a.closers = append(a.closers, pool.Close)
+			expvar.Publish(fmt.Sprintf("pgxpool.replica.%d", len(replicaPools)), database.PoolStats(pool))
+			a.sizePool(pool)
+			replicaPools = append(replicaPools, pool)
+		}
+		dbOptions = append(dbOptions, postgres.WithReplicas(postgres.Replicas{
+			Pools:      replicaPools,
+			HedgeAfter: a.config.HedgeAfter,
+			Meter:      a.tel.Meter.Meter("postgres"),
+		}))
+	}
+	if a.config.SearchView {
+		dbOptions = append(dbOptions, postgres.WithSearchView())
+	}
+	if a.config.SearchWindowTotal {
+		dbOptions = append(dbOptions, postgres.WithWindowTotal())
+	}
+	if len(a.config.Explain) != 0 {
+		// Plans are exposed on the probe server, which uses http.DefaultServeMux.
+		explainer := postgres.NewExplainer(a.config.Explain, 100)
+		http.DefaultServeMux.Handle("/debug/explain", explainer)
+		dbOptions = append(dbOptions, postgres.WithExplainer(explainer))
+	}
+	if a.config.LeakDetector > 0 {
+		// Like the plans, leaks are exposed on the probe server.
+		detector := postgres.NewLeakDetector(a.config.LeakDetector, a.tel.Log)
+		http.DefaultServeMux.Handle("/debug/leaks", detector)
+		dbOptions = append(dbOptions, postgres.WithLeakDetector(detector))
+	}
+	if a.config.ReviewerIDKeys != "" {
+		pseudonymizer, err := newPseudonymizer(a.config.ReviewerIDKeys)
+		if err != nil {
+			return postgres.DB{}, err
+		}
+		dbOptions = append(dbOptions, postgres.WithReviewerPseudonymizer(pseudonymizer))
+	}
+	if a.config.CostPriceKeys != "" {
+		codec, err := newCodec(a.config.CostPriceKeys)
+		if err != nil {
+			return postgres.DB{}, err
+		}
+		dbOptions = append(dbOptions, postgres.WithCostPriceCodec(codec))
+	}
+	if a.config.Quotas {
+		if q := a.config.DefaultQuota; q.MaxProducts < 0 || q.MaxReviewsPerProduct < 0 {
+			return postgres.DB{}, errors.New("quota limits cannot be negative")
+		}
+		dbOptions = append(dbOptions, postgres.WithQuotas(a.config.DefaultQuota))
+	}
+	dbOptions = append(dbOptions, postgres.WithTracing(a.tel.Tracer, a.tel.Propagator), postgres.WithMeter(a.tel.Meter.Meter("postgres")))
+	db := postgres.NewDB(pgPool, a.tel.Log, dbOptions...)
+	if err := outbox.RegisterMetrics(a.tel.Meter.Meter("outbox"), db); err != nil {
+		return postgres.DB{}, fmt.Errorf("cannot register outbox metrics: %w", err)
+	}
+	if err := outbox.RegisterBacklogMetrics(a.tel.Meter.Meter("outbox"), db); err != nil {
+		return postgres.DB{}, fmt.Errorf("cannot register outbox metrics: %w", err)
+	}
+	a.db = &db
+	return db, nil
+}
+
+// newPseudonymizer creates a postgres.Pseudonymizer from a comma-separated list of base64 encoded keys.
+func newPseudonymizer(keys string) (*postgres.Pseudonymizer, error) {
+	var kk [][]byte
+	for _, k := range strings.Split(keys, ",") {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(k))
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode reviewer ID pseudonymization key: %w", err)
+		}
+		kk = append(kk, key)
+	}
+	p, err := postgres.NewPseudonymizer(kk...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create reviewer ID pseudonymizer: %w", err)
+	}
+	return p, nil
+}
+
+// newCodec creates a postgres.Codec from a comma-separated list of id:key pairs, where the key is base64 encoded.
+// The first key is used for encryption.
+func newCodec(keys string) (*postgres.Codec, error) {
+	var (
+		current string
+		kk      = map[string][]byte{}
+	)
+	for _, pair := range strings.Split(keys, ",") {
+		id, k, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, errors.New("encryption keys must be in the id:key format")
+		}
+		key, err := base64.StdEncoding.DecodeString(k)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode encryption key %q: %w", id, err)
+		}
+		if current == "" {
+			current = id
+		}
+		kk[id] = key
+	}
+	sk, err := postgres.NewStaticKeys(current, kk)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.NewCodec(sk), nil
+}
+
+// Inventory service, with its middleware.
+func (a *App) Inventory(ctx context.Context) (inventory.API, error) {
+	if a.inventory != nil {
+		return a.inventory, nil
+	}
+	db, err := a.DB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := inventory.WithMetrics(a.tel.Meter.Meter("inventory"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create inventory metrics: %w", err)
+	}
+	// Database calls are measured apart from the service calls, which might not hit the database.
+	observedDB, err := inventory.WithDBMetrics(db, a.tel.Meter.Meter("inventory"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create inventory database metrics: %w", err)
+	}
+	inventoryService := inventory.NewService(observedDB)
+	inventoryService.SetReadOnly(a.config.ReadOnly)
+	inventoryService.SetLanguageDetector(langdetect.Detector{})
+	inventoryService.SetSentimentScorer(sentiment.Lexicon{})
+	inventoryService.SetReviewQuota(inventory.ReviewQuota{
+		Max:    a.config.ReviewQuota,
+		Period: a.config.ReviewQuotaPeriod,
+	})
+	if a.config.ContentReject != "" || a.config.ContentFlag != "" {
+		filter, err := contentfilter.New(a.config.ContentReject, a.config.ContentFlag)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create content filter: %w", err)
+		}
+		inventoryService.SetContentFilter(filter)
+	}
+	if a.config.TaxRates != "" {
+		taxes, err := inventory.ParseTaxTable(a.config.TaxRates)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse tax rates: %w", err)
+		}
+		inventoryService.SetTaxCalculator(taxes)
+	}
+	mw := []inventory.ServiceMiddleware{
+		inventory.WithLogging(a.tel.Log),
+		metrics,
+		inventory.WithCounters(expvar.NewMap("inventory")),
+		inventory.WithTracing(a.tel.Tracer.Tracer("inventory")),
+	}
+	if a.config.ListingCache > 0 {
+		mw = append(mw, inventory.WithListingCache(a.config.ListingCache))
+	}
+	if a.config.ProductCache > 0 {
+		mw = append(mw, inventory.WithProductCache(a.config.ProductCache))
+	}
+	if a.config.SearchCache > 0 {
+		searchCache, err := inventory.WithSearchCache(a.config.SearchCache, a.config.SearchCacheStale, a.tel.Meter.Meter("inventory"))
+		if err != nil {
+			return nil, fmt.Errorf("cannot create search cache: %w", err)
+		}
+		mw = append(mw, searchCache)
+	}
+	a.inventory = inventory.Chain(inventoryService, mw...)
+	return a.inventory, nil
+}
+
+// sizePool adapts the number of connections the pool keeps open to its utilization, if enabled.
+// The batch pool is left out, as it's already limited to the connections background work can use.
+func (a *App) sizePool(pool *pgxpool.Pool) {
+	if a.config.PoolSizeInterval > 0 {
+		a.sizers = append(a.sizers, database.NewPoolSizer(pool, a.config.PoolSizeInterval, a.config.PoolShrinkAfter, a.tel.Log))
+	}
+}
+
+// Workers run in the background, such as the outbox relay.
+// Workers needing to write to the database aren't built in read-only mode.
+func (a *App) Workers(ctx context.Context) ([]api.Runner, error) {
+	if a.workers != nil {
+		return a.workers, nil
+	}
+	db, err := a.DB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workers := []api.Runner{}
+	if a.config.SearchView {
+		workers = append(workers, newSearchViewRefresher(db, a.config.SearchViewRefresh))
+	}
+	if a.config.OutboxInterval > 0 && !a.config.ReadOnly {
+		var publisher outbox.Publisher = outbox.LogPublisher{Log: a.tel.Log}
+		if a.config.OutboxRate > 0 || a.config.OutboxConcurrency > 0 {
+			throttled := &outbox.ThrottledPublisher{
+				Publisher: publisher,
+				DefaultLimit: outbox.Limit{
+					Rate:        a.config.OutboxRate,
+					Concurrency: a.config.OutboxConcurrency,
+				},
+				// A throttled event waits for the next run of the relay anyway.
+				MaxWait:     a.config.OutboxInterval,
+				SlowPublish: a.config.OutboxSlowPublish,
+			}
+			if err := throttled.RegisterMetrics(a.tel.Meter.Meter("outbox")); err != nil {
+				return nil, fmt.Errorf("cannot register outbox delivery metrics: %w", err)
+			}
+			publisher = throttled
+		}
+		workers = append(workers, &outbox.Relay{
+			Store:       db,
+			Publisher:   publisher,
+			BatchSize:   a.config.OutboxBatchSize,
+			Interval:    a.config.OutboxInterval,
+			MaxAttempts: a.config.OutboxMaxAttempts,
+			Concurrency: a.config.OutboxConcurrency,
+			Log:         a.tel.Log,
+			Tracer:      a.tel.Tracer.Tracer("outbox"),
+			Propagator:  a.tel.Propagator,
+		})
+	}
+	if a.config.ReviewSummaryInterval > 0 && !a.config.ReadOnly {
+		summarizer := a.config.Summarizer
+		if summarizer == nil {
+			summarizer = summary.Scores{}
+		}
+		workers = append(workers, &summary.Job{
+			Store:      db,
+			Summarizer: summarizer,
+			BatchSize:  a.config.ReviewSummaryBatchSize,
+			Interval:   a.config.ReviewSummaryInterval,
+			Log:        a.tel.Log,
+		})
+	}
+	if a.config.RecentlyViewedPrune > 0 && !a.config.ReadOnly {
+		workers = append(workers, newRecentlyViewedPruner(db, a.config.RecentlyViewedTTL, a.config.RecentlyViewedPrune))
+	}
+	if a.config.CDCSlot != "" && !a.config.ReadOnly {
+		// The stream uses its own replication connection, configured with the same environment variables as the pool.
+		conf, err := pgconn.ParseConfig("")
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse change data capture connection config: %w", err)
+		}
+		workers = append(workers, &cdc.Stream{
+			Config:      conf,
+			Slot:        a.config.CDCSlot,
+			Publication: a.config.CDCPublication,
+			Handler:     cdc.PublisherHandler(outbox.LogPublisher{Log: a.tel.Log}),
+			Log:         a.tel.Log,
+		})
+	}
+	// Workers do their database work with batch priority, so they use the batch pool, if any.
+	for i, w := range workers {
+		workers[i] = batchWorker{w}
+	}
+	a.workers = workers
+	return workers, nil
+}
+
+// batchWorker runs a worker with database.PriorityBatch.
+type batchWorker struct {
+	api.Runner
+}
+
+// Run the worker with database.PriorityBatch.
+func (w batchWorker) Run(ctx context.Context) error {
+	return w.Runner.Run(database.WithPriority(ctx, database.PriorityBatch))
+}
+
+// Server of the API, running the workers along with its listeners, according to the Mode.
+// In ModeWorker, only the probe server and the workers are started.
+func (a *App) Server(ctx context.Context) (*api.Server, error) {
+	db, err := a.DB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &api.Server{
+		ProbeAddress: a.config.ProbeAddress,
+		BuildInfo:    a.build,
+		Log:          a.tel.Log,
+		Tracer:       a.tel.Tracer,
+		Meter:        a.tel.Meter,
+		Propagator:   a.tel.Propagator,
+	}
+	if a.config.Mode != ModeServe {
+		if s.Workers, err = a.Workers(ctx); err != nil {
+			return nil, err
+		}
+	}
+	// The pools are sized in every mode, as they're used by both the listeners and the workers.
+	s.Workers = append(s.Workers, a.sizers...)
+	if a.config.Mode == ModeWorker {
+		if len(s.Workers) == len(a.sizers) {
+			a.tel.Log.Warn("running in worker mode without any workers enabled")
+		}
+		return s, nil
+	}
+
+	if s.Inventory, err = a.Inventory(ctx); err != nil {
+		return nil, err
+	}
+	if a.config.ProductCache > 0 && a.config.ProductCacheWarmup > 0 {
+		a.warmProductCache(ctx, s.Inventory)
+	}
+
+	var tracker *slo.Tracker
+	if a.config.SLOAvailability != 0 {
+		if tracker, err = slo.NewTracker(slo.Objective{
+			Availability: a.config.SLOAvailability,
+			Latency:      a.config.SLOLatency,
+			MinCalls:     a.config.SLOMinCalls,
+		}, a.config.SLOWindow); err != nil {
+			return nil, err
+		}
+		if err := tracker.RegisterMetrics(a.tel.Meter.Meter("slo")); err != nil {
+			return nil, fmt.Errorf("cannot register SLO metrics: %w", err)
+		}
+	}
+
+	var limiter *loadshed.Limiter
+	if a.config.ConcurrencyPerConn > 0 {
+		// Requests are limited by the capacity of the primary pool, as writes can't be served by replicas.
+		limiter = loadshed.NewLimiter(int(math.Ceil(a.config.ConcurrencyPerConn * float64(a.maxConns))))
+		if err := limiter.RegisterMetrics(a.tel.Meter.Meter("loadshed")); err != nil {
+			return nil, fmt.Errorf("cannot register load shedding metrics: %w", err)
+		}
+		a.tel.Log.Info("load shedding enabled", slog.Int("limit", limiter.Limit()))
+	}
+
+	var faults *faultinject.Injector
+	if a.config.FaultInjection != "" || a.config.FaultInjectionHeaders {
+		if a.config.Environment == EnvironmentProduction {
+			return nil, errors.New("fault injection is disabled in production")
+		}
+		rules, err := faultinject.ParseRules(a.config.FaultInjection)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse fault injection rules: %w", err)
+		}
+		faults = &faultinject.Injector{
+			Rules:   rules,
+			Headers: a.config.FaultInjectionHeaders,
+		}
+		a.tel.Log.Warn("fault injection enabled", slog.Int("rules", len(rules)), slog.Bool("headers", faults.Headers))
+	}
+
+	if a.config.CaptureDir != "" {
+		if a.config.CaptureSampleRate < 0 || a.config.CaptureSampleRate > 1 {
+			return nil, errors.New("capture sample rate must be from 0 to 1")
+		}
+		s.Recorder = &replay.Recorder{
+			Dir:        a.config.CaptureDir,
+			SampleRate: a.config.CaptureSampleRate,
+			Log:        a.tel.Log,
+		}
+		s.Workers = append(s.Workers, s.Recorder)
+	}
+
+	if a.config.UsageInterval > 0 && !a.config.ReadOnly {
+		s.Usage = &usage.Meter{
+			Store:    db,
+			Interval: a.config.UsageInterval,
+			Log:      a.tel.Log,
+		}
+		s.Workers = append(s.Workers, s.Usage)
+	}
+	if a.config.Quotas {
+		s.Quotas = db
+	}
+
+	s.HTTPAddress = a.config.HTTPAddress
+	s.GRPCAddress = a.config.GRPCAddress
+	s.Address = a.config.Address
+	if s.TrustedProxies, err = api.ParseTrustedProxies(a.config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("cannot parse trusted proxies: %w", err)
+	}
+	s.HTTPEnvelope = a.config.HTTPEnvelope
+	if a.config.SearchCache > 0 {
+		s.SearchCacheMaxAge = a.config.SearchCache
+		s.SearchCacheStale = a.config.SearchCacheStale
+	}
+	s.AdminToken = a.config.AdminToken
+	s.DeadLetters = db
+	s.SLO = tracker
+	s.Limiter = limiter
+	s.Faults = faults
+	s.SLOReadiness = a.config.SLOReadiness
+	return s, nil
+}
+
+// Run the server, according to the Mode, until it fails or the context is canceled, such as by a SIGINT or SIGTERM.
+// After the context is canceled, the server is shut down gracefully: requests taking longer than
+// the ShutdownGracePeriod are forcibly closed, and workers drain their pending work until then.
+func (a *App) Run(ctx context.Context) error {
+	s, err := a.Server(ctx)
+	if err != nil {
+		return err
+	}
+	ec := make(chan error, 1)
+	go func() {
+		ec <- s.Run(context.Background())
+	}()
+	select {
+	case err = <-ec:
+	case <-ctx.Done():
+		haltCtx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownGracePeriod)
+		defer cancel()
+		s.Shutdown(haltCtx)
+		err = <-ec
+	}
+	if err != nil {
+		return fmt.Errorf("application terminated by error: %w", err)
+	}
+	return nil
+}
+
+// productCacheWarmupTimeout limits warming the product cache, so a slow database doesn't hold the startup for long.
+const productCacheWarmupTimeout = 30 * time.Second
+
+// warmProductCache reads the trending products into the product cache before the server starts,
+// so the first requests after a deploy don't all hit the database at once.
+// It's done with batch priority, and a failure only means the cache is filled by requests instead.
+func (a *App) warmProductCache(ctx context.Context, i inventory.API) {
+	ctx, cancel := context.WithTimeout(database.WithPriority(ctx, database.PriorityBatch), productCacheWarmupTimeout)
+	defer cancel()
+	start := time.Now()
+	n, err := inventory.WarmProductCache(ctx, i, a.config.ProductCacheWarmup)
+	if err != nil {
+		a.tel.Log.Warn("cannot warm product cache", slog.Int("products", n), slog.Any("error", err))
+		return
+	}
+	a.tel.Log.Info("product cache warmed", slog.Int("products", n), slog.Duration("duration", time.Since(start)))
+}
+
+// searchViewRefresher refreshes the product_search materialized view periodically.
+type searchViewRefresher struct {
+	db       postgres.DB
+	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newSearchViewRefresher(db postgres.DB, interval time.Duration) *searchViewRefresher {
+	return &searchViewRefresher{
+		db:       db,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Run refreshes the view until Shutdown is called.
+func (r *searchViewRefresher) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	r.db.RefreshProductSearchEvery(ctx, r.interval)
+	return nil
+}
+
+// Shutdown stops refreshing the view, canceling a refresh in progress, as there is nothing to drain.
+func (r *searchViewRefresher) Shutdown(ctx context.Context) {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// recentlyViewedPruner removes the products viewed longer than the TTL ago from the recently viewed lists periodically.
+type recentlyViewedPruner struct {
+	db       postgres.DB
+	ttl      time.Duration
+	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newRecentlyViewedPruner(db postgres.DB, ttl, interval time.Duration) *recentlyViewedPruner {
+	return &recentlyViewedPruner{
+		db:       db,
+		ttl:      ttl,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Run prunes the recently viewed lists until Shutdown is called.
+func (p *recentlyViewedPruner) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	p.db.PruneRecentlyViewedEvery(ctx, p.ttl, p.interval)
+	return nil
+}
+
+// Shutdown stops pruning, canceling a pruning in progress, as the next one catches up.
+func (p *recentlyViewedPruner) Shutdown(ctx context.Context) {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/summary"
+)
+
+// Mode of the application, selecting the subsystems it starts, so API servers and workers
+// can be scaled independently.
+type Mode string
+
+const (
+	// ModeAll serves the API and runs the workers.
+	ModeAll Mode = "all"
+
+	// ModeServe serves the API only.
+	ModeServe Mode = "serve"
+
+	// ModeWorker runs the workers only, such as the outbox relay.
+	ModeWorker Mode = "worker"
+)
+
+// ParseMode parses the name of a mode.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeAll, ModeServe, ModeWorker:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown mode %q: must be all, serve, or worker", s)
+}
+
+// EnvironmentProduction is the Environment of production deployments.
+const EnvironmentProduction = "production"
+
+// Config of the application.
+type Config struct {
+	// Mode of the application (default: ModeAll).
+	Mode Mode
+
+	// Addresses to listen for HTTP, gRPC, and probe (inspection) HTTP requests on.
+	HTTPAddress  string
+	GRPCAddress  string
+	ProbeAddress string
+
+	// Address to serve both gRPC and HTTP on, multiplexed as HTTP/2 cleartext (h2c).
+	// If set, HTTPAddress and GRPCAddress are ignored.
+	Address string
+
+	// TrustedProxies is a comma-separated list of IP addresses and CIDR prefixes of the proxies in front of the server,
+	// whose Forwarded and X-Forwarded-For headers are used to resolve the IP address of the client.
+	TrustedProxies string
+
+	// HTTPEnvelope wraps HTTP responses in an envelope, as in {"data": ..., "meta": ...} or {"error": ...}.
+	HTTPEnvelope bool
+
+	// AdminToken authorizes calls to the InventoryAdmin gRPC service, which is only registered if it's set.
+	AdminToken string
+
+	// Replicas are connection strings of read replicas.
+	Replicas []string
+
+	// HedgeAfter is the latency threshold for hedging read queries to a second replica (0 disables hedging).
+	HedgeAfter time.Duration
+
+	// BatchPoolSize is the maximum number of connections of a separate pool for background work,
+	// such as the workers, so it can't starve API requests (0 uses the same pool).
+	BatchPoolSize int
+
+	// PoolSizeInterval between checks of the utilization of the database pools, adapting the number of connections
+	// they keep open to it (0 disables it), halving it after the utilization stays low for PoolShrinkAfter.
+	PoolSizeInterval time.Duration
+	PoolShrinkAfter  time.Duration
+
+	// SchemaCheck refuses to start if the database schema version is incompatible, unless in read-only mode.
+	SchemaCheck bool
+
+	// ReadOnly rejects requests that modify data, such as when only read replicas are available.
+	ReadOnly bool
+
+	// SearchView searches products using the product_search materialized view, refreshed every SearchViewRefresh.
+	SearchView        bool
+	SearchViewRefresh time.Duration
+
+	// SearchWindowTotal counts the products found by a search along with the page of them, in a single statement.
+	SearchWindowTotal bool
+
+	// Explain lists the statements to capture EXPLAIN ANALYZE plans of.
+	Explain []string
+
+	// LeakDetector reports connections and transactions held longer than it, with where they were acquired (0 disables it).
+	LeakDetector time.Duration
+
+	// ReviewerIDKeys is a comma-separated list of base64 encoded keys to pseudonymize reviewer IDs with.
+	ReviewerIDKeys string
+
+	// CostPriceKeys is a comma-separated list of id:key pairs, with base64 encoded keys, to encrypt cost prices with.
+	CostPriceKeys string
+
+	// ListingCache is the duration to cache product listings for (0 disables caching).
+	ListingCache time.Duration
+
+	// ProductCache is the duration to cache products read by ID for (0 disables caching),
+	// and ProductCacheWarmup the number of trending products read into the cache on startup (0 disables warming).
+	ProductCache       time.Duration
+	ProductCacheWarmup int
+
+	// SearchCache is the duration to cache product searches for (0 disables caching), and SearchCacheStale
+	// how much longer to serve expired searches for while they're revalidated in the background.
+	SearchCache      time.Duration
+	SearchCacheStale time.Duration
+
+	// ContentReject and ContentFlag are regular expressions of review content to reject or flag for moderation.
+	ContentReject string
+	ContentFlag   string
+
+	// ReviewQuota is the maximum number of reviews a reviewer can create within ReviewQuotaPeriod (0 disables it).
+	ReviewQuota       int
+	ReviewQuotaPeriod time.Duration
+
+	// TaxRates of the tax classes, in the inventory.ParseTaxTable format.
+	TaxRates string
+
+	// SLOAvailability of gRPC methods, such as 0.999 (0 disables SLO tracking), with the other SLO settings.
+	SLOAvailability float64
+	SLOLatency      time.Duration
+	SLOWindow       time.Duration
+	SLOMinCalls     int64
+	SLOReadiness    bool
+
+	// ConcurrencyPerConn is the limit of in-flight requests of each endpoint, as a multiple of the maximum
+	// connections of the database pool, beyond which load is shed (0 disables load shedding).
+	ConcurrencyPerConn float64
+
+	// ProfilingURL of the continuous profiling backend to push profiles to, if set.
+	ProfilingURL      string
+	ProfilingLabels   map[string]string
+	ProfilingInterval time.Duration
+
+	// OutboxInterval between runs of the outbox relay (0 disables the relay), with the other relay settings.
+	OutboxInterval    time.Duration
+	OutboxBatchSize   int
+	OutboxMaxAttempts int
+
+	// OutboxRate limits the events published to each topic by the outbox relay (0 doesn't limit it), slowing down
+	// the topics whose events fail or take longer than OutboxSlowPublish to be published, and OutboxConcurrency
+	// publishes up to that many events of each topic at once, out of order (0 publishes them one at a time, in order).
+	OutboxRate        float64
+	OutboxConcurrency int
+	OutboxSlowPublish time.Duration
+
+	// ReviewSummaryInterval between runs of the job generating review summaries (0 disables the job),
+	// with the maximum number of summaries generated by each run.
+	ReviewSummaryInterval  time.Duration
+	ReviewSummaryBatchSize int
+
+	// Summarizer of the reviews of products, such as one backed by an external service (default: summary.Scores).
+	Summarizer summary.Summarizer
+
+	// RecentlyViewedTTL of the products on the recently viewed lists of users,
+	// pruned every RecentlyViewedPrune (0 disables pruning).
+	RecentlyViewedTTL   time.Duration
+	RecentlyViewedPrune time.Duration
+
+	// CDCSlot of the logical replication slot streaming changes of products and reviews to the log (empty disables it),
+	// and CDCPublication of the tables it streams.
+	CDCSlot        string
+	CDCPublication string
+
+	// Environment the application runs in, such as production or staging.
+	Environment string
+
+	// FaultInjection rules, in the faultinject.ParseRules format, and FaultInjectionHeaders enabling faults
+	// requested by the X-Fault-Injection header, for chaos testing. They're refused in the production Environment.
+	FaultInjection        string
+	FaultInjectionHeaders bool
+
+	// CaptureDir to record a sample of the HTTP requests and responses to, anonymized, to replay them against
+	// another environment (empty disables recording), and CaptureSampleRate of the requests recorded, from 0 to 1.
+	CaptureDir        string
+	CaptureSampleRate float64
+
+	// UsageInterval between the writes of the usage of the callers identified by the trusted proxies,
+	// by tenant and API key, also served by the HTTP API (0 disables usage accounting, as in ReadOnly mode).
+	UsageInterval time.Duration
+
+	// Quotas enforces the quotas of the products and reviews the tenants create, with the DefaultQuota
+	// unless the admin sets another one for them with the HTTP API. Zero limits are unlimited.
+	Quotas       bool
+	DefaultQuota inventory.Quota
+
+	// ShutdownGracePeriod to finish requests and drain workers after a shutdown signal.
+	ShutdownGracePeriod time.Duration
+}
@@ -0,0 +1,55 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/app"
+)
+
+func TestParseMode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		s       string
+		want    app.Mode
+		wantErr string
+	}{
+		{
+			name: "all",
+			s:    "all",
+			want: app.ModeAll,
+		},
+		{
+			name: "serve",
+			s:    "serve",
+			want: app.ModeServe,
+		},
+		{
+			name: "worker",
+			s:    "worker",
+			want: app.ModeWorker,
+		},
+		{
+			name:    "unknown",
+			s:       "cron",
+			wantErr: `unknown mode "cron": must be all, serve, or worker`,
+		},
+		{
+			name:    "empty",
+			s:       "",
+			wantErr: `unknown mode "": must be all, serve, or worker`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := app.ParseMode(tt.s)
+			if err == nil && tt.wantErr != "" || err != nil && err.Error() != tt.wantErr {
+				t.Errorf("ParseMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"log/slog"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Telemetry providers of the application.
+//
+// The OpenTelemetry SDK and exporters are left out of binaries built with the notelemetry build tag,
+// in which case traces and metrics are discarded.
+type Telemetry struct {
+	Log        *slog.Logger
+	Tracer     trace.TracerProvider
+	Meter      metric.MeterProvider
+	Propagator propagation.TextMapPropagator
+
+	halt func()
+}
+
+// Shutdown flushes the telemetry.
+func (t *Telemetry) Shutdown() {
+	t.halt()
+}
@@ -0,0 +1,27 @@
+//go:build notelemetry
+
+package app
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/henvic/pgxtutorial/internal/buildinfo"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// NewTelemetry returns no-op tracing and metrics providers, as the binary was built without telemetry.
+func NewTelemetry(log *slog.Logger, build buildinfo.Info) (*Telemetry, error) {
+	if _, ok := os.LookupEnv("OTEL_EXPORTER"); ok {
+		log.Warn("OTEL_EXPORTER is ignored, as the binary was built with the notelemetry build tag")
+	}
+	return &Telemetry{
+		Log:        log,
+		Tracer:     tracenoop.NewTracerProvider(),
+		Meter:      noop.NewMeterProvider(),
+		Propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+		halt:       func() {},
+	}, nil
+}
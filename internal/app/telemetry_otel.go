@@ -0,0 +1,121 @@
+//go:build !notelemetry
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/buildinfo"
+	"github.com/henvic/pgxtutorial/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// buildInfoTelemetry for OpenTelemetry.
+func buildInfoTelemetry(build buildinfo.Info) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName("api"),
+		semconv.ServiceVersion(build.Version),
+		attribute.Key("build.go").String(build.GoVersion),
+	}
+	if build.Revision != "" {
+		attrs = append(attrs,
+			attribute.Key("build.vcs.revision").String(build.Revision),
+			attribute.Key("build.vcs.time").String(build.Time),
+			attribute.Key("build.vcs.modified").Bool(build.Modified),
+		)
+	}
+	return attrs
+}
+
+// NewTelemetry initializes OpenTelemetry tracing and metrics providers.
+// Call Shutdown to flush them once the application is done.
+func NewTelemetry(log *slog.Logger, build buildinfo.Info) (*Telemetry, error) {
+	t := &Telemetry{
+		Log:        log,
+		Propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+		halt:       func() {},
+	}
+	var (
+		tr  sdktrace.SpanExporter
+		mt  sdkmetric.Exporter
+		err error
+	)
+
+	// OTEL_EXPORTER can be used to configure whether to use the OpenTelemetry gRPC exporter protocol, stdout, or noop.
+	switch exporter, ok := os.LookupEnv("OTEL_EXPORTER"); {
+	case exporter == "stdout":
+		// Tip: Use stdouttrace.WithPrettyPrint() to print spans in human readable format.
+		if tr, err = stdouttrace.New(); err != nil {
+			return nil, fmt.Errorf("stdouttrace: %w", err)
+		}
+		if mt, err = stdoutmetric.New(stdoutmetric.WithEncoder(json.NewEncoder(os.Stdout))); err != nil {
+			return nil, fmt.Errorf("stdoutmetric: %w", err)
+		}
+	case exporter == "otlp":
+		conf, err := telemetry.OTLPConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		if tr, mt, err = telemetry.NewOTLPExporters(context.Background(), conf); err != nil {
+			return nil, err
+		}
+	case ok:
+		log.Warn("unknown OTEL_EXPORTER value")
+		fallthrough
+	default:
+		t.Tracer = tracenoop.NewTracerProvider()
+		t.Meter = noop.NewMeterProvider()
+		return t, nil
+	}
+
+	res, err := telemetry.NewResource(context.Background(), buildInfoTelemetry(build)...)
+	switch {
+	case errors.Is(err, resource.ErrPartialResource):
+		log.Warn("cannot detect some telemetry resource attributes", slog.Any("error", err))
+	case err != nil:
+		return nil, fmt.Errorf("cannot initialize telemetry resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()), sdktrace.WithResource(res), sdktrace.WithBatcher(tr))
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithResource(res), sdkmetric.WithReader(sdkmetric.NewPeriodicReader(mt)))
+	t.Tracer = tp
+	t.Meter = mp
+
+	// The following function will be called when the graceful shutdown starts.
+	t.halt = func() {
+		haltCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		var w sync.WaitGroup
+		w.Add(2)
+		go func() {
+			defer w.Done()
+			if err := tp.Shutdown(haltCtx); err != nil {
+				log.Error("telemetry tracer shutdown", slog.Any("error", err))
+			}
+		}()
+		go func() {
+			defer w.Done()
+			if err := mp.Shutdown(haltCtx); err != nil {
+				log.Error("telemetry meter shutdown", slog.Any("error", err))
+			}
+		}()
+		w.Wait()
+	}
+	return t, nil
+}
@@ -0,0 +1,71 @@
+// Package buildinfo exposes metadata about how the running binary was built.
+package buildinfo
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// Info about the build of the binary.
+type Info struct {
+	// Version of the main module, or "(devel)" if it wasn't built from a module version.
+	Version string `json:"version"`
+
+	// Revision of the version control system the binary was built from.
+	Revision string `json:"revision,omitempty"`
+
+	// Time of the revision, in RFC 3339 format.
+	Time string `json:"time,omitempty"`
+
+	// Modified is true if the source tree had local modifications.
+	Modified bool `json:"modified,omitempty"`
+
+	// GoVersion used to build the binary.
+	GoVersion string `json:"go_version"`
+}
+
+// Read the build info embedded in the binary.
+func Read() Info {
+	info := Info{
+		Version:   "(devel)",
+		GoVersion: runtime.Version(),
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if bi.Main.Version != "" {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.Time = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// LogAttr returns the build info as a slog group, to add to every log record with slog.Logger.With.
+func (i Info) LogAttr() slog.Attr {
+	return slog.Group("build",
+		slog.String("version", i.Version),
+		slog.String("revision", i.Revision),
+		slog.String("go", i.GoVersion),
+	)
+}
+
+// ServeHTTP writes the build info as JSON.
+func (i Info) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	_ = enc.Encode(i)
+}
@@ -0,0 +1,41 @@
+package buildinfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+	info := Read()
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Read().GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+	if info.Version == "" {
+		t.Error("Read().Version is empty")
+	}
+}
+
+func TestInfoServeHTTP(t *testing.T) {
+	t.Parallel()
+	want := Info{
+		Version:   "v1.2.3",
+		Revision:  "abc",
+		GoVersion: "go1.22.4",
+	}
+	rec := httptest.NewRecorder()
+	want.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("unexpected Content-Type: %q", ct)
+	}
+	var got Info
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("cannot decode response: %v", err)
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
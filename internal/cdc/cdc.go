@@ -0,0 +1,381 @@
+// Package cdc streams the changes of tables, such as products and reviews, using PostgreSQL logical replication,
+// as an alternative to writing events to the outbox within the transactions making the changes,
+// or to recording them with triggers.
+//
+// It requires wal_level = logical, and a role with the REPLICATION attribute.
+// Changes are decoded with the built-in pgoutput plugin from a replication slot, which keeps the WAL
+// of the changes until they're confirmed, so a stream resumes where it stopped, even after a restart.
+// Changes are delivered at least once: a transaction is confirmed after all its changes are handled,
+// so the changes of a transaction interrupted before being confirmed are delivered again.
+//
+// A slot that isn't consumed anymore retains WAL indefinitely, filling the disk:
+// drop it with Stream.DropSlot when it's not needed anymore.
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/events"
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+// LSN (log sequence number) is a position in the WAL.
+type LSN = pglogrepl.LSN
+
+// ParseLSN parses an LSN in the PostgreSQL format, such as 16/B374D848.
+func ParseLSN(s string) (LSN, error) {
+	hi, lo, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	h, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	l, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	return LSN(h<<32 | l), nil
+}
+
+// Operation changing a row.
+type Operation string
+
+// Operations.
+const (
+	Insert Operation = "INSERT"
+	Update Operation = "UPDATE"
+	Delete Operation = "DELETE"
+)
+
+// Change of a row.
+type Change struct {
+	// LSN of the change.
+	LSN LSN
+
+	Operation Operation
+	Schema    string
+	Table     string
+
+	// New values of the columns of an inserted or updated row, as text.
+	// NULL values are nil, and unchanged TOASTed values of updates are left out.
+	New map[string]*string
+
+	// Old values of the columns of an updated or deleted row, as text.
+	// Only the replica identity columns are set, such as the primary key, unless the table has REPLICA IDENTITY FULL.
+	// Updates that don't change the replica identity don't have them.
+	Old map[string]*string
+
+	// CommitTime of the transaction of the change.
+	CommitTime time.Time
+}
+
+// Handler of changes.
+// An error stops the stream, and the changes of the transaction are delivered again when it's resumed.
+type Handler func(ctx context.Context, c Change) error
+
+// DefaultTables of the publication.
+var DefaultTables = []string{"product", "review"}
+
+// Stream of changes from a replication slot.
+type Stream struct {
+	// Config of the database connection, such as from pgconn.ParseConfig.
+	// The replication connection is made with a copy of it.
+	Config *pgconn.Config
+
+	// Slot to stream from, created if it doesn't exist.
+	// Slot names can only have lower case letters, numbers, and the underscore character.
+	Slot string
+
+	// Publication of the tables to stream, created with the Tables if it doesn't exist.
+	Publication string
+
+	// Tables of the publication, when it's created (default: DefaultTables).
+	Tables []string
+
+	// StartLSN to stream from. By default, the stream resumes from the last transaction confirmed to the slot.
+	StartLSN LSN
+
+	Handler Handler
+
+	// StatusInterval between the status updates confirming the transactions handled (default: 10s).
+	StatusInterval time.Duration
+
+	Log *slog.Logger
+
+	initOnce sync.Once
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func (s *Stream) init() {
+	s.initOnce.Do(func() {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+	})
+}
+
+var slotName = regexp.MustCompile(`^[a-z0-9_]{1,63}$`)
+
+// connect to the database with a replication connection.
+func (s *Stream) connect(ctx context.Context) (*pgconn.PgConn, error) {
+	if !slotName.MatchString(s.Slot) {
+		return nil, fmt.Errorf("invalid slot name %q", s.Slot)
+	}
+	config := s.Config.Copy()
+	config.RuntimeParams["replication"] = "database"
+	return pgconn.ConnectConfig(ctx, config)
+}
+
+// Run the stream until the context is canceled or Shutdown is called.
+func (s *Stream) Run(ctx context.Context) error {
+	s.init()
+	defer close(s.done)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot connect for replication: %w", err)
+	}
+	defer conn.Close(context.Background())
+	if err := s.setup(ctx, conn); err != nil {
+		return err
+	}
+	if err := pglogrepl.StartReplication(ctx, conn, s.Slot, s.StartLSN, pglogrepl.StartReplicationOptions{
+		Mode: pglogrepl.LogicalReplication,
+		PluginArgs: []string{
+			"proto_version '1'",
+			"publication_names " + quoteLiteral(pgx.Identifier{s.Publication}.Sanitize()),
+		},
+	}); err != nil {
+		return fmt.Errorf("cannot start replication: %w", err)
+	}
+	s.Log.Info("change data capture started", slog.String("slot", s.Slot), slog.String("publication", s.Publication))
+	err = s.stream(ctx, conn)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// setup the publication and the slot, if they don't exist.
+func (s *Stream) setup(ctx context.Context, conn *pgconn.PgConn) error {
+	exists, err := queryExists(ctx, conn, "SELECT 1 FROM pg_publication WHERE pubname = "+quoteLiteral(s.Publication))
+	if err != nil {
+		return fmt.Errorf("cannot check publication: %w", err)
+	}
+	if !exists {
+		tables := s.Tables
+		if len(tables) == 0 {
+			tables = DefaultTables
+		}
+		identifiers := make([]string, 0, len(tables))
+		for _, t := range tables {
+			identifiers = append(identifiers, pgx.Identifier{t}.Sanitize())
+		}
+		sql := fmt.Sprintf("CREATE PUBLICATION %s FOR TABLE %s", pgx.Identifier{s.Publication}.Sanitize(), strings.Join(identifiers, ", "))
+		if _, err := conn.Exec(ctx, sql).ReadAll(); err != nil {
+			return fmt.Errorf("cannot create publication: %w", err)
+		}
+		s.Log.Info("publication created", slog.String("publication", s.Publication), slog.Any("tables", tables))
+	}
+
+	exists, err = queryExists(ctx, conn, "SELECT 1 FROM pg_replication_slots WHERE slot_name = "+quoteLiteral(s.Slot))
+	if err != nil {
+		return fmt.Errorf("cannot check replication slot: %w", err)
+	}
+	if !exists {
+		if _, err := pglogrepl.CreateReplicationSlot(ctx, conn, s.Slot, "pgoutput", pglogrepl.CreateReplicationSlotOptions{
+			Mode: pglogrepl.LogicalReplication,
+		}); err != nil {
+			return fmt.Errorf("cannot create replication slot: %w", err)
+		}
+		s.Log.Info("replication slot created", slog.String("slot", s.Slot))
+	}
+	return nil
+}
+
+// DropSlot drops the replication slot, so the database doesn't retain WAL for it anymore.
+// It must not be called while the stream is running.
+func (s *Stream) DropSlot(ctx context.Context) error {
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot connect for replication: %w", err)
+	}
+	defer conn.Close(context.Background())
+	if err := pglogrepl.DropReplicationSlot(ctx, conn, s.Slot, pglogrepl.DropReplicationSlotOptions{}); err != nil {
+		return fmt.Errorf("cannot drop replication slot: %w", err)
+	}
+	return nil
+}
+
+// stream the changes to the handler, confirming the transactions handled with status updates.
+func (s *Stream) stream(ctx context.Context, conn *pgconn.PgConn) error {
+	interval := s.StatusInterval
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+	var (
+		dec       = newDecoder()
+		confirmed = s.StartLSN
+		next      = time.Now().Add(interval)
+	)
+	// Confirm the last transaction handled before stopping, so it isn't delivered again.
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := sendStatus(ctx, conn, confirmed); err != nil {
+			s.Log.Warn("cannot confirm replication position", slog.Any("error", err))
+		}
+	}()
+	for {
+		if time.Now().After(next) {
+			if err := sendStatus(ctx, conn, confirmed); err != nil {
+				return fmt.Errorf("cannot send replication status: %w", err)
+			}
+			next = time.Now().Add(interval)
+		}
+		receiveCtx, cancel := context.WithDeadline(ctx, next)
+		msg, err := conn.ReceiveMessage(receiveCtx)
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case pgconn.Timeout(err):
+			continue
+		case err != nil:
+			return fmt.Errorf("cannot receive replication message: %w", err)
+		}
+
+		var data []byte
+		switch msg := msg.(type) {
+		case *pgproto3.CopyData:
+			data = msg.Data
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(msg)
+		default:
+			return fmt.Errorf("unexpected replication message %T", msg)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		switch data[0] {
+		case pglogrepl.PrimaryKeepaliveMessageByteID:
+			pkm, err := pglogrepl.ParsePrimaryKeepaliveMessage(data[1:])
+			if err != nil {
+				return fmt.Errorf("malformed replication message: %w", err)
+			}
+			// Confirm the WAL end when idle, or the slot retains the WAL of other tables until a published one changes.
+			if lsn := dec.keepalive(pkm.ServerWALEnd, confirmed); lsn != confirmed {
+				confirmed = lsn
+				next = time.Time{}
+			}
+			if pkm.ReplyRequested {
+				next = time.Time{}
+			}
+		case pglogrepl.XLogDataByteID:
+			xld, err := pglogrepl.ParseXLogData(data[1:])
+			if err != nil {
+				return fmt.Errorf("malformed replication message: %w", err)
+			}
+			change, commit, err := dec.decode(xld.WALData)
+			if err != nil {
+				return err
+			}
+			if change != nil {
+				change.LSN = xld.WALStart
+				if err := s.Handler(ctx, *change); err != nil {
+					return fmt.Errorf("cannot handle change of %s.%s at %v: %w", change.Schema, change.Table, xld.WALStart, err)
+				}
+			}
+			if commit != 0 {
+				confirmed = commit
+			}
+		}
+	}
+}
+
+// Shutdown stops the stream, waiting for it until the context is done.
+func (s *Stream) Shutdown(ctx context.Context) {
+	s.init()
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		s.Log.Error("change data capture shutdown timed out", slog.Any("error", ctx.Err()))
+	}
+}
+
+// sendStatus of the standby, confirming the transactions handled up to an LSN.
+func sendStatus(ctx context.Context, conn *pgconn.PgConn, lsn LSN) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.Conn().SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+		defer conn.Conn().SetWriteDeadline(time.Time{})
+	}
+	return pglogrepl.SendStandbyStatusUpdate(ctx, conn, pglogrepl.StandbyStatusUpdate{WALWritePosition: lsn})
+}
+
+// queryExists returns whether a query returns any rows.
+func queryExists(ctx context.Context, conn *pgconn.PgConn, sql string) (bool, error) {
+	results, err := conn.Exec(ctx, sql).ReadAll()
+	if err != nil {
+		return false, err
+	}
+	return len(results) != 0 && len(results[0].Rows) != 0, nil
+}
+
+// quoteLiteral quotes a string as an SQL literal, as parameters can't be used by replication connections.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// PublisherHandler publishes changes as events, such as to the Publisher of the outbox, with topics
+// such as "cdc.product.update", and the change as events.Change JSON payload.
+func PublisherHandler(p outbox.Publisher) Handler {
+	return func(ctx context.Context, c Change) error {
+		payload, err := json.Marshal(events.Change{
+			LSN:        c.LSN.String(),
+			Operation:  string(c.Operation),
+			Schema:     c.Schema,
+			Table:      c.Table,
+			New:        c.New,
+			Old:        c.Old,
+			CommitTime: c.CommitTime,
+		})
+		if err != nil {
+			return err
+		}
+		return p.Publish(ctx, outbox.Event{
+			ID:        int64(c.LSN), // #nosec G115
+			Topic:     "cdc." + c.Table + "." + strings.ToLower(string(c.Operation)),
+			Payload:   payload,
+			CreatedAt: c.CommitTime,
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package cdc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pglogrepl"
+)
+
+// decoder of the messages of the pgoutput plugin (protocol version 1) into changes.
+type decoder struct {
+	// relations of the changes, as described by the pgoutput plugin before its first change in a session.
+	relations map[uint32]*pglogrepl.RelationMessage
+
+	// commitTime of the transaction being decoded.
+	commitTime time.Time
+
+	// pending is true between the begin and commit messages of a transaction.
+	pending bool
+}
+
+func newDecoder() *decoder {
+	return &decoder{
+		relations: map[uint32]*pglogrepl.RelationMessage{},
+	}
+}
+
+// decode a pgoutput message, returning the change it has, if any,
+// or the end LSN of the transaction if it's a commit message.
+func (d *decoder) decode(msg []byte) (change *Change, commit LSN, err error) {
+	if len(msg) == 0 {
+		return nil, 0, errors.New("empty message")
+	}
+	m, err := pglogrepl.Parse(msg)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot parse message %q: %w", msg[0], err)
+	}
+	switch m := m.(type) {
+	case *pglogrepl.BeginMessage:
+		d.commitTime = m.CommitTime
+		d.pending = true
+	case *pglogrepl.CommitMessage:
+		d.pending = false
+		commit = m.TransactionEndLSN
+	case *pglogrepl.RelationMessage:
+		d.relations[m.RelationID] = m
+	case *pglogrepl.InsertMessage:
+		change, err = d.change(Insert, m.RelationID)
+		if err != nil {
+			return nil, 0, err
+		}
+		change.New = d.tuple(m.RelationID, m.Tuple)
+	case *pglogrepl.UpdateMessage:
+		change, err = d.change(Update, m.RelationID)
+		if err != nil {
+			return nil, 0, err
+		}
+		change.Old = d.tuple(m.RelationID, m.OldTuple)
+		change.New = d.tuple(m.RelationID, m.NewTuple)
+	case *pglogrepl.DeleteMessage:
+		change, err = d.change(Delete, m.RelationID)
+		if err != nil {
+			return nil, 0, err
+		}
+		change.Old = d.tuple(m.RelationID, m.OldTuple)
+	}
+	// Origin, type, truncate, and logical decoding messages aren't changes of rows.
+	return change, commit, nil
+}
+
+// keepalive returns the position to confirm when the server reports its WAL end.
+// Without a transaction pending, everything up to the WAL end has been sent, and what wasn't is
+// of tables outside of the publication, so confirming it lets the slot release the WAL it retains
+// while the published tables aren't changed.
+func (d *decoder) keepalive(walEnd, confirmed LSN) LSN {
+	if d.pending || walEnd <= confirmed {
+		return confirmed
+	}
+	return walEnd
+}
+
+// change of a relation, described by a previous relation message.
+func (d *decoder) change(op Operation, id uint32) (*Change, error) {
+	rel, ok := d.relations[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown relation %d", id)
+	}
+	return &Change{
+		Operation:  op,
+		Schema:     rel.Namespace,
+		Table:      rel.RelationName,
+		CommitTime: d.commitTime,
+	}, nil
+}
+
+// tuple with the values of the columns of a row as text. NULL values are nil,
+// and unchanged TOASTed values are left out, as they aren't sent.
+func (d *decoder) tuple(id uint32, t *pglogrepl.TupleData) map[string]*string {
+	if t == nil {
+		return nil
+	}
+	columns := d.relations[id].Columns
+	values := make(map[string]*string, len(t.Columns))
+	for i, c := range t.Columns {
+		var name string
+		if i < len(columns) {
+			name = columns[i].Name
+		}
+		switch c.DataType {
+		case pglogrepl.TupleDataTypeNull:
+			values[name] = nil
+		case pglogrepl.TupleDataTypeText, pglogrepl.TupleDataTypeBinary:
+			v := string(c.Data)
+			values[name] = &v
+		}
+	}
+	return values
+}
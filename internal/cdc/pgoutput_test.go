@@ -0,0 +1,188 @@
+package cdc
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// message builder of pgoutput messages.
+type message []byte
+
+func (m message) byte(b byte) message { return append(m, b) }
+
+func (m message) uint16(v uint16) message { return binary.BigEndian.AppendUint16(m, v) }
+
+func (m message) uint32(v uint32) message { return binary.BigEndian.AppendUint32(m, v) }
+
+func (m message) uint64(v uint64) message { return binary.BigEndian.AppendUint64(m, v) }
+
+func (m message) string(s string) message { return append(append(m, s...), 0) }
+
+func (m message) text(s string) message { return m.byte('t').uint32(uint32(len(s))).append(s) }
+
+func (m message) append(s string) message { return append(m, s...) }
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestDecoder(t *testing.T) {
+	t.Parallel()
+	commitTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	micros := uint64(commitTime.Sub(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).Microseconds())
+	relation := message{}.byte('R').uint32(16384).string("public").string("product").byte('d').uint16(3).
+		byte(1).string("id").uint32(25).uint32(0xFFFFFFFF).
+		byte(0).string("name").uint32(25).uint32(0xFFFFFFFF).
+		byte(0).string("description").uint32(25).uint32(0xFFFFFFFF)
+	tests := []struct {
+		name       string
+		msg        message
+		want       *Change
+		wantCommit LSN
+		wantErr    string
+	}{
+		{
+			name: "insert",
+			msg:  message{}.byte('I').uint32(16384).byte('N').uint16(3).text("p1").text("Product").byte('n'),
+			want: &Change{
+				Operation:  Insert,
+				Schema:     "public",
+				Table:      "product",
+				New:        map[string]*string{"id": ptr("p1"), "name": ptr("Product"), "description": nil},
+				CommitTime: commitTime,
+			},
+		},
+		{
+			name: "update",
+			msg:  message{}.byte('U').uint32(16384).byte('K').uint16(3).text("p0").byte('n').byte('n').byte('N').uint16(3).text("p1").text("Renamed").byte('u'),
+			want: &Change{
+				Operation:  Update,
+				Schema:     "public",
+				Table:      "product",
+				Old:        map[string]*string{"id": ptr("p0"), "name": nil, "description": nil},
+				New:        map[string]*string{"id": ptr("p1"), "name": ptr("Renamed")},
+				CommitTime: commitTime,
+			},
+		},
+		{
+			name: "update_without_old",
+			msg:  message{}.byte('U').uint32(16384).byte('N').uint16(1).text("p1"),
+			want: &Change{
+				Operation:  Update,
+				Schema:     "public",
+				Table:      "product",
+				New:        map[string]*string{"id": ptr("p1")},
+				CommitTime: commitTime,
+			},
+		},
+		{
+			name: "delete",
+			msg:  message{}.byte('D').uint32(16384).byte('K').uint16(1).text("p1"),
+			want: &Change{
+				Operation:  Delete,
+				Schema:     "public",
+				Table:      "product",
+				Old:        map[string]*string{"id": ptr("p1")},
+				CommitTime: commitTime,
+			},
+		},
+		{
+			name:       "commit",
+			msg:        message{}.byte('C').byte(0).uint64(0x16B374D800).uint64(0x16B374D848).uint64(micros),
+			wantCommit: 0x16B374D848,
+		},
+		{
+			name: "truncate",
+			msg:  message{}.byte('T').uint32(1).byte(0).uint32(16384),
+		},
+		{
+			name:    "unknown_relation",
+			msg:     message{}.byte('I').uint32(1).byte('N').uint16(1).byte('n'),
+			wantErr: "unknown relation 1",
+		},
+		{
+			name:    "malformed",
+			msg:     message{}.byte('I').uint32(16384),
+			wantErr: `cannot parse message 'I': InsertMessage must have 8 bytes, got 4 bytes`,
+		},
+		{
+			name:    "unknown",
+			msg:     message{}.byte('X'),
+			wantErr: `cannot parse message 'X': replication message not supported`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d := newDecoder()
+			for _, msg := range []message{
+				relation,
+				message{}.byte('B').uint64(0x16B374D848).uint64(micros).uint32(1000),
+			} {
+				if _, _, err := d.decode(msg); err != nil {
+					t.Fatalf("cannot decode setup message: %v", err)
+				}
+			}
+			got, commit, err := d.decode(tt.msg)
+			if err != nil || tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("decode() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("decode() = %v", cmp.Diff(tt.want, got))
+			}
+			if commit != tt.wantCommit {
+				t.Errorf("decode() commit = %v, want %v", commit, tt.wantCommit)
+			}
+		})
+	}
+}
+
+func TestDecoderKeepalive(t *testing.T) {
+	t.Parallel()
+	d := newDecoder()
+	if got := d.keepalive(0x200, 0x100); got != 0x200 {
+		t.Errorf("keepalive() when idle = %v, want %v", got, LSN(0x200))
+	}
+	if got := d.keepalive(0x100, 0x200); got != 0x200 {
+		t.Errorf("keepalive() behind the confirmed position = %v, want %v", got, LSN(0x200))
+	}
+	begin := message{}.byte('B').uint64(0x300).uint64(0).uint32(1000)
+	if _, _, err := d.decode(begin); err != nil {
+		t.Fatalf("cannot decode begin message: %v", err)
+	}
+	if got := d.keepalive(0x300, 0x200); got != 0x200 {
+		t.Errorf("keepalive() with a transaction pending = %v, want %v", got, LSN(0x200))
+	}
+	commit := message{}.byte('C').byte(0).uint64(0x2F0).uint64(0x300).uint64(0)
+	if _, _, err := d.decode(commit); err != nil {
+		t.Fatalf("cannot decode commit message: %v", err)
+	}
+	if got := d.keepalive(0x400, 0x300); got != 0x400 {
+		t.Errorf("keepalive() after commit = %v, want %v", got, LSN(0x400))
+	}
+}
+
+func TestLSN(t *testing.T) {
+	t.Parallel()
+	lsn, err := ParseLSN("16/B374D848")
+	if err != nil {
+		t.Fatalf("ParseLSN() error = %v", err)
+	}
+	if lsn != 0x16B374D848 {
+		t.Errorf("ParseLSN() = %d, want %d", lsn, LSN(0x16B374D848))
+	}
+	if s := lsn.String(); s != "16/B374D848" {
+		t.Errorf("LSN.String() = %q, want 16/B374D848", s)
+	}
+	for _, s := range []string{"", "16", "16/", "G/0", "1/100000000"} {
+		if _, err := ParseLSN(s); err == nil {
+			t.Errorf("ParseLSN(%q) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Package contentfilter filters the content of product reviews using regular expressions,
+// such as a list of forbidden words.
+//
+// It's a simple default for inventory.ContentFilter.
+// To use an external moderation service, implement inventory.ContentFilter instead.
+package contentfilter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// Filter rejects content matching Reject, and flags content matching Flag for moderation.
+// A nil regular expression matches nothing.
+type Filter struct {
+	Reject *regexp.Regexp
+	Flag   *regexp.Regexp
+}
+
+// New creates a filter from the reject and flag regular expressions, which are case-insensitive.
+// An empty expression matches nothing.
+func New(reject, flag string) (*Filter, error) {
+	var (
+		f   Filter
+		err error
+	)
+	if reject != "" {
+		if f.Reject, err = regexp.Compile("(?i)" + reject); err != nil {
+			return nil, fmt.Errorf("invalid reject expression: %w", err)
+		}
+	}
+	if flag != "" {
+		if f.Flag, err = regexp.Compile("(?i)" + flag); err != nil {
+			return nil, fmt.Errorf("invalid flag expression: %w", err)
+		}
+	}
+	return &f, nil
+}
+
+// FilterContent returns the verdict for the text.
+func (f *Filter) FilterContent(ctx context.Context, text string) (inventory.ContentVerdict, error) {
+	switch {
+	case f.Reject != nil && f.Reject.MatchString(text):
+		return inventory.ContentRejected, nil
+	case f.Flag != nil && f.Flag.MatchString(text):
+		return inventory.ContentFlagged, nil
+	default:
+		return inventory.ContentAllowed, nil
+	}
+}
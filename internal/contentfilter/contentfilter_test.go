@@ -0,0 +1,49 @@
+package contentfilter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestFilterContent(t *testing.T) {
+	t.Parallel()
+	f, err := New(`\b(scam|fraud)\b`, `https?://`)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	tests := []struct {
+		text string
+		want inventory.ContentVerdict
+	}{
+		{"Great chair, very comfortable.", inventory.ContentAllowed},
+		{"This store is a SCAM.", inventory.ContentRejected},
+		{"Fraudulent? No, it's fine.", inventory.ContentAllowed},
+		{"Cheaper at https://example.com", inventory.ContentFlagged},
+		{"A scam, buy at https://example.com instead", inventory.ContentRejected},
+	}
+	for _, tt := range tests {
+		got, err := f.FilterContent(context.Background(), tt.text)
+		if err != nil || got != tt.want {
+			t.Errorf("FilterContent(%q) = %v, %v, want %v", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	f, err := New("", "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got, err := f.FilterContent(context.Background(), "anything"); err != nil || got != inventory.ContentAllowed {
+		t.Errorf("FilterContent() = %v, %v, want %v", got, err, inventory.ContentAllowed)
+	}
+	if _, err := New("(", ""); err == nil || err.Error() != "invalid reject expression: error parsing regexp: missing closing ): `(?i)(`" {
+		t.Errorf("New() error = %v", err)
+	}
+	if _, err := New("", "["); err == nil || err.Error() != "invalid flag expression: error parsing regexp: missing closing ]: `[`" {
+		t.Errorf("New() error = %v", err)
+	}
+}
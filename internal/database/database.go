@@ -24,7 +24,7 @@ import (
 // you might use PG environment variables such as the following to configure the database:
 // PGDATABASE, PGHOST, PGPORT, PGUSER, PGPASSWORD, PGCONNECT_TIMEOUT, etc.
 // Reference: https://www.postgresql.org/docs/current/libpq-envars.html
-func NewPGXPool(ctx context.Context, connString string, logger tracelog.Logger, logLevel tracelog.LogLevel, tracer trace.TracerProvider) (*pgxpool.Pool, error) {
+func NewPGXPool(ctx context.Context, connString string, logger tracelog.Logger, logLevel tracelog.LogLevel, tracer trace.TracerProvider, opts ...PoolOption) (*pgxpool.Pool, error) {
 	conf, err := pgxpool.ParseConfig(connString) // Using environment variables instead of a connection string.
 	if err != nil {
 		return nil, err
@@ -45,6 +45,9 @@ func NewPGXPool(ctx context.Context, connString string, logger tracelog.Logger,
 	// This number is very conservative, and you might be able to improve performance for highly concurrent applications
 	// by increasing it.
 	// conf.MaxConns = runtime.NumCPU() * 5
+	for _, o := range opts {
+		o(conf)
+	}
 	pool, err := pgxpool.NewWithConfig(ctx, conf)
 	if err != nil {
 		return nil, fmt.Errorf("pgx connection error: %w", err)
@@ -180,3 +180,39 @@ Routine: a`,
 		})
 	}
 }
+
+func TestRegisterTypes(t *testing.T) {
+	t.Parallel()
+	pool, err := NewPGXPool(context.Background(), "", &PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelInfo, nil)
+	if err != nil {
+		t.Fatalf("NewPGXPool() error: %v", err)
+	}
+	defer pool.Close()
+
+	conn, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("pool.Acquire() error: %v", err)
+	}
+	defer conn.Release()
+
+	// Use a temporary type so the test doesn't depend on the application schema.
+	if _, err := conn.Exec(context.Background(), `CREATE TYPE pg_temp.mood AS ENUM ('sad', 'ok', 'happy')`); err != nil {
+		t.Fatalf("cannot create type: %v", err)
+	}
+	if err := RegisterTypes(context.Background(), conn.Conn(), "mood"); err != nil {
+		t.Fatalf("RegisterTypes() error: %v", err)
+	}
+	var got []string
+	if err := conn.QueryRow(context.Background(), `SELECT '{happy,sad}'::mood[]`).Scan(&got); err != nil {
+		t.Fatalf("cannot scan enum array: %v", err)
+	}
+	if len(got) != 2 || got[0] != "happy" || got[1] != "sad" {
+		t.Errorf("unexpected enum array: %v", got)
+	}
+
+	if err := RegisterTypes(context.Background(), conn.Conn(), "unknown_type"); err == nil {
+		t.Error("RegisterTypes() should fail for unknown type")
+	}
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"expvar"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolStats returns an expvar.Var exposing the statistics of the pool, to publish with expvar.Publish.
+func PoolStats(pool *pgxpool.Pool) expvar.Var {
+	return expvar.Func(func() any {
+		s := pool.Stat()
+		return map[string]any{
+			"acquired_conns":             s.AcquiredConns(),
+			"idle_conns":                 s.IdleConns(),
+			"constructing_conns":         s.ConstructingConns(),
+			"total_conns":                s.TotalConns(),
+			"max_conns":                  s.MaxConns(),
+			"acquire_count":              s.AcquireCount(),
+			"acquire_duration_ms":        s.AcquireDuration().Milliseconds(),
+			"empty_acquire_count":        s.EmptyAcquireCount(),
+			"canceled_acquire_count":     s.CanceledAcquireCount(),
+			"new_conns_count":            s.NewConnsCount(),
+			"max_lifetime_destroy_count": s.MaxLifetimeDestroyCount(),
+			"max_idle_destroy_count":     s.MaxIdleDestroyCount(),
+		}
+	})
+}
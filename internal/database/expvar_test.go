@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/jackc/pgx/v5/tracelog"
+)
+
+func TestPoolStats(t *testing.T) {
+	t.Parallel()
+
+	pool, err := NewPGXPool(context.Background(), "", &PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelInfo, nil)
+	if err != nil {
+		t.Fatalf("NewPGXPool() error: %v", err)
+	}
+	defer pool.Close()
+	if _, err = pool.Exec(context.Background(), `SELECT 1`); err != nil {
+		t.Errorf("pool.Exec() error: %v", err)
+	}
+
+	var stats map[string]int64
+	if err := json.Unmarshal([]byte(PoolStats(pool).String()), &stats); err != nil {
+		t.Fatalf("cannot decode pool stats: %v", err)
+	}
+	if stats["acquire_count"] != 1 || stats["total_conns"] != 1 {
+		t.Errorf("unexpected pool stats: %v", stats)
+	}
+}
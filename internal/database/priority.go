@@ -0,0 +1,40 @@
+package database
+
+import "context"
+
+// Priority of the database work done with a context.
+type Priority int
+
+const (
+	// PriorityInteractive is for work a client is waiting for, such as API requests. It's the default.
+	PriorityInteractive Priority = iota
+
+	// PriorityBatch is for background work, such as workers, imports, and exports,
+	// which might be done with fewer connections, so it can't starve interactive work.
+	PriorityBatch
+)
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBatch:
+		return "batch"
+	}
+	return "unknown"
+}
+
+// priorityKey is the context key of the priority.
+type priorityKey struct{}
+
+// WithPriority returns a copy of the parent context carrying the priority of the database work done with it.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority carried by the context, or PriorityInteractive if it has none.
+func PriorityFromContext(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return p
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Utilization of the target number of connections of a PoolSizer from which it grows or shrinks the target.
+const (
+	highPoolUtilization = 0.75
+	lowPoolUtilization  = 0.25
+)
+
+// PoolSizer adapts the number of connections a pool keeps open to its utilization,
+// so an idle service doesn't hold connections of a shared PostgreSQL instance.
+//
+// pgxpool doesn't allow changing the MinConns and MaxConns of a pool after creating it,
+// and recreating the pool would drop the statement caches of its connections.
+// Instead, PoolSizer keeps a target number of connections, between the MinConns and MaxConns of the pool:
+// when the utilization of the target stays low for a while, the target is halved, and idle connections above it
+// are closed. When the pool is saturated, the target is doubled, and connections are opened up to it,
+// so the following requests don't wait for them to be established.
+// The pool still opens connections up to MaxConns on demand, and keeps at least MinConns open,
+// so MinConns should be set low for the pool to shrink.
+type PoolSizer struct {
+	pool        *pgxpool.Pool
+	interval    time.Duration
+	shrinkAfter time.Duration
+	log         *slog.Logger
+
+	target        int32
+	lowSince      time.Time
+	emptyAcquires int64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPoolSizer of the pool, checking its utilization every interval,
+// and halving its target number of connections after the utilization stays low for shrinkAfter.
+func NewPoolSizer(pool *pgxpool.Pool, interval, shrinkAfter time.Duration, log *slog.Logger) *PoolSizer {
+	stat := pool.Stat()
+	return &PoolSizer{
+		pool:          pool,
+		interval:      interval,
+		shrinkAfter:   shrinkAfter,
+		log:           log,
+		target:        clampConns(stat.TotalConns(), pool.Config()),
+		emptyAcquires: stat.EmptyAcquireCount(),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Run checks the utilization of the pool until Shutdown is called.
+func (s *PoolSizer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.stop:
+			return nil
+		case now := <-ticker.C:
+			s.check(ctx, now)
+		}
+	}
+}
+
+// Shutdown stops checking the utilization of the pool, leaving its connections as they are.
+func (s *PoolSizer) Shutdown(ctx context.Context) {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// check the utilization of the pool, resizing it if needed.
+func (s *PoolSizer) check(ctx context.Context, now time.Time) {
+	stat := s.pool.Stat()
+	// Acquires finding no idle connection wait for one to be established or released.
+	waited := stat.EmptyAcquireCount() > s.emptyAcquires
+	s.emptyAcquires = stat.EmptyAcquireCount()
+	target := s.resize(now, stat.AcquiredConns(), stat.TotalConns(), waited)
+	if target == s.target {
+		return
+	}
+	s.log.Info("resizing database pool",
+		slog.Int("from", int(s.target)),
+		slog.Int("to", int(target)),
+		slog.Int("acquired_conns", int(stat.AcquiredConns())),
+		slog.Int("total_conns", int(stat.TotalConns())),
+	)
+	s.target = target
+	switch total := stat.TotalConns(); {
+	case total > target:
+		s.closeIdle(ctx, total-target)
+	case total < target:
+		ctx, cancel := context.WithTimeout(ctx, s.interval)
+		defer cancel()
+		s.open(ctx, target-total)
+	}
+}
+
+// resize returns the new target number of connections, given the connections acquired and open,
+// and whether acquiring connections had to wait since the last check.
+func (s *PoolSizer) resize(now time.Time, acquired, total int32, waited bool) int32 {
+	conf := s.pool.Config()
+	utilization := float64(acquired) / float64(max(s.target, 1))
+	switch {
+	case utilization >= highPoolUtilization || (waited && total >= s.target):
+		s.lowSince = time.Time{}
+		return clampConns(max(s.target*2, s.target+1), conf)
+	case utilization >= lowPoolUtilization:
+		s.lowSince = time.Time{}
+	case s.lowSince.IsZero():
+		s.lowSince = now
+	case now.Sub(s.lowSince) >= s.shrinkAfter:
+		// Shrink gradually, waiting for the utilization of the new target to stay low too.
+		s.lowSince = now
+		return clampConns(s.target/2, conf)
+	}
+	return s.target
+}
+
+// clampConns to the MinConns and MaxConns of the pool, keeping at least one connection.
+func clampConns(n int32, conf *pgxpool.Config) int32 {
+	return min(max(n, conf.MinConns, 1), conf.MaxConns)
+}
+
+// closeIdle closes up to n idle connections.
+func (s *PoolSizer) closeIdle(ctx context.Context, n int32) {
+	for _, c := range s.pool.AcquireAllIdle(ctx) {
+		if n <= 0 {
+			c.Release()
+			continue
+		}
+		n--
+		// Hijacking removes the connection from the pool, so it isn't replaced.
+		if err := c.Hijack().Close(ctx); err != nil {
+			s.log.Debug("cannot close idle database connection", slog.Any("error", err))
+		}
+	}
+}
+
+// open n connections, acquiring them all at once, so each is a new connection.
+func (s *PoolSizer) open(ctx context.Context, n int32) {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		conns []*pgxpool.Conn
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := s.pool.Acquire(ctx)
+			if err != nil {
+				s.log.Debug("cannot open database connection", slog.Any("error", err))
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			conns = append(conns, c)
+		}()
+	}
+	wg.Wait()
+	for _, c := range conns {
+		c.Release()
+	}
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/tracelog"
+)
+
+func TestPoolSizer(t *testing.T) {
+	t.Parallel()
+	pool, err := NewPGXPool(context.Background(), "", &PGXStdLogger{
+		Logger: slog.Default(),
+	}, tracelog.LogLevelInfo, nil, WithMaxConns(8))
+	if err != nil {
+		t.Fatalf("NewPGXPool() error: %v", err)
+	}
+	defer pool.Close()
+
+	s := NewPoolSizer(pool, time.Second, time.Minute, slog.Default())
+	if s.target != 1 {
+		t.Fatalf("initial target = %d, want 1", s.target)
+	}
+	now := time.Now()
+	steps := []struct {
+		name     string
+		after    time.Duration
+		acquired int32
+		waited   bool
+		want     int32
+	}{
+		{"saturated", 0, 1, false, 2},
+		{"waited", time.Second, 1, true, 4},
+		{"busy", 2 * time.Second, 3, false, 8},
+		{"max", 3 * time.Second, 8, true, 8},
+		{"low", 4 * time.Second, 1, false, 8},
+		{"still_low", time.Minute, 1, false, 8},
+		{"sustained_low", time.Minute + 4*time.Second, 1, false, 4},
+		{"low_again", 2 * time.Minute, 0, false, 4},
+		{"sustained_low_again", 2*time.Minute + 4*time.Second, 0, false, 2},
+		{"traffic", 2*time.Minute + 5*time.Second, 1, false, 2},
+		{"not_sustained", 3*time.Minute + 5*time.Second, 0, false, 2},
+	}
+	for _, step := range steps {
+		s.target = s.resize(now.Add(step.after), step.acquired, s.target, step.waited)
+		if s.target != step.want {
+			t.Errorf("%s: target = %d, want %d", step.name, s.target, step.want)
+		}
+	}
+
+	s.open(context.Background(), 3)
+	if got := pool.Stat().TotalConns(); got != 3 {
+		t.Errorf("open(3) left %d connections, want 3", got)
+	}
+	s.closeIdle(context.Background(), 2)
+	if got := pool.Stat().TotalConns(); got != 1 {
+		t.Errorf("closeIdle(2) left %d connections, want 1", got)
+	}
+}
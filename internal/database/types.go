@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolOption configures the pool created by NewPGXPool.
+type PoolOption func(*pgxpool.Config)
+
+// WithReadOnly makes every transaction on the pool's connections read-only by default,
+// so PostgreSQL rejects writes, for example, when only read replicas are available.
+func WithReadOnly() PoolOption {
+	return func(conf *pgxpool.Config) {
+		conf.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
+}
+
+// WithMaxConns sets the maximum number of connections of the pool.
+func WithMaxConns(n int32) PoolOption {
+	return func(conf *pgxpool.Config) {
+		conf.MaxConns = n
+	}
+}
+
+// WithTypes registers the given custom PostgreSQL data types on every new connection.
+// See RegisterTypes.
+func WithTypes(names ...string) PoolOption {
+	return func(conf *pgxpool.Config) {
+		conf.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			return RegisterTypes(ctx, conn, names...)
+		}
+	}
+}
+
+// RegisterTypes loads custom PostgreSQL data types, such as enums, composite types, and domains,
+// and registers them on the connection type map along with their array types.
+//
+// pgx v5 doesn't know about types created with CREATE TYPE, so it can only exchange them in text format
+// and cannot scan arrays of them into Go slices.
+// After registration, it can encode and decode them with the appropriate codec (pgtype.EnumCodec, pgtype.CompositeCodec, ...).
+//
+// Types must be registered in dependency order, such as a composite type only after the types of its fields.
+// It's meant to be called from pgxpool.Config.AfterConnect, as the type map is per connection.
+func RegisterTypes(ctx context.Context, conn *pgx.Conn, names ...string) error {
+	for _, name := range names {
+		for _, n := range []string{name, "_" + name} { // Register the array type too.
+			t, err := conn.LoadType(ctx, n)
+			if err != nil {
+				return fmt.Errorf("cannot load type %q: %w", n, err)
+			}
+			conn.TypeMap().RegisterType(t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Package datagen generates realistic synthetic products and reviews for load testing,
+// so benchmarks don't need a copy of production data and the personal data in it.
+//
+// The data of each product and its reviews depends only on the seed and the position of the product,
+// so the same seed generates the same data across runs, making benchmarks reproducible.
+package datagen
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5"
+)
+
+// Generator of products and reviews.
+type Generator struct {
+	// Seed of the data.
+	Seed uint64
+
+	// ReviewsPerProduct on average.
+	// The number of reviews of products follows an exponential distribution, so most have few, and some have many.
+	ReviewsPerProduct float64
+
+	// Now is the time the products and reviews are created before (default: 2024-01-01 UTC),
+	// which is fixed so the data is reproducible.
+	Now time.Time
+}
+
+func (g *Generator) now() time.Time {
+	if g.Now.IsZero() {
+		return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return g.Now
+}
+
+// Product at position i, with its reviews.
+func (g *Generator) Product(i int) (inventory.Product, []inventory.ProductReview) {
+	r := rand.New(rand.NewPCG(g.Seed, uint64(i))) // #nosec G115 G404
+	name := fmt.Sprintf("%s %s %s", pick(r, adjectives), pick(r, materials), pick(r, nouns))
+	created := g.now().Add(-time.Duration(r.Int64N(int64(2 * 365 * 24 * time.Hour))))
+	p := inventory.Product{
+		ID:          fmt.Sprintf("datagen-%d", i),
+		Name:        name,
+		Description: sentence(r, 8+r.IntN(24)),
+		Price:       price(r),
+		Status:      inventory.ProductStatusActive,
+		CreatedAt:   created,
+		ModifiedAt:  created,
+		// Suffixing the position keeps slugs unique without querying the database, as product_free_slug does.
+		Slug: fmt.Sprintf("%s-%d", strings.ReplaceAll(strings.ToLower(name), " ", "-"), i),
+	}
+
+	n := int(r.ExpFloat64() * g.ReviewsPerProduct)
+	reviews := make([]inventory.ProductReview, 0, n)
+	for j := range n {
+		s := score(r)
+		reviewed := p.CreatedAt.Add(time.Duration(r.Int64N(int64(g.now().Sub(p.CreatedAt)) + 1)))
+		reviews = append(reviews, inventory.ProductReview{
+			ID:          fmt.Sprintf("%s-%d", p.ID, j),
+			ProductID:   p.ID,
+			ReviewerID:  fmt.Sprintf("datagen-reviewer-%d", r.IntN(1_000_000)),
+			Score:       s,
+			Title:       pick(r, titles[s]),
+			Description: sentence(r, r.IntN(60)),
+			Language:    "en",
+			CreatedAt:   reviewed,
+			ModifiedAt:  reviewed,
+		})
+	}
+	return p, reviews
+}
+
+// Stats of the rows loaded.
+type Stats struct {
+	Products int64
+	Reviews  int64
+}
+
+// Load products from position 0 up to n into the database, and their reviews,
+// calling progress, if set, after every 10000 rows copied to a table.
+func (g *Generator) Load(ctx context.Context, db database.PGX, n int, progress func(table string, rows int64)) (Stats, error) {
+	var stats Stats
+	var i int
+	copied, err := db.CopyFrom(ctx, pgx.Identifier{"product"},
+		[]string{"id", "name", "description", "price", "slug", "created_at", "modified_at"},
+		pgx.CopyFromFunc(func() ([]any, error) {
+			if i == n {
+				return nil, nil
+			}
+			p, _ := g.Product(i)
+			i++
+			report(progress, "product", i)
+			return []any{p.ID, p.Name, p.Description, p.Price, p.Slug, p.CreatedAt, p.ModifiedAt}, nil
+		}))
+	stats.Products = copied
+	if err != nil {
+		return stats, fmt.Errorf("cannot load products: %w", err)
+	}
+
+	i = 0
+	var reviews []inventory.ProductReview
+	var loaded int
+	stats.Reviews, err = db.CopyFrom(ctx, pgx.Identifier{"review"},
+		[]string{"id", "product_id", "reviewer_id", "title", "description", "score", "language", "created_at", "modified_at"},
+		pgx.CopyFromFunc(func() ([]any, error) {
+			for len(reviews) == 0 {
+				if i == n {
+					return nil, nil
+				}
+				_, reviews = g.Product(i)
+				i++
+			}
+			v := reviews[0]
+			reviews = reviews[1:]
+			loaded++
+			report(progress, "review", loaded)
+			return []any{v.ID, v.ProductID, v.ReviewerID, v.Title, v.Description, v.Score, v.Language, v.CreatedAt, v.ModifiedAt}, nil
+		}))
+	if err != nil {
+		return stats, fmt.Errorf("cannot load reviews: %w", err)
+	}
+	return stats, nil
+}
+
+func report(progress func(table string, rows int64), table string, rows int) {
+	if progress != nil && rows%10000 == 0 {
+		progress(table, int64(rows))
+	}
+}
+
+// price in cents following a log-normal distribution with a median of about $25,
+// ending in 99 cents, as most retail prices do.
+func price(r *rand.Rand) int {
+	dollars := int(math.Exp(math.Log(25) + r.NormFloat64()))
+	return min(dollars, 99_999)*100 + 99
+}
+
+// scoreWeights of each score, in percent, skewed towards the extremes as the scores of online reviews usually are.
+var scoreWeights = [...]int{2, 8, 5, 10, 25, 50}
+
+func score(r *rand.Rand) int {
+	n := r.IntN(100)
+	for s, w := range scoreWeights {
+		if n < w {
+			return s
+		}
+		n -= w
+	}
+	return len(scoreWeights) - 1
+}
+
+// sentence of n random words, capitalized and ending with a period, or empty if n is 0.
+func sentence(r *rand.Rand, n int) string {
+	if n == 0 {
+		return ""
+	}
+	words := make([]string, n)
+	for i := range words {
+		words[i] = pick(r, vocabulary)
+	}
+	s := strings.Join(words, " ")
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+func pick(r *rand.Rand, s []string) string {
+	return s[r.IntN(len(s))]
+}
+
+var (
+	adjectives = []string{"Classic", "Compact", "Deluxe", "Ergonomic", "Handmade", "Lightweight", "Modern", "Portable", "Rustic", "Sleek", "Sturdy", "Vintage"}
+	materials  = []string{"Bamboo", "Ceramic", "Cotton", "Glass", "Granite", "Leather", "Linen", "Oak", "Rubber", "Steel", "Walnut", "Wool"}
+	nouns      = []string{"Backpack", "Blanket", "Bowl", "Chair", "Clock", "Desk", "Kettle", "Lamp", "Mug", "Shelf", "Table", "Vase"}
+	vocabulary = []string{"a", "and", "best", "color", "daily", "design", "easy", "feels", "for", "gift", "great", "home", "in",
+		"is", "it", "kitchen", "made", "nice", "office", "quality", "size", "the", "to", "use", "very", "well", "with", "works"}
+	titles = [len(scoreWeights)][]string{
+		{"Broken on arrival", "Do not buy", "Waste of money"},
+		{"Very disappointing", "Poor quality", "Not as described"},
+		{"Could be better", "Below expectations", "Meh"},
+		{"It's okay", "Decent for the price", "Does the job"},
+		{"Very good", "Happy with it", "Good value"},
+		{"Excellent", "Love it", "Perfect", "Highly recommended"},
+	}
+)
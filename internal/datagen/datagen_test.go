@@ -0,0 +1,65 @@
+package datagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeneratorDeterministic(t *testing.T) {
+	t.Parallel()
+	g := &Generator{Seed: 42, ReviewsPerProduct: 5}
+	p, reviews := g.Product(7)
+	again, againReviews := (&Generator{Seed: 42, ReviewsPerProduct: 5}).Product(7)
+	if !reflect.DeepEqual(p, again) || !reflect.DeepEqual(reviews, againReviews) {
+		t.Errorf("Product() isn't deterministic: got %+v and %+v", p, again)
+	}
+	if other, _ := (&Generator{Seed: 43, ReviewsPerProduct: 5}).Product(7); other.Name == p.Name && other.Price == p.Price && other.CreatedAt.Equal(p.CreatedAt) {
+		t.Errorf("Product() = %+v with a different seed, want different data", other)
+	}
+}
+
+func TestGeneratorDistribution(t *testing.T) {
+	t.Parallel()
+	const n = 2000
+	g := &Generator{Seed: 1, ReviewsPerProduct: 4}
+	slugs := map[string]bool{}
+	var scores [len(scoreWeights)]int
+	var reviews, cheap int
+	for i := range n {
+		p, rs := g.Product(i)
+		if p.ID == "" || p.Name == "" || p.Description == "" {
+			t.Fatalf("Product(%d) = %+v, want ID, name, and description", i, p)
+		}
+		if p.Price < 99 || p.Price%100 != 99 {
+			t.Errorf("Product(%d) price = %d, want a price ending in 99 cents", i, p.Price)
+		}
+		if p.Price < 2500 {
+			cheap++
+		}
+		if slugs[p.Slug] {
+			t.Errorf("Product(%d) slug %q isn't unique", i, p.Slug)
+		}
+		slugs[p.Slug] = true
+		if p.CreatedAt.After(g.now()) {
+			t.Errorf("Product(%d) created at %v, after %v", i, p.CreatedAt, g.now())
+		}
+		for _, r := range rs {
+			if r.ProductID != p.ID || r.Title == "" || r.CreatedAt.Before(p.CreatedAt) || r.CreatedAt.After(g.now()) {
+				t.Errorf("Product(%d) has invalid review %+v", i, r)
+			}
+			scores[r.Score]++
+		}
+		reviews += len(rs)
+	}
+
+	// Bounds are loose enough to not depend on the seed.
+	if avg := float64(reviews) / n; avg < 3 || avg > 5 {
+		t.Errorf("got %.2f reviews per product on average, want about 4", avg)
+	}
+	if cheap < n*4/10 || cheap > n*6/10 {
+		t.Errorf("got %d of %d products cheaper than $25, want about half", cheap, n)
+	}
+	if scores[5] < scores[4] || scores[4] < scores[3] || scores[0] > scores[1] {
+		t.Errorf("got scores %v, want them skewed towards 5", scores)
+	}
+}
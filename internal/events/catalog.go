@@ -0,0 +1,158 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Catalog of the events emitted, so integrators can discover them programmatically.
+type Catalog struct {
+	Channels []Channel `json:"channels"`
+	Types    []Type    `json:"types"`
+}
+
+// Channel the events are delivered through.
+type Channel struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Channels of the events.
+const (
+	// ChannelOutbox delivers events written to the outbox within the transactions making the changes,
+	// and published by its relay at least once, in order.
+	ChannelOutbox = "outbox"
+
+	// ChannelCDC delivers the changes of tables streamed by change data capture from the write-ahead log, at least once.
+	ChannelCDC = "cdc"
+)
+
+// Type of event emitted.
+type Type struct {
+	// Topic of the events, where a <placeholder> stands for any value, such as in cdc.<table>.<operation>.
+	Topic       string `json:"topic"`
+	Description string `json:"description"`
+	Channel     string `json:"channel"`
+
+	// Payload Go type, and its current Version, if versioned.
+	Payload string `json:"payload"`
+	Version int    `json:"version,omitempty"`
+
+	// Fields of the JSON payload, as described by the Go type.
+	Fields []Field `json:"fields"`
+
+	// Schema of the current version of the payload as a JSON Schema document, if published.
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+// Field of a JSON payload.
+type Field struct {
+	Name string `json:"name"`
+
+	// Type of the field in JSON: string, number, integer, boolean, array, or object.
+	Type string `json:"type"`
+
+	// Format of a string, such as date-time, if any.
+	Format string `json:"format,omitempty"`
+
+	// Optional fields are left out of the payload when empty.
+	Optional bool `json:"optional,omitempty"`
+}
+
+// NewCatalog returns the catalog of the events, generated from the Go types of their payloads.
+// The schemas of versioned payloads are read from Schemas.
+func NewCatalog() Catalog {
+	order := func(topic, description string) Type {
+		return newType(topic, description, ChannelOutbox, Order{}, OrderVersion, "order")
+	}
+	return Catalog{
+		Channels: []Channel{
+			{
+				Name:        ChannelOutbox,
+				Description: "Events written within the transactions making the changes, and published by the outbox relay at least once, in order.",
+			},
+			{
+				Name:        ChannelCDC,
+				Description: "Changes of tables streamed from the write-ahead log by change data capture, published at least once, if enabled.",
+			},
+		},
+		Types: []Type{
+			order("order.created", "An order was placed, reserving its stock, and is pending payment."),
+			order("order.paid", "An order was paid, deducting its reserved stock."),
+			order("order.canceled", "An order was canceled, such as when charging it failed, releasing its reserved stock."),
+			newType("cdc.<table>.<operation>", "A row of a table, such as product or review, was inserted, updated, or deleted.",
+				ChannelCDC, Change{}, 0, ""),
+		},
+	}
+}
+
+// newType describes the events of a topic with the Go type of their payload.
+// The schema of a versioned payload is read from schema/<name>.v<version>.json.
+func newType(topic, description, channel string, payload any, version int, name string) Type {
+	t := reflect.TypeOf(payload)
+	typ := Type{
+		Topic:       topic,
+		Description: description,
+		Channel:     channel,
+		Payload:     t.String(),
+		Version:     version,
+		Fields:      fields(t),
+	}
+	if version != 0 {
+		schema, err := Schemas.ReadFile(fmt.Sprintf("schema/%s.v%d.json", name, version))
+		if err != nil {
+			panic(fmt.Sprintf("missing schema of %s: %v", t, err))
+		}
+		typ.Schema = schema
+	}
+	return typ
+}
+
+// fields of the JSON encoding of a struct type.
+func fields(t reflect.Type) []Field {
+	var ff []Field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		f := Field{
+			Name:     name,
+			Optional: strings.Contains(","+opts+",", ",omitempty,"),
+		}
+		f.Type, f.Format = jsonType(sf.Type)
+		ff = append(ff, f)
+	}
+	return ff
+}
+
+// jsonType of a Go type, and the format of strings, such as date-time.
+func jsonType(t reflect.Type) (typ, format string) {
+	if t == reflect.TypeFor[time.Time]() {
+		return "string", "date-time"
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonType(t.Elem())
+	case reflect.String:
+		return "string", ""
+	case reflect.Bool:
+		return "boolean", ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", ""
+	case reflect.Float32, reflect.Float64:
+		return "number", ""
+	case reflect.Slice, reflect.Array:
+		return "array", ""
+	default:
+		return "object", ""
+	}
+}
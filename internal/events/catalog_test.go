@@ -0,0 +1,83 @@
+package events
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCatalog(t *testing.T) {
+	t.Parallel()
+	catalog := NewCatalog()
+	var topics []string
+	for _, typ := range catalog.Types {
+		topics = append(topics, typ.Topic)
+		if !slices.ContainsFunc(catalog.Channels, func(c Channel) bool { return c.Name == typ.Channel }) {
+			t.Errorf("%s is delivered through unknown channel %q", typ.Topic, typ.Channel)
+		}
+	}
+	if want := []string{"order.created", "order.paid", "order.canceled", "cdc.<table>.<operation>"}; !cmp.Equal(want, topics) {
+		t.Errorf("catalog topics don't match: %v", cmp.Diff(want, topics))
+	}
+
+	order := catalog.Types[0]
+	if order.Payload != "events.Order" || order.Version != OrderVersion {
+		t.Errorf("order.created payload = %s version %d, want events.Order version %d", order.Payload, order.Version, OrderVersion)
+	}
+	want := []Field{
+		{Name: "version", Type: "integer"},
+		{Name: "order_id", Type: "string"},
+		{Name: "payment_id", Type: "string", Optional: true},
+		{Name: "reason", Type: "string", Optional: true},
+	}
+	if !cmp.Equal(want, order.Fields) {
+		t.Errorf("order.created fields don't match: %v", cmp.Diff(want, order.Fields))
+	}
+
+	change := catalog.Types[3]
+	if change.Schema != nil || change.Version != 0 {
+		t.Errorf("cdc events should have no schema or version, got %s version %d", change.Schema, change.Version)
+	}
+	if i := slices.IndexFunc(change.Fields, func(f Field) bool { return f.Name == "commit_time" }); i == -1 ||
+		change.Fields[i] != (Field{Name: "commit_time", Type: "string", Format: "date-time"}) {
+		t.Errorf("cdc commit_time field not described as a date-time string: %+v", change.Fields)
+	}
+}
+
+// TestCatalogSchemas checks that the fields described by the Go types of the payloads match their published schemas.
+func TestCatalogSchemas(t *testing.T) {
+	t.Parallel()
+	for _, typ := range NewCatalog().Types {
+		if typ.Schema == nil {
+			continue
+		}
+		t.Run(typ.Topic, func(t *testing.T) {
+			t.Parallel()
+			var schema struct {
+				Properties map[string]struct {
+					Type string `json:"type"`
+				} `json:"properties"`
+				Required []string `json:"required"`
+			}
+			if err := json.Unmarshal(typ.Schema, &schema); err != nil {
+				t.Fatalf("cannot decode schema: %v", err)
+			}
+			for _, f := range typ.Fields {
+				p, ok := schema.Properties[f.Name]
+				switch {
+				case !ok:
+					t.Errorf("field %q not in the schema", f.Name)
+				case p.Type != "" && p.Type != f.Type:
+					t.Errorf("field %q type = %s, schema type = %s", f.Name, f.Type, p.Type)
+				case f.Optional && slices.Contains(schema.Required, f.Name):
+					t.Errorf("optional field %q is required by the schema", f.Name)
+				}
+			}
+			if len(schema.Properties) != len(typ.Fields) {
+				t.Errorf("schema has %d properties, payload has %d fields", len(schema.Properties), len(typ.Fields))
+			}
+		})
+	}
+}
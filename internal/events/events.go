@@ -0,0 +1,120 @@
+// Package events defines the versioned payloads of the events written to the outbox,
+// so the consumers of the topics, such as message brokers and webhooks, can rely on a stable format.
+//
+// Payloads are JSON objects with a "version" field of their schema, published in the schema directory
+// as JSON Schema documents, such as schema/order.v1.json.
+// Payloads written before versioning don't have the field, and are version 1.
+//
+// Compatible changes, such as adding optional fields, keep the version.
+// Other changes, such as removing, renaming, or changing the type of a field, increment it, and register an upgrade
+// from the previous version, so the payloads written with it, such as the ones still in the outbox
+// or in its dead letter queue, are decoded as the current version.
+// Consumers decode payloads of versions newer than they know of as ErrUnsupportedVersion, so they must be
+// upgraded before the producer.
+package events
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Schemas of the payloads as JSON Schema documents, by topic and version, such as schema/order.v1.json.
+//
+//go:embed schema/*.json
+var Schemas embed.FS
+
+// ErrUnsupportedVersion is returned when decoding a payload with a version newer than the current one.
+var ErrUnsupportedVersion = errors.New("unsupported event payload version")
+
+// OrderVersion is the current version of the Order payload.
+const OrderVersion = 1
+
+// Order payload of the order.created, order.paid, and order.canceled topics.
+type Order struct {
+	// Version of the payload, set to OrderVersion by NewOrder.
+	Version int `json:"version"`
+
+	OrderID string `json:"order_id"`
+
+	// PaymentID of the order, if paid.
+	PaymentID string `json:"payment_id,omitempty"`
+
+	// Reason an order was canceled, if it failed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewOrder payload of the current version.
+func NewOrder(orderID string) Order {
+	return Order{
+		Version: OrderVersion,
+		OrderID: orderID,
+	}
+}
+
+// orderUpgrades of the Order payload, where orderUpgrades[v-1] upgrades version v to v+1.
+var orderUpgrades []upgrade
+
+// DecodeOrder payload of any version up to OrderVersion, upgrading it to the current one.
+func DecodeOrder(payload []byte) (Order, error) {
+	var o Order
+	if err := decode(payload, OrderVersion, orderUpgrades, &o); err != nil {
+		return Order{}, fmt.Errorf("cannot decode order event: %w", err)
+	}
+	return o, nil
+}
+
+// Change payload of the cdc.<table>.<operation> topics, published by change data capture.
+// It mirrors the rows of the tables, so it isn't versioned.
+type Change struct {
+	// LSN of the change, such as "16/B374D848".
+	LSN string `json:"lsn"`
+
+	// Operation changing the row: INSERT, UPDATE, or DELETE.
+	Operation string `json:"operation"`
+
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+
+	// New and Old values of the columns of the row, as text.
+	New map[string]*string `json:"new,omitempty"`
+	Old map[string]*string `json:"old,omitempty"`
+
+	CommitTime time.Time `json:"commit_time"`
+}
+
+// upgrade the fields of a payload from a version to the next one.
+type upgrade func(fields map[string]json.RawMessage) error
+
+// decode a payload of any version up to the current one into v, applying the upgrades from its version.
+func decode(payload []byte, current int, upgrades []upgrade, v any) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return err
+	}
+	version := 1
+	if raw, ok := fields["version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return fmt.Errorf("invalid version: %w", err)
+		}
+	}
+	switch {
+	case version < 1:
+		return fmt.Errorf("invalid version %d", version)
+	case version > current:
+		return fmt.Errorf("%w %d: current version is %d", ErrUnsupportedVersion, version, current)
+	}
+	for ; version < current; version++ {
+		if err := upgrades[version-1](fields); err != nil {
+			return fmt.Errorf("cannot upgrade from version %d: %w", version, err)
+		}
+	}
+	fields["version"] = json.RawMessage(fmt.Sprint(current))
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
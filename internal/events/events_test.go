@@ -0,0 +1,234 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Update the golden payloads of the current versions")
+
+// TestGolden checks that the payloads of the current versions are stable.
+//
+// Golden payloads are never changed or removed, as consumers might still receive them:
+// a new version adds new golden payloads, and the ones of the previous versions must still be decoded by TestDecodeGolden.
+func TestGolden(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		payload any
+	}{
+		{
+			name:    "order.created.v1",
+			payload: NewOrder("order"),
+		},
+		{
+			name: "order.paid.v1",
+			payload: Order{
+				Version:   OrderVersion,
+				OrderID:   "order",
+				PaymentID: "payment",
+			},
+		},
+		{
+			name: "order.canceled.v1",
+			payload: Order{
+				Version: OrderVersion,
+				OrderID: "order",
+				Reason:  "cannot charge order: card declined",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := json.MarshalIndent(tt.payload, "", "\t")
+			if err != nil {
+				t.Fatalf("cannot marshal payload: %v", err)
+			}
+			got = append(got, '\n')
+			golden := filepath.Join("testdata", tt.name+".json")
+			if *update {
+				if _, err := os.Stat(golden); err == nil {
+					t.Fatalf("golden payload %s already exists: it must not change", golden)
+				}
+				if err := os.WriteFile(golden, got, 0o644); err != nil { // #nosec G306
+					t.Fatalf("cannot write golden payload: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("cannot read golden payload (create it with go test ./internal/events -update): %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("payload changed:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// TestDecodeGolden checks that the golden payloads of every version are decoded as the current version.
+func TestDecodeGolden(t *testing.T) {
+	t.Parallel()
+	files, err := filepath.Glob("testdata/order.*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no golden order payloads")
+	}
+	for _, file := range files {
+		b, err := os.ReadFile(file) // #nosec G304
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodeOrder(b)
+		if err != nil {
+			t.Errorf("DecodeOrder(%s) error = %v", file, err)
+			continue
+		}
+		if got.Version != OrderVersion || got.OrderID == "" {
+			t.Errorf("DecodeOrder(%s) = %+v, want an order of version %d", file, got, OrderVersion)
+		}
+	}
+}
+
+func TestDecodeOrder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		payload string
+		want    Order
+		wantErr error
+		errMsg  string
+	}{
+		{
+			name:    "current",
+			payload: `{"version":1,"order_id":"order","payment_id":"payment"}`,
+			want:    Order{Version: 1, OrderID: "order", PaymentID: "payment"},
+		},
+		{
+			name:    "unversioned",
+			payload: `{"order_id":"order","reason":"failed"}`,
+			want:    Order{Version: 1, OrderID: "order", Reason: "failed"},
+		},
+		{
+			name:    "unknown_fields",
+			payload: `{"version":1,"order_id":"order","quantity":2}`,
+			want:    Order{Version: 1, OrderID: "order"},
+		},
+		{
+			name:    "newer",
+			payload: `{"version":2,"order_id":"order"}`,
+			wantErr: ErrUnsupportedVersion,
+			errMsg:  "cannot decode order event: unsupported event payload version 2: current version is 1",
+		},
+		{
+			name:    "invalid_version",
+			payload: `{"version":0,"order_id":"order"}`,
+			errMsg:  "cannot decode order event: invalid version 0",
+		},
+		{
+			name:    "invalid_payload",
+			payload: `[]`,
+			errMsg:  "cannot decode order event: json: cannot unmarshal array", // Suffix depends on the Go version.
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := DecodeOrder([]byte(tt.payload))
+			if tt.errMsg != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.errMsg) {
+					t.Errorf("DecodeOrder() error = %v, want %q", err, tt.errMsg)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("DecodeOrder() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeOrder() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DecodeOrder() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeUpgrade(t *testing.T) {
+	t.Parallel()
+	// Version 2 renamed id to order_id, and version 3 requires a reason.
+	upgrades := []upgrade{
+		func(fields map[string]json.RawMessage) error {
+			fields["order_id"] = fields["id"]
+			delete(fields, "id")
+			return nil
+		},
+		func(fields map[string]json.RawMessage) error {
+			if _, ok := fields["reason"]; !ok {
+				fields["reason"] = json.RawMessage(`"unknown"`)
+			}
+			return nil
+		},
+	}
+	var got Order
+	if err := decode([]byte(`{"id":"order"}`), 3, upgrades, &got); err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if want := (Order{Version: 3, OrderID: "order", Reason: "unknown"}); got != want {
+		t.Errorf("decode() = %+v, want %+v", got, want)
+	}
+}
+
+// TestSchema checks that the JSON Schema of the current version has the fields of the payload.
+func TestSchema(t *testing.T) {
+	t.Parallel()
+	b, err := Schemas.ReadFile("schema/order.v1.json")
+	if err != nil {
+		t.Fatalf("cannot read schema: %v", err)
+	}
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+	}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("cannot unmarshal schema: %v", err)
+	}
+	fields := func(o Order) []string {
+		b, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]any
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatal(err)
+		}
+		return sortedKeys(m)
+	}
+	all := fields(Order{Version: OrderVersion, OrderID: "order", PaymentID: "payment", Reason: "reason"})
+	if properties := sortedKeys(schema.Properties); !slices.Equal(all, properties) {
+		t.Errorf("schema properties = %v, want %v", properties, all)
+	}
+	minimal := fields(NewOrder("order"))
+	for _, r := range schema.Required {
+		if !slices.Contains(minimal, r) {
+			t.Errorf("required property %q is omitted from payloads", r)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,289 @@
+// Package faultinject injects latency, errors, and dropped connections into HTTP requests and gRPC calls,
+// so the retry, hedging, and circuit breaking behavior of clients can be validated against a real server.
+//
+// Faults are injected into a percentage of the requests to an endpoint by rules, or into a single request
+// by its X-Fault-Injection header (x-fault-injection gRPC metadata), such as:
+//
+//	X-Fault-Injection: latency=500ms,error=unavailable
+//
+// It must only be enabled outside of production.
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Header of a request with the fault to inject into it, in the ParseFault format.
+const Header = "X-Fault-Injection"
+
+// Fault injected into a request.
+type Fault struct {
+	// Latency before handling the request. The wait is cut short if the request is canceled.
+	Latency time.Duration
+
+	// Code of the error to fail the request with after the latency, if not codes.OK.
+	// HTTP requests fail with the equivalent status code, such as 503 Service Unavailable for codes.Unavailable.
+	Code codes.Code
+
+	// Drop the connection after the latency, without a response.
+	// As a gRPC interceptor can't close the connection of a call, gRPC calls fail with codes.Unavailable instead,
+	// as clients see on a dropped connection.
+	Drop bool
+}
+
+// Rule injecting a fault into a percentage of the requests to an endpoint.
+type Rule struct {
+	// Endpoint is the prefix of the HTTP paths, such as /product/, or gRPC methods,
+	// such as /pgxtutorial.v1.Inventory/, the rule applies to. Empty applies to every request.
+	Endpoint string
+
+	// Percentage of the requests to inject the fault into, from 0 to 100.
+	Percentage float64
+
+	Fault Fault
+}
+
+// Injector of faults into requests.
+type Injector struct {
+	// Rules checked in order, injecting the fault of the first one that applies to the request.
+	Rules []Rule
+
+	// Headers enables injecting the fault of the Header of a request, instead of the ones of the rules.
+	Headers bool
+}
+
+// fault to inject into a request to an endpoint with the given Header value, if any.
+func (i *Injector) fault(endpoint, header string) (Fault, bool, error) {
+	if i.Headers && header != "" {
+		f, err := ParseFault(header)
+		return f, err == nil, err
+	}
+	for _, r := range i.Rules {
+		if strings.HasPrefix(endpoint, r.Endpoint) && rand.Float64()*100 < r.Percentage { // #nosec G404
+			return r.Fault, true, nil
+		}
+	}
+	return Fault{}, false, nil
+}
+
+// errDrop is returned by inject when the connection should be dropped.
+var errDrop = errors.New("connection dropped by fault injection")
+
+// inject the fault, waiting for its latency, and returning the error to fail the request with, if any.
+func inject(ctx context.Context, f Fault) error {
+	if f.Latency > 0 {
+		t := time.NewTimer(f.Latency)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	switch {
+	case f.Drop:
+		return errDrop
+	case f.Code != codes.OK:
+		return status.Errorf(f.Code, "fault injected: %v", f.Code)
+	}
+	return nil
+}
+
+// Handler injects faults into HTTP requests.
+func (i *Injector) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok, err := i.fault(r.URL.Path, r.Header.Get(Header))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid %s header: %v", Header, err), http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch err := inject(r.Context(), f); {
+		case err == errDrop:
+			// Closes the connection, or resets the stream of an HTTP/2 request, without a response.
+			panic(http.ErrAbortHandler)
+		case err != nil && r.Context().Err() != nil:
+			return // Canceled by the client while waiting.
+		case err != nil:
+			code := status.Code(err)
+			http.Error(w, "Fault injected: "+code.String(), httpStatus(code))
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// httpStatus equivalent to a gRPC code.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.Canceled:
+		return 499 // Client Closed Request, as used by nginx.
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// grpcFault to inject into a call.
+func (i *Injector) grpcFault(ctx context.Context, method string) (Fault, bool, error) {
+	var header string
+	if v := metadata.ValueFromIncomingContext(ctx, strings.ToLower(Header)); len(v) != 0 {
+		header = v[0]
+	}
+	f, ok, err := i.fault(method, header)
+	if err != nil {
+		return f, ok, status.Errorf(codes.InvalidArgument, "invalid %s metadata: %v", strings.ToLower(Header), err)
+	}
+	return f, ok, nil
+}
+
+// grpcInject injects the fault into a call, returning the error to fail it with, if any.
+func grpcInject(ctx context.Context, f Fault) error {
+	switch err := inject(ctx, f); {
+	case err == errDrop:
+		return status.Error(codes.Unavailable, err.Error())
+	case err != nil && ctx.Err() != nil:
+		return status.FromContextError(err).Err()
+	default:
+		return err
+	}
+}
+
+// UnaryServerInterceptor injects faults into unary gRPC calls.
+func (i *Injector) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		f, ok, err := i.grpcFault(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if err := grpcInject(ctx, f); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor injects faults into streaming gRPC calls, before they start.
+func (i *Injector) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		f, ok, err := i.grpcFault(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := grpcInject(ss.Context(), f); err != nil {
+				return err
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// ParseFault parses a fault as a comma-separated list of its settings, such as latency=200ms,error=unavailable:
+//
+//   - latency=<duration>: latency before handling the request.
+//   - error=<code>: gRPC code of the error to fail the request with, such as unavailable or resource_exhausted.
+//   - drop: drop the connection.
+func ParseFault(s string) (Fault, error) {
+	var f Fault
+	for _, setting := range strings.Split(s, ",") {
+		if err := f.set(strings.TrimSpace(setting)); err != nil {
+			return Fault{}, err
+		}
+	}
+	return f, nil
+}
+
+// set a setting of the fault.
+func (f *Fault) set(setting string) error {
+	key, value, _ := strings.Cut(setting, "=")
+	switch key {
+	case "latency":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid latency %q", value)
+		}
+		f.Latency = d
+	case "error":
+		// codes.Code parses the names of the codes in upper case, such as UNAVAILABLE.
+		if err := f.Code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(value)))); err != nil || f.Code == codes.OK {
+			return fmt.Errorf("invalid error code %q", value)
+		}
+	case "drop":
+		f.Drop = true
+	default:
+		return fmt.Errorf("unknown fault setting %q", setting)
+	}
+	return nil
+}
+
+// ParseRules parses a semicolon-separated list of rules, each as a comma-separated list of the settings of its fault,
+// as in ParseFault, along with:
+//
+//   - endpoint=<prefix>: prefix of the HTTP paths or gRPC methods the rule applies to (default: every request).
+//   - percent=<number>: percentage of the requests to inject the fault into, from 0 to 100 (default: 100).
+//
+// Example: percent=5,error=unavailable;endpoint=/pgxtutorial.v1.Inventory/SearchProducts,percent=10,latency=2s
+func ParseRules(s string) ([]Rule, error) {
+	var rules []Rule
+	for _, spec := range strings.Split(s, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		r := Rule{Percentage: 100}
+		for _, setting := range strings.Split(spec, ",") {
+			setting = strings.TrimSpace(setting)
+			key, value, _ := strings.Cut(setting, "=")
+			switch key {
+			case "endpoint":
+				r.Endpoint = value
+			case "percent":
+				p, err := strconv.ParseFloat(value, 64)
+				if err != nil || p < 0 || p > 100 {
+					return nil, fmt.Errorf("invalid percentage %q: must be from 0 to 100", value)
+				}
+				r.Percentage = p
+			default:
+				if err := r.Fault.set(setting); err != nil {
+					return nil, err
+				}
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
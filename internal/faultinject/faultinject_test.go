@@ -0,0 +1,187 @@
+package faultinject_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/faultinject"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseRules(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		s       string
+		want    []faultinject.Rule
+		wantErr string
+	}{
+		{
+			name: "empty",
+			s:    "",
+		},
+		{
+			name: "rules",
+			s:    "percent=5,error=unavailable; endpoint=/pgxtutorial.v1.Inventory/SearchProducts,percent=10,latency=2s,drop",
+			want: []faultinject.Rule{
+				{
+					Percentage: 5,
+					Fault:      faultinject.Fault{Code: codes.Unavailable},
+				},
+				{
+					Endpoint:   "/pgxtutorial.v1.Inventory/SearchProducts",
+					Percentage: 10,
+					Fault:      faultinject.Fault{Latency: 2 * time.Second, Drop: true},
+				},
+			},
+		},
+		{
+			name: "default_percentage",
+			s:    "error=resource_exhausted",
+			want: []faultinject.Rule{
+				{
+					Percentage: 100,
+					Fault:      faultinject.Fault{Code: codes.ResourceExhausted},
+				},
+			},
+		},
+		{
+			name:    "invalid_percentage",
+			s:       "percent=150,drop",
+			wantErr: `invalid percentage "150": must be from 0 to 100`,
+		},
+		{
+			name:    "invalid_code",
+			s:       "error=ok",
+			wantErr: `invalid error code "ok"`,
+		},
+		{
+			name:    "invalid_latency",
+			s:       "latency=-1s",
+			wantErr: `invalid latency "-1s"`,
+		},
+		{
+			name:    "unknown",
+			s:       "delay=1s",
+			wantErr: `unknown fault setting "delay=1s"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := faultinject.ParseRules(tt.s)
+			if err == nil && tt.wantErr != "" || err != nil && err.Error() != tt.wantErr {
+				t.Errorf("ParseRules() error = %v, wantErr %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	injector := &faultinject.Injector{
+		Rules: []faultinject.Rule{
+			{Endpoint: "/broken/", Percentage: 100, Fault: faultinject.Fault{Code: codes.Unavailable}},
+			{Endpoint: "/dropped/", Percentage: 100, Fault: faultinject.Fault{Drop: true}},
+			{Endpoint: "/never/", Percentage: 0, Fault: faultinject.Fault{Code: codes.Internal}},
+		},
+		Headers: true,
+	}
+	server := httptest.NewServer(injector.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name    string
+		path    string
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{name: "no_fault", path: "/product/1", want: http.StatusNoContent},
+		{name: "rule", path: "/broken/1", want: http.StatusServiceUnavailable},
+		{name: "zero_percent", path: "/never/1", want: http.StatusNoContent},
+		{name: "drop", path: "/dropped/1", wantErr: true},
+		{name: "header", path: "/product/1", header: "latency=10ms,error=resource_exhausted", want: http.StatusTooManyRequests},
+		{name: "header_over_rules", path: "/broken/1", header: "latency=1ms", want: http.StatusNoContent},
+		{name: "header_drop", path: "/product/1", header: "drop", wantErr: true},
+		{name: "invalid_header", path: "/product/1", header: "error=nope", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set(faultinject.Header, tt.header)
+			}
+			resp, err := server.Client().Do(req)
+			if tt.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Errorf("request got status %d, want the connection to be dropped", resp.StatusCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cannot make request: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("request got status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+	injector := &faultinject.Injector{
+		Rules: []faultinject.Rule{
+			{Endpoint: "/pgxtutorial.v1.Inventory/SearchProducts", Percentage: 100, Fault: faultinject.Fault{Drop: true}},
+		},
+	}
+	interceptor := injector.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	call := func(ctx context.Context, method string) error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+	if err := call(context.Background(), "/pgxtutorial.v1.Inventory/GetProduct"); err != nil {
+		t.Errorf("call without a fault error = %v", err)
+	}
+	if err := call(context.Background(), "/pgxtutorial.v1.Inventory/SearchProducts"); status.Code(err) != codes.Unavailable {
+		t.Errorf("call with a dropped connection error = %v, want code %v", err, codes.Unavailable)
+	}
+
+	// The header is ignored unless enabled.
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-fault-injection", "error=internal"))
+	if err := call(ctx, "/pgxtutorial.v1.Inventory/GetProduct"); err != nil {
+		t.Errorf("call with a disabled header error = %v", err)
+	}
+	injector.Headers = true
+	if err := call(ctx, "/pgxtutorial.v1.Inventory/GetProduct"); status.Code(err) != codes.Internal {
+		t.Errorf("call with a header error = %v, want code %v", err, codes.Internal)
+	}
+
+	ctx, cancel := context.WithCancel(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-fault-injection", "latency=1h")))
+	cancel()
+	if err := call(ctx, "/pgxtutorial.v1.Inventory/GetProduct"); status.Code(err) != codes.Canceled {
+		t.Errorf("call canceled while waiting error = %v, want code %v", err, codes.Canceled)
+	}
+}
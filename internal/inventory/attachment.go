@@ -0,0 +1,52 @@
+package inventory
+
+import (
+	"net/url"
+	"slices"
+)
+
+// MaxReviewAttachments is the maximum number of attachments of a review.
+const MaxReviewAttachments = 5
+
+// maxAttachmentURLLength is the maximum length of the URL of an attachment.
+const maxAttachmentURLLength = 2048
+
+// attachmentContentTypes supported.
+var attachmentContentTypes = []string{"image/gif", "image/jpeg", "image/png", "image/webp"}
+
+// Attachment of a review, such as a photo of the product.
+// The file itself is stored elsewhere, and only its URL is kept.
+type Attachment struct {
+	URL         string
+	ContentType string
+}
+
+func (a *Attachment) validate() error {
+	if a.URL == "" {
+		return ValidationError{"missing attachment URL"}
+	}
+	if len(a.URL) > maxAttachmentURLLength {
+		return ValidationError{"attachment URL is too long"}
+	}
+	u, err := url.Parse(a.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.User != nil {
+		return ValidationError{"invalid attachment URL"}
+	}
+	if !slices.Contains(attachmentContentTypes, a.ContentType) {
+		return ValidationError{"unsupported attachment content type"}
+	}
+	return nil
+}
+
+// validateAttachments checks the number of attachments of a review, and each attachment.
+func validateAttachments(attachments []Attachment) error {
+	if len(attachments) > MaxReviewAttachments {
+		return ValidationError{"too many attachments"}
+	}
+	for _, a := range attachments {
+		if err := a.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package inventory
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// DeleteProductsConfirmationThreshold is the number of products from which DeleteProducts requires a confirmation token.
+const DeleteProductsConfirmationThreshold = 100
+
+// DefaultDeleteProductsBatchSize is the number of products deleted by each transaction of DeleteProducts, by default.
+const DefaultDeleteProductsBatchSize = 100
+
+// Number of products deleted by DeleteProducts.
+const (
+	defaultDeleteProductsLimit = 1000
+	maxDeleteProductsLimit     = 100000
+)
+
+// ProductFilter selects the products deleted by DeleteProducts.
+type ProductFilter struct {
+	// Tenant whose products are matched, or empty for the products without a tenant.
+	// Products of other tenants are never matched.
+	Tenant string
+
+	// Status of the products matched, if set.
+	Status ProductStatus
+
+	// ModifiedBefore matches only the products last modified before it, if set.
+	ModifiedBefore time.Time
+
+	// WithReviews also matches the products with reviews, which are deleted along with them.
+	// Otherwise, products with reviews are left out.
+	WithReviews bool
+}
+
+// DeleteProductsParams used by DeleteProducts.
+type DeleteProductsParams struct {
+	Filter ProductFilter
+
+	// Limit of products deleted. Defaults to 1000.
+	Limit int
+
+	// DryRun returns the number of products matched, and the confirmation token to delete them, without deleting them.
+	DryRun bool
+
+	// Confirmation token returned by a dry run with the same parameters.
+	// It's required to delete more than DeleteProductsConfirmationThreshold products.
+	Confirmation string
+
+	// BatchSize is the maximum number of products deleted by each transaction. Defaults to DefaultDeleteProductsBatchSize.
+	BatchSize int
+}
+
+func (p *DeleteProductsParams) validate() error {
+	if p.Filter.Status != "" && !p.Filter.Status.Valid() {
+		return ValidationError{"invalid product status"}
+	}
+	if p.Limit == 0 {
+		p.Limit = defaultDeleteProductsLimit
+	}
+	if p.Limit < 0 || p.Limit > maxDeleteProductsLimit {
+		return ValidationError{fmt.Sprintf("limit must be between 1 and %d", maxDeleteProductsLimit)}
+	}
+	if p.BatchSize < 0 {
+		return ValidationError{"batch size cannot be negative"}
+	}
+	return nil
+}
+
+// confirmation token to delete the matched products.
+// It changes when the number of products matched does, so a large delete is confirmed again if the products change.
+// It guards against deleting products by accident rather than authorizing it, so it isn't secret.
+func (p *DeleteProductsParams) confirmation(matched int) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%q %q %s %t %d %d",
+		p.Filter.Tenant, p.Filter.Status, p.Filter.ModifiedBefore.UTC().Format(time.RFC3339Nano), p.Filter.WithReviews,
+		p.Limit, matched)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// DeleteProductsResult of DeleteProducts.
+type DeleteProductsResult struct {
+	// Matched products, up to the limit.
+	Matched int
+
+	// Deleted products, and the reviews deleted along with them.
+	Deleted int
+	Reviews int
+
+	// Confirmation token to delete the matched products, returned by a dry run matching more than
+	// DeleteProductsConfirmationThreshold products.
+	Confirmation string
+}
+
+// ConfirmationRequiredError is returned by DeleteProducts when deleting the products requires a confirmation token
+// that wasn't given, or that doesn't match the products anymore.
+type ConfirmationRequiredError struct {
+	// Matched products.
+	Matched int
+}
+
+func (e *ConfirmationRequiredError) Error() string {
+	return fmt.Sprintf("deleting %d products requires the confirmation token of a dry run", e.Matched)
+}
+
+// DeleteProducts deletes up to params.Limit products matching params.Filter.
+//
+// Deleting more than DeleteProductsConfirmationThreshold products requires the confirmation token returned by a dry run,
+// and otherwise fails with a *ConfirmationRequiredError without deleting anything.
+//
+// Products are deleted in batches of params.BatchSize, each in a transaction of its own, so deleting many products
+// doesn't hold locks for long. If a batch fails, the result has the products deleted by the previous batches.
+func (s *Service) DeleteProducts(ctx context.Context, params DeleteProductsParams) (*DeleteProductsResult, error) {
+	if !params.DryRun {
+		if err := s.writable(); err != nil {
+			return nil, err
+		}
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	matched, err := s.products.CountProducts(ctx, params.Filter, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	result := &DeleteProductsResult{
+		Matched: matched,
+	}
+	large := matched > DeleteProductsConfirmationThreshold
+	if params.DryRun {
+		if large {
+			result.Confirmation = params.confirmation(matched)
+		}
+		return result, nil
+	}
+	if large && params.Confirmation != params.confirmation(matched) {
+		return nil, &ConfirmationRequiredError{Matched: matched}
+	}
+	// Products matching the filter after counting them aren't deleted past the number confirmed.
+	size := cmp.Or(params.BatchSize, DefaultDeleteProductsBatchSize)
+	for result.Deleted < matched {
+		batch, err := s.products.DeleteProducts(ctx, params.Filter, min(size, matched-result.Deleted))
+		if err != nil {
+			return result, err
+		}
+		result.Deleted += batch.Deleted
+		result.Reviews += batch.Reviews
+		if batch.Deleted == 0 {
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceDeleteProductsValidation(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewService(inventory.NewMockDB(gomock.NewController(t)))
+	tests := []struct {
+		name   string
+		params inventory.DeleteProductsParams
+		want   string
+	}{
+		{"invalid_status", inventory.DeleteProductsParams{Filter: inventory.ProductFilter{Status: "sold"}}, "invalid product status"},
+		{"negative_limit", inventory.DeleteProductsParams{Limit: -1}, "limit must be between 1 and 100000"},
+		{"limit_too_large", inventory.DeleteProductsParams{Limit: 100001}, "limit must be between 1 and 100000"},
+		{"negative_batch_size", inventory.DeleteProductsParams{BatchSize: -1}, "batch size cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := s.DeleteProducts(context.Background(), tt.params); err == nil || err.Error() != tt.want {
+				t.Errorf("Service.DeleteProducts() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceDeleteProducts(t *testing.T) {
+	t.Parallel()
+	filter := inventory.ProductFilter{
+		Tenant: "acme",
+		Status: inventory.ProductStatusDiscontinued,
+	}
+	db := inventory.NewMockDB(gomock.NewController(t))
+	db.EXPECT().CountProducts(gomock.Any(), filter, 1000).Return(30, nil)
+	gomock.InOrder(
+		db.EXPECT().DeleteProducts(gomock.Any(), filter, 20).Return(&inventory.DeleteProductsResult{Deleted: 20, Reviews: 3}, nil),
+		db.EXPECT().DeleteProducts(gomock.Any(), filter, 10).Return(&inventory.DeleteProductsResult{Deleted: 10}, nil),
+	)
+	s := inventory.NewService(db)
+	got, err := s.DeleteProducts(context.Background(), inventory.DeleteProductsParams{
+		Filter:    filter,
+		BatchSize: 20,
+	})
+	if err != nil {
+		t.Fatalf("Service.DeleteProducts() error = %v", err)
+	}
+	want := &inventory.DeleteProductsResult{Matched: 30, Deleted: 30, Reviews: 3}
+	if !cmp.Equal(want, got) {
+		t.Errorf("value returned by Service.DeleteProducts() doesn't match: %v", cmp.Diff(want, got))
+	}
+}
+
+func TestServiceDeleteProductsConfirmation(t *testing.T) {
+	t.Parallel()
+	filter := inventory.ProductFilter{
+		Tenant: "acme",
+	}
+	db := inventory.NewMockDB(gomock.NewController(t))
+	gomock.InOrder(
+		db.EXPECT().CountProducts(gomock.Any(), filter, 500).Return(250, nil).Times(2),
+		db.EXPECT().CountProducts(gomock.Any(), filter, 500).Return(260, nil),
+		db.EXPECT().CountProducts(gomock.Any(), filter, 500).Return(250, nil),
+		db.EXPECT().DeleteProducts(gomock.Any(), filter, 100).Return(&inventory.DeleteProductsResult{Deleted: 100}, nil),
+		db.EXPECT().DeleteProducts(gomock.Any(), filter, 100).Return(&inventory.DeleteProductsResult{Deleted: 90}, nil),
+		db.EXPECT().DeleteProducts(gomock.Any(), filter, 60).Return(nil, errors.New("unexpected error")),
+	)
+	s := inventory.NewService(db)
+	params := inventory.DeleteProductsParams{
+		Filter: filter,
+		Limit:  500,
+		DryRun: true,
+	}
+	dryRun, err := s.DeleteProducts(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Service.DeleteProducts() dry run error = %v", err)
+	}
+	if dryRun.Matched != 250 || dryRun.Deleted != 0 || dryRun.Confirmation == "" {
+		t.Fatalf("Service.DeleteProducts() dry run = %+v, want 250 products matched with a confirmation token", dryRun)
+	}
+
+	params.DryRun = false
+	var confirmErr *inventory.ConfirmationRequiredError
+	if _, err := s.DeleteProducts(context.Background(), params); !errors.As(err, &confirmErr) || confirmErr.Matched != 250 {
+		t.Errorf("Service.DeleteProducts() without confirmation error = %v, want *ConfirmationRequiredError", err)
+	}
+
+	// The token isn't valid anymore once the number of products matched changes.
+	params.Confirmation = dryRun.Confirmation
+	if _, err := s.DeleteProducts(context.Background(), params); !errors.As(err, &confirmErr) || confirmErr.Matched != 260 {
+		t.Errorf("Service.DeleteProducts() after the products changed error = %v, want *ConfirmationRequiredError", err)
+	}
+
+	got, err := s.DeleteProducts(context.Background(), params)
+	if err == nil || err.Error() != "unexpected error" {
+		t.Errorf("Service.DeleteProducts() error = %v, want unexpected error", err)
+	}
+	want := &inventory.DeleteProductsResult{Matched: 250, Deleted: 190}
+	if !cmp.Equal(want, got) {
+		t.Errorf("value returned by Service.DeleteProducts() doesn't match: %v", cmp.Diff(want, got))
+	}
+}
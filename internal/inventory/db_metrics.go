@@ -0,0 +1,349 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// WithDBMetrics decorates the DB to record the number of calls and their duration by method and outcome,
+// so the time spent on the database can be told apart from the time spent on the rest of the service.
+// Unlike the outcome of the service calls, the outcome of the database calls tells infrastructure errors apart.
+func WithDBMetrics(db DB, meter metric.Meter) (DB, error) {
+	calls, err := meter.Int64Counter("inventory.db.calls",
+		metric.WithDescription("Number of database calls of the inventory service."))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("inventory.db.duration",
+		metric.WithDescription("Duration of database calls of the inventory service."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return observedDB{
+		next: db,
+		observe: func(ctx context.Context, method string) (context.Context, func(error)) {
+			start := time.Now()
+			return ctx, func(err error) {
+				attrs := metric.WithAttributes(
+					attribute.String("method", method),
+					attribute.String("outcome", dbOutcome(err)),
+				)
+				calls.Add(ctx, 1, attrs)
+				duration.Record(ctx, time.Since(start).Seconds(), attrs)
+			}
+		},
+	}, nil
+}
+
+// dbOutcome of a database call, used to classify calls in metrics.
+func dbOutcome(err error) string {
+	if errors.As(err, new(*InfrastructureError)) {
+		return "infrastructure"
+	}
+	return outcome(err)
+}
+
+// observedDB decorated by an observer.
+type observedDB struct {
+	next    DB
+	observe observer
+}
+
+func (o observedDB) CreateProduct(ctx context.Context, params CreateProductParams) (_ *CreateProductResult, err error) {
+	ctx, done := o.observe(ctx, "CreateProduct")
+	defer func() { done(err) }()
+	return o.next.CreateProduct(ctx, params)
+}
+
+func (o observedDB) UpdateProduct(ctx context.Context, params UpdateProductParams) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "UpdateProduct")
+	defer func() { done(err) }()
+	return o.next.UpdateProduct(ctx, params)
+}
+
+func (o observedDB) GetProduct(ctx context.Context, id string) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "GetProduct")
+	defer func() { done(err) }()
+	return o.next.GetProduct(ctx, id)
+}
+
+func (o observedDB) GetProductBySlug(ctx context.Context, slug string) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "GetProductBySlug")
+	defer func() { done(err) }()
+	return o.next.GetProductBySlug(ctx, slug)
+}
+
+func (o observedDB) GetProductBySKU(ctx context.Context, sku string) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "GetProductBySKU")
+	defer func() { done(err) }()
+	return o.next.GetProductBySKU(ctx, sku)
+}
+
+func (o observedDB) GetProducts(ctx context.Context, ids []string) (_ []*Product, err error) {
+	ctx, done := o.observe(ctx, "GetProducts")
+	defer func() { done(err) }()
+	return o.next.GetProducts(ctx, ids)
+}
+
+func (o observedDB) GetProductAt(ctx context.Context, id string, at time.Time) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "GetProductAt")
+	defer func() { done(err) }()
+	return o.next.GetProductAt(ctx, id, at)
+}
+
+func (o observedDB) UpsertProductTranslation(ctx context.Context, params ProductTranslation) (err error) {
+	ctx, done := o.observe(ctx, "UpsertProductTranslation")
+	defer func() { done(err) }()
+	return o.next.UpsertProductTranslation(ctx, params)
+}
+
+func (o observedDB) DeleteProductTranslation(ctx context.Context, productID, locale string) (err error) {
+	ctx, done := o.observe(ctx, "DeleteProductTranslation")
+	defer func() { done(err) }()
+	return o.next.DeleteProductTranslation(ctx, productID, locale)
+}
+
+func (o observedDB) GetProductTranslation(ctx context.Context, productID string, locales []string) (_ *ProductTranslation, err error) {
+	ctx, done := o.observe(ctx, "GetProductTranslation")
+	defer func() { done(err) }()
+	return o.next.GetProductTranslation(ctx, productID, locales)
+}
+
+func (o observedDB) ProductHistory(ctx context.Context, id string) (_ []Version, err error) {
+	ctx, done := o.observe(ctx, "ProductHistory")
+	defer func() { done(err) }()
+	return o.next.ProductHistory(ctx, id)
+}
+
+func (o observedDB) SearchProducts(ctx context.Context, params SearchProductsParams) (_ *SearchProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "SearchProducts")
+	defer func() { done(err) }()
+	return o.next.SearchProducts(ctx, params)
+}
+
+func (o observedDB) ExportProducts(ctx context.Context, params SearchProductsParams, fn func(*Product) error) (err error) {
+	ctx, done := o.observe(ctx, "ExportProducts")
+	defer func() { done(err) }()
+	return o.next.ExportProducts(ctx, params, fn)
+}
+
+func (o observedDB) FindSimilarProducts(ctx context.Context, params FindSimilarProductsParams) (_ []SimilarProduct, err error) {
+	ctx, done := o.observe(ctx, "FindSimilarProducts")
+	defer func() { done(err) }()
+	return o.next.FindSimilarProducts(ctx, params)
+}
+
+func (o observedDB) ListTenantProducts(ctx context.Context, params ListTenantProductsParams) (_ []*Product, err error) {
+	ctx, done := o.observe(ctx, "ListTenantProducts")
+	defer func() { done(err) }()
+	return o.next.ListTenantProducts(ctx, params)
+}
+
+func (o observedDB) ApplyProductChanges(ctx context.Context, changes []ProductChange) (err error) {
+	ctx, done := o.observe(ctx, "ApplyProductChanges")
+	defer func() { done(err) }()
+	return o.next.ApplyProductChanges(ctx, changes)
+}
+
+func (o observedDB) CountProducts(ctx context.Context, filter ProductFilter, limit int) (_ int, err error) {
+	ctx, done := o.observe(ctx, "CountProducts")
+	defer func() { done(err) }()
+	return o.next.CountProducts(ctx, filter, limit)
+}
+
+func (o observedDB) DeleteProducts(ctx context.Context, filter ProductFilter, limit int) (_ *DeleteProductsResult, err error) {
+	ctx, done := o.observe(ctx, "DeleteProducts")
+	defer func() { done(err) }()
+	return o.next.DeleteProducts(ctx, filter, limit)
+}
+
+func (o observedDB) GetProductStats(ctx context.Context, id string) (_ *ProductStats, err error) {
+	ctx, done := o.observe(ctx, "GetProductStats")
+	defer func() { done(err) }()
+	return o.next.GetProductStats(ctx, id)
+}
+
+func (o observedDB) AddFavorite(ctx context.Context, params FavoriteParams) (err error) {
+	ctx, done := o.observe(ctx, "AddFavorite")
+	defer func() { done(err) }()
+	return o.next.AddFavorite(ctx, params)
+}
+
+func (o observedDB) RemoveFavorite(ctx context.Context, params FavoriteParams) (err error) {
+	ctx, done := o.observe(ctx, "RemoveFavorite")
+	defer func() { done(err) }()
+	return o.next.RemoveFavorite(ctx, params)
+}
+
+func (o observedDB) ListFavorites(ctx context.Context, params ListFavoritesParams) (_ *ListFavoritesResponse, err error) {
+	ctx, done := o.observe(ctx, "ListFavorites")
+	defer func() { done(err) }()
+	return o.next.ListFavorites(ctx, params)
+}
+
+func (o observedDB) RecordProductView(ctx context.Context, id string) (err error) {
+	ctx, done := o.observe(ctx, "RecordProductView")
+	defer func() { done(err) }()
+	return o.next.RecordProductView(ctx, id)
+}
+
+func (o observedDB) ListTrendingProducts(ctx context.Context, params ListTrendingProductsParams) (_ *ListProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "ListTrendingProducts")
+	defer func() { done(err) }()
+	return o.next.ListTrendingProducts(ctx, params)
+}
+
+func (o observedDB) ListRecentProducts(ctx context.Context, params ListRecentProductsParams) (_ *ListProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "ListRecentProducts")
+	defer func() { done(err) }()
+	return o.next.ListRecentProducts(ctx, params)
+}
+
+func (o observedDB) RecordRecentlyViewed(ctx context.Context, params RecentlyViewedParams) (err error) {
+	ctx, done := o.observe(ctx, "RecordRecentlyViewed")
+	defer func() { done(err) }()
+	return o.next.RecordRecentlyViewed(ctx, params)
+}
+
+func (o observedDB) ListRecentlyViewed(ctx context.Context, params ListRecentlyViewedParams) (_ *ListProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "ListRecentlyViewed")
+	defer func() { done(err) }()
+	return o.next.ListRecentlyViewed(ctx, params)
+}
+
+func (o observedDB) CreateSupplier(ctx context.Context, params CreateSupplierParams) (_ *Supplier, err error) {
+	ctx, done := o.observe(ctx, "CreateSupplier")
+	defer func() { done(err) }()
+	return o.next.CreateSupplier(ctx, params)
+}
+
+func (o observedDB) UpdateSupplier(ctx context.Context, params UpdateSupplierParams) (_ *Supplier, err error) {
+	ctx, done := o.observe(ctx, "UpdateSupplier")
+	defer func() { done(err) }()
+	return o.next.UpdateSupplier(ctx, params)
+}
+
+func (o observedDB) DeleteSupplier(ctx context.Context, id string) (err error) {
+	ctx, done := o.observe(ctx, "DeleteSupplier")
+	defer func() { done(err) }()
+	return o.next.DeleteSupplier(ctx, id)
+}
+
+func (o observedDB) GetSupplier(ctx context.Context, id string) (_ *Supplier, err error) {
+	ctx, done := o.observe(ctx, "GetSupplier")
+	defer func() { done(err) }()
+	return o.next.GetSupplier(ctx, id)
+}
+
+func (o observedDB) SetProductSupplier(ctx context.Context, params ProductSupplier) (err error) {
+	ctx, done := o.observe(ctx, "SetProductSupplier")
+	defer func() { done(err) }()
+	return o.next.SetProductSupplier(ctx, params)
+}
+
+func (o observedDB) RemoveProductSupplier(ctx context.Context, productID, supplierID string) (err error) {
+	ctx, done := o.observe(ctx, "RemoveProductSupplier")
+	defer func() { done(err) }()
+	return o.next.RemoveProductSupplier(ctx, productID, supplierID)
+}
+
+func (o observedDB) ListProductSuppliers(ctx context.Context, productID string) (_ []*ProductSupplier, err error) {
+	ctx, done := o.observe(ctx, "ListProductSuppliers")
+	defer func() { done(err) }()
+	return o.next.ListProductSuppliers(ctx, productID)
+}
+
+func (o observedDB) ListSupplierProducts(ctx context.Context, params ListSupplierProductsParams) (_ *ListProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "ListSupplierProducts")
+	defer func() { done(err) }()
+	return o.next.ListSupplierProducts(ctx, params)
+}
+
+func (o observedDB) CreateWarehouse(ctx context.Context, params CreateWarehouseParams) (_ *Warehouse, err error) {
+	ctx, done := o.observe(ctx, "CreateWarehouse")
+	defer func() { done(err) }()
+	return o.next.CreateWarehouse(ctx, params)
+}
+
+func (o observedDB) ListWarehouses(ctx context.Context) (_ []*Warehouse, err error) {
+	ctx, done := o.observe(ctx, "ListWarehouses")
+	defer func() { done(err) }()
+	return o.next.ListWarehouses(ctx)
+}
+
+func (o observedDB) SetProductStock(ctx context.Context, params SetProductStockParams) (err error) {
+	ctx, done := o.observe(ctx, "SetProductStock")
+	defer func() { done(err) }()
+	return o.next.SetProductStock(ctx, params)
+}
+
+func (o observedDB) TransferStock(ctx context.Context, params TransferStockParams) (err error) {
+	ctx, done := o.observe(ctx, "TransferStock")
+	defer func() { done(err) }()
+	return o.next.TransferStock(ctx, params)
+}
+
+func (o observedDB) GetProductStock(ctx context.Context, productID string) (_ []*StockLevel, err error) {
+	ctx, done := o.observe(ctx, "GetProductStock")
+	defer func() { done(err) }()
+	return o.next.GetProductStock(ctx, productID)
+}
+
+func (o observedDB) DeleteProduct(ctx context.Context, params DeleteProductParams) (err error) {
+	ctx, done := o.observe(ctx, "DeleteProduct")
+	defer func() { done(err) }()
+	return o.next.DeleteProduct(ctx, params)
+}
+
+func (o observedDB) CreateProductReview(ctx context.Context, params CreateProductReviewDBParams) (err error) {
+	ctx, done := o.observe(ctx, "CreateProductReview")
+	defer func() { done(err) }()
+	return o.next.CreateProductReview(ctx, params)
+}
+
+func (o observedDB) UpdateProductReview(ctx context.Context, params UpdateProductReviewParams) (err error) {
+	ctx, done := o.observe(ctx, "UpdateProductReview")
+	defer func() { done(err) }()
+	return o.next.UpdateProductReview(ctx, params)
+}
+
+func (o observedDB) GetProductReview(ctx context.Context, id string) (_ *ProductReview, err error) {
+	ctx, done := o.observe(ctx, "GetProductReview")
+	defer func() { done(err) }()
+	return o.next.GetProductReview(ctx, id)
+}
+
+func (o observedDB) GetProductReviews(ctx context.Context, params ProductReviewsParams) (_ *ProductReviewsResponse, err error) {
+	ctx, done := o.observe(ctx, "GetProductReviews")
+	defer func() { done(err) }()
+	return o.next.GetProductReviews(ctx, params)
+}
+
+func (o observedDB) CountReviewerReviews(ctx context.Context, reviewerID string, since time.Time) (_ int, err error) {
+	ctx, done := o.observe(ctx, "CountReviewerReviews")
+	defer func() { done(err) }()
+	return o.next.CountReviewerReviews(ctx, reviewerID, since)
+}
+
+func (o observedDB) ReviewHistory(ctx context.Context, id string) (_ []Version, err error) {
+	ctx, done := o.observe(ctx, "ReviewHistory")
+	defer func() { done(err) }()
+	return o.next.ReviewHistory(ctx, id)
+}
+
+func (o observedDB) DeleteProductReview(ctx context.Context, id string) (err error) {
+	ctx, done := o.observe(ctx, "DeleteProductReview")
+	defer func() { done(err) }()
+	return o.next.DeleteProductReview(ctx, id)
+}
+
+func (o observedDB) PurgeReviewerData(ctx context.Context, params PurgeReviewerDataParams) (_ *PurgeReviewerDataResult, err error) {
+	ctx, done := o.observe(ctx, "PurgeReviewerData")
+	defer func() { done(err) }()
+	return o.next.PurgeReviewerData(ctx, params)
+}
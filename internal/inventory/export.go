@@ -0,0 +1,27 @@
+package inventory
+
+import "context"
+
+// MaxExportedProducts is the maximum number of products exported by ExportProducts,
+// so a single export can't hold a database connection while reading the whole catalog.
+const MaxExportedProducts = 100000
+
+// ExportProducts calls fn with each product matching the search, in the order of the search,
+// as the products are read from the database, rather than reading them all into memory first.
+//
+// Up to params.Pagination.Limit products are exported, which defaults to, and can't exceed, MaxExportedProducts.
+// The options of SearchProducts that only change its response, such as CountPriceBands, are ignored.
+// Exporting stops at the first error returned by fn, which ExportProducts returns.
+func (s *Service) ExportProducts(ctx context.Context, params SearchProductsParams, fn func(*Product) error) error {
+	if params.Pagination.Limit == 0 {
+		params.Pagination.Limit = MaxExportedProducts
+	}
+	if params.Pagination.Limit > MaxExportedProducts {
+		return ValidationError{"export limit cannot exceed the maximum number of exported products"}
+	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+	params.CountPriceBands, params.EstimateTotal = false, false
+	return s.products.ExportProducts(ctx, params, fn)
+}
@@ -0,0 +1,74 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceExportProductsValidation(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewService(inventory.NewMockDB(gomock.NewController(t)))
+	tests := []struct {
+		name   string
+		params inventory.SearchProductsParams
+		want   string
+	}{
+		{"missing_search", inventory.SearchProductsParams{}, "missing search string"},
+		{"limit_too_large", inventory.SearchProductsParams{
+			QueryString: "desk",
+			Pagination:  inventory.Pagination{Limit: inventory.MaxExportedProducts + 1},
+		}, "export limit cannot exceed the maximum number of exported products"},
+		{"negative_offset", inventory.SearchProductsParams{
+			QueryString: "desk",
+			Pagination:  inventory.Pagination{Offset: -1},
+		}, "pagination offset cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := s.ExportProducts(context.Background(), tt.params, func(*inventory.Product) error { return nil })
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Service.ExportProducts() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceExportProducts(t *testing.T) {
+	t.Parallel()
+	db := inventory.NewMockDB(gomock.NewController(t))
+	errStop := errors.New("stop")
+	db.EXPECT().ExportProducts(gomock.Any(), inventory.SearchProductsParams{
+		QueryString: "desk",
+		Pagination:  inventory.Pagination{Limit: inventory.MaxExportedProducts},
+	}, gomock.Any()).DoAndReturn(func(ctx context.Context, params inventory.SearchProductsParams, fn func(*inventory.Product) error) error {
+		for _, id := range []string{"desk", "chair", "lamp"} {
+			if err := fn(&inventory.Product{ID: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s := inventory.NewService(db)
+	var got []string
+	err := s.ExportProducts(context.Background(), inventory.SearchProductsParams{
+		QueryString:     "desk",
+		CountPriceBands: true,
+		EstimateTotal:   true,
+	}, func(p *inventory.Product) error {
+		if got = append(got, p.ID); len(got) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Errorf("Service.ExportProducts() error = %v, want %v", err, errStop)
+	}
+	if len(got) != 2 {
+		t.Errorf("Service.ExportProducts() exported %v, want to stop after two products", got)
+	}
+}
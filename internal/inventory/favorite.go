@@ -0,0 +1,98 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Favorite product of a user.
+type Favorite struct {
+	UserID    string
+	ProductID string
+	CreatedAt time.Time
+}
+
+// FavoriteParams used by AddFavorite and RemoveFavorite.
+type FavoriteParams struct {
+	UserID    string
+	ProductID string
+}
+
+func (p *FavoriteParams) validate() error {
+	if p.UserID == "" {
+		return ValidationError{"missing user ID"}
+	}
+	if p.ProductID == "" {
+		return ValidationError{"missing product ID"}
+	}
+	return nil
+}
+
+// ErrFavoriteNoProduct is returned when a favorite cannot be added because the product is not found.
+var ErrFavoriteNoProduct = errors.New("cannot find product to add to favorites")
+
+// AddFavorite adds a product to the favorites of a user.
+// Adding a product that is already a favorite is a no-op.
+func (s *Service) AddFavorite(ctx context.Context, params FavoriteParams) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+	return s.products.AddFavorite(ctx, params)
+}
+
+// RemoveFavorite removes a product from the favorites of a user.
+func (s *Service) RemoveFavorite(ctx context.Context, params FavoriteParams) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+	return s.products.RemoveFavorite(ctx, params)
+}
+
+// ListFavoritesParams used by ListFavorites.
+type ListFavoritesParams struct {
+	UserID     string
+	Pagination Pagination
+}
+
+// ListFavoritesResponse from ListFavorites.
+type ListFavoritesResponse struct {
+	Favorites []*Favorite
+	Total     int
+}
+
+// ListFavorites returns the favorites of a user, the most recent first.
+func (s *Service) ListFavorites(ctx context.Context, params ListFavoritesParams) (*ListFavoritesResponse, error) {
+	if params.UserID == "" {
+		return nil, ValidationError{"missing user ID"}
+	}
+	if err := params.Pagination.Validate(); err != nil {
+		return nil, err
+	}
+	return s.products.ListFavorites(ctx, params)
+}
+
+// ProductStats of a product.
+type ProductStats struct {
+	ProductID    string
+	Reviews      int
+	AverageScore float64
+	Favorites    int
+
+	// AverageSentiment of the reviews with a sentiment, or nil if there are none.
+	AverageSentiment *float64
+}
+
+// GetProductStats returns the stats of a product, or nil if it's not found.
+func (s *Service) GetProductStats(ctx context.Context, id string) (*ProductStats, error) {
+	if id == "" {
+		return nil, ValidationError{"missing product ID"}
+	}
+	return s.products.GetProductStats(ctx, id)
+}
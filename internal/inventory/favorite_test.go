@@ -0,0 +1,58 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceFavoritesValidation(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewService(inventory.NewMockDB(gomock.NewController(t)))
+	if err := s.AddFavorite(context.Background(), inventory.FavoriteParams{ProductID: "product"}); err == nil || err.Error() != "missing user ID" {
+		t.Errorf("Service.AddFavorite() error = %v, want missing user ID", err)
+	}
+	if err := s.RemoveFavorite(context.Background(), inventory.FavoriteParams{UserID: "user"}); err == nil || err.Error() != "missing product ID" {
+		t.Errorf("Service.RemoveFavorite() error = %v, want missing product ID", err)
+	}
+	if _, err := s.ListFavorites(context.Background(), inventory.ListFavoritesParams{UserID: "user"}); err == nil || err.Error() != "pagination limit must be at least 1" {
+		t.Errorf("Service.ListFavorites() error = %v, want pagination error", err)
+	}
+	if _, err := s.GetProductStats(context.Background(), ""); err == nil || err.Error() != "missing product ID" {
+		t.Errorf("Service.GetProductStats() error = %v, want missing product ID", err)
+	}
+
+	s.SetReadOnly(true)
+	if err := s.AddFavorite(context.Background(), inventory.FavoriteParams{UserID: "user", ProductID: "product"}); err != inventory.ErrReadOnly {
+		t.Errorf("Service.AddFavorite() error = %v, want %v", err, inventory.ErrReadOnly)
+	}
+}
+
+func TestServiceAddFavorite(t *testing.T) {
+	t.Parallel()
+	var service = serviceWithPostgres(t)
+	if service == nil {
+		t.Skip("required database not found, skipping test")
+	}
+	createProducts(t, service, []inventory.CreateProductParams{
+		{
+			ID:          "product",
+			Name:        "A product name",
+			Description: "A great description",
+			Price:       10000,
+		},
+	})
+	params := inventory.FavoriteParams{UserID: "user", ProductID: "product"}
+	if err := service.AddFavorite(context.Background(), params); err != nil {
+		t.Fatalf("Service.AddFavorite() error = %v", err)
+	}
+	got, err := service.ListFavorites(context.Background(), inventory.ListFavoritesParams{
+		UserID:     "user",
+		Pagination: inventory.Pagination{Limit: 10},
+	})
+	if err != nil || got.Total != 1 || len(got.Favorites) != 1 || got.Favorites[0].ProductID != "product" {
+		t.Errorf("Service.ListFavorites() = %+v, %v, want the favorite product", got, err)
+	}
+}
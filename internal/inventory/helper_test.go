@@ -14,7 +14,7 @@ import (
 
 func createProducts(t testing.TB, s *inventory.Service, products []inventory.CreateProductParams) {
 	for _, p := range products {
-		if err := s.CreateProduct(context.Background(), p); err != nil {
+		if _, err := s.CreateProduct(context.Background(), p); err != nil {
 			t.Errorf("Service.CreateProduct() error = %v", err)
 		}
 	}
@@ -0,0 +1,43 @@
+package inventory
+
+import (
+	"context"
+	"time"
+)
+
+// Version of a product or review recorded on its history.
+type Version struct {
+	// Operation that created the version: INSERT, UPDATE, or DELETE.
+	Operation string
+	ChangedAt time.Time
+
+	// Data of the record after the operation, or nil if it was deleted.
+	Data map[string]any
+
+	// Changes from the previous version, sorted by field.
+	Changes []Change
+}
+
+// Change of a field between two versions.
+// Old is nil for a field set for the first time, and New is nil for a field that was removed.
+type Change struct {
+	Field string
+	Old   any
+	New   any
+}
+
+// ProductHistory returns the versions of a product, from the oldest to the newest.
+func (s *Service) ProductHistory(ctx context.Context, id string) ([]Version, error) {
+	if id == "" {
+		return nil, ValidationError{"missing product ID"}
+	}
+	return s.products.ProductHistory(ctx, id)
+}
+
+// ReviewHistory returns the versions of a product review, from the oldest to the newest.
+func (s *Service) ReviewHistory(ctx context.Context, id string) ([]Version, error) {
+	if id == "" {
+		return nil, ValidationError{"missing review ID"}
+	}
+	return s.reviews.ReviewHistory(ctx, id)
+}
@@ -0,0 +1,80 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceProductHistory(t *testing.T) {
+	t.Parallel()
+	versions := []inventory.Version{
+		{
+			Operation: "INSERT",
+			Data:      map[string]any{"id": "product"},
+			Changes:   []inventory.Change{{Field: "id", New: "product"}},
+		},
+	}
+	tests := []struct {
+		name    string
+		id      string
+		mock    func(t testing.TB) *inventory.MockDB
+		want    []inventory.Version
+		wantErr string
+	}{
+		{
+			name: "missing_product_id",
+			mock: func(t testing.TB) *inventory.MockDB {
+				return inventory.NewMockDB(gomock.NewController(t))
+			},
+			wantErr: "missing product ID",
+		},
+		{
+			name: "history",
+			id:   "product",
+			mock: func(t testing.TB) *inventory.MockDB {
+				m := inventory.NewMockDB(gomock.NewController(t))
+				m.EXPECT().ProductHistory(gomock.Not(gomock.Nil()), "product").Return(versions, nil)
+				return m
+			},
+			want: versions,
+		},
+		{
+			name: "database_error",
+			id:   "product",
+			mock: func(t testing.TB) *inventory.MockDB {
+				m := inventory.NewMockDB(gomock.NewController(t))
+				m.EXPECT().ProductHistory(gomock.Not(gomock.Nil()), "product").Return(nil, errors.New("unexpected error"))
+				return m
+			},
+			wantErr: "unexpected error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := inventory.NewService(tt.mock(t))
+			got, err := s.ProductHistory(context.Background(), tt.id)
+			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
+				t.Errorf("Service.ProductHistory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("value returned by Service.ProductHistory() doesn't match: %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestServiceReviewHistory(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewServiceWithRepositories(nil, nil)
+	if _, err := s.ReviewHistory(context.Background(), ""); err == nil || err.Error() != "missing review ID" {
+		t.Errorf("Service.ReviewHistory() error = %v, wanted missing review ID", err)
+	}
+	if _, err := s.ReviewHistory(context.Background(), "review"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Service.ReviewHistory() error = %v, wanted errors.ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,151 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// maxFallbackLocales is the maximum number of locales tried when localizing a product,
+// so a long Accept-Language header doesn't make for an expensive query.
+const maxFallbackLocales = 20
+
+// ProductTranslation of the name and description of a product to a locale.
+type ProductTranslation struct {
+	ProductID   string
+	Locale      string
+	Name        string
+	Description string
+}
+
+func (p *ProductTranslation) validate() error {
+	if p.ProductID == "" {
+		return ValidationError{"missing product ID"}
+	}
+	locale, ok := normalizeLocale(p.Locale)
+	if !ok {
+		return ValidationError{"invalid locale"}
+	}
+	p.Locale = locale
+	p.Name = normalizeText(p.Name)
+	p.Description = normalizeMultilineText(p.Description)
+	if p.Name == "" {
+		return ValidationError{"missing product name"}
+	}
+	if p.Description == "" {
+		return ValidationError{"missing product description"}
+	}
+	return nil
+}
+
+// normalizeLocale returns a BCP 47 language tag, such as pt-BR or zh-Hant-TW, in its canonical case.
+// It returns false if the locale isn't well-formed.
+func normalizeLocale(locale string) (string, bool) {
+	subtags := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	for i, st := range subtags {
+		if len(st) == 0 || len(st) > 8 || strings.IndexFunc(st, func(r rune) bool {
+			return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+		}) != -1 {
+			return "", false
+		}
+		switch {
+		case i == 0:
+			if len(st) < 2 || len(st) > 3 || strings.IndexFunc(st, func(r rune) bool { return r >= '0' && r <= '9' }) != -1 {
+				return "", false
+			}
+			subtags[i] = strings.ToLower(st)
+		case len(st) == 2: // region
+			subtags[i] = strings.ToUpper(st)
+		case len(st) == 4: // script
+			subtags[i] = strings.ToUpper(st[:1]) + strings.ToLower(st[1:])
+		default:
+			subtags[i] = strings.ToLower(st)
+		}
+	}
+	return strings.Join(subtags, "-"), true
+}
+
+// fallbackLocales returns the locales to try, in order of preference, when localizing content.
+// Each locale is followed by its less specific forms, so pt-BR falls back to pt before the next locale.
+// Invalid locales are ignored, as they're often taken from an Accept-Language header.
+func fallbackLocales(locales []string) []string {
+	var chain []string
+	for _, l := range locales {
+		l, ok := normalizeLocale(l)
+		if !ok {
+			continue
+		}
+		for {
+			if !slices.Contains(chain, l) {
+				if len(chain) == maxFallbackLocales {
+					return chain
+				}
+				chain = append(chain, l)
+			}
+			i := strings.LastIndexByte(l, '-')
+			if i == -1 {
+				break
+			}
+			l = l[:i]
+		}
+	}
+	return chain
+}
+
+// ErrTranslationNoProduct is returned when a translation cannot be saved because the product is not found.
+var ErrTranslationNoProduct = errors.New("cannot find product to translate")
+
+// UpsertProductTranslation creates or replaces the translation of a product to a locale.
+func (s *Service) UpsertProductTranslation(ctx context.Context, params ProductTranslation) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+	return s.products.UpsertProductTranslation(ctx, params)
+}
+
+// DeleteProductTranslation deletes the translation of a product to a locale.
+func (s *Service) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if productID == "" {
+		return ValidationError{"missing product ID"}
+	}
+	locale, ok := normalizeLocale(locale)
+	if !ok {
+		return ValidationError{"invalid locale"}
+	}
+	return s.products.DeleteProductTranslation(ctx, productID, locale)
+}
+
+// GetLocalizedProduct returns a product with its name and description translated to the first of the locales,
+// in order of preference, that it has a translation for.
+// Locales fall back to their less specific forms, such as pt-BR to pt, and then to the untranslated product.
+// Product.Locale is set to the locale of the translation used, if any.
+func (s *Service) GetLocalizedProduct(ctx context.Context, id string, locales []string) (*Product, error) {
+	if id == "" {
+		return nil, ValidationError{"missing product ID"}
+	}
+	product, err := s.products.GetProduct(ctx, id)
+	if err == nil {
+		product, err = s.withPriceBreakdown(ctx, product)
+	}
+	chain := fallbackLocales(locales)
+	if err != nil || product == nil || len(chain) == 0 {
+		return product, err
+	}
+	translation, err := s.products.GetProductTranslation(ctx, id, chain)
+	if err != nil {
+		return nil, err
+	}
+	if translation != nil {
+		product.Name = translation.Name
+		product.Description = translation.Description
+		product.Locale = translation.Locale
+	}
+	return product, nil
+}
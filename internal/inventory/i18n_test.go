@@ -0,0 +1,122 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceGetLocalizedProduct(t *testing.T) {
+	t.Parallel()
+	product := func() *inventory.Product {
+		return &inventory.Product{
+			ID:          "chair",
+			Name:        "Chair",
+			Description: "A chair",
+			Price:       80,
+		}
+	}
+	tests := []struct {
+		name        string
+		locales     []string
+		wantLocales []string
+		translation *inventory.ProductTranslation
+		want        *inventory.Product
+	}{
+		{
+			name:        "translated",
+			locales:     []string{"pt-br", "en;", "ES_419", "zh-hant-tw"},
+			wantLocales: []string{"pt-BR", "pt", "es-419", "es", "zh-Hant-TW", "zh-Hant", "zh"},
+			translation: &inventory.ProductTranslation{ProductID: "chair", Locale: "pt", Name: "Cadeira", Description: "Uma cadeira"},
+			want: &inventory.Product{
+				ID:          "chair",
+				Name:        "Cadeira",
+				Description: "Uma cadeira",
+				Price:       80,
+				Locale:      "pt",
+			},
+		},
+		{
+			name:        "not_translated",
+			locales:     []string{"fr-CA", "fr"},
+			wantLocales: []string{"fr-CA", "fr"},
+			want:        product(),
+		},
+		{
+			name:    "no_valid_locale",
+			locales: []string{"*", "1"},
+			want:    product(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := inventory.NewMockDB(gomock.NewController(t))
+			m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "chair").Return(product(), nil)
+			if tt.wantLocales != nil {
+				m.EXPECT().GetProductTranslation(gomock.Not(gomock.Nil()), "chair", tt.wantLocales).Return(tt.translation, nil)
+			}
+			s := inventory.NewService(m)
+			got, err := s.GetLocalizedProduct(context.Background(), "chair", tt.locales)
+			if err != nil {
+				t.Fatalf("Service.GetLocalizedProduct() error = %v", err)
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("value returned by Service.GetLocalizedProduct() doesn't match: %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestServiceUpsertProductTranslation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		params  inventory.ProductTranslation
+		want    inventory.ProductTranslation
+		wantErr string
+	}{
+		{
+			name:   "success",
+			params: inventory.ProductTranslation{ProductID: "chair", Locale: "PT_br", Name: "Cadeira", Description: "Uma cadeira"},
+			want:   inventory.ProductTranslation{ProductID: "chair", Locale: "pt-BR", Name: "Cadeira", Description: "Uma cadeira"},
+		},
+		{
+			name:    "missing_product_id",
+			params:  inventory.ProductTranslation{Locale: "pt", Name: "Cadeira", Description: "Uma cadeira"},
+			wantErr: "missing product ID",
+		},
+		{
+			name:    "invalid_locale",
+			params:  inventory.ProductTranslation{ProductID: "chair", Locale: "portuguese", Name: "Cadeira", Description: "Uma cadeira"},
+			wantErr: "invalid locale",
+		},
+		{
+			name:    "missing_name",
+			params:  inventory.ProductTranslation{ProductID: "chair", Locale: "pt", Description: "Uma cadeira"},
+			wantErr: "missing product name",
+		},
+		{
+			name:    "missing_description",
+			params:  inventory.ProductTranslation{ProductID: "chair", Locale: "pt", Name: "Cadeira"},
+			wantErr: "missing product description",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := inventory.NewMockDB(gomock.NewController(t))
+			if tt.wantErr == "" {
+				m.EXPECT().UpsertProductTranslation(gomock.Not(gomock.Nil()), tt.want).Return(nil)
+			}
+			s := inventory.NewService(m)
+			err := s.UpsertProductTranslation(context.Background(), tt.params)
+			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
+				t.Errorf("Service.UpsertProductTranslation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
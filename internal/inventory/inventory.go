@@ -2,6 +2,7 @@ package inventory
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -11,8 +12,58 @@ type Product struct {
 	Name        string
 	Description string
 	Price       int
+	Status      ProductStatus
 	CreatedAt   time.Time
 	ModifiedAt  time.Time
+
+	// Slug is the URL-safe form of the name, unique across products.
+	// It's generated when the product is created, and changes when it's renamed.
+	Slug string
+
+	// SKU (stock keeping unit) and GTIN (barcode number, such as an EAN-13) are optional and unique across products.
+	SKU  string
+	GTIN string
+
+	// TaxClass of the product, or empty for the default tax class.
+	TaxClass string
+
+	// PriceBreakdown of the price into its net value and taxes, if a TaxCalculator is set.
+	// It's only set when getting a single product, such as by GetProduct.
+	PriceBreakdown *PriceBreakdown
+
+	// Available units in stock across all warehouses, excluding the ones reserved by pending orders.
+	// It's only set when getting a single product, such as by GetProduct.
+	Available int
+
+	// Locale of the name and description, if translated by GetLocalizedProduct.
+	Locale string
+
+	// ReviewSummary of the reviews of the product, if one was generated.
+	// It's only set when getting a single product, such as by GetProduct.
+	ReviewSummary *ReviewSummary
+
+	// Rating is the average score of the reviews of the product, or zero if it has none.
+	// It's only set on lists of products with the ProductViewSummary view.
+	Rating float64
+}
+
+// ProductStatus is the lifecycle status of a product.
+type ProductStatus string
+
+// Product status values.
+const (
+	ProductStatusDraft        ProductStatus = "draft"
+	ProductStatusActive       ProductStatus = "active"
+	ProductStatusDiscontinued ProductStatus = "discontinued"
+)
+
+// Valid returns whether the product status is known.
+func (s ProductStatus) Valid() bool {
+	switch s {
+	case ProductStatusDraft, ProductStatusActive, ProductStatusDiscontinued:
+		return true
+	}
+	return false
 }
 
 // CreateProductParams used by CreateProduct.
@@ -21,12 +72,32 @@ type CreateProductParams struct {
 	Name        string
 	Description string
 	Price       int
+
+	// Status of the product. Defaults to ProductStatusActive.
+	Status ProductStatus
+
+	// SKU and GTIN of the product, if any.
+	SKU  string
+	GTIN string
+
+	// TaxClass of the product. Defaults to the default tax class.
+	TaxClass string
+
+	// RejectDuplicates fails with a *DuplicateProductError if other products of the tenant have a similar name,
+	// as found by FindSimilarProducts with the DefaultSimilarityThreshold.
+	// It's a best-effort check: a similar product created concurrently isn't detected.
+	RejectDuplicates bool
 }
 
 func (p *CreateProductParams) validate() error {
 	if p.ID == "" {
 		return ValidationError{"missing product ID"}
 	}
+	if _, _, err := ParseID(p.ID); err != nil {
+		return err
+	}
+	p.Name = normalizeText(p.Name)
+	p.Description = normalizeMultilineText(p.Description)
 	if p.Name == "" {
 		return ValidationError{"missing product name"}
 	}
@@ -36,15 +107,49 @@ func (p *CreateProductParams) validate() error {
 	if p.Price < 0 {
 		return ValidationError{"price cannot be negative"}
 	}
-	return nil
+	if p.Status != "" && !p.Status.Valid() {
+		return ValidationError{"invalid product status"}
+	}
+	if p.SKU != "" {
+		if err := validateSKU(p.SKU); err != nil {
+			return err
+		}
+	}
+	if p.GTIN != "" {
+		if err := validateGTIN(p.GTIN); err != nil {
+			return err
+		}
+	}
+	return validateTaxClass(p.TaxClass)
+}
+
+// CreateProductResult of CreateProduct.
+type CreateProductResult struct {
+	// Product that was created, or the existing product if one with the same ID already exists.
+	Product *Product
+
+	// Created is false if a product with the same ID already exists.
+	Created bool
 }
 
 // CreateProduct creates a new product.
-func (s *Service) CreateProduct(ctx context.Context, params CreateProductParams) (err error) {
+// If a product with the same ID already exists, it is returned unmodified with Created set to false.
+func (s *Service) CreateProduct(ctx context.Context, params CreateProductParams) (*CreateProductResult, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
 	if err := params.validate(); err != nil {
-		return err
+		return nil, err
+	}
+	if err := s.checkTaxClass(ctx, params.TaxClass); err != nil {
+		return nil, err
+	}
+	if params.RejectDuplicates {
+		if err := s.checkDuplicates(ctx, params); err != nil {
+			return nil, err
+		}
 	}
-	return s.db.CreateProduct(ctx, params)
+	return s.products.CreateProduct(ctx, params)
 }
 
 // UpdateProductParams used by UpdateProduct.
@@ -53,15 +158,25 @@ type UpdateProductParams struct {
 	Name        *string
 	Description *string
 	Price       *int
+	Status      *ProductStatus
+
+	// SKU and GTIN replace the existing ones. An empty string removes them.
+	SKU  *string
+	GTIN *string
+
+	// TaxClass replaces the existing one. An empty string sets the default tax class.
+	TaxClass *string
 }
 
 func (p *UpdateProductParams) validate() error {
 	if p.ID == "" {
 		return ValidationError{"missing product ID"}
 	}
-	if p.Name == nil && p.Description == nil && p.Price == nil {
+	if p.Name == nil && p.Description == nil && p.Price == nil && p.Status == nil && p.SKU == nil && p.GTIN == nil && p.TaxClass == nil {
 		return ValidationError{"no product arguments to update"}
 	}
+	p.Name = normalizeOptional(p.Name, normalizeText)
+	p.Description = normalizeOptional(p.Description, normalizeMultilineText)
 	if p.Name != nil && *p.Name == "" {
 		return ValidationError{"missing product name"}
 	}
@@ -71,23 +186,69 @@ func (p *UpdateProductParams) validate() error {
 	if p.Price != nil && *p.Price < 0 {
 		return ValidationError{"price cannot be negative"}
 	}
+	if p.Status != nil && !p.Status.Valid() {
+		return ValidationError{"invalid product status"}
+	}
+	if p.SKU != nil && *p.SKU != "" {
+		if err := validateSKU(*p.SKU); err != nil {
+			return err
+		}
+	}
+	if p.GTIN != nil && *p.GTIN != "" {
+		if err := validateGTIN(*p.GTIN); err != nil {
+			return err
+		}
+	}
+	if p.TaxClass != nil {
+		return validateTaxClass(*p.TaxClass)
+	}
 	return nil
 }
 
-// UpdateProduct creates a new product.
-func (s *Service) UpdateProduct(ctx context.Context, params UpdateProductParams) (err error) {
+// UpdateProduct updates an existing product.
+func (s *Service) UpdateProduct(ctx context.Context, params UpdateProductParams) (*Product, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
 	if err := params.validate(); err != nil {
-		return err
+		return nil, err
 	}
-	return s.db.UpdateProduct(ctx, params)
+	if params.TaxClass != nil {
+		if err := s.checkTaxClass(ctx, *params.TaxClass); err != nil {
+			return nil, err
+		}
+	}
+	return s.products.UpdateProduct(ctx, params)
+}
+
+// DeleteProductParams used by DeleteProduct.
+type DeleteProductParams struct {
+	ID string
+
+	// Force deletion of the product along with its reviews.
+	// Otherwise, a product with reviews isn't deleted, and a *HasDependentsError is returned.
+	Force bool
+}
+
+// HasDependentsError is returned when a product cannot be deleted because other records depend on it.
+type HasDependentsError struct {
+	// Reviews of the product.
+	Reviews int
+}
+
+func (e *HasDependentsError) Error() string {
+	return fmt.Sprintf("product has %d reviews", e.Reviews)
 }
 
 // DeleteProduct deletes a product.
-func (s *Service) DeleteProduct(ctx context.Context, id string) (err error) {
-	if id == "" {
+func (s *Service) DeleteProduct(ctx context.Context, params DeleteProductParams) (err error) {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if params.ID == "" {
 		return ValidationError{"missing product ID"}
 	}
-	return s.db.DeleteProduct(ctx, id)
+	return s.products.DeleteProduct(ctx, params)
 }
 
 // GetProduct returns a product.
@@ -95,7 +256,35 @@ func (s *Service) GetProduct(ctx context.Context, id string) (*Product, error) {
 	if id == "" {
 		return nil, ValidationError{"missing product ID"}
 	}
-	return s.db.GetProduct(ctx, id)
+	p, err := s.products.GetProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withPriceBreakdown(ctx, p)
+}
+
+// GetProductBySlug returns a product by its current or a previous slug, or nil if it's not found.
+// A product found by a previous slug has a different Slug, so callers can redirect to the current one.
+func (s *Service) GetProductBySlug(ctx context.Context, slug string) (*Product, error) {
+	if slug == "" {
+		return nil, ValidationError{"missing product slug"}
+	}
+	p, err := s.products.GetProductBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return s.withPriceBreakdown(ctx, p)
+}
+
+// GetProductAt returns the product as it was at the given time, or nil if it didn't exist then.
+func (s *Service) GetProductAt(ctx context.Context, id string, at time.Time) (*Product, error) {
+	if id == "" {
+		return nil, ValidationError{"missing product ID"}
+	}
+	if at.IsZero() {
+		return nil, ValidationError{"missing time"}
+	}
+	return s.products.GetProductAt(ctx, id, at)
 }
 
 // SearchProductsParams used by SearchProducts.
@@ -104,18 +293,91 @@ type SearchProductsParams struct {
 	MinPrice    int
 	MaxPrice    int
 	Pagination  Pagination
+
+	// SKUPrefix filters products by the beginning of their SKU.
+	// Either it, QueryString, or Text is required.
+	SKUPrefix string
+
+	// Text searches the words of the name and description of products, regardless of case, in the web search syntax:
+	// words are all required unless separated by "or", "quoted phrases" match in order, and -words are excluded.
+	// Unlike QueryString, it matches whole words rather than parts of them.
+	Text string
+
+	// CountPriceBands counts the products matching the search by price band on the response.
+	CountPriceBands bool
+
+	// EstimateTotal estimates the Total from the statistics of the database rather than counting the products,
+	// for listings showing only an approximate number of results, such as "about 12,000 products".
+	// Small totals are counted anyway, as counting them is cheap, and their estimates are the least accurate.
+	// It's ignored with CountPriceBands, which counts the products.
+	EstimateTotal bool
+
+	// OrderBy sorts the products (default: ProductsByNewest).
+	OrderBy ProductOrder
+
+	// Locales whose alphabetical order ProductsByName follows, in order of preference, such as from an Accept-Language header.
+	// The first one the database has a collation for is used, or its default collation if none.
+	Locales []string
+
+	// View of the products found (default: ProductViewFull).
+	View ProductView
+}
+
+// ProductOrder of a list of products.
+type ProductOrder string
+
+// Product orders.
+const (
+	ProductsByNewest ProductOrder = "newest"
+	ProductsByName   ProductOrder = "name"
+)
+
+// ProductView is the representation of the products of a list, such as the products found by SearchProducts.
+type ProductView string
+
+// Product views.
+const (
+	// ProductViewFull has every field of the products, except the ones only set when getting a single product.
+	ProductViewFull ProductView = "full"
+
+	// ProductViewSummary has only the ID, Name, Price, and Rating of the products, for listings showing many of them,
+	// and is lighter to read from the database.
+	ProductViewSummary ProductView = "summary"
+)
+
+func (v ProductView) validate() error {
+	switch v {
+	case "", ProductViewFull, ProductViewSummary:
+		return nil
+	}
+	return ValidationError{"invalid product view"}
 }
 
 func (p *SearchProductsParams) validate() error {
-	if p.QueryString == "" {
+	p.Text = normalizeText(p.Text)
+	if p.QueryString == "" && p.SKUPrefix == "" && p.Text == "" {
 		return ValidationError{"missing search string"}
 	}
+	if p.SKUPrefix != "" && (len(p.SKUPrefix) > MaxSKULength || !validSKUChars(p.SKUPrefix)) {
+		return ValidationError{"invalid SKU prefix"}
+	}
 	if p.MinPrice < 0 {
 		return ValidationError{"min price cannot be negative"}
 	}
 	if p.MaxPrice < 0 {
 		return ValidationError{"max price cannot be negative"}
 	}
+	switch p.OrderBy {
+	case "", ProductsByNewest:
+		p.Locales = nil // Only used to sort by name.
+	case ProductsByName:
+		p.Locales = fallbackLocales(p.Locales)
+	default:
+		return ValidationError{"invalid product order"}
+	}
+	if err := p.View.validate(); err != nil {
+		return err
+	}
 	return p.Pagination.Validate()
 }
 
@@ -123,6 +385,12 @@ func (p *SearchProductsParams) validate() error {
 type SearchProductsResponse struct {
 	Items []*Product
 	Total int
+
+	// TotalIsEstimate is set when the Total is an estimate, as requested with EstimateTotal.
+	TotalIsEstimate bool
+
+	// PriceBands with products matching the search, from the cheapest, if requested with CountPriceBands.
+	PriceBands []PriceBand
 }
 
 // SearchProducts returns a list of products.
@@ -130,5 +398,5 @@ func (s *Service) SearchProducts(ctx context.Context, params SearchProductsParam
 	if err := params.validate(); err != nil {
 		return nil, err
 	}
-	return s.db.SearchProducts(ctx, params)
+	return s.products.SearchProducts(ctx, params)
 }
@@ -63,8 +63,10 @@ func TestServiceCreateProduct(t *testing.T) {
 				Name:        "product name",
 				Description: "product description",
 				Price:       150,
+				Status:      inventory.ProductStatusActive,
 				CreatedAt:   time.Now(),
 				ModifiedAt:  time.Now(),
+				Slug:        "product-name",
 			},
 			wantErr: "",
 		},
@@ -106,6 +108,20 @@ func TestServiceCreateProduct(t *testing.T) {
 			},
 			wantErr: "price cannot be negative",
 		},
+		{
+			name: "invalid_status",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.CreateProductParams{
+					ID:          "invalid_status",
+					Name:        "product name",
+					Description: "product description",
+					Price:       10,
+					Status:      "unknown",
+				},
+			},
+			wantErr: "invalid product status",
+		},
 		{
 			name: "canceled_ctx",
 			args: args{
@@ -130,7 +146,7 @@ func TestServiceCreateProduct(t *testing.T) {
 						Name:        "product name",
 						Description: "product description",
 						Price:       150,
-					}).Return(errors.New("unexpected error"))
+					}).Return(nil, errors.New("unexpected error"))
 				return m
 			},
 			args: args{
@@ -155,7 +171,7 @@ func TestServiceCreateProduct(t *testing.T) {
 			} else if s == nil {
 				t.Skip("required database not found, skipping test")
 			}
-			err := s.CreateProduct(tt.args.ctx, tt.args.params)
+			_, err := s.CreateProduct(tt.args.ctx, tt.args.params)
 			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
 				t.Errorf("Service.CreateProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -255,6 +271,17 @@ func TestServiceUpdateProduct(t *testing.T) {
 			},
 			wantErr: "price cannot be negative",
 		},
+		{
+			name: "invalid_status",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.UpdateProductParams{
+					ID:     "invalid_status",
+					Status: ptr(inventory.ProductStatus("unknown")),
+				},
+			},
+			wantErr: "invalid product status",
+		},
 		{
 			name: "product_name_change",
 			args: args{
@@ -269,6 +296,8 @@ func TestServiceUpdateProduct(t *testing.T) {
 				Name:        "A new name",
 				Description: "This is the original description",
 				Price:       250,
+				Status:      inventory.ProductStatusActive,
+				Slug:        "a-new-name",
 			},
 		},
 		{
@@ -285,6 +314,8 @@ func TestServiceUpdateProduct(t *testing.T) {
 				Name:        "A new name",
 				Description: "A new description",
 				Price:       250,
+				Status:      inventory.ProductStatusActive,
+				Slug:        "a-new-name",
 			},
 		},
 		{
@@ -303,6 +334,8 @@ func TestServiceUpdateProduct(t *testing.T) {
 				Name:        "Even another name",
 				Description: "yet another description",
 				Price:       400,
+				Status:      inventory.ProductStatusActive,
+				Slug:        "even-another-name",
 			},
 		},
 		{
@@ -363,6 +396,8 @@ func TestServiceUpdateProduct(t *testing.T) {
 				Name:        "Is your SQL UPDATE call correct?",
 				Description: "Only the price of this one should be modified",
 				Price:       97,
+				Status:      inventory.ProductStatusActive,
+				Slug:        "is-your-sql-update-call-correct",
 			},
 		},
 		{
@@ -386,7 +421,7 @@ func TestServiceUpdateProduct(t *testing.T) {
 						Name:        ptr("product name"),
 						Description: ptr("product description"),
 						Price:       ptr(150),
-					}).Return(errors.New("unexpected error"))
+					}).Return(nil, errors.New("unexpected error"))
 				return m
 			},
 			args: args{
@@ -411,7 +446,7 @@ func TestServiceUpdateProduct(t *testing.T) {
 			} else if s == nil {
 				t.Skip("required database not found, skipping test")
 			}
-			err := s.UpdateProduct(tt.args.ctx, tt.args.params)
+			updated, err := s.UpdateProduct(tt.args.ctx, tt.args.params)
 			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
 				t.Errorf("Service.UpdateProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -422,6 +457,9 @@ func TestServiceUpdateProduct(t *testing.T) {
 			if err != nil {
 				t.Errorf("Service.GetProduct() error = %v", err)
 			}
+			if !cmp.Equal(updated, got) {
+				t.Errorf("value returned by Service.UpdateProduct() doesn't match: %v", cmp.Diff(updated, got))
+			}
 			if got.CreatedAt.IsZero() {
 				t.Error("Service.GetProduct() returned CreatedAt should not be zero")
 			}
@@ -449,8 +487,8 @@ func TestServiceDeleteProduct(t *testing.T) {
 	})
 
 	type args struct {
-		ctx context.Context
-		id  string
+		ctx    context.Context
+		params inventory.DeleteProductParams
 	}
 	tests := []struct {
 		name    string
@@ -461,16 +499,16 @@ func TestServiceDeleteProduct(t *testing.T) {
 		{
 			name: "missing_product_id",
 			args: args{
-				ctx: context.Background(),
-				id:  "",
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: ""},
 			},
 			wantErr: "missing product ID",
 		},
 		{
 			name: "product",
 			args: args{
-				ctx: context.Background(),
-				id:  "product",
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: "product"},
 			},
 			wantErr: "",
 		},
@@ -478,8 +516,8 @@ func TestServiceDeleteProduct(t *testing.T) {
 		{
 			name: "product_already_deleted",
 			args: args{
-				ctx: context.Background(),
-				id:  "product",
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: "product"},
 			},
 			wantErr: "",
 		},
@@ -487,36 +525,36 @@ func TestServiceDeleteProduct(t *testing.T) {
 		{
 			name: "not_found",
 			args: args{
-				ctx: context.Background(),
-				id:  "xyz",
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: "xyz"},
 			},
 		},
 		{
 			name: "canceled_ctx",
 			args: args{
-				ctx: canceledContext(),
-				id:  "product",
+				ctx:    canceledContext(),
+				params: inventory.DeleteProductParams{ID: "product"},
 			},
 			wantErr: "context canceled",
 		},
 		{
 			name: "deadline_exceeded_ctx",
 			args: args{
-				ctx: deadlineExceededContext(),
-				id:  "product",
+				ctx:    deadlineExceededContext(),
+				params: inventory.DeleteProductParams{ID: "product"},
 			},
 			wantErr: "context deadline exceeded",
 		},
 		{
 			name: "database_error",
 			args: args{
-				ctx: context.Background(),
-				id:  "product",
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: "product"},
 			},
 			mock: func(t testing.TB) *inventory.MockDB {
 				ctrl := gomock.NewController(t)
 				m := inventory.NewMockDB(ctrl)
-				m.EXPECT().DeleteProduct(gomock.Not(gomock.Nil()), "product").Return(errors.New("unexpected error"))
+				m.EXPECT().DeleteProduct(gomock.Not(gomock.Nil()), inventory.DeleteProductParams{ID: "product"}).Return(errors.New("unexpected error"))
 				return m
 			},
 			wantErr: "unexpected error",
@@ -532,7 +570,7 @@ func TestServiceDeleteProduct(t *testing.T) {
 			} else if s == nil {
 				t.Skip("required database not found, skipping test")
 			}
-			if err := s.DeleteProduct(tt.args.ctx, tt.args.id); err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
+			if err := s.DeleteProduct(tt.args.ctx, tt.args.params); err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
 				t.Errorf("Service.DeleteProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -581,8 +619,10 @@ func TestServiceGetProduct(t *testing.T) {
 				Name:        "A product name",
 				Description: "A great description",
 				Price:       10000,
+				Status:      inventory.ProductStatusActive,
 				CreatedAt:   time.Now(),
 				ModifiedAt:  time.Now(),
+				Slug:        "a-product-name",
 			},
 		},
 		{
@@ -648,6 +688,129 @@ func TestServiceGetProduct(t *testing.T) {
 	}
 }
 
+func TestServiceGetProductBySlug(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	want := &inventory.Product{ID: "product", Name: "A product name", Slug: "a-product-name"}
+	m.EXPECT().GetProductBySlug(gomock.Not(gomock.Nil()), "a-product-name").Return(want, nil)
+	s := inventory.NewService(m)
+
+	if _, err := s.GetProductBySlug(context.Background(), ""); err == nil || err.Error() != "missing product slug" {
+		t.Errorf("Service.GetProductBySlug() error = %v, want missing product slug", err)
+	}
+	got, err := s.GetProductBySlug(context.Background(), "a-product-name")
+	if err != nil || got != want {
+		t.Errorf("Service.GetProductBySlug() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestServiceGetProductAt(t *testing.T) {
+	t.Parallel()
+	var service = serviceWithPostgres(t)
+	createProducts(t, service, []inventory.CreateProductParams{
+		{
+			ID:          "product",
+			Name:        "A product name",
+			Description: "A great description",
+			Price:       10000,
+		},
+	})
+
+	type args struct {
+		ctx context.Context
+		id  string
+		at  time.Time
+	}
+	tests := []struct {
+		name    string
+		args    args
+		mock    func(t testing.TB) *inventory.MockDB // Leave as nil for using a real database implementation.
+		want    *inventory.Product
+		wantErr string
+	}{
+		{
+			name: "missing_product_id",
+			args: args{
+				ctx: context.Background(),
+				at:  time.Now(),
+			},
+			wantErr: "missing product ID",
+		},
+		{
+			name: "missing_time",
+			args: args{
+				ctx: context.Background(),
+				id:  "product",
+			},
+			wantErr: "missing time",
+		},
+		{
+			name: "product",
+			args: args{
+				ctx: context.Background(),
+				id:  "product",
+				at:  time.Now().Add(time.Minute),
+			},
+			want: &inventory.Product{
+				ID:          "product",
+				Name:        "A product name",
+				Description: "A great description",
+				Price:       10000,
+				Status:      inventory.ProductStatusActive,
+				CreatedAt:   time.Now(),
+				ModifiedAt:  time.Now(),
+				Slug:        "a-product-name",
+			},
+		},
+		{
+			name: "before_created",
+			args: args{
+				ctx: context.Background(),
+				id:  "product",
+				at:  time.Now().Add(-time.Hour),
+			},
+			want: nil,
+		},
+		{
+			name: "database_error",
+			args: args{
+				ctx: context.Background(),
+				id:  "product",
+				at:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			mock: func(t testing.TB) *inventory.MockDB {
+				ctrl := gomock.NewController(t)
+				m := inventory.NewMockDB(ctrl)
+				m.EXPECT().GetProductAt(gomock.Not(gomock.Nil()), "product", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).Return(nil, errors.New("unexpected error"))
+				return m
+			},
+			wantErr: "unexpected error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// If tt.mock is nil, use real database implementation if available. Otherwise, skip the test.
+			var s = service
+			if tt.mock != nil {
+				s = inventory.NewService(tt.mock(t))
+			} else if s == nil {
+				t.Skip("required database not found, skipping test")
+			}
+			got, err := s.GetProductAt(tt.args.ctx, tt.args.id, tt.args.at)
+			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
+				t.Errorf("Service.GetProductAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !cmp.Equal(tt.want, got, cmpopts.EquateApproxTime(time.Minute)) {
+				t.Errorf("value returned by Service.GetProductAt() doesn't match: %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
 func TestServiceSearchProducts(t *testing.T) {
 	t.Parallel()
 	var service = serviceWithPostgres(t)
@@ -707,8 +870,10 @@ func TestServiceSearchProducts(t *testing.T) {
 						Name:        "plain desk (home)",
 						Description: "A plain desk",
 						Price:       140,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "plain-desk-home",
 					},
 				},
 				Total: 1,
@@ -728,6 +893,90 @@ func TestServiceSearchProducts(t *testing.T) {
 			},
 			wantErr: "missing search string",
 		},
+		{
+			name: "blank_text",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.SearchProductsParams{
+					Text: " \t ",
+				},
+			},
+			wantErr: "missing search string",
+		},
+		{
+			name: "invalid_order",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.SearchProductsParams{
+					QueryString: "desk",
+					OrderBy:     "price",
+				},
+			},
+			wantErr: "invalid product order",
+		},
+		{
+			name: "by_name",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.SearchProductsParams{
+					QueryString: "e",
+					OrderBy:     inventory.ProductsByName,
+					Locales:     []string{"en-US"},
+					Pagination: inventory.Pagination{
+						Limit: 2,
+					},
+				},
+			},
+			want: &inventory.SearchProductsResponse{
+				Items: []*inventory.Product{
+					{
+						ID:          "bed",
+						Name:        "bed",
+						Description: "small bed",
+						Price:       100,
+						Status:      inventory.ProductStatusActive,
+						CreatedAt:   time.Now(),
+						ModifiedAt:  time.Now(),
+						Slug:        "bed",
+					},
+					{
+						ID:          "table",
+						Name:        "dining home table",
+						Description: "dining table",
+						Price:       120,
+						Status:      inventory.ProductStatusActive,
+						CreatedAt:   time.Now(),
+						ModifiedAt:  time.Now(),
+						Slug:        "dining-home-table",
+					},
+				},
+				Total: 4,
+			},
+		},
+		{
+			name: "by_name_locales",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.SearchProductsParams{
+					QueryString: "desk",
+					OrderBy:     inventory.ProductsByName,
+					Locales:     []string{"pt_br", "not a locale", "en"},
+				},
+			},
+			mock: func(t testing.TB) *inventory.MockDB {
+				ctrl := gomock.NewController(t)
+				m := inventory.NewMockDB(ctrl)
+				m.EXPECT().SearchProducts(gomock.Not(gomock.Nil()), inventory.SearchProductsParams{
+					QueryString: "desk",
+					OrderBy:     inventory.ProductsByName,
+					Locales:     []string{"pt-BR", "pt", "en"},
+				}).Return(&inventory.SearchProductsResponse{Items: []*inventory.Product{}}, nil)
+				return m
+			},
+			want: &inventory.SearchProductsResponse{
+				Items: []*inventory.Product{},
+			},
+		},
 		{
 			name: "negative_min_price",
 			args: args{
@@ -816,16 +1065,20 @@ func TestServiceSearchProducts(t *testing.T) {
 						Name:        "dining home table",
 						Description: "dining table",
 						Price:       120,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "dining-home-table",
 					},
 					{
 						ID:          "desk",
 						Name:        "plain desk (home)",
 						Description: "A plain desk",
 						Price:       140,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "plain-desk-home",
 					},
 				},
 				Total: 2,
@@ -851,8 +1104,10 @@ func TestServiceSearchProducts(t *testing.T) {
 						Name:        "plain desk (home)",
 						Description: "A plain desk",
 						Price:       140,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "plain-desk-home",
 					},
 				},
 				Total: 2,
@@ -878,8 +1133,10 @@ func TestServiceSearchProducts(t *testing.T) {
 						Name:        "dining home table",
 						Description: "dining table",
 						Price:       120,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "dining-home-table",
 					},
 				},
 				Total: 1,
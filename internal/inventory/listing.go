@@ -0,0 +1,141 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TrendingWindow is the default period of views considered by ListTrendingProducts.
+const TrendingWindow = 7 * 24 * time.Hour
+
+// ListTrendingProductsParams used by ListTrendingProducts.
+type ListTrendingProductsParams struct {
+	// Since when views are counted. Defaults to TrendingWindow ago.
+	Since      time.Time
+	Pagination Pagination
+
+	// View of the products listed (default: ProductViewFull).
+	View ProductView
+}
+
+// ListRecentProductsParams used by ListRecentProducts.
+type ListRecentProductsParams struct {
+	Pagination Pagination
+
+	// View of the products listed (default: ProductViewFull).
+	View ProductView
+}
+
+// ListProductsResponse from ListTrendingProducts, ListRecentProducts, and ListRecentlyViewed.
+type ListProductsResponse struct {
+	Items []*Product
+}
+
+// RecordProductView counts a view of a product, used to list trending products.
+func (s *Service) RecordProductView(ctx context.Context, id string) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if id == "" {
+		return ValidationError{"missing product ID"}
+	}
+	return s.products.RecordProductView(ctx, id)
+}
+
+// ListTrendingProducts returns the active products with the most views, the most viewed first.
+func (s *Service) ListTrendingProducts(ctx context.Context, params ListTrendingProductsParams) (*ListProductsResponse, error) {
+	if err := params.Pagination.Validate(); err != nil {
+		return nil, err
+	}
+	if err := params.View.validate(); err != nil {
+		return nil, err
+	}
+	if params.Since.IsZero() {
+		params.Since = time.Now().Add(-TrendingWindow)
+	}
+	return s.products.ListTrendingProducts(ctx, params)
+}
+
+// ListRecentProducts returns the active products, the most recently added first.
+func (s *Service) ListRecentProducts(ctx context.Context, params ListRecentProductsParams) (*ListProductsResponse, error) {
+	if err := params.Pagination.Validate(); err != nil {
+		return nil, err
+	}
+	if err := params.View.validate(); err != nil {
+		return nil, err
+	}
+	return s.products.ListRecentProducts(ctx, params)
+}
+
+// WithListingCache caches the responses of ListTrendingProducts and ListRecentProducts for the given duration,
+// as storefronts call them often and with the same few parameters.
+// Responses are shared between callers, and must not be modified.
+func WithListingCache(ttl time.Duration) ServiceMiddleware {
+	return func(next API) API {
+		return &listingCache{
+			API:     next,
+			ttl:     ttl,
+			entries: map[string]listingCacheEntry{},
+		}
+	}
+}
+
+// maxListingCacheEntries limits the memory used by the listing cache.
+const maxListingCacheEntries = 1000
+
+type listingCache struct {
+	API
+
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]listingCacheEntry
+}
+
+type listingCacheEntry struct {
+	resp    *ListProductsResponse
+	expires time.Time
+}
+
+func (c *listingCache) ListTrendingProducts(ctx context.Context, params ListTrendingProductsParams) (*ListProductsResponse, error) {
+	if !params.Since.IsZero() {
+		return c.API.ListTrendingProducts(ctx, params) // Only the default window is cached.
+	}
+	key := fmt.Sprintf("trending:%d:%d:%s", params.Pagination.Limit, params.Pagination.Offset, params.View)
+	return c.get(key, func() (*ListProductsResponse, error) {
+		return c.API.ListTrendingProducts(ctx, params)
+	})
+}
+
+func (c *listingCache) ListRecentProducts(ctx context.Context, params ListRecentProductsParams) (*ListProductsResponse, error) {
+	key := fmt.Sprintf("recent:%d:%d:%s", params.Pagination.Limit, params.Pagination.Offset, params.View)
+	return c.get(key, func() (*ListProductsResponse, error) {
+		return c.API.ListRecentProducts(ctx, params)
+	})
+}
+
+// get the cached response for key, calling fn and caching its response if it's missing or expired.
+func (c *listingCache) get(key string, fn func() (*ListProductsResponse, error)) (*ListProductsResponse, error) {
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.resp, nil
+	}
+	resp, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxListingCacheEntries {
+		clear(c.entries)
+	}
+	c.entries[key] = listingCacheEntry{
+		resp:    resp,
+		expires: now.Add(c.ttl),
+	}
+	return resp, nil
+}
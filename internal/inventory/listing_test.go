@@ -0,0 +1,103 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceListTrendingProductsDefaultWindow(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	m.EXPECT().ListTrendingProducts(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.ListTrendingProductsParams) (*inventory.ListProductsResponse, error) {
+			if since := time.Since(params.Since); since < inventory.TrendingWindow || since > inventory.TrendingWindow+time.Minute {
+				t.Errorf("ListTrendingProducts() called with views since %v ago, want %v", since, inventory.TrendingWindow)
+			}
+			return &inventory.ListProductsResponse{}, nil
+		})
+	s := inventory.NewService(m)
+	if _, err := s.ListTrendingProducts(context.Background(), inventory.ListTrendingProductsParams{
+		Pagination: inventory.Pagination{Limit: 10},
+	}); err != nil {
+		t.Errorf("Service.ListTrendingProducts() error = %v", err)
+	}
+	if _, err := s.ListTrendingProducts(context.Background(), inventory.ListTrendingProductsParams{}); err == nil || err.Error() != "pagination limit must be at least 1" {
+		t.Errorf("Service.ListTrendingProducts() error = %v, want pagination error", err)
+	}
+}
+
+func TestWithListingCache(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	recent := &inventory.ListProductsResponse{
+		Items: []*inventory.Product{{ID: "product"}},
+	}
+	pagination := inventory.Pagination{Limit: 10}
+	// Called once for each distinct page, as the following calls are served from the cache.
+	m.EXPECT().ListRecentProducts(gomock.Not(gomock.Nil()), inventory.ListRecentProductsParams{Pagination: pagination}).Return(recent, nil).Times(1)
+	m.EXPECT().ListRecentProducts(gomock.Not(gomock.Nil()), inventory.ListRecentProductsParams{Pagination: inventory.Pagination{Limit: 10, Offset: 10}}).Return(&inventory.ListProductsResponse{}, nil).Times(1)
+	summary := inventory.ListRecentProductsParams{Pagination: pagination, View: inventory.ProductViewSummary}
+	m.EXPECT().ListRecentProducts(gomock.Not(gomock.Nil()), summary).Return(&inventory.ListProductsResponse{}, nil).Times(1)
+	m.EXPECT().ListTrendingProducts(gomock.Not(gomock.Nil()), gomock.Any()).Return(&inventory.ListProductsResponse{}, nil).Times(2)
+
+	api := inventory.Chain(inventory.NewService(m), inventory.WithListingCache(time.Hour))
+	for range 3 {
+		got, err := api.ListRecentProducts(context.Background(), inventory.ListRecentProductsParams{Pagination: pagination})
+		if err != nil || got != recent {
+			t.Errorf("ListRecentProducts() = %v, %v, want cached response", got, err)
+		}
+	}
+	if _, err := api.ListRecentProducts(context.Background(), inventory.ListRecentProductsParams{Pagination: inventory.Pagination{Limit: 10, Offset: 10}}); err != nil {
+		t.Errorf("ListRecentProducts() error = %v", err)
+	}
+	// Each view is cached separately.
+	for range 2 {
+		if got, err := api.ListRecentProducts(context.Background(), summary); err != nil || got == recent {
+			t.Errorf("ListRecentProducts() = %v, %v, want summary response", got, err)
+		}
+	}
+	for range 2 {
+		if _, err := api.ListTrendingProducts(context.Background(), inventory.ListTrendingProductsParams{Pagination: pagination}); err != nil {
+			t.Errorf("ListTrendingProducts() error = %v", err)
+		}
+	}
+	// A custom window isn't cached.
+	if _, err := api.ListTrendingProducts(context.Background(), inventory.ListTrendingProductsParams{
+		Since:      time.Now().Add(-time.Hour),
+		Pagination: pagination,
+	}); err != nil {
+		t.Errorf("ListTrendingProducts() error = %v", err)
+	}
+}
+
+func TestServiceProductViewValidation(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewService(inventory.NewMockDB(gomock.NewController(t)))
+	const view = inventory.ProductView("compact")
+	pagination := inventory.Pagination{Limit: 10}
+	const want = "invalid product view"
+	if _, err := s.SearchProducts(context.Background(), inventory.SearchProductsParams{
+		QueryString: "desk",
+		Pagination:  pagination,
+		View:        view,
+	}); err == nil || err.Error() != want {
+		t.Errorf("Service.SearchProducts() error = %v, want %q", err, want)
+	}
+	if _, err := s.ListTrendingProducts(context.Background(), inventory.ListTrendingProductsParams{Pagination: pagination, View: view}); err == nil || err.Error() != want {
+		t.Errorf("Service.ListTrendingProducts() error = %v, want %q", err, want)
+	}
+	if _, err := s.ListRecentProducts(context.Background(), inventory.ListRecentProductsParams{Pagination: pagination, View: view}); err == nil || err.Error() != want {
+		t.Errorf("Service.ListRecentProducts() error = %v, want %q", err, want)
+	}
+	if _, err := s.ListRecentlyViewed(context.Background(), inventory.ListRecentlyViewedParams{
+		UserID:     "user",
+		Pagination: pagination,
+		View:       view,
+	}); err == nil || err.Error() != want {
+		t.Errorf("Service.ListRecentlyViewed() error = %v, want %q", err, want)
+	}
+}
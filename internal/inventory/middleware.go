@@ -0,0 +1,411 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceMiddleware decorates an API, such as to instrument every method uniformly.
+type ServiceMiddleware func(API) API
+
+// Chain the middlewares around the API.
+// The first middleware is the outermost one, and is called first.
+func Chain(api API, mw ...ServiceMiddleware) API {
+	for i := len(mw) - 1; i >= 0; i-- {
+		api = mw[i](api)
+	}
+	return api
+}
+
+// WithLogging logs every method call with its duration and outcome, along with the request attributes in the context.
+// Successful and invalid calls are logged at debug level, and failed ones at error level.
+func WithLogging(log *slog.Logger) ServiceMiddleware {
+	return observe(func(ctx context.Context, method string) (context.Context, func(error)) {
+		start := time.Now()
+		return ctx, func(err error) {
+			level := slog.LevelDebug
+			if outcome(err) == "error" {
+				level = slog.LevelError
+			}
+			log.LogAttrs(ctx, level, "inventory call", append([]slog.Attr{
+				slog.String("method", method),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("outcome", outcome(err)),
+				slog.Any("error", err),
+			}, telemetry.RequestLogAttrs(ctx)...)...)
+		}
+	})
+}
+
+// WithMetrics records the number of calls and their duration by method and outcome.
+func WithMetrics(meter metric.Meter) (ServiceMiddleware, error) {
+	calls, err := meter.Int64Counter("inventory.calls",
+		metric.WithDescription("Number of inventory service calls."))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("inventory.duration",
+		metric.WithDescription("Duration of inventory service calls."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return observe(func(ctx context.Context, method string) (context.Context, func(error)) {
+		start := time.Now()
+		return ctx, func(err error) {
+			attrs := metric.WithAttributes(
+				attribute.String("method", method),
+				attribute.String("outcome", outcome(err)),
+			)
+			calls.Add(ctx, 1, attrs)
+			duration.Record(ctx, time.Since(start).Seconds(), attrs)
+		}
+	}), nil
+}
+
+// WithCounters counts the calls in progress ("in_flight") and the calls by method and outcome
+// (such as "GetProduct.ok") on the expvar map, so they can be inspected without a telemetry backend.
+func WithCounters(m *expvar.Map) ServiceMiddleware {
+	return observe(func(ctx context.Context, method string) (context.Context, func(error)) {
+		m.Add("in_flight", 1)
+		return ctx, func(err error) {
+			m.Add("in_flight", -1)
+			m.Add(method+"."+outcome(err), 1)
+		}
+	})
+}
+
+// WithTracing creates a span for every method call, with the request attributes in the context.
+// Validation errors are recorded on the span, but don't set its status to error.
+func WithTracing(tracer trace.Tracer) ServiceMiddleware {
+	return observe(func(ctx context.Context, method string) (context.Context, func(error)) {
+		ctx, span := tracer.Start(ctx, "inventory."+method, trace.WithAttributes(telemetry.RequestAttributes(ctx)...))
+		return ctx, func(err error) {
+			if err != nil {
+				span.RecordError(err)
+				if outcome(err) == "error" {
+					span.SetStatus(codes.Error, err.Error())
+				}
+			}
+			span.End()
+		}
+	})
+}
+
+// outcome of a call, used to classify calls in logs and metrics.
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &ValidationError{}), errors.As(err, new(*HasDependentsError)):
+		return "invalid"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case errors.Is(err, ErrReadOnly):
+		return "read_only"
+	default:
+		return "error"
+	}
+}
+
+// observer is called before a method call with the method name.
+// It returns the context to use for the call, and a function that is called with its error once it returns.
+type observer func(ctx context.Context, method string) (context.Context, func(err error))
+
+// observe creates a ServiceMiddleware calling the observer around every method.
+func observe(o observer) ServiceMiddleware {
+	return func(next API) API {
+		return observed{next: next, observe: o}
+	}
+}
+
+// observed API decorated by an observer.
+type observed struct {
+	next    API
+	observe observer
+}
+
+func (o observed) CreateProduct(ctx context.Context, params CreateProductParams) (_ *CreateProductResult, err error) {
+	ctx, done := o.observe(ctx, "CreateProduct")
+	defer func() { done(err) }()
+	return o.next.CreateProduct(ctx, params)
+}
+
+func (o observed) UpdateProduct(ctx context.Context, params UpdateProductParams) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "UpdateProduct")
+	defer func() { done(err) }()
+	return o.next.UpdateProduct(ctx, params)
+}
+
+func (o observed) DeleteProduct(ctx context.Context, params DeleteProductParams) (err error) {
+	ctx, done := o.observe(ctx, "DeleteProduct")
+	defer func() { done(err) }()
+	return o.next.DeleteProduct(ctx, params)
+}
+
+func (o observed) SyncProducts(ctx context.Context, params SyncProductsParams) (_ *SyncProductsResult, err error) {
+	ctx, done := o.observe(ctx, "SyncProducts")
+	defer func() { done(err) }()
+	return o.next.SyncProducts(ctx, params)
+}
+
+func (o observed) DeleteProducts(ctx context.Context, params DeleteProductsParams) (_ *DeleteProductsResult, err error) {
+	ctx, done := o.observe(ctx, "DeleteProducts")
+	defer func() { done(err) }()
+	return o.next.DeleteProducts(ctx, params)
+}
+
+func (o observed) GetProduct(ctx context.Context, id string) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "GetProduct")
+	defer func() { done(err) }()
+	return o.next.GetProduct(ctx, id)
+}
+
+func (o observed) GetProductAt(ctx context.Context, id string, at time.Time) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "GetProductAt")
+	defer func() { done(err) }()
+	return o.next.GetProductAt(ctx, id, at)
+}
+
+func (o observed) ProductHistory(ctx context.Context, id string) (_ []Version, err error) {
+	ctx, done := o.observe(ctx, "ProductHistory")
+	defer func() { done(err) }()
+	return o.next.ProductHistory(ctx, id)
+}
+
+func (o observed) SearchProducts(ctx context.Context, params SearchProductsParams) (_ *SearchProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "SearchProducts")
+	defer func() { done(err) }()
+	return o.next.SearchProducts(ctx, params)
+}
+
+func (o observed) ExportProducts(ctx context.Context, params SearchProductsParams, fn func(*Product) error) (err error) {
+	ctx, done := o.observe(ctx, "ExportProducts")
+	defer func() { done(err) }()
+	return o.next.ExportProducts(ctx, params, fn)
+}
+
+func (o observed) FindSimilarProducts(ctx context.Context, params FindSimilarProductsParams) (_ []SimilarProduct, err error) {
+	ctx, done := o.observe(ctx, "FindSimilarProducts")
+	defer func() { done(err) }()
+	return o.next.FindSimilarProducts(ctx, params)
+}
+
+func (o observed) GetProductBySlug(ctx context.Context, slug string) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "GetProductBySlug")
+	defer func() { done(err) }()
+	return o.next.GetProductBySlug(ctx, slug)
+}
+
+func (o observed) GetProductBySKU(ctx context.Context, sku string) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "GetProductBySKU")
+	defer func() { done(err) }()
+	return o.next.GetProductBySKU(ctx, sku)
+}
+
+func (o observed) QuoteProducts(ctx context.Context, lines []QuoteLine) (_ *Quote, err error) {
+	ctx, done := o.observe(ctx, "QuoteProducts")
+	defer func() { done(err) }()
+	return o.next.QuoteProducts(ctx, lines)
+}
+
+func (o observed) GetLocalizedProduct(ctx context.Context, id string, locales []string) (_ *Product, err error) {
+	ctx, done := o.observe(ctx, "GetLocalizedProduct")
+	defer func() { done(err) }()
+	return o.next.GetLocalizedProduct(ctx, id, locales)
+}
+
+func (o observed) UpsertProductTranslation(ctx context.Context, params ProductTranslation) (err error) {
+	ctx, done := o.observe(ctx, "UpsertProductTranslation")
+	defer func() { done(err) }()
+	return o.next.UpsertProductTranslation(ctx, params)
+}
+
+func (o observed) DeleteProductTranslation(ctx context.Context, productID, locale string) (err error) {
+	ctx, done := o.observe(ctx, "DeleteProductTranslation")
+	defer func() { done(err) }()
+	return o.next.DeleteProductTranslation(ctx, productID, locale)
+}
+
+func (o observed) GetProductStats(ctx context.Context, id string) (_ *ProductStats, err error) {
+	ctx, done := o.observe(ctx, "GetProductStats")
+	defer func() { done(err) }()
+	return o.next.GetProductStats(ctx, id)
+}
+
+func (o observed) AddFavorite(ctx context.Context, params FavoriteParams) (err error) {
+	ctx, done := o.observe(ctx, "AddFavorite")
+	defer func() { done(err) }()
+	return o.next.AddFavorite(ctx, params)
+}
+
+func (o observed) RemoveFavorite(ctx context.Context, params FavoriteParams) (err error) {
+	ctx, done := o.observe(ctx, "RemoveFavorite")
+	defer func() { done(err) }()
+	return o.next.RemoveFavorite(ctx, params)
+}
+
+func (o observed) ListFavorites(ctx context.Context, params ListFavoritesParams) (_ *ListFavoritesResponse, err error) {
+	ctx, done := o.observe(ctx, "ListFavorites")
+	defer func() { done(err) }()
+	return o.next.ListFavorites(ctx, params)
+}
+
+func (o observed) RecordProductView(ctx context.Context, id string) (err error) {
+	ctx, done := o.observe(ctx, "RecordProductView")
+	defer func() { done(err) }()
+	return o.next.RecordProductView(ctx, id)
+}
+
+func (o observed) ListTrendingProducts(ctx context.Context, params ListTrendingProductsParams) (_ *ListProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "ListTrendingProducts")
+	defer func() { done(err) }()
+	return o.next.ListTrendingProducts(ctx, params)
+}
+
+func (o observed) ListRecentProducts(ctx context.Context, params ListRecentProductsParams) (_ *ListProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "ListRecentProducts")
+	defer func() { done(err) }()
+	return o.next.ListRecentProducts(ctx, params)
+}
+
+func (o observed) RecordRecentlyViewed(ctx context.Context, params RecentlyViewedParams) (err error) {
+	ctx, done := o.observe(ctx, "RecordRecentlyViewed")
+	defer func() { done(err) }()
+	return o.next.RecordRecentlyViewed(ctx, params)
+}
+
+func (o observed) ListRecentlyViewed(ctx context.Context, params ListRecentlyViewedParams) (_ *ListProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "ListRecentlyViewed")
+	defer func() { done(err) }()
+	return o.next.ListRecentlyViewed(ctx, params)
+}
+
+func (o observed) CreateSupplier(ctx context.Context, params CreateSupplierParams) (_ *Supplier, err error) {
+	ctx, done := o.observe(ctx, "CreateSupplier")
+	defer func() { done(err) }()
+	return o.next.CreateSupplier(ctx, params)
+}
+
+func (o observed) UpdateSupplier(ctx context.Context, params UpdateSupplierParams) (_ *Supplier, err error) {
+	ctx, done := o.observe(ctx, "UpdateSupplier")
+	defer func() { done(err) }()
+	return o.next.UpdateSupplier(ctx, params)
+}
+
+func (o observed) DeleteSupplier(ctx context.Context, id string) (err error) {
+	ctx, done := o.observe(ctx, "DeleteSupplier")
+	defer func() { done(err) }()
+	return o.next.DeleteSupplier(ctx, id)
+}
+
+func (o observed) GetSupplier(ctx context.Context, id string) (_ *Supplier, err error) {
+	ctx, done := o.observe(ctx, "GetSupplier")
+	defer func() { done(err) }()
+	return o.next.GetSupplier(ctx, id)
+}
+
+func (o observed) SetProductSupplier(ctx context.Context, params ProductSupplier) (err error) {
+	ctx, done := o.observe(ctx, "SetProductSupplier")
+	defer func() { done(err) }()
+	return o.next.SetProductSupplier(ctx, params)
+}
+
+func (o observed) RemoveProductSupplier(ctx context.Context, productID, supplierID string) (err error) {
+	ctx, done := o.observe(ctx, "RemoveProductSupplier")
+	defer func() { done(err) }()
+	return o.next.RemoveProductSupplier(ctx, productID, supplierID)
+}
+
+func (o observed) ListProductSuppliers(ctx context.Context, productID string) (_ []*ProductSupplier, err error) {
+	ctx, done := o.observe(ctx, "ListProductSuppliers")
+	defer func() { done(err) }()
+	return o.next.ListProductSuppliers(ctx, productID)
+}
+
+func (o observed) ListSupplierProducts(ctx context.Context, params ListSupplierProductsParams) (_ *ListProductsResponse, err error) {
+	ctx, done := o.observe(ctx, "ListSupplierProducts")
+	defer func() { done(err) }()
+	return o.next.ListSupplierProducts(ctx, params)
+}
+
+func (o observed) CreateWarehouse(ctx context.Context, params CreateWarehouseParams) (_ *Warehouse, err error) {
+	ctx, done := o.observe(ctx, "CreateWarehouse")
+	defer func() { done(err) }()
+	return o.next.CreateWarehouse(ctx, params)
+}
+
+func (o observed) ListWarehouses(ctx context.Context) (_ []*Warehouse, err error) {
+	ctx, done := o.observe(ctx, "ListWarehouses")
+	defer func() { done(err) }()
+	return o.next.ListWarehouses(ctx)
+}
+
+func (o observed) SetProductStock(ctx context.Context, params SetProductStockParams) (err error) {
+	ctx, done := o.observe(ctx, "SetProductStock")
+	defer func() { done(err) }()
+	return o.next.SetProductStock(ctx, params)
+}
+
+func (o observed) TransferStock(ctx context.Context, params TransferStockParams) (err error) {
+	ctx, done := o.observe(ctx, "TransferStock")
+	defer func() { done(err) }()
+	return o.next.TransferStock(ctx, params)
+}
+
+func (o observed) GetProductStock(ctx context.Context, productID string) (_ []*StockLevel, err error) {
+	ctx, done := o.observe(ctx, "GetProductStock")
+	defer func() { done(err) }()
+	return o.next.GetProductStock(ctx, productID)
+}
+
+func (o observed) CreateProductReview(ctx context.Context, params CreateProductReviewParams) (_ string, err error) {
+	ctx, done := o.observe(ctx, "CreateProductReview")
+	defer func() { done(err) }()
+	return o.next.CreateProductReview(ctx, params)
+}
+
+func (o observed) UpdateProductReview(ctx context.Context, params UpdateProductReviewParams) (err error) {
+	ctx, done := o.observe(ctx, "UpdateProductReview")
+	defer func() { done(err) }()
+	return o.next.UpdateProductReview(ctx, params)
+}
+
+func (o observed) DeleteProductReview(ctx context.Context, id string) (err error) {
+	ctx, done := o.observe(ctx, "DeleteProductReview")
+	defer func() { done(err) }()
+	return o.next.DeleteProductReview(ctx, id)
+}
+
+func (o observed) GetProductReview(ctx context.Context, id string) (_ *ProductReview, err error) {
+	ctx, done := o.observe(ctx, "GetProductReview")
+	defer func() { done(err) }()
+	return o.next.GetProductReview(ctx, id)
+}
+
+func (o observed) GetProductReviews(ctx context.Context, params ProductReviewsParams) (_ *ProductReviewsResponse, err error) {
+	ctx, done := o.observe(ctx, "GetProductReviews")
+	defer func() { done(err) }()
+	return o.next.GetProductReviews(ctx, params)
+}
+
+func (o observed) ReviewHistory(ctx context.Context, id string) (_ []Version, err error) {
+	ctx, done := o.observe(ctx, "ReviewHistory")
+	defer func() { done(err) }()
+	return o.next.ReviewHistory(ctx, id)
+}
+
+func (o observed) PurgeReviewerData(ctx context.Context, params PurgeReviewerDataParams) (_ *PurgeReviewerDataResult, err error) {
+	ctx, done := o.observe(ctx, "PurgeReviewerData")
+	defer func() { done(err) }()
+	return o.next.PurgeReviewerData(ctx, params)
+}
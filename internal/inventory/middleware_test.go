@@ -0,0 +1,123 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"strings"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/telemetry"
+	"github.com/henvic/pgxtutorial/internal/telemetry/telemetrytest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceMiddleware(t *testing.T) {
+	t.Parallel()
+	tel, mem := telemetrytest.Provider()
+	counters := new(expvar.Map)
+	metrics, err := inventory.WithMetrics(tel.Meter())
+	if err != nil {
+		t.Fatalf("inventory.WithMetrics() error = %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	m := inventory.NewMockDB(ctrl)
+	m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "product").Return(&inventory.Product{ID: "product"}, nil)
+	m.EXPECT().DeleteProduct(gomock.Not(gomock.Nil()), inventory.DeleteProductParams{ID: "product"}).Return(errors.New("unexpected error"))
+
+	s := inventory.Chain(inventory.NewService(m),
+		inventory.WithLogging(tel.Logger()),
+		metrics,
+		inventory.WithCounters(counters),
+		inventory.WithTracing(tel.Tracer()),
+	)
+
+	if _, err := s.GetProduct(context.Background(), "product"); err != nil {
+		t.Errorf("GetProduct() error = %v", err)
+	}
+	tenant, err := baggage.NewMember("tenant", "acme")
+	if err != nil {
+		t.Fatalf("baggage.NewMember() error = %v", err)
+	}
+	b, err := baggage.New(tenant)
+	if err != nil {
+		t.Fatalf("baggage.New() error = %v", err)
+	}
+	ctx := telemetry.ContextWithBaggageAttributes(baggage.ContextWithBaggage(context.Background(), b), telemetry.BaggageKeys...)
+	if err := s.DeleteProduct(ctx, inventory.DeleteProductParams{ID: "product"}); err == nil || err.Error() != "unexpected error" {
+		t.Errorf("DeleteProduct() error = %v, want unexpected error", err)
+	}
+	if _, err := s.GetProduct(context.Background(), ""); err == nil || err.Error() != "missing product ID" {
+		t.Errorf("GetProduct() error = %v, want missing product ID", err)
+	}
+
+	spans := mem.Trace()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d instead", len(spans))
+	}
+	wantStatus := []codes.Code{codes.Unset, codes.Error, codes.Unset}
+	for i, span := range spans {
+		if want := []string{"inventory.GetProduct", "inventory.DeleteProduct", "inventory.GetProduct"}[i]; span.Name() != want {
+			t.Errorf("spans[%d].Name() = %v, want %v", i, span.Name(), want)
+		}
+		if span.Status().Code != wantStatus[i] {
+			t.Errorf("spans[%d].Status() = %v, want %v", i, span.Status().Code, wantStatus[i])
+		}
+	}
+	if attrs := spans[1].Attributes(); len(attrs) != 1 || attrs[0] != attribute.String("tenant", "acme") {
+		t.Errorf("spans[1].Attributes() = %v, want tenant attribute", attrs)
+	}
+
+	log := mem.Log()
+	if !strings.Contains(log, `"level":"ERROR","msg":"inventory call","method":"DeleteProduct"`) {
+		t.Errorf("expected failed call to be logged, got %v", log)
+	}
+	if !strings.Contains(log, `"tenant":"acme"`) {
+		t.Errorf("expected request attributes to be logged, got %v", log)
+	}
+	for k, want := range map[string]string{"in_flight": "0", "GetProduct.ok": "1", "GetProduct.invalid": "1", "DeleteProduct.error": "1"} {
+		if got := counters.Get(k); got == nil || got.String() != want {
+			t.Errorf("counter %s = %v, want %v", k, got, want)
+		}
+	}
+	if meter := mem.Meter(); !strings.Contains(meter, "inventory.calls") || !strings.Contains(meter, "inventory.duration") {
+		t.Errorf("expected inventory metrics to be recorded, got %v", meter)
+	}
+}
+
+func TestWithDBMetrics(t *testing.T) {
+	t.Parallel()
+	tel, mem := telemetrytest.Provider()
+	ctrl := gomock.NewController(t)
+	m := inventory.NewMockDB(ctrl)
+	m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "product").Return(&inventory.Product{ID: "product"}, nil)
+	m.EXPECT().DeleteProduct(gomock.Not(gomock.Nil()), inventory.DeleteProductParams{ID: "product"}).Return(
+		&inventory.InfrastructureError{Message: "cannot delete product from database", Err: errors.New("connection refused")})
+	db, err := inventory.WithDBMetrics(m, tel.Meter())
+	if err != nil {
+		t.Fatalf("inventory.WithDBMetrics() error = %v", err)
+	}
+	s := inventory.NewService(db)
+	if _, err := s.GetProduct(context.Background(), "product"); err != nil {
+		t.Errorf("GetProduct() error = %v", err)
+	}
+	if err := s.DeleteProduct(context.Background(), inventory.DeleteProductParams{ID: "product"}); err == nil || err.Error() != "cannot delete product from database" {
+		t.Errorf("DeleteProduct() error = %v, want cannot delete product from database", err)
+	}
+	// Validation errors are returned by the service before calling the database.
+	if _, err := s.GetProduct(context.Background(), ""); err == nil {
+		t.Error("GetProduct() should fail with a missing product ID")
+	}
+
+	meter := mem.Meter()
+	for _, want := range []string{"inventory.db.calls", "inventory.db.duration", "GetProduct", "DeleteProduct", "infrastructure"} {
+		if !strings.Contains(meter, want) {
+			t.Errorf("expected %q in the database metrics, got %v", want, meter)
+		}
+	}
+}
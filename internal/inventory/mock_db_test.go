@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/henvic/pgxtutorial/internal/inventory (interfaces: DB)
+// Source: github.com/henvic/pgxtutorial/internal/inventory (interfaces: DB,ProductRepository,ReviewRepository)
 //
 // Generated by this command:
 //
-//	mockgen --build_flags=--mod=mod -package inventory -destination mock_db_test.go . DB
+//	mockgen --build_flags=--mod=mod -package inventory -destination mock_db_test.go . DB,ProductRepository,ReviewRepository
 //
 
 // Package inventory is a generated GoMock package.
@@ -12,6 +12,7 @@ package inventory
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -39,14 +40,73 @@ func (m *MockDB) EXPECT() *MockDBMockRecorder {
 	return m.recorder
 }
 
-// CreateProduct mocks base method.
-func (m *MockDB) CreateProduct(arg0 context.Context, arg1 CreateProductParams) error {
+// AddFavorite mocks base method.
+func (m *MockDB) AddFavorite(arg0 context.Context, arg1 FavoriteParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateProduct", arg0, arg1)
+	ret := m.ctrl.Call(m, "AddFavorite", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFavorite indicates an expected call of AddFavorite.
+func (mr *MockDBMockRecorder) AddFavorite(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFavorite", reflect.TypeOf((*MockDB)(nil).AddFavorite), arg0, arg1)
+}
+
+// ApplyProductChanges mocks base method.
+func (m *MockDB) ApplyProductChanges(arg0 context.Context, arg1 []ProductChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyProductChanges", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// ApplyProductChanges indicates an expected call of ApplyProductChanges.
+func (mr *MockDBMockRecorder) ApplyProductChanges(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyProductChanges", reflect.TypeOf((*MockDB)(nil).ApplyProductChanges), arg0, arg1)
+}
+
+// CountProducts mocks base method.
+func (m *MockDB) CountProducts(arg0 context.Context, arg1 ProductFilter, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountProducts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountProducts indicates an expected call of CountProducts.
+func (mr *MockDBMockRecorder) CountProducts(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountProducts", reflect.TypeOf((*MockDB)(nil).CountProducts), arg0, arg1, arg2)
+}
+
+// CountReviewerReviews mocks base method.
+func (m *MockDB) CountReviewerReviews(arg0 context.Context, arg1 string, arg2 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReviewerReviews", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReviewerReviews indicates an expected call of CountReviewerReviews.
+func (mr *MockDBMockRecorder) CountReviewerReviews(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReviewerReviews", reflect.TypeOf((*MockDB)(nil).CountReviewerReviews), arg0, arg1, arg2)
+}
+
+// CreateProduct mocks base method.
+func (m *MockDB) CreateProduct(arg0 context.Context, arg1 CreateProductParams) (*CreateProductResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", arg0, arg1)
+	ret0, _ := ret[0].(*CreateProductResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // CreateProduct indicates an expected call of CreateProduct.
 func (mr *MockDBMockRecorder) CreateProduct(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
@@ -67,8 +127,38 @@ func (mr *MockDBMockRecorder) CreateProductReview(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductReview", reflect.TypeOf((*MockDB)(nil).CreateProductReview), arg0, arg1)
 }
 
+// CreateSupplier mocks base method.
+func (m *MockDB) CreateSupplier(arg0 context.Context, arg1 CreateSupplierParams) (*Supplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSupplier", arg0, arg1)
+	ret0, _ := ret[0].(*Supplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSupplier indicates an expected call of CreateSupplier.
+func (mr *MockDBMockRecorder) CreateSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSupplier", reflect.TypeOf((*MockDB)(nil).CreateSupplier), arg0, arg1)
+}
+
+// CreateWarehouse mocks base method.
+func (m *MockDB) CreateWarehouse(arg0 context.Context, arg1 CreateWarehouseParams) (*Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWarehouse", arg0, arg1)
+	ret0, _ := ret[0].(*Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWarehouse indicates an expected call of CreateWarehouse.
+func (mr *MockDBMockRecorder) CreateWarehouse(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWarehouse", reflect.TypeOf((*MockDB)(nil).CreateWarehouse), arg0, arg1)
+}
+
 // DeleteProduct mocks base method.
-func (m *MockDB) DeleteProduct(arg0 context.Context, arg1 string) error {
+func (m *MockDB) DeleteProduct(arg0 context.Context, arg1 DeleteProductParams) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteProduct", arg0, arg1)
 	ret0, _ := ret[0].(error)
@@ -95,6 +185,78 @@ func (mr *MockDBMockRecorder) DeleteProductReview(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductReview", reflect.TypeOf((*MockDB)(nil).DeleteProductReview), arg0, arg1)
 }
 
+// DeleteProductTranslation mocks base method.
+func (m *MockDB) DeleteProductTranslation(arg0 context.Context, arg1 string, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductTranslation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductTranslation indicates an expected call of DeleteProductTranslation.
+func (mr *MockDBMockRecorder) DeleteProductTranslation(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductTranslation", reflect.TypeOf((*MockDB)(nil).DeleteProductTranslation), arg0, arg1, arg2)
+}
+
+// DeleteProducts mocks base method.
+func (m *MockDB) DeleteProducts(arg0 context.Context, arg1 ProductFilter, arg2 int) (*DeleteProductsResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProducts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*DeleteProductsResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProducts indicates an expected call of DeleteProducts.
+func (mr *MockDBMockRecorder) DeleteProducts(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProducts", reflect.TypeOf((*MockDB)(nil).DeleteProducts), arg0, arg1, arg2)
+}
+
+// DeleteSupplier mocks base method.
+func (m *MockDB) DeleteSupplier(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSupplier", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSupplier indicates an expected call of DeleteSupplier.
+func (mr *MockDBMockRecorder) DeleteSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSupplier", reflect.TypeOf((*MockDB)(nil).DeleteSupplier), arg0, arg1)
+}
+
+// ExportProducts mocks base method.
+func (m *MockDB) ExportProducts(arg0 context.Context, arg1 SearchProductsParams, arg2 func(*Product) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportProducts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportProducts indicates an expected call of ExportProducts.
+func (mr *MockDBMockRecorder) ExportProducts(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportProducts", reflect.TypeOf((*MockDB)(nil).ExportProducts), arg0, arg1, arg2)
+}
+
+// FindSimilarProducts mocks base method.
+func (m *MockDB) FindSimilarProducts(arg0 context.Context, arg1 FindSimilarProductsParams) ([]SimilarProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSimilarProducts", arg0, arg1)
+	ret0, _ := ret[0].([]SimilarProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSimilarProducts indicates an expected call of FindSimilarProducts.
+func (mr *MockDBMockRecorder) FindSimilarProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilarProducts", reflect.TypeOf((*MockDB)(nil).FindSimilarProducts), arg0, arg1)
+}
+
 // GetProduct mocks base method.
 func (m *MockDB) GetProduct(arg0 context.Context, arg1 string) (*Product, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +272,51 @@ func (mr *MockDBMockRecorder) GetProduct(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockDB)(nil).GetProduct), arg0, arg1)
 }
 
+// GetProductAt mocks base method.
+func (m *MockDB) GetProductAt(arg0 context.Context, arg1 string, arg2 time.Time) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductAt indicates an expected call of GetProductAt.
+func (mr *MockDBMockRecorder) GetProductAt(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductAt", reflect.TypeOf((*MockDB)(nil).GetProductAt), arg0, arg1, arg2)
+}
+
+// GetProductBySKU mocks base method.
+func (m *MockDB) GetProductBySKU(arg0 context.Context, arg1 string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductBySKU", arg0, arg1)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductBySKU indicates an expected call of GetProductBySKU.
+func (mr *MockDBMockRecorder) GetProductBySKU(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductBySKU", reflect.TypeOf((*MockDB)(nil).GetProductBySKU), arg0, arg1)
+}
+
+// GetProductBySlug mocks base method.
+func (m *MockDB) GetProductBySlug(arg0 context.Context, arg1 string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductBySlug", arg0, arg1)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductBySlug indicates an expected call of GetProductBySlug.
+func (mr *MockDBMockRecorder) GetProductBySlug(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductBySlug", reflect.TypeOf((*MockDB)(nil).GetProductBySlug), arg0, arg1)
+}
+
 // GetProductReview mocks base method.
 func (m *MockDB) GetProductReview(arg0 context.Context, arg1 string) (*ProductReview, error) {
 	m.ctrl.T.Helper()
@@ -140,45 +347,1177 @@ func (mr *MockDBMockRecorder) GetProductReviews(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductReviews", reflect.TypeOf((*MockDB)(nil).GetProductReviews), arg0, arg1)
 }
 
-// SearchProducts mocks base method.
-func (m *MockDB) SearchProducts(arg0 context.Context, arg1 SearchProductsParams) (*SearchProductsResponse, error) {
+// GetProductStats mocks base method.
+func (m *MockDB) GetProductStats(arg0 context.Context, arg1 string) (*ProductStats, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchProducts", arg0, arg1)
-	ret0, _ := ret[0].(*SearchProductsResponse)
+	ret := m.ctrl.Call(m, "GetProductStats", arg0, arg1)
+	ret0, _ := ret[0].(*ProductStats)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchProducts indicates an expected call of SearchProducts.
-func (mr *MockDBMockRecorder) SearchProducts(arg0, arg1 any) *gomock.Call {
+// GetProductStats indicates an expected call of GetProductStats.
+func (mr *MockDBMockRecorder) GetProductStats(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockDB)(nil).SearchProducts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductStats", reflect.TypeOf((*MockDB)(nil).GetProductStats), arg0, arg1)
 }
 
-// UpdateProduct mocks base method.
-func (m *MockDB) UpdateProduct(arg0 context.Context, arg1 UpdateProductParams) error {
+// GetProductStock mocks base method.
+func (m *MockDB) GetProductStock(arg0 context.Context, arg1 string) ([]*StockLevel, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProduct", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetProductStock", arg0, arg1)
+	ret0, _ := ret[0].([]*StockLevel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductStock indicates an expected call of GetProductStock.
+func (mr *MockDBMockRecorder) GetProductStock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductStock", reflect.TypeOf((*MockDB)(nil).GetProductStock), arg0, arg1)
+}
+
+// GetProductTranslation mocks base method.
+func (m *MockDB) GetProductTranslation(arg0 context.Context, arg1 string, arg2 []string) (*ProductTranslation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductTranslation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*ProductTranslation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductTranslation indicates an expected call of GetProductTranslation.
+func (mr *MockDBMockRecorder) GetProductTranslation(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductTranslation", reflect.TypeOf((*MockDB)(nil).GetProductTranslation), arg0, arg1, arg2)
+}
+
+// GetProducts mocks base method.
+func (m *MockDB) GetProducts(arg0 context.Context, arg1 []string) ([]*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProducts", arg0, arg1)
+	ret0, _ := ret[0].([]*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProducts indicates an expected call of GetProducts.
+func (mr *MockDBMockRecorder) GetProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockDB)(nil).GetProducts), arg0, arg1)
+}
+
+// GetSupplier mocks base method.
+func (m *MockDB) GetSupplier(arg0 context.Context, arg1 string) (*Supplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSupplier", arg0, arg1)
+	ret0, _ := ret[0].(*Supplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSupplier indicates an expected call of GetSupplier.
+func (mr *MockDBMockRecorder) GetSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSupplier", reflect.TypeOf((*MockDB)(nil).GetSupplier), arg0, arg1)
+}
+
+// ListFavorites mocks base method.
+func (m *MockDB) ListFavorites(arg0 context.Context, arg1 ListFavoritesParams) (*ListFavoritesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFavorites", arg0, arg1)
+	ret0, _ := ret[0].(*ListFavoritesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFavorites indicates an expected call of ListFavorites.
+func (mr *MockDBMockRecorder) ListFavorites(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFavorites", reflect.TypeOf((*MockDB)(nil).ListFavorites), arg0, arg1)
+}
+
+// ListProductSuppliers mocks base method.
+func (m *MockDB) ListProductSuppliers(arg0 context.Context, arg1 string) ([]*ProductSupplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductSuppliers", arg0, arg1)
+	ret0, _ := ret[0].([]*ProductSupplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductSuppliers indicates an expected call of ListProductSuppliers.
+func (mr *MockDBMockRecorder) ListProductSuppliers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductSuppliers", reflect.TypeOf((*MockDB)(nil).ListProductSuppliers), arg0, arg1)
+}
+
+// ListRecentProducts mocks base method.
+func (m *MockDB) ListRecentProducts(arg0 context.Context, arg1 ListRecentProductsParams) (*ListProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecentProducts", arg0, arg1)
+	ret0, _ := ret[0].(*ListProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentProducts indicates an expected call of ListRecentProducts.
+func (mr *MockDBMockRecorder) ListRecentProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentProducts", reflect.TypeOf((*MockDB)(nil).ListRecentProducts), arg0, arg1)
+}
+
+// ListRecentlyViewed mocks base method.
+func (m *MockDB) ListRecentlyViewed(arg0 context.Context, arg1 ListRecentlyViewedParams) (*ListProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecentlyViewed", arg0, arg1)
+	ret0, _ := ret[0].(*ListProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentlyViewed indicates an expected call of ListRecentlyViewed.
+func (mr *MockDBMockRecorder) ListRecentlyViewed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentlyViewed", reflect.TypeOf((*MockDB)(nil).ListRecentlyViewed), arg0, arg1)
+}
+
+// ListSupplierProducts mocks base method.
+func (m *MockDB) ListSupplierProducts(arg0 context.Context, arg1 ListSupplierProductsParams) (*ListProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSupplierProducts", arg0, arg1)
+	ret0, _ := ret[0].(*ListProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSupplierProducts indicates an expected call of ListSupplierProducts.
+func (mr *MockDBMockRecorder) ListSupplierProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSupplierProducts", reflect.TypeOf((*MockDB)(nil).ListSupplierProducts), arg0, arg1)
+}
+
+// ListTenantProducts mocks base method.
+func (m *MockDB) ListTenantProducts(arg0 context.Context, arg1 ListTenantProductsParams) ([]*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTenantProducts", arg0, arg1)
+	ret0, _ := ret[0].([]*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTenantProducts indicates an expected call of ListTenantProducts.
+func (mr *MockDBMockRecorder) ListTenantProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTenantProducts", reflect.TypeOf((*MockDB)(nil).ListTenantProducts), arg0, arg1)
+}
+
+// ListTrendingProducts mocks base method.
+func (m *MockDB) ListTrendingProducts(arg0 context.Context, arg1 ListTrendingProductsParams) (*ListProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrendingProducts", arg0, arg1)
+	ret0, _ := ret[0].(*ListProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrendingProducts indicates an expected call of ListTrendingProducts.
+func (mr *MockDBMockRecorder) ListTrendingProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrendingProducts", reflect.TypeOf((*MockDB)(nil).ListTrendingProducts), arg0, arg1)
+}
+
+// ListWarehouses mocks base method.
+func (m *MockDB) ListWarehouses(arg0 context.Context) ([]*Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWarehouses", arg0)
+	ret0, _ := ret[0].([]*Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWarehouses indicates an expected call of ListWarehouses.
+func (mr *MockDBMockRecorder) ListWarehouses(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWarehouses", reflect.TypeOf((*MockDB)(nil).ListWarehouses), arg0)
+}
+
+// ProductHistory mocks base method.
+func (m *MockDB) ProductHistory(arg0 context.Context, arg1 string) ([]Version, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProductHistory", arg0, arg1)
+	ret0, _ := ret[0].([]Version)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProductHistory indicates an expected call of ProductHistory.
+func (mr *MockDBMockRecorder) ProductHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProductHistory", reflect.TypeOf((*MockDB)(nil).ProductHistory), arg0, arg1)
+}
+
+// PurgeReviewerData mocks base method.
+func (m *MockDB) PurgeReviewerData(arg0 context.Context, arg1 PurgeReviewerDataParams) (*PurgeReviewerDataResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeReviewerData", arg0, arg1)
+	ret0, _ := ret[0].(*PurgeReviewerDataResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeReviewerData indicates an expected call of PurgeReviewerData.
+func (mr *MockDBMockRecorder) PurgeReviewerData(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeReviewerData", reflect.TypeOf((*MockDB)(nil).PurgeReviewerData), arg0, arg1)
+}
+
+// RecordProductView mocks base method.
+func (m *MockDB) RecordProductView(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordProductView", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateProduct indicates an expected call of UpdateProduct.
-func (mr *MockDBMockRecorder) UpdateProduct(arg0, arg1 any) *gomock.Call {
+// RecordProductView indicates an expected call of RecordProductView.
+func (mr *MockDBMockRecorder) RecordProductView(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockDB)(nil).UpdateProduct), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordProductView", reflect.TypeOf((*MockDB)(nil).RecordProductView), arg0, arg1)
 }
 
-// UpdateProductReview mocks base method.
-func (m *MockDB) UpdateProductReview(arg0 context.Context, arg1 UpdateProductReviewParams) error {
+// RecordRecentlyViewed mocks base method.
+func (m *MockDB) RecordRecentlyViewed(arg0 context.Context, arg1 RecentlyViewedParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProductReview", arg0, arg1)
+	ret := m.ctrl.Call(m, "RecordRecentlyViewed", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateProductReview indicates an expected call of UpdateProductReview.
-func (mr *MockDBMockRecorder) UpdateProductReview(arg0, arg1 any) *gomock.Call {
+// RecordRecentlyViewed indicates an expected call of RecordRecentlyViewed.
+func (mr *MockDBMockRecorder) RecordRecentlyViewed(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductReview", reflect.TypeOf((*MockDB)(nil).UpdateProductReview), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRecentlyViewed", reflect.TypeOf((*MockDB)(nil).RecordRecentlyViewed), arg0, arg1)
+}
+
+// RemoveFavorite mocks base method.
+func (m *MockDB) RemoveFavorite(arg0 context.Context, arg1 FavoriteParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFavorite", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFavorite indicates an expected call of RemoveFavorite.
+func (mr *MockDBMockRecorder) RemoveFavorite(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFavorite", reflect.TypeOf((*MockDB)(nil).RemoveFavorite), arg0, arg1)
+}
+
+// RemoveProductSupplier mocks base method.
+func (m *MockDB) RemoveProductSupplier(arg0 context.Context, arg1 string, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveProductSupplier", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveProductSupplier indicates an expected call of RemoveProductSupplier.
+func (mr *MockDBMockRecorder) RemoveProductSupplier(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProductSupplier", reflect.TypeOf((*MockDB)(nil).RemoveProductSupplier), arg0, arg1, arg2)
+}
+
+// ReviewHistory mocks base method.
+func (m *MockDB) ReviewHistory(arg0 context.Context, arg1 string) ([]Version, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewHistory", arg0, arg1)
+	ret0, _ := ret[0].([]Version)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewHistory indicates an expected call of ReviewHistory.
+func (mr *MockDBMockRecorder) ReviewHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewHistory", reflect.TypeOf((*MockDB)(nil).ReviewHistory), arg0, arg1)
+}
+
+// SearchProducts mocks base method.
+func (m *MockDB) SearchProducts(arg0 context.Context, arg1 SearchProductsParams) (*SearchProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", arg0, arg1)
+	ret0, _ := ret[0].(*SearchProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockDBMockRecorder) SearchProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockDB)(nil).SearchProducts), arg0, arg1)
+}
+
+// SetProductStock mocks base method.
+func (m *MockDB) SetProductStock(arg0 context.Context, arg1 SetProductStockParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductStock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProductStock indicates an expected call of SetProductStock.
+func (mr *MockDBMockRecorder) SetProductStock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductStock", reflect.TypeOf((*MockDB)(nil).SetProductStock), arg0, arg1)
+}
+
+// SetProductSupplier mocks base method.
+func (m *MockDB) SetProductSupplier(arg0 context.Context, arg1 ProductSupplier) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductSupplier", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProductSupplier indicates an expected call of SetProductSupplier.
+func (mr *MockDBMockRecorder) SetProductSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductSupplier", reflect.TypeOf((*MockDB)(nil).SetProductSupplier), arg0, arg1)
+}
+
+// TransferStock mocks base method.
+func (m *MockDB) TransferStock(arg0 context.Context, arg1 TransferStockParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferStock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferStock indicates an expected call of TransferStock.
+func (mr *MockDBMockRecorder) TransferStock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferStock", reflect.TypeOf((*MockDB)(nil).TransferStock), arg0, arg1)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockDB) UpdateProduct(arg0 context.Context, arg1 UpdateProductParams) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", arg0, arg1)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockDBMockRecorder) UpdateProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockDB)(nil).UpdateProduct), arg0, arg1)
+}
+
+// UpdateProductReview mocks base method.
+func (m *MockDB) UpdateProductReview(arg0 context.Context, arg1 UpdateProductReviewParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProductReview", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProductReview indicates an expected call of UpdateProductReview.
+func (mr *MockDBMockRecorder) UpdateProductReview(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductReview", reflect.TypeOf((*MockDB)(nil).UpdateProductReview), arg0, arg1)
+}
+
+// UpdateSupplier mocks base method.
+func (m *MockDB) UpdateSupplier(arg0 context.Context, arg1 UpdateSupplierParams) (*Supplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSupplier", arg0, arg1)
+	ret0, _ := ret[0].(*Supplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSupplier indicates an expected call of UpdateSupplier.
+func (mr *MockDBMockRecorder) UpdateSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSupplier", reflect.TypeOf((*MockDB)(nil).UpdateSupplier), arg0, arg1)
+}
+
+// UpsertProductTranslation mocks base method.
+func (m *MockDB) UpsertProductTranslation(arg0 context.Context, arg1 ProductTranslation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertProductTranslation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertProductTranslation indicates an expected call of UpsertProductTranslation.
+func (mr *MockDBMockRecorder) UpsertProductTranslation(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertProductTranslation", reflect.TypeOf((*MockDB)(nil).UpsertProductTranslation), arg0, arg1)
+}
+
+// MockProductRepository is a mock of ProductRepository interface.
+type MockProductRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductRepositoryMockRecorder
+}
+
+// MockProductRepositoryMockRecorder is the mock recorder for MockProductRepository.
+type MockProductRepositoryMockRecorder struct {
+	mock *MockProductRepository
+}
+
+// NewMockProductRepository creates a new mock instance.
+func NewMockProductRepository(ctrl *gomock.Controller) *MockProductRepository {
+	mock := &MockProductRepository{ctrl: ctrl}
+	mock.recorder = &MockProductRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductRepository) EXPECT() *MockProductRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddFavorite mocks base method.
+func (m *MockProductRepository) AddFavorite(arg0 context.Context, arg1 FavoriteParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFavorite", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFavorite indicates an expected call of AddFavorite.
+func (mr *MockProductRepositoryMockRecorder) AddFavorite(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFavorite", reflect.TypeOf((*MockProductRepository)(nil).AddFavorite), arg0, arg1)
+}
+
+// ApplyProductChanges mocks base method.
+func (m *MockProductRepository) ApplyProductChanges(arg0 context.Context, arg1 []ProductChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyProductChanges", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyProductChanges indicates an expected call of ApplyProductChanges.
+func (mr *MockProductRepositoryMockRecorder) ApplyProductChanges(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyProductChanges", reflect.TypeOf((*MockProductRepository)(nil).ApplyProductChanges), arg0, arg1)
+}
+
+// CountProducts mocks base method.
+func (m *MockProductRepository) CountProducts(arg0 context.Context, arg1 ProductFilter, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountProducts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountProducts indicates an expected call of CountProducts.
+func (mr *MockProductRepositoryMockRecorder) CountProducts(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountProducts", reflect.TypeOf((*MockProductRepository)(nil).CountProducts), arg0, arg1, arg2)
+}
+
+// CreateProduct mocks base method.
+func (m *MockProductRepository) CreateProduct(arg0 context.Context, arg1 CreateProductParams) (*CreateProductResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", arg0, arg1)
+	ret0, _ := ret[0].(*CreateProductResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockProductRepositoryMockRecorder) CreateProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockProductRepository)(nil).CreateProduct), arg0, arg1)
+}
+
+// CreateSupplier mocks base method.
+func (m *MockProductRepository) CreateSupplier(arg0 context.Context, arg1 CreateSupplierParams) (*Supplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSupplier", arg0, arg1)
+	ret0, _ := ret[0].(*Supplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSupplier indicates an expected call of CreateSupplier.
+func (mr *MockProductRepositoryMockRecorder) CreateSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSupplier", reflect.TypeOf((*MockProductRepository)(nil).CreateSupplier), arg0, arg1)
+}
+
+// CreateWarehouse mocks base method.
+func (m *MockProductRepository) CreateWarehouse(arg0 context.Context, arg1 CreateWarehouseParams) (*Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWarehouse", arg0, arg1)
+	ret0, _ := ret[0].(*Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWarehouse indicates an expected call of CreateWarehouse.
+func (mr *MockProductRepositoryMockRecorder) CreateWarehouse(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWarehouse", reflect.TypeOf((*MockProductRepository)(nil).CreateWarehouse), arg0, arg1)
+}
+
+// DeleteProduct mocks base method.
+func (m *MockProductRepository) DeleteProduct(arg0 context.Context, arg1 DeleteProductParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockProductRepositoryMockRecorder) DeleteProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProductRepository)(nil).DeleteProduct), arg0, arg1)
+}
+
+// DeleteProductTranslation mocks base method.
+func (m *MockProductRepository) DeleteProductTranslation(arg0 context.Context, arg1 string, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductTranslation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductTranslation indicates an expected call of DeleteProductTranslation.
+func (mr *MockProductRepositoryMockRecorder) DeleteProductTranslation(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductTranslation", reflect.TypeOf((*MockProductRepository)(nil).DeleteProductTranslation), arg0, arg1, arg2)
+}
+
+// DeleteProducts mocks base method.
+func (m *MockProductRepository) DeleteProducts(arg0 context.Context, arg1 ProductFilter, arg2 int) (*DeleteProductsResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProducts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*DeleteProductsResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProducts indicates an expected call of DeleteProducts.
+func (mr *MockProductRepositoryMockRecorder) DeleteProducts(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProducts", reflect.TypeOf((*MockProductRepository)(nil).DeleteProducts), arg0, arg1, arg2)
+}
+
+// DeleteSupplier mocks base method.
+func (m *MockProductRepository) DeleteSupplier(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSupplier", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSupplier indicates an expected call of DeleteSupplier.
+func (mr *MockProductRepositoryMockRecorder) DeleteSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSupplier", reflect.TypeOf((*MockProductRepository)(nil).DeleteSupplier), arg0, arg1)
+}
+
+// ExportProducts mocks base method.
+func (m *MockProductRepository) ExportProducts(arg0 context.Context, arg1 SearchProductsParams, arg2 func(*Product) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportProducts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportProducts indicates an expected call of ExportProducts.
+func (mr *MockProductRepositoryMockRecorder) ExportProducts(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportProducts", reflect.TypeOf((*MockProductRepository)(nil).ExportProducts), arg0, arg1, arg2)
+}
+
+// FindSimilarProducts mocks base method.
+func (m *MockProductRepository) FindSimilarProducts(arg0 context.Context, arg1 FindSimilarProductsParams) ([]SimilarProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSimilarProducts", arg0, arg1)
+	ret0, _ := ret[0].([]SimilarProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSimilarProducts indicates an expected call of FindSimilarProducts.
+func (mr *MockProductRepositoryMockRecorder) FindSimilarProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilarProducts", reflect.TypeOf((*MockProductRepository)(nil).FindSimilarProducts), arg0, arg1)
+}
+
+// GetProduct mocks base method.
+func (m *MockProductRepository) GetProduct(arg0 context.Context, arg1 string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProduct", arg0, arg1)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProduct indicates an expected call of GetProduct.
+func (mr *MockProductRepositoryMockRecorder) GetProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockProductRepository)(nil).GetProduct), arg0, arg1)
+}
+
+// GetProductAt mocks base method.
+func (m *MockProductRepository) GetProductAt(arg0 context.Context, arg1 string, arg2 time.Time) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductAt indicates an expected call of GetProductAt.
+func (mr *MockProductRepositoryMockRecorder) GetProductAt(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductAt", reflect.TypeOf((*MockProductRepository)(nil).GetProductAt), arg0, arg1, arg2)
+}
+
+// GetProductBySKU mocks base method.
+func (m *MockProductRepository) GetProductBySKU(arg0 context.Context, arg1 string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductBySKU", arg0, arg1)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductBySKU indicates an expected call of GetProductBySKU.
+func (mr *MockProductRepositoryMockRecorder) GetProductBySKU(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductBySKU", reflect.TypeOf((*MockProductRepository)(nil).GetProductBySKU), arg0, arg1)
+}
+
+// GetProductBySlug mocks base method.
+func (m *MockProductRepository) GetProductBySlug(arg0 context.Context, arg1 string) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductBySlug", arg0, arg1)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductBySlug indicates an expected call of GetProductBySlug.
+func (mr *MockProductRepositoryMockRecorder) GetProductBySlug(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductBySlug", reflect.TypeOf((*MockProductRepository)(nil).GetProductBySlug), arg0, arg1)
+}
+
+// GetProductStats mocks base method.
+func (m *MockProductRepository) GetProductStats(arg0 context.Context, arg1 string) (*ProductStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductStats", arg0, arg1)
+	ret0, _ := ret[0].(*ProductStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductStats indicates an expected call of GetProductStats.
+func (mr *MockProductRepositoryMockRecorder) GetProductStats(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductStats", reflect.TypeOf((*MockProductRepository)(nil).GetProductStats), arg0, arg1)
+}
+
+// GetProductStock mocks base method.
+func (m *MockProductRepository) GetProductStock(arg0 context.Context, arg1 string) ([]*StockLevel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductStock", arg0, arg1)
+	ret0, _ := ret[0].([]*StockLevel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductStock indicates an expected call of GetProductStock.
+func (mr *MockProductRepositoryMockRecorder) GetProductStock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductStock", reflect.TypeOf((*MockProductRepository)(nil).GetProductStock), arg0, arg1)
+}
+
+// GetProductTranslation mocks base method.
+func (m *MockProductRepository) GetProductTranslation(arg0 context.Context, arg1 string, arg2 []string) (*ProductTranslation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductTranslation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*ProductTranslation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductTranslation indicates an expected call of GetProductTranslation.
+func (mr *MockProductRepositoryMockRecorder) GetProductTranslation(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductTranslation", reflect.TypeOf((*MockProductRepository)(nil).GetProductTranslation), arg0, arg1, arg2)
+}
+
+// GetProducts mocks base method.
+func (m *MockProductRepository) GetProducts(arg0 context.Context, arg1 []string) ([]*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProducts", arg0, arg1)
+	ret0, _ := ret[0].([]*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProducts indicates an expected call of GetProducts.
+func (mr *MockProductRepositoryMockRecorder) GetProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockProductRepository)(nil).GetProducts), arg0, arg1)
+}
+
+// GetSupplier mocks base method.
+func (m *MockProductRepository) GetSupplier(arg0 context.Context, arg1 string) (*Supplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSupplier", arg0, arg1)
+	ret0, _ := ret[0].(*Supplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSupplier indicates an expected call of GetSupplier.
+func (mr *MockProductRepositoryMockRecorder) GetSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSupplier", reflect.TypeOf((*MockProductRepository)(nil).GetSupplier), arg0, arg1)
+}
+
+// ListFavorites mocks base method.
+func (m *MockProductRepository) ListFavorites(arg0 context.Context, arg1 ListFavoritesParams) (*ListFavoritesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFavorites", arg0, arg1)
+	ret0, _ := ret[0].(*ListFavoritesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFavorites indicates an expected call of ListFavorites.
+func (mr *MockProductRepositoryMockRecorder) ListFavorites(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFavorites", reflect.TypeOf((*MockProductRepository)(nil).ListFavorites), arg0, arg1)
+}
+
+// ListProductSuppliers mocks base method.
+func (m *MockProductRepository) ListProductSuppliers(arg0 context.Context, arg1 string) ([]*ProductSupplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductSuppliers", arg0, arg1)
+	ret0, _ := ret[0].([]*ProductSupplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductSuppliers indicates an expected call of ListProductSuppliers.
+func (mr *MockProductRepositoryMockRecorder) ListProductSuppliers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductSuppliers", reflect.TypeOf((*MockProductRepository)(nil).ListProductSuppliers), arg0, arg1)
+}
+
+// ListRecentProducts mocks base method.
+func (m *MockProductRepository) ListRecentProducts(arg0 context.Context, arg1 ListRecentProductsParams) (*ListProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecentProducts", arg0, arg1)
+	ret0, _ := ret[0].(*ListProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentProducts indicates an expected call of ListRecentProducts.
+func (mr *MockProductRepositoryMockRecorder) ListRecentProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentProducts", reflect.TypeOf((*MockProductRepository)(nil).ListRecentProducts), arg0, arg1)
+}
+
+// ListRecentlyViewed mocks base method.
+func (m *MockProductRepository) ListRecentlyViewed(arg0 context.Context, arg1 ListRecentlyViewedParams) (*ListProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecentlyViewed", arg0, arg1)
+	ret0, _ := ret[0].(*ListProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentlyViewed indicates an expected call of ListRecentlyViewed.
+func (mr *MockProductRepositoryMockRecorder) ListRecentlyViewed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentlyViewed", reflect.TypeOf((*MockProductRepository)(nil).ListRecentlyViewed), arg0, arg1)
+}
+
+// ListSupplierProducts mocks base method.
+func (m *MockProductRepository) ListSupplierProducts(arg0 context.Context, arg1 ListSupplierProductsParams) (*ListProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSupplierProducts", arg0, arg1)
+	ret0, _ := ret[0].(*ListProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSupplierProducts indicates an expected call of ListSupplierProducts.
+func (mr *MockProductRepositoryMockRecorder) ListSupplierProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSupplierProducts", reflect.TypeOf((*MockProductRepository)(nil).ListSupplierProducts), arg0, arg1)
+}
+
+// ListTenantProducts mocks base method.
+func (m *MockProductRepository) ListTenantProducts(arg0 context.Context, arg1 ListTenantProductsParams) ([]*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTenantProducts", arg0, arg1)
+	ret0, _ := ret[0].([]*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTenantProducts indicates an expected call of ListTenantProducts.
+func (mr *MockProductRepositoryMockRecorder) ListTenantProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTenantProducts", reflect.TypeOf((*MockProductRepository)(nil).ListTenantProducts), arg0, arg1)
+}
+
+// ListTrendingProducts mocks base method.
+func (m *MockProductRepository) ListTrendingProducts(arg0 context.Context, arg1 ListTrendingProductsParams) (*ListProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrendingProducts", arg0, arg1)
+	ret0, _ := ret[0].(*ListProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrendingProducts indicates an expected call of ListTrendingProducts.
+func (mr *MockProductRepositoryMockRecorder) ListTrendingProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrendingProducts", reflect.TypeOf((*MockProductRepository)(nil).ListTrendingProducts), arg0, arg1)
+}
+
+// ListWarehouses mocks base method.
+func (m *MockProductRepository) ListWarehouses(arg0 context.Context) ([]*Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWarehouses", arg0)
+	ret0, _ := ret[0].([]*Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWarehouses indicates an expected call of ListWarehouses.
+func (mr *MockProductRepositoryMockRecorder) ListWarehouses(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWarehouses", reflect.TypeOf((*MockProductRepository)(nil).ListWarehouses), arg0)
+}
+
+// ProductHistory mocks base method.
+func (m *MockProductRepository) ProductHistory(arg0 context.Context, arg1 string) ([]Version, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProductHistory", arg0, arg1)
+	ret0, _ := ret[0].([]Version)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProductHistory indicates an expected call of ProductHistory.
+func (mr *MockProductRepositoryMockRecorder) ProductHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProductHistory", reflect.TypeOf((*MockProductRepository)(nil).ProductHistory), arg0, arg1)
+}
+
+// RecordProductView mocks base method.
+func (m *MockProductRepository) RecordProductView(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordProductView", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordProductView indicates an expected call of RecordProductView.
+func (mr *MockProductRepositoryMockRecorder) RecordProductView(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordProductView", reflect.TypeOf((*MockProductRepository)(nil).RecordProductView), arg0, arg1)
+}
+
+// RecordRecentlyViewed mocks base method.
+func (m *MockProductRepository) RecordRecentlyViewed(arg0 context.Context, arg1 RecentlyViewedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordRecentlyViewed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordRecentlyViewed indicates an expected call of RecordRecentlyViewed.
+func (mr *MockProductRepositoryMockRecorder) RecordRecentlyViewed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRecentlyViewed", reflect.TypeOf((*MockProductRepository)(nil).RecordRecentlyViewed), arg0, arg1)
+}
+
+// RemoveFavorite mocks base method.
+func (m *MockProductRepository) RemoveFavorite(arg0 context.Context, arg1 FavoriteParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFavorite", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFavorite indicates an expected call of RemoveFavorite.
+func (mr *MockProductRepositoryMockRecorder) RemoveFavorite(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFavorite", reflect.TypeOf((*MockProductRepository)(nil).RemoveFavorite), arg0, arg1)
+}
+
+// RemoveProductSupplier mocks base method.
+func (m *MockProductRepository) RemoveProductSupplier(arg0 context.Context, arg1 string, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveProductSupplier", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveProductSupplier indicates an expected call of RemoveProductSupplier.
+func (mr *MockProductRepositoryMockRecorder) RemoveProductSupplier(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProductSupplier", reflect.TypeOf((*MockProductRepository)(nil).RemoveProductSupplier), arg0, arg1, arg2)
+}
+
+// SearchProducts mocks base method.
+func (m *MockProductRepository) SearchProducts(arg0 context.Context, arg1 SearchProductsParams) (*SearchProductsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", arg0, arg1)
+	ret0, _ := ret[0].(*SearchProductsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockProductRepositoryMockRecorder) SearchProducts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockProductRepository)(nil).SearchProducts), arg0, arg1)
+}
+
+// SetProductStock mocks base method.
+func (m *MockProductRepository) SetProductStock(arg0 context.Context, arg1 SetProductStockParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductStock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProductStock indicates an expected call of SetProductStock.
+func (mr *MockProductRepositoryMockRecorder) SetProductStock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductStock", reflect.TypeOf((*MockProductRepository)(nil).SetProductStock), arg0, arg1)
+}
+
+// SetProductSupplier mocks base method.
+func (m *MockProductRepository) SetProductSupplier(arg0 context.Context, arg1 ProductSupplier) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProductSupplier", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProductSupplier indicates an expected call of SetProductSupplier.
+func (mr *MockProductRepositoryMockRecorder) SetProductSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProductSupplier", reflect.TypeOf((*MockProductRepository)(nil).SetProductSupplier), arg0, arg1)
+}
+
+// TransferStock mocks base method.
+func (m *MockProductRepository) TransferStock(arg0 context.Context, arg1 TransferStockParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferStock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferStock indicates an expected call of TransferStock.
+func (mr *MockProductRepositoryMockRecorder) TransferStock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferStock", reflect.TypeOf((*MockProductRepository)(nil).TransferStock), arg0, arg1)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockProductRepository) UpdateProduct(arg0 context.Context, arg1 UpdateProductParams) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", arg0, arg1)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockProductRepositoryMockRecorder) UpdateProduct(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProductRepository)(nil).UpdateProduct), arg0, arg1)
+}
+
+// UpdateSupplier mocks base method.
+func (m *MockProductRepository) UpdateSupplier(arg0 context.Context, arg1 UpdateSupplierParams) (*Supplier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSupplier", arg0, arg1)
+	ret0, _ := ret[0].(*Supplier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSupplier indicates an expected call of UpdateSupplier.
+func (mr *MockProductRepositoryMockRecorder) UpdateSupplier(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSupplier", reflect.TypeOf((*MockProductRepository)(nil).UpdateSupplier), arg0, arg1)
+}
+
+// UpsertProductTranslation mocks base method.
+func (m *MockProductRepository) UpsertProductTranslation(arg0 context.Context, arg1 ProductTranslation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertProductTranslation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertProductTranslation indicates an expected call of UpsertProductTranslation.
+func (mr *MockProductRepositoryMockRecorder) UpsertProductTranslation(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertProductTranslation", reflect.TypeOf((*MockProductRepository)(nil).UpsertProductTranslation), arg0, arg1)
+}
+
+// MockReviewRepository is a mock of ReviewRepository interface.
+type MockReviewRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewRepositoryMockRecorder
+}
+
+// MockReviewRepositoryMockRecorder is the mock recorder for MockReviewRepository.
+type MockReviewRepositoryMockRecorder struct {
+	mock *MockReviewRepository
+}
+
+// NewMockReviewRepository creates a new mock instance.
+func NewMockReviewRepository(ctrl *gomock.Controller) *MockReviewRepository {
+	mock := &MockReviewRepository{ctrl: ctrl}
+	mock.recorder = &MockReviewRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReviewRepository) EXPECT() *MockReviewRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountReviewerReviews mocks base method.
+func (m *MockReviewRepository) CountReviewerReviews(arg0 context.Context, arg1 string, arg2 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReviewerReviews", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReviewerReviews indicates an expected call of CountReviewerReviews.
+func (mr *MockReviewRepositoryMockRecorder) CountReviewerReviews(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReviewerReviews", reflect.TypeOf((*MockReviewRepository)(nil).CountReviewerReviews), arg0, arg1, arg2)
+}
+
+// CreateProductReview mocks base method.
+func (m *MockReviewRepository) CreateProductReview(arg0 context.Context, arg1 CreateProductReviewDBParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductReview", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProductReview indicates an expected call of CreateProductReview.
+func (mr *MockReviewRepositoryMockRecorder) CreateProductReview(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductReview", reflect.TypeOf((*MockReviewRepository)(nil).CreateProductReview), arg0, arg1)
+}
+
+// DeleteProductReview mocks base method.
+func (m *MockReviewRepository) DeleteProductReview(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductReview", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProductReview indicates an expected call of DeleteProductReview.
+func (mr *MockReviewRepositoryMockRecorder) DeleteProductReview(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductReview", reflect.TypeOf((*MockReviewRepository)(nil).DeleteProductReview), arg0, arg1)
+}
+
+// GetProductReview mocks base method.
+func (m *MockReviewRepository) GetProductReview(arg0 context.Context, arg1 string) (*ProductReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductReview", arg0, arg1)
+	ret0, _ := ret[0].(*ProductReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductReview indicates an expected call of GetProductReview.
+func (mr *MockReviewRepositoryMockRecorder) GetProductReview(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductReview", reflect.TypeOf((*MockReviewRepository)(nil).GetProductReview), arg0, arg1)
+}
+
+// GetProductReviews mocks base method.
+func (m *MockReviewRepository) GetProductReviews(arg0 context.Context, arg1 ProductReviewsParams) (*ProductReviewsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductReviews", arg0, arg1)
+	ret0, _ := ret[0].(*ProductReviewsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductReviews indicates an expected call of GetProductReviews.
+func (mr *MockReviewRepositoryMockRecorder) GetProductReviews(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductReviews", reflect.TypeOf((*MockReviewRepository)(nil).GetProductReviews), arg0, arg1)
+}
+
+// PurgeReviewerData mocks base method.
+func (m *MockReviewRepository) PurgeReviewerData(arg0 context.Context, arg1 PurgeReviewerDataParams) (*PurgeReviewerDataResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeReviewerData", arg0, arg1)
+	ret0, _ := ret[0].(*PurgeReviewerDataResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeReviewerData indicates an expected call of PurgeReviewerData.
+func (mr *MockReviewRepositoryMockRecorder) PurgeReviewerData(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeReviewerData", reflect.TypeOf((*MockReviewRepository)(nil).PurgeReviewerData), arg0, arg1)
+}
+
+// ReviewHistory mocks base method.
+func (m *MockReviewRepository) ReviewHistory(arg0 context.Context, arg1 string) ([]Version, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewHistory", arg0, arg1)
+	ret0, _ := ret[0].([]Version)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewHistory indicates an expected call of ReviewHistory.
+func (mr *MockReviewRepositoryMockRecorder) ReviewHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewHistory", reflect.TypeOf((*MockReviewRepository)(nil).ReviewHistory), arg0, arg1)
+}
+
+// UpdateProductReview mocks base method.
+func (m *MockReviewRepository) UpdateProductReview(arg0 context.Context, arg1 UpdateProductReviewParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProductReview", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProductReview indicates an expected call of UpdateProductReview.
+func (mr *MockReviewRepositoryMockRecorder) UpdateProductReview(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductReview", reflect.TypeOf((*MockReviewRepository)(nil).UpdateProductReview), arg0, arg1)
 }
@@ -0,0 +1,34 @@
+package inventory
+
+// PriceBandLimits are the prices from which each price band after the first one starts.
+// Keep in sync with the price_band column of the product table.
+var PriceBandLimits = [...]int{1000, 5000, 10000, 50000}
+
+// PriceBand of products counted by SearchProducts with CountPriceBands.
+type PriceBand struct {
+	// Band number, from 0 for the cheapest products to len(PriceBandLimits) for the most expensive ones.
+	Band int
+
+	// MinPrice and MaxPrice of the products of the band.
+	// MaxPrice is 0 for the last band, which has no upper limit.
+	MinPrice int
+	MaxPrice int
+
+	// Count of products matching the search in the band.
+	Count int
+}
+
+// NewPriceBand returns the price band with the given number, with the count of products in it.
+func NewPriceBand(band, count int) PriceBand {
+	pb := PriceBand{
+		Band:  band,
+		Count: count,
+	}
+	if band > 0 {
+		pb.MinPrice = PriceBandLimits[band-1]
+	}
+	if band < len(PriceBandLimits) {
+		pb.MaxPrice = PriceBandLimits[band] - 1
+	}
+	return pb
+}
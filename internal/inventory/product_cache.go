@@ -0,0 +1,163 @@
+package inventory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// WithProductCache caches the products returned by GetProduct for the given duration,
+// as popular products are read far more often than they change.
+// Products updated or deleted through the cache are evicted, but changes made elsewhere, such as by another instance,
+// or to the stock levels of a product by orders, are only seen once the cached product expires.
+// Responses are shared between callers, and must not be modified.
+func WithProductCache(ttl time.Duration) ServiceMiddleware {
+	return func(next API) API {
+		return &productCache{
+			API:     next,
+			ttl:     ttl,
+			entries: map[string]productCacheEntry{},
+			evicted: map[string]uint64{},
+		}
+	}
+}
+
+// maxProductCacheEntries limits the memory used by the product cache.
+const maxProductCacheEntries = 10000
+
+type productCache struct {
+	API
+
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]productCacheEntry
+
+	// seq is incremented by each eviction, recorded on evicted for the product evicted,
+	// or on evictedAll when every product is, so products read before them aren't cached.
+	seq        uint64
+	evicted    map[string]uint64
+	evictedAll uint64
+}
+
+type productCacheEntry struct {
+	product *Product
+	expires time.Time
+}
+
+func (c *productCache) GetProduct(ctx context.Context, id string) (*Product, error) {
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.entries[id]
+	seq := c.seq
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.product, nil
+	}
+	p, err := c.API.GetProduct(ctx, id)
+	if err != nil || p == nil {
+		return p, err // Products not found aren't cached, so they're found as soon as they're created.
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.evicted[id] > seq || c.evictedAll > seq {
+		return p, nil // The product changed while it was read, so it might be stale.
+	}
+	if len(c.entries) >= maxProductCacheEntries {
+		clear(c.entries)
+	}
+	c.entries[id] = productCacheEntry{
+		product: p,
+		expires: now.Add(c.ttl),
+	}
+	return p, nil
+}
+
+func (c *productCache) UpdateProduct(ctx context.Context, params UpdateProductParams) (*Product, error) {
+	defer c.evict(params.ID)
+	return c.API.UpdateProduct(ctx, params)
+}
+
+func (c *productCache) DeleteProduct(ctx context.Context, params DeleteProductParams) error {
+	defer c.evict(params.ID)
+	return c.API.DeleteProduct(ctx, params)
+}
+
+func (c *productCache) SyncProducts(ctx context.Context, params SyncProductsParams) (*SyncProductsResult, error) {
+	defer c.evictAll()
+	return c.API.SyncProducts(ctx, params)
+}
+
+func (c *productCache) DeleteProducts(ctx context.Context, params DeleteProductsParams) (*DeleteProductsResult, error) {
+	defer c.evictAll()
+	return c.API.DeleteProducts(ctx, params)
+}
+
+func (c *productCache) SetProductStock(ctx context.Context, params SetProductStockParams) error {
+	defer c.evict(params.ProductID)
+	return c.API.SetProductStock(ctx, params)
+}
+
+func (c *productCache) TransferStock(ctx context.Context, params TransferStockParams) error {
+	defer c.evict(params.ProductID)
+	return c.API.TransferStock(ctx, params)
+}
+
+// evict the cached product, after it's changed, even if changing it failed, as it might have changed anyway.
+func (c *productCache) evict(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	if len(c.evicted) >= maxProductCacheEntries {
+		clear(c.evicted)
+		c.evictedAll = c.seq
+	}
+	c.evicted[id] = c.seq
+	delete(c.entries, id)
+}
+
+// evictAll cached products, after changing products in bulk.
+func (c *productCache) evictAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	c.evictedAll = c.seq
+	clear(c.evicted)
+	clear(c.entries)
+}
+
+// warmConcurrency is the number of products read concurrently by WarmProductCache.
+const warmConcurrency = 4
+
+// WarmProductCache reads the n trending products through the API, so they're cached by WithProductCache
+// before serving requests, rather than all read from the database by the first requests after a deploy.
+// It returns the number of products read.
+func WarmProductCache(ctx context.Context, api API, n int) (int, error) {
+	trending, err := api.ListTrendingProducts(ctx, ListTrendingProductsParams{
+		Pagination: Pagination{Limit: n},
+		View:       ProductViewSummary, // Only their IDs are used.
+	})
+	if err != nil {
+		return 0, err
+	}
+	var (
+		g      errgroup.Group
+		mu     sync.Mutex
+		warmed int
+	)
+	g.SetLimit(warmConcurrency)
+	for _, p := range trending.Items {
+		g.Go(func() error {
+			if _, err := api.GetProduct(ctx, p.ID); err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			warmed++
+			return nil
+		})
+	}
+	err = g.Wait()
+	return warmed, err
+}
@@ -0,0 +1,113 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWithProductCache(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	desk := &inventory.Product{ID: "desk", Name: "Desk"}
+	renamed := &inventory.Product{ID: "desk", Name: "Table"}
+	name := "Table"
+	gomock.InOrder(
+		m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "desk").Return(desk, nil),
+		m.EXPECT().UpdateProduct(gomock.Not(gomock.Nil()), inventory.UpdateProductParams{ID: "desk", Name: &name}).Return(renamed, nil),
+		m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "desk").Return(renamed, nil),
+	)
+	// Products not found aren't cached.
+	m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "chair").Return(nil, nil).Times(2)
+
+	api := inventory.Chain(inventory.NewService(m), inventory.WithProductCache(time.Hour))
+	for range 3 {
+		if got, err := api.GetProduct(context.Background(), "desk"); err != nil || got != desk {
+			t.Errorf("GetProduct() = %v, %v, want cached product", got, err)
+		}
+	}
+	for range 2 {
+		if got, err := api.GetProduct(context.Background(), "chair"); err != nil || got != nil {
+			t.Errorf("GetProduct() = %v, %v, want no product", got, err)
+		}
+	}
+	// Updating the product evicts it.
+	if _, err := api.UpdateProduct(context.Background(), inventory.UpdateProductParams{ID: "desk", Name: &name}); err != nil {
+		t.Errorf("UpdateProduct() error = %v", err)
+	}
+	for range 2 {
+		if got, err := api.GetProduct(context.Background(), "desk"); err != nil || got != renamed {
+			t.Errorf("GetProduct() = %v, %v, want updated product", got, err)
+		}
+	}
+}
+
+func TestWithProductCacheConcurrentUpdate(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	desk := &inventory.Product{ID: "desk", Name: "Desk"}
+	renamed := &inventory.Product{ID: "desk", Name: "Table"}
+	name := "Table"
+	reading, updated := make(chan struct{}), make(chan struct{})
+	gomock.InOrder(
+		// The first read returns the product as it was before the update, once the update is done.
+		m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "desk").DoAndReturn(func(ctx context.Context, id string) (*inventory.Product, error) {
+			close(reading)
+			<-updated
+			return desk, nil
+		}),
+		m.EXPECT().UpdateProduct(gomock.Not(gomock.Nil()), inventory.UpdateProductParams{ID: "desk", Name: &name}).Return(renamed, nil),
+		m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "desk").Return(renamed, nil),
+	)
+
+	api := inventory.Chain(inventory.NewService(m), inventory.WithProductCache(time.Hour))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if got, err := api.GetProduct(context.Background(), "desk"); err != nil || got != desk {
+			t.Errorf("GetProduct() = %v, %v, want product read before the update", got, err)
+		}
+	}()
+	<-reading
+	if _, err := api.UpdateProduct(context.Background(), inventory.UpdateProductParams{ID: "desk", Name: &name}); err != nil {
+		t.Errorf("UpdateProduct() error = %v", err)
+	}
+	close(updated)
+	<-done
+
+	// The product read before the update isn't cached.
+	if got, err := api.GetProduct(context.Background(), "desk"); err != nil || got != renamed {
+		t.Errorf("GetProduct() = %v, %v, want updated product", got, err)
+	}
+}
+
+func TestWarmProductCache(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	trending := []*inventory.Product{{ID: "desk"}, {ID: "chair"}, {ID: "table"}}
+	m.EXPECT().ListTrendingProducts(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.ListTrendingProductsParams) (*inventory.ListProductsResponse, error) {
+			if params.Pagination.Limit != 3 {
+				t.Errorf("ListTrendingProducts() called with limit %d, want 3", params.Pagination.Limit)
+			}
+			return &inventory.ListProductsResponse{Items: trending}, nil
+		})
+	// Each product is read from the database once, when warming the cache.
+	for _, p := range trending {
+		m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), p.ID).Return(p, nil)
+	}
+
+	api := inventory.Chain(inventory.NewService(m), inventory.WithProductCache(time.Hour))
+	n, err := inventory.WarmProductCache(context.Background(), api, 3)
+	if err != nil || n != 3 {
+		t.Errorf("WarmProductCache() = %d, %v, want 3 products", n, err)
+	}
+	for _, p := range trending {
+		if got, err := api.GetProduct(context.Background(), p.ID); err != nil || got != p {
+			t.Errorf("GetProduct(%q) = %v, %v, want cached product", p.ID, got, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Quota of the products and reviews a tenant can create. Zero limits are unlimited.
+// Products without a tenant aren't limited.
+type Quota struct {
+	MaxProducts          int
+	MaxReviewsPerProduct int
+}
+
+// TenantQuota set for a tenant, overriding the default quota.
+type TenantQuota struct {
+	Tenant string
+	Quota
+}
+
+// Validate the quota.
+func (q TenantQuota) Validate() error {
+	if q.Tenant == "" || strings.Contains(q.Tenant, TenantSeparator) {
+		return ValidationError{"invalid tenant"}
+	}
+	if q.MaxProducts < 0 || q.MaxReviewsPerProduct < 0 {
+		return ValidationError{"quota limits cannot be negative"}
+	}
+	return nil
+}
+
+// Resources limited by a Quota.
+const (
+	QuotaProducts          = "products"
+	QuotaReviewsPerProduct = "reviews_per_product"
+)
+
+// ErrQuotaExceeded is matched by a *QuotaExceededError with errors.Is.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaExceededError is returned when creating a product or a review would exceed the quota of its tenant.
+type QuotaExceededError struct {
+	Tenant string
+
+	// Resource limited, such as QuotaProducts.
+	Resource string
+
+	Limit int
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota of %s of tenant %q exceeded (limit: %d)", e.Resource, e.Tenant, e.Limit)
+}
+
+// Is returns whether target is ErrQuotaExceeded.
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// QuotaStore of the quotas set for tenants.
+// The store enforces them when products and reviews are created, in the same transaction.
+//
+//go:generate mockgen --build_flags=--mod=mod -package inventory -destination mock_quota_test.go . QuotaStore
+type QuotaStore interface {
+	// GetTenantQuota returns the quota set for a tenant, or nil if the default quota applies.
+	GetTenantQuota(ctx context.Context, tenant string) (*TenantQuota, error)
+
+	// SetTenantQuota sets the quota of a tenant, replacing the one set before, if any.
+	// Lowering it doesn't delete any products or reviews, but no more can be created until they're under it.
+	SetTenantQuota(ctx context.Context, q TenantQuota) error
+
+	// DeleteTenantQuota deletes the quota set for a tenant, so the default quota applies again.
+	DeleteTenantQuota(ctx context.Context, tenant string) error
+}
@@ -0,0 +1,40 @@
+package inventory_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestTenantQuotaValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		q       inventory.TenantQuota
+		wantErr bool
+	}{
+		{q: inventory.TenantQuota{Tenant: "acme"}},
+		{q: inventory.TenantQuota{Tenant: "acme", Quota: inventory.Quota{MaxProducts: 100, MaxReviewsPerProduct: 10}}},
+		{q: inventory.TenantQuota{}, wantErr: true},
+		{q: inventory.TenantQuota{Tenant: "acme:local"}, wantErr: true},
+		{q: inventory.TenantQuota{Tenant: "acme", Quota: inventory.Quota{MaxProducts: -1}}, wantErr: true},
+		{q: inventory.TenantQuota{Tenant: "acme", Quota: inventory.Quota{MaxReviewsPerProduct: -1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := tt.q.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("TenantQuota(%+v).Validate() error = %v, wantErr %v", tt.q, err, tt.wantErr)
+		}
+	}
+}
+
+func TestQuotaExceededError(t *testing.T) {
+	t.Parallel()
+	err := fmt.Errorf("cannot create product: %w", &inventory.QuotaExceededError{Tenant: "acme", Resource: inventory.QuotaProducts, Limit: 100})
+	if !errors.Is(err, inventory.ErrQuotaExceeded) {
+		t.Errorf("errors.Is(%v, ErrQuotaExceeded) = false, want true", err)
+	}
+	if want := `cannot create product: quota of products of tenant "acme" exceeded (limit: 100)`; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+}
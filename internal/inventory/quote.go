@@ -0,0 +1,128 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxQuoteLines is the maximum number of lines of a quote.
+const MaxQuoteLines = 100
+
+// QuoteLine requests a quantity of a product.
+type QuoteLine struct {
+	ProductID string
+	Quantity  int
+}
+
+// QuotedLine of a quote.
+type QuotedLine struct {
+	ProductID string
+	Quantity  int
+
+	// UnitPrice of the product.
+	UnitPrice int
+
+	// Total of the line, with its taxes.
+	Total PriceBreakdown
+}
+
+// Quote for products.
+type Quote struct {
+	Lines []QuotedLine
+
+	// Net, Tax, and Gross sum the totals of the lines.
+	Net   int
+	Tax   int
+	Gross int
+}
+
+// UnavailableProductError is returned when quoting a product that isn't found or isn't active.
+type UnavailableProductError struct {
+	ProductID string
+}
+
+func (e *UnavailableProductError) Error() string {
+	return fmt.Sprintf("product %q is not available", e.ProductID)
+}
+
+func validateQuoteLines(lines []QuoteLine) error {
+	if len(lines) == 0 {
+		return ValidationError{"missing quote lines"}
+	}
+	if len(lines) > MaxQuoteLines {
+		return ValidationError{"too many quote lines"}
+	}
+	seen := make(map[string]bool, len(lines))
+	for _, l := range lines {
+		if l.ProductID == "" {
+			return ValidationError{"missing product ID"}
+		}
+		if l.Quantity < 1 {
+			return ValidationError{"quantity must be at least 1"}
+		}
+		if seen[l.ProductID] {
+			return ValidationError{"duplicate product in quote"}
+		}
+		seen[l.ProductID] = true
+	}
+	return nil
+}
+
+// QuoteProducts returns the price of quantities of products, with taxes applied if a TaxCalculator is set.
+// The products are read at once, and must be active.
+// The tax of each line is computed on its total, rather than on the unit price.
+func (s *Service) QuoteProducts(ctx context.Context, lines []QuoteLine) (*Quote, error) {
+	if err := validateQuoteLines(lines); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(lines))
+	for _, l := range lines {
+		ids = append(ids, l.ProductID)
+	}
+	products, err := s.products.GetProducts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	quote := &Quote{
+		Lines: make([]QuotedLine, 0, len(lines)),
+	}
+	for _, l := range lines {
+		p, ok := byID[l.ProductID]
+		if !ok || p.Status != ProductStatusActive {
+			return nil, &UnavailableProductError{ProductID: l.ProductID}
+		}
+		total, err := s.lineTotal(ctx, p, l.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		quote.Lines = append(quote.Lines, QuotedLine{
+			ProductID: p.ID,
+			Quantity:  l.Quantity,
+			UnitPrice: p.Price,
+			Total:     total,
+		})
+		quote.Net += total.Net
+		quote.Tax += total.Tax
+		quote.Gross += total.Gross
+	}
+	return quote, nil
+}
+
+// lineTotal returns the price breakdown of a quantity of a product.
+func (s *Service) lineTotal(ctx context.Context, p *Product, quantity int) (PriceBreakdown, error) {
+	line := *p
+	line.Price = p.Price * quantity
+	if s.taxCalculator == nil {
+		return PriceBreakdown{Net: line.Price, Gross: line.Price}, nil
+	}
+	b, err := s.taxCalculator.PriceBreakdown(ctx, &line)
+	if err != nil {
+		return PriceBreakdown{}, fmt.Errorf("cannot compute price breakdown: %w", err)
+	}
+	return *b, nil
+}
@@ -0,0 +1,143 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceQuoteProducts(t *testing.T) {
+	t.Parallel()
+	products := []*inventory.Product{
+		{ID: "book", Price: 1999, Status: inventory.ProductStatusActive, TaxClass: "reduced"},
+		{ID: "lamp", Price: 4500, Status: inventory.ProductStatusActive},
+		{ID: "chair", Price: 8000, Status: inventory.ProductStatusDiscontinued},
+	}
+	tests := []struct {
+		name    string
+		lines   []inventory.QuoteLine
+		taxes   inventory.TaxCalculator
+		mock    func(t testing.TB, m *inventory.MockDB)
+		want    *inventory.Quote
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			wantErr: "missing quote lines",
+		},
+		{
+			name: "duplicate",
+			lines: []inventory.QuoteLine{
+				{ProductID: "book", Quantity: 1},
+				{ProductID: "book", Quantity: 2},
+			},
+			wantErr: "duplicate product in quote",
+		},
+		{
+			name:    "invalid_quantity",
+			lines:   []inventory.QuoteLine{{ProductID: "book"}},
+			wantErr: "quantity must be at least 1",
+		},
+		{
+			name: "taxed",
+			lines: []inventory.QuoteLine{
+				{ProductID: "lamp", Quantity: 2},
+				{ProductID: "book", Quantity: 3},
+			},
+			taxes: inventory.TaxTable{"": 2000, "reduced": 500},
+			mock: func(t testing.TB, m *inventory.MockDB) {
+				m.EXPECT().GetProducts(gomock.Not(gomock.Nil()), []string{"lamp", "book"}).Return(products, nil)
+			},
+			want: &inventory.Quote{
+				Lines: []inventory.QuotedLine{
+					{
+						ProductID: "lamp",
+						Quantity:  2,
+						UnitPrice: 4500,
+						Total:     inventory.PriceBreakdown{Net: 9000, Tax: 1800, Gross: 10800, Rate: 2000},
+					},
+					{
+						ProductID: "book",
+						Quantity:  3,
+						UnitPrice: 1999,
+						// The tax is rounded on the line total (5997 * 5% = 299.85), not on the unit price.
+						Total: inventory.PriceBreakdown{Net: 5997, Tax: 300, Gross: 6297, Rate: 500},
+					},
+				},
+				Net:   14997,
+				Tax:   2100,
+				Gross: 17097,
+			},
+		},
+		{
+			name:  "untaxed",
+			lines: []inventory.QuoteLine{{ProductID: "lamp", Quantity: 1}},
+			mock: func(t testing.TB, m *inventory.MockDB) {
+				m.EXPECT().GetProducts(gomock.Not(gomock.Nil()), []string{"lamp"}).Return(products[1:2], nil)
+			},
+			want: &inventory.Quote{
+				Lines: []inventory.QuotedLine{
+					{
+						ProductID: "lamp",
+						Quantity:  1,
+						UnitPrice: 4500,
+						Total:     inventory.PriceBreakdown{Net: 4500, Gross: 4500},
+					},
+				},
+				Net:   4500,
+				Gross: 4500,
+			},
+		},
+		{
+			name: "discontinued",
+			lines: []inventory.QuoteLine{
+				{ProductID: "lamp", Quantity: 1},
+				{ProductID: "chair", Quantity: 1},
+			},
+			mock: func(t testing.TB, m *inventory.MockDB) {
+				m.EXPECT().GetProducts(gomock.Not(gomock.Nil()), []string{"lamp", "chair"}).Return(products[1:], nil)
+			},
+			wantErr: `product "chair" is not available`,
+		},
+		{
+			name:  "not_found",
+			lines: []inventory.QuoteLine{{ProductID: "desk", Quantity: 1}},
+			mock: func(t testing.TB, m *inventory.MockDB) {
+				m.EXPECT().GetProducts(gomock.Not(gomock.Nil()), []string{"desk"}).Return(nil, nil)
+			},
+			wantErr: `product "desk" is not available`,
+		},
+		{
+			name:  "database_error",
+			lines: []inventory.QuoteLine{{ProductID: "lamp", Quantity: 1}},
+			mock: func(t testing.TB, m *inventory.MockDB) {
+				m.EXPECT().GetProducts(gomock.Not(gomock.Nil()), []string{"lamp"}).Return(nil, errors.New("unexpected error"))
+			},
+			wantErr: "unexpected error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := inventory.NewMockDB(gomock.NewController(t))
+			if tt.mock != nil {
+				tt.mock(t, m)
+			}
+			s := inventory.NewService(m)
+			if tt.taxes != nil {
+				s.SetTaxCalculator(tt.taxes)
+			}
+			got, err := s.QuoteProducts(context.Background(), tt.lines)
+			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
+				t.Errorf("Service.QuoteProducts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("Service.QuoteProducts() = %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package inventory
+
+import "context"
+
+// MaxRecentlyViewed is the number of products kept on the recently viewed list of a user.
+// Recording a view beyond it drops the least recently viewed product.
+const MaxRecentlyViewed = 50
+
+// RecentlyViewedParams used by RecordRecentlyViewed.
+type RecentlyViewedParams struct {
+	UserID    string
+	ProductID string
+}
+
+func (p *RecentlyViewedParams) validate() error {
+	if p.UserID == "" {
+		return ValidationError{"missing user ID"}
+	}
+	if p.ProductID == "" {
+		return ValidationError{"missing product ID"}
+	}
+	return nil
+}
+
+// RecordRecentlyViewed moves a product to the top of the recently viewed list of a user.
+func (s *Service) RecordRecentlyViewed(ctx context.Context, params RecentlyViewedParams) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+	return s.products.RecordRecentlyViewed(ctx, params)
+}
+
+// ListRecentlyViewedParams used by ListRecentlyViewed.
+type ListRecentlyViewedParams struct {
+	UserID     string
+	Pagination Pagination
+
+	// View of the products listed (default: ProductViewFull).
+	View ProductView
+}
+
+// ListRecentlyViewed returns the products recently viewed by a user, the most recent first.
+func (s *Service) ListRecentlyViewed(ctx context.Context, params ListRecentlyViewedParams) (*ListProductsResponse, error) {
+	if params.UserID == "" {
+		return nil, ValidationError{"missing user ID"}
+	}
+	if err := params.Pagination.Validate(); err != nil {
+		return nil, err
+	}
+	if err := params.View.validate(); err != nil {
+		return nil, err
+	}
+	return s.products.ListRecentlyViewed(ctx, params)
+}
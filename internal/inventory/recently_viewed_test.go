@@ -0,0 +1,28 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceRecentlyViewedValidation(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewService(inventory.NewMockDB(gomock.NewController(t)))
+	if err := s.RecordRecentlyViewed(context.Background(), inventory.RecentlyViewedParams{ProductID: "product"}); err == nil || err.Error() != "missing user ID" {
+		t.Errorf("Service.RecordRecentlyViewed() error = %v, want missing user ID", err)
+	}
+	if err := s.RecordRecentlyViewed(context.Background(), inventory.RecentlyViewedParams{UserID: "user"}); err == nil || err.Error() != "missing product ID" {
+		t.Errorf("Service.RecordRecentlyViewed() error = %v, want missing product ID", err)
+	}
+	if _, err := s.ListRecentlyViewed(context.Background(), inventory.ListRecentlyViewedParams{UserID: "user"}); err == nil || err.Error() != "pagination limit must be at least 1" {
+		t.Errorf("Service.ListRecentlyViewed() error = %v, want pagination error", err)
+	}
+
+	s.SetReadOnly(true)
+	if err := s.RecordRecentlyViewed(context.Background(), inventory.RecentlyViewedParams{UserID: "user", ProductID: "product"}); err != inventory.ErrReadOnly {
+		t.Errorf("Service.RecordRecentlyViewed() error = %v, want %v", err, inventory.ErrReadOnly)
+	}
+}
@@ -3,7 +3,17 @@ package inventory
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+// Maximum length of the title and description of a review, in characters.
+// They're also enforced by the database.
+const (
+	MaxReviewTitleLength       = 200
+	MaxReviewDescriptionLength = 10000
 )
 
 // ProductReview of a product.
@@ -16,6 +26,28 @@ type ProductReview struct {
 	Description string
 	CreatedAt   time.Time
 	ModifiedAt  time.Time
+
+	// Language is the ISO 639-1 code of the language of the review, or empty if unknown.
+	Language string
+
+	// Flagged is true if the ContentFilter of the service flagged the review for moderation.
+	Flagged bool
+
+	// Sentiment of the review, from MinSentiment to MaxSentiment, or nil if it wasn't scored by the SentimentScorer of the service.
+	Sentiment *float64
+
+	Attachments []Attachment
+}
+
+// ReviewSummary of the reviews of a product, such as their pros and cons, generated in the background.
+// It might not reflect the latest reviews until it's generated again.
+type ReviewSummary struct {
+	Text string
+
+	// Reviews summarized.
+	Reviews int
+
+	GeneratedAt time.Time
 }
 
 // CreateProductReviewParams is used when creating the review of a product.
@@ -25,6 +57,12 @@ type CreateProductReviewParams struct {
 	Score       int
 	Title       string
 	Description string
+
+	// Language of the review. If empty, it's detected from the text by the LanguageDetector of the service.
+	Language string
+
+	// Attachments of the review, up to MaxReviewAttachments.
+	Attachments []Attachment
 }
 
 func (p *CreateProductReviewParams) validate() error {
@@ -37,11 +75,51 @@ func (p *CreateProductReviewParams) validate() error {
 	if err := validateScore(p.Score); err != nil {
 		return err
 	}
-	if p.Title == "" {
-		return ValidationError{"missing review title"}
+	p.Title = normalizeText(p.Title)
+	p.Description = normalizeMultilineText(p.Description)
+	if err := validateReviewTitle(p.Title); err != nil {
+		return err
+	}
+	if err := validateReviewDescription(p.Description); err != nil {
+		return err
+	}
+	if err := validateAttachments(p.Attachments); err != nil {
+		return err
+	}
+	return validateLanguage(p.Language)
+}
+
+// validateReviewTitle checks if the title is set and up to MaxReviewTitleLength characters.
+func validateReviewTitle(title string) error {
+	if title == "" {
+		return &FieldError{Field: "title", Message: "missing review title"}
 	}
-	if p.Description == "" {
-		return ValidationError{"missing review description"}
+	if utf8.RuneCountInString(title) > MaxReviewTitleLength {
+		return &FieldError{Field: "title", Message: fmt.Sprintf("review title is longer than %d characters", MaxReviewTitleLength)}
+	}
+	return nil
+}
+
+// validateReviewDescription checks if the description is set and up to MaxReviewDescriptionLength characters.
+func validateReviewDescription(description string) error {
+	if description == "" {
+		return &FieldError{Field: "description", Message: "missing review description"}
+	}
+	if utf8.RuneCountInString(description) > MaxReviewDescriptionLength {
+		return &FieldError{Field: "description", Message: fmt.Sprintf("review description is longer than %d characters", MaxReviewDescriptionLength)}
+	}
+	return nil
+}
+
+// validateLanguage checks if the language is empty or looks like an ISO 639 code.
+func validateLanguage(language string) error {
+	if language == "" {
+		return nil
+	}
+	if len(language) < 2 || len(language) > 3 || strings.IndexFunc(language, func(r rune) bool {
+		return r < 'a' || r > 'z'
+	}) != -1 {
+		return ValidationError{"invalid language"}
 	}
 	return nil
 }
@@ -54,10 +132,157 @@ func validateScore(score int) error {
 	return nil
 }
 
+// LanguageDetector detects the language of reviews.
+type LanguageDetector interface {
+	// DetectLanguage returns the ISO 639-1 code of the language of the text, or an empty string if it's unknown.
+	DetectLanguage(text string) string
+}
+
+// SetLanguageDetector sets the detector of the language of reviews created or updated without one.
+// It must be called before the service is used.
+func (s *Service) SetLanguageDetector(d LanguageDetector) {
+	s.languageDetector = d
+}
+
+// detectLanguage of a review, if a LanguageDetector is set.
+func (s *Service) detectLanguage(title, description string) string {
+	if s.languageDetector == nil {
+		return ""
+	}
+	return s.languageDetector.DetectLanguage(title + "\n" + description)
+}
+
+// ContentVerdict of a ContentFilter.
+type ContentVerdict int
+
+const (
+	// ContentAllowed is published as is.
+	ContentAllowed ContentVerdict = iota
+
+	// ContentFlagged is published, but flagged for moderation.
+	ContentFlagged
+
+	// ContentRejected isn't published.
+	ContentRejected
+)
+
+// ContentFilter checks the content of reviews before they're created or updated,
+// such as against a list of forbidden words or with an external moderation service.
+//
+//go:generate mockgen --build_flags=--mod=mod -package inventory -destination mock_review_test.go . ContentFilter
+type ContentFilter interface {
+	// FilterContent returns the verdict for the text.
+	// An error fails the request, rather than letting the content through unchecked.
+	FilterContent(ctx context.Context, text string) (ContentVerdict, error)
+}
+
+// ErrContentRejected is returned when the ContentFilter rejects the content of a review.
+var ErrContentRejected = ValidationError{"review content is not allowed"}
+
+// SetContentFilter sets the filter for the content of reviews created or updated.
+// It must be called before the service is used.
+func (s *Service) SetContentFilter(f ContentFilter) {
+	s.contentFilter = f
+}
+
+// filterContent of a review, returning whether it's flagged for moderation, or ErrContentRejected.
+func (s *Service) filterContent(ctx context.Context, title, description string) (flagged bool, err error) {
+	if s.contentFilter == nil {
+		return false, nil
+	}
+	verdict, err := s.contentFilter.FilterContent(ctx, title+"\n"+description)
+	if err != nil {
+		return false, err
+	}
+	switch verdict {
+	case ContentAllowed:
+		return false, nil
+	case ContentFlagged:
+		return true, nil
+	default:
+		return false, ErrContentRejected
+	}
+}
+
+// Range of sentiments, from negative to positive, with 0 being neutral.
+const (
+	MinSentiment = -1
+	MaxSentiment = 1
+)
+
+// SentimentScorer scores the sentiment of reviews, such as with a lexicon or an external model.
+type SentimentScorer interface {
+	// ScoreSentiment returns the sentiment of the text, from MinSentiment to MaxSentiment.
+	// An error fails the request, as with a ContentFilter.
+	ScoreSentiment(ctx context.Context, text string) (float64, error)
+}
+
+// SetSentimentScorer sets the scorer of the sentiment of reviews created or updated.
+// It must be called before the service is used.
+func (s *Service) SetSentimentScorer(scorer SentimentScorer) {
+	s.sentimentScorer = scorer
+}
+
+// scoreSentiment of a review, or nil if no SentimentScorer is set.
+func (s *Service) scoreSentiment(ctx context.Context, title, description string) (*float64, error) {
+	if s.sentimentScorer == nil {
+		return nil, nil
+	}
+	sentiment, err := s.sentimentScorer.ScoreSentiment(ctx, title+"\n"+description)
+	if err != nil {
+		return nil, err
+	}
+	if !(sentiment >= MinSentiment && sentiment <= MaxSentiment) {
+		return nil, fmt.Errorf("sentiment %v out of range", sentiment)
+	}
+	return &sentiment, nil
+}
+
+// ReviewQuota limits how many reviews a reviewer can create within a period, to slow down spam.
+type ReviewQuota struct {
+	// Max number of reviews created within a period. Zero means unlimited.
+	Max int
+
+	// Period of the quota, counting backwards from now.
+	Period time.Duration
+}
+
+// ErrTooManyReviews is returned when a reviewer exceeds the review quota.
+var ErrTooManyReviews = errors.New("too many reviews")
+
+// SetReviewQuota sets the quota of reviews a reviewer can create.
+// It must be called before the service is used.
+func (s *Service) SetReviewQuota(q ReviewQuota) {
+	s.reviewQuota = q
+}
+
+// checkReviewQuota returns ErrTooManyReviews if the reviewer reached the review quota.
+// Concurrent requests might exceed the quota slightly, as reviews are counted before being created.
+// Deleted reviews don't count towards the quota.
+func (s *Service) checkReviewQuota(ctx context.Context, reviewerID string) error {
+	if s.reviewQuota.Max == 0 {
+		return nil
+	}
+	n, err := s.reviews.CountReviewerReviews(ctx, reviewerID, time.Now().Add(-s.reviewQuota.Period))
+	if err != nil {
+		return err
+	}
+	if n >= s.reviewQuota.Max {
+		return ErrTooManyReviews
+	}
+	return nil
+}
+
 // CreateProductReviewParams is used when creating the review of a product in the database.
 type CreateProductReviewDBParams struct {
 	ID string
 	CreateProductReviewParams
+
+	// Flagged for moderation by the ContentFilter.
+	Flagged bool
+
+	// Sentiment scored by the SentimentScorer, if any.
+	Sentiment *float64
 }
 
 // ErrCreateReviewNoProduct is returned when a product review cannot be created because a product is not found.
@@ -65,14 +290,38 @@ var ErrCreateReviewNoProduct = errors.New("cannot find product to create review"
 
 // CreateProductReview of a product.
 func (s *Service) CreateProductReview(ctx context.Context, params CreateProductReviewParams) (id string, err error) {
+	if err := s.writable(); err != nil {
+		return "", err
+	}
 	if err := params.validate(); err != nil {
 		return "", err
 	}
+	// The review belongs to the tenant of the reviewer, which must be the one of the product.
+	tenant, err := sameTenant(params.ReviewerID, params.ProductID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.checkReviewQuota(ctx, params.ReviewerID); err != nil {
+		return "", err
+	}
+	flagged, err := s.filterContent(ctx, params.Title, params.Description)
+	if err != nil {
+		return "", err
+	}
+	if params.Language == "" {
+		params.Language = s.detectLanguage(params.Title, params.Description)
+	}
+	sentiment, err := s.scoreSentiment(ctx, params.Title, params.Description)
+	if err != nil {
+		return "", err
+	}
 
-	id = newID()
-	if err := s.db.CreateProductReview(ctx, CreateProductReviewDBParams{
+	id = newID(tenant)
+	if err := s.reviews.CreateProductReview(ctx, CreateProductReviewDBParams{
 		ID:                        id,
 		CreateProductReviewParams: params,
+		Flagged:                   flagged,
+		Sentiment:                 sentiment,
 	}); err != nil {
 		return "", err
 	}
@@ -85,13 +334,27 @@ type UpdateProductReviewParams struct {
 	Score       *int
 	Title       *string
 	Description *string
+
+	// Language of the review. If nil, it's detected again when the description is updated.
+	Language *string
+
+	// Attachments replacing the existing ones, if not nil.
+	Attachments *[]Attachment
+
+	// Flagged flags the review for moderation. Once flagged, a review stays flagged when updated.
+	// It's set by the ContentFilter of the service, overriding any value given.
+	Flagged bool
+
+	// Sentiment of the review, if not nil.
+	// It's set by the SentimentScorer of the service when the title or description is updated, overriding any value given.
+	Sentiment *float64
 }
 
 func (p *UpdateProductReviewParams) validate() error {
 	if p.ID == "" {
 		return ValidationError{"missing review ID"}
 	}
-	if p.Score == nil && p.Title == nil && p.Description == nil {
+	if p.Score == nil && p.Title == nil && p.Description == nil && p.Language == nil && p.Attachments == nil {
 		return ValidationError{"no product review arguments to update"}
 	}
 	if p.Score != nil {
@@ -99,29 +362,104 @@ func (p *UpdateProductReviewParams) validate() error {
 			return err
 		}
 	}
-	if p.Title != nil && *p.Title == "" {
-		return ValidationError{"missing review title"}
+	p.Title = normalizeOptional(p.Title, normalizeText)
+	p.Description = normalizeOptional(p.Description, normalizeMultilineText)
+	if p.Title != nil {
+		if err := validateReviewTitle(*p.Title); err != nil {
+			return err
+		}
 	}
-	if p.Description != nil && *p.Description == "" {
-		return ValidationError{"missing review description"}
+	if p.Description != nil {
+		if err := validateReviewDescription(*p.Description); err != nil {
+			return err
+		}
+	}
+	if p.Attachments != nil {
+		if err := validateAttachments(*p.Attachments); err != nil {
+			return err
+		}
+	}
+	if p.Language != nil {
+		return validateLanguage(*p.Language)
 	}
 	return nil
 }
 
 // UpdateProductReview of a product.
 func (s *Service) UpdateProductReview(ctx context.Context, params UpdateProductReviewParams) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
 	if err := params.validate(); err != nil {
 		return err
 	}
-	return s.db.UpdateProductReview(ctx, params)
+	params.Flagged = false
+	if params.Title != nil || params.Description != nil {
+		var title, description string
+		if params.Title != nil {
+			title = *params.Title
+		}
+		if params.Description != nil {
+			description = *params.Description
+		}
+		flagged, err := s.filterContent(ctx, title, description)
+		if err != nil {
+			return err
+		}
+		params.Flagged = flagged
+	}
+	if params.Language == nil && params.Description != nil && s.languageDetector != nil {
+		var title string
+		if params.Title != nil {
+			title = *params.Title
+		}
+		language := s.detectLanguage(title, *params.Description)
+		params.Language = &language
+	}
+	params.Sentiment = nil
+	if (params.Title != nil || params.Description != nil) && s.sentimentScorer != nil {
+		sentiment, err := s.scoreUpdatedSentiment(ctx, params)
+		if err != nil {
+			return err
+		}
+		params.Sentiment = sentiment
+	}
+	return s.reviews.UpdateProductReview(ctx, params)
+}
+
+// scoreUpdatedSentiment of a review, scoring its updated title and description along with the current ones not updated.
+// The review might be updated concurrently, but the sentiment of the last update wins, as with the text.
+func (s *Service) scoreUpdatedSentiment(ctx context.Context, params UpdateProductReviewParams) (*float64, error) {
+	var title, description string
+	if params.Title == nil || params.Description == nil {
+		review, err := s.reviews.GetProductReview(ctx, params.ID)
+		if err != nil {
+			return nil, err
+		}
+		if review == nil {
+			// Let the update fail as usual.
+			return nil, nil
+		}
+		title, description = review.Title, review.Description
+	}
+	if params.Title != nil {
+		title = *params.Title
+	}
+	if params.Description != nil {
+		description = *params.Description
+	}
+	return s.scoreSentiment(ctx, title, description)
 }
 
 // DeleteProductReview of a product.
 func (s *Service) DeleteProductReview(ctx context.Context, id string) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
 	if id == "" {
 		return ValidationError{"missing review ID"}
 	}
-	return s.db.DeleteProductReview(ctx, id)
+	return s.reviews.DeleteProductReview(ctx, id)
 }
 
 // GetProductReview gets a product review.
@@ -129,26 +467,110 @@ func (s *Service) GetProductReview(ctx context.Context, id string) (*ProductRevi
 	if id == "" {
 		return nil, ValidationError{"missing review ID"}
 	}
-	return s.db.GetProductReview(ctx, id)
+	return s.reviews.GetProductReview(ctx, id)
 }
 
 // ProductReviewsParams is used to get a list of reviews.
 type ProductReviewsParams struct {
 	ProductID  string
 	ReviewerID string
+
+	// Language filters reviews by language, if set.
+	Language string
+
+	// MinSentiment and MaxSentiment filter reviews by sentiment, if set, excluding the ones without a sentiment.
+	MinSentiment *float64
+	MaxSentiment *float64
+
+	// OrderBy sorts the reviews (default: ReviewsByNewest).
+	OrderBy ReviewOrder
+
+	// EstimateTotal estimates the Total rather than counting the reviews, as with SearchProductsParams.EstimateTotal.
+	EstimateTotal bool
+
 	Pagination Pagination
 }
 
+// ReviewOrder of a list of reviews.
+type ReviewOrder string
+
+// Review orders.
+// Reviews with the same sentiment, or without a sentiment, which come last, are sorted by the newest first.
+const (
+	ReviewsByNewest       ReviewOrder = "newest"
+	ReviewsByMostPositive ReviewOrder = "positive"
+	ReviewsByMostNegative ReviewOrder = "negative"
+)
+
+func (p *ProductReviewsParams) validate() error {
+	if p.ReviewerID == "" && p.ProductID == "" {
+		return ValidationError{"missing params: reviewer_id or product_id are required"}
+	}
+	if err := validateLanguage(p.Language); err != nil {
+		return err
+	}
+	for _, s := range []*float64{p.MinSentiment, p.MaxSentiment} {
+		if s != nil && !(*s >= MinSentiment && *s <= MaxSentiment) {
+			return ValidationError{"invalid sentiment"}
+		}
+	}
+	if p.MinSentiment != nil && p.MaxSentiment != nil && *p.MinSentiment > *p.MaxSentiment {
+		return ValidationError{"invalid sentiment range"}
+	}
+	switch p.OrderBy {
+	case "", ReviewsByNewest, ReviewsByMostPositive, ReviewsByMostNegative:
+	default:
+		return ValidationError{"invalid review order"}
+	}
+	return nil
+}
+
 // ProductReviewsResponse is the response from GetProductReviews.
 type ProductReviewsResponse struct {
 	Reviews []*ProductReview
 	Total   int
+
+	// TotalIsEstimate is set when the Total is an estimate, as requested with EstimateTotal.
+	TotalIsEstimate bool
 }
 
 // GetProductReviews gets a list of reviews.
 func (s *Service) GetProductReviews(ctx context.Context, params ProductReviewsParams) (*ProductReviewsResponse, error) {
-	if params.ReviewerID == "" && params.ProductID == "" {
-		return nil, ValidationError{"missing params: reviewer_id or product_id are required"}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return s.reviews.GetProductReviews(ctx, params)
+}
+
+// AnonymousReviewerID replaces the reviewer ID of anonymized reviews.
+const AnonymousReviewerID = "anonymous"
+
+// PurgeReviewerDataParams used by PurgeReviewerData.
+type PurgeReviewerDataParams struct {
+	ReviewerID string
+
+	// Anonymize reviews rather than deleting them.
+	// Anonymized reviews are kept with their reviewer ID replaced by AnonymousReviewerID.
+	Anonymize bool
+}
+
+// PurgeReviewerDataResult of PurgeReviewerData.
+type PurgeReviewerDataResult struct {
+	// Reviews deleted or anonymized.
+	Reviews int
+}
+
+// PurgeReviewerData deletes or anonymizes all reviews of a reviewer to fulfill a data deletion request.
+// An audit record of the operation is written in the same transaction.
+func (s *Service) PurgeReviewerData(ctx context.Context, params PurgeReviewerDataParams) (*PurgeReviewerDataResult, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	if params.ReviewerID == "" {
+		return nil, ValidationError{"missing reviewer ID"}
+	}
+	if params.ReviewerID == AnonymousReviewerID {
+		return nil, ValidationError{"invalid reviewer ID"}
 	}
-	return s.db.GetProductReviews(ctx, params)
+	return s.reviews.PurgeReviewerData(ctx, params)
 }
@@ -3,6 +3,7 @@ package inventory_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -92,6 +93,20 @@ func TestServiceCreateProductReview(t *testing.T) {
 			},
 			wantErr: "missing review description",
 		},
+		{
+			name: "title_too_long",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.CreateProductReviewParams{
+					ProductID:   "product",
+					ReviewerID:  "customer",
+					Score:       5,
+					Title:       strings.Repeat("á", inventory.MaxReviewTitleLength+1),
+					Description: "I don't really know what to say about this product, and am here just for the points.",
+				},
+			},
+			wantErr: "review title is longer than 200 characters",
+		},
 		{
 			name: "invalid_score",
 			args: args{
@@ -106,6 +121,20 @@ func TestServiceCreateProductReview(t *testing.T) {
 			},
 			wantErr: "invalid score",
 		},
+		{
+			name: "cross_tenant",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.CreateProductReviewParams{
+					ProductID:   "product",
+					ReviewerID:  "acme:customer",
+					Score:       5,
+					Title:       "Anything",
+					Description: "I don't really know what to say about this product, and am here just for the points.",
+				},
+			},
+			wantErr: "cannot reference a record of another tenant",
+		},
 		{
 			name: "success",
 			args: args{
@@ -203,6 +232,224 @@ func TestServiceCreateProductReview(t *testing.T) {
 	}
 }
 
+// languageDetector stub detecting every text as the given language.
+type languageDetector string
+
+func (l languageDetector) DetectLanguage(string) string {
+	return string(l)
+}
+
+func TestServiceProductReviewLanguage(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	m := inventory.NewMockDB(ctrl)
+	m.EXPECT().CreateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+			if params.Language != "en" {
+				t.Errorf("CreateProductReview() called with language %q, want detected language", params.Language)
+			}
+			return nil
+		})
+	m.EXPECT().CreateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+			if params.Language != "pt" {
+				t.Errorf("CreateProductReview() called with language %q, want given language", params.Language)
+			}
+			return nil
+		})
+	m.EXPECT().UpdateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+			if params.Language == nil || *params.Language != "en" {
+				t.Errorf("UpdateProductReview() called with language %v, want detected language", params.Language)
+			}
+			return nil
+		})
+	m.EXPECT().UpdateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+			if params.Language != nil {
+				t.Errorf("UpdateProductReview() called with language %v, want nil as the description isn't updated", *params.Language)
+			}
+			return nil
+		})
+	s := inventory.NewService(m)
+	s.SetLanguageDetector(languageDetector("en"))
+
+	review := inventory.CreateProductReviewParams{
+		ProductID:   "product",
+		ReviewerID:  "reviewer",
+		Score:       5,
+		Title:       "Great",
+		Description: "This is a great product.",
+	}
+	if _, err := s.CreateProductReview(context.Background(), review); err != nil {
+		t.Errorf("Service.CreateProductReview() error = %v", err)
+	}
+	review.Language = "pt"
+	if _, err := s.CreateProductReview(context.Background(), review); err != nil {
+		t.Errorf("Service.CreateProductReview() error = %v", err)
+	}
+	review.Language = "Portuguese"
+	if _, err := s.CreateProductReview(context.Background(), review); err == nil || err.Error() != "invalid language" {
+		t.Errorf("Service.CreateProductReview() error = %v, want invalid language", err)
+	}
+	if err := s.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:          "review",
+		Description: ptr("This is still a great product."),
+	}); err != nil {
+		t.Errorf("Service.UpdateProductReview() error = %v", err)
+	}
+	if err := s.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:    "review",
+		Score: ptr(4),
+	}); err != nil {
+		t.Errorf("Service.UpdateProductReview() error = %v", err)
+	}
+	if _, err := s.GetProductReviews(context.Background(), inventory.ProductReviewsParams{
+		ProductID: "product",
+		Language:  "EN",
+	}); err == nil || err.Error() != "invalid language" {
+		t.Errorf("Service.GetProductReviews() error = %v, want invalid language", err)
+	}
+}
+
+// contentFilter stub rejecting texts containing reject, and flagging texts containing flag.
+type contentFilter struct {
+	reject string
+	flag   string
+}
+
+func (c contentFilter) FilterContent(ctx context.Context, text string) (inventory.ContentVerdict, error) {
+	switch {
+	case strings.Contains(text, "error"):
+		return inventory.ContentAllowed, errors.New("moderation service unavailable")
+	case strings.Contains(text, c.reject):
+		return inventory.ContentRejected, nil
+	case strings.Contains(text, c.flag):
+		return inventory.ContentFlagged, nil
+	default:
+		return inventory.ContentAllowed, nil
+	}
+}
+
+func TestServiceProductReviewContentFilter(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	m := inventory.NewMockDB(ctrl)
+	m.EXPECT().CreateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+			if params.Flagged {
+				t.Errorf("CreateProductReview() called with flagged review, want allowed")
+			}
+			return nil
+		})
+	m.EXPECT().CreateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+			if !params.Flagged {
+				t.Errorf("CreateProductReview() called with allowed review, want flagged")
+			}
+			return nil
+		})
+	m.EXPECT().UpdateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+			if !params.Flagged {
+				t.Errorf("UpdateProductReview() called with allowed review, want flagged")
+			}
+			return nil
+		})
+	m.EXPECT().UpdateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+			if params.Flagged {
+				t.Errorf("UpdateProductReview() called with flagged review, want value given by caller ignored")
+			}
+			return nil
+		})
+	s := inventory.NewService(m)
+	s.SetContentFilter(contentFilter{
+		reject: "scam",
+		flag:   "http",
+	})
+
+	review := inventory.CreateProductReviewParams{
+		ProductID:   "product",
+		ReviewerID:  "reviewer",
+		Score:       5,
+		Title:       "Great",
+		Description: "This is a great product.",
+	}
+	if _, err := s.CreateProductReview(context.Background(), review); err != nil {
+		t.Errorf("Service.CreateProductReview() error = %v", err)
+	}
+	review.Description = "Buy it at http://example.com"
+	if _, err := s.CreateProductReview(context.Background(), review); err != nil {
+		t.Errorf("Service.CreateProductReview() error = %v", err)
+	}
+	review.Title = "A scam"
+	if _, err := s.CreateProductReview(context.Background(), review); err != inventory.ErrContentRejected {
+		t.Errorf("Service.CreateProductReview() error = %v, want %v", err, inventory.ErrContentRejected)
+	}
+	review.Title = "An error"
+	if _, err := s.CreateProductReview(context.Background(), review); err == nil || err.Error() != "moderation service unavailable" {
+		t.Errorf("Service.CreateProductReview() error = %v, want moderation service unavailable", err)
+	}
+	if err := s.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:    "review",
+		Title: ptr("See http://example.com"),
+	}); err != nil {
+		t.Errorf("Service.UpdateProductReview() error = %v", err)
+	}
+	if err := s.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:      "review",
+		Score:   ptr(4),
+		Flagged: true,
+	}); err != nil {
+		t.Errorf("Service.UpdateProductReview() error = %v", err)
+	}
+	if err := s.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:          "review",
+		Description: ptr("What a scam"),
+	}); err != inventory.ErrContentRejected {
+		t.Errorf("Service.UpdateProductReview() error = %v, want %v", err, inventory.ErrContentRejected)
+	}
+}
+
+func TestServiceCreateProductReviewQuota(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	m := inventory.NewMockDB(ctrl)
+	m.EXPECT().CountReviewerReviews(gomock.Not(gomock.Nil()), "reviewer", gomock.Any()).DoAndReturn(
+		func(ctx context.Context, reviewerID string, since time.Time) (int, error) {
+			if d := time.Since(since); d < time.Hour || d > time.Hour+time.Minute {
+				t.Errorf("CountReviewerReviews() called with since %v ago, want an hour ago", d)
+			}
+			return 2, nil
+		})
+	m.EXPECT().CreateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).Return(nil)
+	m.EXPECT().CountReviewerReviews(gomock.Not(gomock.Nil()), "reviewer", gomock.Any()).Return(3, nil)
+	m.EXPECT().CountReviewerReviews(gomock.Not(gomock.Nil()), "reviewer", gomock.Any()).Return(0, errors.New("unexpected error"))
+	s := inventory.NewService(m)
+	s.SetReviewQuota(inventory.ReviewQuota{
+		Max:    3,
+		Period: time.Hour,
+	})
+
+	review := inventory.CreateProductReviewParams{
+		ProductID:   "product",
+		ReviewerID:  "reviewer",
+		Score:       5,
+		Title:       "Great",
+		Description: "This is a great product.",
+	}
+	if _, err := s.CreateProductReview(context.Background(), review); err != nil {
+		t.Errorf("Service.CreateProductReview() error = %v", err)
+	}
+	if _, err := s.CreateProductReview(context.Background(), review); err != inventory.ErrTooManyReviews {
+		t.Errorf("Service.CreateProductReview() error = %v, want %v", err, inventory.ErrTooManyReviews)
+	}
+	if _, err := s.CreateProductReview(context.Background(), review); err == nil || err.Error() != "unexpected error" {
+		t.Errorf("Service.CreateProductReview() error = %v, want unexpected error", err)
+	}
+}
+
 func TestServiceUpdateProductReview(t *testing.T) {
 	t.Parallel()
 	var service = serviceWithPostgres(t)
@@ -287,6 +534,17 @@ func TestServiceUpdateProductReview(t *testing.T) {
 			},
 			wantErr: "missing review description",
 		},
+		{
+			name: "product_review_description_too_long",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.UpdateProductReviewParams{
+					ID:          "no_product_review_desc",
+					Description: ptr(strings.Repeat("a", inventory.MaxReviewDescriptionLength+1)),
+				},
+			},
+			wantErr: "review description is longer than 10000 characters",
+		},
 		{
 			name: "not_found",
 			args: args{
@@ -908,3 +1166,239 @@ func TestServiceGetProductReviews(t *testing.T) {
 		})
 	}
 }
+
+func TestServicePurgeReviewerData(t *testing.T) {
+	t.Parallel()
+	var service = serviceWithPostgres(t)
+	createProducts(t, service, []inventory.CreateProductParams{
+		{
+			ID:          "lamp",
+			Name:        "Lamp",
+			Description: "A desk lamp",
+			Price:       30,
+		},
+	})
+	for _, reviewer := range []string{"forgetme", "forgetme", "keepme"} {
+		createProductReview(t, service, inventory.CreateProductReviewParams{
+			ProductID:   "lamp",
+			ReviewerID:  reviewer,
+			Score:       4,
+			Title:       "Good lamp",
+			Description: "It lights up the room",
+		})
+	}
+
+	type args struct {
+		ctx    context.Context
+		params inventory.PurgeReviewerDataParams
+	}
+	tests := []struct {
+		name    string
+		args    args
+		mock    func(t testing.TB) *inventory.MockDB // Leave as nil for using a real database implementation.
+		want    *inventory.PurgeReviewerDataResult
+		wantErr string
+	}{
+		{
+			name: "missing_reviewer_id",
+			args: args{
+				ctx: context.Background(),
+			},
+			wantErr: "missing reviewer ID",
+		},
+		{
+			name: "anonymous_reviewer_id",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.PurgeReviewerDataParams{
+					ReviewerID: inventory.AnonymousReviewerID,
+				},
+			},
+			wantErr: "invalid reviewer ID",
+		},
+		{
+			name: "purge",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.PurgeReviewerDataParams{
+					ReviewerID: "forgetme",
+				},
+			},
+			want: &inventory.PurgeReviewerDataResult{
+				Reviews: 2,
+			},
+		},
+		{
+			name: "anonymize",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.PurgeReviewerDataParams{
+					ReviewerID: "keepme",
+					Anonymize:  true,
+				},
+			},
+			want: &inventory.PurgeReviewerDataResult{
+				Reviews: 1,
+			},
+		},
+		{
+			name: "canceled_ctx",
+			args: args{
+				ctx: canceledContext(),
+				params: inventory.PurgeReviewerDataParams{
+					ReviewerID: "forgetme",
+				},
+			},
+			wantErr: "context canceled",
+		},
+		{
+			name: "database_error",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.PurgeReviewerDataParams{
+					ReviewerID: "forgetme",
+				},
+			},
+			mock: func(t testing.TB) *inventory.MockDB {
+				ctrl := gomock.NewController(t)
+				m := inventory.NewMockDB(ctrl)
+				m.EXPECT().PurgeReviewerData(gomock.Not(gomock.Nil()), inventory.PurgeReviewerDataParams{
+					ReviewerID: "forgetme",
+				}).Return(nil, errors.New("unexpected error"))
+				return m
+			},
+			wantErr: "unexpected error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// If tt.mock is nil, use real database implementation if available. Otherwise, skip the test.
+			var s = service
+			if tt.mock != nil {
+				s = inventory.NewService(tt.mock(t))
+			} else if s == nil {
+				t.Skip("required database not found, skipping test")
+			}
+			got, err := s.PurgeReviewerData(tt.args.ctx, tt.args.params)
+			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
+				t.Errorf("Service.PurgeReviewerData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("value returned by Service.PurgeReviewerData() doesn't match: %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+
+	if service == nil {
+		return
+	}
+	got, err := service.GetProductReviews(context.Background(), inventory.ProductReviewsParams{
+		ProductID:  "lamp",
+		Pagination: inventory.Pagination{Limit: 10},
+	})
+	if err != nil {
+		t.Fatalf("Service.GetProductReviews() error = %v", err)
+	}
+	if got.Total != 1 || got.Reviews[0].ReviewerID != inventory.AnonymousReviewerID {
+		t.Errorf("expected only the anonymized review to remain, got %+v", got.Reviews)
+	}
+}
+
+// sentimentScorer stub scoring texts by whether they contain "good" or "bad".
+type sentimentScorer struct{}
+
+func (sentimentScorer) ScoreSentiment(ctx context.Context, text string) (float64, error) {
+	switch {
+	case strings.Contains(text, "error"):
+		return 0, errors.New("sentiment service unavailable")
+	case strings.Contains(text, "invalid"):
+		return 2, nil
+	case strings.Contains(text, "good"):
+		return 1, nil
+	case strings.Contains(text, "bad"):
+		return -1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func TestServiceProductReviewSentiment(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	m := inventory.NewMockDB(ctrl)
+	m.EXPECT().CreateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+			if params.Sentiment == nil || *params.Sentiment != 1 {
+				t.Errorf("CreateProductReview() called with sentiment %v, want 1", params.Sentiment)
+			}
+			return nil
+		})
+	// The title is updated, so the sentiment is scored along with the current description.
+	m.EXPECT().GetProductReview(gomock.Not(gomock.Nil()), "review").Return(&inventory.ProductReview{
+		ID:          "review",
+		Title:       "Desk",
+		Description: "A bad desk",
+	}, nil)
+	m.EXPECT().UpdateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+			if params.Sentiment == nil || *params.Sentiment != -1 {
+				t.Errorf("UpdateProductReview() called with sentiment %v, want -1", params.Sentiment)
+			}
+			return nil
+		})
+	m.EXPECT().UpdateProductReview(gomock.Not(gomock.Nil()), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+			if params.Sentiment != nil {
+				t.Errorf("UpdateProductReview() called with sentiment %v, want value given by caller ignored", *params.Sentiment)
+			}
+			return nil
+		})
+	s := inventory.NewService(m)
+	s.SetSentimentScorer(sentimentScorer{})
+
+	review := inventory.CreateProductReviewParams{
+		ProductID:   "product",
+		ReviewerID:  "reviewer",
+		Score:       5,
+		Title:       "Great",
+		Description: "A good desk.",
+	}
+	if _, err := s.CreateProductReview(context.Background(), review); err != nil {
+		t.Errorf("Service.CreateProductReview() error = %v", err)
+	}
+	review.Title = "An error"
+	if _, err := s.CreateProductReview(context.Background(), review); err == nil || err.Error() != "sentiment service unavailable" {
+		t.Errorf("Service.CreateProductReview() error = %v, want sentiment service unavailable", err)
+	}
+	review.Title = "An invalid sentiment"
+	if _, err := s.CreateProductReview(context.Background(), review); err == nil {
+		t.Errorf("Service.CreateProductReview() error = nil, want sentiment out of range")
+	}
+	if err := s.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:    "review",
+		Title: ptr("Not great"),
+	}); err != nil {
+		t.Errorf("Service.UpdateProductReview() error = %v", err)
+	}
+	if err := s.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:        "review",
+		Score:     ptr(4),
+		Sentiment: ptr(1.0),
+	}); err != nil {
+		t.Errorf("Service.UpdateProductReview() error = %v", err)
+	}
+
+	for _, params := range []inventory.ProductReviewsParams{
+		{ProductID: "product", MinSentiment: ptr(-2.0)},
+		{ProductID: "product", MinSentiment: ptr(0.5), MaxSentiment: ptr(0.0)},
+		{ProductID: "product", OrderBy: "score"},
+	} {
+		if _, err := s.GetProductReviews(context.Background(), params); !errors.As(err, &inventory.ValidationError{}) {
+			t.Errorf("Service.GetProductReviews(%+v) error = %v, want validation error", params, err)
+		}
+	}
+}
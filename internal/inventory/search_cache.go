@@ -0,0 +1,163 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheStatus of a call served by a cache, such as SearchProducts with WithSearchCache.
+type CacheStatus string
+
+// Cache status values.
+const (
+	// CacheHit is a fresh cached response.
+	CacheHit CacheStatus = "HIT"
+
+	// CacheStale is an expired cached response, served while it's revalidated in the background.
+	CacheStale CacheStatus = "STALE"
+
+	// CacheMiss is a response that wasn't cached.
+	CacheMiss CacheStatus = "MISS"
+)
+
+type cacheStatusKey struct{}
+
+// WithCacheStatus returns a context recording the CacheStatus of a call made with it,
+// and a function returning the status, or an empty one if the call wasn't served by a cache.
+func WithCacheStatus(ctx context.Context) (context.Context, func() CacheStatus) {
+	var (
+		mu     sync.Mutex
+		status CacheStatus
+	)
+	set := func(s CacheStatus) {
+		mu.Lock()
+		defer mu.Unlock()
+		status = s
+	}
+	return context.WithValue(ctx, cacheStatusKey{}, set), func() CacheStatus {
+		mu.Lock()
+		defer mu.Unlock()
+		return status
+	}
+}
+
+// setCacheStatus of the call made with the context, if it's recorded.
+func setCacheStatus(ctx context.Context, s CacheStatus) {
+	if set, ok := ctx.Value(cacheStatusKey{}).(func(CacheStatus)); ok {
+		set(s)
+	}
+}
+
+// WithSearchCache caches the responses of SearchProducts for the ttl, absorbing bursts of the same popular queries.
+// Expired responses are served for up to stale longer while they're revalidated in the background,
+// and concurrent calls missing the cache for the same query share a single call.
+// The requests are counted by cache status on the meter.
+// Responses are shared between callers, and must not be modified.
+func WithSearchCache(ttl, stale time.Duration, meter metric.Meter) (ServiceMiddleware, error) {
+	requests, err := meter.Int64Counter("inventory.search_cache.requests",
+		metric.WithDescription("Number of product searches by cache status."))
+	if err != nil {
+		return nil, err
+	}
+	return func(next API) API {
+		return &searchCache{
+			API:      next,
+			ttl:      ttl,
+			stale:    stale,
+			requests: requests,
+			entries:  map[string]searchCacheEntry{},
+		}
+	}, nil
+}
+
+// maxSearchCacheEntries limits the memory used by the search cache.
+const maxSearchCacheEntries = 1000
+
+// searchFetchTimeout limits a call shared by the callers missing the cache, and the background revalidation of a stale response.
+const searchFetchTimeout = 10 * time.Second
+
+type searchCache struct {
+	API
+
+	ttl      time.Duration
+	stale    time.Duration
+	requests metric.Int64Counter
+	group    singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]searchCacheEntry
+}
+
+type searchCacheEntry struct {
+	resp    *SearchProductsResponse
+	expires time.Time
+}
+
+func (c *searchCache) SearchProducts(ctx context.Context, params SearchProductsParams) (*SearchProductsResponse, error) {
+	key := fmt.Sprintf("%q:%q:%q:%t:%t:%d:%d:%d:%d:%s:%s:%s", params.QueryString, params.SKUPrefix, params.Text,
+		params.CountPriceBands, params.EstimateTotal, params.MinPrice, params.MaxPrice, params.Pagination.Limit, params.Pagination.Offset, params.OrderBy, strings.Join(params.Locales, ","),
+		params.View)
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	switch {
+	case ok && now.Before(e.expires):
+		c.record(ctx, CacheHit)
+		return e.resp, nil
+	case ok && now.Before(e.expires.Add(c.stale)):
+		c.record(ctx, CacheStale)
+		// Revalidations of the same query are shared, and not waited for.
+		c.group.DoChan(key, func() (any, error) {
+			return c.fetch(ctx, key, params)
+		})
+		return e.resp, nil
+	}
+	c.record(ctx, CacheMiss)
+	ch := c.group.DoChan(key, func() (any, error) {
+		return c.fetch(ctx, key, params)
+	})
+	// Each caller stops waiting when its own context is done, without affecting the others.
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*SearchProductsResponse), nil
+	}
+}
+
+// fetch the response of a query, caching it.
+// The call is shared, so it isn't canceled along with the context of the caller starting it.
+func (c *searchCache) fetch(ctx context.Context, key string, params SearchProductsParams) (*SearchProductsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), searchFetchTimeout)
+	defer cancel()
+	resp, err := c.API.SearchProducts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxSearchCacheEntries {
+		clear(c.entries)
+	}
+	c.entries[key] = searchCacheEntry{
+		resp:    resp,
+		expires: time.Now().Add(c.ttl),
+	}
+	return resp, nil
+}
+
+func (c *searchCache) record(ctx context.Context, s CacheStatus) {
+	setCacheStatus(ctx, s)
+	c.requests.Add(ctx, 1, metric.WithAttributes(attribute.String("status", string(s))))
+}
@@ -0,0 +1,147 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWithSearchCache(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	params := inventory.SearchProductsParams{
+		QueryString: "product",
+		Pagination:  inventory.Pagination{Limit: 10},
+	}
+	resp := &inventory.SearchProductsResponse{Total: 1, Items: []*inventory.Product{{ID: "product"}}}
+	// Called once, as the following calls are served from the cache.
+	m.EXPECT().SearchProducts(gomock.Not(gomock.Nil()), params).Return(resp, nil).Times(1)
+
+	searchCache, err := inventory.WithSearchCache(time.Hour, 0, noop.NewMeterProvider().Meter("test"))
+	if err != nil {
+		t.Fatalf("WithSearchCache() error = %v", err)
+	}
+	api := inventory.Chain(inventory.NewService(m), searchCache)
+	for _, want := range []inventory.CacheStatus{inventory.CacheMiss, inventory.CacheHit, inventory.CacheHit} {
+		ctx, status := inventory.WithCacheStatus(context.Background())
+		got, err := api.SearchProducts(ctx, params)
+		if err != nil || got != resp {
+			t.Errorf("SearchProducts() = %v, %v, want cached response", got, err)
+		}
+		if s := status(); s != want {
+			t.Errorf("got cache status %q, want %q", s, want)
+		}
+	}
+
+	// Invalid searches aren't cached.
+	if _, err := api.SearchProducts(context.Background(), inventory.SearchProductsParams{}); err == nil || err.Error() != "missing search string" {
+		t.Errorf("SearchProducts() error = %v, want validation error", err)
+	}
+}
+
+func TestWithSearchCacheStale(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	params := inventory.SearchProductsParams{
+		QueryString: "product",
+		Pagination:  inventory.Pagination{Limit: 10},
+	}
+	stale := &inventory.SearchProductsResponse{Total: 1}
+	fresh := &inventory.SearchProductsResponse{Total: 2}
+	gomock.InOrder(
+		m.EXPECT().SearchProducts(gomock.Not(gomock.Nil()), params).Return(stale, nil),
+		m.EXPECT().SearchProducts(gomock.Not(gomock.Nil()), params).Return(fresh, nil),
+	)
+
+	const ttl = 50 * time.Millisecond
+	searchCache, err := inventory.WithSearchCache(ttl, time.Hour, noop.NewMeterProvider().Meter("test"))
+	if err != nil {
+		t.Fatalf("WithSearchCache() error = %v", err)
+	}
+	api := inventory.Chain(inventory.NewService(m), searchCache)
+	if got, err := api.SearchProducts(context.Background(), params); err != nil || got != stale {
+		t.Fatalf("SearchProducts() = %v, %v, want response", got, err)
+	}
+	time.Sleep(ttl)
+	ctx, status := inventory.WithCacheStatus(context.Background())
+	if got, err := api.SearchProducts(ctx, params); err != nil || got != stale {
+		t.Errorf("SearchProducts() = %v, %v, want stale response", got, err)
+	}
+	if s := status(); s != inventory.CacheStale {
+		t.Errorf("got cache status %q, want %q", s, inventory.CacheStale)
+	}
+
+	// The revalidated response is fresh, so it isn't revalidated again while it's polled.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := api.SearchProducts(context.Background(), params)
+		if err != nil {
+			t.Fatalf("SearchProducts() error = %v", err)
+		}
+		if got == fresh {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stale response wasn't revalidated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWithSearchCacheCanceledLeader(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	params := inventory.SearchProductsParams{
+		QueryString: "product",
+		Pagination:  inventory.Pagination{Limit: 10},
+	}
+	resp := &inventory.SearchProductsResponse{Total: 1}
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	// Called once, shared by both callers.
+	m.EXPECT().SearchProducts(gomock.Not(gomock.Nil()), params).DoAndReturn(
+		func(ctx context.Context, params inventory.SearchProductsParams) (*inventory.SearchProductsResponse, error) {
+			close(started)
+			<-release
+			return resp, ctx.Err()
+		}).Times(1)
+
+	searchCache, err := inventory.WithSearchCache(time.Hour, 0, noop.NewMeterProvider().Meter("test"))
+	if err != nil {
+		t.Fatalf("WithSearchCache() error = %v", err)
+	}
+	api := inventory.Chain(inventory.NewService(m), searchCache)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := api.SearchProducts(leaderCtx, params)
+		leader <- err
+	}()
+	<-started
+	type result struct {
+		resp *inventory.SearchProductsResponse
+		err  error
+	}
+	follower := make(chan result, 1)
+	go func() {
+		resp, err := api.SearchProducts(context.Background(), params)
+		follower <- result{resp, err}
+	}()
+	time.Sleep(10 * time.Millisecond) // Let the follower join the shared call.
+
+	cancel()
+	if err := <-leader; err != context.Canceled {
+		t.Errorf("SearchProducts() error = %v, want %v", err, context.Canceled)
+	}
+	close(release)
+	if got := <-follower; got.err != nil || got.resp != resp {
+		t.Errorf("SearchProducts() = %v, %v, want shared response", got.resp, got.err)
+	}
+}
@@ -3,37 +3,270 @@ package inventory
 import (
 	"context"
 	"crypto/rand"
+	"errors"
+	"sync/atomic"
+	"time"
 )
 
 // NewService creates an API service.
 func NewService(db DB) *Service {
-	return &Service{db: db}
+	return NewServiceWithRepositories(db, db)
+}
+
+// NewServiceWithRepositories creates an API service using a repository per aggregate.
+// This way, alternative backends only need to implement the repositories they support.
+// Methods of a nil repository return errors.ErrUnsupported.
+func NewServiceWithRepositories(products ProductRepository, reviews ReviewRepository) *Service {
+	if products == nil {
+		products = unsupported{}
+	}
+	if reviews == nil {
+		reviews = unsupported{}
+	}
+	return &Service{
+		products: products,
+		reviews:  reviews,
+	}
 }
 
 // Service for the API.
 type Service struct {
-	db DB
+	products ProductRepository
+	reviews  ReviewRepository
+
+	readOnly         atomic.Bool
+	languageDetector LanguageDetector
+	contentFilter    ContentFilter
+	sentimentScorer  SentimentScorer
+	reviewQuota      ReviewQuota
+	taxCalculator    TaxCalculator
+}
+
+// ErrReadOnly is returned by methods that modify data when the service is in read-only mode.
+var ErrReadOnly = errors.New("service is in read-only mode")
+
+// ErrNoProductHistory is returned by GetProductAt when the state of a product at the given time is unknown.
+var ErrNoProductHistory = errors.New("product history not available")
+
+// SetReadOnly sets whether the service is in read-only mode, rejecting calls that modify data with ErrReadOnly.
+// It's safe to call it while the service is in use, such as to fail over to read replicas.
+func (s *Service) SetReadOnly(readOnly bool) {
+	s.readOnly.Store(readOnly)
+}
+
+// writable returns ErrReadOnly if the service is in read-only mode.
+func (s *Service) writable() error {
+	if s.readOnly.Load() {
+		return ErrReadOnly
+	}
+	return nil
 }
 
-// DB layer.
+// API of the inventory service.
+// It is implemented by Service, and by the ServiceMiddleware decorators wrapping it.
 //
-//go:generate mockgen --build_flags=--mod=mod -package inventory -destination mock_db_test.go . DB
-type DB interface {
-	// CreateProduct creates a new product.
-	CreateProduct(ctx context.Context, params CreateProductParams) error
+//go:generate mockgen --build_flags=--mod=mod -package inventory -destination mock_api_test.go . API
+type API interface {
+	CreateProduct(ctx context.Context, params CreateProductParams) (*CreateProductResult, error)
+	UpdateProduct(ctx context.Context, params UpdateProductParams) (*Product, error)
+	DeleteProduct(ctx context.Context, params DeleteProductParams) error
+	SyncProducts(ctx context.Context, params SyncProductsParams) (*SyncProductsResult, error)
+	DeleteProducts(ctx context.Context, params DeleteProductsParams) (*DeleteProductsResult, error)
+	GetProduct(ctx context.Context, id string) (*Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (*Product, error)
+	GetProductBySKU(ctx context.Context, sku string) (*Product, error)
+	QuoteProducts(ctx context.Context, lines []QuoteLine) (*Quote, error)
+	GetProductAt(ctx context.Context, id string, at time.Time) (*Product, error)
+	GetLocalizedProduct(ctx context.Context, id string, locales []string) (*Product, error)
+	UpsertProductTranslation(ctx context.Context, params ProductTranslation) error
+	DeleteProductTranslation(ctx context.Context, productID, locale string) error
+	ProductHistory(ctx context.Context, id string) ([]Version, error)
+	SearchProducts(ctx context.Context, params SearchProductsParams) (*SearchProductsResponse, error)
+	ExportProducts(ctx context.Context, params SearchProductsParams, fn func(*Product) error) error
+	FindSimilarProducts(ctx context.Context, params FindSimilarProductsParams) ([]SimilarProduct, error)
+	GetProductStats(ctx context.Context, id string) (*ProductStats, error)
+	AddFavorite(ctx context.Context, params FavoriteParams) error
+	RemoveFavorite(ctx context.Context, params FavoriteParams) error
+	ListFavorites(ctx context.Context, params ListFavoritesParams) (*ListFavoritesResponse, error)
+	RecordProductView(ctx context.Context, id string) error
+	ListTrendingProducts(ctx context.Context, params ListTrendingProductsParams) (*ListProductsResponse, error)
+	ListRecentProducts(ctx context.Context, params ListRecentProductsParams) (*ListProductsResponse, error)
+	RecordRecentlyViewed(ctx context.Context, params RecentlyViewedParams) error
+	ListRecentlyViewed(ctx context.Context, params ListRecentlyViewedParams) (*ListProductsResponse, error)
+	CreateSupplier(ctx context.Context, params CreateSupplierParams) (*Supplier, error)
+	UpdateSupplier(ctx context.Context, params UpdateSupplierParams) (*Supplier, error)
+	DeleteSupplier(ctx context.Context, id string) error
+	GetSupplier(ctx context.Context, id string) (*Supplier, error)
+	SetProductSupplier(ctx context.Context, params ProductSupplier) error
+	RemoveProductSupplier(ctx context.Context, productID, supplierID string) error
+	ListProductSuppliers(ctx context.Context, productID string) ([]*ProductSupplier, error)
+	ListSupplierProducts(ctx context.Context, params ListSupplierProductsParams) (*ListProductsResponse, error)
+	CreateWarehouse(ctx context.Context, params CreateWarehouseParams) (*Warehouse, error)
+	ListWarehouses(ctx context.Context) ([]*Warehouse, error)
+	SetProductStock(ctx context.Context, params SetProductStockParams) error
+	TransferStock(ctx context.Context, params TransferStockParams) error
+	GetProductStock(ctx context.Context, productID string) ([]*StockLevel, error)
+	CreateProductReview(ctx context.Context, params CreateProductReviewParams) (id string, err error)
+	UpdateProductReview(ctx context.Context, params UpdateProductReviewParams) error
+	DeleteProductReview(ctx context.Context, id string) error
+	GetProductReview(ctx context.Context, id string) (*ProductReview, error)
+	GetProductReviews(ctx context.Context, params ProductReviewsParams) (*ProductReviewsResponse, error)
+	ReviewHistory(ctx context.Context, id string) ([]Version, error)
+	PurgeReviewerData(ctx context.Context, params PurgeReviewerDataParams) (*PurgeReviewerDataResult, error)
+}
+
+var _ API = (*Service)(nil)
 
-	// UpdateProduct updates an existing product.
-	UpdateProduct(ctx context.Context, params UpdateProductParams) error
+// ProductRepository is the storage layer for products.
+//
+//go:generate mockgen --build_flags=--mod=mod -package inventory -destination mock_db_test.go . DB,ProductRepository,ReviewRepository
+type ProductRepository interface {
+	// CreateProduct creates a new product, or returns the existing one if a product with the same ID already exists.
+	CreateProduct(ctx context.Context, params CreateProductParams) (*CreateProductResult, error)
+
+	// UpdateProduct updates an existing product, returning the updated product.
+	UpdateProduct(ctx context.Context, params UpdateProductParams) (*Product, error)
 
 	// GetProduct returns a product.
 	GetProduct(ctx context.Context, id string) (*Product, error)
 
+	// GetProductBySlug returns a product by its current or a previous slug.
+	GetProductBySlug(ctx context.Context, slug string) (*Product, error)
+
+	// GetProductBySKU returns a product by its SKU.
+	GetProductBySKU(ctx context.Context, sku string) (*Product, error)
+
+	// GetProducts returns the products with the given IDs, in any order.
+	// Products that aren't found are left out.
+	GetProducts(ctx context.Context, ids []string) ([]*Product, error)
+
+	// GetProductAt returns the product as it was at the given time, or nil if it didn't exist then.
+	GetProductAt(ctx context.Context, id string, at time.Time) (*Product, error)
+
+	// UpsertProductTranslation creates or replaces the translation of a product to a locale.
+	// It must return ErrTranslationNoProduct if the product doesn't exist.
+	UpsertProductTranslation(ctx context.Context, params ProductTranslation) error
+
+	// DeleteProductTranslation deletes the translation of a product to a locale.
+	DeleteProductTranslation(ctx context.Context, productID, locale string) error
+
+	// GetProductTranslation returns the translation of a product to the first of the locales it's translated to,
+	// or nil if there is none.
+	GetProductTranslation(ctx context.Context, productID string, locales []string) (*ProductTranslation, error)
+
+	// ProductHistory returns the versions of a product, from the oldest to the newest.
+	ProductHistory(ctx context.Context, id string) ([]Version, error)
+
 	// SearchProducts returns a list of products.
 	SearchProducts(ctx context.Context, params SearchProductsParams) (*SearchProductsResponse, error)
 
+	// ExportProducts calls fn with each product of the page of a search, in order, as it's read from the database.
+	// It stops at the first error returned by fn, and returns it.
+	ExportProducts(ctx context.Context, params SearchProductsParams, fn func(*Product) error) error
+
+	// FindSimilarProducts returns the products of params.Tenant with a name similar to params.Name by trigrams,
+	// with a similarity of at least params.Threshold, the most similar first.
+	FindSimilarProducts(ctx context.Context, params FindSimilarProductsParams) ([]SimilarProduct, error)
+
 	// DeleteProduct deletes a product.
-	DeleteProduct(ctx context.Context, id string) error
+	// Unless params.Force is set, it must return a *HasDependentsError if the product has reviews.
+	DeleteProduct(ctx context.Context, params DeleteProductParams) error
+
+	// ListTenantProducts returns up to params.Limit products of a tenant with an ID after params.After, ordered by ID.
+	ListTenantProducts(ctx context.Context, params ListTenantProductsParams) ([]*Product, error)
+
+	// ApplyProductChanges creates, updates, and deletes products, in a single transaction.
+	ApplyProductChanges(ctx context.Context, changes []ProductChange) error
+
+	// CountProducts returns the number of products matching the filter, up to limit.
+	CountProducts(ctx context.Context, filter ProductFilter, limit int) (int, error)
+
+	// DeleteProducts deletes up to limit products matching the filter, along with their reviews, in a single transaction.
+	// It returns the number of products and reviews deleted.
+	DeleteProducts(ctx context.Context, filter ProductFilter, limit int) (*DeleteProductsResult, error)
+
+	// GetProductStats returns the stats of a product, or nil if it's not found.
+	GetProductStats(ctx context.Context, id string) (*ProductStats, error)
+
+	// AddFavorite adds a product to the favorites of a user, doing nothing if it's already there.
+	// It must return ErrFavoriteNoProduct if the product doesn't exist.
+	AddFavorite(ctx context.Context, params FavoriteParams) error
+
+	// RemoveFavorite removes a product from the favorites of a user.
+	RemoveFavorite(ctx context.Context, params FavoriteParams) error
+
+	// ListFavorites returns the favorites of a user, the most recent first.
+	ListFavorites(ctx context.Context, params ListFavoritesParams) (*ListFavoritesResponse, error)
+
+	// RecordProductView counts a view of a product on the current day.
+	// Views of a product that doesn't exist are ignored.
+	RecordProductView(ctx context.Context, id string) error
+
+	// ListTrendingProducts returns the active products with the most views since params.Since, the most viewed first.
+	ListTrendingProducts(ctx context.Context, params ListTrendingProductsParams) (*ListProductsResponse, error)
+
+	// ListRecentProducts returns the active products, the most recently added first.
+	ListRecentProducts(ctx context.Context, params ListRecentProductsParams) (*ListProductsResponse, error)
+
+	// RecordRecentlyViewed moves a product to the top of the recently viewed list of a user,
+	// dropping the least recently viewed products beyond MaxRecentlyViewed.
+	// Views of a product that doesn't exist are ignored.
+	RecordRecentlyViewed(ctx context.Context, params RecentlyViewedParams) error
 
+	// ListRecentlyViewed returns the products recently viewed by a user, the most recent first.
+	ListRecentlyViewed(ctx context.Context, params ListRecentlyViewedParams) (*ListProductsResponse, error)
+
+	// CreateSupplier creates a new supplier.
+	// It must return ErrSupplierExists if a supplier with the same ID already exists.
+	CreateSupplier(ctx context.Context, params CreateSupplierParams) (*Supplier, error)
+
+	// UpdateSupplier updates an existing supplier, returning the updated supplier.
+	// It must return ErrSupplierNotFound if the supplier doesn't exist.
+	UpdateSupplier(ctx context.Context, params UpdateSupplierParams) (*Supplier, error)
+
+	// DeleteSupplier deletes a supplier, along with its relations to products.
+	// It must return ErrSupplierNotFound if the supplier doesn't exist.
+	DeleteSupplier(ctx context.Context, id string) error
+
+	// GetSupplier returns a supplier, or nil if it's not found.
+	GetSupplier(ctx context.Context, id string) (*Supplier, error)
+
+	// SetProductSupplier adds a supplier to a product, or replaces its lead time and cost if it's already there.
+	// It must return ErrSupplierNoProduct or ErrSupplierNotFound if the product or the supplier doesn't exist.
+	SetProductSupplier(ctx context.Context, params ProductSupplier) error
+
+	// RemoveProductSupplier removes a supplier from a product.
+	RemoveProductSupplier(ctx context.Context, productID, supplierID string) error
+
+	// ListProductSuppliers returns the suppliers of a product, the cheapest first.
+	ListProductSuppliers(ctx context.Context, productID string) ([]*ProductSupplier, error)
+
+	// ListSupplierProducts returns the products of a supplier, regardless of their status, ordered by ID.
+	ListSupplierProducts(ctx context.Context, params ListSupplierProductsParams) (*ListProductsResponse, error)
+
+	// CreateWarehouse creates a new warehouse.
+	// It must return ErrWarehouseExists if a warehouse with the same ID already exists.
+	CreateWarehouse(ctx context.Context, params CreateWarehouseParams) (*Warehouse, error)
+
+	// ListWarehouses returns all warehouses, ordered by ID.
+	ListWarehouses(ctx context.Context) ([]*Warehouse, error)
+
+	// SetProductStock sets the quantity of a product in stock at a warehouse.
+	// It must return ErrStockNoProduct or ErrWarehouseNotFound if the product or the warehouse doesn't exist,
+	// and ErrStockReserved if the quantity is lower than the units reserved.
+	SetProductStock(ctx context.Context, params SetProductStockParams) error
+
+	// TransferStock moves available units of a product from a warehouse to another, atomically.
+	// It must return ErrInsufficientStock if the origin warehouse doesn't have enough units available,
+	// and ErrWarehouseNotFound if the destination warehouse doesn't exist.
+	TransferStock(ctx context.Context, params TransferStockParams) error
+
+	// GetProductStock returns the stock levels of a product at each warehouse stocking it, ordered by warehouse ID.
+	GetProductStock(ctx context.Context, productID string) ([]*StockLevel, error)
+}
+
+// ReviewRepository is the storage layer for product reviews.
+type ReviewRepository interface {
 	// CreateProductReview for a given product.
 	CreateProductReview(ctx context.Context, params CreateProductReviewDBParams) error
 
@@ -46,8 +279,222 @@ type DB interface {
 	// GetProductReviews gets reviews for a given product or from a given user.
 	GetProductReviews(ctx context.Context, params ProductReviewsParams) (*ProductReviewsResponse, error)
 
+	// CountReviewerReviews returns how many reviews a reviewer created since the given time.
+	CountReviewerReviews(ctx context.Context, reviewerID string, since time.Time) (int, error)
+
+	// ReviewHistory returns the versions of a review, from the oldest to the newest.
+	ReviewHistory(ctx context.Context, id string) ([]Version, error)
+
 	// DeleteProductReview deletes a review.
 	DeleteProductReview(ctx context.Context, id string) error
+
+	// PurgeReviewerData deletes or anonymizes all reviews of a reviewer and writes an audit record, in a single transaction.
+	PurgeReviewerData(ctx context.Context, params PurgeReviewerDataParams) (*PurgeReviewerDataResult, error)
+}
+
+// DB layer implementing all repositories.
+type DB interface {
+	ProductRepository
+	ReviewRepository
+}
+
+// unsupported repository used in place of a nil one.
+type unsupported struct{}
+
+func (unsupported) CreateProduct(context.Context, CreateProductParams) (*CreateProductResult, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) UpdateProduct(context.Context, UpdateProductParams) (*Product, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) GetProduct(context.Context, string) (*Product, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) GetProductBySlug(context.Context, string) (*Product, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) GetProductBySKU(context.Context, string) (*Product, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) GetProducts(context.Context, []string) ([]*Product, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) GetProductAt(context.Context, string, time.Time) (*Product, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) UpsertProductTranslation(context.Context, ProductTranslation) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) DeleteProductTranslation(context.Context, string, string) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) GetProductTranslation(context.Context, string, []string) (*ProductTranslation, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) ProductHistory(context.Context, string) ([]Version, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) SearchProducts(context.Context, SearchProductsParams) (*SearchProductsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) ExportProducts(context.Context, SearchProductsParams, func(*Product) error) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) DeleteProduct(context.Context, DeleteProductParams) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) FindSimilarProducts(context.Context, FindSimilarProductsParams) ([]SimilarProduct, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) ListTenantProducts(context.Context, ListTenantProductsParams) ([]*Product, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) ApplyProductChanges(context.Context, []ProductChange) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) CountProducts(context.Context, ProductFilter, int) (int, error) {
+	return 0, errors.ErrUnsupported
+}
+
+func (unsupported) DeleteProducts(context.Context, ProductFilter, int) (*DeleteProductsResult, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) GetProductStats(context.Context, string) (*ProductStats, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) AddFavorite(context.Context, FavoriteParams) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) RemoveFavorite(context.Context, FavoriteParams) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) ListFavorites(context.Context, ListFavoritesParams) (*ListFavoritesResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) RecordProductView(context.Context, string) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) ListTrendingProducts(context.Context, ListTrendingProductsParams) (*ListProductsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) ListRecentProducts(context.Context, ListRecentProductsParams) (*ListProductsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) RecordRecentlyViewed(context.Context, RecentlyViewedParams) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) ListRecentlyViewed(context.Context, ListRecentlyViewedParams) (*ListProductsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) CreateSupplier(context.Context, CreateSupplierParams) (*Supplier, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) UpdateSupplier(context.Context, UpdateSupplierParams) (*Supplier, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) DeleteSupplier(context.Context, string) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) GetSupplier(context.Context, string) (*Supplier, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) SetProductSupplier(context.Context, ProductSupplier) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) RemoveProductSupplier(context.Context, string, string) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) ListProductSuppliers(context.Context, string) ([]*ProductSupplier, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) ListSupplierProducts(context.Context, ListSupplierProductsParams) (*ListProductsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) CreateWarehouse(context.Context, CreateWarehouseParams) (*Warehouse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) ListWarehouses(context.Context) ([]*Warehouse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) SetProductStock(context.Context, SetProductStockParams) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) TransferStock(context.Context, TransferStockParams) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) GetProductStock(context.Context, string) ([]*StockLevel, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) CreateProductReview(context.Context, CreateProductReviewDBParams) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) UpdateProductReview(context.Context, UpdateProductReviewParams) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) GetProductReview(context.Context, string) (*ProductReview, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) GetProductReviews(context.Context, ProductReviewsParams) (*ProductReviewsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) CountReviewerReviews(context.Context, string, time.Time) (int, error) {
+	return 0, errors.ErrUnsupported
+}
+
+func (unsupported) ReviewHistory(context.Context, string) ([]Version, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (unsupported) DeleteProductReview(context.Context, string) error {
+	return errors.ErrUnsupported
+}
+
+func (unsupported) PurgeReviewerData(context.Context, PurgeReviewerDataParams) (*PurgeReviewerDataResult, error) {
+	return nil, errors.ErrUnsupported
 }
 
 // ValidationError is returned when there is an invalid parameter received.
@@ -59,6 +506,52 @@ func (e ValidationError) Error() string {
 	return e.s
 }
 
+// FieldError is a ValidationError of a single field of the parameters, such as a value that is too long.
+// It matches ValidationError with errors.As, so it's handled as any other invalid parameter.
+type FieldError struct {
+	// Field is the name of the invalid field, as seen by clients of the API, such as "title".
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// As sets target to a ValidationError with the message of the error, if it's a *ValidationError.
+func (e *FieldError) As(target any) bool {
+	v, ok := target.(*ValidationError)
+	if ok {
+		*v = ValidationError{e.Message}
+	}
+	return ok
+}
+
+// ConflictError is returned when a valid parameter conflicts with existing data, such as a value that must be unique.
+type ConflictError struct {
+	s string
+}
+
+func (e ConflictError) Error() string {
+	return e.s
+}
+
+// InfrastructureError is returned when a dependency of the service, such as the database, fails transiently,
+// such as when it's unavailable or overloaded, so retrying might succeed.
+// Its message is safe to show to clients, while Err is the cause, kept for logs.
+type InfrastructureError struct {
+	Message string
+	Err     error
+}
+
+func (e *InfrastructureError) Error() string {
+	return e.Message
+}
+
+func (e *InfrastructureError) Unwrap() error {
+	return e.Err
+}
+
 // Pagination is used to paginate results.
 //
 // Usage:
@@ -87,8 +580,8 @@ func (p *Pagination) Validate() error {
 	return nil
 }
 
-// newID generates a random base-58 ID.
-func newID() string {
+// newID generates a random base-58 ID, prefixed by the tenant if it's not empty.
+func newID(tenant string) string {
 	const (
 		alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz" // base58
 		size     = 11
@@ -100,5 +593,5 @@ func newID() string {
 	for i, p := range id {
 		id[i] = alphabet[int(p)%len(alphabet)] // discard everything but the least significant bits
 	}
-	return string(id)
+	return TenantID(tenant, string(id))
 }
@@ -1,6 +1,12 @@
 package inventory
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
 
 func TestPaginationValidate(t *testing.T) {
 	tests := []struct {
@@ -62,3 +68,50 @@ func TestPaginationValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestNewServiceWithRepositories(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	products := NewMockProductRepository(ctrl)
+	products.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "product").Return(&Product{ID: "product"}, nil)
+	s := NewServiceWithRepositories(products, nil)
+
+	got, err := s.GetProduct(context.Background(), "product")
+	if err != nil {
+		t.Errorf("Service.GetProduct() error = %v", err)
+	}
+	if got == nil || got.ID != "product" {
+		t.Errorf("Service.GetProduct() = %v, want product", got)
+	}
+
+	// Methods of the missing review repository are unsupported.
+	if _, err := s.GetProductReview(context.Background(), "review"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Service.GetProductReview() error = %v, want %v", err, errors.ErrUnsupported)
+	}
+}
+
+func TestServiceReadOnly(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	db := NewMockDB(ctrl)
+	db.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "product").Return(&Product{ID: "product"}, nil)
+	s := NewService(db)
+	s.SetReadOnly(true)
+
+	if _, err := s.GetProduct(context.Background(), "product"); err != nil {
+		t.Errorf("Service.GetProduct() error = %v", err)
+	}
+	if _, err := s.CreateProduct(context.Background(), CreateProductParams{ID: "product"}); err != ErrReadOnly {
+		t.Errorf("Service.CreateProduct() error = %v, want %v", err, ErrReadOnly)
+	}
+	if err := s.DeleteProductReview(context.Background(), "review"); err != ErrReadOnly {
+		t.Errorf("Service.DeleteProductReview() error = %v, want %v", err, ErrReadOnly)
+	}
+
+	// Mutations reach the repository again after leaving read-only mode.
+	s.SetReadOnly(false)
+	db.EXPECT().DeleteProductReview(gomock.Not(gomock.Nil()), "review").Return(nil)
+	if err := s.DeleteProductReview(context.Background(), "review"); err != nil {
+		t.Errorf("Service.DeleteProductReview() error = %v", err)
+	}
+}
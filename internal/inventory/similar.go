@@ -0,0 +1,117 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultSimilarityThreshold is the similarity from which the names of products are considered likely duplicates, by default.
+const DefaultSimilarityThreshold = 0.6
+
+// Number of similar products returned by FindSimilarProducts.
+const (
+	defaultSimilarProducts = 10
+	maxSimilarProducts     = 100
+)
+
+// FindSimilarProductsParams used by FindSimilarProducts.
+type FindSimilarProductsParams struct {
+	// Tenant whose products are compared, or empty for the products without a tenant.
+	// Products of other tenants are never considered duplicates.
+	Tenant string
+
+	Name string
+
+	// Threshold of the similarity of the names, greater than 0 and up to 1. Defaults to DefaultSimilarityThreshold.
+	Threshold float64
+
+	// Limit of products returned. Defaults to 10.
+	Limit int
+}
+
+func (p *FindSimilarProductsParams) validate() error {
+	p.Name = normalizeText(p.Name)
+	if p.Name == "" {
+		return ValidationError{"missing product name"}
+	}
+	if p.Threshold == 0 {
+		p.Threshold = DefaultSimilarityThreshold
+	}
+	if !(p.Threshold > 0 && p.Threshold <= 1) {
+		return ValidationError{"invalid similarity threshold"}
+	}
+	if p.Limit == 0 {
+		p.Limit = defaultSimilarProducts
+	}
+	if p.Limit < 0 || p.Limit > maxSimilarProducts {
+		return ValidationError{fmt.Sprintf("limit must be between 1 and %d", maxSimilarProducts)}
+	}
+	return nil
+}
+
+// SimilarProduct found by FindSimilarProducts.
+type SimilarProduct struct {
+	Product *Product
+
+	// Similarity of the name of the product, from 0 to 1, where 1 means the names have the same trigrams.
+	Similarity float64
+}
+
+// FindSimilarProducts returns the products of a tenant with a name similar to params.Name, the most similar first,
+// to detect likely duplicates before creating a product.
+// Names are compared by their trigrams (groups of three consecutive characters), regardless of case,
+// so misspellings and reordered words are still found.
+func (s *Service) FindSimilarProducts(ctx context.Context, params FindSimilarProductsParams) ([]SimilarProduct, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return s.products.FindSimilarProducts(ctx, params)
+}
+
+// DuplicateProductError is returned by CreateProduct with RejectDuplicates when products with a similar name exist.
+// It matches ConflictError with errors.As.
+type DuplicateProductError struct {
+	// Similar products, the most similar first.
+	Similar []SimilarProduct
+}
+
+func (e *DuplicateProductError) Error() string {
+	return fmt.Sprintf("product %q has a similar name", e.Similar[0].Product.ID)
+}
+
+// As sets target to a ConflictError with the message of the error, if it's a *ConflictError.
+func (e *DuplicateProductError) As(target any) bool {
+	v, ok := target.(*ConflictError)
+	if ok {
+		*v = ConflictError{e.Error()}
+	}
+	return ok
+}
+
+// checkDuplicates returns a *DuplicateProductError if other products of the tenant have a name similar to the one of the product.
+func (s *Service) checkDuplicates(ctx context.Context, params CreateProductParams) error {
+	tenant, _, err := ParseID(params.ID)
+	if err != nil {
+		return err
+	}
+	similar, err := s.products.FindSimilarProducts(ctx, FindSimilarProductsParams{
+		Tenant:    tenant,
+		Name:      params.Name,
+		Threshold: DefaultSimilarityThreshold,
+		Limit:     defaultSimilarProducts,
+	})
+	if err != nil {
+		return err
+	}
+	// The product itself isn't a duplicate, as creating it again returns it.
+	var duplicates []SimilarProduct
+	for _, sp := range similar {
+		if sp.Product.ID != params.ID {
+			duplicates = append(duplicates, sp)
+		}
+	}
+	if len(duplicates) != 0 {
+		return &DuplicateProductError{Similar: duplicates}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceFindSimilarProductsValidation(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewService(inventory.NewMockDB(gomock.NewController(t)))
+	tests := []struct {
+		name   string
+		params inventory.FindSimilarProductsParams
+		want   string
+	}{
+		{"missing_name", inventory.FindSimilarProductsParams{Name: " \t"}, "missing product name"},
+		{"negative_threshold", inventory.FindSimilarProductsParams{Name: "Desk", Threshold: -0.5}, "invalid similarity threshold"},
+		{"threshold_above_one", inventory.FindSimilarProductsParams{Name: "Desk", Threshold: 1.5}, "invalid similarity threshold"},
+		{"limit_too_large", inventory.FindSimilarProductsParams{Name: "Desk", Limit: 1000}, "limit must be between 1 and 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := s.FindSimilarProducts(context.Background(), tt.params); err == nil || err.Error() != tt.want {
+				t.Errorf("Service.FindSimilarProducts() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceFindSimilarProducts(t *testing.T) {
+	t.Parallel()
+	db := inventory.NewMockDB(gomock.NewController(t))
+	want := []inventory.SimilarProduct{
+		{Product: &inventory.Product{ID: "acme:desk", Name: "Office desk"}, Similarity: 0.8},
+	}
+	db.EXPECT().FindSimilarProducts(gomock.Any(), inventory.FindSimilarProductsParams{
+		Tenant:    "acme",
+		Name:      "Office desks",
+		Threshold: inventory.DefaultSimilarityThreshold,
+		Limit:     10,
+	}).Return(want, nil)
+	s := inventory.NewService(db)
+	got, err := s.FindSimilarProducts(context.Background(), inventory.FindSimilarProductsParams{
+		Tenant: "acme",
+		Name:   "  Office   desks ",
+	})
+	if err != nil {
+		t.Fatalf("Service.FindSimilarProducts() error = %v", err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("value returned by Service.FindSimilarProducts() doesn't match: %v", cmp.Diff(want, got))
+	}
+}
+
+func TestServiceCreateProductRejectDuplicates(t *testing.T) {
+	t.Parallel()
+	db := inventory.NewMockDB(gomock.NewController(t))
+	similar := []inventory.SimilarProduct{
+		{Product: &inventory.Product{ID: "acme:desk", Name: "Office desk"}, Similarity: 1},
+		{Product: &inventory.Product{ID: "acme:desk2", Name: "Office desks"}, Similarity: 0.8},
+	}
+	db.EXPECT().FindSimilarProducts(gomock.Any(), gomock.Any()).Return(similar, nil).Times(2)
+	s := inventory.NewService(db)
+
+	params := inventory.CreateProductParams{
+		ID:               "acme:desk3",
+		Name:             "Office desk",
+		Description:      "An office desk",
+		RejectDuplicates: true,
+	}
+	_, err := s.CreateProduct(context.Background(), params)
+	var dupErr *inventory.DuplicateProductError
+	if !errors.As(err, &dupErr) || len(dupErr.Similar) != 2 {
+		t.Fatalf("Service.CreateProduct() error = %v, want *DuplicateProductError", err)
+	}
+	if !errors.As(err, &inventory.ConflictError{}) {
+		t.Errorf("Service.CreateProduct() error = %v doesn't match ConflictError", err)
+	}
+	if want := `product "acme:desk" has a similar name`; err.Error() != want {
+		t.Errorf("Service.CreateProduct() error = %q, want %q", err, want)
+	}
+
+	// The product itself isn't a duplicate of the one being created.
+	params.ID = "acme:desk"
+	_, err = s.CreateProduct(context.Background(), params)
+	if !errors.As(err, &dupErr) || len(dupErr.Similar) != 1 || dupErr.Similar[0].Product.ID != "acme:desk2" {
+		t.Errorf("Service.CreateProduct() error = %v, want a duplicate of acme:desk2", err)
+	}
+}
@@ -0,0 +1,88 @@
+package inventory
+
+import "context"
+
+// MaxSKULength is the maximum length of a product SKU.
+const MaxSKULength = 64
+
+var (
+	// ErrDuplicateSKU is returned when another product already has the same SKU.
+	ErrDuplicateSKU = ConflictError{"product SKU already exists"}
+
+	// ErrDuplicateGTIN is returned when another product already has the same GTIN.
+	ErrDuplicateGTIN = ConflictError{"product GTIN already exists"}
+)
+
+// validateSKU checks a SKU (stock keeping unit) is made of ASCII letters and digits,
+// optionally separated by the "-", "_", and "." characters.
+func validateSKU(sku string) error {
+	if sku == "" {
+		return ValidationError{"missing product SKU"}
+	}
+	if len(sku) > MaxSKULength {
+		return ValidationError{"product SKU is too long"}
+	}
+	if !validSKUChars(sku) || !isAlphanumeric(sku[0]) {
+		return ValidationError{"invalid product SKU"}
+	}
+	return nil
+}
+
+func validSKUChars(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isAlphanumeric(c) && c != '-' && c != '_' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// ValidGTIN returns whether s is a GTIN (Global Trade Item Number) with a valid check digit.
+// It accepts the GTIN-8 (EAN-8), GTIN-12 (UPC-A), GTIN-13 (EAN-13), and GTIN-14 formats.
+func ValidGTIN(s string) bool {
+	switch len(s) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+	// Digits are weighted 3 and 1 alternately, starting with 3 on the right of the check digit.
+	sum := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		if i == len(s)-1 {
+			break
+		}
+		d := int(c - '0')
+		if (len(s)-1-i)%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return int(s[len(s)-1]-'0') == (10-sum%10)%10
+}
+
+func validateGTIN(gtin string) error {
+	if !ValidGTIN(gtin) {
+		return ValidationError{"invalid product GTIN"}
+	}
+	return nil
+}
+
+// GetProductBySKU returns a product by its SKU, or nil if it's not found.
+func (s *Service) GetProductBySKU(ctx context.Context, sku string) (*Product, error) {
+	if err := validateSKU(sku); err != nil {
+		return nil, err
+	}
+	p, err := s.products.GetProductBySKU(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+	return s.withPriceBreakdown(ctx, p)
+}
@@ -0,0 +1,106 @@
+package inventory_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestValidGTIN(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		gtin string
+		want bool
+	}{
+		{"96385074", true},       // GTIN-8.
+		{"036000291452", true},   // GTIN-12.
+		{"4006381333931", true},  // GTIN-13.
+		{"10012345678902", true}, // GTIN-14.
+		{"4006381333932", false},
+		{"400638133393", false},
+		{"400638133393a", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := inventory.ValidGTIN(tt.gtin); got != tt.want {
+			t.Errorf("ValidGTIN(%q) = %v, want %v", tt.gtin, got, tt.want)
+		}
+	}
+}
+
+func TestServiceProductSKU(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		params  inventory.CreateProductParams
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			params: inventory.CreateProductParams{SKU: "TBL-01.oak_L", GTIN: "4006381333931"},
+		},
+		{
+			name:    "invalid_sku",
+			params:  inventory.CreateProductParams{SKU: "TBL 01"},
+			wantErr: "invalid product SKU",
+		},
+		{
+			name:    "invalid_sku_start",
+			params:  inventory.CreateProductParams{SKU: "-TBL"},
+			wantErr: "invalid product SKU",
+		},
+		{
+			name:    "long_sku",
+			params:  inventory.CreateProductParams{SKU: strings.Repeat("A", inventory.MaxSKULength+1)},
+			wantErr: "product SKU is too long",
+		},
+		{
+			name:    "invalid_gtin",
+			params:  inventory.CreateProductParams{GTIN: "4006381333932"},
+			wantErr: "invalid product GTIN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := inventory.NewMockDB(gomock.NewController(t))
+			if tt.wantErr == "" {
+				m.EXPECT().CreateProduct(gomock.Not(gomock.Nil()), gomock.Any()).Return(&inventory.CreateProductResult{}, nil)
+			}
+			s := inventory.NewService(m)
+			tt.params.ID, tt.params.Name, tt.params.Description = "table", "Table", "A table"
+			if _, err := s.CreateProduct(context.Background(), tt.params); err == nil && tt.wantErr != "" || err != nil && err.Error() != tt.wantErr {
+				t.Errorf("Service.CreateProduct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == "" {
+				return
+			}
+			sku, gtin := tt.params.SKU, tt.params.GTIN
+			if _, err := s.UpdateProduct(context.Background(), inventory.UpdateProductParams{ID: "table", SKU: &sku, GTIN: &gtin}); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Service.UpdateProduct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceGetProductBySKU(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	want := &inventory.Product{ID: "table", SKU: "TBL-01"}
+	m.EXPECT().GetProductBySKU(gomock.Not(gomock.Nil()), "TBL-01").Return(want, nil)
+	s := inventory.NewService(m)
+
+	if _, err := s.GetProductBySKU(context.Background(), ""); err == nil || err.Error() != "missing product SKU" {
+		t.Errorf("Service.GetProductBySKU() error = %v, want missing product SKU", err)
+	}
+	got, err := s.GetProductBySKU(context.Background(), "TBL-01")
+	if err != nil || got != want {
+		t.Errorf("Service.GetProductBySKU() = %v, %v, want %v", got, err, want)
+	}
+	if _, err := s.SearchProducts(context.Background(), inventory.SearchProductsParams{SKUPrefix: "TBL%"}); err == nil || err.Error() != "invalid SKU prefix" {
+		t.Errorf("Service.SearchProducts() error = %v, want invalid SKU prefix", err)
+	}
+}
@@ -0,0 +1,185 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Supplier of products.
+type Supplier struct {
+	ID         string
+	Name       string
+	Email      string
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+// CreateSupplierParams used by CreateSupplier.
+type CreateSupplierParams struct {
+	ID    string
+	Name  string
+	Email string
+}
+
+func (p *CreateSupplierParams) validate() error {
+	if p.ID == "" {
+		return ValidationError{"missing supplier ID"}
+	}
+	if p.Name == "" {
+		return ValidationError{"missing supplier name"}
+	}
+	return nil
+}
+
+// UpdateSupplierParams used by UpdateSupplier.
+type UpdateSupplierParams struct {
+	ID    string
+	Name  *string
+	Email *string
+}
+
+func (p *UpdateSupplierParams) validate() error {
+	if p.ID == "" {
+		return ValidationError{"missing supplier ID"}
+	}
+	if p.Name == nil && p.Email == nil {
+		return ValidationError{"no supplier arguments to update"}
+	}
+	if p.Name != nil && *p.Name == "" {
+		return ValidationError{"missing supplier name"}
+	}
+	return nil
+}
+
+var (
+	// ErrSupplierExists is returned when a supplier with the same ID already exists.
+	ErrSupplierExists = errors.New("supplier already exists")
+
+	// ErrSupplierNotFound is returned when a supplier to update, delete, or relate to a product is not found.
+	ErrSupplierNotFound = errors.New("supplier not found")
+
+	// ErrSupplierNoProduct is returned when a supplier cannot be related to a product because the product is not found.
+	ErrSupplierNoProduct = errors.New("cannot find product to add supplier to")
+)
+
+// CreateSupplier creates a new supplier.
+func (s *Service) CreateSupplier(ctx context.Context, params CreateSupplierParams) (*Supplier, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return s.products.CreateSupplier(ctx, params)
+}
+
+// UpdateSupplier updates an existing supplier.
+func (s *Service) UpdateSupplier(ctx context.Context, params UpdateSupplierParams) (*Supplier, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	return s.products.UpdateSupplier(ctx, params)
+}
+
+// DeleteSupplier deletes a supplier, along with its relations to products.
+func (s *Service) DeleteSupplier(ctx context.Context, id string) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if id == "" {
+		return ValidationError{"missing supplier ID"}
+	}
+	return s.products.DeleteSupplier(ctx, id)
+}
+
+// GetSupplier returns a supplier, or nil if it's not found.
+func (s *Service) GetSupplier(ctx context.Context, id string) (*Supplier, error) {
+	if id == "" {
+		return nil, ValidationError{"missing supplier ID"}
+	}
+	return s.products.GetSupplier(ctx, id)
+}
+
+// ProductSupplier relates a product to one of its suppliers.
+type ProductSupplier struct {
+	ProductID  string
+	SupplierID string
+
+	// LeadTime between ordering the product from the supplier and receiving it, in whole days.
+	LeadTime time.Duration
+
+	// Cost of a unit of the product from the supplier, in the same unit of the product price.
+	Cost int
+
+	ModifiedAt time.Time
+}
+
+func (p *ProductSupplier) validate() error {
+	if p.ProductID == "" {
+		return ValidationError{"missing product ID"}
+	}
+	if p.SupplierID == "" {
+		return ValidationError{"missing supplier ID"}
+	}
+	if p.LeadTime < 0 || p.LeadTime%(24*time.Hour) != 0 {
+		return ValidationError{"lead time must be a non-negative number of days"}
+	}
+	if p.Cost < 0 {
+		return ValidationError{"cost cannot be negative"}
+	}
+	return nil
+}
+
+// SetProductSupplier adds a supplier to a product, or replaces its lead time and cost if it's already there.
+func (s *Service) SetProductSupplier(ctx context.Context, params ProductSupplier) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+	return s.products.SetProductSupplier(ctx, params)
+}
+
+// RemoveProductSupplier removes a supplier from a product.
+func (s *Service) RemoveProductSupplier(ctx context.Context, productID, supplierID string) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if productID == "" {
+		return ValidationError{"missing product ID"}
+	}
+	if supplierID == "" {
+		return ValidationError{"missing supplier ID"}
+	}
+	return s.products.RemoveProductSupplier(ctx, productID, supplierID)
+}
+
+// ListProductSuppliers returns the suppliers of a product, the cheapest first.
+func (s *Service) ListProductSuppliers(ctx context.Context, productID string) ([]*ProductSupplier, error) {
+	if productID == "" {
+		return nil, ValidationError{"missing product ID"}
+	}
+	return s.products.ListProductSuppliers(ctx, productID)
+}
+
+// ListSupplierProductsParams used by ListSupplierProducts.
+type ListSupplierProductsParams struct {
+	SupplierID string
+	Pagination Pagination
+}
+
+// ListSupplierProducts returns the products of a supplier, regardless of their status, ordered by ID.
+func (s *Service) ListSupplierProducts(ctx context.Context, params ListSupplierProductsParams) (*ListProductsResponse, error) {
+	if params.SupplierID == "" {
+		return nil, ValidationError{"missing supplier ID"}
+	}
+	if err := params.Pagination.Validate(); err != nil {
+		return nil, err
+	}
+	return s.products.ListSupplierProducts(ctx, params)
+}
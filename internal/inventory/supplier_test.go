@@ -0,0 +1,46 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceSuppliersValidation(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewService(inventory.NewMockDB(gomock.NewController(t)))
+	if _, err := s.CreateSupplier(context.Background(), inventory.CreateSupplierParams{ID: "acme"}); err == nil || err.Error() != "missing supplier name" {
+		t.Errorf("Service.CreateSupplier() error = %v, want missing supplier name", err)
+	}
+	if _, err := s.UpdateSupplier(context.Background(), inventory.UpdateSupplierParams{ID: "acme"}); err == nil || err.Error() != "no supplier arguments to update" {
+		t.Errorf("Service.UpdateSupplier() error = %v, want no supplier arguments to update", err)
+	}
+	if err := s.SetProductSupplier(context.Background(), inventory.ProductSupplier{
+		ProductID:  "product",
+		SupplierID: "acme",
+		LeadTime:   36 * time.Hour,
+	}); err == nil || err.Error() != "lead time must be a non-negative number of days" {
+		t.Errorf("Service.SetProductSupplier() error = %v, want lead time error", err)
+	}
+	if err := s.SetProductSupplier(context.Background(), inventory.ProductSupplier{
+		ProductID:  "product",
+		SupplierID: "acme",
+		Cost:       -1,
+	}); err == nil || err.Error() != "cost cannot be negative" {
+		t.Errorf("Service.SetProductSupplier() error = %v, want cost cannot be negative", err)
+	}
+	if err := s.RemoveProductSupplier(context.Background(), "product", ""); err == nil || err.Error() != "missing supplier ID" {
+		t.Errorf("Service.RemoveProductSupplier() error = %v, want missing supplier ID", err)
+	}
+	if _, err := s.ListSupplierProducts(context.Background(), inventory.ListSupplierProductsParams{SupplierID: "acme"}); err == nil || err.Error() != "pagination limit must be at least 1" {
+		t.Errorf("Service.ListSupplierProducts() error = %v, want pagination error", err)
+	}
+
+	s.SetReadOnly(true)
+	if err := s.DeleteSupplier(context.Background(), "acme"); err != inventory.ErrReadOnly {
+		t.Errorf("Service.DeleteSupplier() error = %v, want %v", err, inventory.ErrReadOnly)
+	}
+}
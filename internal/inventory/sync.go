@@ -0,0 +1,236 @@
+package inventory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// DefaultSyncBatchSize is the number of changes applied by each transaction of SyncProducts, by default.
+const DefaultSyncBatchSize = 500
+
+// syncPageSize is the number of products read at a time to diff a snapshot against.
+const syncPageSize = 1000
+
+// SyncProductsParams used by SyncProducts.
+type SyncProductsParams struct {
+	// Tenant whose products are synced, or empty for the products without a tenant.
+	// Products of other tenants are never modified.
+	Tenant string
+
+	// Snapshot of all the products of the tenant, such as exported by an ERP.
+	// Products missing from it are deleted.
+	Snapshot []CreateProductParams
+
+	// ForceDelete deletes products missing from the snapshot along with their reviews.
+	// Otherwise, deleting a product with reviews fails with a *HasDependentsError.
+	ForceDelete bool
+
+	// DryRun returns the planned changes without applying them.
+	DryRun bool
+
+	// BatchSize is the maximum number of changes applied by each transaction. Defaults to DefaultSyncBatchSize.
+	BatchSize int
+}
+
+func (p *SyncProductsParams) validate() error {
+	if p.BatchSize < 0 {
+		return ValidationError{"batch size cannot be negative"}
+	}
+	// The products are normalized by validating them, so they're copied first, as the snapshot belongs to the caller.
+	p.Snapshot = slices.Clone(p.Snapshot)
+	ids := make(map[string]struct{}, len(p.Snapshot))
+	for i := range p.Snapshot {
+		product := &p.Snapshot[i]
+		if err := product.validate(); err != nil {
+			return ValidationError{fmt.Sprintf("invalid product %q: %v", product.ID, err)}
+		}
+		if tenant, _, _ := ParseID(product.ID); tenant != p.Tenant {
+			return ValidationError{fmt.Sprintf("product %q doesn't belong to the tenant", product.ID)}
+		}
+		if _, ok := ids[product.ID]; ok {
+			return ValidationError{fmt.Sprintf("duplicate product %q", product.ID)}
+		}
+		ids[product.ID] = struct{}{}
+	}
+	return nil
+}
+
+// ProductChangeKind is the kind of change made to a product by SyncProducts.
+type ProductChangeKind string
+
+// Product change kinds.
+const (
+	ProductCreate ProductChangeKind = "create"
+	ProductUpdate ProductChangeKind = "update"
+	ProductDelete ProductChangeKind = "delete"
+)
+
+// ProductChange planned or applied by SyncProducts.
+type ProductChange struct {
+	Kind ProductChangeKind
+	ID   string
+
+	// Create has the product to create, Update the fields that changed, and Delete the product to delete,
+	// depending on the Kind.
+	Create *CreateProductParams
+	Update *UpdateProductParams
+	Delete *DeleteProductParams
+}
+
+// SyncProductsResult of SyncProducts.
+type SyncProductsResult struct {
+	// Changes to sync the products with the snapshot, in the order they're applied:
+	// deletions, updates, then creations, so a SKU or GTIN can move from a deleted product to a new one.
+	Changes []ProductChange
+
+	// Applied changes, which are all of them unless it's a dry run or a batch failed.
+	Applied int
+}
+
+// ListTenantProductsParams used by ListTenantProducts.
+type ListTenantProductsParams struct {
+	// Tenant whose products are listed, or empty for the products without a tenant.
+	Tenant string
+
+	// After is the ID the products listed come after, to iterate over all of them.
+	After string
+	Limit int
+}
+
+// SyncProducts diffs a snapshot of all the products of a tenant against the current ones,
+// creating, updating, and deleting products to match it.
+//
+// Changes are applied in batches of params.BatchSize, each in a transaction of its own, so syncing a large catalog
+// doesn't hold locks for long. If a batch fails, the result has the changes applied by the previous batches,
+// which are kept, and syncing the same snapshot again resumes from there.
+func (s *Service) SyncProducts(ctx context.Context, params SyncProductsParams) (*SyncProductsResult, error) {
+	if !params.DryRun {
+		if err := s.writable(); err != nil {
+			return nil, err
+		}
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	classes := map[string]struct{}{}
+	for _, p := range params.Snapshot {
+		if _, ok := classes[p.TaxClass]; ok {
+			continue
+		}
+		if err := s.checkTaxClass(ctx, p.TaxClass); err != nil {
+			return nil, err
+		}
+		classes[p.TaxClass] = struct{}{}
+	}
+	changes, err := s.planProductChanges(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	result := &SyncProductsResult{
+		Changes: changes,
+	}
+	if params.DryRun {
+		return result, nil
+	}
+	size := cmp.Or(params.BatchSize, DefaultSyncBatchSize)
+	for len(changes) > 0 {
+		batch := changes[:min(size, len(changes))]
+		if err := s.products.ApplyProductChanges(ctx, batch); err != nil {
+			return result, err
+		}
+		result.Applied += len(batch)
+		changes = changes[len(batch):]
+	}
+	return result, nil
+}
+
+// planProductChanges returns the changes to make the products of the tenant match the snapshot.
+func (s *Service) planProductChanges(ctx context.Context, params SyncProductsParams) ([]ProductChange, error) {
+	snapshot := make(map[string]*CreateProductParams, len(params.Snapshot))
+	for i := range params.Snapshot {
+		snapshot[params.Snapshot[i].ID] = &params.Snapshot[i]
+	}
+	var deletes, updates, creates []ProductChange
+	seen := make(map[string]struct{}, len(params.Snapshot))
+	list := ListTenantProductsParams{
+		Tenant: params.Tenant,
+		Limit:  syncPageSize,
+	}
+	for {
+		products, err := s.products.ListTenantProducts(ctx, list)
+		if err != nil {
+			return nil, err
+		}
+		for _, current := range products {
+			want, ok := snapshot[current.ID]
+			if !ok {
+				deletes = append(deletes, ProductChange{
+					Kind:   ProductDelete,
+					ID:     current.ID,
+					Delete: &DeleteProductParams{ID: current.ID, Force: params.ForceDelete},
+				})
+				continue
+			}
+			seen[current.ID] = struct{}{}
+			if update := productDiff(current, want); update != nil {
+				updates = append(updates, ProductChange{
+					Kind:   ProductUpdate,
+					ID:     current.ID,
+					Update: update,
+				})
+			}
+		}
+		if len(products) < list.Limit {
+			break
+		}
+		list.After = products[len(products)-1].ID
+	}
+	for i := range params.Snapshot {
+		want := &params.Snapshot[i]
+		if _, ok := seen[want.ID]; !ok {
+			creates = append(creates, ProductChange{
+				Kind:   ProductCreate,
+				ID:     want.ID,
+				Create: want,
+			})
+		}
+	}
+	slices.SortFunc(creates, func(a, b ProductChange) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return slices.Concat(deletes, updates, creates), nil
+}
+
+// productDiff returns the update of the fields of the current product that differ from the wanted one,
+// or nil if they're the same.
+func productDiff(current *Product, want *CreateProductParams) *UpdateProductParams {
+	var (
+		update  = &UpdateProductParams{ID: current.ID}
+		changed bool
+	)
+	diff := func(field **string, from, to string) {
+		if from != to {
+			*field = &to
+			changed = true
+		}
+	}
+	diff(&update.Name, current.Name, want.Name)
+	diff(&update.Description, current.Description, want.Description)
+	diff(&update.SKU, current.SKU, want.SKU)
+	diff(&update.GTIN, current.GTIN, want.GTIN)
+	diff(&update.TaxClass, current.TaxClass, want.TaxClass)
+	if current.Price != want.Price {
+		update.Price = &want.Price
+		changed = true
+	}
+	if status := cmp.Or(want.Status, ProductStatusActive); current.Status != status {
+		update.Status = &status
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return update
+}
@@ -0,0 +1,117 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceSyncProductsValidation(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewService(inventory.NewMockDB(gomock.NewController(t)))
+	tests := []struct {
+		name   string
+		params inventory.SyncProductsParams
+		want   string
+	}{
+		{
+			name: "invalid_product",
+			params: inventory.SyncProductsParams{
+				Snapshot: []inventory.CreateProductParams{{ID: "desk", Name: "Desk"}},
+			},
+			want: `invalid product "desk": missing product description`,
+		},
+		{
+			name: "duplicate",
+			params: inventory.SyncProductsParams{
+				Snapshot: []inventory.CreateProductParams{
+					{ID: "desk", Name: "Desk", Description: "A desk"},
+					{ID: "desk", Name: "Desk", Description: "A desk"},
+				},
+			},
+			want: `duplicate product "desk"`,
+		},
+		{
+			name: "other_tenant",
+			params: inventory.SyncProductsParams{
+				Tenant:   "acme",
+				Snapshot: []inventory.CreateProductParams{{ID: "other:desk", Name: "Desk", Description: "A desk"}},
+			},
+			want: `product "other:desk" doesn't belong to the tenant`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := s.SyncProducts(context.Background(), tt.params)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Service.SyncProducts() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceSyncProducts(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	db := inventory.NewMockDB(ctrl)
+	db.EXPECT().ListTenantProducts(gomock.Any(), inventory.ListTenantProductsParams{Tenant: "acme", Limit: 1000}).Return([]*inventory.Product{
+		{ID: "acme:chair", Name: "Chair", Description: "A chair", Price: 40, Status: inventory.ProductStatusActive},
+		{ID: "acme:desk", Name: "Desk", Description: "A desk", Price: 100, Status: inventory.ProductStatusActive},
+		{ID: "acme:lamp", Name: "Lamp", Description: "A lamp", Price: 20, Status: inventory.ProductStatusActive},
+	}, nil)
+	snapshot := []inventory.CreateProductParams{
+		{ID: "acme:table", Name: "Table", Description: "A table", Price: 200},
+		{ID: "acme:desk", Name: "Desk", Description: "A desk", Price: 120},
+		{ID: "acme:chair", Name: "Chair", Description: "A chair", Price: 40},
+	}
+	want := []inventory.ProductChange{
+		{
+			Kind:   inventory.ProductDelete,
+			ID:     "acme:lamp",
+			Delete: &inventory.DeleteProductParams{ID: "acme:lamp"},
+		},
+		{
+			Kind:   inventory.ProductUpdate,
+			ID:     "acme:desk",
+			Update: &inventory.UpdateProductParams{ID: "acme:desk", Price: ptr(120)},
+		},
+		{
+			Kind:   inventory.ProductCreate,
+			ID:     "acme:table",
+			Create: &snapshot[0],
+		},
+	}
+	gomock.InOrder(
+		db.EXPECT().ApplyProductChanges(gomock.Any(), want[:2]).Return(nil),
+		db.EXPECT().ApplyProductChanges(gomock.Any(), want[2:]).Return(errors.New("unexpected error")),
+	)
+	s := inventory.NewService(db)
+	got, err := s.SyncProducts(context.Background(), inventory.SyncProductsParams{
+		Tenant:    "acme",
+		Snapshot:  snapshot,
+		BatchSize: 2,
+	})
+	if err == nil || err.Error() != "unexpected error" {
+		t.Errorf("Service.SyncProducts() error = %v, want unexpected error", err)
+	}
+	if wantResult := (&inventory.SyncProductsResult{Changes: want, Applied: 2}); !cmp.Equal(wantResult, got) {
+		t.Errorf("value returned by Service.SyncProducts() doesn't match: %v", cmp.Diff(wantResult, got))
+	}
+
+	// A dry run only plans the changes, even in read-only mode.
+	s.SetReadOnly(true)
+	db.EXPECT().ListTenantProducts(gomock.Any(), gomock.Any()).Return(nil, nil)
+	got, err = s.SyncProducts(context.Background(), inventory.SyncProductsParams{
+		Tenant:   "acme",
+		Snapshot: snapshot[:1],
+		DryRun:   true,
+	})
+	if err != nil || len(got.Changes) != 1 || got.Applied != 0 {
+		t.Errorf("Service.SyncProducts() = (%+v, %v), want one planned change", got, err)
+	}
+}
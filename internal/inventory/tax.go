@@ -0,0 +1,139 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxTaxClassLength is the maximum length of a tax class.
+const MaxTaxClassLength = 32
+
+// PriceBreakdown of a price into its net value and taxes.
+type PriceBreakdown struct {
+	// Net price, before taxes. It's the price of the product.
+	Net int
+
+	// Tax on the net price.
+	Tax int
+
+	// Gross price, including taxes.
+	Gross int
+
+	// Rate of the tax applied.
+	Rate TaxRate
+}
+
+// TaxRate in basis points (hundredths of a percent), such as 2000 for 20%.
+type TaxRate int
+
+// String returns the rate as a percentage, such as "5.5%".
+func (r TaxRate) String() string {
+	return strconv.FormatFloat(float64(r)/100, 'f', -1, 64) + "%"
+}
+
+// ErrUnknownTaxClass is returned by a TaxCalculator for a tax class it doesn't know.
+var ErrUnknownTaxClass = errors.New("unknown tax class")
+
+// TaxCalculator computes the taxes on product prices, such as according to regional rules.
+type TaxCalculator interface {
+	// PriceBreakdown returns the net price, tax, and gross price of a product.
+	// It must return ErrUnknownTaxClass if the tax class of the product is unknown.
+	PriceBreakdown(ctx context.Context, p *Product) (*PriceBreakdown, error)
+}
+
+// TaxTable is a TaxCalculator applying a rate per tax class.
+// The rate of the default tax class, used by products without one, is keyed by an empty string.
+type TaxTable map[string]TaxRate
+
+// PriceBreakdown returns the net price, tax, and gross price of a product.
+// The tax is rounded half up to the smaller subdivision of the price.
+func (t TaxTable) PriceBreakdown(ctx context.Context, p *Product) (*PriceBreakdown, error) {
+	rate, ok := t[p.TaxClass]
+	if !ok {
+		return nil, ErrUnknownTaxClass
+	}
+	tax := (p.Price*int(rate) + 5000) / 10000
+	return &PriceBreakdown{
+		Net:   p.Price,
+		Tax:   tax,
+		Gross: p.Price + tax,
+		Rate:  rate,
+	}, nil
+}
+
+// ParseTaxTable parses a comma-separated list of tax classes and their rates as percentages,
+// with the default tax class left unnamed (example: =20,reduced=5.5,exempt=0).
+func ParseTaxTable(s string) (TaxTable, error) {
+	t := TaxTable{}
+	if s == "" {
+		return t, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		class, percentage, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tax rate %q: missing percentage", entry)
+		}
+		if class != "" {
+			if err := validateTaxClass(class); err != nil {
+				return nil, fmt.Errorf("invalid tax rate %q: %w", entry, err)
+			}
+		}
+		f, err := strconv.ParseFloat(percentage, 64)
+		if err != nil || f < 0 || f > 100 {
+			return nil, fmt.Errorf("invalid tax rate %q: percentage must be a number between 0 and 100", entry)
+		}
+		if _, ok := t[class]; ok {
+			return nil, fmt.Errorf("duplicate tax class %q", class)
+		}
+		t[class] = TaxRate(math.Round(f * 100))
+	}
+	return t, nil
+}
+
+// validateTaxClass checks a tax class is made of lowercase ASCII letters, digits, "-", and "_".
+func validateTaxClass(class string) error {
+	if len(class) > MaxTaxClassLength {
+		return ValidationError{"tax class is too long"}
+	}
+	for i := 0; i < len(class); i++ {
+		if c := class[i]; !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return ValidationError{"invalid tax class"}
+		}
+	}
+	return nil
+}
+
+// SetTaxCalculator sets the calculator of the price breakdown of products.
+// Without one, products have no price breakdown and any tax class is accepted.
+// It must be called before the service is used.
+func (s *Service) SetTaxCalculator(c TaxCalculator) {
+	s.taxCalculator = c
+}
+
+// checkTaxClass returns a ValidationError if the TaxCalculator doesn't know the tax class.
+func (s *Service) checkTaxClass(ctx context.Context, class string) error {
+	if s.taxCalculator == nil || class == "" {
+		return nil
+	}
+	_, err := s.taxCalculator.PriceBreakdown(ctx, &Product{TaxClass: class})
+	if errors.Is(err, ErrUnknownTaxClass) {
+		return ValidationError{"unknown tax class"}
+	}
+	return err
+}
+
+// withPriceBreakdown sets the price breakdown of a product, if a TaxCalculator is set.
+func (s *Service) withPriceBreakdown(ctx context.Context, p *Product) (*Product, error) {
+	if p == nil || s.taxCalculator == nil {
+		return p, nil
+	}
+	var err error
+	if p.PriceBreakdown, err = s.taxCalculator.PriceBreakdown(ctx, p); err != nil {
+		return nil, fmt.Errorf("cannot compute price breakdown: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,129 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTaxTablePriceBreakdown(t *testing.T) {
+	t.Parallel()
+	taxes := inventory.TaxTable{"": 2000, "reduced": 550}
+	tests := []struct {
+		name    string
+		product inventory.Product
+		want    *inventory.PriceBreakdown
+		wantErr error
+	}{
+		{
+			name:    "default",
+			product: inventory.Product{Price: 1000},
+			want:    &inventory.PriceBreakdown{Net: 1000, Tax: 200, Gross: 1200, Rate: 2000},
+		},
+		{
+			name:    "rounded_half_up",
+			product: inventory.Product{Price: 10, TaxClass: "reduced"},
+			want:    &inventory.PriceBreakdown{Net: 10, Tax: 1, Gross: 11, Rate: 550},
+		},
+		{
+			name:    "unknown",
+			product: inventory.Product{Price: 10, TaxClass: "luxury"},
+			wantErr: inventory.ErrUnknownTaxClass,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := taxes.PriceBreakdown(context.Background(), &tt.product)
+			if err != tt.wantErr {
+				t.Errorf("TaxTable.PriceBreakdown() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("TaxTable.PriceBreakdown() = %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestParseTaxTable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in      string
+		want    inventory.TaxTable
+		wantErr string
+	}{
+		{
+			in:   "",
+			want: inventory.TaxTable{},
+		},
+		{
+			in:   "=20,reduced=5.5,exempt=0",
+			want: inventory.TaxTable{"": 2000, "reduced": 550, "exempt": 0},
+		},
+		{
+			in:      "reduced",
+			wantErr: `invalid tax rate "reduced": missing percentage`,
+		},
+		{
+			in:      "Reduced=5",
+			wantErr: `invalid tax rate "Reduced=5": invalid tax class`,
+		},
+		{
+			in:      "reduced=101",
+			wantErr: `invalid tax rate "reduced=101": percentage must be a number between 0 and 100`,
+		},
+		{
+			in:      "=20,=10",
+			wantErr: `duplicate tax class ""`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := inventory.ParseTaxTable(tt.in)
+		if err == nil && tt.wantErr != "" || err != nil && err.Error() != tt.wantErr {
+			t.Errorf("ParseTaxTable(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if !cmp.Equal(tt.want, got) {
+			t.Errorf("ParseTaxTable(%q) = %v", tt.in, cmp.Diff(tt.want, got))
+		}
+	}
+}
+
+func TestServiceTaxes(t *testing.T) {
+	t.Parallel()
+	m := inventory.NewMockDB(gomock.NewController(t))
+	m.EXPECT().GetProduct(gomock.Not(gomock.Nil()), "book").Return(&inventory.Product{
+		ID:       "book",
+		Price:    1000,
+		TaxClass: "reduced",
+	}, nil)
+	s := inventory.NewService(m)
+	s.SetTaxCalculator(inventory.TaxTable{"": 2000, "reduced": 500})
+
+	got, err := s.GetProduct(context.Background(), "book")
+	if err != nil {
+		t.Fatalf("Service.GetProduct() error = %v", err)
+	}
+	want := &inventory.PriceBreakdown{Net: 1000, Tax: 50, Gross: 1050, Rate: 500}
+	if !cmp.Equal(want, got.PriceBreakdown) {
+		t.Errorf("Service.GetProduct() price breakdown = %v", cmp.Diff(want, got.PriceBreakdown))
+	}
+
+	if _, err := s.CreateProduct(context.Background(), inventory.CreateProductParams{
+		ID:          "yacht",
+		Name:        "Yacht",
+		Description: "A yacht",
+		Price:       100000,
+		TaxClass:    "luxury",
+	}); err == nil || err.Error() != "unknown tax class" {
+		t.Errorf("Service.CreateProduct() error = %v, want unknown tax class", err)
+	}
+	if _, err := s.UpdateProduct(context.Background(), inventory.UpdateProductParams{
+		ID:       "book",
+		TaxClass: ptr("Reduced"),
+	}); err == nil || err.Error() != "invalid tax class" {
+		t.Errorf("Service.UpdateProduct() error = %v, want invalid tax class", err)
+	}
+}
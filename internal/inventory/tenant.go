@@ -0,0 +1,46 @@
+package inventory
+
+import "strings"
+
+// TenantSeparator separates the tenant from the local ID in a tenant-prefixed ID, such as "acme:desk".
+// IDs without it don't belong to any tenant, as in a single-tenant deployment.
+const TenantSeparator = ":"
+
+// TenantID returns the ID of a record of a tenant, given its local ID.
+// If the tenant is empty, the local ID is returned.
+func TenantID(tenant, local string) string {
+	if tenant == "" {
+		return local
+	}
+	return tenant + TenantSeparator + local
+}
+
+// ParseID splits an ID into its tenant and its local ID.
+// The tenant of an ID without a TenantSeparator is empty.
+func ParseID(id string) (tenant, local string, err error) {
+	tenant, local, ok := strings.Cut(id, TenantSeparator)
+	if !ok {
+		return "", id, nil
+	}
+	if tenant == "" || local == "" || strings.Contains(local, TenantSeparator) {
+		return "", "", ValidationError{"invalid tenant-prefixed ID"}
+	}
+	return tenant, local, nil
+}
+
+// sameTenant returns the tenant of the record with the given ID,
+// or a ValidationError if the ID is invalid or the record references one of another tenant.
+func sameTenant(id, reference string) (string, error) {
+	tenant, _, err := ParseID(id)
+	if err != nil {
+		return "", err
+	}
+	other, _, err := ParseID(reference)
+	if err != nil {
+		return "", err
+	}
+	if tenant != other {
+		return "", ValidationError{"cannot reference a record of another tenant"}
+	}
+	return tenant, nil
+}
@@ -0,0 +1,37 @@
+package inventory_test
+
+import (
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestParseID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		id         string
+		wantTenant string
+		wantLocal  string
+		wantErr    bool
+	}{
+		{id: "desk", wantLocal: "desk"},
+		{id: "acme:desk", wantTenant: "acme", wantLocal: "desk"},
+		{id: ":desk", wantErr: true},
+		{id: "acme:", wantErr: true},
+		{id: "acme:desk:oak", wantErr: true},
+	}
+	for _, tt := range tests {
+		tenant, local, err := inventory.ParseID(tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+		}
+		if tenant != tt.wantTenant || local != tt.wantLocal {
+			t.Errorf("ParseID(%q) = (%q, %q), want (%q, %q)", tt.id, tenant, local, tt.wantTenant, tt.wantLocal)
+		}
+		if err == nil {
+			if got := inventory.TenantID(tenant, local); got != tt.id {
+				t.Errorf("TenantID(%q, %q) = %q, want %q", tenant, local, got, tt.id)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package inventory
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizeText returns the text in Unicode NFC, without control characters or invalid UTF-8,
+// and with runs of whitespace collapsed into a single space and trimmed,
+// so single-line values, such as names and titles, are stored consistently for search and display.
+func normalizeText(s string) string {
+	return normalize(s, false)
+}
+
+// normalizeMultilineText is like normalizeText, but keeps line breaks, such as between the paragraphs of a description.
+// Lines are trimmed, line and paragraph separators are normalized to "\n",
+// and runs of blank lines are collapsed into a single one.
+func normalizeMultilineText(s string) string {
+	return normalize(s, true)
+}
+
+// normalizeOptional returns a pointer to the normalized text s points to, or nil if s is nil.
+// The text s points to is left untouched, as it belongs to the caller.
+func normalizeOptional(s *string, normalize func(string) string) *string {
+	if s == nil {
+		return nil
+	}
+	v := normalize(*s)
+	return &v
+}
+
+func normalize(s string, multiline bool) string {
+	s = strings.ToValidUTF8(s, "")
+	if multiline {
+		s = strings.ReplaceAll(s, "\r\n", "\n")
+	}
+	var (
+		b     strings.Builder
+		space bool
+		lines int
+	)
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case multiline && (r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029'):
+			lines++
+			space = false
+		case unicode.IsSpace(r):
+			space = true
+		case unicode.IsControl(r):
+			// Control characters are dropped without separating the text around them.
+		default:
+			if b.Len() > 0 {
+				switch {
+				case lines > 0:
+					b.WriteString("\n\n"[:min(lines, 2)])
+				case space:
+					b.WriteByte(' ')
+				}
+			}
+			space, lines = false, 0
+			b.WriteRune(r)
+		}
+	}
+	// Normalizing after dropping control characters composes the characters they separated, if any.
+	return norm.NFC.String(b.String())
+}
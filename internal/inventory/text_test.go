@@ -0,0 +1,142 @@
+package inventory
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestNormalizeText(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		in        string
+		want      string
+		multiline string
+	}{
+		{
+			name:      "empty",
+			in:        "",
+			want:      "",
+			multiline: "",
+		},
+		{
+			name:      "whitespace",
+			in:        " \t\n\u00a0\u3000 ",
+			want:      "",
+			multiline: "",
+		},
+		{
+			name:      "trim_and_collapse",
+			in:        "  Office\t\tchair  ",
+			want:      "Office chair",
+			multiline: "Office chair",
+		},
+		{
+			name:      "nfc",
+			in:        "Cafe\u0301",
+			want:      "Caf\u00e9",
+			multiline: "Caf\u00e9",
+		},
+		{
+			name:      "control_characters",
+			in:        "Desk\x00\x1b lamp\x7f",
+			want:      "Desk lamp",
+			multiline: "Desk lamp",
+		},
+		{
+			name:      "compose_across_control_character",
+			in:        "e\x00\u0301",
+			want:      "\u00e9",
+			multiline: "\u00e9",
+		},
+		{
+			name:      "invalid_utf8",
+			in:        "Sofa\xff\xfe bed",
+			want:      "Sofa bed",
+			multiline: "Sofa bed",
+		},
+		{
+			name:      "lines",
+			in:        "First line.  \r\n  Second line.\r\n\r\n\r\n\n Third paragraph.\u2029Fourth.\n",
+			want:      "First line. Second line. Third paragraph. Fourth.",
+			multiline: "First line.\nSecond line.\n\nThird paragraph.\nFourth.",
+		},
+		{
+			name:      "emoji_sequence",
+			in:        "Great \U0001F469\u200d\U0001F4BB desk",
+			want:      "Great \U0001F469\u200d\U0001F4BB desk",
+			multiline: "Great \U0001F469\u200d\U0001F4BB desk",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := normalizeText(tt.in); got != tt.want {
+				t.Errorf("normalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if got := normalizeMultilineText(tt.in); got != tt.multiline {
+				t.Errorf("normalizeMultilineText(%q) = %q, want %q", tt.in, got, tt.multiline)
+			}
+		})
+	}
+}
+
+// textAlphabet has the characters normalization deals with, which random strings rarely have next to each other:
+// whitespace, line breaks, control characters, invalid UTF-8, and characters composed or decomposed by NFC.
+var textAlphabet = []string{
+	"a", "e", "\u00e9", "\u0301", "\u1100", "\u1161", "\u212b", "\u2000",
+	" ", "\t", "\n", "\r", "\r\n", "\u00a0", "\u2028", "\u2029", "\u3000",
+	"\x00", "\x1b", "\x7f", "\u0085", "\u200d", "\xff",
+}
+
+// textValues generates the strings checked by quick.Check from textAlphabet.
+func textValues(values []reflect.Value, r *rand.Rand) {
+	for i := range values {
+		var b strings.Builder
+		for range r.Intn(20) {
+			b.WriteString(textAlphabet[r.Intn(len(textAlphabet))])
+		}
+		values[i] = reflect.ValueOf(b.String())
+	}
+}
+
+func TestNormalizeTextProperties(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		name      string
+		normalize func(string) string
+		multiline bool
+	}{
+		{"normalizeText", normalizeText, false},
+		{"normalizeMultilineText", normalizeMultilineText, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			property := func(s string) bool {
+				got := tt.normalize(s)
+				return utf8.ValidString(got) &&
+					norm.NFC.IsNormalString(got) &&
+					got == strings.TrimSpace(got) &&
+					!strings.Contains(got, "  ") &&
+					!strings.Contains(got, "\n\n\n") &&
+					!strings.ContainsFunc(got, func(r rune) bool {
+						if r == '\n' {
+							return !tt.multiline
+						}
+						return unicode.IsControl(r) || unicode.IsSpace(r) && r != ' '
+					}) &&
+					tt.normalize(got) == got // Idempotent.
+			}
+			if err := quick.Check(property, &quick.Config{Values: textValues, MaxCount: 1000}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Warehouse where products are stocked.
+type Warehouse struct {
+	ID         string
+	Name       string
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+// CreateWarehouseParams used by CreateWarehouse.
+type CreateWarehouseParams struct {
+	ID   string
+	Name string
+}
+
+var (
+	// ErrWarehouseExists is returned when a warehouse with the same ID already exists.
+	ErrWarehouseExists = errors.New("warehouse already exists")
+
+	// ErrWarehouseNotFound is returned when a warehouse to stock or transfer products to or from is not found.
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+
+	// ErrStockNoProduct is returned when stock cannot be set because the product is not found.
+	ErrStockNoProduct = errors.New("cannot find product to stock")
+
+	// ErrInsufficientStock is returned when a warehouse doesn't have enough units available to transfer.
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	// ErrStockReserved is returned when setting the quantity of a product below its units reserved by pending orders.
+	ErrStockReserved = errors.New("quantity cannot be lower than the units reserved")
+)
+
+// CreateWarehouse creates a new warehouse.
+func (s *Service) CreateWarehouse(ctx context.Context, params CreateWarehouseParams) (*Warehouse, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	if params.ID == "" {
+		return nil, ValidationError{"missing warehouse ID"}
+	}
+	if params.Name == "" {
+		return nil, ValidationError{"missing warehouse name"}
+	}
+	return s.products.CreateWarehouse(ctx, params)
+}
+
+// ListWarehouses returns all warehouses, ordered by ID.
+func (s *Service) ListWarehouses(ctx context.Context) ([]*Warehouse, error) {
+	return s.products.ListWarehouses(ctx)
+}
+
+// StockLevel of a product at a warehouse.
+type StockLevel struct {
+	ProductID   string
+	WarehouseID string
+	Quantity    int
+
+	// Reserved units by pending orders.
+	Reserved int
+
+	ModifiedAt time.Time
+}
+
+// Available units for new orders.
+func (l StockLevel) Available() int {
+	return l.Quantity - l.Reserved
+}
+
+// SetProductStockParams used by SetProductStock.
+type SetProductStockParams struct {
+	ProductID   string
+	WarehouseID string
+	Quantity    int
+}
+
+// SetProductStock sets the quantity of a product in stock at a warehouse, such as after counting it.
+func (s *Service) SetProductStock(ctx context.Context, params SetProductStockParams) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if params.ProductID == "" {
+		return ValidationError{"missing product ID"}
+	}
+	if params.WarehouseID == "" {
+		return ValidationError{"missing warehouse ID"}
+	}
+	if params.Quantity < 0 {
+		return ValidationError{"quantity cannot be negative"}
+	}
+	return s.products.SetProductStock(ctx, params)
+}
+
+// TransferStockParams used by TransferStock.
+type TransferStockParams struct {
+	ProductID string
+	From      string
+	To        string
+	Quantity  int
+}
+
+func (p *TransferStockParams) validate() error {
+	if p.ProductID == "" {
+		return ValidationError{"missing product ID"}
+	}
+	if p.From == "" || p.To == "" {
+		return ValidationError{"missing warehouse ID"}
+	}
+	if p.From == p.To {
+		return ValidationError{"cannot transfer stock to the same warehouse"}
+	}
+	if p.Quantity < 1 {
+		return ValidationError{"quantity must be at least 1"}
+	}
+	return nil
+}
+
+// TransferStock moves available units of a product from a warehouse to another.
+// It returns ErrInsufficientStock if the origin warehouse doesn't have enough units available.
+func (s *Service) TransferStock(ctx context.Context, params TransferStockParams) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+	if err := params.validate(); err != nil {
+		return err
+	}
+	return s.products.TransferStock(ctx, params)
+}
+
+// GetProductStock returns the stock levels of a product at each warehouse stocking it, ordered by warehouse ID.
+func (s *Service) GetProductStock(ctx context.Context, productID string) ([]*StockLevel, error) {
+	if productID == "" {
+		return nil, ValidationError{"missing product ID"}
+	}
+	return s.products.GetProductStock(ctx, productID)
+}
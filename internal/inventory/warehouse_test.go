@@ -0,0 +1,47 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceWarehousesValidation(t *testing.T) {
+	t.Parallel()
+	s := inventory.NewService(inventory.NewMockDB(gomock.NewController(t)))
+	if _, err := s.CreateWarehouse(context.Background(), inventory.CreateWarehouseParams{ID: "north"}); err == nil || err.Error() != "missing warehouse name" {
+		t.Errorf("Service.CreateWarehouse() error = %v, want missing warehouse name", err)
+	}
+	if err := s.SetProductStock(context.Background(), inventory.SetProductStockParams{
+		ProductID:   "product",
+		WarehouseID: "north",
+		Quantity:    -1,
+	}); err == nil || err.Error() != "quantity cannot be negative" {
+		t.Errorf("Service.SetProductStock() error = %v, want quantity cannot be negative", err)
+	}
+	if err := s.TransferStock(context.Background(), inventory.TransferStockParams{
+		ProductID: "product",
+		From:      "north",
+		To:        "north",
+		Quantity:  1,
+	}); err == nil || err.Error() != "cannot transfer stock to the same warehouse" {
+		t.Errorf("Service.TransferStock() error = %v, want same warehouse error", err)
+	}
+	if err := s.TransferStock(context.Background(), inventory.TransferStockParams{
+		ProductID: "product",
+		From:      "north",
+		To:        "south",
+	}); err == nil || err.Error() != "quantity must be at least 1" {
+		t.Errorf("Service.TransferStock() error = %v, want quantity must be at least 1", err)
+	}
+	if _, err := s.GetProductStock(context.Background(), ""); err == nil || err.Error() != "missing product ID" {
+		t.Errorf("Service.GetProductStock() error = %v, want missing product ID", err)
+	}
+
+	s.SetReadOnly(true)
+	if err := s.TransferStock(context.Background(), inventory.TransferStockParams{}); err != inventory.ErrReadOnly {
+		t.Errorf("Service.TransferStock() error = %v, want %v", err, inventory.ErrReadOnly)
+	}
+}
@@ -0,0 +1,63 @@
+// Package langdetect detects the language of short texts, such as product reviews,
+// by counting their most common words (stopwords) in each language.
+//
+// It's a lightweight default for inventory.LanguageDetector.
+// For better accuracy, use a statistical model or an external service instead.
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords by ISO 639-1 language code.
+// Words common to several languages are left out, as they don't help telling them apart.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "it", "this", "was", "with", "for", "very", "not", "but", "have", "are", "of", "to", "i", "my", "you"},
+	"es": {"el", "la", "los", "las", "y", "es", "muy", "pero", "con", "para", "una", "lo", "del", "por", "que", "mi", "está", "bien"},
+	"pt": {"o", "os", "as", "e", "é", "muito", "mas", "com", "para", "uma", "do", "da", "não", "que", "meu", "minha", "está", "bem"},
+	"fr": {"le", "les", "et", "est", "très", "mais", "avec", "pour", "une", "du", "pas", "je", "mon", "ce", "il", "bien", "des", "qui"},
+	"de": {"der", "die", "das", "und", "ist", "sehr", "aber", "mit", "für", "ein", "eine", "nicht", "ich", "es", "gut", "auch", "zu", "den"},
+	"it": {"il", "gli", "e", "è", "molto", "ma", "con", "per", "di", "non", "che", "mio", "questo", "sono", "bene", "anche", "della", "ho"},
+}
+
+// index of the languages of each stopword.
+var index = func() map[string][]string {
+	m := map[string][]string{}
+	for lang, words := range stopwords {
+		for _, w := range words {
+			m[w] = append(m[w], lang)
+		}
+	}
+	return m
+}()
+
+// Detector detects the language of texts in English, Spanish, Portuguese, French, German, or Italian.
+type Detector struct{}
+
+// DetectLanguage returns the ISO 639-1 code of the language of the text,
+// or an empty string if it cannot be told apart from the other languages.
+func (Detector) DetectLanguage(text string) string {
+	scores := map[string]int{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		for _, lang := range index[word] {
+			scores[lang]++
+		}
+	}
+	var best string
+	var tie bool
+	for lang, score := range scores {
+		switch {
+		case best == "" || score > scores[best]:
+			best, tie = lang, false
+		case score == scores[best]:
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,26 @@
+package langdetect
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"This chair is very comfortable, and the price was fair.", "en"},
+		{"La silla es muy cómoda y el precio está bien.", "es"},
+		{"A cadeira é muito confortável, mas o preço não é bom.", "pt"},
+		{"La chaise est très confortable, mais le prix est élevé.", "fr"},
+		{"Der Stuhl ist sehr bequem und auch nicht teuer.", "de"},
+		{"La sedia è molto comoda, ma il prezzo non è basso.", "it"},
+		{"", ""},
+		{"12345 !!!", ""},
+		{"Ok", ""},
+	}
+	for _, tt := range tests {
+		if got := (Detector{}).DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
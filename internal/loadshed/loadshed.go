@@ -0,0 +1,84 @@
+// Package loadshed limits the in-flight requests of endpoints, shedding the excess load.
+//
+// Requests beyond the capacity of the database pile up waiting for connections until they time out,
+// and retries make it worse. Rejecting them early keeps the latency of the accepted requests bounded.
+package loadshed
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Limiter of the in-flight requests of each endpoint.
+type Limiter struct {
+	limit int
+
+	mu       sync.Mutex
+	inflight map[string]int
+	shed     map[string]int64
+}
+
+// NewLimiter creates a Limiter allowing up to limit in-flight requests per endpoint.
+func NewLimiter(limit int) *Limiter {
+	return &Limiter{
+		limit:    max(limit, 1),
+		inflight: map[string]int{},
+		shed:     map[string]int64{},
+	}
+}
+
+// Limit of in-flight requests per endpoint.
+func (l *Limiter) Limit() int {
+	return l.limit
+}
+
+// Acquire a slot for a request to an endpoint.
+// If the endpoint is at its limit, the request should be rejected, and ok is false.
+// Otherwise, release must be called once the request is done.
+func (l *Limiter) Acquire(endpoint string) (release func(), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight[endpoint] >= l.limit {
+		l.shed[endpoint]++
+		return nil, false
+	}
+	l.inflight[endpoint]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.inflight[endpoint]--
+		})
+	}, true
+}
+
+// RegisterMetrics exposes the in-flight requests of every endpoint as the loadshed.inflight gauge,
+// and the rejected ones as the loadshed.shed counter.
+func (l *Limiter) RegisterMetrics(meter metric.Meter) error {
+	inflight, err := meter.Int64ObservableGauge("loadshed.inflight",
+		metric.WithDescription("In-flight requests of the endpoint."))
+	if err != nil {
+		return err
+	}
+	shed, err := meter.Int64ObservableCounter("loadshed.shed",
+		metric.WithDescription("Requests rejected as the endpoint was at its concurrency limit."))
+	if err != nil {
+		return err
+	}
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for name, n := range l.inflight {
+			o.ObserveInt64(inflight, int64(n), metric.WithAttributes(attribute.String("endpoint", name)))
+		}
+		for name, n := range l.shed {
+			o.ObserveInt64(shed, n, metric.WithAttributes(attribute.String("endpoint", name)))
+		}
+		return nil
+	}, inflight, shed)
+	return err
+}
@@ -0,0 +1,54 @@
+package loadshed
+
+import "testing"
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+	l := NewLimiter(2)
+	if got := l.Limit(); got != 2 {
+		t.Errorf("Limiter.Limit() = %d, want 2", got)
+	}
+	r1, ok := l.Acquire("GetProduct")
+	if !ok {
+		t.Fatal("Limiter.Acquire() = false, want true")
+	}
+	r2, ok := l.Acquire("GetProduct")
+	if !ok {
+		t.Fatal("Limiter.Acquire() = false, want true")
+	}
+	if _, ok := l.Acquire("GetProduct"); ok {
+		t.Error("Limiter.Acquire() = true at the limit, want false")
+	}
+	// Endpoints are limited independently.
+	r3, ok := l.Acquire("SearchProducts")
+	if !ok {
+		t.Fatal("Limiter.Acquire() = false for another endpoint, want true")
+	}
+	defer r3()
+
+	// Releasing more than once doesn't free extra slots.
+	r1()
+	r1()
+	r4, ok := l.Acquire("GetProduct")
+	if !ok {
+		t.Fatal("Limiter.Acquire() = false after release, want true")
+	}
+	if _, ok := l.Acquire("GetProduct"); ok {
+		t.Error("Limiter.Acquire() = true after releasing twice, want false")
+	}
+	r2()
+	r4()
+	if got := l.shed["GetProduct"]; got != 2 {
+		t.Errorf("shed requests = %d, want 2", got)
+	}
+	if got := l.inflight["GetProduct"]; got != 0 {
+		t.Errorf("in-flight requests = %d, want 0", got)
+	}
+}
+
+func TestNewLimiterMinimum(t *testing.T) {
+	t.Parallel()
+	if got := NewLimiter(0).Limit(); got != 1 {
+		t.Errorf("Limiter.Limit() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,620 @@
+// Package memdb is an in-memory inventory database, such as for the mock server,
+// so clients of the API can be developed against it without PostgreSQL.
+//
+// It implements products, their translations, listings, and reviews, following the behavior of the postgres package,
+// and it can be seeded with the deterministic data of a datagen.Generator.
+// Other methods, such as the ones of suppliers and warehouses, return errors.ErrUnsupported.
+// Data is lost when the process exits.
+package memdb
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/henvic/pgxtutorial/internal/datagen"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// ErrProductNotFound is returned when a product is not found.
+var ErrProductNotFound = errors.New("product not found")
+
+// ErrReviewNotFound is returned when a review is not found.
+var ErrReviewNotFound = errors.New("product review not found")
+
+// DB is an in-memory inventory database.
+type DB struct {
+	mu           sync.RWMutex
+	products     map[string]*inventory.Product
+	slugs        map[string]string // Product ID by current or previous slug.
+	translations map[string]map[string]inventory.ProductTranslation
+	views        map[string]map[time.Time]int // Views of products by day.
+	reviews      map[string]*inventory.ProductReview
+
+	now func() time.Time
+}
+
+var _ inventory.DB = (*DB)(nil)
+
+// New creates an empty in-memory database.
+func New() *DB {
+	return &DB{
+		products:     map[string]*inventory.Product{},
+		slugs:        map[string]string{},
+		translations: map[string]map[string]inventory.ProductTranslation{},
+		views:        map[string]map[time.Time]int{},
+		reviews:      map[string]*inventory.ProductReview{},
+		now:          time.Now,
+	}
+}
+
+// Seed the database with the first n products of the generator, and their reviews.
+func (db *DB) Seed(g *datagen.Generator, n int) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for i := range n {
+		p, reviews := g.Product(i)
+		db.products[p.ID] = &p
+		db.slugs[p.Slug] = p.ID
+		for _, r := range reviews {
+			db.reviews[r.ID] = &r
+		}
+	}
+}
+
+// CreateProduct creates a product, or returns the existing one with the same ID.
+func (db *DB) CreateProduct(ctx context.Context, params inventory.CreateProductParams) (*inventory.CreateProductResult, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if p, ok := db.products[params.ID]; ok {
+		return &inventory.CreateProductResult{Product: clone(p)}, nil
+	}
+	if err := db.unique(params.ID, params.SKU, params.GTIN); err != nil {
+		return nil, err
+	}
+	now := db.now()
+	p := &inventory.Product{
+		ID:          params.ID,
+		Name:        params.Name,
+		Description: params.Description,
+		Price:       params.Price,
+		Status:      cmp.Or(params.Status, inventory.ProductStatusActive),
+		SKU:         params.SKU,
+		GTIN:        params.GTIN,
+		TaxClass:    params.TaxClass,
+		CreatedAt:   now,
+		ModifiedAt:  now,
+	}
+	p.Slug = db.freeSlug(p.Name, p.ID)
+	db.products[p.ID] = p
+	db.slugs[p.Slug] = p.ID
+	return &inventory.CreateProductResult{
+		Product: clone(p),
+		Created: true,
+	}, nil
+}
+
+// unique checks that the SKU and GTIN aren't taken by another product.
+// db.mu must be held.
+func (db *DB) unique(id, sku, gtin string) error {
+	for _, p := range db.products {
+		switch {
+		case p.ID == id:
+		case sku != "" && p.SKU == sku:
+			return inventory.ErrDuplicateSKU
+		case gtin != "" && p.GTIN == gtin:
+			return inventory.ErrDuplicateGTIN
+		}
+	}
+	return nil
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// freeSlug returns the slug of the name, suffixed by a number if it's taken by another product.
+// Unlike the product_slug SQL function, accents aren't removed, but treated as separators.
+// db.mu must be held.
+func (db *DB) freeSlug(name, id string) string {
+	slug := nonAlphanumeric.ReplaceAllString(strings.ToLower(name), "-")
+	slug = cmp.Or(strings.Trim(slug[:min(len(slug), 80)], "-"), "product")
+	candidate := slug
+	for i := 2; ; i++ {
+		if owner, ok := db.slugs[candidate]; !ok || owner == id {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+}
+
+// UpdateProduct updates the fields of a product that are set.
+func (db *DB) UpdateProduct(ctx context.Context, params inventory.UpdateProductParams) (*inventory.Product, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	p, ok := db.products[params.ID]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+	if err := db.unique(p.ID, deref(params.SKU), deref(params.GTIN)); err != nil {
+		return nil, err
+	}
+	// The previous slug is kept, so it still finds the product.
+	if params.Name != nil && *params.Name != p.Name {
+		p.Name = *params.Name
+		p.Slug = db.freeSlug(p.Name, p.ID)
+		db.slugs[p.Slug] = p.ID
+	}
+	set(&p.Description, params.Description)
+	set(&p.Price, params.Price)
+	set(&p.Status, params.Status)
+	set(&p.SKU, params.SKU)
+	set(&p.GTIN, params.GTIN)
+	set(&p.TaxClass, params.TaxClass)
+	p.ModifiedAt = db.now()
+	return clone(p), nil
+}
+
+// GetProduct returns a product, or nil if it isn't found.
+func (db *DB) GetProduct(ctx context.Context, id string) (*inventory.Product, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return clone(db.products[id]), nil
+}
+
+// GetProductBySlug returns a product by its current or a previous slug, or nil if it isn't found.
+func (db *DB) GetProductBySlug(ctx context.Context, slug string) (*inventory.Product, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return clone(db.products[db.slugs[slug]]), nil
+}
+
+// GetProductBySKU returns a product by its SKU, or nil if it isn't found.
+func (db *DB) GetProductBySKU(ctx context.Context, sku string) (*inventory.Product, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, p := range db.products {
+		if p.SKU == sku {
+			return clone(p), nil
+		}
+	}
+	return nil, nil
+}
+
+// GetProducts returns the products with the given IDs that exist.
+func (db *DB) GetProducts(ctx context.Context, ids []string) ([]*inventory.Product, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	products := []*inventory.Product{}
+	for _, id := range ids {
+		if p, ok := db.products[id]; ok {
+			products = append(products, clone(p))
+		}
+	}
+	return products, nil
+}
+
+// UpsertProductTranslation creates or replaces the translation of a product to a locale.
+func (db *DB) UpsertProductTranslation(ctx context.Context, params inventory.ProductTranslation) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.products[params.ProductID]; !ok {
+		return ErrProductNotFound
+	}
+	if db.translations[params.ProductID] == nil {
+		db.translations[params.ProductID] = map[string]inventory.ProductTranslation{}
+	}
+	db.translations[params.ProductID][params.Locale] = params
+	return nil
+}
+
+// DeleteProductTranslation deletes the translation of a product to a locale.
+func (db *DB) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	delete(db.translations[productID], locale)
+	return nil
+}
+
+// GetProductTranslation returns the translation of a product to the first of the locales it has, or nil if none.
+func (db *DB) GetProductTranslation(ctx context.Context, productID string, locales []string) (*inventory.ProductTranslation, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, locale := range locales {
+		if t, ok := db.translations[productID][locale]; ok {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+// SearchProducts returns a page of the products matching the search, and their total.
+func (db *DB) SearchProducts(ctx context.Context, params inventory.SearchProductsParams) (*inventory.SearchProductsResponse, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	found := db.search(params)
+	resp := &inventory.SearchProductsResponse{
+		Items: []*inventory.Product{},
+		Total: len(found),
+	}
+	if params.CountPriceBands {
+		resp.PriceBands = priceBands(found)
+	}
+	for _, p := range page(found, params.Pagination) {
+		resp.Items = append(resp.Items, db.view(p, params.View))
+	}
+	return resp, nil
+}
+
+// ExportProducts calls fn with each product of the page of a search.
+func (db *DB) ExportProducts(ctx context.Context, params inventory.SearchProductsParams, fn func(*inventory.Product) error) error {
+	db.mu.RLock()
+	var products []*inventory.Product
+	for _, p := range page(db.search(params), params.Pagination) {
+		products = append(products, db.view(p, params.View))
+	}
+	db.mu.RUnlock()
+	for _, p := range products {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// search returns the products matching the search, in its order.
+// Text matches the products with all of its words in their name or description, regardless of case,
+// and without the -words, rather than following the full web search syntax.
+// db.mu must be held.
+func (db *DB) search(params inventory.SearchProductsParams) []*inventory.Product {
+	var include, exclude []string
+	for _, w := range strings.Fields(strings.ToLower(params.Text)) {
+		if w, ok := strings.CutPrefix(w, "-"); ok {
+			exclude = append(exclude, w)
+		} else {
+			include = append(include, strings.Trim(w, `"`))
+		}
+	}
+	var found []*inventory.Product
+	for _, p := range db.products {
+		words := strings.FieldsFunc(strings.ToLower(p.Name+" "+p.Description), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		switch {
+		case (params.QueryString != "" || (params.SKUPrefix == "" && params.Text == "")) && !strings.Contains(p.Name, params.QueryString),
+			params.SKUPrefix != "" && !strings.HasPrefix(p.SKU, params.SKUPrefix),
+			slices.ContainsFunc(include, func(w string) bool { return !slices.Contains(words, w) }),
+			slices.ContainsFunc(exclude, func(w string) bool { return slices.Contains(words, w) }),
+			params.MinPrice != 0 && p.Price < params.MinPrice,
+			params.MaxPrice != 0 && p.Price > params.MaxPrice:
+			continue
+		}
+		found = append(found, p)
+	}
+	slices.SortFunc(found, func(a, b *inventory.Product) int {
+		if params.OrderBy == inventory.ProductsByName {
+			return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.ID, b.ID))
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+	return found
+}
+
+// priceBands of the products with any, from the cheapest.
+func priceBands(products []*inventory.Product) []inventory.PriceBand {
+	counts := make([]int, len(inventory.PriceBandLimits)+1)
+	for _, p := range products {
+		band := 0
+		for band < len(inventory.PriceBandLimits) && p.Price >= inventory.PriceBandLimits[band] {
+			band++
+		}
+		counts[band]++
+	}
+	bands := []inventory.PriceBand{}
+	for band, n := range counts {
+		if n != 0 {
+			bands = append(bands, inventory.NewPriceBand(band, n))
+		}
+	}
+	return bands
+}
+
+// DeleteProduct deletes a product, along with its reviews and translations if forced.
+func (db *DB) DeleteProduct(ctx context.Context, params inventory.DeleteProductParams) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var reviews []string
+	for _, r := range db.reviews {
+		if r.ProductID == params.ID {
+			reviews = append(reviews, r.ID)
+		}
+	}
+	if len(reviews) > 0 && !params.Force {
+		return &inventory.HasDependentsError{Reviews: len(reviews)}
+	}
+	for _, id := range reviews {
+		delete(db.reviews, id)
+	}
+	for slug, id := range db.slugs {
+		if id == params.ID {
+			delete(db.slugs, slug)
+		}
+	}
+	delete(db.products, params.ID)
+	delete(db.translations, params.ID)
+	delete(db.views, params.ID)
+	return nil
+}
+
+// RecordProductView counts a view of a product on the current day.
+func (db *DB) RecordProductView(ctx context.Context, id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.products[id]; !ok {
+		return nil
+	}
+	if db.views[id] == nil {
+		db.views[id] = map[time.Time]int{}
+	}
+	db.views[id][day(db.now())]++
+	return nil
+}
+
+// ListTrendingProducts returns a page of the active products most viewed since the given day.
+func (db *DB) ListTrendingProducts(ctx context.Context, params inventory.ListTrendingProductsParams) (*inventory.ListProductsResponse, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	since := day(params.Since)
+	views := map[string]int{}
+	var products []*inventory.Product
+	for id, days := range db.views {
+		for d, n := range days {
+			if !d.Before(since) {
+				views[id] += n
+			}
+		}
+		if p := db.products[id]; views[id] > 0 && p.Status == inventory.ProductStatusActive {
+			products = append(products, p)
+		}
+	}
+	slices.SortFunc(products, func(a, b *inventory.Product) int {
+		return cmp.Or(cmp.Compare(views[b.ID], views[a.ID]), strings.Compare(a.ID, b.ID))
+	})
+	return db.list(products, params.Pagination, params.View), nil
+}
+
+// ListRecentProducts returns a page of the active products, the most recently created first.
+func (db *DB) ListRecentProducts(ctx context.Context, params inventory.ListRecentProductsParams) (*inventory.ListProductsResponse, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	var products []*inventory.Product
+	for _, p := range db.products {
+		if p.Status == inventory.ProductStatusActive {
+			products = append(products, p)
+		}
+	}
+	slices.SortFunc(products, func(a, b *inventory.Product) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	return db.list(products, params.Pagination, params.View), nil
+}
+
+// list a page of the products with the view.
+// db.mu must be held.
+func (db *DB) list(products []*inventory.Product, pagination inventory.Pagination, view inventory.ProductView) *inventory.ListProductsResponse {
+	resp := &inventory.ListProductsResponse{
+		Items: []*inventory.Product{},
+	}
+	for _, p := range page(products, pagination) {
+		resp.Items = append(resp.Items, db.view(p, view))
+	}
+	return resp
+}
+
+// view returns a copy of the product with the fields of the view.
+// db.mu must be held.
+func (db *DB) view(p *inventory.Product, view inventory.ProductView) *inventory.Product {
+	if view != inventory.ProductViewSummary {
+		return clone(p)
+	}
+	var sum, n int
+	for _, r := range db.reviews {
+		if r.ProductID == p.ID {
+			sum += r.Score
+			n++
+		}
+	}
+	summary := &inventory.Product{
+		ID:        p.ID,
+		Name:      p.Name,
+		Price:     p.Price,
+		CreatedAt: p.CreatedAt,
+	}
+	if n != 0 {
+		summary.Rating = float64(sum) / float64(n)
+	}
+	return summary
+}
+
+// CreateProductReview for a given product.
+func (db *DB) CreateProductReview(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.products[params.ProductID]; !ok {
+		return inventory.ErrCreateReviewNoProduct
+	}
+	if _, ok := db.reviews[params.ID]; ok {
+		return errors.New("product review already exists")
+	}
+	now := db.now()
+	db.reviews[params.ID] = &inventory.ProductReview{
+		ID:          params.ID,
+		ProductID:   params.ProductID,
+		ReviewerID:  params.ReviewerID,
+		Score:       params.Score,
+		Title:       params.Title,
+		Description: params.Description,
+		Language:    params.Language,
+		Flagged:     params.Flagged,
+		Sentiment:   params.Sentiment,
+		Attachments: slices.Clone(params.Attachments),
+		CreatedAt:   now,
+		ModifiedAt:  now,
+	}
+	return nil
+}
+
+// UpdateProductReview updates the fields of a review that are set.
+func (db *DB) UpdateProductReview(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	r, ok := db.reviews[params.ID]
+	if !ok {
+		return ErrReviewNotFound
+	}
+	set(&r.Score, params.Score)
+	set(&r.Title, params.Title)
+	set(&r.Description, params.Description)
+	set(&r.Language, params.Language)
+	if params.Sentiment != nil {
+		r.Sentiment = params.Sentiment
+	}
+	if params.Attachments != nil {
+		r.Attachments = slices.Clone(*params.Attachments)
+	}
+	r.Flagged = r.Flagged || params.Flagged
+	r.ModifiedAt = db.now()
+	return nil
+}
+
+// GetProductReview returns a review, or nil if it isn't found.
+func (db *DB) GetProductReview(ctx context.Context, id string) (*inventory.ProductReview, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return cloneReview(db.reviews[id]), nil
+}
+
+// GetProductReviews returns a page of the reviews matching the parameters, and their total.
+func (db *DB) GetProductReviews(ctx context.Context, params inventory.ProductReviewsParams) (*inventory.ProductReviewsResponse, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	var found []*inventory.ProductReview
+	for _, r := range db.reviews {
+		switch {
+		case params.ProductID != "" && r.ProductID != params.ProductID,
+			params.ReviewerID != "" && r.ReviewerID != params.ReviewerID,
+			params.Language != "" && r.Language != params.Language,
+			params.MinSentiment != nil && (r.Sentiment == nil || *r.Sentiment < *params.MinSentiment),
+			params.MaxSentiment != nil && (r.Sentiment == nil || *r.Sentiment > *params.MaxSentiment):
+			continue
+		}
+		found = append(found, r)
+	}
+	slices.SortFunc(found, func(a, b *inventory.ProductReview) int {
+		newest := cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+		switch {
+		case params.OrderBy != inventory.ReviewsByMostPositive && params.OrderBy != inventory.ReviewsByMostNegative:
+			return newest
+		case a.Sentiment == nil || b.Sentiment == nil: // Reviews without a sentiment are the last ones.
+			return cmp.Or(cmp.Compare(boolInt(a.Sentiment == nil), boolInt(b.Sentiment == nil)), newest)
+		case params.OrderBy == inventory.ReviewsByMostPositive:
+			return cmp.Or(cmp.Compare(*b.Sentiment, *a.Sentiment), newest)
+		default:
+			return cmp.Or(cmp.Compare(*a.Sentiment, *b.Sentiment), newest)
+		}
+	})
+	resp := &inventory.ProductReviewsResponse{
+		Reviews: []*inventory.ProductReview{},
+		Total:   len(found),
+	}
+	for _, r := range page(found, params.Pagination) {
+		resp.Reviews = append(resp.Reviews, cloneReview(r))
+	}
+	return resp, nil
+}
+
+// CountReviewerReviews returns how many reviews a reviewer created since the given time.
+func (db *DB) CountReviewerReviews(ctx context.Context, reviewerID string, since time.Time) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	var n int
+	for _, r := range db.reviews {
+		if r.ReviewerID == reviewerID && !r.CreatedAt.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteProductReview deletes a review.
+func (db *DB) DeleteProductReview(ctx context.Context, id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	delete(db.reviews, id)
+	return nil
+}
+
+// page of the items.
+func page[T any](items []T, p inventory.Pagination) []T {
+	start := min(p.Offset, len(items))
+	end := len(items)
+	if p.Limit > 0 {
+		end = min(start+p.Limit, end)
+	}
+	return items[start:end]
+}
+
+// day of the time, in UTC.
+func day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// set the field to the value, if it's set.
+func set[T any](field *T, v *T) {
+	if v != nil {
+		*field = *v
+	}
+}
+
+// deref returns the value, or its zero value if it isn't set.
+func deref[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// clone the product, so it isn't modified by the callers.
+func clone(p *inventory.Product) *inventory.Product {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+// cloneReview clones the review, so it isn't modified by the callers.
+func cloneReview(r *inventory.ProductReview) *inventory.ProductReview {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	c.Attachments = slices.Clone(r.Attachments)
+	return &c
+}
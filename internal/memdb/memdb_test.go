@@ -0,0 +1,164 @@
+package memdb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/datagen"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/memdb"
+)
+
+func TestProducts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := memdb.New()
+	created, err := db.CreateProduct(ctx, inventory.CreateProductParams{
+		ID:    "1",
+		Name:  "Desk Lamp",
+		Price: 4500,
+		SKU:   "LAMP-1",
+	})
+	if err != nil || !created.Created {
+		t.Fatalf("DB.CreateProduct() = %v, %v, want created", created, err)
+	}
+	if created.Product.Slug != "desk-lamp" || created.Product.Status != inventory.ProductStatusActive {
+		t.Errorf("DB.CreateProduct() slug = %q, status = %q, want desk-lamp, active", created.Product.Slug, created.Product.Status)
+	}
+	if again, err := db.CreateProduct(ctx, inventory.CreateProductParams{ID: "1", Name: "Other"}); err != nil || again.Created || again.Product.Name != "Desk Lamp" {
+		t.Errorf("DB.CreateProduct() with an existing ID = %v, %v, want the existing product", again, err)
+	}
+	if _, err := db.CreateProduct(ctx, inventory.CreateProductParams{ID: "2", Name: "Lamp", SKU: "LAMP-1"}); err != inventory.ErrDuplicateSKU {
+		t.Errorf("DB.CreateProduct() with a duplicate SKU error = %v, want %v", err, inventory.ErrDuplicateSKU)
+	}
+	if _, err := db.CreateProduct(ctx, inventory.CreateProductParams{ID: "2", Name: "Desk Lamp", Price: 900}); err != nil {
+		t.Fatalf("DB.CreateProduct() error = %v", err)
+	}
+
+	name := "Floor Lamp"
+	p, err := db.UpdateProduct(ctx, inventory.UpdateProductParams{ID: "1", Name: &name})
+	if err != nil || p.Slug != "floor-lamp" {
+		t.Fatalf("DB.UpdateProduct() = %v, %v, want slug floor-lamp", p, err)
+	}
+	if got, err := db.GetProductBySlug(ctx, "desk-lamp"); err != nil || got == nil || got.ID != "1" {
+		t.Errorf("DB.GetProductBySlug() of a previous slug = %v, %v, want product 1", got, err)
+	}
+	if got, err := db.GetProductBySlug(ctx, "desk-lamp-2"); err != nil || got == nil || got.ID != "2" {
+		t.Errorf("DB.GetProductBySlug() = %v, %v, want product 2", got, err)
+	}
+	if _, err := db.UpdateProduct(ctx, inventory.UpdateProductParams{ID: "404", Name: &name}); err != memdb.ErrProductNotFound {
+		t.Errorf("DB.UpdateProduct() of a missing product error = %v, want %v", err, memdb.ErrProductNotFound)
+	}
+
+	// Products returned are copies.
+	p.Name = "modified"
+	if got, _ := db.GetProduct(ctx, "1"); got.Name != name {
+		t.Errorf("DB.GetProduct() name = %q, want %q", got.Name, name)
+	}
+
+	resp, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+		QueryString:     "Lamp",
+		CountPriceBands: true,
+		OrderBy:         inventory.ProductsByName,
+		Pagination:      inventory.Pagination{Limit: 1},
+	})
+	if err != nil {
+		t.Fatalf("DB.SearchProducts() error = %v", err)
+	}
+	if resp.Total != 2 || len(resp.Items) != 1 || resp.Items[0].ID != "2" {
+		t.Errorf("DB.SearchProducts() = %d products, total %d, want product 2 of 2", len(resp.Items), resp.Total)
+	}
+	wantBands := []inventory.PriceBand{inventory.NewPriceBand(0, 1), inventory.NewPriceBand(1, 1)}
+	if len(resp.PriceBands) != 2 || resp.PriceBands[0] != wantBands[0] || resp.PriceBands[1] != wantBands[1] {
+		t.Errorf("DB.SearchProducts() price bands = %v, want %v", resp.PriceBands, wantBands)
+	}
+	if resp, _ := db.SearchProducts(ctx, inventory.SearchProductsParams{Text: "FLOOR -desk"}); resp.Total != 1 || resp.Items[0].ID != "1" {
+		t.Errorf("DB.SearchProducts() by text = %v, want product 1", resp.Items)
+	}
+	if resp, _ := db.SearchProducts(ctx, inventory.SearchProductsParams{QueryString: "lamp"}); resp.Total != 0 {
+		t.Errorf("DB.SearchProducts() query string total = %d, want it to be case-sensitive", resp.Total)
+	}
+
+	if err := db.RecordProductView(ctx, "2"); err != nil {
+		t.Fatalf("DB.RecordProductView() error = %v", err)
+	}
+	trending, err := db.ListTrendingProducts(ctx, inventory.ListTrendingProductsParams{Since: time.Now().Add(-time.Hour)})
+	if err != nil || len(trending.Items) != 1 || trending.Items[0].ID != "2" {
+		t.Errorf("DB.ListTrendingProducts() = %v, %v, want product 2", trending, err)
+	}
+}
+
+func TestReviews(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := memdb.New()
+	if err := db.CreateProductReview(ctx, inventory.CreateProductReviewDBParams{
+		ID:                        "r0",
+		CreateProductReviewParams: inventory.CreateProductReviewParams{ProductID: "404"},
+	}); err != inventory.ErrCreateReviewNoProduct {
+		t.Errorf("DB.CreateProductReview() of a missing product error = %v, want %v", err, inventory.ErrCreateReviewNoProduct)
+	}
+	if _, err := db.CreateProduct(ctx, inventory.CreateProductParams{ID: "p", Name: "Chair"}); err != nil {
+		t.Fatalf("DB.CreateProduct() error = %v", err)
+	}
+	positive, negative := 0.8, -0.5
+	for _, r := range []inventory.CreateProductReviewDBParams{
+		{ID: "r1", CreateProductReviewParams: inventory.CreateProductReviewParams{ProductID: "p", ReviewerID: "alice", Score: 5}, Sentiment: &positive},
+		{ID: "r2", CreateProductReviewParams: inventory.CreateProductReviewParams{ProductID: "p", ReviewerID: "bob", Score: 1}, Sentiment: &negative},
+		{ID: "r3", CreateProductReviewParams: inventory.CreateProductReviewParams{ProductID: "p", ReviewerID: "alice", Score: 3}},
+	} {
+		if err := db.CreateProductReview(ctx, r); err != nil {
+			t.Fatalf("DB.CreateProductReview() error = %v", err)
+		}
+	}
+	resp, err := db.GetProductReviews(ctx, inventory.ProductReviewsParams{ProductID: "p", OrderBy: inventory.ReviewsByMostNegative})
+	if err != nil {
+		t.Fatalf("DB.GetProductReviews() error = %v", err)
+	}
+	var ids []string
+	for _, r := range resp.Reviews {
+		ids = append(ids, r.ID)
+	}
+	if len(ids) != 3 || ids[0] != "r2" || ids[1] != "r1" || ids[2] != "r3" {
+		t.Errorf("DB.GetProductReviews() = %v, want [r2 r1 r3]", ids)
+	}
+	if n, err := db.CountReviewerReviews(ctx, "alice", time.Time{}); err != nil || n != 2 {
+		t.Errorf("DB.CountReviewerReviews() = %d, %v, want 2", n, err)
+	}
+	if err := db.UpdateProductReview(ctx, inventory.UpdateProductReviewParams{ID: "404"}); err != memdb.ErrReviewNotFound {
+		t.Errorf("DB.UpdateProductReview() of a missing review error = %v, want %v", err, memdb.ErrReviewNotFound)
+	}
+
+	var dependents *inventory.HasDependentsError
+	if err := db.DeleteProduct(ctx, inventory.DeleteProductParams{ID: "p"}); !errors.As(err, &dependents) || dependents.Reviews != 3 {
+		t.Errorf("DB.DeleteProduct() with reviews error = %v, want 3 dependent reviews", err)
+	}
+	if err := db.DeleteProduct(ctx, inventory.DeleteProductParams{ID: "p", Force: true}); err != nil {
+		t.Errorf("DB.DeleteProduct() forced error = %v", err)
+	}
+	if r, err := db.GetProductReview(ctx, "r1"); err != nil || r != nil {
+		t.Errorf("DB.GetProductReview() of a deleted product = %v, %v, want nil", r, err)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	g := &datagen.Generator{Seed: 1, ReviewsPerProduct: 2}
+	db := memdb.New()
+	db.Seed(g, 10)
+	want, reviews := g.Product(3)
+	got, err := db.GetProductBySlug(ctx, want.Slug)
+	if err != nil || got == nil || got.ID != want.ID {
+		t.Fatalf("DB.GetProductBySlug() = %v, %v, want product %s", got, err, want.ID)
+	}
+	resp, err := db.GetProductReviews(ctx, inventory.ProductReviewsParams{ProductID: want.ID})
+	if err != nil || resp.Total != len(reviews) {
+		t.Errorf("DB.GetProductReviews() = %v, %v, want %d reviews", resp, err, len(reviews))
+	}
+	if _, err := db.ListWarehouses(ctx); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("DB.ListWarehouses() error = %v, want %v", err, errors.ErrUnsupported)
+	}
+}
@@ -0,0 +1,149 @@
+package memdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// GetProductAt is unsupported.
+func (db *DB) GetProductAt(context.Context, string, time.Time) (*inventory.Product, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// ProductHistory is unsupported.
+func (db *DB) ProductHistory(context.Context, string) ([]inventory.Version, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// FindSimilarProducts is unsupported.
+func (db *DB) FindSimilarProducts(context.Context, inventory.FindSimilarProductsParams) ([]inventory.SimilarProduct, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// ListTenantProducts is unsupported.
+func (db *DB) ListTenantProducts(context.Context, inventory.ListTenantProductsParams) ([]*inventory.Product, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// ApplyProductChanges is unsupported.
+func (db *DB) ApplyProductChanges(context.Context, []inventory.ProductChange) error {
+	return errors.ErrUnsupported
+}
+
+// CountProducts is unsupported.
+func (db *DB) CountProducts(context.Context, inventory.ProductFilter, int) (int, error) {
+	return 0, errors.ErrUnsupported
+}
+
+// DeleteProducts is unsupported.
+func (db *DB) DeleteProducts(context.Context, inventory.ProductFilter, int) (*inventory.DeleteProductsResult, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// GetProductStats is unsupported.
+func (db *DB) GetProductStats(context.Context, string) (*inventory.ProductStats, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// AddFavorite is unsupported.
+func (db *DB) AddFavorite(context.Context, inventory.FavoriteParams) error {
+	return errors.ErrUnsupported
+}
+
+// RemoveFavorite is unsupported.
+func (db *DB) RemoveFavorite(context.Context, inventory.FavoriteParams) error {
+	return errors.ErrUnsupported
+}
+
+// ListFavorites is unsupported.
+func (db *DB) ListFavorites(context.Context, inventory.ListFavoritesParams) (*inventory.ListFavoritesResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// RecordRecentlyViewed is unsupported.
+func (db *DB) RecordRecentlyViewed(context.Context, inventory.RecentlyViewedParams) error {
+	return errors.ErrUnsupported
+}
+
+// ListRecentlyViewed is unsupported.
+func (db *DB) ListRecentlyViewed(context.Context, inventory.ListRecentlyViewedParams) (*inventory.ListProductsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// CreateSupplier is unsupported.
+func (db *DB) CreateSupplier(context.Context, inventory.CreateSupplierParams) (*inventory.Supplier, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// UpdateSupplier is unsupported.
+func (db *DB) UpdateSupplier(context.Context, inventory.UpdateSupplierParams) (*inventory.Supplier, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// DeleteSupplier is unsupported.
+func (db *DB) DeleteSupplier(context.Context, string) error {
+	return errors.ErrUnsupported
+}
+
+// GetSupplier is unsupported.
+func (db *DB) GetSupplier(context.Context, string) (*inventory.Supplier, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// SetProductSupplier is unsupported.
+func (db *DB) SetProductSupplier(context.Context, inventory.ProductSupplier) error {
+	return errors.ErrUnsupported
+}
+
+// RemoveProductSupplier is unsupported.
+func (db *DB) RemoveProductSupplier(context.Context, string, string) error {
+	return errors.ErrUnsupported
+}
+
+// ListProductSuppliers is unsupported.
+func (db *DB) ListProductSuppliers(context.Context, string) ([]*inventory.ProductSupplier, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// ListSupplierProducts is unsupported.
+func (db *DB) ListSupplierProducts(context.Context, inventory.ListSupplierProductsParams) (*inventory.ListProductsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// CreateWarehouse is unsupported.
+func (db *DB) CreateWarehouse(context.Context, inventory.CreateWarehouseParams) (*inventory.Warehouse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// ListWarehouses is unsupported.
+func (db *DB) ListWarehouses(context.Context) ([]*inventory.Warehouse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// SetProductStock is unsupported.
+func (db *DB) SetProductStock(context.Context, inventory.SetProductStockParams) error {
+	return errors.ErrUnsupported
+}
+
+// TransferStock is unsupported.
+func (db *DB) TransferStock(context.Context, inventory.TransferStockParams) error {
+	return errors.ErrUnsupported
+}
+
+// GetProductStock is unsupported.
+func (db *DB) GetProductStock(context.Context, string) ([]*inventory.StockLevel, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// ReviewHistory is unsupported.
+func (db *DB) ReviewHistory(context.Context, string) ([]inventory.Version, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// PurgeReviewerData is unsupported.
+func (db *DB) PurgeReviewerData(context.Context, inventory.PurgeReviewerDataParams) (*inventory.PurgeReviewerDataResult, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,341 @@
+// Package orders places orders for products, demonstrating a saga: a workflow made of
+// local database transactions and calls to an external payment provider, where
+// each step that succeeded is undone by a compensating action when a later one fails.
+//
+// Placing an order takes these steps:
+//
+//  1. Reserve stock and record a pending order (transaction).
+//  2. Charge the payment provider.
+//  3. Deduct the reserved stock and mark the order as paid (transaction).
+//
+// If the charge fails, the reservation is released and the order is canceled.
+// If the last step fails, the payment is refunded before canceling the order.
+// Every transaction writes an event to the outbox, so consumers learn about each change
+// if, and only if, it's committed.
+package orders
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/events"
+)
+
+// Order of a product.
+type Order struct {
+	ID        string
+	ProductID string
+
+	// WarehouseID of the warehouse the units are reserved from.
+	WarehouseID string
+
+	Quantity   int
+	Amount     int
+	Status     Status
+	PaymentID  string
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+// Status of an order.
+type Status string
+
+// Order statuses.
+const (
+	StatusPending  Status = "pending"
+	StatusPaid     Status = "paid"
+	StatusCanceled Status = "canceled"
+)
+
+// Topics of the events written to the outbox, with events.Order payloads.
+const (
+	TopicOrderCreated  = "order.created"
+	TopicOrderPaid     = "order.paid"
+	TopicOrderCanceled = "order.canceled"
+)
+
+// ErrOrderNotPending is returned when changing the status of an order that isn't pending.
+var ErrOrderNotPending = errors.New("order is not pending")
+
+// ErrInsufficientStock is returned when there isn't enough stock of a product to place an order.
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ValidationError is returned when there is an invalid parameter received.
+type ValidationError struct {
+	s string
+}
+
+func (e ValidationError) Error() string {
+	return e.s
+}
+
+// Store used by the saga.
+// Methods are called within a transaction created by TransactionContext.
+type Store interface {
+	// TransactionContext returns a copy of the parent context with a transaction used by the other methods.
+	TransactionContext(ctx context.Context) (context.Context, error)
+
+	// Commit transaction from context.
+	Commit(ctx context.Context) error
+
+	// Rollback transaction from context.
+	Rollback(ctx context.Context) error
+
+	// ReserveStock reserves units of a product from a warehouse with enough of them available,
+	// returning the warehouse ID and the price of the product.
+	// It must return ErrInsufficientStock if no single warehouse has the quantity requested available.
+	ReserveStock(ctx context.Context, productID string, quantity int) (warehouseID string, price int, err error)
+
+	// ReleaseStock releases units of a product previously reserved from a warehouse.
+	ReleaseStock(ctx context.Context, productID, warehouseID string, quantity int) error
+
+	// DeductStock removes units of a product previously reserved from the stock of a warehouse.
+	DeductStock(ctx context.Context, productID, warehouseID string, quantity int) error
+
+	// CreateOrder records an order.
+	CreateOrder(ctx context.Context, order Order) error
+
+	// UpdateOrderStatus sets the status and the payment ID of a pending order.
+	// It must return ErrOrderNotPending if the order isn't pending anymore.
+	UpdateOrderStatus(ctx context.Context, id string, status Status, paymentID string) error
+
+	// GetOrder returns an order, or nil if it isn't found.
+	GetOrder(ctx context.Context, id string) (*Order, error)
+
+	// EnqueueEvent writes an event to the outbox.
+	EnqueueEvent(ctx context.Context, topic string, payload any) error
+}
+
+// PaymentProvider charges and refunds payments.
+type PaymentProvider interface {
+	// Charge the amount of an order, returning the payment ID.
+	// The order ID is used as an idempotency key, so retrying a charge doesn't charge it twice.
+	Charge(ctx context.Context, orderID string, amount int) (paymentID string, err error)
+
+	// Refund a payment.
+	// Refunding a payment already refunded must succeed.
+	Refund(ctx context.Context, paymentID string) error
+
+	// Lookup the payment of an order, returning ErrPaymentNotFound if it wasn't charged.
+	Lookup(ctx context.Context, orderID string) (paymentID string, err error)
+}
+
+// NewService creates an orders service.
+func NewService(store Store, payments PaymentProvider, log *slog.Logger) *Service {
+	return &Service{
+		store:    store,
+		payments: payments,
+		log:      log,
+	}
+}
+
+// Service for placing orders.
+type Service struct {
+	store    Store
+	payments PaymentProvider
+	log      *slog.Logger
+}
+
+// PlaceOrderParams used by PlaceOrder.
+type PlaceOrderParams struct {
+	ProductID string
+	Quantity  int
+}
+
+func (p *PlaceOrderParams) validate() error {
+	if p.ProductID == "" {
+		return ValidationError{"missing product ID"}
+	}
+	if p.Quantity < 1 {
+		return ValidationError{"quantity must be at least 1"}
+	}
+	return nil
+}
+
+// PlaceOrder reserves stock for an order, charges it, and marks it as paid.
+// If the order cannot be completed, the steps already taken are compensated and the canceled order is returned
+// along with the error.
+//
+// If the process stops midway (say, it crashes), the order is left pending with its stock reserved,
+// and must be canceled by CancelOrder.
+func (s *Service) PlaceOrder(ctx context.Context, params PlaceOrderParams) (*Order, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	order, err := s.reserve(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compensating actions must run even if the request is canceled.
+	compensateCtx := context.WithoutCancel(ctx)
+
+	paymentID, err := s.payments.Charge(ctx, order.ID, order.Amount)
+	if err != nil {
+		err = fmt.Errorf("cannot charge order: %w", err)
+		return s.compensate(compensateCtx, order, err)
+	}
+	order.PaymentID = paymentID
+
+	if err := s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.store.DeductStock(ctx, order.ProductID, order.WarehouseID, order.Quantity); err != nil {
+			return err
+		}
+		if err := s.store.UpdateOrderStatus(ctx, order.ID, StatusPaid, paymentID); err != nil {
+			return err
+		}
+		e := events.NewOrder(order.ID)
+		e.PaymentID = paymentID
+		return s.store.EnqueueEvent(ctx, TopicOrderPaid, e)
+	}); err != nil {
+		if rerr := s.payments.Refund(compensateCtx, paymentID); rerr != nil {
+			// The order is left pending, so the refund can be retried by CancelOrder.
+			s.log.Error("cannot refund payment of failed order",
+				slog.String("order", order.ID),
+				slog.String("payment", paymentID),
+				slog.Any("error", rerr),
+			)
+			return order, errors.Join(err, rerr)
+		}
+		return s.compensate(compensateCtx, order, err)
+	}
+	order.Status = StatusPaid
+	return order, nil
+}
+
+// reserve stock and record a pending order.
+func (s *Service) reserve(ctx context.Context, params PlaceOrderParams) (*Order, error) {
+	order := &Order{
+		ID:        newID(),
+		ProductID: params.ProductID,
+		Quantity:  params.Quantity,
+		Status:    StatusPending,
+	}
+	err := s.inTx(ctx, func(ctx context.Context) error {
+		warehouseID, price, err := s.store.ReserveStock(ctx, params.ProductID, params.Quantity)
+		if err != nil {
+			return err
+		}
+		order.WarehouseID = warehouseID
+		order.Amount = price * params.Quantity
+		if err := s.store.CreateOrder(ctx, *order); err != nil {
+			return err
+		}
+		return s.store.EnqueueEvent(ctx, TopicOrderCreated, events.NewOrder(order.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// compensate a failed order by releasing its reservation and canceling it.
+func (s *Service) compensate(ctx context.Context, order *Order, cause error) (*Order, error) {
+	// ErrOrderNotPending means the order was canceled concurrently by CancelOrder.
+	if err := s.cancel(ctx, order, cause); err != nil && !errors.Is(err, ErrOrderNotPending) {
+		s.log.Error("cannot cancel failed order",
+			slog.String("order", order.ID),
+			slog.Any("error", err),
+		)
+		return order, errors.Join(cause, err)
+	}
+	return order, cause
+}
+
+// cancel a pending order, releasing its reservation.
+func (s *Service) cancel(ctx context.Context, order *Order, cause error) error {
+	err := s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.store.ReleaseStock(ctx, order.ProductID, order.WarehouseID, order.Quantity); err != nil {
+			return err
+		}
+		if err := s.store.UpdateOrderStatus(ctx, order.ID, StatusCanceled, order.PaymentID); err != nil {
+			return err
+		}
+		e := events.NewOrder(order.ID)
+		if cause != nil {
+			e.Reason = cause.Error()
+		}
+		return s.store.EnqueueEvent(ctx, TopicOrderCanceled, e)
+	})
+	if err == nil {
+		order.Status = StatusCanceled
+	}
+	return err
+}
+
+// CancelOrder cancels a pending order left behind by an interrupted PlaceOrder, refunding its payment if charged.
+// It must not be called while PlaceOrder might still be running for the order, as the payment could be refunded
+// right before the order is marked as paid.
+func (s *Service) CancelOrder(ctx context.Context, id string) (*Order, error) {
+	if id == "" {
+		return nil, ValidationError{"missing order ID"}
+	}
+	order, err := s.store.GetOrder(ctx, id)
+	switch {
+	case err != nil:
+		return nil, err
+	case order == nil:
+		return nil, nil
+	case order.Status != StatusPending:
+		return order, ErrOrderNotPending
+	}
+	paymentID, err := s.payments.Lookup(ctx, order.ID)
+	if err == nil {
+		order.PaymentID = paymentID
+		err = s.payments.Refund(ctx, paymentID)
+	}
+	if err != nil && !errors.Is(err, ErrPaymentNotFound) {
+		return nil, fmt.Errorf("cannot refund order: %w", err)
+	}
+	if err := s.cancel(ctx, order, nil); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// GetOrder returns an order, or nil if it isn't found.
+func (s *Service) GetOrder(ctx context.Context, id string) (*Order, error) {
+	if id == "" {
+		return nil, ValidationError{"missing order ID"}
+	}
+	return s.store.GetOrder(ctx, id)
+}
+
+// inTx calls fn with a transaction, committing it if fn succeeds, and rolling it back otherwise.
+func (s *Service) inTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	txCtx, err := s.store.TransactionContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rerr := s.store.Rollback(txCtx); rerr != nil && ctx.Err() == nil {
+				s.log.Error("cannot rollback transaction", slog.Any("error", rerr))
+			}
+		}
+	}()
+	if err = fn(txCtx); err != nil {
+		return err
+	}
+	return s.store.Commit(txCtx)
+}
+
+// newID generates a random base-58 ID.
+func newID() string {
+	const (
+		alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz" // base58
+		size     = 11
+	)
+	var id = make([]byte, size)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	for i, p := range id {
+		id[i] = alphabet[int(p)%len(alphabet)] // discard everything but the least significant bits
+	}
+	return string(id)
+}
@@ -0,0 +1,266 @@
+package orders_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/orders"
+	"github.com/henvic/pgxtutorial/internal/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var force = flag.Bool("force", false, "Force cleaning the database before starting")
+
+func TestMain(m *testing.M) {
+	if os.Getenv("INTEGRATION_TESTDB") != "true" {
+		log.Printf("Skipping tests that require database connection")
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// setup a database with a product with 10 units in stock, priced at 100.
+func setup(t *testing.T) (*pgxpool.Pool, postgres.DB) {
+	t.Helper()
+	migration := sqltest.New(t, sqltest.Options{
+		Force:                   *force,
+		TemporaryDatabasePrefix: "test_orders_pkg", // Avoid a clash between database names of packages on parallel execution.
+		Files:                   os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := postgres.NewDB(pool, slog.Default())
+	if _, err := db.CreateProduct(context.Background(), inventory.CreateProductParams{
+		ID:          "product",
+		Name:        "A product name",
+		Description: "A great description",
+		Price:       100,
+	}); err != nil {
+		t.Fatalf("DB.CreateProduct() error = %v", err)
+	}
+	if err := db.SetProductStock(context.Background(), inventory.SetProductStockParams{
+		ProductID:   "product",
+		WarehouseID: "main",
+		Quantity:    10,
+	}); err != nil {
+		t.Fatalf("DB.SetProductStock() error = %v", err)
+	}
+	return pool, db
+}
+
+type stock struct {
+	Quantity int
+	Reserved int
+}
+
+func getStock(t *testing.T, pool *pgxpool.Pool) stock {
+	t.Helper()
+	var s stock
+	if err := pool.QueryRow(context.Background(), `SELECT "quantity", "reserved" FROM "product_stock" WHERE "product_id" = 'product'`).Scan(&s.Quantity, &s.Reserved); err != nil {
+		t.Fatalf("cannot get stock: %v", err)
+	}
+	return s
+}
+
+// topics of the events written to the outbox.
+func topics(t *testing.T, pool *pgxpool.Pool) []string {
+	t.Helper()
+	rows, err := pool.Query(context.Background(), `SELECT "topic" FROM "outbox" ORDER BY "id"`)
+	if err != nil {
+		t.Fatalf("cannot get outbox: %v", err)
+	}
+	defer rows.Close()
+	var topics []string
+	for rows.Next() {
+		var topic string
+		if err := rows.Scan(&topic); err != nil {
+			t.Fatalf("cannot scan outbox: %v", err)
+		}
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// failingDeduct store fails to deduct stock, after the payment is charged.
+type failingDeduct struct {
+	postgres.DB
+}
+
+func (failingDeduct) DeductStock(context.Context, string, string, int) error {
+	return errors.New("unexpected error")
+}
+
+func TestServicePlaceOrder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		params     orders.PlaceOrderParams
+		store      func(db postgres.DB) orders.Store
+		limit      int
+		wantStatus orders.Status
+		wantErr    string
+		wantStock  stock
+		wantTopics []string
+		wantCharge int
+	}{
+		{
+			name: "missing_product_id",
+			params: orders.PlaceOrderParams{
+				Quantity: 1,
+			},
+			wantErr:   "missing product ID",
+			wantStock: stock{Quantity: 10},
+		},
+		{
+			name: "invalid_quantity",
+			params: orders.PlaceOrderParams{
+				ProductID: "product",
+			},
+			wantErr:   "quantity must be at least 1",
+			wantStock: stock{Quantity: 10},
+		},
+		{
+			name: "paid",
+			params: orders.PlaceOrderParams{
+				ProductID: "product",
+				Quantity:  3,
+			},
+			wantStatus: orders.StatusPaid,
+			wantStock:  stock{Quantity: 7},
+			wantTopics: []string{orders.TopicOrderCreated, orders.TopicOrderPaid},
+			wantCharge: 300,
+		},
+		{
+			name: "insufficient_stock",
+			params: orders.PlaceOrderParams{
+				ProductID: "product",
+				Quantity:  11,
+			},
+			wantErr:   "insufficient stock",
+			wantStock: stock{Quantity: 10},
+		},
+		{
+			name: "payment_declined",
+			params: orders.PlaceOrderParams{
+				ProductID: "product",
+				Quantity:  3,
+			},
+			limit:      200,
+			wantStatus: orders.StatusCanceled,
+			wantErr:    "cannot charge order: payment declined",
+			wantStock:  stock{Quantity: 10},
+			wantTopics: []string{orders.TopicOrderCreated, orders.TopicOrderCanceled},
+		},
+		{
+			name: "refunded",
+			params: orders.PlaceOrderParams{
+				ProductID: "product",
+				Quantity:  3,
+			},
+			store: func(db postgres.DB) orders.Store {
+				return failingDeduct{db}
+			},
+			wantStatus: orders.StatusCanceled,
+			wantErr:    "unexpected error",
+			wantStock:  stock{Quantity: 10},
+			wantTopics: []string{orders.TopicOrderCreated, orders.TopicOrderCanceled},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			pool, db := setup(t)
+			var store orders.Store = db
+			if tt.store != nil {
+				store = tt.store(db)
+			}
+			payments := &orders.FakePayments{Limit: tt.limit}
+			s := orders.NewService(store, payments, slog.Default())
+
+			got, err := s.PlaceOrder(context.Background(), tt.params)
+			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
+				t.Errorf("Service.PlaceOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != nil {
+				if got.Status != tt.wantStatus {
+					t.Errorf("Service.PlaceOrder() status = %v, want %v", got.Status, tt.wantStatus)
+				}
+				if stored, err := s.GetOrder(context.Background(), got.ID); err != nil || stored.Status != tt.wantStatus || stored.WarehouseID != "main" {
+					t.Errorf("Service.GetOrder() = %+v, %v, want status %v from warehouse main", stored, err, tt.wantStatus)
+				}
+			} else if tt.wantStatus != "" {
+				t.Errorf("Service.PlaceOrder() returned no order, want status %v", tt.wantStatus)
+			}
+			if s := getStock(t, pool); s != tt.wantStock {
+				t.Errorf("stock = %+v, want %+v", s, tt.wantStock)
+			}
+			if topics := topics(t, pool); !cmp.Equal(tt.wantTopics, topics) {
+				t.Errorf("outbox topics don't match: %v", cmp.Diff(tt.wantTopics, topics))
+			}
+			if balance := payments.Balance(); balance != tt.wantCharge {
+				t.Errorf("charged %d, want %d", balance, tt.wantCharge)
+			}
+		})
+	}
+}
+
+func TestServiceCancelOrder(t *testing.T) {
+	t.Parallel()
+	pool, db := setup(t)
+	payments := &orders.FakePayments{}
+	s := orders.NewService(db, payments, slog.Default())
+
+	// Leave a charged order pending, as if PlaceOrder was interrupted.
+	ctx, err := db.TransactionContext(context.Background())
+	if err != nil {
+		t.Fatalf("DB.TransactionContext() error = %v", err)
+	}
+	warehouseID, _, err := db.ReserveStock(ctx, "product", 2)
+	if err != nil {
+		t.Fatalf("DB.ReserveStock() error = %v", err)
+	}
+	if err := db.CreateOrder(ctx, orders.Order{
+		ID:          "stale",
+		ProductID:   "product",
+		WarehouseID: warehouseID,
+		Quantity:    2,
+		Amount:      200,
+		Status:      orders.StatusPending,
+	}); err != nil {
+		t.Fatalf("DB.CreateOrder() error = %v", err)
+	}
+	if err := db.Commit(ctx); err != nil {
+		t.Fatalf("DB.Commit() error = %v", err)
+	}
+	if _, err := payments.Charge(context.Background(), "stale", 200); err != nil {
+		t.Fatalf("FakePayments.Charge() error = %v", err)
+	}
+
+	got, err := s.CancelOrder(context.Background(), "stale")
+	if err != nil {
+		t.Fatalf("Service.CancelOrder() error = %v", err)
+	}
+	if got.Status != orders.StatusCanceled || got.PaymentID == "" {
+		t.Errorf("Service.CancelOrder() = %+v, want canceled order with payment", got)
+	}
+	if s := getStock(t, pool); s != (stock{Quantity: 10}) {
+		t.Errorf("stock = %+v, want reservation released", s)
+	}
+	if balance := payments.Balance(); balance != 0 {
+		t.Errorf("charged %d, want payment refunded", balance)
+	}
+
+	if _, err := s.CancelOrder(context.Background(), "stale"); !errors.Is(err, orders.ErrOrderNotPending) {
+		t.Errorf("Service.CancelOrder() error = %v, want %v", err, orders.ErrOrderNotPending)
+	}
+	if got, err := s.CancelOrder(context.Background(), "not_found"); got != nil || err != nil {
+		t.Errorf("Service.CancelOrder() = %v, %v, want nil", got, err)
+	}
+}
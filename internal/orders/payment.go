@@ -0,0 +1,97 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrPaymentDeclined is returned when the payment provider declines a charge.
+	ErrPaymentDeclined = errors.New("payment declined")
+
+	// ErrPaymentNotFound is returned when a payment isn't found.
+	ErrPaymentNotFound = errors.New("payment not found")
+)
+
+// FakePayments is a PaymentProvider stub keeping payments in memory.
+// Use it for development and testing.
+type FakePayments struct {
+	// Limit of the amount charged. Charges above it are declined, unless it's zero.
+	Limit int
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment // by order ID
+}
+
+type fakePayment struct {
+	id       string
+	amount   int
+	refunded bool
+}
+
+// Charge the amount of an order.
+func (f *FakePayments) Charge(ctx context.Context, orderID string, amount int) (paymentID string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if f.Limit > 0 && amount > f.Limit {
+		return "", ErrPaymentDeclined
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if p, ok := f.payments[orderID]; ok {
+		return p.id, nil
+	}
+	if f.payments == nil {
+		f.payments = map[string]*fakePayment{}
+	}
+	p := &fakePayment{
+		id:     "pay_" + newID(),
+		amount: amount,
+	}
+	f.payments[orderID] = p
+	return p.id, nil
+}
+
+// Refund a payment.
+func (f *FakePayments) Refund(ctx context.Context, paymentID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range f.payments {
+		if p.id == paymentID {
+			p.refunded = true
+			return nil
+		}
+	}
+	return ErrPaymentNotFound
+}
+
+// Lookup the payment of an order.
+func (f *FakePayments) Lookup(ctx context.Context, orderID string) (paymentID string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if p, ok := f.payments[orderID]; ok {
+		return p.id, nil
+	}
+	return "", ErrPaymentNotFound
+}
+
+// Balance returns the amount charged and not refunded.
+func (f *FakePayments) Balance() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var balance int
+	for _, p := range f.payments {
+		if !p.refunded {
+			balance += p.amount
+		}
+	}
+	return balance
+}
@@ -0,0 +1,82 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// MaxDeadLetters is the maximum number of dead letters listed at once.
+const MaxDeadLetters = 1000
+
+// DeadLetter is an event the relay stopped trying to publish.
+type DeadLetter struct {
+	Event
+
+	// LastError is the reason of the last failed attempt to publish the event.
+	LastError string
+
+	DeadLetteredAt time.Time
+}
+
+// ErrDeadLetterNotFound is returned when an event isn't found on the dead letter queue.
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetterQueue of events the relay stopped trying to publish.
+type DeadLetterQueue interface {
+	// ListDeadLetters returns up to limit dead letters, the oldest events first.
+	ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error)
+
+	// GetDeadLetter returns a dead letter, or ErrDeadLetterNotFound.
+	GetDeadLetter(ctx context.Context, id int64) (*DeadLetter, error)
+
+	// ReplayDeadLetters moves events from the dead letter queue back to the outbox, resetting their attempts.
+	// Either all events are replayed, or none is and ErrDeadLetterNotFound is returned.
+	ReplayDeadLetters(ctx context.Context, ids []int64) error
+
+	// CountDeadLetters returns the depth of the dead letter queue.
+	CountDeadLetters(ctx context.Context) (int, error)
+}
+
+// RegisterMetrics registers the outbox.dead_letters gauge reporting the depth of the dead letter queue.
+func RegisterMetrics(meter metric.Meter, q DeadLetterQueue) error {
+	_, err := meter.Int64ObservableGauge("outbox.dead_letters",
+		metric.WithDescription("Number of events on the dead letter queue of the outbox."),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			n, err := q.CountDeadLetters(ctx)
+			if err != nil {
+				return err
+			}
+			o.Observe(int64(n))
+			return nil
+		}))
+	return err
+}
+
+// Backlog of the events waiting to be published.
+type Backlog interface {
+	// CountPendingEvents returns the number of events waiting to be published by topic,
+	// excluding the ones on the dead letter queue.
+	CountPendingEvents(ctx context.Context) (map[string]int, error)
+}
+
+// RegisterBacklogMetrics registers the outbox.pending gauge reporting the depth of the queue of events
+// waiting to be published to each topic, such as held back by a slow or failing destination.
+func RegisterBacklogMetrics(meter metric.Meter, b Backlog) error {
+	_, err := meter.Int64ObservableGauge("outbox.pending",
+		metric.WithDescription("Number of events waiting to be published to the topic."),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			pending, err := b.CountPendingEvents(ctx)
+			if err != nil {
+				return err
+			}
+			for topic, n := range pending {
+				o.Observe(int64(n), metric.WithAttributes(attribute.String("topic", topic)))
+			}
+			return nil
+		}))
+	return err
+}
@@ -0,0 +1,417 @@
+// Package outbox relays the events written to the outbox table, such as by the orders saga, to a Publisher.
+//
+// Events are relayed at least once: if the relay stops after publishing an event but before marking it
+// as published, the event is published again.
+//
+// The events of each destination (by default, their topic) are published in order, and the destinations in parallel,
+// so a slow or failing destination doesn't hold back the others.
+// An event failing to be published is retried by the next runs, holding back the events of its destination after it,
+// until it fails MaxAttempts times and is moved to the dead letter queue to be inspected and replayed.
+// An event throttled by a ThrottledPublisher is retried by the next runs too, but isn't counted as a failed attempt.
+//
+// Each run of the relay is traced as a new root span, as it isn't part of any request.
+// The span publishing an event links to the trace of the request that wrote it, if it was recorded,
+// and its own trace context is delivered with the event, so consumers can start their spans with StartConsumerSpan
+// as children of it, linked to the request, and the asynchronous work can be followed end-to-end.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Event written to the outbox.
+type Event struct {
+	ID      int64
+	Topic   string
+	Payload json.RawMessage
+
+	// TraceContext of the request that wrote the event, in the format of its propagator
+	// (such as the W3C traceparent header), if it was recorded.
+	TraceContext map[string]string
+
+	// DeliveryContext is the trace context of the span publishing the event, set by the relay
+	// for the Publisher to deliver with the event, such as in the headers of a message.
+	DeliveryContext map[string]string
+
+	// Attempts to publish the event that failed.
+	Attempts int
+
+	CreatedAt time.Time
+}
+
+// Publisher of events, such as a message broker.
+//
+//go:generate mockgen --build_flags=--mod=mod -package outbox -destination mock_outbox_test.go . Publisher,Store
+type Publisher interface {
+	// Publish an event.
+	// Consumers must handle duplicates, as an event might be published more than once.
+	Publish(ctx context.Context, e Event) error
+}
+
+// Store of events.
+// Methods are called within a transaction created by TransactionContext.
+type Store interface {
+	// TransactionContext returns a copy of the parent context with a transaction used by the other methods.
+	TransactionContext(ctx context.Context) (context.Context, error)
+
+	// Commit transaction from context.
+	Commit(ctx context.Context) error
+
+	// Rollback transaction from context.
+	Rollback(ctx context.Context) error
+
+	// ClaimOutboxEvents locks up to limit unpublished events, the oldest first, until the transaction ends.
+	// Events locked by other transactions are skipped, so multiple relays can run concurrently.
+	ClaimOutboxEvents(ctx context.Context, limit int) ([]Event, error)
+
+	// MarkOutboxEventsPublished marks events as published.
+	MarkOutboxEventsPublished(ctx context.Context, ids []int64) error
+
+	// FailOutboxEvent records a failed attempt to publish an event, with the reason.
+	// If deadLetter is set, the event is moved to the dead letter queue and isn't claimed anymore.
+	FailOutboxEvent(ctx context.Context, id int64, reason string, deadLetter bool) error
+}
+
+// Relay publishes the events of the outbox periodically.
+type Relay struct {
+	Store     Store
+	Publisher Publisher
+
+	// BatchSize is the maximum number of events claimed at once.
+	BatchSize int
+
+	// Interval between runs.
+	Interval time.Duration
+
+	// MaxAttempts to publish an event before moving it to the dead letter queue (0 retries it indefinitely).
+	MaxAttempts int
+
+	// Destination of an event, whose events are published in order (default: its topic).
+	// It should match the Destination of a ThrottledPublisher.
+	Destination func(Event) string
+
+	// Concurrency of the events published to each destination at once (default: 1).
+	// Events of a destination are only published in order, holding back the ones after an event that
+	// fails or is throttled, with a concurrency of 1. Otherwise, only the events failing or throttled are kept.
+	Concurrency int
+
+	Log *slog.Logger
+
+	// Tracer of the runs, and Propagator to extract the trace context of events with.
+	Tracer     trace.Tracer
+	Propagator propagation.TextMapPropagator
+
+	initOnce  sync.Once
+	stopOnce  sync.Once
+	abortOnce sync.Once
+	stop      chan struct{}
+	abort     chan struct{}
+	done      chan struct{}
+}
+
+func (r *Relay) init() {
+	r.initOnce.Do(func() {
+		r.stop = make(chan struct{})
+		r.abort = make(chan struct{})
+		r.done = make(chan struct{})
+	})
+}
+
+// Run relays events until the context is canceled or Shutdown is called.
+// A run relaying a full batch is followed by another without waiting, to catch up with a backlog.
+func (r *Relay) Run(ctx context.Context) error {
+	r.init()
+	defer close(r.done)
+
+	// Abort the work in progress if Shutdown times out.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.abort:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if r.relay(ctx) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.stop:
+			r.drain(ctx)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay a batch of events, logging errors.
+// It returns true if the batch was full, so there might be more events to relay.
+func (r *Relay) relay(ctx context.Context) (full bool) {
+	n, err := r.RelayOnce(ctx)
+	if err != nil && ctx.Err() == nil {
+		r.Log.Error("cannot relay outbox events", slog.Any("error", err))
+	}
+	return err == nil && n == r.BatchSize
+}
+
+// drain relays the events enqueued until now, such as by the last requests before shutting down.
+func (r *Relay) drain(ctx context.Context) {
+	for r.relay(ctx) {
+	}
+}
+
+// Shutdown stops the relay after relaying the pending events, waiting for it until the context is done.
+func (r *Relay) Shutdown(ctx context.Context) {
+	r.init()
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		r.Log.Error("outbox relay shutdown timed out", slog.Any("error", ctx.Err()))
+		r.abortOnce.Do(func() {
+			close(r.abort)
+		})
+	}
+}
+
+// RelayOnce publishes a batch of events, returning how many were published.
+// The destinations of the events are published to in parallel, and each stops at the first event that
+// cannot be published, keeping it and the ones after it for the next run, unless the event is moved to the
+// dead letter queue, or at the first event throttled with ErrThrottled, which isn't an error.
+func (r *Relay) RelayOnce(ctx context.Context) (n int, err error) {
+	ctx, span := r.Tracer.Start(ctx, "outbox.relay", trace.WithNewRoot())
+	var throttled int
+	defer func() {
+		span.SetAttributes(attribute.Int("outbox.published", n), attribute.Int("outbox.throttled", throttled))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	ctx, err = r.Store.TransactionContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var committed bool
+	defer func() {
+		if committed {
+			return
+		}
+		if rerr := r.Store.Rollback(ctx); rerr != nil && err == nil {
+			err = rerr
+		}
+	}()
+	events, err := r.Store.ClaimOutboxEvents(ctx, r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// The results are recorded on the store once every destination is done, as the transaction isn't safe for concurrent use.
+	results := make([]error, len(events))
+	var wg sync.WaitGroup
+	for _, indexes := range r.destinations(events) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.publishDestination(ctx, events, indexes, results)
+		}()
+	}
+	wg.Wait()
+
+	var (
+		published = make([]int64, 0, len(events))
+		failed    bool
+		perr      error
+	)
+	for i, e := range events {
+		err := results[i]
+		switch {
+		case err == nil:
+			published = append(published, e.ID)
+			continue
+		case errors.Is(err, errHeldBack):
+			continue
+		case errors.Is(err, ErrThrottled):
+			throttled++
+			continue
+		}
+		failed = true
+		deadLetter := r.deadLetter(e)
+		if ferr := r.Store.FailOutboxEvent(ctx, e.ID, err.Error(), deadLetter); ferr != nil {
+			return 0, ferr
+		}
+		if !deadLetter {
+			perr = errors.Join(perr, err)
+			continue
+		}
+		r.Log.Warn("outbox event moved to the dead letter queue",
+			slog.Int64("id", e.ID),
+			slog.String("topic", e.Topic),
+			slog.Any("error", err),
+		)
+	}
+	if len(published) != 0 {
+		if err := r.Store.MarkOutboxEventsPublished(ctx, published); err != nil {
+			return 0, err
+		}
+	}
+	if len(published) != 0 || failed {
+		if err := r.Store.Commit(ctx); err != nil {
+			return 0, err
+		}
+		committed = true
+	}
+	return len(published), perr
+}
+
+// errHeldBack is the result of an event held back by a previous event of its destination that wasn't published.
+var errHeldBack = errors.New("held back by a previous event")
+
+// destinations groups the indexes of the events by their destination, in order.
+func (r *Relay) destinations(events []Event) map[string][]int {
+	destinations := map[string][]int{}
+	for i, e := range events {
+		name := e.Topic
+		if r.Destination != nil {
+			name = r.Destination(e)
+		}
+		destinations[name] = append(destinations[name], i)
+	}
+	return destinations
+}
+
+// deadLetter returns whether an event failing to be published is moved to the dead letter queue.
+func (r *Relay) deadLetter(e Event) bool {
+	return r.MaxAttempts > 0 && e.Attempts+1 >= r.MaxAttempts
+}
+
+// publishDestination publishes the events of a destination with the given indexes, setting their results.
+func (r *Relay) publishDestination(ctx context.Context, events []Event, indexes []int, results []error) {
+	if r.Concurrency <= 1 {
+		for j, i := range indexes {
+			err := r.publish(ctx, events[i])
+			results[i] = err
+			if err != nil && (errors.Is(err, ErrThrottled) || !r.deadLetter(events[i])) {
+				for _, k := range indexes[j+1:] {
+					results[k] = errHeldBack
+				}
+				return
+			}
+		}
+		return
+	}
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, r.Concurrency)
+	)
+	for _, i := range indexes {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = r.publish(ctx, events[i])
+		}()
+	}
+	wg.Wait()
+}
+
+// publish an event within a span linked to the trace of the request that wrote it.
+func (r *Relay) publish(ctx context.Context, e Event) error {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.Int64("outbox.event_id", e.ID),
+			attribute.String("outbox.topic", e.Topic),
+			attribute.Int("outbox.attempt", e.Attempts+1),
+		),
+	}
+	if sc := spanContext(r.Propagator, e.TraceContext); sc.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+	}
+	ctx, span := r.Tracer.Start(ctx, "outbox.publish "+e.Topic, opts...)
+	defer span.End()
+	if r.Propagator != nil {
+		carrier := propagation.MapCarrier{}
+		r.Propagator.Inject(ctx, carrier)
+		e.DeliveryContext = carrier
+	}
+	err := r.Publisher.Publish(ctx, e)
+	if errors.Is(err, ErrThrottled) {
+		span.SetAttributes(attribute.Bool("outbox.throttled", true))
+		return err
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot publish event %d: %w", e.ID, err)
+	}
+	return nil
+}
+
+// spanContext extracts the span context from a trace context in the format of the propagator, if any.
+func spanContext(propagator propagation.TextMapPropagator, tc map[string]string) trace.SpanContext {
+	if len(tc) == 0 || propagator == nil {
+		return trace.SpanContext{}
+	}
+	return trace.SpanContextFromContext(propagator.Extract(context.Background(), propagation.MapCarrier(tc)))
+}
+
+// StartConsumerSpan starts a span consuming an event, such as by a subscriber of a message broker.
+// The span is a child of the span publishing the event, if its DeliveryContext was delivered, or of the span in ctx otherwise,
+// and it links to the trace of the request that wrote the event, if it was recorded.
+func StartConsumerSpan(ctx context.Context, tracer trace.Tracer, propagator propagation.TextMapPropagator, e Event) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.Int64("outbox.event_id", e.ID),
+			attribute.String("outbox.topic", e.Topic),
+		),
+	}
+	if sc := spanContext(propagator, e.TraceContext); sc.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+	}
+	if sc := spanContext(propagator, e.DeliveryContext); sc.IsValid() {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+	}
+	return tracer.Start(ctx, "outbox.consume "+e.Topic, opts...)
+}
+
+// LogPublisher publishes events to a log, such as for development.
+type LogPublisher struct {
+	Log *slog.Logger
+}
+
+// Publish an event to the log.
+func (p LogPublisher) Publish(ctx context.Context, e Event) error {
+	p.Log.InfoContext(ctx, "outbox event",
+		slog.Int64("id", e.ID),
+		slog.String("topic", e.Topic),
+		slog.String("payload", string(e.Payload)),
+	)
+	return nil
+}
@@ -0,0 +1,272 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	"github.com/henvic/pgxtutorial/internal/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var force = flag.Bool("force", false, "Force cleaning the database before starting")
+
+func TestMain(m *testing.M) {
+	if os.Getenv("INTEGRATION_TESTDB") != "true" {
+		log.Printf("Skipping tests that require database connection")
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func setup(t *testing.T, tp trace.TracerProvider) (*pgxpool.Pool, postgres.DB) {
+	t.Helper()
+	migration := sqltest.New(t, sqltest.Options{
+		Force:                   *force,
+		TemporaryDatabasePrefix: "test_outbox_pkg", // Avoid a clash between database names of packages on parallel execution.
+		Files:                   os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	return pool, postgres.NewDB(pool, slog.Default(), postgres.WithTracing(tp, propagation.TraceContext{}))
+}
+
+// unpublished events of the outbox.
+func unpublished(t *testing.T, pool *pgxpool.Pool) int {
+	t.Helper()
+	var n int
+	if err := pool.QueryRow(context.Background(), `SELECT count(*) FROM "outbox" WHERE "published_at" IS NULL`).Scan(&n); err != nil {
+		t.Fatalf("cannot count unpublished events: %v", err)
+	}
+	return n
+}
+
+type publisher struct {
+	mu     sync.Mutex
+	topics []string
+	fail   string
+}
+
+func (p *publisher) Publish(ctx context.Context, e outbox.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e.Topic == p.fail {
+		return errors.New("broker unavailable")
+	}
+	p.topics = append(p.topics, e.Topic)
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	t.Parallel()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	pool, db := setup(t, tp)
+
+	// The first event is enqueued within a request trace, and the second without one.
+	reqCtx, reqSpan := tp.Tracer("test").Start(context.Background(), "request")
+	if err := db.EnqueueEvent(reqCtx, "order.created", map[string]string{"id": "1"}); err != nil {
+		t.Fatalf("DB.EnqueueEvent() error = %v", err)
+	}
+	reqSpan.End()
+	if err := db.EnqueueEvent(context.Background(), "order.canceled", map[string]string{"id": "2"}); err != nil {
+		t.Fatalf("DB.EnqueueEvent() error = %v", err)
+	}
+	if err := db.EnqueueEvent(context.Background(), "order.paid", map[string]string{"id": "3"}); err != nil {
+		t.Fatalf("DB.EnqueueEvent() error = %v", err)
+	}
+
+	pub := &publisher{fail: "order.paid"}
+	relay := &outbox.Relay{
+		Store:      db,
+		Publisher:  pub,
+		BatchSize:  10,
+		Log:        slog.Default(),
+		Tracer:     tp.Tracer("outbox"),
+		Propagator: propagation.TraceContext{},
+	}
+	n, err := relay.RelayOnce(context.Background())
+	if err == nil || err.Error() != "cannot publish event 3: broker unavailable" {
+		t.Errorf("Relay.RelayOnce() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Relay.RelayOnce() = %d, want 2", n)
+	}
+	// Topics are published to in parallel.
+	if want := []string{"order.canceled", "order.created"}; !cmp.Equal(want, pub.topics, cmpopts.SortSlices(func(a, b string) bool { return a < b })) {
+		t.Errorf("published topics don't match: %v", cmp.Diff(want, pub.topics))
+	}
+	if got := unpublished(t, pool); got != 1 {
+		t.Errorf("unpublished events = %d, want 1", got)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	run, ok := spans["outbox.relay"]
+	switch {
+	case !ok:
+		t.Fatal("outbox.relay span not found")
+	case run.Parent().IsValid():
+		t.Error("outbox.relay span should be a root span")
+	}
+	created := spans["outbox.publish order.created"]
+	if created == nil {
+		t.Fatal("outbox.publish order.created span not found")
+	}
+	if created.Parent().SpanID() != run.SpanContext().SpanID() {
+		t.Error("outbox.publish span should be a child of the outbox.relay span")
+	}
+	if links := created.Links(); len(links) != 1 || links[0].SpanContext.TraceID() != reqSpan.SpanContext().TraceID() {
+		t.Errorf("outbox.publish span should link to the request trace, got links %v", links)
+	}
+	if canceled := spans["outbox.publish order.canceled"]; canceled == nil || len(canceled.Links()) != 0 {
+		t.Error("outbox.publish order.canceled span should have no links")
+	}
+
+	// The event that failed is published by the next run.
+	pub.fail = ""
+	if n, err := relay.RelayOnce(context.Background()); err != nil || n != 1 {
+		t.Errorf("Relay.RelayOnce() = %d, %v, want 1, nil", n, err)
+	}
+	if got := unpublished(t, pool); got != 0 {
+		t.Errorf("unpublished events = %d, want 0", got)
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	t.Parallel()
+	pool, db := setup(t, noop.NewTracerProvider())
+	ctx := context.Background()
+	if err := db.EnqueueEvent(ctx, "order.paid", map[string]string{"id": "1"}); err != nil {
+		t.Fatalf("DB.EnqueueEvent() error = %v", err)
+	}
+	if err := db.EnqueueEvent(ctx, "order.canceled", map[string]string{"id": "2"}); err != nil {
+		t.Fatalf("DB.EnqueueEvent() error = %v", err)
+	}
+
+	pub := &publisher{fail: "order.paid"}
+	relay := &outbox.Relay{
+		Store:       db,
+		Publisher:   pub,
+		BatchSize:   10,
+		MaxAttempts: 2,
+		Log:         slog.Default(),
+		Tracer:      noop.NewTracerProvider().Tracer("outbox"),
+	}
+
+	// The first attempt fails, without holding back the event of another topic after it.
+	if n, err := relay.RelayOnce(ctx); err == nil || n != 1 {
+		t.Errorf("Relay.RelayOnce() = %d, %v, want 1 and an error", n, err)
+	}
+	if got := unpublished(t, pool); got != 1 {
+		t.Errorf("unpublished events = %d, want 1", got)
+	}
+	if pending, err := db.CountPendingEvents(ctx); err != nil || !cmp.Equal(pending, map[string]int{"order.paid": 1}) {
+		t.Errorf("DB.CountPendingEvents() = %v, %v, want 1 event of order.paid", pending, err)
+	}
+
+	// The second attempt fails too, moving the event to the dead letter queue.
+	if n, err := relay.RelayOnce(ctx); err != nil || n != 0 {
+		t.Errorf("Relay.RelayOnce() = %d, %v, want 0, nil", n, err)
+	}
+	if want := []string{"order.canceled"}; !cmp.Equal(want, pub.topics) {
+		t.Errorf("published topics don't match: %v", cmp.Diff(want, pub.topics))
+	}
+	if n, err := db.CountDeadLetters(ctx); err != nil || n != 1 {
+		t.Errorf("DB.CountDeadLetters() = %d, %v, want 1, nil", n, err)
+	}
+	if pending, err := db.CountPendingEvents(ctx); err != nil || len(pending) != 0 {
+		t.Errorf("DB.CountPendingEvents() = %v, %v, want none", pending, err)
+	}
+	letters, err := db.ListDeadLetters(ctx, 10)
+	if err != nil {
+		t.Fatalf("DB.ListDeadLetters() error = %v", err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("DB.ListDeadLetters() returned %d dead letters, want 1", len(letters))
+	}
+	d, err := db.GetDeadLetter(ctx, letters[0].ID)
+	if err != nil {
+		t.Fatalf("DB.GetDeadLetter() error = %v", err)
+	}
+	if d.Topic != "order.paid" || d.Attempts != 2 || d.LastError != "cannot publish event 1: broker unavailable" || d.DeadLetteredAt.IsZero() {
+		t.Errorf("DB.GetDeadLetter() = %+v", d)
+	}
+	if _, err := db.GetDeadLetter(ctx, 2); err != outbox.ErrDeadLetterNotFound {
+		t.Errorf("DB.GetDeadLetter() of a published event error = %v, want %v", err, outbox.ErrDeadLetterNotFound)
+	}
+
+	// Replaying events not on the dead letter queue replays none.
+	if err := db.ReplayDeadLetters(ctx, []int64{d.ID, 2}); err != outbox.ErrDeadLetterNotFound {
+		t.Errorf("DB.ReplayDeadLetters() error = %v, want %v", err, outbox.ErrDeadLetterNotFound)
+	}
+	if n, err := db.CountDeadLetters(ctx); err != nil || n != 1 {
+		t.Errorf("DB.CountDeadLetters() = %d, %v, want 1, nil", n, err)
+	}
+
+	if err := db.ReplayDeadLetters(ctx, []int64{d.ID}); err != nil {
+		t.Errorf("DB.ReplayDeadLetters() error = %v", err)
+	}
+	pub.fail = ""
+	if n, err := relay.RelayOnce(ctx); err != nil || n != 1 {
+		t.Errorf("Relay.RelayOnce() = %d, %v, want 1, nil", n, err)
+	}
+	if got := unpublished(t, pool); got != 0 {
+		t.Errorf("unpublished events = %d, want 0", got)
+	}
+	if n, err := db.CountDeadLetters(ctx); err != nil || n != 0 {
+		t.Errorf("DB.CountDeadLetters() = %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestRelayShutdown(t *testing.T) {
+	t.Parallel()
+	pool, db := setup(t, noop.NewTracerProvider())
+	ctx := context.Background()
+	pub := &publisher{}
+	relay := &outbox.Relay{
+		Store:     db,
+		Publisher: pub,
+		BatchSize: 10,
+		Interval:  time.Hour,
+		Log:       slog.Default(),
+		Tracer:    noop.NewTracerProvider().Tracer("outbox"),
+	}
+	ec := make(chan error, 1)
+	go func() {
+		ec <- relay.Run(ctx)
+	}()
+
+	// The event is enqueued while the relay waits for the next run, so it's relayed by draining on shutdown.
+	if err := db.EnqueueEvent(ctx, "order.created", map[string]string{"id": "1"}); err != nil {
+		t.Fatalf("DB.EnqueueEvent() error = %v", err)
+	}
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	relay.Shutdown(shutdownCtx)
+	if err := <-ec; err != nil {
+		t.Errorf("Relay.Run() error = %v", err)
+	}
+	if got := unpublished(t, pool); got != 0 {
+		t.Errorf("unpublished events = %d, want 0", got)
+	}
+	if want := []string{"order.created"}; !cmp.Equal(want, pub.topics) {
+		t.Errorf("published topics don't match: %v", cmp.Diff(want, pub.topics))
+	}
+}
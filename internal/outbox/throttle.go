@@ -0,0 +1,216 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrThrottled is returned by a ThrottledPublisher when an event isn't published as its destination is at its limits.
+// The relay keeps a throttled event for its next run, without counting it as a failed attempt.
+var ErrThrottled = errors.New("event delivery throttled")
+
+// Limit of the delivery of events to a destination.
+type Limit struct {
+	// Rate of events per second, allowing bursts of up to a second of events (0 doesn't limit the rate).
+	Rate float64
+
+	// Concurrency of the events being published at once (0 doesn't limit it).
+	Concurrency int
+}
+
+// ThrottledPublisher publishes events through a Publisher with the limits of their destination,
+// so a slow or overwhelmed destination, such as a webhook, doesn't hold every goroutine publishing events,
+// and isn't flooded with retries.
+//
+// The rate of a destination slows down by half when publishing an event to it fails or is slow,
+// down to a tenth of its Limit, and recovers by a tenth of its Limit with each event published in time.
+type ThrottledPublisher struct {
+	Publisher Publisher
+
+	// Destination of an event, whose limits apply to it (default: its topic).
+	Destination func(Event) string
+
+	// Limits of destinations, and DefaultLimit of the ones without limits of their own.
+	Limits       map[string]Limit
+	DefaultLimit Limit
+
+	// MaxWait for the rate limit of a destination before throttling an event (0 throttles it rather than waiting).
+	MaxWait time.Duration
+
+	// SlowPublish is how long publishing an event takes for its destination to slow down (0 only slows down on failures).
+	SlowPublish time.Duration
+
+	mu           sync.Mutex
+	destinations map[string]*destination
+}
+
+// destination of events, and the state of its limits.
+type destination struct {
+	limit Limit
+
+	rate   float64 // Current rate, adapted to the responsiveness of the destination.
+	tokens float64 // Events that can be published now, negative if reserved by waiting events.
+	last   time.Time
+
+	inflight  int
+	waiting   int
+	throttled int64
+}
+
+// Publish the event, or return ErrThrottled if its destination is at its limits.
+func (p *ThrottledPublisher) Publish(ctx context.Context, e Event) error {
+	d, err := p.acquire(ctx, e)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = p.Publisher.Publish(ctx, e)
+	p.release(d, err != nil || (p.SlowPublish > 0 && time.Since(start) > p.SlowPublish))
+	return err
+}
+
+// acquire a slot to publish the event to its destination, waiting for its rate limit, if needed.
+func (p *ThrottledPublisher) acquire(ctx context.Context, e Event) (*destination, error) {
+	name := e.Topic
+	if p.Destination != nil {
+		name = p.Destination(e)
+	}
+	now := time.Now()
+	p.mu.Lock()
+	d := p.destination(name, now)
+	if d.limit.Concurrency > 0 && d.inflight >= d.limit.Concurrency {
+		d.throttled++
+		p.mu.Unlock()
+		return nil, ErrThrottled
+	}
+	wait := d.reserve(now)
+	if wait > p.MaxWait {
+		d.tokens++
+		d.throttled++
+		p.mu.Unlock()
+		return nil, ErrThrottled
+	}
+	d.inflight++
+	if wait <= 0 {
+		p.mu.Unlock()
+		return d, nil
+	}
+	d.waiting++
+	p.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		p.mu.Lock()
+		d.waiting--
+		p.mu.Unlock()
+		return d, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		d.waiting--
+		d.inflight--
+		d.tokens++
+		p.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// release the slot of an event published to the destination, slowing it down if it failed or was slow.
+func (p *ThrottledPublisher) release(d *destination, slow bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d.inflight--
+	if d.limit.Rate == 0 {
+		return
+	}
+	if slow {
+		d.rate = max(d.rate/2, d.limit.Rate/10)
+	} else {
+		d.rate = min(d.rate+d.limit.Rate/10, d.limit.Rate)
+	}
+}
+
+// destination returns the state of the destination with the given name, creating it if needed.
+// p.mu must be held.
+func (p *ThrottledPublisher) destination(name string, now time.Time) *destination {
+	if d, ok := p.destinations[name]; ok {
+		return d
+	}
+	if p.destinations == nil {
+		p.destinations = map[string]*destination{}
+	}
+	limit, ok := p.Limits[name]
+	if !ok {
+		limit = p.DefaultLimit
+	}
+	d := &destination{
+		limit:  limit,
+		rate:   limit.Rate,
+		tokens: max(limit.Rate, 1),
+		last:   now,
+	}
+	p.destinations[name] = d
+	return d
+}
+
+// reserve a token of the rate limit of the destination, returning how long to wait for it.
+func (d *destination) reserve(now time.Time) time.Duration {
+	if d.limit.Rate == 0 {
+		return 0
+	}
+	d.tokens = min(d.tokens+now.Sub(d.last).Seconds()*d.rate, max(d.rate, 1))
+	d.last = now
+	d.tokens--
+	if d.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-d.tokens / d.rate * float64(time.Second))
+}
+
+// RegisterMetrics exposes the events of each destination being published as the outbox.delivery.inflight gauge,
+// the ones waiting for its rate limit as the outbox.delivery.waiting gauge, its current rate as the
+// outbox.delivery.rate gauge, and the throttled ones as the outbox.delivery.throttled counter.
+func (p *ThrottledPublisher) RegisterMetrics(meter metric.Meter) error {
+	inflight, err := meter.Int64ObservableGauge("outbox.delivery.inflight",
+		metric.WithDescription("Events being published to the destination."))
+	if err != nil {
+		return err
+	}
+	waiting, err := meter.Int64ObservableGauge("outbox.delivery.waiting",
+		metric.WithDescription("Events waiting for the rate limit of the destination."))
+	if err != nil {
+		return err
+	}
+	rate, err := meter.Float64ObservableGauge("outbox.delivery.rate",
+		metric.WithDescription("Current rate limit of the destination, slowed down when it fails or is slow."),
+		metric.WithUnit("{event}/s"))
+	if err != nil {
+		return err
+	}
+	throttled, err := meter.Int64ObservableCounter("outbox.delivery.throttled",
+		metric.WithDescription("Events not published as the destination was at its limits."))
+	if err != nil {
+		return err
+	}
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for name, d := range p.destinations {
+			attrs := metric.WithAttributes(attribute.String("destination", name))
+			o.ObserveInt64(inflight, int64(d.inflight), attrs)
+			o.ObserveInt64(waiting, int64(d.waiting), attrs)
+			o.ObserveInt64(throttled, d.throttled, attrs)
+			if d.limit.Rate != 0 {
+				o.ObserveFloat64(rate, d.rate, attrs)
+			}
+		}
+		return nil
+	}, inflight, waiting, rate, throttled)
+	return err
+}
@@ -0,0 +1,320 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// publisherFunc adapts a function to outbox.Publisher.
+type publisherFunc func(ctx context.Context, e outbox.Event) error
+
+func (f publisherFunc) Publish(ctx context.Context, e outbox.Event) error {
+	return f(ctx, e)
+}
+
+func TestThrottledPublisherConcurrency(t *testing.T) {
+	t.Parallel()
+	started, unblock := make(chan struct{}), make(chan struct{})
+	p := &outbox.ThrottledPublisher{
+		Publisher: publisherFunc(func(ctx context.Context, e outbox.Event) error {
+			if e.Topic == "slow" {
+				close(started)
+				<-unblock
+			}
+			return nil
+		}),
+		DefaultLimit: outbox.Limit{Concurrency: 1},
+	}
+	done := make(chan error)
+	go func() {
+		done <- p.Publish(context.Background(), outbox.Event{ID: 1, Topic: "slow"})
+	}()
+	<-started
+	if err := p.Publish(context.Background(), outbox.Event{ID: 2, Topic: "slow"}); err != outbox.ErrThrottled {
+		t.Errorf("ThrottledPublisher.Publish() to a destination at its concurrency limit error = %v, want %v", err, outbox.ErrThrottled)
+	}
+	if err := p.Publish(context.Background(), outbox.Event{ID: 3, Topic: "fast"}); err != nil {
+		t.Errorf("ThrottledPublisher.Publish() to another destination error = %v", err)
+	}
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Errorf("ThrottledPublisher.Publish() error = %v", err)
+	}
+	if err := p.Publish(context.Background(), outbox.Event{ID: 2, Topic: "fast"}); err != nil {
+		t.Errorf("ThrottledPublisher.Publish() after the destination was released error = %v", err)
+	}
+}
+
+func TestThrottledPublisherRate(t *testing.T) {
+	t.Parallel()
+	var published int
+	p := &outbox.ThrottledPublisher{
+		Publisher: publisherFunc(func(ctx context.Context, e outbox.Event) error {
+			published++
+			return nil
+		}),
+		Destination: func(e outbox.Event) string { return "webhook" },
+		Limits:      map[string]outbox.Limit{"webhook": {Rate: 20}},
+	}
+	// Up to a second of events is published at once.
+	for i := range 20 {
+		if err := p.Publish(context.Background(), outbox.Event{ID: int64(i)}); err != nil {
+			t.Fatalf("ThrottledPublisher.Publish() error = %v", err)
+		}
+	}
+	if err := p.Publish(context.Background(), outbox.Event{ID: 20}); err != outbox.ErrThrottled {
+		t.Errorf("ThrottledPublisher.Publish() past the rate limit error = %v, want %v", err, outbox.ErrThrottled)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.MaxWait = time.Second
+	if err := p.Publish(ctx, outbox.Event{ID: 20}); err != context.Canceled {
+		t.Errorf("ThrottledPublisher.Publish() waiting with a canceled context error = %v, want %v", err, context.Canceled)
+	}
+	start := time.Now()
+	if err := p.Publish(context.Background(), outbox.Event{ID: 20}); err != nil {
+		t.Errorf("ThrottledPublisher.Publish() waiting for the rate limit error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("ThrottledPublisher.Publish() took %v, want to wait for the rate limit", elapsed)
+	}
+	if published != 21 {
+		t.Errorf("published %d events, want 21", published)
+	}
+}
+
+func TestThrottledPublisherSlowdown(t *testing.T) {
+	t.Parallel()
+	errUnavailable := errors.New("webhook unavailable")
+	var fail bool
+	p := &outbox.ThrottledPublisher{
+		Publisher: publisherFunc(func(ctx context.Context, e outbox.Event) error {
+			if fail {
+				return errUnavailable
+			}
+			return nil
+		}),
+		DefaultLimit: outbox.Limit{Rate: 100},
+	}
+	reader := sdkmetric.NewManualReader()
+	if err := p.RegisterMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("outbox")); err != nil {
+		t.Fatalf("ThrottledPublisher.RegisterMetrics() error = %v", err)
+	}
+	rate := func() float64 {
+		t.Helper()
+		var rm metricdata.ResourceMetrics
+		if err := reader.Collect(context.Background(), &rm); err != nil {
+			t.Fatalf("cannot collect metrics: %v", err)
+		}
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if g, ok := m.Data.(metricdata.Gauge[float64]); ok && m.Name == "outbox.delivery.rate" && len(g.DataPoints) == 1 {
+					return g.DataPoints[0].Value
+				}
+			}
+		}
+		t.Fatal("outbox.delivery.rate not found")
+		return 0
+	}
+
+	fail = true
+	for range 5 {
+		if err := p.Publish(context.Background(), outbox.Event{Topic: "order.paid"}); err != errUnavailable {
+			t.Fatalf("ThrottledPublisher.Publish() error = %v, want %v", err, errUnavailable)
+		}
+	}
+	if got := rate(); got != 10 {
+		t.Errorf("rate after failures = %v, want it slowed down to a tenth of the limit", got)
+	}
+	fail = false
+	for range 3 {
+		if err := p.Publish(context.Background(), outbox.Event{Topic: "order.paid"}); err != nil {
+			t.Fatalf("ThrottledPublisher.Publish() error = %v", err)
+		}
+	}
+	if got := rate(); got != 40 {
+		t.Errorf("rate after recovering = %v, want 40", got)
+	}
+}
+
+func TestRelayThrottled(t *testing.T) {
+	t.Parallel()
+	pool, db := setup(t, noop.NewTracerProvider())
+	ctx := context.Background()
+	if err := db.EnqueueEvent(ctx, "order.paid", map[string]string{"id": "1"}); err != nil {
+		t.Fatalf("DB.EnqueueEvent() error = %v", err)
+	}
+	if err := db.EnqueueEvent(ctx, "order.paid", map[string]string{"id": "2"}); err != nil {
+		t.Fatalf("DB.EnqueueEvent() error = %v", err)
+	}
+	pub := &publisher{}
+	relay := &outbox.Relay{
+		Store: db,
+		Publisher: &outbox.ThrottledPublisher{
+			Publisher:    pub,
+			DefaultLimit: outbox.Limit{Rate: 1},
+		},
+		BatchSize:   10,
+		MaxAttempts: 1,
+		Log:         slog.Default(),
+		Tracer:      noop.NewTracerProvider().Tracer("outbox"),
+	}
+	// The second event is throttled, and kept for the next run without counting as an attempt.
+	if n, err := relay.RelayOnce(ctx); err != nil || n != 1 {
+		t.Errorf("Relay.RelayOnce() = %d, %v, want 1, nil", n, err)
+	}
+	if got := unpublished(t, pool); got != 1 {
+		t.Errorf("unpublished events = %d, want 1", got)
+	}
+	if n, err := db.CountDeadLetters(ctx); err != nil || n != 0 {
+		t.Errorf("DB.CountDeadLetters() = %d, %v, want 0, nil", n, err)
+	}
+}
+
+// memStore of events, without transactions.
+type memStore struct {
+	mu        sync.Mutex
+	events    []outbox.Event
+	published []int64
+	failed    []int64
+}
+
+func (s *memStore) TransactionContext(ctx context.Context) (context.Context, error) { return ctx, nil }
+func (s *memStore) Commit(ctx context.Context) error                                { return nil }
+func (s *memStore) Rollback(ctx context.Context) error                              { return nil }
+
+func (s *memStore) ClaimOutboxEvents(ctx context.Context, limit int) ([]outbox.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []outbox.Event
+	for _, e := range s.events {
+		if len(events) < limit && !slices.Contains(s.published, e.ID) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (s *memStore) MarkOutboxEventsPublished(ctx context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published = append(s.published, ids...)
+	return nil
+}
+
+func (s *memStore) FailOutboxEvent(ctx context.Context, id int64, reason string, deadLetter bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = append(s.failed, id)
+	return nil
+}
+
+func TestRelayDestinations(t *testing.T) {
+	t.Parallel()
+	store := &memStore{events: []outbox.Event{
+		{ID: 1, Topic: "webhook"},
+		{ID: 2, Topic: "webhook"},
+		{ID: 3, Topic: "webhook"},
+		{ID: 4, Topic: "order.paid"},
+		{ID: 5, Topic: "order.paid"},
+	}}
+	fast := make(chan struct{})
+	relay := &outbox.Relay{
+		Store: store,
+		Publisher: &outbox.ThrottledPublisher{
+			Publisher: publisherFunc(func(ctx context.Context, e outbox.Event) error {
+				switch e.ID {
+				case 1:
+					// The slow destination doesn't hold back the others.
+					select {
+					case <-fast:
+					case <-time.After(5 * time.Second):
+						t.Error("events of another destination weren't published while the webhook was slow")
+					}
+				case 5:
+					close(fast)
+				}
+				return nil
+			}),
+			Limits: map[string]outbox.Limit{"webhook": {Rate: 1}},
+		},
+		BatchSize:   10,
+		MaxAttempts: 1,
+		Log:         slog.Default(),
+		Tracer:      noop.NewTracerProvider().Tracer("outbox"),
+	}
+	// The second event of the webhook is throttled, holding back the third one, while the other topic is published.
+	if n, err := relay.RelayOnce(context.Background()); err != nil || n != 3 {
+		t.Errorf("Relay.RelayOnce() = %d, %v, want 3, nil", n, err)
+	}
+	slices.Sort(store.published)
+	if want := []int64{1, 4, 5}; !slices.Equal(store.published, want) || len(store.failed) != 0 {
+		t.Errorf("published events %v and failed %v, want %v and none", store.published, store.failed, want)
+	}
+}
+
+func TestRelayConcurrency(t *testing.T) {
+	t.Parallel()
+	store := &memStore{events: []outbox.Event{
+		{ID: 1, Topic: "webhook"},
+		{ID: 2, Topic: "webhook"},
+		{ID: 3, Topic: "webhook"},
+	}}
+	var (
+		mu          sync.Mutex
+		inflight    int
+		maxInflight int
+		both        = make(chan struct{})
+		bothOnce    sync.Once
+	)
+	relay := &outbox.Relay{
+		Store: store,
+		Publisher: &outbox.ThrottledPublisher{
+			Publisher: publisherFunc(func(ctx context.Context, e outbox.Event) error {
+				mu.Lock()
+				inflight++
+				if maxInflight = max(maxInflight, inflight); inflight == 2 {
+					bothOnce.Do(func() { close(both) })
+				}
+				mu.Unlock()
+				select {
+				case <-both:
+				case <-time.After(5 * time.Second):
+					t.Error("events of the webhook weren't published concurrently")
+				}
+				mu.Lock()
+				inflight--
+				mu.Unlock()
+				return nil
+			}),
+			DefaultLimit: outbox.Limit{Concurrency: 2},
+		},
+		BatchSize:   10,
+		Concurrency: 3,
+		Log:         slog.Default(),
+		Tracer:      noop.NewTracerProvider().Tracer("outbox"),
+	}
+	// Events are published concurrently, up to the concurrency limit of the webhook,
+	// and an event throttled by it is published by the next run.
+	var published int
+	for range 2 {
+		n, err := relay.RelayOnce(context.Background())
+		if err != nil {
+			t.Fatalf("Relay.RelayOnce() error = %v", err)
+		}
+		published += n
+	}
+	if published != 3 || maxInflight != 2 {
+		t.Errorf("published %d events, up to %d at once, want 3 events, up to 2 at once", published, maxInflight)
+	}
+}
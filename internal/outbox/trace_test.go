@@ -0,0 +1,113 @@
+package outbox_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	"github.com/henvic/pgxtutorial/internal/telemetry/telemetrytest"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// memoryStore of events, without transactions.
+type memoryStore struct {
+	events    []outbox.Event
+	published []int64
+}
+
+func (s *memoryStore) TransactionContext(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+
+func (s *memoryStore) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (s *memoryStore) Rollback(ctx context.Context) error {
+	return nil
+}
+
+func (s *memoryStore) ClaimOutboxEvents(ctx context.Context, limit int) ([]outbox.Event, error) {
+	return s.events[:min(limit, len(s.events))], nil
+}
+
+func (s *memoryStore) MarkOutboxEventsPublished(ctx context.Context, ids []int64) error {
+	s.published = append(s.published, ids...)
+	return nil
+}
+
+func (s *memoryStore) FailOutboxEvent(ctx context.Context, id int64, reason string, deadLetter bool) error {
+	return nil
+}
+
+func TestStartConsumerSpan(t *testing.T) {
+	t.Parallel()
+	tel, mem := telemetrytest.Provider()
+
+	// The event is written within a request, recording its trace context as EnqueueEvent does.
+	reqCtx, reqSpan := tel.Tracer().Start(context.Background(), "request")
+	tc := propagation.MapCarrier{}
+	tel.Propagator().Inject(reqCtx, tc)
+	reqSpan.End()
+	store := &memoryStore{events: []outbox.Event{{ID: 1, Topic: "order.created", TraceContext: tc}}}
+
+	// The consumer receives the event as delivered by a message broker, without the context of the relay.
+	var delivered outbox.Event
+	relay := &outbox.Relay{
+		Store: store,
+		Publisher: publisherFunc(func(ctx context.Context, e outbox.Event) error {
+			delivered = e
+			return nil
+		}),
+		BatchSize:  10,
+		Log:        slog.Default(),
+		Tracer:     tel.Tracer(),
+		Propagator: tel.Propagator(),
+	}
+	if n, err := relay.RelayOnce(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Relay.RelayOnce() = %d, %v, want 1, nil", n, err)
+	}
+	if len(delivered.DeliveryContext) == 0 {
+		t.Fatal("event delivered without the trace context of the span publishing it")
+	}
+	_, consumer := outbox.StartConsumerSpan(context.Background(), tel.Tracer(), tel.Propagator(), delivered)
+	consumer.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range mem.Trace() {
+		spans[s.Name()] = s
+	}
+	publish, consume := spans["outbox.publish order.created"], spans["outbox.consume order.created"]
+	if publish == nil || consume == nil {
+		t.Fatalf("outbox.publish and outbox.consume spans not found, got %v", spans)
+	}
+	if consume.SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("outbox.consume span kind = %v, want %v", consume.SpanKind(), trace.SpanKindConsumer)
+	}
+	if consume.Parent().SpanID() != publish.SpanContext().SpanID() || consume.SpanContext().TraceID() != publish.SpanContext().TraceID() {
+		t.Error("outbox.consume span should be a child of the outbox.publish span")
+	}
+	if links := consume.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != reqSpan.SpanContext().SpanID() {
+		t.Errorf("outbox.consume span should link to the request span, got links %v", links)
+	}
+
+	// An event without trace context is consumed within the span of the context.
+	mem.Reset()
+	ctx, parent := tel.Tracer().Start(context.Background(), "subscriber")
+	_, consumer = outbox.StartConsumerSpan(ctx, tel.Tracer(), tel.Propagator(), outbox.Event{ID: 2, Topic: "order.paid"})
+	consumer.End()
+	parent.End()
+	for _, s := range mem.Trace() {
+		if s.Name() != "outbox.consume order.paid" {
+			continue
+		}
+		if s.Parent().SpanID() != parent.SpanContext().SpanID() || len(s.Links()) != 0 {
+			t.Errorf("outbox.consume span should be a child of the subscriber span without links, got parent %v and links %v", s.Parent(), s.Links())
+		}
+		return
+	}
+	t.Error("outbox.consume order.paid span not found")
+}
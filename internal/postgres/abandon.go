@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// abandonedRollbackTimeout limits rolling back a transaction abandoned after its context is done.
+const abandonedRollbackTimeout = 5 * time.Second
+
+// WithMeter records metrics of the DB, such as the number of abandoned transactions.
+func WithMeter(meter metric.Meter) Option {
+	return func(db *DB) {
+		var err error
+		if db.abandoned, err = meter.Int64Counter("db.transactions.abandoned",
+			metric.WithDescription("Number of transactions rolled back as their context was done before they were committed or rolled back.")); err != nil {
+			db.log.Error("cannot create abandoned transactions counter", slog.Any("error", err))
+		}
+	}
+}
+
+// rollbackAbandoned rolls back a transaction whose context is done before it's committed or rolled back,
+// such as when a request is canceled and the code handling it returns early.
+//
+// It's called by the owner of the transaction, from DB.Commit or DB.Rollback, as a pgx.Tx
+// must not be used concurrently. The rollback doesn't use the done context, as it'd fail right away,
+// and pgx would close the connection instead of returning it to the pool.
+func (db DB) rollbackAbandoned(ctx context.Context, tx pgx.Tx) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abandonedRollbackTimeout)
+	defer cancel()
+	err := tx.Rollback(ctx)
+	if errors.Is(err, pgx.ErrTxClosed) {
+		// Already committed or rolled back, such as by a deferred rollback after a commit.
+		return err
+	}
+	db.log.Warn("rolled back transaction abandoned after its context was done")
+	if db.abandoned != nil {
+		db.abandoned.Add(ctx, 1)
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/telemetry/telemetrytest"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestTransactionContextAbandoned(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	tel, mem := telemetrytest.Provider()
+	db := NewDB(pool, slog.Default(), WithMeter(tel.Meter()))
+
+	// A committed transaction isn't rolled back by the deferred rollback once its context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	txCtx, err := db.TransactionContext(ctx)
+	if err != nil {
+		t.Fatalf("DB.TransactionContext() error = %v", err)
+	}
+	if _, err := db.conn(txCtx).Exec(txCtx, `INSERT INTO product (id, name, description, price, slug) VALUES ('committed', 'Committed', '', 10, 'committed')`); err != nil {
+		t.Fatalf("cannot insert product: %v", err)
+	}
+	if err := db.Commit(txCtx); err != nil {
+		t.Fatalf("DB.Commit() error = %v", err)
+	}
+	cancel()
+	if err := db.Rollback(txCtx); !errors.Is(err, pgx.ErrTxClosed) {
+		t.Errorf("DB.Rollback() error = %v, want %v", err, pgx.ErrTxClosed)
+	}
+
+	// The context of a transaction is canceled while its owner is still using it, such as by a client going away.
+	// The owner rolls it back, returning its connection to the pool instead of closing it.
+	conns := pool.Stat().TotalConns()
+	ctx, cancel = context.WithCancel(context.Background())
+	txCtx, err = db.TransactionContext(ctx)
+	if err != nil {
+		t.Fatalf("DB.TransactionContext() error = %v", err)
+	}
+	if _, err := db.conn(txCtx).Exec(txCtx, `INSERT INTO product (id, name, description, price, slug) VALUES ('abandoned', 'Abandoned', '', 10, 'abandoned')`); err != nil {
+		t.Fatalf("cannot insert product: %v", err)
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	for txCtx.Err() == nil {
+		if _, err := db.conn(txCtx).Exec(txCtx, `SELECT pg_sleep(0.001)`); err != nil && txCtx.Err() == nil {
+			t.Fatalf("cannot query: %v", err)
+		}
+	}
+	if err := db.Commit(txCtx); err != context.Canceled {
+		t.Errorf("DB.Commit() error = %v, want %v", err, context.Canceled)
+	}
+	if err := db.Rollback(txCtx); !errors.Is(err, pgx.ErrTxClosed) {
+		t.Errorf("DB.Rollback() error = %v, want %v", err, pgx.ErrTxClosed)
+	}
+	if stat := pool.Stat(); stat.AcquiredConns() != 0 || stat.TotalConns() < conns {
+		t.Errorf("connection wasn't returned to the pool: %d acquired, %d total, want 0 acquired, %d total",
+			stat.AcquiredConns(), stat.TotalConns(), conns)
+	}
+	if m := mem.Meter(); !strings.Contains(m, "db.transactions.abandoned") {
+		t.Errorf("abandoned transaction wasn't recorded: %v", m)
+	}
+
+	// A nested transaction abandoned by its owner is rolled back to its savepoint.
+	outer, err := db.TransactionContext(context.Background())
+	if err != nil {
+		t.Fatalf("DB.TransactionContext() error = %v", err)
+	}
+	defer db.Rollback(outer) // #nosec G104
+	ctx, cancel = context.WithCancel(outer)
+	inner, err := db.TransactionContext(ctx)
+	if err != nil {
+		t.Fatalf("DB.TransactionContext() error = %v", err)
+	}
+	if _, err := db.conn(inner).Exec(inner, `INSERT INTO product (id, name, description, price, slug) VALUES ('nested', 'Nested', '', 10, 'nested')`); err != nil {
+		t.Fatalf("cannot insert product: %v", err)
+	}
+	cancel()
+	if err := db.Rollback(inner); err != nil {
+		t.Errorf("DB.Rollback() error = %v", err)
+	}
+	if err := db.Commit(outer); err != nil {
+		t.Errorf("DB.Commit() error = %v", err)
+	}
+
+	var ids []string
+	rows, err := pool.Query(context.Background(), `SELECT id FROM product ORDER BY id`)
+	if err == nil {
+		ids, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	}
+	if err != nil {
+		t.Fatalf("cannot get products: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "committed" {
+		t.Errorf("got products %v, want only the committed one", ids)
+	}
+}
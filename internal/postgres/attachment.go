@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5"
+)
+
+// attachmentColumns returns the URLs and content types of attachments as arrays, to insert them with unnest.
+func attachmentColumns(attachments []inventory.Attachment) (urls, contentTypes []string) {
+	urls = make([]string, 0, len(attachments))
+	contentTypes = make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		urls = append(urls, a.URL)
+		contentTypes = append(contentTypes, a.ContentType)
+	}
+	return urls, contentTypes
+}
+
+// reviewAttachments returns the attachments of reviews, in order, by review ID.
+func (db DB) reviewAttachments(ctx context.Context, ids []string) (map[string][]inventory.Attachment, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	const sql = `SELECT "review_id", "url", "content_type" FROM "review_attachment" WHERE "review_id" = ANY($1) ORDER BY "id"`
+	m, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (map[string][]inventory.Attachment, error) {
+		rows, err := conn.Query(ctx, sql, ids)
+		if err != nil {
+			return nil, err
+		}
+		m := map[string][]inventory.Attachment{}
+		var (
+			reviewID string
+			a        inventory.Attachment
+		)
+		_, err = pgx.ForEachRow(rows, []any{&reviewID, &a.URL, &a.ContentType}, func() error {
+			m[reviewID] = append(m[reviewID], a)
+			return nil
+		})
+		return m, err
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot get review attachments from database", slog.Any("error", err))
+		return nil, infraError("cannot get review attachments from database", err)
+	}
+	return m, nil
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestReviewAttachments(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "product",
+			Name:        "A product name",
+			Description: "A great description",
+			Price:       10000,
+		},
+	})
+	photos := []inventory.Attachment{
+		{URL: "https://example.com/front.jpg", ContentType: "image/jpeg"},
+		{URL: "https://example.com/back.png", ContentType: "image/png"},
+	}
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "review",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "product",
+				ReviewerID:  "reviewer",
+				Score:       4,
+				Title:       "Good",
+				Description: "A good product",
+				Attachments: photos,
+			},
+		},
+		{
+			ID: "other",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "product",
+				ReviewerID:  "reviewer",
+				Score:       5,
+				Title:       "Great",
+				Description: "A great product",
+			},
+		},
+	})
+
+	attachments := func(id string) []inventory.Attachment {
+		t.Helper()
+		review, err := db.GetProductReview(context.Background(), id)
+		if err != nil || review == nil {
+			t.Fatalf("DB.GetProductReview() = %v, %v", review, err)
+		}
+		return review.Attachments
+	}
+	if got := attachments("review"); !cmp.Equal(photos, got) {
+		t.Errorf("attachments don't match: %v", cmp.Diff(photos, got))
+	}
+	if got := attachments("other"); got != nil {
+		t.Errorf("attachments = %v, want none", got)
+	}
+	reviews, err := db.GetProductReviews(context.Background(), inventory.ProductReviewsParams{ProductID: "product"})
+	if err != nil {
+		t.Fatalf("DB.GetProductReviews() error = %v", err)
+	}
+	for _, r := range reviews.Reviews {
+		if want := map[string][]inventory.Attachment{"review": photos}[r.ID]; !cmp.Equal(want, r.Attachments) {
+			t.Errorf("attachments of %q don't match: %v", r.ID, cmp.Diff(want, r.Attachments))
+		}
+	}
+
+	// Updating other fields keeps the attachments.
+	if err := db.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:    "review",
+		Score: ptr(5),
+	}); err != nil {
+		t.Fatalf("DB.UpdateProductReview() error = %v", err)
+	}
+	if got := attachments("review"); !cmp.Equal(photos, got) {
+		t.Errorf("attachments don't match: %v", cmp.Diff(photos, got))
+	}
+
+	// Replacing the attachments.
+	replaced := []inventory.Attachment{
+		{URL: "https://example.com/side.webp", ContentType: "image/webp"},
+	}
+	if err := db.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:          "review",
+		Attachments: &replaced,
+	}); err != nil {
+		t.Fatalf("DB.UpdateProductReview() error = %v", err)
+	}
+	if got := attachments("review"); !cmp.Equal(replaced, got) {
+		t.Errorf("attachments don't match: %v", cmp.Diff(replaced, got))
+	}
+	if err := db.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:          "not_found",
+		Attachments: &replaced,
+	}); err != ErrReviewNotFound {
+		t.Errorf("DB.UpdateProductReview() error = %v, want %v", err, ErrReviewNotFound)
+	}
+
+	// Deleting a review deletes its attachments.
+	if err := db.DeleteProductReview(context.Background(), "review"); err != nil {
+		t.Fatalf("DB.DeleteProductReview() error = %v", err)
+	}
+	var n int
+	if err := pool.QueryRow(context.Background(), `SELECT COUNT(*) FROM "review_attachment"`).Scan(&n); err != nil {
+		t.Fatalf("cannot count attachments: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("got %d attachments after deleting review, want none", n)
+	}
+}
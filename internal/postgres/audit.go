@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/telemetry"
+)
+
+// audit writes a record of an administrative operation to the audit_log table.
+// It should be called with the transaction executing the operation, so the record is only kept if the operation succeeds.
+// The IP address of the client of the request, if known, is recorded with the details.
+func audit(ctx context.Context, conn database.PGXQuerier, action, subject string, details map[string]any) error {
+	if ip, ok := telemetry.ClientIP(ctx); ok {
+		details["client_address"] = ip.String()
+	}
+	const sql = `INSERT INTO audit_log ("action", "subject", "details") VALUES ($1, $2, $3)`
+	_, err := conn.Exec(ctx, sql, action, subject, details)
+	return err
+}
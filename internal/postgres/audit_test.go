@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestPurgeReviewerDataAudit(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "chair",
+			Name:        "Chair",
+			Description: "A chair",
+			Price:       80,
+		},
+	})
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "review",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "chair",
+				ReviewerID:  "forgetme",
+				Score:       3,
+				Title:       "title",
+				Description: "description",
+			},
+		},
+	})
+
+	got, err := db.PurgeReviewerData(context.Background(), inventory.PurgeReviewerDataParams{
+		ReviewerID: "forgetme",
+	})
+	if err != nil {
+		t.Fatalf("DB.PurgeReviewerData() error = %v", err)
+	}
+	if got.Reviews != 1 {
+		t.Errorf("DB.PurgeReviewerData() returned %d reviews, want 1", got.Reviews)
+	}
+
+	var (
+		action  string
+		reviews int
+	)
+	if err := pool.QueryRow(context.Background(), `SELECT "action", ("details"->>'reviews')::int FROM "audit_log" WHERE "subject" = $1`,
+		db.reviewerPseudonymizer.digest("forgetme")).Scan(&action, &reviews); err != nil {
+		t.Fatalf("cannot get audit record: %v", err)
+	}
+	if action != "purge_reviewer_data" || reviews != 1 {
+		t.Errorf("unexpected audit record: action = %q, reviews = %d", action, reviews)
+	}
+
+	// The raw ID of the erased reviewer isn't kept on the audit log.
+	var raw int
+	if err := pool.QueryRow(context.Background(), `SELECT count(*) FROM "audit_log" WHERE "subject" = $1 OR "details"::text LIKE '%' || $1 || '%'`,
+		"forgetme").Scan(&raw); err != nil {
+		t.Fatalf("cannot search audit log: %v", err)
+	}
+	if raw != 0 {
+		t.Errorf("audit log has %d records with the raw reviewer ID, want none", raw)
+	}
+}
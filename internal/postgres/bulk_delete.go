@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5"
+)
+
+// productFilterSQL is the condition matching the products of an inventory.ProductFilter, with the arguments of productFilterArgs.
+const productFilterSQL = `CASE
+		WHEN $1::text = '' THEN strpos("id", $2::text) = 0
+		ELSE starts_with("id", $1::text || $2::text)
+	END
+	AND ($3::product_status IS NULL OR "status" = $3)
+	AND ($4::timestamptz IS NULL OR "modified_at" < $4)
+	AND ($5 OR NOT EXISTS (SELECT 1 FROM "review" WHERE "review"."product_id" = "product"."id"))`
+
+// productFilterArgs returns the arguments of productFilterSQL.
+func productFilterArgs(filter inventory.ProductFilter) []any {
+	var status, modifiedBefore any
+	if filter.Status != "" {
+		status = string(filter.Status)
+	}
+	if !filter.ModifiedBefore.IsZero() {
+		modifiedBefore = filter.ModifiedBefore
+	}
+	return []any{filter.Tenant, inventory.TenantSeparator, status, modifiedBefore, filter.WithReviews}
+}
+
+// CountProducts returns the number of products matching the filter, up to limit.
+// Products are counted on the primary rather than on a replica, as the count is what a deletion is confirmed for.
+func (db DB) CountProducts(ctx context.Context, filter inventory.ProductFilter, limit int) (int, error) {
+	sql := `SELECT count(*) FROM (SELECT FROM "product" WHERE ` + productFilterSQL + ` LIMIT $6) AS "p"`
+	args := append(productFilterArgs(filter), limit)
+	db.explainOn(ctx, db.conn(ctx), "CountProducts", sql, args...)
+	var count int
+	switch err := db.conn(ctx).QueryRow(ctx, sql, args...).Scan(&count); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return 0, err
+	case err != nil:
+		db.log.Error("cannot count products on database", slog.Any("error", err))
+		return 0, infraError("cannot count products on database", err)
+	}
+	return count, nil
+}
+
+// DeleteProducts deletes up to limit products matching the filter, along with their reviews if filter.WithReviews is set,
+// writing an audit record in the same transaction.
+func (db DB) DeleteProducts(ctx context.Context, filter inventory.ProductFilter, limit int) (*inventory.DeleteProductsResult, error) {
+	tx, err := db.begin(ctx)
+	var result *inventory.DeleteProductsResult
+	if err == nil {
+		defer func() {
+			if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback products deletion", slog.Any("error", rerr))
+			}
+		}()
+		result, err = db.deleteProducts(ctx, tx, filter, limit)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot delete products from database", slog.Any("error", err))
+		return nil, infraError("cannot delete products from database", err)
+	}
+	return result, nil
+}
+
+func (db DB) deleteProducts(ctx context.Context, tx pgx.Tx, filter inventory.ProductFilter, limit int) (*inventory.DeleteProductsResult, error) {
+	// Lock the product rows first, as it blocks concurrent inserts of reviews referencing them until the transaction ends.
+	sql := `SELECT "id" FROM "product" WHERE ` + productFilterSQL + ` ORDER BY "id" LIMIT $6 FOR UPDATE`
+	args := append(productFilterArgs(filter), limit)
+	db.explainOn(ctx, tx, "DeleteProducts", sql, args...)
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil || len(ids) == 0 {
+		return &inventory.DeleteProductsResult{}, err
+	}
+	var reviews int64
+	if filter.WithReviews {
+		// Delete reviews explicitly rather than relying on ON DELETE CASCADE to make the intent clear.
+		ct, err := tx.Exec(ctx, `DELETE FROM "review" WHERE "product_id" = ANY($1)`, ids)
+		if err != nil {
+			return nil, err
+		}
+		reviews = ct.RowsAffected()
+	}
+	// Reviews created before the rows were locked are visible now, so products with reviews are checked again.
+	rows, err = tx.Query(ctx, `DELETE FROM "product"
+	WHERE "id" = ANY($1) AND ($2 OR NOT EXISTS (SELECT 1 FROM "review" WHERE "review"."product_id" = "product"."id"))
+	RETURNING "id"`, ids, filter.WithReviews)
+	if err != nil {
+		return nil, err
+	}
+	if ids, err = pgx.CollectRows(rows, pgx.RowTo[string]); err != nil {
+		return nil, err
+	}
+	if err := audit(ctx, tx, "products_deleted", filter.Tenant, map[string]any{
+		"products": ids,
+		"reviews":  reviews,
+	}); err != nil {
+		return nil, err
+	}
+	return &inventory.DeleteProductsResult{
+		Deleted: len(ids),
+		Reviews: int(reviews),
+	}, nil
+}
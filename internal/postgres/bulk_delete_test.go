@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestDeleteProducts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{ID: "acme:desk", Name: "Desk", Description: "A desk", Price: 100, Status: inventory.ProductStatusDiscontinued},
+		{ID: "acme:chair", Name: "Chair", Description: "A chair", Price: 40, Status: inventory.ProductStatusDiscontinued},
+		{ID: "acme:lamp", Name: "Lamp", Description: "A lamp", Price: 30, Status: inventory.ProductStatusDiscontinued},
+		{ID: "acme:sofa", Name: "Sofa", Description: "A sofa", Price: 500},
+		{ID: "other:desk", Name: "Desk", Description: "A desk", Price: 100, Status: inventory.ProductStatusDiscontinued},
+	})
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "review",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "acme:lamp",
+				ReviewerID:  "reviewer",
+				Score:       5,
+				Title:       "Bright",
+				Description: "It lights up the room",
+			},
+		},
+	})
+	filter := inventory.ProductFilter{
+		Tenant: "acme",
+		Status: inventory.ProductStatusDiscontinued,
+	}
+	count := func(filter inventory.ProductFilter, limit int) int {
+		t.Helper()
+		n, err := db.CountProducts(ctx, filter, limit)
+		if err != nil {
+			t.Fatalf("DB.CountProducts() error = %v", err)
+		}
+		return n
+	}
+	if got := count(filter, 10); got != 2 {
+		t.Errorf("DB.CountProducts() = %d, want 2", got)
+	}
+	if got := count(filter, 1); got != 1 {
+		t.Errorf("DB.CountProducts() with limit = %d, want 1", got)
+	}
+	if got := count(inventory.ProductFilter{Tenant: "acme", ModifiedBefore: time.Now().Add(-time.Hour)}, 10); got != 0 {
+		t.Errorf("DB.CountProducts() modified before an hour ago = %d, want 0", got)
+	}
+
+	got, err := db.DeleteProducts(ctx, filter, 1)
+	if err != nil {
+		t.Fatalf("DB.DeleteProducts() error = %v", err)
+	}
+	if want := (&inventory.DeleteProductsResult{Deleted: 1}); !cmp.Equal(want, got) {
+		t.Errorf("value returned by DB.DeleteProducts() doesn't match: %v", cmp.Diff(want, got))
+	}
+	if p, err := db.GetProduct(ctx, "acme:chair"); err != nil || p != nil {
+		t.Errorf("DB.GetProduct() = (%v, %v), want the product deleted first by ID to be gone", p, err)
+	}
+
+	filter.WithReviews = true
+	got, err = db.DeleteProducts(ctx, filter, 10)
+	if err != nil {
+		t.Fatalf("DB.DeleteProducts() error = %v", err)
+	}
+	if want := (&inventory.DeleteProductsResult{Deleted: 2, Reviews: 1}); !cmp.Equal(want, got) {
+		t.Errorf("value returned by DB.DeleteProducts() with reviews doesn't match: %v", cmp.Diff(want, got))
+	}
+	for _, id := range []string{"acme:sofa", "other:desk"} {
+		if p, err := db.GetProduct(ctx, id); err != nil || p == nil {
+			t.Errorf("DB.GetProduct(%q) = (%v, %v), want product not matched by the filter", id, p, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"cmp"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// collations that products can be sorted by name with, by locale.
+// They're the ICU collations PostgreSQL creates when built with ICU support, which the official images are.
+// Only collations from this list are interpolated into queries, as collation names cannot be query parameters.
+var collations = map[string]string{
+	"cs": "cs-x-icu",
+	"da": "da-x-icu",
+	"de": "de-x-icu",
+	"el": "el-x-icu",
+	"en": "en-x-icu",
+	"es": "es-x-icu",
+	"fi": "fi-x-icu",
+	"fr": "fr-x-icu",
+	"hu": "hu-x-icu",
+	"it": "it-x-icu",
+	"ja": "ja-x-icu",
+	"ko": "ko-x-icu",
+	"nb": "nb-x-icu",
+	"nl": "nl-x-icu",
+	"pl": "pl-x-icu",
+	"pt": "pt-x-icu",
+	"ru": "ru-x-icu",
+	"sv": "sv-x-icu",
+	"tr": "tr-x-icu",
+	"uk": "uk-x-icu",
+	"zh": "zh-x-icu",
+}
+
+// collation returns the first of the locales with a collation, and the quoted name of the collation,
+// or an empty locale and the C collation, comparing byte by byte, as the default collation of the database is unknown.
+func collation(locales []string) (locale, name string) {
+	for _, l := range locales {
+		if c, ok := collations[l]; ok {
+			return l, pgx.Identifier{c}.Sanitize()
+		}
+	}
+	return "", `"C"`
+}
+
+// productNameOrder returns a function comparing products by name in the alphabetical order of the locales,
+// like the collation of the database, and then by ID.
+func productNameOrder(locales []string) func(a, b *inventory.Product) int {
+	locale, _ := collation(locales)
+	if locale == "" {
+		// Without a locale, the database compares the names byte by byte too, with COLLATE "C".
+		return func(a, b *inventory.Product) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+		}
+	}
+	c := collate.New(language.Make(locale))
+	return func(a, b *inventory.Product) int {
+		return cmp.Or(c.CompareString(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	}
+}
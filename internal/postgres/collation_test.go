@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestCollation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		locales    []string
+		wantLocale string
+		wantName   string
+	}{
+		{nil, "", `"C"`},
+		{[]string{"xx", "pt-BR", "pt", "en"}, "pt", `"pt-x-icu"`},
+		{[]string{"sv"}, "sv", `"sv-x-icu"`},
+		{[]string{`en"; DROP TABLE product; --`}, "", `"C"`},
+	}
+	for _, tc := range tests {
+		if locale, name := collation(tc.locales); locale != tc.wantLocale || name != tc.wantName {
+			t.Errorf("collation(%q) = (%q, %q), want (%q, %q)", tc.locales, locale, name, tc.wantLocale, tc.wantName)
+		}
+	}
+}
+
+func TestProductNameOrder(t *testing.T) {
+	t.Parallel()
+	names := func(locales []string, products ...*inventory.Product) []string {
+		slices.SortFunc(products, productNameOrder(locales))
+		var names []string
+		for _, p := range products {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	products := []*inventory.Product{{ID: "1", Name: "zebra"}, {ID: "2", Name: "öl"}, {ID: "3", Name: "Oak"}, {ID: "4", Name: "apple"}}
+	tests := []struct {
+		locales []string
+		want    []string
+	}{
+		{nil, []string{"Oak", "apple", "zebra", "öl"}},
+		{[]string{"de"}, []string{"apple", "Oak", "öl", "zebra"}},
+		{[]string{"sv"}, []string{"apple", "Oak", "zebra", "öl"}},
+	}
+	for _, tc := range tests {
+		if got := names(tc.locales, slices.Clone(products)...); !slices.Equal(got, tc.want) {
+			t.Errorf("products sorted by name for %q = %q, want %q", tc.locales, got, tc.want)
+		}
+	}
+}
+
+func TestSearchProductsByNameOrder(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pool := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	}).Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+	createProducts(t, db, []inventory.CreateProductParams{
+		{ID: "1", Name: "zebra", Description: "A product"},
+		{ID: "2", Name: "öl", Description: "A product"},
+		{ID: "3", Name: "Oak", Description: "A product"},
+		{ID: "4", Name: "apple", Description: "A product"},
+	})
+
+	// The database sorts the names like productNameOrder, which merges the results of shards.
+	for _, locales := range [][]string{nil, {"de"}, {"sv"}} {
+		resp, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+			OrderBy:    inventory.ProductsByName,
+			Locales:    locales,
+			Pagination: inventory.Pagination{Limit: 10},
+		})
+		if err != nil {
+			t.Fatalf("DB.SearchProducts() error = %v", err)
+		}
+		if len(resp.Items) != 4 || !slices.IsSortedFunc(resp.Items, productNameOrder(locales)) {
+			t.Errorf("DB.SearchProducts() for %q isn't sorted like productNameOrder: %v", locales, resp.Items)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/henvic/pgtools"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// columnsRow is a row with the given columns, to check they're mapped by name to the fields of a struct.
+type columnsRow []string
+
+func (r columnsRow) FieldDescriptions() []pgconn.FieldDescription {
+	var fields []pgconn.FieldDescription
+	for _, c := range r {
+		fields = append(fields, pgconn.FieldDescription{Name: c})
+	}
+	return fields
+}
+
+func (r columnsRow) Scan(dest ...any) error { return nil }
+
+func (r columnsRow) Values() ([]any, error) { return nil, nil }
+
+func (r columnsRow) RawValues() [][]byte { return nil }
+
+// wildcardColumns returns the columns of pgtools.Wildcard without quotes, plus the extra columns.
+func wildcardColumns(v any, extra ...string) columnsRow {
+	return append(columnsRow(strings.Split(strings.ReplaceAll(pgtools.Wildcard(v), `"`, ""), ",")), extra...)
+}
+
+func TestRowStructColumns(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		fn   func(pgx.CollectableRow) error
+		row  columnsRow
+	}{
+		{"product", collectByName[product], wildcardColumns(product{})},
+		{"product_with_total", collectByName[productWithTotal], wildcardColumns(product{}, "total")},
+		{"similar_product", collectByName[similarProduct], wildcardColumns(product{}, "similarity")},
+		{"product_summary", collectByName[productSummary], columnsRow{"id", "name", "price", "rating", "created_at"}},
+		{"product_summary_with_total", collectByName[productSummaryWithTotal], columnsRow{"id", "name", "price", "rating", "created_at", "total"}},
+		{"review", collectByName[review], wildcardColumns(review{})},
+		{"favorite", collectByName[favorite], wildcardColumns(favorite{})},
+		{"order", collectByName[order], wildcardColumns(order{})},
+		{"supplier", collectByName[supplier], wildcardColumns(supplier{})},
+		{"product_supplier", collectByName[productSupplier], wildcardColumns(productSupplier{})},
+		{"warehouse", collectByName[warehouse], wildcardColumns(warehouse{})},
+		{"stock_level", collectByName[stockLevel], wildcardColumns(stockLevel{})},
+		{"outbox_event", collectByName[outboxEvent], columnsRow{"id", "topic", "payload", "trace_context", "attempts", "created_at"}},
+		{"dead_letter", collectByName[deadLetter], columnsRow{"id", "topic", "payload", "trace_context", "attempts", "created_at", "last_error", "dead_lettered_at"}},
+		{"usage", collectByName[usageRow], columnsRow{"tenant", "api_key", "hour", "requests", "bytes_in", "bytes_out"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.fn(tt.row); err != nil {
+				t.Errorf("cannot map columns %v by name: %v", tt.row, err)
+			}
+		})
+	}
+	if err := collectByName[product](columnsRow{"id", "name"}); err == nil {
+		t.Error("expected mapping a row without every column of the product to fail")
+	}
+}
+
+func collectByName[T any](row pgx.CollectableRow) error {
+	_, err := pgx.RowToStructByName[T](row)
+	return err
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrNoCostPriceCodec is returned when accessing cost prices without a Codec set with WithCostPriceCodec.
+var ErrNoCostPriceCodec = errors.New("cost price encryption is not configured")
+
+// costPriceAdditionalData binds an encrypted cost price to its product.
+func costPriceAdditionalData(id string) []byte {
+	return []byte("product.cost_price:" + id)
+}
+
+// SetProductCostPrice encrypts and stores the cost price of a product.
+func (db DB) SetProductCostPrice(ctx context.Context, id string, costPrice int) error {
+	if db.costPriceCodec == nil {
+		return ErrNoCostPriceCodec
+	}
+	if costPrice < 0 {
+		return errors.New("cost price cannot be negative")
+	}
+	encrypted, err := db.costPriceCodec.Encrypt(ctx, binary.BigEndian.AppendUint64(nil, uint64(costPrice)), costPriceAdditionalData(id))
+	if err == nil {
+		const sql = `UPDATE "product" SET "cost_price" = $1, "modified_at" = now() WHERE "id" = $2`
+		var ct pgconn.CommandTag
+		if ct, err = db.conn(ctx).Exec(ctx, sql, encrypted, id); err == nil && ct.RowsAffected() == 0 {
+			return ErrProductNotFound
+		}
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot set product cost price on database", slog.Any("error", err))
+		return infraError("cannot set product cost price on database", err)
+	}
+	return nil
+}
+
+// GetProductCostPrice returns the decrypted cost price of a product, or nil if it isn't set.
+func (db DB) GetProductCostPrice(ctx context.Context, id string) (*int, error) {
+	if db.costPriceCodec == nil {
+		return nil, ErrNoCostPriceCodec
+	}
+	var encrypted []byte
+	err := db.conn(ctx).QueryRow(ctx, `SELECT "cost_price" FROM "product" WHERE "id" = $1`, id).Scan(&encrypted)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, ErrProductNotFound
+	case err != nil:
+		db.log.Error("cannot get product cost price from database", slog.Any("error", err))
+		return nil, infraError("cannot get product cost price from database", err)
+	case encrypted == nil:
+		return nil, nil
+	}
+	value, err := db.costPriceCodec.Decrypt(ctx, encrypted, costPriceAdditionalData(id))
+	if err == nil && len(value) != 8 {
+		err = errors.New("invalid cost price length")
+	}
+	if err != nil {
+		db.log.Error("cannot decrypt product cost price",
+			slog.String("id", id),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot decrypt product cost price", err)
+	}
+	costPrice := int(binary.BigEndian.Uint64(value))
+	return &costPrice, nil
+}
+
+// ReencryptProductCostPrices encrypts the cost prices that aren't encrypted with the current key again.
+// It should be called after rotating the key, before removing the old key from the KeyProvider.
+// It returns how many cost prices were encrypted again.
+func (db DB) ReencryptProductCostPrices(ctx context.Context) (int, error) {
+	if db.costPriceCodec == nil {
+		return 0, ErrNoCostPriceCodec
+	}
+	tx, err := db.begin(ctx)
+	var n int
+	if err == nil {
+		defer func() {
+			if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback cost price encryption", slog.Any("error", rerr))
+			}
+		}()
+		n, err = db.reencryptProductCostPrices(ctx, tx)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return 0, err
+	case err != nil:
+		db.log.Error("cannot encrypt product cost prices again", slog.Any("error", err))
+		return 0, infraError("cannot encrypt product cost prices again", err)
+	}
+	return n, nil
+}
+
+func (db DB) reencryptProductCostPrices(ctx context.Context, tx pgx.Tx) (int, error) {
+	type row struct {
+		ID        string
+		CostPrice []byte
+	}
+	rows, err := tx.Query(ctx, `SELECT "id", "cost_price" FROM "product" WHERE "cost_price" IS NOT NULL FOR UPDATE`)
+	if err != nil {
+		return 0, err
+	}
+	products, err := pgx.CollectRows(rows, pgx.RowToStructByName[row])
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, p := range products {
+		current, err := db.costPriceCodec.current(ctx, p.CostPrice)
+		if err != nil {
+			return n, err
+		}
+		if current {
+			continue
+		}
+		ad := costPriceAdditionalData(p.ID)
+		value, err := db.costPriceCodec.Decrypt(ctx, p.CostPrice, ad)
+		if err != nil {
+			return n, err
+		}
+		encrypted, err := db.costPriceCodec.Encrypt(ctx, value, ad)
+		if err != nil {
+			return n, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE "product" SET "cost_price" = $1 WHERE "id" = $2`, encrypted, p.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestProductCostPrice(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	ctx := context.Background()
+
+	oldKeys, err := NewStaticKeys("old", map[string][]byte{
+		"old": bytes.Repeat([]byte("o"), 32),
+	})
+	if err != nil {
+		t.Fatalf("NewStaticKeys() error = %v", err)
+	}
+	db := NewDB(pool, slog.Default(), WithCostPriceCodec(NewCodec(oldKeys)))
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "lamp",
+			Name:        "Lamp",
+			Description: "A lamp",
+			Price:       40,
+		},
+	})
+
+	if got, err := db.GetProductCostPrice(ctx, "lamp"); err != nil || got != nil {
+		t.Errorf("DB.GetProductCostPrice() = %v, %v, want nil", got, err)
+	}
+	if err := db.SetProductCostPrice(ctx, "lamp", 25); err != nil {
+		t.Fatalf("DB.SetProductCostPrice() error = %v", err)
+	}
+	if err := db.SetProductCostPrice(ctx, "unknown", 25); err != ErrProductNotFound {
+		t.Errorf("DB.SetProductCostPrice() error = %v, want %v", err, ErrProductNotFound)
+	}
+	if _, err := NewDB(pool, slog.Default()).GetProductCostPrice(ctx, "lamp"); !errors.Is(err, ErrNoCostPriceCodec) {
+		t.Errorf("DB.GetProductCostPrice() error = %v, want %v", err, ErrNoCostPriceCodec)
+	}
+
+	// Rotate the key, and encrypt the cost price again with the new one.
+	rotatedKeys, err := NewStaticKeys("new", map[string][]byte{
+		"old": bytes.Repeat([]byte("o"), 32),
+		"new": bytes.Repeat([]byte("n"), 32),
+	})
+	if err != nil {
+		t.Fatalf("NewStaticKeys() error = %v", err)
+	}
+	db = NewDB(pool, slog.Default(), WithCostPriceCodec(NewCodec(rotatedKeys)))
+	if n, err := db.ReencryptProductCostPrices(ctx); err != nil || n != 1 {
+		t.Errorf("DB.ReencryptProductCostPrices() = %v, %v, want 1", n, err)
+	}
+	if n, err := db.ReencryptProductCostPrices(ctx); err != nil || n != 0 {
+		t.Errorf("DB.ReencryptProductCostPrices() = %v, %v, want 0", n, err)
+	}
+
+	newKeys, err := NewStaticKeys("new", map[string][]byte{
+		"new": bytes.Repeat([]byte("n"), 32),
+	})
+	if err != nil {
+		t.Fatalf("NewStaticKeys() error = %v", err)
+	}
+	db = NewDB(pool, slog.Default(), WithCostPriceCodec(NewCodec(newKeys)))
+	got, err := db.GetProductCostPrice(ctx, "lamp")
+	if err != nil {
+		t.Fatalf("DB.GetProductCostPrice() error = %v", err)
+	}
+	if got == nil || *got != 25 {
+		t.Errorf("DB.GetProductCostPrice() = %v, want 25", got)
+	}
+}
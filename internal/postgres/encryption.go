@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// KeyProvider provides the keys used by Codec, for example, from the configuration or from a KMS.
+type KeyProvider interface {
+	// CurrentKey returns the ID and the value of the key used to encrypt new values.
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+
+	// Key returns the value of the key with the given ID, used to decrypt values.
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider with a fixed set of keys.
+type StaticKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeys creates a StaticKeys using the key with the current ID for encryption.
+// Keys must have 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+func NewStaticKeys(current string, keys map[string][]byte) (*StaticKeys, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("missing current encryption key %q", current)
+	}
+	for id, k := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, errors.New("encryption key ID must have between 1 and 255 bytes")
+		}
+		if _, err := aes.NewCipher(k); err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+	}
+	return &StaticKeys{current: current, keys: keys}, nil
+}
+
+// CurrentKey returns the ID and the value of the current key.
+func (s *StaticKeys) CurrentKey(ctx context.Context) (id string, key []byte, err error) {
+	return s.current, s.keys[s.current], nil
+}
+
+// Key returns the key with the given ID.
+func (s *StaticKeys) Key(ctx context.Context, id string) ([]byte, error) {
+	if k, ok := s.keys[id]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown encryption key %q", id)
+}
+
+// Codec encrypts and decrypts column values with AES-GCM.
+//
+// The ID of the key used to encrypt a value is stored along with it, so keys can be rotated:
+// new values are encrypted with the current key, while old values are decrypted with the key they were encrypted with
+// until they're encrypted again (see DB.ReencryptProductCostPrices).
+type Codec struct {
+	keys KeyProvider
+}
+
+// NewCodec creates a Codec.
+func NewCodec(keys KeyProvider) *Codec {
+	return &Codec{keys: keys}
+}
+
+// codecVersion is the first byte of encrypted values, followed by the length of the key ID, the key ID,
+// the nonce, and the sealed value.
+const codecVersion = 1
+
+// Encrypt the value.
+// The additional data isn't encrypted, but must be the same to decrypt the value.
+// It should identify where the value is stored, so it cannot be copied elsewhere.
+func (c *Codec) Encrypt(ctx context.Context, value, additionalData []byte) ([]byte, error) {
+	id, key, err := c.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 2+len(id)+aead.NonceSize()+len(value)+aead.Overhead())
+	out = append(out, codecVersion, byte(len(id)))
+	out = append(out, id...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, value, additionalData), nil
+}
+
+// Decrypt a value encrypted with Encrypt.
+func (c *Codec) Decrypt(ctx context.Context, encrypted, additionalData []byte) ([]byte, error) {
+	id, rest, err := splitKeyID(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.keys.Key(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted value")
+	}
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], additionalData)
+}
+
+// current reports whether the value is encrypted with the current key.
+func (c *Codec) current(ctx context.Context, encrypted []byte) (bool, error) {
+	id, _, err := splitKeyID(encrypted)
+	if err != nil {
+		return false, err
+	}
+	current, _, err := c.keys.CurrentKey(ctx)
+	return id == current, err
+}
+
+func splitKeyID(encrypted []byte) (id string, rest []byte, err error) {
+	if len(encrypted) < 2 || encrypted[0] != codecVersion || len(encrypted) < 2+int(encrypted[1]) {
+		return "", nil, errors.New("invalid encrypted value")
+	}
+	n := 2 + int(encrypted[1])
+	return string(encrypted[2:n]), encrypted[n:], nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WithCostPriceCodec sets the Codec used to encrypt the cost price of products.
+// Without it, cost prices cannot be stored nor read.
+func WithCostPriceCodec(c *Codec) Option {
+	return func(db *DB) {
+		db.costPriceCodec = c
+	}
+}
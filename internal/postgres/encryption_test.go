@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestNewStaticKeys(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		current string
+		keys    map[string][]byte
+		wantErr string
+	}{
+		{
+			name:    "success",
+			current: "k1",
+			keys:    map[string][]byte{"k1": bytes.Repeat([]byte("a"), 32)},
+		},
+		{
+			name:    "missing_current",
+			current: "k2",
+			keys:    map[string][]byte{"k1": bytes.Repeat([]byte("a"), 32)},
+			wantErr: `missing current encryption key "k2"`,
+		},
+		{
+			name:    "invalid_key",
+			current: "k1",
+			keys:    map[string][]byte{"k1": []byte("short")},
+			wantErr: `invalid encryption key "k1": crypto/aes: invalid key size 5`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewStaticKeys(tt.current, tt.keys)
+			if err == nil && tt.wantErr != "" || err != nil && err.Error() != tt.wantErr {
+				t.Errorf("NewStaticKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCodec(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	oldKeys, err := NewStaticKeys("old", map[string][]byte{
+		"old": bytes.Repeat([]byte("o"), 32),
+	})
+	if err != nil {
+		t.Fatalf("NewStaticKeys() error = %v", err)
+	}
+	rotatedKeys, err := NewStaticKeys("new", map[string][]byte{
+		"old": bytes.Repeat([]byte("o"), 32),
+		"new": bytes.Repeat([]byte("n"), 16),
+	})
+	if err != nil {
+		t.Fatalf("NewStaticKeys() error = %v", err)
+	}
+	before, after := NewCodec(oldKeys), NewCodec(rotatedKeys)
+
+	encrypted, err := before.Encrypt(ctx, []byte("secret"), []byte("ad"))
+	if err != nil {
+		t.Fatalf("Codec.Encrypt() error = %v", err)
+	}
+	if bytes.Contains(encrypted, []byte("secret")) {
+		t.Error("Codec.Encrypt() returned the value in plaintext")
+	}
+	got, err := after.Decrypt(ctx, encrypted, []byte("ad"))
+	if err != nil {
+		t.Fatalf("Codec.Decrypt() error = %v", err)
+	}
+	if string(got) != "secret" {
+		t.Errorf("Codec.Decrypt() = %q, want secret", got)
+	}
+	if _, err := after.Decrypt(ctx, encrypted, []byte("other")); err == nil {
+		t.Error("Codec.Decrypt() should fail with different additional data")
+	}
+	if current, err := after.current(ctx, encrypted); err != nil || current {
+		t.Errorf("Codec.current() = %v, %v, want false", current, err)
+	}
+	if _, err := after.Decrypt(ctx, []byte{codecVersion}, nil); err == nil || err.Error() != "invalid encrypted value" {
+		t.Errorf("Codec.Decrypt() error = %v, want invalid encrypted value", err)
+	}
+}
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Explainer captures query plans of selected statements with EXPLAIN (ANALYZE, BUFFERS).
+//
+// It is meant to be used for debugging and learning query tuning only:
+// EXPLAIN ANALYZE executes the statement once more, so it roughly doubles the cost of each captured statement.
+// Only read statements are captured.
+type Explainer struct {
+	statements map[string]bool
+	size       int
+
+	mu    sync.Mutex
+	plans []Plan
+}
+
+// Plan of a statement.
+type Plan struct {
+	Statement string    `json:"statement"`
+	SQL       string    `json:"sql"`
+	Plan      string    `json:"plan"`
+	Time      time.Time `json:"time"`
+}
+
+// NewExplainer creates an Explainer for the given statements, keeping the last size plans in memory.
+// Statements are named after the DB method executing them, such as SearchProducts.
+// Use "*" to capture the plan of every supported statement.
+func NewExplainer(statements []string, size int) *Explainer {
+	e := &Explainer{
+		statements: map[string]bool{},
+		size:       size,
+	}
+	for _, s := range statements {
+		if s = strings.TrimSpace(s); s != "" {
+			e.statements[s] = true
+		}
+	}
+	return e
+}
+
+// WithExplainer captures query plans of the statements selected by the Explainer.
+func WithExplainer(e *Explainer) Option {
+	return func(db *DB) {
+		db.explainer = e
+	}
+}
+
+func (e *Explainer) enabled(statement string) bool {
+	return e != nil && (e.statements["*"] || e.statements[statement])
+}
+
+func (e *Explainer) record(p Plan) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.plans = append(e.plans, p)
+	if len(e.plans) > e.size {
+		e.plans = e.plans[len(e.plans)-e.size:]
+	}
+}
+
+// Plans returns the recorded plans, from the oldest to the newest.
+func (e *Explainer) Plans() []Plan {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Plan{}, e.plans...)
+}
+
+// ServeHTTP exposes the recorded plans as JSON.
+func (e *Explainer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(e.Plans()); err != nil {
+		slog.Default().Info("cannot json encode query plans", slog.Any("error", err))
+	}
+}
+
+// explain the given statement, if selected, recording its plan and attaching it to the current span.
+// The statement is explained on the server it's read from, a read replica if it's read through read.
+// Errors are logged rather than returned as capturing a plan must not affect the request.
+func (db DB) explain(ctx context.Context, statement, sql string, args ...any) {
+	if !db.explainer.enabled(statement) {
+		return
+	}
+	lines, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]string, error) {
+		return explainPlan(ctx, conn, sql, args...)
+	})
+	db.recordPlan(ctx, statement, sql, lines, err)
+}
+
+// explainOn explains the given statement on conn, like explain, for statements not read through read,
+// such as the ones that must see the latest writes, or that run in a transaction.
+// In a transaction, it's explained under a savepoint rolled back right after, releasing the locks it took,
+// so explaining it neither blocks the statement nor aborts the transaction if it fails.
+func (db DB) explainOn(ctx context.Context, conn database.PGXQuerier, statement, sql string, args ...any) {
+	if !db.explainer.enabled(statement) {
+		return
+	}
+	if tx, ok := conn.(pgx.Tx); ok {
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			db.recordPlan(ctx, statement, sql, nil, err)
+			return
+		}
+		defer func() {
+			if err := sp.Rollback(ctx); err != nil && ctx.Err() == nil {
+				db.log.Error("cannot rollback to savepoint", slog.Any("error", err))
+			}
+		}()
+		conn = sp
+	}
+	lines, err := explainPlan(ctx, conn, sql, args...)
+	db.recordPlan(ctx, statement, sql, lines, err)
+}
+
+// explainPlan returns the lines of the plan of the statement, executing it.
+func explainPlan(ctx context.Context, conn database.PGXQuerier, sql string, args ...any) ([]string, error) {
+	rows, err := conn.Query(ctx, "EXPLAIN (ANALYZE, BUFFERS) "+sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// recordPlan of the statement, or log the error explaining it.
+func (db DB) recordPlan(ctx context.Context, statement, sql string, lines []string, err error) {
+	if err != nil {
+		db.log.Warn("cannot explain statement",
+			slog.String("statement", statement),
+			slog.Any("error", err),
+		)
+		return
+	}
+	plan := strings.Join(lines, "\n")
+	db.explainer.record(Plan{
+		Statement: statement,
+		SQL:       sql,
+		Plan:      plan,
+		Time:      time.Now(),
+	})
+	trace.SpanFromContext(ctx).AddEvent("db.explain", trace.WithAttributes(
+		attribute.String("db.statement.name", statement),
+		attribute.String("db.plan", plan),
+	))
+}
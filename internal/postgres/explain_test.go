@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestExplainer(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	explainer := NewExplainer([]string{"SearchProducts"}, 1)
+	db := NewDB(pool, slog.Default(), WithExplainer(explainer))
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "desk",
+			Name:        "plain desk (home)",
+			Description: "A plain desk",
+			Price:       140,
+		},
+	})
+
+	// GetProduct is not selected, so its plan must not be captured.
+	if _, err := db.GetProduct(context.Background(), "desk"); err != nil {
+		t.Fatalf("DB.GetProduct() error = %v", err)
+	}
+	if plans := explainer.Plans(); len(plans) != 0 {
+		t.Errorf("expected no plans to be captured, got %d instead", len(plans))
+	}
+
+	for _, q := range []string{"desk", "home"} {
+		if _, err := db.SearchProducts(context.Background(), inventory.SearchProductsParams{
+			QueryString: q,
+		}); err != nil {
+			t.Fatalf("DB.SearchProducts() error = %v", err)
+		}
+	}
+
+	// Only the most recent plan is kept.
+	plans := explainer.Plans()
+	if len(plans) != 1 {
+		t.Fatalf("expected one plan to be captured, got %d instead", len(plans))
+	}
+	if plans[0].Statement != "SearchProducts" {
+		t.Errorf("unexpected statement: %q", plans[0].Statement)
+	}
+	if !strings.Contains(plans[0].Plan, "Execution Time") {
+		t.Errorf("plan doesn't look like an EXPLAIN ANALYZE output: %q", plans[0].Plan)
+	}
+
+	rec := httptest.NewRecorder()
+	explainer.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/explain", nil))
+	var got []Plan
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("cannot decode plans: %v", err)
+	}
+	if len(got) != 1 || got[0].Plan != plans[0].Plan {
+		t.Errorf("unexpected plans served: %v", got)
+	}
+}
+
+func TestExplainerReplicas(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	createProducts(t, NewDB(pool, slog.Default()), []inventory.CreateProductParams{
+		{
+			ID:          "desk",
+			Name:        "plain desk (home)",
+			Description: "A plain desk",
+			Price:       140,
+		},
+	})
+
+	// Without a primary, the statement must be explained on the replica it's read from.
+	explainer := NewExplainer([]string{"GetProduct"}, 1)
+	db := NewDB(nil, slog.Default(), WithExplainer(explainer), WithReplicas(Replicas{
+		Pools: []*pgxpool.Pool{pool},
+	}))
+	if _, err := db.GetProduct(context.Background(), "desk"); err != nil {
+		t.Fatalf("DB.GetProduct() error = %v", err)
+	}
+	if plans := explainer.Plans(); len(plans) != 1 || plans[0].Statement != "GetProduct" {
+		t.Errorf("expected the plan of GetProduct to be captured, got %v instead", plans)
+	}
+}
+
+func TestExplainerTransaction(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	explainer := NewExplainer([]string{"DeleteProducts"}, 1)
+	db := NewDB(pool, slog.Default(), WithExplainer(explainer))
+	createProducts(t, db, []inventory.CreateProductParams{
+		{ID: "acme:desk", Name: "desk", Description: "A desk"},
+		{ID: "acme:chair", Name: "chair", Description: "A chair"},
+	})
+
+	// The locking statement is explained in the transaction of the deletion, rather than blocking on its locks.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := db.DeleteProducts(ctx, inventory.ProductFilter{Tenant: "acme"}, 10)
+	if err != nil {
+		t.Fatalf("DB.DeleteProducts() error = %v", err)
+	}
+	if got.Deleted != 2 {
+		t.Errorf("DB.DeleteProducts() deleted %d products, want 2", got.Deleted)
+	}
+	if plans := explainer.Plans(); len(plans) != 1 || !strings.Contains(plans[0].Plan, "LockRows") {
+		t.Errorf("expected the plan of DeleteProducts to be captured, got %v instead", plans)
+	}
+}
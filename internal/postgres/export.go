@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// ExportProducts calls fn with each product of the page of a search as its row is read,
+// so the products aren't all held in memory at once.
+// The products are read by a single statement, which holds a connection until the export is done.
+func (db DB) ExportProducts(ctx context.Context, params inventory.SearchProductsParams, fn func(*inventory.Product) error) error {
+	from, columns := `"product"`, productColumns(params.View, "product")
+	switch {
+	case db.searchView:
+		from, columns = `"product_search"`, productColumns(params.View, "product_search")
+	case params.View == inventory.ProductViewSummary:
+		// The products are read as they're exported, so they can't be rated afterwards.
+		from, columns = productRatingsJoin, productRatingsColumns
+	}
+	q := searchProductsQuery(params, from, columns, searchProductsConditions(params))
+	sql, args := q.String(), q.args
+	db.explain(ctx, "ExportProducts", sql, args...)
+	rows, err := readConn(ctx, db).Query(ctx, sql, args...)
+	if err == nil {
+		defer rows.Close()
+		rowToProduct := productRow(params.View)
+		for rows.Next() {
+			var p *inventory.Product
+			if p, err = rowToProduct(rows); err != nil {
+				break
+			}
+			if err := fn(p); err != nil {
+				return err // Not a database error, such as the client of the export going away.
+			}
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot export products from the database", slog.Any("error", err))
+		return infraError("cannot export products", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestExportProducts(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	db := NewDB(migration.Setup(context.Background(), ""), slog.Default())
+
+	var products []inventory.CreateProductParams
+	for i := range 5 {
+		products = append(products, inventory.CreateProductParams{
+			ID:          fmt.Sprintf("desk%d", i),
+			Name:        fmt.Sprintf("desk %d", i),
+			Description: "A desk",
+			Price:       100 * i,
+		})
+	}
+	createProducts(t, db, products)
+
+	params := inventory.SearchProductsParams{
+		QueryString: "desk",
+		MinPrice:    100,
+		Pagination:  inventory.Pagination{Limit: 3, Offset: 1},
+	}
+	want, err := db.SearchProducts(context.Background(), params)
+	if err != nil {
+		t.Fatalf("DB.SearchProducts() error = %v", err)
+	}
+	var got []*inventory.Product
+	if err := db.ExportProducts(context.Background(), params, func(p *inventory.Product) error {
+		got = append(got, p)
+		return nil
+	}); err != nil {
+		t.Fatalf("DB.ExportProducts() error = %v", err)
+	}
+	if !cmp.Equal(want.Items, got) {
+		t.Errorf("DB.ExportProducts() doesn't match the search: %v", cmp.Diff(want.Items, got))
+	}
+
+	errStop := errors.New("stop")
+	var n int
+	err = db.ExportProducts(context.Background(), params, func(*inventory.Product) error {
+		n++
+		return errStop
+	})
+	if err != errStop || n != 1 {
+		t.Errorf("DB.ExportProducts() error = %v after %d products, want %v after the first", err, n, errStop)
+	}
+	if err := db.ExportProducts(canceledContext(), params, func(*inventory.Product) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("DB.ExportProducts() error = %v, want context canceled", err)
+	}
+}
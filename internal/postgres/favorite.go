@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgtools"
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// AddFavorite adds a product to the favorites of a user.
+// The primary key on (user_id, product_id) guarantees a product is added only once.
+func (db DB) AddFavorite(ctx context.Context, params inventory.FavoriteParams) error {
+	const sql = `INSERT INTO "favorite" ("user_id", "product_id") VALUES ($1, $2)
+	ON CONFLICT ("user_id", "product_id") DO NOTHING`
+	_, err := db.conn(ctx).Exec(ctx, sql, params.UserID, params.ProductID)
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation && pgErr.ConstraintName == "favorite_product_id_fkey":
+		return inventory.ErrFavoriteNoProduct
+	case err != nil:
+		db.log.Error("cannot add favorite on database", slog.Any("error", err))
+		return infraError("cannot add favorite on database", err)
+	}
+	return nil
+}
+
+// RemoveFavorite removes a product from the favorites of a user.
+func (db DB) RemoveFavorite(ctx context.Context, params inventory.FavoriteParams) error {
+	const sql = `DELETE FROM "favorite" WHERE "user_id" = $1 AND "product_id" = $2`
+	switch _, err := db.conn(ctx).Exec(ctx, sql, params.UserID, params.ProductID); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot remove favorite from database", slog.Any("error", err))
+		return infraError("cannot remove favorite from database", err)
+	}
+	return nil
+}
+
+// favorite table.
+type favorite struct {
+	UserID    string
+	ProductID string
+	CreatedAt time.Time
+}
+
+// ListFavorites returns the favorites of a user, the most recent first.
+func (db DB) ListFavorites(ctx context.Context, params inventory.ListFavoritesParams) (*inventory.ListFavoritesResponse, error) {
+	const sqlTotal = `SELECT COUNT(*) AS total FROM "favorite" WHERE "user_id" = $1`
+	total, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (total int, err error) {
+		err = conn.QueryRow(ctx, sqlTotal, params.UserID).Scan(&total)
+		return total, err
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+	if err != nil {
+		db.log.Error("cannot get favorites count from the database", slog.Any("error", err))
+		return nil, infraError("cannot get favorites", err)
+	}
+
+	sql := fmt.Sprintf(`SELECT %s FROM "favorite" WHERE "user_id" = $1
+	ORDER BY "created_at" DESC, "product_id" COLLATE "C" LIMIT $2 OFFSET $3`, pgtools.Wildcard(favorite{})) // #nosec G201
+	favorites, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]favorite, error) {
+		rows, err := conn.Query(ctx, sql, params.UserID, params.Pagination.Limit, params.Pagination.Offset)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[favorite])
+	})
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+	if err != nil {
+		db.log.Error("cannot get favorites from database", slog.Any("error", err))
+		return nil, infraError("cannot get favorites", err)
+	}
+	resp := &inventory.ListFavoritesResponse{
+		Favorites: make([]*inventory.Favorite, 0, len(favorites)),
+		Total:     total,
+	}
+	for _, f := range favorites {
+		resp.Favorites = append(resp.Favorites, &inventory.Favorite{
+			UserID:    f.UserID,
+			ProductID: f.ProductID,
+			CreatedAt: f.CreatedAt,
+		})
+	}
+	return resp, nil
+}
+
+// GetProductStats returns the stats of a product.
+func (db DB) GetProductStats(ctx context.Context, id string) (*inventory.ProductStats, error) {
+	const sql = `SELECT p."id",
+	(SELECT COUNT(*) FROM "review" WHERE "product_id" = p."id") AS reviews,
+	(SELECT COALESCE(AVG("score"), 0)::double precision FROM "review" WHERE "product_id" = p."id") AS average_score,
+	(SELECT COUNT(*) FROM "favorite" WHERE "product_id" = p."id") AS favorites,
+	(SELECT AVG("sentiment") FROM "review" WHERE "product_id" = p."id") AS average_sentiment
+	FROM "product" p WHERE p."id" = $1`
+	stats, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (*inventory.ProductStats, error) {
+		var s inventory.ProductStats
+		err := conn.QueryRow(ctx, sql, id).Scan(&s.ProductID, &s.Reviews, &s.AverageScore, &s.Favorites, &s.AverageSentiment)
+		return &s, err
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		db.log.Error("cannot get product stats from database",
+			slog.Any("id", id),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get product stats from database", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestFavorites(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "chair",
+			Name:        "Chair",
+			Description: "A chair",
+			Price:       80,
+		},
+		{
+			ID:          "table",
+			Name:        "Table",
+			Description: "A table",
+			Price:       200,
+		},
+	})
+	for _, f := range []inventory.FavoriteParams{
+		{UserID: "alice", ProductID: "chair"},
+		{UserID: "alice", ProductID: "table"},
+		{UserID: "alice", ProductID: "chair"}, // Adding it again is a no-op.
+		{UserID: "bob", ProductID: "chair"},
+	} {
+		if err := db.AddFavorite(context.Background(), f); err != nil {
+			t.Errorf("DB.AddFavorite(%v) error = %v", f, err)
+		}
+	}
+	if err := db.AddFavorite(context.Background(), inventory.FavoriteParams{UserID: "alice", ProductID: "not_found"}); err != inventory.ErrFavoriteNoProduct {
+		t.Errorf("DB.AddFavorite() error = %v, want %v", err, inventory.ErrFavoriteNoProduct)
+	}
+
+	got, err := db.ListFavorites(context.Background(), inventory.ListFavoritesParams{
+		UserID:     "alice",
+		Pagination: inventory.Pagination{Limit: 1, Offset: 1},
+	})
+	if err != nil {
+		t.Fatalf("DB.ListFavorites() error = %v", err)
+	}
+	want := &inventory.ListFavoritesResponse{
+		Favorites: []*inventory.Favorite{
+			{UserID: "alice", ProductID: "chair", CreatedAt: time.Now()},
+		},
+		Total: 2,
+	}
+	if !cmp.Equal(want, got, cmpopts.EquateApproxTime(time.Minute)) {
+		t.Errorf("value returned by DB.ListFavorites() doesn't match: %v", cmp.Diff(want, got))
+	}
+
+	stats, err := db.GetProductStats(context.Background(), "chair")
+	if err != nil {
+		t.Fatalf("DB.GetProductStats() error = %v", err)
+	}
+	if wantStats := (&inventory.ProductStats{ProductID: "chair", Favorites: 2}); !cmp.Equal(wantStats, stats) {
+		t.Errorf("value returned by DB.GetProductStats() doesn't match: %v", cmp.Diff(wantStats, stats))
+	}
+
+	if err := db.RemoveFavorite(context.Background(), inventory.FavoriteParams{UserID: "bob", ProductID: "chair"}); err != nil {
+		t.Errorf("DB.RemoveFavorite() error = %v", err)
+	}
+	if stats, err = db.GetProductStats(context.Background(), "chair"); err != nil || stats.Favorites != 1 {
+		t.Errorf("DB.GetProductStats() = %+v, %v, want 1 favorite", stats, err)
+	}
+	if stats, err = db.GetProductStats(context.Background(), "not_found"); err != nil || stats != nil {
+		t.Errorf("DB.GetProductStats() = %+v, %v, want nil", stats, err)
+	}
+	if _, err := db.ListFavorites(canceledContext(), inventory.ListFavoritesParams{UserID: "alice"}); err != context.Canceled {
+		t.Errorf("DB.ListFavorites() error = %v, want %v", err, context.Canceled)
+	}
+}
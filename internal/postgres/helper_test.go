@@ -6,11 +6,12 @@ import (
 	"time"
 
 	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func createProducts(t testing.TB, db DB, products []inventory.CreateProductParams) {
 	for _, p := range products {
-		if err := db.CreateProduct(context.Background(), p); err != nil {
+		if _, err := db.CreateProduct(context.Background(), p); err != nil {
 			t.Errorf("DB.CreateProduct() error = %v", err)
 		}
 	}
@@ -40,3 +41,23 @@ func deadlineExceededContext() context.Context {
 func ptr[T any](v T) *T {
 	return &v
 }
+
+// waitForLock waits until a statement on the database of the pool is waiting for a lock.
+func waitForLock(t testing.TB, pool *pgxpool.Pool) {
+	t.Helper()
+	const sql = `SELECT EXISTS (SELECT 1 FROM pg_stat_activity WHERE "datname" = current_database() AND "wait_event_type" = 'Lock')`
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var waiting bool
+		if err := pool.QueryRow(context.Background(), sql).Scan(&waiting); err != nil {
+			t.Fatalf("cannot check for statements waiting for a lock: %v", err)
+		}
+		if waiting {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no statement is waiting for a lock")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
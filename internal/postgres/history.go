@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ProductHistory returns the versions of a product recorded on the product_history table.
+// The table is maintained by triggers created by the optional migrations/history migrations.
+// If they're not applied, errors.ErrUnsupported is returned.
+func (db DB) ProductHistory(ctx context.Context, id string) ([]inventory.Version, error) {
+	return db.history(ctx, "product_history", id)
+}
+
+// ReviewHistory returns the versions of a review recorded on the review_history table.
+// The table is maintained by triggers created by the optional migrations/history migrations.
+// If they're not applied, errors.ErrUnsupported is returned.
+func (db DB) ReviewHistory(ctx context.Context, id string) ([]inventory.Version, error) {
+	return db.history(ctx, "review_history", id)
+}
+
+// historyRow of a <table>_history table.
+type historyRow struct {
+	Operation string
+	Data      map[string]any
+	ChangedAt time.Time
+}
+
+func (db DB) history(ctx context.Context, table, id string) ([]inventory.Version, error) {
+	sql := fmt.Sprintf(`SELECT "operation", "data", "changed_at" FROM %s WHERE "id" = $1 ORDER BY "history_id"`,
+		pgx.Identifier{table}.Sanitize()) // #nosec G201
+	rows, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]historyRow, error) {
+		rows, err := conn.Query(ctx, sql, id)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[historyRow])
+	})
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UndefinedTable:
+		return nil, fmt.Errorf("%s table not found: %w", table, errors.ErrUnsupported)
+	case err != nil:
+		db.log.Error("cannot get history from database",
+			slog.String("table", table),
+			slog.Any("id", id),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get history from database", err)
+	}
+	versions := make([]inventory.Version, 0, len(rows))
+	var previous map[string]any
+	for _, r := range rows {
+		if r.Operation == "DELETE" {
+			r.Data = nil
+		}
+		versions = append(versions, inventory.Version{
+			Operation: r.Operation,
+			ChangedAt: r.ChangedAt,
+			Data:      r.Data,
+			Changes:   diff(previous, r.Data),
+		})
+		previous = r.Data
+	}
+	return versions, nil
+}
+
+// diff returns the changes from the old to the new values of each field, sorted by field.
+func diff(old, new map[string]any) []inventory.Change {
+	var changes []inventory.Change
+	for field, v := range new {
+		if ov, ok := old[field]; !ok || !reflect.DeepEqual(ov, v) {
+			changes = append(changes, inventory.Change{Field: field, Old: ov, New: v})
+		}
+	}
+	for field, ov := range old {
+		if _, ok := new[field]; !ok {
+			changes = append(changes, inventory.Change{Field: field, Old: ov})
+		}
+	}
+	slices.SortFunc(changes, func(a, b inventory.Change) int {
+		return cmp.Compare(a.Field, b.Field)
+	})
+	return changes
+}
+
+// purgeReviewHistory deletes the versions of reviews of the given reviewers from the review_history table, if it exists.
+func purgeReviewHistory(ctx context.Context, tx pgx.Tx, reviewerIDs []string) error {
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass('review_history') IS NOT NULL`).Scan(&exists); err != nil || !exists {
+		return err
+	}
+	_, err := tx.Exec(ctx, `DELETE FROM "review_history" WHERE "data"->>'reviewer_id' = ANY($1)`, reviewerIDs)
+	return err
+}
@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrateHistory applies the optional history migrations.
+func migrateHistory(t testing.TB, pool *pgxpool.Pool) {
+	t.Helper()
+	files, err := fs.Glob(os.DirFS("../../migrations/history"), "*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		b, err := os.ReadFile("../../migrations/history/" + f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up, _, _ := strings.Cut(string(b), "---- create above / drop below ----")
+		if _, err := pool.Exec(context.Background(), up); err != nil {
+			t.Fatalf("cannot apply history migration %s: %v", f, err)
+		}
+	}
+}
+
+// operations and changed fields of each version.
+func operations(versions []inventory.Version) (ops []string, fields [][]string) {
+	for _, v := range versions {
+		ops = append(ops, v.Operation)
+		var changed []string
+		for _, c := range v.Changes {
+			changed = append(changed, c.Field)
+		}
+		fields = append(fields, changed)
+	}
+	return ops, fields
+}
+
+func TestProductHistory(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	migrateHistory(t, pool)
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "product",
+			Name:        "A product name",
+			Description: "A great description",
+			Price:       10000,
+		},
+	})
+	if _, err := db.UpdateProduct(context.Background(), inventory.UpdateProductParams{
+		ID:    "product",
+		Price: ptr(12000),
+	}); err != nil {
+		t.Fatalf("DB.UpdateProduct() error = %v", err)
+	}
+	if err := db.DeleteProduct(context.Background(), inventory.DeleteProductParams{ID: "product"}); err != nil {
+		t.Fatalf("DB.DeleteProduct() error = %v", err)
+	}
+
+	got, err := db.ProductHistory(context.Background(), "product")
+	if err != nil {
+		t.Fatalf("DB.ProductHistory() error = %v", err)
+	}
+	ops, fields := operations(got)
+	if want := []string{"INSERT", "UPDATE", "DELETE"}; !cmp.Equal(want, ops) {
+		t.Errorf("operations returned by DB.ProductHistory() don't match: %v", cmp.Diff(want, ops))
+	}
+	wantFields := [][]string{
+		{"created_at", "description", "id", "modified_at", "name", "price", "status"},
+		{"modified_at", "price"},
+		{"created_at", "description", "id", "modified_at", "name", "price", "status"},
+	}
+	if !cmp.Equal(wantFields, fields) {
+		t.Errorf("changes returned by DB.ProductHistory() don't match: %v", cmp.Diff(wantFields, fields))
+	}
+	if len(got) == 3 {
+		if price := got[1].Changes[1]; price.Old != float64(10000) || price.New != float64(12000) {
+			t.Errorf("wanted price to change from 10000 to 12000, got %v", price)
+		}
+		if got[2].Data != nil {
+			t.Errorf("wanted no data for deleted product, got %v", got[2].Data)
+		}
+	}
+
+	if got, err := db.ProductHistory(canceledContext(), "product"); err == nil || err.Error() != "context canceled" {
+		t.Errorf("DB.ProductHistory() = %v, %v, wanted context canceled error", got, err)
+	}
+}
+
+func TestReviewHistoryPurgeReviewerData(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	migrateHistory(t, pool)
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "chair",
+			Name:        "Chair",
+			Description: "A chair",
+			Price:       80,
+		},
+	})
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "review",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "chair",
+				ReviewerID:  "forgetme",
+				Score:       3,
+				Title:       "title",
+				Description: "description",
+			},
+		},
+	})
+	got, err := db.ReviewHistory(context.Background(), "review")
+	if err != nil || len(got) != 1 {
+		t.Fatalf("DB.ReviewHistory() = %v, %v, wanted a single version", got, err)
+	}
+
+	if _, err := db.PurgeReviewerData(context.Background(), inventory.PurgeReviewerDataParams{ReviewerID: "forgetme"}); err != nil {
+		t.Fatalf("DB.PurgeReviewerData() error = %v", err)
+	}
+	if got, err = db.ReviewHistory(context.Background(), "review"); err != nil || len(got) != 0 {
+		t.Errorf("DB.ReviewHistory() = %v, %v, wanted no versions after purging reviewer data", got, err)
+	}
+}
+
+func TestProductHistoryNotMigrated(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	if _, err := db.ProductHistory(context.Background(), "product"); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("DB.ProductHistory() error = %v, wanted errors.ErrUnsupported", err)
+	}
+	// Purging reviewer data must work without the history tables.
+	if _, err := db.PurgeReviewerData(context.Background(), inventory.PurgeReviewerDataParams{ReviewerID: "x"}); err != nil {
+		t.Errorf("DB.PurgeReviewerData() error = %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		old  map[string]any
+		new  map[string]any
+		want []inventory.Change
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "created",
+			new:  map[string]any{"name": "chair", "price": float64(80)},
+			want: []inventory.Change{
+				{Field: "name", New: "chair"},
+				{Field: "price", New: float64(80)},
+			},
+		},
+		{
+			name: "updated",
+			old:  map[string]any{"name": "chair", "price": float64(80), "tags": []any{"wood"}},
+			new:  map[string]any{"name": "chair", "price": float64(90), "tags": []any{"wood"}},
+			want: []inventory.Change{
+				{Field: "price", Old: float64(80), New: float64(90)},
+			},
+		},
+		{
+			name: "deleted",
+			old:  map[string]any{"price": float64(80), "name": "chair"},
+			want: []inventory.Change{
+				{Field: "name", Old: "chair"},
+				{Field: "price", Old: float64(80)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff(tt.old, tt.new); !cmp.Equal(tt.want, got) {
+				t.Errorf("diff() mismatch: %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// UpsertProductTranslation creates or replaces the translation of a product to a locale.
+func (db DB) UpsertProductTranslation(ctx context.Context, params inventory.ProductTranslation) error {
+	const sql = `INSERT INTO "product_i18n" ("product_id", "locale", "name", "description")
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT ("product_id", "locale") DO UPDATE SET
+	"name" = EXCLUDED."name",
+	"description" = EXCLUDED."description",
+	"modified_at" = now()`
+	_, err := db.conn(ctx).Exec(ctx, sql, params.ProductID, params.Locale, params.Name, params.Description)
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation && pgErr.ConstraintName == "product_i18n_product_id_fkey":
+		return inventory.ErrTranslationNoProduct
+	case err != nil:
+		db.log.Error("cannot upsert product translation on database", slog.Any("error", err))
+		return infraError("cannot upsert product translation on database", err)
+	}
+	return nil
+}
+
+// DeleteProductTranslation deletes the translation of a product to a locale.
+func (db DB) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	const sql = `DELETE FROM "product_i18n" WHERE "product_id" = $1 AND "locale" = $2`
+	switch _, err := db.conn(ctx).Exec(ctx, sql, productID, locale); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot delete product translation from database", slog.Any("error", err))
+		return infraError("cannot delete product translation from database", err)
+	}
+	return nil
+}
+
+// GetProductTranslation returns the translation of a product to the first of the locales it's translated to.
+func (db DB) GetProductTranslation(ctx context.Context, productID string, locales []string) (*inventory.ProductTranslation, error) {
+	const sql = `SELECT "product_id", "locale", "name", "description" FROM "product_i18n"
+	WHERE "product_id" = $1 AND "locale" = ANY($2)
+	ORDER BY array_position($2, "locale")
+	LIMIT 1`
+	db.explain(ctx, "GetProductTranslation", sql, productID, locales)
+	t, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (*inventory.ProductTranslation, error) {
+		rows, err := conn.Query(ctx, sql, productID, locales)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[inventory.ProductTranslation])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		db.log.Error("cannot get product translation from database", slog.Any("error", err))
+		return nil, infraError("cannot get product translation from database", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestProductTranslations(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "chair",
+			Name:        "Chair",
+			Description: "A chair",
+			Price:       80,
+		},
+	})
+	for _, tr := range []inventory.ProductTranslation{
+		{ProductID: "chair", Locale: "pt", Name: "Cadeira", Description: "Uma cadeira"},
+		{ProductID: "chair", Locale: "pt-PT", Name: "Cadeira", Description: "Uma cadeira portuguesa"},
+		{ProductID: "chair", Locale: "es", Name: "Sila", Description: "Una silla"},
+		{ProductID: "chair", Locale: "es", Name: "Silla", Description: "Una silla"}, // Replaces the previous one.
+	} {
+		if err := db.UpsertProductTranslation(context.Background(), tr); err != nil {
+			t.Errorf("DB.UpsertProductTranslation(%v) error = %v", tr, err)
+		}
+	}
+	if err := db.UpsertProductTranslation(context.Background(), inventory.ProductTranslation{
+		ProductID:   "not_found",
+		Locale:      "pt",
+		Name:        "Mesa",
+		Description: "Uma mesa",
+	}); err != inventory.ErrTranslationNoProduct {
+		t.Errorf("DB.UpsertProductTranslation() error = %v, want %v", err, inventory.ErrTranslationNoProduct)
+	}
+
+	tests := []struct {
+		name    string
+		locales []string
+		want    *inventory.ProductTranslation
+	}{
+		{
+			name:    "exact",
+			locales: []string{"pt-PT", "pt"},
+			want:    &inventory.ProductTranslation{ProductID: "chair", Locale: "pt-PT", Name: "Cadeira", Description: "Uma cadeira portuguesa"},
+		},
+		{
+			name:    "order",
+			locales: []string{"pt-BR", "es", "pt"},
+			want:    &inventory.ProductTranslation{ProductID: "chair", Locale: "es", Name: "Silla", Description: "Una silla"},
+		},
+		{
+			name:    "not_found",
+			locales: []string{"fr", "de"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.GetProductTranslation(context.Background(), "chair", tt.locales)
+			if err != nil {
+				t.Fatalf("DB.GetProductTranslation() error = %v", err)
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("value returned by DB.GetProductTranslation() doesn't match: %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+
+	if err := db.DeleteProductTranslation(context.Background(), "chair", "es"); err != nil {
+		t.Errorf("DB.DeleteProductTranslation() error = %v", err)
+	}
+	if got, err := db.GetProductTranslation(context.Background(), "chair", []string{"es"}); got != nil || err != nil {
+		t.Errorf("DB.GetProductTranslation() = %v, %v, want translation deleted", got, err)
+	}
+	if _, err := db.GetProductTranslation(canceledContext(), "chair", []string{"es"}); err != context.Canceled {
+		t.Errorf("DB.GetProductTranslation() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LeakDetector reports connections acquired by DB.WithAcquire that aren't released by DB.Release,
+// and transactions begun by DB.TransactionContext that aren't committed or rolled back,
+// within a duration, with the stack traces of where they were acquired.
+//
+// It is meant to be used for debugging and learning the context-based API only:
+// capturing a stack trace on every acquisition is expensive.
+type LeakDetector struct {
+	after time.Duration
+	log   *slog.Logger
+
+	mu     sync.Mutex
+	leases map[any]*lease
+}
+
+// lease of a connection or transaction.
+type lease struct {
+	kind     string
+	stack    string
+	acquired time.Time
+	timer    *time.Timer
+}
+
+// Leak of a connection or transaction.
+type Leak struct {
+	// Kind of the leak: connection or transaction.
+	Kind     string        `json:"kind"`
+	Acquired time.Time     `json:"acquired"`
+	Held     time.Duration `json:"held"`
+
+	// Stack trace of where it was acquired.
+	Stack string `json:"stack"`
+}
+
+// NewLeakDetector creates a LeakDetector reporting connections and transactions held longer than after.
+func NewLeakDetector(after time.Duration, logger *slog.Logger) *LeakDetector {
+	return &LeakDetector{
+		after:  after,
+		log:    logger,
+		leases: map[any]*lease{},
+	}
+}
+
+// WithLeakDetector reports leaked connections and transactions with the LeakDetector.
+func WithLeakDetector(d *LeakDetector) Option {
+	return func(db *DB) {
+		db.leakDetector = d
+	}
+}
+
+// acquire records the lease of a connection or transaction, logging it if it's not released in time.
+func (d *LeakDetector) acquire(key any, kind string) {
+	if d == nil {
+		return
+	}
+	l := &lease{
+		kind:     kind,
+		stack:    string(debug.Stack()),
+		acquired: time.Now(),
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.leases[key] = l
+	// The fields of the lease and the detector read by the callback are immutable, so it doesn't need the lock.
+	l.timer = time.AfterFunc(d.after, func() {
+		d.log.Warn("possible leak: "+kind+" not released",
+			slog.Duration("held", time.Since(l.acquired)),
+			slog.String("stack", l.stack),
+		)
+	})
+}
+
+// release the lease of a connection or transaction.
+func (d *LeakDetector) release(key any) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if l, ok := d.leases[key]; ok {
+		l.timer.Stop()
+		delete(d.leases, key)
+	}
+}
+
+// Leaks returns the connections and transactions held longer than the duration of the detector, the oldest first.
+// A nil detector has no leaks.
+func (d *LeakDetector) Leaks() []Leak {
+	leaks := []Leak{}
+	if d == nil {
+		return leaks
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, l := range d.leases {
+		if held := time.Since(l.acquired); held >= d.after {
+			leaks = append(leaks, Leak{
+				Kind:     l.kind,
+				Acquired: l.acquired,
+				Held:     held,
+				Stack:    l.stack,
+			})
+		}
+	}
+	sort.Slice(leaks, func(i, j int) bool {
+		return leaks[i].Acquired.Before(leaks[j].Acquired)
+	})
+	return leaks
+}
+
+// ServeHTTP exposes the leaks as JSON, failing with 500 Internal Server Error if there are any.
+func (d *LeakDetector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	leaks := d.Leaks()
+	w.Header().Set("Content-Type", "application/json")
+	if len(leaks) != 0 {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(leaks); err != nil {
+		slog.Default().Info("cannot json encode leaks", slog.Any("error", err))
+	}
+}
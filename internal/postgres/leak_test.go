@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgtools/sqltest"
+)
+
+func TestLeakDetector(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	var logs bytes.Buffer
+	detector := NewLeakDetector(50*time.Millisecond, slog.New(slog.NewTextHandler(&logs, nil)))
+	db := NewDB(pool, slog.Default(), WithLeakDetector(detector))
+
+	released, err := db.WithAcquire(context.Background())
+	if err != nil {
+		t.Fatalf("DB.WithAcquire() error = %v", err)
+	}
+	db.Release(released)
+	committed, err := db.TransactionContext(context.Background())
+	if err != nil {
+		t.Fatalf("DB.TransactionContext() error = %v", err)
+	}
+	if err := db.Commit(committed); err != nil {
+		t.Fatalf("DB.Commit() error = %v", err)
+	}
+
+	leaked, err := db.TransactionContext(context.Background())
+	if err != nil {
+		t.Fatalf("DB.TransactionContext() error = %v", err)
+	}
+	defer db.Rollback(leaked) // #nosec G104
+	time.Sleep(100 * time.Millisecond)
+
+	leaks := detector.Leaks()
+	if len(leaks) != 1 {
+		t.Fatalf("expected one leak, got %d instead: %+v", len(leaks), leaks)
+	}
+	if leaks[0].Kind != "transaction" || !strings.Contains(leaks[0].Stack, "TestLeakDetector") {
+		t.Errorf("unexpected leak: %+v", leaks[0])
+	}
+	if !strings.Contains(logs.String(), "possible leak: transaction not released") {
+		t.Errorf("leak wasn't logged: %q", logs.String())
+	}
+
+	rec := httptest.NewRecorder()
+	detector.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/leaks", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status code %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	var got []Leak
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || len(got) != 1 {
+		t.Errorf("unexpected leaks served: %v (error: %v)", got, err)
+	}
+
+	if err := db.Rollback(leaked); err != nil {
+		t.Fatalf("DB.Rollback() error = %v", err)
+	}
+	if leaks := detector.Leaks(); len(leaks) != 0 {
+		t.Errorf("expected no leaks after rollback, got %+v", leaks)
+	}
+	rec = httptest.NewRecorder()
+	detector.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/leaks", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status code %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestLeakDetectorNil(t *testing.T) {
+	t.Parallel()
+	var detector *LeakDetector
+	if leaks := detector.Leaks(); len(leaks) != 0 {
+		t.Errorf("expected no leaks, got %+v", leaks)
+	}
+	rec := httptest.NewRecorder()
+	detector.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/leaks", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status code %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != "[]" {
+		t.Errorf("unexpected leaks served: %q", got)
+	}
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RecordProductView counts a view of a product on the current day.
+func (db DB) RecordProductView(ctx context.Context, id string) error {
+	const sql = `INSERT INTO "product_view" ("product_id", "views") VALUES ($1, 1)
+	ON CONFLICT ("product_id", "day") DO UPDATE SET "views" = "product_view"."views" + 1`
+	_, err := db.conn(ctx).Exec(ctx, sql, id)
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation:
+		return nil // The product doesn't exist (anymore).
+	case err != nil:
+		db.log.Error("cannot record product view on database",
+			slog.Any("id", id),
+			slog.Any("error", err),
+		)
+		return infraError("cannot record product view on database", err)
+	}
+	return nil
+}
+
+// ListTrendingProducts returns the active products with the most views since params.Since, the most viewed first.
+func (db DB) ListTrendingProducts(ctx context.Context, params inventory.ListTrendingProductsParams) (*inventory.ListProductsResponse, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "product" p
+	JOIN (
+		SELECT "product_id", SUM("views") AS "views" FROM "product_view"
+		WHERE "day" >= $1::date
+		GROUP BY "product_id"
+	) v ON v."product_id" = p."id"
+	WHERE p."status" = 'active'
+	ORDER BY v."views" DESC, p."id"
+	LIMIT $2 OFFSET $3`, productColumns(params.View, "p")) // #nosec G201
+	return db.listProducts(ctx, "ListTrendingProducts", params.View, sql, params.Since, params.Pagination.Limit, params.Pagination.Offset)
+}
+
+// ListRecentProducts returns the active products, the most recently added first.
+func (db DB) ListRecentProducts(ctx context.Context, params inventory.ListRecentProductsParams) (*inventory.ListProductsResponse, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "product"
+	WHERE "status" = 'active'
+	ORDER BY "created_at" DESC, "id" COLLATE "C"
+	LIMIT $1 OFFSET $2`, productColumns(params.View, "product")) // #nosec G201
+	return db.listProducts(ctx, "ListRecentProducts", params.View, sql, params.Pagination.Limit, params.Pagination.Offset)
+}
+
+// listProducts reads the products of the view with the sql, which reads them from the product table.
+func (db DB) listProducts(ctx context.Context, method string, view inventory.ProductView, sql string, args ...any) (*inventory.ListProductsResponse, error) {
+	db.explain(ctx, method, sql, args...)
+	products, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]*inventory.Product, error) {
+		rows, err := conn.Query(ctx, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		products, err := pgx.CollectRows(rows, productRow(view))
+		if err == nil && rateLater(view, "product") {
+			err = productRatings(ctx, conn, products)
+		}
+		return products, err
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot list products from database",
+			slog.String("method", method),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot list products from database", err)
+	}
+	if products == nil {
+		products = []*inventory.Product{}
+	}
+	return &inventory.ListProductsResponse{
+		Items: products,
+	}, nil
+}
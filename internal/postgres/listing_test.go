@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// ids of the products.
+func ids(products []*inventory.Product) []string {
+	var ids []string
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestListProducts(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "chair",
+			Name:        "Chair",
+			Description: "A chair",
+			Price:       80,
+		},
+		{
+			ID:          "table",
+			Name:        "Table",
+			Description: "A table",
+			Price:       200,
+		},
+		{
+			ID:          "lamp",
+			Name:        "Lamp",
+			Description: "A lamp",
+			Price:       30,
+		},
+		{
+			ID:          "sofa",
+			Name:        "Sofa",
+			Description: "Not for sale yet",
+			Price:       900,
+			Status:      inventory.ProductStatusDraft,
+		},
+	})
+	// Spread the creation times over a few days.
+	if _, err := pool.Exec(context.Background(), `UPDATE "product" SET "created_at" = now() - CASE "id"
+		WHEN 'chair' THEN interval '3 days' WHEN 'table' THEN interval '2 days' ELSE interval '1 day' END`); err != nil {
+		t.Fatalf("cannot set creation time: %v", err)
+	}
+	for id, views := range map[string]int{"chair": 1, "table": 3, "lamp": 2, "sofa": 5, "not_found": 1} {
+		for range views {
+			if err := db.RecordProductView(context.Background(), id); err != nil {
+				t.Errorf("DB.RecordProductView(%q) error = %v", id, err)
+			}
+		}
+	}
+	// Old views aren't trending anymore.
+	if _, err := pool.Exec(context.Background(), `INSERT INTO "product_view" ("product_id", "day", "views")
+		VALUES ('chair', current_date - 30, 100)`); err != nil {
+		t.Fatalf("cannot add old views: %v", err)
+	}
+
+	trending, err := db.ListTrendingProducts(context.Background(), inventory.ListTrendingProductsParams{
+		Since:      time.Now().Add(-inventory.TrendingWindow),
+		Pagination: inventory.Pagination{Limit: 10},
+	})
+	if err != nil {
+		t.Fatalf("DB.ListTrendingProducts() error = %v", err)
+	}
+	if want, got := []string{"table", "lamp", "chair"}, ids(trending.Items); !cmp.Equal(want, got) {
+		t.Errorf("trending products don't match: %v", cmp.Diff(want, got))
+	}
+
+	recent, err := db.ListRecentProducts(context.Background(), inventory.ListRecentProductsParams{
+		Pagination: inventory.Pagination{Limit: 2, Offset: 1},
+	})
+	if err != nil {
+		t.Fatalf("DB.ListRecentProducts() error = %v", err)
+	}
+	if want, got := []string{"table", "chair"}, ids(recent.Items); !cmp.Equal(want, got) {
+		t.Errorf("recent products don't match: %v", cmp.Diff(want, got))
+	}
+
+	if _, err := db.ListRecentProducts(canceledContext(), inventory.ListRecentProductsParams{
+		Pagination: inventory.Pagination{Limit: 2},
+	}); err != context.Canceled {
+		t.Errorf("DB.ListRecentProducts() error = %v, want %v", err, context.Canceled)
+	}
+}
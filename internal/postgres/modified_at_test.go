@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgtools/sqltest"
+)
+
+// TestModifiedAtTriggers checks that created_at and modified_at are kept by writes outside of the application,
+// which don't set them.
+func TestModifiedAtTriggers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := pool.Exec(ctx, `INSERT INTO product ("id", "name", "description", "price", "slug", "created_at") VALUES ('desk', 'Desk', 'A desk', 100, 'desk', $1)`, created); err != nil {
+		t.Fatalf("cannot insert product: %v", err)
+	}
+	timestamps := func() (createdAt, modifiedAt time.Time) {
+		t.Helper()
+		if err := pool.QueryRow(ctx, `SELECT "created_at", "modified_at" FROM product WHERE "id" = 'desk'`).Scan(&createdAt, &modifiedAt); err != nil {
+			t.Fatalf("cannot get product timestamps: %v", err)
+		}
+		return createdAt, modifiedAt
+	}
+	if createdAt, modifiedAt := timestamps(); !createdAt.Equal(created) || !modifiedAt.Equal(created) {
+		t.Errorf("inserted product has created_at = %v and modified_at = %v, want both %v", createdAt, modifiedAt, created)
+	}
+
+	if _, err := pool.Exec(ctx, `UPDATE product SET "price" = 120, "created_at" = now() WHERE "id" = 'desk'`); err != nil {
+		t.Fatalf("cannot update product: %v", err)
+	}
+	createdAt, modifiedAt := timestamps()
+	if !createdAt.Equal(created) {
+		t.Errorf("updated product has created_at = %v, want %v", createdAt, created)
+	}
+	if !createdAt.Before(modifiedAt) {
+		t.Errorf("updated product has modified_at = %v, want after created_at %v", modifiedAt, createdAt)
+	}
+}
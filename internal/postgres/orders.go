@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgtools"
+	"github.com/henvic/pgxtutorial/internal/orders"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var _ orders.Store = (*DB)(nil) // Check if methods expected by orders.Store are implemented correctly.
+
+// ReserveStock reserves units of a product from the warehouse with most of them available,
+// returning the warehouse ID and the price of the product.
+func (db DB) ReserveStock(ctx context.Context, productID string, quantity int) (warehouseID string, price int, err error) {
+	// The chosen stock row is locked, so concurrent reservations of the product are serialized.
+	const sql = `UPDATE "product_stock" SET "reserved" = "reserved" + $2, "modified_at" = now()
+	FROM "product"
+	WHERE ("product_stock"."product_id", "product_stock"."warehouse_id") = (
+		SELECT "product_id", "warehouse_id" FROM "product_stock"
+		WHERE "product_id" = $1 AND "quantity" - "reserved" >= $2
+		ORDER BY "quantity" - "reserved" DESC, "warehouse_id"
+		LIMIT 1
+		FOR UPDATE
+	)
+	AND "product"."id" = "product_stock"."product_id"
+	AND "product_stock"."quantity" - "product_stock"."reserved" >= $2
+	RETURNING "product_stock"."warehouse_id", "product"."price"`
+	err = db.conn(ctx).QueryRow(ctx, sql, productID, quantity).Scan(&warehouseID, &price)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "", 0, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return "", 0, orders.ErrInsufficientStock
+	case err != nil:
+		db.log.Error("cannot reserve product stock on database",
+			slog.String("product", productID),
+			slog.Any("error", err),
+		)
+		return "", 0, infraError("cannot reserve product stock on database", err)
+	}
+	return warehouseID, price, nil
+}
+
+// ReleaseStock releases units of a product previously reserved from a warehouse.
+func (db DB) ReleaseStock(ctx context.Context, productID, warehouseID string, quantity int) error {
+	const sql = `UPDATE "product_stock" SET "reserved" = "reserved" - $3, "modified_at" = now()
+	WHERE "product_id" = $1 AND "warehouse_id" = $2`
+	return db.updateStock(ctx, "release", sql, productID, warehouseID, quantity)
+}
+
+// DeductStock removes units of a product previously reserved from the stock of a warehouse.
+func (db DB) DeductStock(ctx context.Context, productID, warehouseID string, quantity int) error {
+	const sql = `UPDATE "product_stock" SET "quantity" = "quantity" - $3, "reserved" = "reserved" - $3, "modified_at" = now()
+	WHERE "product_id" = $1 AND "warehouse_id" = $2`
+	return db.updateStock(ctx, "deduct", sql, productID, warehouseID, quantity)
+}
+
+func (db DB) updateStock(ctx context.Context, op, sql, productID, warehouseID string, quantity int) error {
+	switch _, err := db.conn(ctx).Exec(ctx, sql, productID, warehouseID, quantity); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot "+op+" product stock on database",
+			slog.String("product", productID),
+			slog.String("warehouse", warehouseID),
+			slog.Any("error", err),
+		)
+		return fmt.Errorf("cannot %s product stock on database", op)
+	}
+	return nil
+}
+
+// order table.
+type order struct {
+	ID          string
+	ProductID   string
+	Quantity    int
+	Amount      int
+	Status      string
+	PaymentID   string
+	CreatedAt   time.Time
+	ModifiedAt  time.Time
+	WarehouseID string
+}
+
+func (o *order) dto() *orders.Order {
+	return &orders.Order{
+		ID:          o.ID,
+		ProductID:   o.ProductID,
+		WarehouseID: o.WarehouseID,
+		Quantity:    o.Quantity,
+		Amount:      o.Amount,
+		Status:      orders.Status(o.Status),
+		PaymentID:   o.PaymentID,
+		CreatedAt:   o.CreatedAt,
+		ModifiedAt:  o.ModifiedAt,
+	}
+}
+
+// CreateOrder records an order.
+func (db DB) CreateOrder(ctx context.Context, o orders.Order) error {
+	const sql = `INSERT INTO "orders" ("id", "product_id", "warehouse_id", "quantity", "amount", "status", "payment_id")
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	switch _, err := db.conn(ctx).Exec(ctx, sql, o.ID, o.ProductID, o.WarehouseID, o.Quantity, o.Amount, string(o.Status), o.PaymentID); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot create order on database", slog.Any("error", err))
+		return infraError("cannot create order on database", err)
+	}
+	return nil
+}
+
+// UpdateOrderStatus sets the status and the payment ID of a pending order.
+func (db DB) UpdateOrderStatus(ctx context.Context, id string, status orders.Status, paymentID string) error {
+	const sql = `UPDATE "orders" SET "status" = $2, "payment_id" = $3, "modified_at" = now()
+	WHERE "id" = $1 AND "status" = 'pending'`
+	ct, err := db.conn(ctx).Exec(ctx, sql, id, string(status), paymentID)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot update order status on database",
+			slog.String("order", id),
+			slog.Any("error", err),
+		)
+		return infraError("cannot update order status on database", err)
+	case ct.RowsAffected() == 0:
+		return orders.ErrOrderNotPending
+	}
+	return nil
+}
+
+// GetOrder returns an order.
+func (db DB) GetOrder(ctx context.Context, id string) (*orders.Order, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "orders" WHERE "id" = $1 LIMIT 1`, pgtools.Wildcard(order{})) // #nosec G201
+	var o order
+	rows, err := db.conn(ctx).Query(ctx, sql, id)
+	if err == nil {
+		o, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[order])
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		db.log.Error("cannot get order from database",
+			slog.String("order", id),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get order from database", err)
+	}
+	return o.dto(), nil
+}
+
+// EnqueueEvent writes an event to the outbox.
+// Call it within the transaction of the change it describes, so the event is only published if it's committed.
+// The trace context of the request is recorded along with the event if the DB is created WithTracing.
+func (db DB) EnqueueEvent(ctx context.Context, topic string, payload any) error {
+	const sql = `INSERT INTO "outbox" ("topic", "payload", "trace_context") VALUES ($1, $2, $3)`
+	var traceContext any // NULL if there is no trace context.
+	if db.propagator != nil {
+		carrier := propagation.MapCarrier{}
+		db.propagator.Inject(ctx, carrier)
+		if len(carrier) != 0 {
+			traceContext = map[string]string(carrier)
+		}
+	}
+	switch _, err := db.conn(ctx).Exec(ctx, sql, topic, payload, traceContext); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot enqueue event on database",
+			slog.String("topic", topic),
+			slog.Any("error", err),
+		)
+		return infraError("cannot enqueue event on database", err)
+	}
+	return nil
+}
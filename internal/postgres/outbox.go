@@ -0,0 +1,264 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/outbox"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	_ outbox.Store           = (*DB)(nil) // Check if methods expected by outbox.Store are implemented correctly.
+	_ outbox.DeadLetterQueue = (*DB)(nil) // Check if methods expected by outbox.DeadLetterQueue are implemented correctly.
+)
+
+// outboxEvent of the outbox table.
+type outboxEvent struct {
+	ID           int64
+	Topic        string
+	Payload      json.RawMessage
+	TraceContext map[string]string
+	Attempts     int
+	CreatedAt    time.Time
+}
+
+func (e outboxEvent) dto() outbox.Event {
+	return outbox.Event{
+		ID:           e.ID,
+		Topic:        e.Topic,
+		Payload:      e.Payload,
+		TraceContext: e.TraceContext,
+		Attempts:     e.Attempts,
+		CreatedAt:    e.CreatedAt,
+	}
+}
+
+// ClaimOutboxEvents locks up to limit unpublished events, the oldest first, until the transaction ends.
+// Events locked by other transactions are skipped, so multiple relays can run concurrently.
+func (db DB) ClaimOutboxEvents(ctx context.Context, limit int) ([]outbox.Event, error) {
+	const sql = `SELECT "id", "topic", "payload", COALESCE("trace_context", '{}') AS "trace_context", "attempts", "created_at" FROM "outbox"
+	WHERE "published_at" IS NULL AND "dead_lettered_at" IS NULL
+	ORDER BY "id"
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED`
+	rows, err := db.conn(ctx).Query(ctx, sql, limit)
+	var events []outboxEvent
+	if err == nil {
+		events, err = pgx.CollectRows(rows, pgx.RowToStructByName[outboxEvent])
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot claim outbox events on database", slog.Any("error", err))
+		return nil, infraError("cannot claim outbox events on database", err)
+	}
+	resp := make([]outbox.Event, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, e.dto())
+	}
+	return resp, nil
+}
+
+// MarkOutboxEventsPublished marks events as published.
+func (db DB) MarkOutboxEventsPublished(ctx context.Context, ids []int64) error {
+	const sql = `UPDATE "outbox" SET "published_at" = now() WHERE "id" = ANY($1)`
+	switch _, err := db.conn(ctx).Exec(ctx, sql, ids); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot mark outbox events as published on database", slog.Any("error", err))
+		return infraError("cannot mark outbox events as published on database", err)
+	}
+	return nil
+}
+
+// FailOutboxEvent records a failed attempt to publish an event, with the reason.
+// If deadLetter is set, the event is moved to the dead letter queue and isn't claimed anymore.
+func (db DB) FailOutboxEvent(ctx context.Context, id int64, reason string, deadLetter bool) error {
+	const sql = `UPDATE "outbox" SET
+	"attempts" = "attempts" + 1,
+	"last_error" = $2,
+	"dead_lettered_at" = CASE WHEN $3 THEN now() END
+	WHERE "id" = $1`
+	switch _, err := db.conn(ctx).Exec(ctx, sql, id, reason, deadLetter); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot record failure of outbox event on database",
+			slog.Int64("id", id),
+			slog.Any("error", err),
+		)
+		return infraError("cannot record failure of outbox event on database", err)
+	}
+	return nil
+}
+
+// deadLetter of the outbox table.
+type deadLetter struct {
+	outboxEvent
+	LastError      string
+	DeadLetteredAt time.Time
+}
+
+func (d deadLetter) dto() outbox.DeadLetter {
+	return outbox.DeadLetter{
+		Event:          d.outboxEvent.dto(),
+		LastError:      d.LastError,
+		DeadLetteredAt: d.DeadLetteredAt,
+	}
+}
+
+const deadLetterColumns = `"id", "topic", "payload", COALESCE("trace_context", '{}') AS "trace_context", "attempts", "created_at",
+	COALESCE("last_error", '') AS "last_error", "dead_lettered_at"`
+
+// ListDeadLetters returns up to limit dead letters, the oldest events first.
+func (db DB) ListDeadLetters(ctx context.Context, limit int) ([]outbox.DeadLetter, error) {
+	const sql = `SELECT ` + deadLetterColumns + ` FROM "outbox"
+	WHERE "dead_lettered_at" IS NOT NULL
+	ORDER BY "id"
+	LIMIT $1`
+	letters, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]deadLetter, error) {
+		rows, err := conn.Query(ctx, sql, limit)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[deadLetter])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot list dead letters from database", slog.Any("error", err))
+		return nil, infraError("cannot list dead letters from database", err)
+	}
+	resp := make([]outbox.DeadLetter, 0, len(letters))
+	for _, d := range letters {
+		resp = append(resp, d.dto())
+	}
+	return resp, nil
+}
+
+// GetDeadLetter returns a dead letter, or outbox.ErrDeadLetterNotFound.
+func (db DB) GetDeadLetter(ctx context.Context, id int64) (*outbox.DeadLetter, error) {
+	const sql = `SELECT ` + deadLetterColumns + ` FROM "outbox"
+	WHERE "id" = $1 AND "dead_lettered_at" IS NOT NULL`
+	d, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (deadLetter, error) {
+		rows, err := conn.Query(ctx, sql, id)
+		if err != nil {
+			return deadLetter{}, err
+		}
+		return pgx.CollectOneRow(rows, pgx.RowToStructByName[deadLetter])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, outbox.ErrDeadLetterNotFound
+	case err != nil:
+		db.log.Error("cannot get dead letter from database",
+			slog.Int64("id", id),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get dead letter from database", err)
+	}
+	resp := d.dto()
+	return &resp, nil
+}
+
+// ReplayDeadLetters moves events from the dead letter queue back to the outbox, resetting their attempts,
+// in a transaction.
+func (db DB) ReplayDeadLetters(ctx context.Context, ids []int64) error {
+	tx, err := db.begin(ctx)
+	if err == nil {
+		defer func() {
+			if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback replay of dead letters", slog.Any("error", rerr))
+			}
+		}()
+		err = replayDeadLetters(ctx, tx, ids)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, outbox.ErrDeadLetterNotFound):
+		return err
+	case err != nil:
+		db.log.Error("cannot replay dead letters on database", slog.Any("error", err))
+		return infraError("cannot replay dead letters on database", err)
+	}
+	return nil
+}
+
+func replayDeadLetters(ctx context.Context, tx pgx.Tx, ids []int64) error {
+	const sql = `UPDATE "outbox" SET "attempts" = 0, "last_error" = NULL, "dead_lettered_at" = NULL
+	WHERE "id" = ANY($1) AND "dead_lettered_at" IS NOT NULL`
+	tag, err := tx.Exec(ctx, sql, ids)
+	if err != nil {
+		return err
+	}
+	unique := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	if tag.RowsAffected() != int64(len(unique)) {
+		return outbox.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// CountDeadLetters returns the depth of the dead letter queue.
+func (db DB) CountDeadLetters(ctx context.Context) (int, error) {
+	const sql = `SELECT count(*) FROM "outbox" WHERE "dead_lettered_at" IS NOT NULL`
+	n, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (int, error) {
+		var n int
+		err := conn.QueryRow(ctx, sql).Scan(&n)
+		return n, err
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return 0, err
+	case err != nil:
+		db.log.Error("cannot count dead letters on database", slog.Any("error", err))
+		return 0, infraError("cannot count dead letters on database", err)
+	}
+	return n, nil
+}
+
+// CountPendingEvents returns the number of events waiting to be published by topic,
+// excluding the ones on the dead letter queue.
+func (db DB) CountPendingEvents(ctx context.Context) (map[string]int, error) {
+	const sql = `SELECT "topic", count(*) FROM "outbox" WHERE "published_at" IS NULL AND "dead_lettered_at" IS NULL GROUP BY "topic"`
+	pending, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (map[string]int, error) {
+		rows, err := conn.Query(ctx, sql)
+		if err != nil {
+			return nil, err
+		}
+		pending := map[string]int{}
+		var (
+			topic string
+			n     int
+		)
+		_, err = pgx.ForEachRow(rows, []any{&topic, &n}, func() error {
+			pending[topic] = n
+			return nil
+		})
+		return pending, err
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot count pending events on database", slog.Any("error", err))
+		return nil, infraError("cannot count pending events on database", err)
+	}
+	return pending, nil
+}
@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/datagen"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// seqScanThreshold is the number of rows of a table above which a sequential scan of it is a plan regression.
+const seqScanThreshold = 1000
+
+// maxRowsRemovedByFilter is the number of rows read and discarded by a filter of a plan node above which it's a regression,
+// such as when scanning an index in order to return the first rows of a page.
+const maxRowsRemovedByFilter = 1000
+
+var (
+	seqScan             = regexp.MustCompile(`Seq Scan on (\w+)`)
+	rowsRemovedByFilter = regexp.MustCompile(`Rows Removed by Filter: (\d+)`)
+)
+
+// TestQueryPlans checks the plans of critical statements on a seeded dataset,
+// so changes to their SQL or to the migrations don't make them stop using the indexes they rely on.
+func TestQueryPlans(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	g := &datagen.Generator{Seed: 1, ReviewsPerProduct: 3}
+	if _, err := g.Load(context.Background(), pool, 20000, nil); err != nil {
+		t.Fatalf("cannot seed database: %v", err)
+	}
+	// Only some products have a SKU, as in production.
+	if _, err := pool.Exec(context.Background(), `UPDATE product SET sku = 'SKU-' || id WHERE id LIKE '%0'`); err != nil {
+		t.Fatalf("cannot set SKUs: %v", err)
+	}
+	if _, err := pool.Exec(context.Background(), `ANALYZE product, review`); err != nil {
+		t.Fatalf("cannot analyze tables: %v", err)
+	}
+	p, reviews := g.Product(42)
+	if len(reviews) == 0 {
+		t.Fatal("seeded product has no reviews")
+	}
+
+	tests := []struct {
+		name      string
+		statement string
+		run       func(context.Context, DB) error
+		wantIndex string // Any index if empty.
+	}{
+		{
+			// The first page might be read by scanning the primary key backward, filtering the rows by name,
+			// as long as it doesn't read too many rows it discards.
+			name:      "search_by_name",
+			statement: "SearchProducts",
+			run: func(ctx context.Context, db DB) error {
+				_, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+					QueryString: "Oak",
+					Pagination:  inventory.Pagination{Limit: 10},
+				})
+				return err
+			},
+		},
+		{
+			name:      "search_by_name_count",
+			statement: "SearchProductsCount",
+			run: func(ctx context.Context, db DB) error {
+				_, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+					QueryString: "Rustic Oak",
+					Pagination:  inventory.Pagination{Limit: 10},
+				})
+				return err
+			},
+			wantIndex: "product_name_trgm",
+		},
+		{
+			name:      "search_by_sku_prefix_count",
+			statement: "SearchProductsCount",
+			run: func(ctx context.Context, db DB) error {
+				_, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+					SKUPrefix:  "SKU-datagen-42",
+					Pagination: inventory.Pagination{Limit: 10},
+				})
+				return err
+			},
+			wantIndex: "product_sku_prefix",
+		},
+		{
+			name:      "search_by_sku_prefix",
+			statement: "SearchProducts",
+			run: func(ctx context.Context, db DB) error {
+				_, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+					SKUPrefix:  "SKU-datagen-42",
+					Pagination: inventory.Pagination{Limit: 10},
+				})
+				return err
+			},
+			wantIndex: "product_sku_prefix",
+		},
+		{
+			name:      "search_by_text_count",
+			statement: "SearchProductsCount",
+			run: func(ctx context.Context, db DB) error {
+				_, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+					Text:       "rustic oak",
+					Pagination: inventory.Pagination{Limit: 10},
+				})
+				return err
+			},
+			wantIndex: "product_search_document",
+		},
+		{
+			name:      "search_by_text_price_bands",
+			statement: "SearchProductsPriceBands",
+			run: func(ctx context.Context, db DB) error {
+				_, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+					Text:            "rustic oak",
+					CountPriceBands: true,
+					Pagination:      inventory.Pagination{Limit: 10},
+				})
+				return err
+			},
+			wantIndex: "product_search_document",
+		},
+		{
+			name:      "reviews_of_product",
+			statement: "GetProductReviews",
+			run: func(ctx context.Context, db DB) error {
+				_, err := db.GetProductReviews(ctx, inventory.ProductReviewsParams{
+					ProductID:  p.ID,
+					Pagination: inventory.Pagination{Limit: 10},
+				})
+				return err
+			},
+			wantIndex: "review_product_language",
+		},
+		{
+			name:      "reviews_of_product_by_language",
+			statement: "GetProductReviews",
+			run: func(ctx context.Context, db DB) error {
+				_, err := db.GetProductReviews(ctx, inventory.ProductReviewsParams{
+					ProductID:  p.ID,
+					Language:   "en",
+					Pagination: inventory.Pagination{Limit: 10},
+				})
+				return err
+			},
+			wantIndex: "review_product_language",
+		},
+		{
+			name:      "reviews_of_reviewer",
+			statement: "GetProductReviews",
+			run: func(ctx context.Context, db DB) error {
+				_, err := db.GetProductReviews(ctx, inventory.ProductReviewsParams{
+					ReviewerID: reviews[0].ReviewerID,
+					Pagination: inventory.Pagination{Limit: 10},
+				})
+				return err
+			},
+			wantIndex: "review_reviewer_created_at",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			explainer := NewExplainer([]string{tt.statement}, 1)
+			db := NewDB(pool, slog.Default(), WithExplainer(explainer))
+			if err := tt.run(context.Background(), db); err != nil {
+				t.Fatalf("%s() error = %v", tt.statement, err)
+			}
+			plans := explainer.Plans()
+			if len(plans) != 1 {
+				t.Fatalf("expected one plan to be captured, got %d instead", len(plans))
+			}
+			plan := plans[0].Plan
+			if tt.wantIndex != "" && !strings.Contains(plan, " using "+tt.wantIndex+" ") && !strings.Contains(plan, " on "+tt.wantIndex+" ") {
+				t.Errorf("plan doesn't use index %s:\n%s", tt.wantIndex, plan)
+			}
+			for _, m := range rowsRemovedByFilter.FindAllStringSubmatch(plan, -1) {
+				if rows, err := strconv.Atoi(m[1]); err != nil || rows > maxRowsRemovedByFilter {
+					t.Errorf("plan discards %s rows read, want at most %d:\n%s", m[1], maxRowsRemovedByFilter, plan)
+				}
+			}
+			for _, m := range seqScan.FindAllStringSubmatch(plan, -1) {
+				var rows float64
+				if err := pool.QueryRow(context.Background(),
+					`SELECT reltuples FROM pg_class WHERE oid = $1::regclass`, m[1]).Scan(&rows); err != nil {
+					t.Fatalf("cannot get number of rows of %s: %v", m[1], err)
+				}
+				if rows > seqScanThreshold {
+					t.Errorf("plan has a sequential scan of %s, with %.0f rows:\n%s", m[1], rows, plan)
+				}
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"strings"
 	"time"
 
@@ -15,6 +17,10 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // DB handles database communication with PostgreSQL.
@@ -22,16 +28,103 @@ type DB struct {
 	// pool for accessing Postgres database.PGX
 	pool *pgxpool.Pool
 
+	// batchPool used instead of pool for work with database.PriorityBatch, if set.
+	batchPool *pgxpool.Pool
+
 	// log is a log for the operations.
 	log *slog.Logger
+
+	// searchView routes SearchProducts through the product_search materialized view.
+	searchView bool
+
+	// windowTotal counts the products found by SearchProducts along with the page of them.
+	windowTotal bool
+
+	// explainer captures query plans of selected statements, if set.
+	explainer *Explainer
+
+	// leakDetector reports connections and transactions that aren't released, if set.
+	leakDetector *LeakDetector
+
+	// abandoned counts transactions rolled back as their context was done, if set.
+	abandoned metric.Int64Counter
+
+	// replicas used for read queries, if set.
+	replicas *replicaSet
+
+	// reviewerPseudonymizer pseudonymizes reviewer IDs, if set.
+	reviewerPseudonymizer *Pseudonymizer
+
+	// costPriceCodec encrypts the cost price of products, if set.
+	costPriceCodec *Codec
+
+	// quotas of the tenants without their own, enforcing the quotas of the tenants on what they create, if set.
+	quotas *inventory.Quota
+
+	// readOnly begins transactions in read-only access mode.
+	readOnly bool
+
+	// tracer of background work, such as refreshing the product_search view.
+	tracer trace.Tracer
+
+	// propagator to record the trace context of events enqueued on the outbox with, if set.
+	propagator propagation.TextMapPropagator
+}
+
+// Option for configuring the DB.
+type Option func(*DB)
+
+// WithSearchView makes SearchProducts read from the product_search materialized view
+// instead of the product table.
+//
+// The view must be refreshed periodically with DB.RefreshProductSearch, and search results
+// might be stale up to the refresh interval.
+func WithSearchView() Option {
+	return func(db *DB) {
+		db.searchView = true
+	}
+}
+
+// WithReadOnly begins transactions in read-only access mode, so PostgreSQL rejects any writes in them.
+// Use it along with database.WithReadOnly to reject writes outside of transactions too.
+func WithReadOnly() Option {
+	return func(db *DB) {
+		db.readOnly = true
+	}
+}
+
+// WithBatchPool uses a separate pool for work with database.PriorityBatch, such as background workers,
+// so it can't take the connections of the pool used by interactive work, such as API requests.
+// It should be a smaller pool connected to the same database.
+//
+// Batch work doesn't read from the replicas when a batch pool is set, for the same reason.
+func WithBatchPool(pool *pgxpool.Pool) Option {
+	return func(db *DB) {
+		db.batchPool = pool
+	}
+}
+
+// WithTracing traces background work, such as refreshing the product_search view, as new root spans.
+// The trace context of the requests enqueuing events on the outbox is recorded with the propagator,
+// so the spans publishing them can link to their traces.
+func WithTracing(tp trace.TracerProvider, propagator propagation.TextMapPropagator) Option {
+	return func(db *DB) {
+		db.tracer = tp.Tracer("postgres")
+		db.propagator = propagator
+	}
 }
 
 // NewDB creates a DB.
-func NewDB(pool *pgxpool.Pool, logger *slog.Logger) DB {
-	return DB{
-		pool: pool,
-		log:  logger,
+func NewDB(pool *pgxpool.Pool, logger *slog.Logger, opts ...Option) DB {
+	db := DB{
+		pool:   pool,
+		log:    logger,
+		tracer: noop.NewTracerProvider().Tracer("postgres"),
 	}
+	for _, o := range opts {
+		o(&db)
+	}
+	return db
 }
 
 // TransactionContext returns a copy of the parent context which begins a transaction
@@ -39,28 +132,64 @@ func NewDB(pool *pgxpool.Pool, logger *slog.Logger) DB {
 //
 // Once the transaction is over, you must call db.Commit(ctx) to make the changes effective.
 // This might live in the go-pkg/postgres package later for the sake of code reuse.
+//
+// If the context has a deadline, a matching LOCAL statement_timeout is set for the transaction.
+//
+// If the context is done before the transaction is committed or rolled back, the transaction is abandoned:
+// db.Commit(ctx) rolls it back instead, returning the error of the context, and db.Rollback(ctx) rolls it back
+// without using the done context, so its connection is returned to the pool rather than closed.
+// Defer db.Rollback(ctx) right after beginning a transaction, so it's never left behind.
 func (db DB) TransactionContext(ctx context.Context) (context.Context, error) {
-	tx, err := db.conn(ctx).Begin(ctx)
+	tx, err := db.begin(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if _, err := setStatementTimeout(ctx, tx, true); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			db.log.Error("cannot rollback transaction", slog.Any("error", rollbackErr))
+		}
+		return nil, err
+	}
+	db.leakDetector.acquire(tx, "transaction")
 	return context.WithValue(ctx, txCtx{}, tx), nil
 }
 
+var (
+	// ErrNoTransaction is returned by DB.Commit and DB.Rollback when the context has no transaction.
+	ErrNoTransaction = errors.New("context has no transaction")
+
+	// ErrAlreadyAcquired is returned by DB.WithAcquire when the context already has a connection,
+	// acquired by DB.WithAcquire or held by a transaction begun by DB.TransactionContext.
+	// Its commands already run in series on the same connection, so it can be used as is.
+	ErrAlreadyAcquired = errors.New("context already has a connection acquired")
+)
+
 // Commit transaction from context.
+// If the context is done, the transaction is rolled back, and the error of the context is returned.
 func (db DB) Commit(ctx context.Context) error {
 	if tx, ok := ctx.Value(txCtx{}).(pgx.Tx); ok && tx != nil {
+		db.leakDetector.release(tx)
+		if ctx.Err() != nil {
+			if err := db.rollbackAbandoned(ctx, tx); err != nil {
+				return err
+			}
+			return ctx.Err()
+		}
 		return tx.Commit(ctx)
 	}
-	return errors.New("context has no transaction")
+	return ErrNoTransaction
 }
 
 // Rollback transaction from context.
 func (db DB) Rollback(ctx context.Context) error {
 	if tx, ok := ctx.Value(txCtx{}).(pgx.Tx); ok && tx != nil {
+		db.leakDetector.release(tx)
+		if ctx.Err() != nil {
+			return db.rollbackAbandoned(ctx, tx)
+		}
 		return tx.Rollback(ctx)
 	}
-	return errors.New("context has no transaction")
+	return ErrNoTransaction
 }
 
 // WithAcquire returns a copy of the parent context which acquires a connection
@@ -72,22 +201,80 @@ func (db DB) Rollback(ctx context.Context) error {
 // Example:
 // dbCtx := db.WithAcquire(ctx)
 // defer postgres.Release(dbCtx)
+//
+// If the context has a deadline, a matching statement_timeout is set for the connection
+// until it is released.
+//
+// If the context already has a connection, it returns ErrAlreadyAcquired.
 func (db DB) WithAcquire(ctx context.Context) (dbCtx context.Context, err error) {
 	if _, ok := ctx.Value(connCtx{}).(*pgxpool.Conn); ok {
-		panic("context already has a connection acquired")
+		return nil, ErrAlreadyAcquired
+	}
+	if _, ok := ctx.Value(txCtx{}).(pgx.Tx); ok {
+		return nil, ErrAlreadyAcquired
 	}
-	res, err := db.pool.Acquire(ctx)
+	res, err := db.poolFor(ctx).Acquire(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if _, err := setStatementTimeout(ctx, res, false); err != nil {
+		res.Release()
+		return nil, err
+	}
+	db.leakDetector.acquire(res, "connection")
 	return context.WithValue(ctx, connCtx{}, res), nil
 }
 
 // Release PostgreSQL connection acquired by context back to the pool.
 func (db DB) Release(ctx context.Context) {
-	if res, ok := ctx.Value(connCtx{}).(*pgxpool.Conn); ok && res != nil {
-		res.Release()
+	res, ok := ctx.Value(connCtx{}).(*pgxpool.Conn)
+	if !ok || res == nil {
+		return
 	}
+	db.leakDetector.release(res)
+	if _, ok := ctx.Deadline(); ok {
+		// Reset the statement_timeout set by WithAcquire before returning the connection to the pool.
+		// The request context might be done already, so use a new context for it.
+		resetCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := res.Exec(resetCtx, "RESET statement_timeout"); err != nil {
+			db.log.Error("cannot reset statement_timeout, closing connection", slog.Any("error", err))
+			if err := res.Conn().Close(resetCtx); err != nil {
+				db.log.Error("cannot close connection", slog.Any("error", err))
+			}
+		}
+	}
+	res.Release()
+}
+
+// begin a transaction, or a pseudo nested transaction (savepoint) if the context already has a transaction.
+// In read-only mode, transactions are read-only (savepoints inherit it from their transaction).
+func (db DB) begin(ctx context.Context) (pgx.Tx, error) {
+	conn := db.conn(ctx)
+	if c, ok := conn.(database.PGX); ok && db.readOnly {
+		return c.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	}
+	return conn.Begin(ctx)
+}
+
+// savepoint runs fn under a savepoint if the context has a transaction, rolling back to it if fn fails,
+// so the transaction can go on after an error such as a unique violation. Otherwise, fn runs on db.conn(ctx).
+func (db DB) savepoint(ctx context.Context, fn func(conn database.PGXQuerier) error) error {
+	tx, ok := ctx.Value(txCtx{}).(pgx.Tx)
+	if !ok || tx == nil {
+		return fn(db.conn(ctx))
+	}
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(sp); err != nil {
+		if rerr := sp.Rollback(ctx); rerr != nil && ctx.Err() == nil {
+			db.log.Error("cannot rollback to savepoint", slog.Any("error", rerr))
+		}
+		return err
+	}
+	return sp.Commit(ctx)
 }
 
 // txCtx key.
@@ -106,25 +293,104 @@ func (db DB) conn(ctx context.Context) database.PGXQuerier {
 	if res, ok := ctx.Value(connCtx{}).(*pgxpool.Conn); ok && res != nil {
 		return res
 	}
+	return db.poolFor(ctx)
+}
+
+// poolFor returns the pool for the priority of the context.
+func (db DB) poolFor(ctx context.Context) *pgxpool.Pool {
+	if db.batchPool != nil && database.PriorityFromContext(ctx) == database.PriorityBatch {
+		return db.batchPool
+	}
 	return db.pool
 }
 
 var _ inventory.DB = (*DB)(nil) // Check if methods expected by inventory.DB are implemented correctly.
 
 // CreateProduct creates a new product.
-func (db DB) CreateProduct(ctx context.Context, params inventory.CreateProductParams) error {
-	const sql = `INSERT INTO product ("id", "name", "description", "price") VALUES ($1, $2, $3, $4);`
-	switch _, err := db.conn(ctx).Exec(ctx, sql, params.ID, params.Name, params.Description, params.Price); {
-	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+// If a product with the same ID already exists, it returns the existing product instead.
+// With WithQuotas, it fails with a *inventory.QuotaExceededError if the tenant of the product reached its quota.
+func (db DB) CreateProduct(ctx context.Context, params inventory.CreateProductParams) (res *inventory.CreateProductResult, err error) {
+	tenant, ok := db.quotaTenant(params.ID)
+	if !ok {
+		return db.createProduct(ctx, params)
+	}
+	err = db.withinQuota(ctx, "create product", func(ctx context.Context) error {
+		return db.checkProductQuota(ctx, tenant, params.ID)
+	}, func(ctx context.Context) (err error) {
+		res, err = db.createProduct(ctx, params)
 		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (db DB) createProduct(ctx context.Context, params inventory.CreateProductParams) (*inventory.CreateProductResult, error) {
+	// ON CONFLICT DO NOTHING doesn't return the conflicting row, so it's read with a follow-up query.
+	// The SELECT runs as a separate statement to see a conflicting row committed by a concurrent transaction
+	// after the INSERT statement began.
+	// If the conflicting row is deleted in the meantime, try to create the product again.
+	// The created product is recorded on the audit_log by the same statement (see GetProductAt).
+	// Its slug is generated from the name, and suffixed by a number if it's already taken.
+	// A concurrent transaction might take the same slug first, so it's retried on such conflict too.
+	insert := fmt.Sprintf(`WITH p AS (
+		INSERT INTO product ("id", "name", "description", "price", "status", "slug", "sku", "gtin", "tax_class")
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'active')::product_status, product_free_slug(product_slug($2), $1),
+			NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''))
+		ON CONFLICT ("id") DO NOTHING
+		RETURNING *
+	), a AS (
+		INSERT INTO audit_log ("action", "subject", "details")
+		SELECT 'product_created', p."id", to_jsonb(p) - 'cost_price' - 'search' - 'price_band' FROM p
+	)
+	SELECT %s FROM p`, pgtools.Wildcard(product{})) // #nosec G201
+	sel := fmt.Sprintf(`SELECT %s FROM "product" WHERE id = $1`, pgtools.Wildcard(product{})) // #nosec G201
+	const maxAttempts = 3
+	var (
+		p       product
+		created bool
+		err     error
+	)
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		// Within a transaction, the INSERT runs under a savepoint, as a slug conflict would abort the transaction.
+		err = db.savepoint(ctx, func(conn database.PGXQuerier) error {
+			rows, err := conn.Query(ctx, insert,
+				params.ID, params.Name, params.Description, params.Price, string(params.Status), params.SKU, params.GTIN, params.TaxClass)
+			if err != nil {
+				return err
+			}
+			p, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[product])
+			return err
+		})
+		if isSlugConflict(err) {
+			continue
+		}
+		if created = err == nil; created || !errors.Is(err, pgx.ErrNoRows) {
+			break
+		}
+		var rows pgx.Rows
+		if rows, err = db.conn(ctx).Query(ctx, sel, params.ID); err == nil {
+			p, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[product])
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			break
+		}
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
 	case err != nil:
 		if sqlErr := db.productPgError(err); sqlErr != nil {
-			return sqlErr
+			return nil, sqlErr
 		}
 		db.log.Error("cannot create product on database", slog.Any("error", err))
-		return errors.New("cannot create product on database")
+		return nil, infraError("cannot create product on database", err)
 	}
-	return nil
+	return &inventory.CreateProductResult{
+		Product: p.dto(),
+		Created: created,
+	}, nil
 }
 
 func (db DB) productPgError(err error) error {
@@ -133,6 +399,14 @@ func (db DB) productPgError(err error) error {
 		return nil
 	}
 	if pgErr.Code == pgerrcode.UniqueViolation {
+		switch pgErr.ConstraintName {
+		case "product_slug_key":
+			return errors.New("product slug was taken concurrently")
+		case "product_sku_key":
+			return inventory.ErrDuplicateSKU
+		case "product_gtin_key":
+			return inventory.ErrDuplicateGTIN
+		}
 		return errors.New("product already exists")
 	}
 	if pgErr.Code == pgerrcode.CheckViolation {
@@ -143,44 +417,95 @@ func (db DB) productPgError(err error) error {
 			return errors.New("invalid product name")
 		case "product_price_check":
 			return errors.New("invalid price")
+		case "product_sku_check":
+			return errors.New("invalid product SKU")
+		case "product_gtin_check":
+			return errors.New("invalid product GTIN")
+		case "product_tax_class_check":
+			return errors.New("invalid tax class")
 		}
 	}
 	return nil
 }
 
+// isSlugConflict returns whether the error is due to a product slug taken by a concurrent transaction.
+func isSlugConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == "product_slug_key"
+}
+
 // ErrProductNotFound is returned when a product is not found.
 var ErrProductNotFound = errors.New("product not found")
 
 // UpdateProduct updates an existing product.
-func (db DB) UpdateProduct(ctx context.Context, params inventory.UpdateProductParams) error {
-	const sql = `UPDATE "product" SET
-	"name" = COALESCE($1, "name"),
-	"description" = COALESCE($2, "description"),
-	"price" = COALESCE($3, "price"),
-	"modified_at" = now()
-	WHERE id = $4`
-	ct, err := db.conn(ctx).Exec(ctx, sql,
+// The updated product is recorded on the audit_log by the same statement (see GetProductAt).
+//
+// If the name changes, the product is re-slugged, unless the slug still matches it, and the previous slug is kept
+// as a redirect to the product. A product renamed back reclaims its previous slug.
+func (db DB) UpdateProduct(ctx context.Context, params inventory.UpdateProductParams) (*inventory.Product, error) {
+	sql := fmt.Sprintf(`WITH old AS (
+		SELECT "slug" FROM "product" WHERE id = $5 FOR UPDATE
+	), p AS (
+		UPDATE "product" SET
+		"name" = COALESCE($1, "name"),
+		"description" = COALESCE($2, "description"),
+		"price" = COALESCE($3, "price"),
+		"status" = COALESCE($4::product_status, "status"),
+		"slug" = CASE
+			WHEN $1::text IS NULL OR "slug" ~ ('^' || product_slug($1) || '(-[0-9]+)?$') THEN "slug"
+			ELSE product_free_slug(product_slug($1), "id")
+		END,
+		"sku" = CASE WHEN $6::text IS NULL THEN "sku" ELSE NULLIF($6, '') END,
+		"gtin" = CASE WHEN $7::text IS NULL THEN "gtin" ELSE NULLIF($7, '') END,
+		"tax_class" = CASE WHEN $8::text IS NULL THEN "tax_class" ELSE NULLIF($8, '') END,
+		"modified_at" = now()
+		WHERE id = $5
+		RETURNING *
+	), r AS (
+		INSERT INTO "product_slug_redirect" ("slug", "product_id")
+		SELECT old."slug", p."id" FROM old, p WHERE old."slug" <> p."slug"
+		ON CONFLICT ("slug") DO UPDATE SET "product_id" = EXCLUDED."product_id", "created_at" = now()
+	), d AS (
+		DELETE FROM "product_slug_redirect" WHERE "slug" IN (SELECT "slug" FROM p)
+	), a AS (
+		INSERT INTO audit_log ("action", "subject", "details")
+		SELECT 'product_updated', p."id", to_jsonb(p) - 'cost_price' - 'search' - 'price_band' FROM p
+	)
+	SELECT %s FROM p`, pgtools.Wildcard(product{})) // #nosec G201
+	rows, err := db.conn(ctx).Query(ctx, sql,
 		params.Name,
 		params.Description,
 		params.Price,
-		params.ID)
-	if err == context.Canceled || err == context.DeadlineExceeded {
-		return err
+		params.Status,
+		params.ID,
+		params.SKU,
+		params.GTIN,
+		params.TaxClass)
+	var p product
+	if err == nil {
+		p, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[product])
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrProductNotFound
 	}
 	if err != nil {
 		if sqlErr := db.productPgError(err); sqlErr != nil {
-			return sqlErr
+			return nil, sqlErr
 		}
 		db.log.Error("cannot update product on database", slog.Any("error", err))
-		return errors.New("cannot update product on database")
+		return nil, infraError("cannot update product on database", err)
 	}
-	if ct.RowsAffected() == 0 {
-		return ErrProductNotFound
-	}
-	return nil
+	return p.dto(), nil
 }
 
 // product table.
+//
+// Rows are mapped to its fields by column name, and only its columns are read, as listed by pgtools.Wildcard,
+// so columns the API doesn't return, such as the search document and the encrypted cost price,
+// aren't transferred, and new columns added by migrations don't break reading products.
 type product struct {
 	ID          string
 	Name        string
@@ -188,6 +513,17 @@ type product struct {
 	Price       int
 	CreatedAt   time.Time
 	ModifiedAt  time.Time
+	Status      string // product_status enum.
+	Slug        string
+	SKU         *string
+	GTIN        *string
+	TaxClass    *string
+}
+
+// Types returns the custom data types used by the postgres package.
+// They should be registered on every connection with database.WithTypes.
+func Types() []string {
+	return []string{"product_status"}
 }
 
 func (p *product) dto() *inventory.Product {
@@ -196,24 +532,40 @@ func (p *product) dto() *inventory.Product {
 		Name:        p.Name,
 		Description: p.Description,
 		Price:       p.Price,
+		Status:      inventory.ProductStatus(p.Status),
 		CreatedAt:   p.CreatedAt,
 		ModifiedAt:  p.ModifiedAt,
+		Slug:        p.Slug,
+		SKU:         nullString(p.SKU),
+		GTIN:        nullString(p.GTIN),
+		TaxClass:    nullString(p.TaxClass),
 	}
 }
 
+// nullString returns the value of a nullable text column, or an empty string if it's NULL.
+func nullString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // GetProduct returns a product.
 func (db DB) GetProduct(ctx context.Context, id string) (*inventory.Product, error) {
-	var p product
 	// The following pgtools.Wildcard() call returns:
-	// "id","product_id","reviewer_id","title","description","score","created_at","modified_at"
-	sql := fmt.Sprintf(`SELECT %s FROM "product" WHERE id = $1 LIMIT 1`, pgtools.Wildcard(p)) // #nosec G201
-	rows, err := db.conn(ctx).Query(ctx, sql, id)
+	// "id","name","description","price","created_at","modified_at","status","slug","sku","gtin","tax_class"
+	sql := fmt.Sprintf(`SELECT %s FROM "product" WHERE id = $1 LIMIT 1`, pgtools.Wildcard(product{})) // #nosec G201
+	db.explain(ctx, "GetProduct", sql, id)
+	p, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (product, error) {
+		rows, err := conn.Query(ctx, sql, id)
+		if err != nil {
+			return product{}, err
+		}
+		return pgx.CollectOneRow(rows, pgx.RowToStructByName[product])
+	})
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return nil, err
 	}
-	if err == nil {
-		p, err = pgx.CollectOneRow(rows, pgx.RowToStructByPos[product])
-	}
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -222,95 +574,385 @@ func (db DB) GetProduct(ctx context.Context, id string) (*inventory.Product, err
 			slog.Any("id", id),
 			slog.Any("error", err),
 		)
-		return nil, errors.New("cannot get product from database")
+		return nil, infraError("cannot get product from database", err)
 	}
-	return p.dto(), nil
+	return db.withDetails(ctx, p.dto())
 }
 
-// SearchProducts returns a list of products.
-func (db DB) SearchProducts(ctx context.Context, params inventory.SearchProductsParams) (*inventory.SearchProductsResponse, error) {
+// GetProductBySlug returns a product by its current or a previous slug.
+func (db DB) GetProductBySlug(ctx context.Context, slug string) (*inventory.Product, error) {
+	// A slug is either current or previous, as a product taking a previous slug of its own deletes the redirect.
+	sql := fmt.Sprintf(`SELECT %s FROM "product"
+	WHERE "slug" = $1 OR "id" = (SELECT "product_id" FROM "product_slug_redirect" WHERE "slug" = $1)
+	LIMIT 1`, pgtools.Wildcard(product{})) // #nosec G201
+	db.explain(ctx, "GetProductBySlug", sql, slug)
+	p, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (product, error) {
+		rows, err := conn.Query(ctx, sql, slug)
+		if err != nil {
+			return product{}, err
+		}
+		return pgx.CollectOneRow(rows, pgx.RowToStructByName[product])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		db.log.Error("cannot get product by slug from database",
+			slog.Any("slug", slug),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get product from database", err)
+	}
+	return db.withDetails(ctx, p.dto())
+}
+
+// GetProductBySKU returns a product by its SKU.
+func (db DB) GetProductBySKU(ctx context.Context, sku string) (*inventory.Product, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "product" WHERE "sku" = $1 LIMIT 1`, pgtools.Wildcard(product{})) // #nosec G201
+	db.explain(ctx, "GetProductBySKU", sql, sku)
+	p, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (product, error) {
+		rows, err := conn.Query(ctx, sql, sku)
+		if err != nil {
+			return product{}, err
+		}
+		return pgx.CollectOneRow(rows, pgx.RowToStructByName[product])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		db.log.Error("cannot get product by SKU from database",
+			slog.Any("sku", sku),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get product from database", err)
+	}
+	return db.withDetails(ctx, p.dto())
+}
+
+// GetProducts returns the products with the given IDs, in any order.
+func (db DB) GetProducts(ctx context.Context, ids []string) ([]*inventory.Product, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "product" WHERE "id" = ANY($1)`, pgtools.Wildcard(product{})) // #nosec G201
+	resp, err := db.listProducts(ctx, "GetProducts", inventory.ProductViewFull, sql, ids)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// productSnapshot recorded on the audit_log.
+type productSnapshot struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Price       int       `json:"price"`
+	CreatedAt   time.Time `json:"created_at"`
+	ModifiedAt  time.Time `json:"modified_at"`
+	Status      string    `json:"status"`
+	Slug        string    `json:"slug"`
+	SKU         *string   `json:"sku"`
+	GTIN        *string   `json:"gtin"`
+	TaxClass    *string   `json:"tax_class"`
+}
+
+// GetProductAt returns the product as it was at the given time, or nil if it didn't exist then.
+// It's reconstructed from the latest change recorded on the audit_log until then.
+func (db DB) GetProductAt(ctx context.Context, id string, at time.Time) (*inventory.Product, error) {
+	const sql = `SELECT "action", "details" FROM "audit_log"
+	WHERE "subject" = $1 AND "action" IN ('product_created', 'product_updated', 'product_deleted') AND "created_at" <= $2
+	ORDER BY "created_at" DESC, "id" DESC
+	LIMIT 1`
 	var (
-		args = []any{"%" + params.QueryString + "%"}
-		w    = []string{"name LIKE $1"}
+		action   string
+		snapshot productSnapshot
 	)
-
-	if params.MinPrice != 0 {
-		args = append(args, params.MinPrice)
-		w = append(w, fmt.Sprintf(`"price" >= $%d`, len(args)))
+	err := db.conn(ctx).QueryRow(ctx, sql, id, at).Scan(&action, &snapshot)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return db.getProductAtWithoutHistory(ctx, id, at)
+	case err != nil:
+		db.log.Error("cannot get product history from database",
+			slog.Any("id", id),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get product history from database", err)
+	case action == "product_deleted":
+		return nil, nil
 	}
-	if params.MaxPrice != 0 {
-		args = append(args, params.MaxPrice)
-		w = append(w, fmt.Sprintf(`"price" <= $%d`, len(args)))
+	p := product(snapshot)
+	return p.dto(), nil
+}
+
+// getProductAtWithoutHistory returns the product at the given time when no change was recorded until then.
+// Either the product didn't exist yet, or it predates the audit_log and wasn't modified since then.
+func (db DB) getProductAtWithoutHistory(ctx context.Context, id string, at time.Time) (*inventory.Product, error) {
+	p, err := db.GetProduct(ctx, id)
+	switch {
+	case err != nil:
+		return nil, err
+	case p == nil || p.CreatedAt.After(at):
+		return nil, nil
+	case p.ModifiedAt.After(at):
+		return nil, inventory.ErrNoProductHistory
 	}
+	p.Available = 0 // Stock levels aren't part of the history.
+	return p, nil
+}
 
-	where := strings.Join(w, " AND ")
-	sqlTotal := fmt.Sprintf(`SELECT COUNT(*) AS total FROM "product" WHERE %s`, where) // #nosec G201
+// SearchProducts returns a list of products.
+func (db DB) SearchProducts(ctx context.Context, params inventory.SearchProductsParams) (*inventory.SearchProductsResponse, error) {
+	table := "product"
+	if db.searchView {
+		table = "product_search"
+	}
+	where := searchProductsConditions(params)
+	if db.windowTotal && !params.CountPriceBands && !params.EstimateTotal {
+		return db.searchProductsWindow(ctx, params, table, where)
+	}
 	resp := inventory.SearchProductsResponse{
 		Items: []*inventory.Product{},
 	}
-	switch err := db.conn(ctx).QueryRow(ctx, sqlTotal, args...).Scan(&resp.Total); {
+	var err error
+	if params.CountPriceBands {
+		resp.PriceBands, err = db.countPriceBands(ctx, table, where)
+		for _, pb := range resp.PriceBands {
+			resp.Total += pb.Count
+		}
+	} else {
+		resp.Total, resp.TotalIsEstimate, err = db.count(ctx, "SearchProductsCount", table, where, params.EstimateTotal)
+	}
+	switch {
 	case err == context.Canceled || err == context.DeadlineExceeded:
 		return nil, err
 	case err != nil:
 		db.log.Error("cannot get product count from the database", slog.Any("error", err))
-		return nil, errors.New("cannot get product")
+		return nil, infraError("cannot get product", err)
 	}
 
-	// Once the count query was made, add pagination args and query the results of the current page.
-	sql := fmt.Sprintf(`SELECT * FROM "product" WHERE %s ORDER BY "id" DESC`, where) // #nosec G201
-	if params.Pagination.Limit != 0 {
-		args = append(args, params.Pagination.Limit)
-		sql += fmt.Sprintf(` LIMIT $%d`, len(args))
-	}
-	if params.Pagination.Offset != 0 {
-		args = append(args, params.Pagination.Offset)
-		sql += fmt.Sprintf(` OFFSET $%d`, len(args))
-	}
-
-	rows, err := db.conn(ctx).Query(ctx, sql, args...)
+	q := searchProductsQuery(params, pgx.Identifier{table}.Sanitize(), productColumns(params.View, table), where)
+	sql, args := q.String(), q.args
+	db.explain(ctx, "SearchProducts", sql, args...)
+	products, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]*inventory.Product, error) {
+		rows, err := conn.Query(ctx, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		products, err := pgx.CollectRows(rows, productRow(params.View))
+		if err == nil && rateLater(params.View, table) {
+			err = productRatings(ctx, conn, products)
+		}
+		return products, err
+	})
 	if err == context.Canceled || err == context.DeadlineExceeded {
 		return nil, err
 	}
-	var products []product
-	if err == nil {
-		products, err = pgx.CollectRows(rows, pgx.RowToStructByPos[product])
-	}
 	if err != nil {
 		db.log.Error("cannot get products from the database", slog.Any("error", err))
-		return nil, errors.New("cannot get products")
-	}
-	for _, p := range products {
-		resp.Items = append(resp.Items, p.dto())
+		return nil, infraError("cannot get products", err)
 	}
+	resp.Items = append(resp.Items, products...)
 	return &resp, nil
 }
 
-// DeleteProduct from the database.
-func (db DB) DeleteProduct(ctx context.Context, id string) error {
-	switch _, err := db.conn(ctx).Exec(ctx, `DELETE FROM "product" WHERE "id" = $1`, id); {
-	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+// searchProductsConditions returns the conditions of the products matching the search.
+func searchProductsConditions(params inventory.SearchProductsParams) conditions {
+	var where conditions
+	if params.QueryString != "" || (params.SKUPrefix == "" && params.Text == "") {
+		where.add(`"name" LIKE ?`, "%"+params.QueryString+"%")
+	}
+	if params.SKUPrefix != "" {
+		where.add(`"sku" LIKE ?`, likeEscaper.Replace(params.SKUPrefix)+"%")
+	}
+	if params.Text != "" {
+		// The search generated column is indexed with product_search_document, so products aren't parsed on search.
+		where.add(`"search" @@ websearch_to_tsquery('simple', ?)`, params.Text)
+	}
+	if params.MinPrice != 0 {
+		where.add(`"price" >= ?`, params.MinPrice)
+	}
+	if params.MaxPrice != 0 {
+		where.add(`"price" <= ?`, params.MaxPrice)
+	}
+	return where
+}
+
+// searchProductsQuery returns the query of the columns of the page of products matching the where conditions,
+// from the table, or the join with it, quoted as needed.
+func searchProductsQuery(params inventory.SearchProductsParams, from, columns string, where conditions) *query {
+	// IDs are compared byte by byte, regardless of the collation of the database, so the results of shards can be merged.
+	order := `"id" COLLATE "C" DESC`
+	if params.OrderBy == inventory.ProductsByName {
+		_, c := collation(params.Locales)
+		order = fmt.Sprintf(`"name" COLLATE %s, "id" COLLATE "C"`, c)
+	}
+	q := newQuery(fmt.Sprintf(`SELECT %s FROM %s`, columns, from)) // #nosec G201
+	return q.where(where).append(" ORDER BY "+order).page(params.Pagination.Limit, params.Pagination.Offset)
+}
+
+// exactCountThreshold is the estimated number of rows under which count counts them even if asked to estimate,
+// as counting few rows is cheap, and the estimates of the planner are the least accurate for them.
+const exactCountThreshold = 1000
+
+// count returns the number of rows of the table matching the where conditions, and whether it's an estimate.
+// With estimate set, it returns the number of rows estimated by the query planner from the table statistics,
+// which is much cheaper than counting them on large tables, but might be off by a large margin.
+func (db DB) count(ctx context.Context, statement, table string, where conditions, estimate bool) (int, bool, error) {
+	if estimate {
+		n, err := db.estimateRows(ctx, table, where)
+		if err != nil || n >= exactCountThreshold {
+			return n, err == nil, err
+		}
+	}
+	q := newQuery(fmt.Sprintf(`SELECT COUNT(*) AS total FROM %q`, table)).where(where) // #nosec G201
+	sql, args := q.String(), q.args
+	db.explain(ctx, statement, sql, args...)
+	total, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (total int, err error) {
+		err = conn.QueryRow(ctx, sql, args...).Scan(&total)
+		return total, err
+	})
+	return total, false, err
+}
+
+// estimateRows returns the number of rows of the table matching the where conditions estimated by the query planner,
+// without executing the query.
+func (db DB) estimateRows(ctx context.Context, table string, where conditions) (int, error) {
+	q := newQuery(fmt.Sprintf(`EXPLAIN (FORMAT JSON) SELECT 1 FROM %q`, table)).where(where) // #nosec G201
+	sql, args := q.String(), q.args
+	return read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (int, error) {
+		var plans []struct {
+			Plan struct {
+				Rows float64 `json:"Plan Rows"`
+			}
+		}
+		if err := conn.QueryRow(ctx, sql, args...).Scan(&plans); err != nil {
+			return 0, err
+		}
+		if len(plans) == 0 {
+			return 0, errors.New("query plan not found")
+		}
+		return int(plans[0].Plan.Rows), nil
+	})
+}
+
+// countPriceBands returns the price bands with products of the table matching the where conditions, from the cheapest.
+// The band of each product is read from the price_band generated column rather than computed from its price on each search.
+func (db DB) countPriceBands(ctx context.Context, table string, where conditions) ([]inventory.PriceBand, error) {
+	q := newQuery(fmt.Sprintf(`SELECT "price_band", COUNT(*) FROM %q`, table)) // #nosec G201
+	q.where(where).append(` GROUP BY "price_band" ORDER BY "price_band"`)
+	sql, args := q.String(), q.args
+	db.explain(ctx, "SearchProductsPriceBands", sql, args...)
+	return read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]inventory.PriceBand, error) {
+		rows, err := conn.Query(ctx, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, func(row pgx.CollectableRow) (inventory.PriceBand, error) {
+			var band, count int
+			err := row.Scan(&band, &count)
+			return inventory.NewPriceBand(band, count), err
+		})
+	})
+}
+
+// likeEscaper escapes the characters with a special meaning in a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// DeleteProduct deletes a product.
+// Unless params.Force is set, it refuses to delete a product with reviews.
+// Otherwise, the product reviews are deleted in the same transaction.
+func (db DB) DeleteProduct(ctx context.Context, params inventory.DeleteProductParams) error {
+	tx, err := db.begin(ctx)
+	if err == nil {
+		defer func() {
+			if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback product deletion", slog.Any("error", rerr))
+			}
+		}()
+		err = db.deleteProduct(ctx, tx, params)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	var hasDependents *inventory.HasDependentsError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded), errors.As(err, &hasDependents):
 		return err
 	case err != nil:
 		db.log.Error("cannot delete product from database", slog.Any("error", err))
-		return errors.New("cannot delete product from database")
+		return infraError("cannot delete product from database", err)
 	}
 	return nil
 }
 
+func (db DB) deleteProduct(ctx context.Context, tx pgx.Tx, params inventory.DeleteProductParams) error {
+	// Lock the product row first, as it blocks concurrent inserts of reviews referencing it until the transaction ends.
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM "product" WHERE "id" = $1 FOR UPDATE`, params.ID); err != nil {
+		return err
+	}
+	var reviews int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM "review" WHERE "product_id" = $1`, params.ID).Scan(&reviews); err != nil {
+		return err
+	}
+	if reviews > 0 && !params.Force {
+		return &inventory.HasDependentsError{Reviews: reviews}
+	}
+	// Delete reviews explicitly rather than relying on ON DELETE CASCADE to make the intent clear.
+	if _, err := tx.Exec(ctx, `DELETE FROM "review" WHERE "product_id" = $1`, params.ID); err != nil {
+		return err
+	}
+	ct, err := tx.Exec(ctx, `DELETE FROM "product" WHERE "id" = $1`, params.ID)
+	if err != nil || ct.RowsAffected() == 0 {
+		return err
+	}
+	return audit(ctx, tx, "product_deleted", params.ID, map[string]any{"reviews": reviews})
+}
+
 // CreateProductReview for a given product.
+// With WithQuotas, it fails with a *inventory.QuotaExceededError if the product reached the review quota of its tenant.
 func (db DB) CreateProductReview(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+	tenant, ok := db.quotaTenant(params.ProductID)
+	if !ok {
+		return db.createProductReview(ctx, params)
+	}
+	return db.withinQuota(ctx, "create review", func(ctx context.Context) error {
+		return db.checkReviewQuota(ctx, tenant, params.ProductID)
+	}, func(ctx context.Context) error {
+		return db.createProductReview(ctx, params)
+	})
+}
+
+func (db DB) createProductReview(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+	// The review and its attachments are inserted by a single statement, so it's atomic without a transaction.
 	const sql = `
-	INSERT INTO review (
-		"id", "product_id", "reviewer_id",
-		"title", "description", "score"
+	WITH r AS (
+		INSERT INTO review (
+			"id", "product_id", "reviewer_id",
+			"title", "description", "score",
+			"language", "flagged", "sentiment"
+		)
+		VALUES (
+			$1, $2, $3,
+			$4, $5, $6,
+			$7, $8, $9
+		)
+		RETURNING "id"
 	)
-	VALUES (
-		$1, $2, $3,
-		$4, $5, $6
-	);`
+	INSERT INTO "review_attachment" ("review_id", "url", "content_type")
+	SELECT r."id", a."url", a."content_type"
+	FROM r, unnest($10::text[], $11::text[]) WITH ORDINALITY AS a("url", "content_type", "n")
+	ORDER BY a."n";`
+	urls, contentTypes := attachmentColumns(params.Attachments)
 	switch _, err := db.conn(ctx).Exec(ctx, sql,
-		params.ID, params.ProductID, params.ReviewerID,
-		params.Title, params.Description, params.Score); {
+		params.ID, params.ProductID, db.reviewerPseudonymizer.pseudonymize(params.ReviewerID),
+		params.Title, params.Description, params.Score,
+		params.Language, params.Flagged, params.Sentiment,
+		urls, contentTypes); {
 	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
 		return err
 	case err != nil:
@@ -318,7 +960,7 @@ func (db DB) CreateProductReview(ctx context.Context, params inventory.CreatePro
 			return sqlErr
 		}
 		db.log.Error("cannot create review on database", slog.Any("error", err))
-		return errors.New("cannot create review on database")
+		return infraError("cannot create review on database", err)
 	}
 	return nil
 }
@@ -339,7 +981,11 @@ func (db DB) productReviewPgError(err error) error {
 		case "review_id_check":
 			return errors.New("invalid product review ID")
 		case "review_title_check":
-			return errors.New("invalid title")
+			return &inventory.FieldError{Field: "title", Message: "missing review title"}
+		case "review_title_length":
+			return &inventory.FieldError{Field: "title", Message: fmt.Sprintf("review title is longer than %d characters", inventory.MaxReviewTitleLength)}
+		case "review_description_length":
+			return &inventory.FieldError{Field: "description", Message: fmt.Sprintf("review description is longer than %d characters", inventory.MaxReviewDescriptionLength)}
 		case "review_score_check":
 			return errors.New("invalid score")
 		}
@@ -352,14 +998,41 @@ var ErrReviewNotFound = errors.New("product review not found")
 
 // UpdateProductReview for a given product.
 func (db DB) UpdateProductReview(ctx context.Context, params inventory.UpdateProductReviewParams) error {
-	const sql = `UPDATE "review" SET
-	"title" = COALESCE($1, "title"),
-	"score" = COALESCE($2, "score"),
-	"description" = COALESCE($3, "description"),
-	"modified_at" = now()
-	WHERE id = $4`
-
-	switch ct, err := db.conn(ctx).Exec(ctx, sql, params.Title, params.Score, params.Description, params.ID); {
+	// If params.Attachments is set, the existing attachments are replaced in the same statement.
+	const sql = `
+	WITH u AS (
+		UPDATE "review" SET
+		"title" = COALESCE($1, "title"),
+		"score" = COALESCE($2, "score"),
+		"description" = COALESCE($3, "description"),
+		"language" = COALESCE($4, "language"),
+		"flagged" = "flagged" OR $5,
+		"sentiment" = COALESCE($6, "sentiment"),
+		"modified_at" = now()
+		WHERE id = $7
+		RETURNING "id"
+	), d AS (
+		DELETE FROM "review_attachment" WHERE $8 AND "review_id" IN (SELECT "id" FROM u)
+	), i AS (
+		INSERT INTO "review_attachment" ("review_id", "url", "content_type")
+		SELECT u."id", a."url", a."content_type"
+		FROM u, unnest($9::text[], $10::text[]) WITH ORDINALITY AS a("url", "content_type", "n")
+		WHERE $8
+		ORDER BY a."n"
+	)
+	SELECT COUNT(*) FROM u`
+
+	var (
+		replaceAttachments = params.Attachments != nil
+		urls, contentTypes []string
+		updated            int
+	)
+	if replaceAttachments {
+		urls, contentTypes = attachmentColumns(*params.Attachments)
+	}
+	switch err := db.conn(ctx).QueryRow(ctx, sql,
+		params.Title, params.Score, params.Description, params.Language, params.Flagged, params.Sentiment, params.ID,
+		replaceAttachments, urls, contentTypes).Scan(&updated); {
 	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
 		return err
 	case err != nil:
@@ -367,11 +1040,10 @@ func (db DB) UpdateProductReview(ctx context.Context, params inventory.UpdatePro
 			return sqlErr
 		}
 		db.log.Error("cannot update review on database", slog.Any("error", err))
-		return errors.New("cannot update review on database")
+		return infraError("cannot update review on database", err)
+	case updated == 0:
+		return ErrReviewNotFound
 	default:
-		if ct.RowsAffected() == 0 {
-			return ErrReviewNotFound
-		}
 		return nil
 	}
 }
@@ -386,6 +1058,9 @@ type review struct {
 	Description string
 	CreatedAt   time.Time
 	ModifiedAt  time.Time
+	Language    string
+	Flagged     bool
+	Sentiment   *float64
 }
 
 func (r *review) dto() *inventory.ProductReview {
@@ -398,22 +1073,28 @@ func (r *review) dto() *inventory.ProductReview {
 		Description: r.Description,
 		CreatedAt:   r.CreatedAt,
 		ModifiedAt:  r.ModifiedAt,
+		Language:    r.Language,
+		Flagged:     r.Flagged,
+		Sentiment:   r.Sentiment,
 	}
 }
 
 // GetProductReview gets a specific review.
 func (db DB) GetProductReview(ctx context.Context, id string) (*inventory.ProductReview, error) {
 	// The following pgtools.Wildcard() call returns:
-	// "id","product_id","reviewer_id","title","description","score","created_at","modified_at"
-	var r review
-	sql := fmt.Sprintf(`SELECT %s FROM "review" WHERE id = $1 LIMIT 1`, pgtools.Wildcard(r)) // #nosec G201
-	rows, err := db.conn(ctx).Query(ctx, sql, id)
+	// "id","product_id","reviewer_id","score","title","description","created_at","modified_at","language","flagged","sentiment"
+	sql := fmt.Sprintf(`SELECT %s FROM "review" WHERE id = $1 LIMIT 1`, pgtools.Wildcard(review{})) // #nosec G201
+	db.explain(ctx, "GetProductReview", sql, id)
+	r, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (review, error) {
+		rows, err := conn.Query(ctx, sql, id)
+		if err != nil {
+			return review{}, err
+		}
+		return pgx.CollectOneRow(rows, pgx.RowToStructByName[review])
+	})
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return nil, err
 	}
-	if err == nil {
-		r, err = pgx.CollectOneRow(rows, pgx.RowToStructByPos[review])
-	}
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -421,69 +1102,112 @@ func (db DB) GetProductReview(ctx context.Context, id string) (*inventory.Produc
 		db.log.Error("cannot get product review from database",
 			slog.Any("id", id),
 			slog.Any("error", err))
-		return nil, errors.New("cannot get product review from database")
+		return nil, infraError("cannot get product review from database", err)
 	}
-	return r.dto(), nil
+	dto := r.dto()
+	attachments, err := db.reviewAttachments(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	dto.Attachments = attachments[id]
+	return dto, nil
 }
 
-// GetProductReviews gets reviews for a given product or from a given user.
-func (db DB) GetProductReviews(ctx context.Context, params inventory.ProductReviewsParams) (*inventory.ProductReviewsResponse, error) {
-	var (
-		args  []any
-		where []string
-	)
+// CountReviewerReviews returns how many reviews a reviewer created since the given time.
+// It reads from the primary, as a replica lagging behind would let reviewers exceed their quota.
+func (db DB) CountReviewerReviews(ctx context.Context, reviewerID string, since time.Time) (int, error) {
+	const sql = `SELECT COUNT(*) FROM "review" WHERE "reviewer_id" = ANY($1) AND "created_at" >= $2`
+	var n int
+	switch err := db.conn(ctx).QueryRow(ctx, sql, db.reviewerPseudonymizer.candidates(reviewerID), since).Scan(&n); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return 0, err
+	case err != nil:
+		db.log.Error("cannot count reviewer reviews on database", slog.Any("error", err))
+		return 0, infraError("cannot count reviewer reviews on database", err)
+	}
+	return n, nil
+}
+
+// productReviewsConditions returns the conditions of the reviews matching the parameters.
+func (db DB) productReviewsConditions(params inventory.ProductReviewsParams) conditions {
+	var where conditions
 	if params.ProductID != "" {
-		args = append(args, params.ProductID)
-		where = append(where, fmt.Sprintf(`"product_id" = $%d`, len(args)))
+		where.add(`"product_id" = ?`, params.ProductID)
 	}
 	if params.ReviewerID != "" {
-		args = append(args, params.ReviewerID)
-		where = append(where, fmt.Sprintf(`"reviewer_id" = $%d`, len(args)))
+		where.add(`"reviewer_id" = ANY(?)`, db.reviewerPseudonymizer.candidates(params.ReviewerID))
+	}
+	if params.Language != "" {
+		where.add(`"language" = ?`, params.Language)
 	}
-	sql := fmt.Sprintf(`SELECT %s FROM "review"`, pgtools.Wildcard(review{})) // #nosec G201
-	sqlTotal := `SELECT COUNT(*) AS total FROM "review"`
-	if len(where) > 0 {
-		w := " WHERE " + strings.Join(where, " AND ") // #nosec G202
-		sql += w
-		sqlTotal += w
+	if params.MinSentiment != nil {
+		where.add(`"sentiment" >= ?`, *params.MinSentiment)
 	}
+	if params.MaxSentiment != nil {
+		where.add(`"sentiment" <= ?`, *params.MaxSentiment)
+	}
+	return where
+}
+
+// productReviewsQuery returns the query of the page of reviews matching the where conditions.
+func productReviewsQuery(params inventory.ProductReviewsParams, where conditions) *query {
+	q := newQuery(fmt.Sprintf(`SELECT %s FROM "review"`, pgtools.Wildcard(review{}))).where(where) // #nosec G201
+	switch params.OrderBy {
+	case inventory.ReviewsByMostPositive:
+		q.append(` ORDER BY "sentiment" DESC NULLS LAST, "created_at" DESC`)
+	case inventory.ReviewsByMostNegative:
+		q.append(` ORDER BY "sentiment" ASC NULLS LAST, "created_at" DESC`)
+	default:
+		q.append(` ORDER BY "created_at" DESC`)
+	}
+	return q.page(params.Pagination.Limit, params.Pagination.Offset)
+}
 
+// GetProductReviews gets reviews for a given product or from a given user.
+func (db DB) GetProductReviews(ctx context.Context, params inventory.ProductReviewsParams) (*inventory.ProductReviewsResponse, error) {
+	where := db.productReviewsConditions(params)
 	resp := &inventory.ProductReviewsResponse{
 		Reviews: []*inventory.ProductReview{},
 	}
-	err := db.conn(ctx).QueryRow(ctx, sqlTotal, args...).Scan(&resp.Total)
+	total, estimated, err := db.count(ctx, "GetProductReviewsCount", "review", where, params.EstimateTotal)
 	if err == context.Canceled || err == context.DeadlineExceeded {
 		return nil, err
 	}
 	if err != nil {
 		db.log.Error("cannot get reviews count from the database", slog.Any("error", err))
-		return nil, errors.New("cannot get reviews")
+		return nil, infraError("cannot get reviews", err)
 	}
+	resp.Total, resp.TotalIsEstimate = total, estimated
 
-	// Once the count query was made, add pagination args and query the results of the current page.
-	sql += ` ORDER BY "created_at" DESC`
-	if params.Pagination.Limit != 0 {
-		args = append(args, params.Pagination.Limit)
-		sql += fmt.Sprintf(` LIMIT $%d`, len(args))
-	}
-	if params.Pagination.Offset != 0 {
-		args = append(args, params.Pagination.Offset)
-		sql += fmt.Sprintf(` OFFSET $%d`, len(args))
-	}
-	rows, err := db.conn(ctx).Query(ctx, sql, args...)
+	q := productReviewsQuery(params, where)
+	sql, args := q.String(), q.args
+	db.explain(ctx, "GetProductReviews", sql, args...)
+	reviews, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]review, error) {
+		rows, err := conn.Query(ctx, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[review])
+	})
 	if err == context.Canceled || err == context.DeadlineExceeded {
 		return nil, err
 	}
-	var reviews []review
-	if err == nil {
-		reviews, err = pgx.CollectRows(rows, pgx.RowToStructByPos[review])
-	}
 	if err != nil {
 		db.log.Error("cannot get reviews from database", slog.Any("error", err))
-		return nil, errors.New("cannot get reviews")
+		return nil, infraError("cannot get reviews", err)
 	}
+	ids := make([]string, 0, len(reviews))
 	for _, r := range reviews {
-		resp.Reviews = append(resp.Reviews, r.dto())
+		ids = append(ids, r.ID)
+	}
+	attachments, err := db.reviewAttachments(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reviews {
+		dto := r.dto()
+		dto.Attachments = attachments[r.ID]
+		resp.Reviews = append(resp.Reviews, dto)
 	}
 	return resp, nil
 }
@@ -498,7 +1222,110 @@ func (db DB) DeleteProductReview(ctx context.Context, id string) error {
 			slog.Any("id", id),
 			slog.Any("error", err),
 		)
-		return errors.New("cannot delete review from database")
+		return infraError("cannot delete review from database", err)
 	}
 	return nil
 }
+
+// PurgeReviewerData deletes or anonymizes all reviews of a reviewer, writing an audit record in the same transaction.
+func (db DB) PurgeReviewerData(ctx context.Context, params inventory.PurgeReviewerDataParams) (*inventory.PurgeReviewerDataResult, error) {
+	tx, err := db.begin(ctx)
+	var reviews int64
+	if err == nil {
+		defer func() {
+			if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback reviewer data purge", slog.Any("error", rerr))
+			}
+		}()
+		reviews, err = db.purgeReviewerData(ctx, tx, params)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot purge reviewer data from database", slog.Any("error", err))
+		return nil, infraError("cannot purge reviewer data from database", err)
+	}
+	return &inventory.PurgeReviewerDataResult{
+		Reviews: int(reviews),
+	}, nil
+}
+
+func (db DB) purgeReviewerData(ctx context.Context, tx pgx.Tx, params inventory.PurgeReviewerDataParams) (int64, error) {
+	var (
+		ct  pgconn.CommandTag
+		err error
+	)
+	if params.Anonymize {
+		ct, err = tx.Exec(ctx, `UPDATE "review" SET "reviewer_id" = $1, "modified_at" = now() WHERE "reviewer_id" = ANY($2)`,
+			inventory.AnonymousReviewerID, db.reviewerPseudonymizer.candidates(params.ReviewerID))
+	} else {
+		ct, err = tx.Exec(ctx, `DELETE FROM "review" WHERE "reviewer_id" = ANY($1)`, db.reviewerPseudonymizer.candidates(params.ReviewerID))
+	}
+	if err != nil {
+		return 0, err
+	}
+	// The optional review_history table would otherwise keep the purged data.
+	if err := purgeReviewHistory(ctx, tx, db.reviewerPseudonymizer.candidates(params.ReviewerID)); err != nil {
+		return 0, err
+	}
+	// The audit record must not keep the raw reviewer ID of the erased reviewer.
+	if err := audit(ctx, tx, "purge_reviewer_data", db.reviewerPseudonymizer.digest(params.ReviewerID), map[string]any{
+		"reviews":   ct.RowsAffected(),
+		"anonymize": params.Anonymize,
+	}); err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
+
+// infraError with a message safe to show to clients, wrapping the database error causing it.
+// Only transient errors, which might not happen again if retried, are returned as *inventory.InfrastructureError.
+func infraError(message string, err error) error {
+	if transient(err) {
+		return &inventory.InfrastructureError{Message: message, Err: err}
+	}
+	return &dbError{message: message, err: err}
+}
+
+// dbError is an unexpected database error, such as a syntax error or an unexpected constraint violation.
+// Retrying won't fix it.
+type dbError struct {
+	message string
+	err     error
+}
+
+func (e *dbError) Error() string {
+	return e.message
+}
+
+func (e *dbError) Unwrap() error {
+	return e.err
+}
+
+// transient returns whether the database error is due to the database being unavailable or overloaded,
+// or to a conflict with concurrent transactions, so retrying might succeed.
+func transient(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgerrcode.IsConnectionException(pgErr.Code), // 08xxx
+			pgerrcode.IsInsufficientResources(pgErr.Code), // 53xxx
+			pgErr.Code == pgerrcode.AdminShutdown,
+			pgErr.Code == pgerrcode.CrashShutdown,
+			pgErr.Code == pgerrcode.CannotConnectNow,
+			pgErr.Code == pgerrcode.QueryCanceled, // Such as by statement_timeout.
+			pgErr.Code == pgerrcode.SerializationFailure,
+			pgErr.Code == pgerrcode.DeadlockDetected:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return pgconn.Timeout(err) || pgconn.SafeToRetry(err) ||
+		errors.As(err, new(*pgconn.ConnectError)) || errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
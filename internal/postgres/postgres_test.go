@@ -2,10 +2,16 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"log/slog"
+	"math"
+	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +19,8 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/henvic/pgtools/sqltest"
 	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 var force = flag.Bool("force", false, "Force cleaning the database before starting")
@@ -64,6 +72,32 @@ func TestTransactionContextCanceled(t *testing.T) {
 	}
 }
 
+func TestTransactionContextReadOnly(t *testing.T) {
+	t.Parallel()
+
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default(), WithReadOnly())
+
+	ctx, err := db.TransactionContext(context.Background())
+	if err != nil {
+		t.Fatalf("cannot create transaction context: %v", err)
+	}
+	defer db.Rollback(ctx)
+
+	_, err = db.CreateProduct(ctx, inventory.CreateProductParams{
+		ID:          "product",
+		Name:        "Product",
+		Description: "A product",
+	})
+	if want := "cannot create product on database"; err == nil || err.Error() != want {
+		t.Errorf("DB.CreateProduct() error = %v, want %v", err, want)
+	}
+}
+
 func TestCommitNoTransaction(t *testing.T) {
 	t.Parallel()
 
@@ -99,13 +133,19 @@ func TestWithAcquire(t *testing.T) {
 	defer db.Release(dbCtx)
 
 	// Check if we can acquire a connection only for a given context.
-	defer func() {
-		want := "context already has a connection acquired"
-		if r := recover(); r != want {
-			t.Errorf("expected panic %v, got %v instead", want, r)
-		}
-	}()
-	db.WithAcquire(dbCtx)
+	if _, err := db.WithAcquire(dbCtx); !errors.Is(err, ErrAlreadyAcquired) {
+		t.Errorf("expected error %v, got %v instead", ErrAlreadyAcquired, err)
+	}
+
+	// A transaction holds a connection too.
+	txCtx, err := db.TransactionContext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected DB.TransactionContext() error = %v", err)
+	}
+	defer db.Rollback(txCtx) // #nosec G104
+	if _, err := db.WithAcquire(txCtx); !errors.Is(err, ErrAlreadyAcquired) {
+		t.Errorf("expected error %v, got %v instead", ErrAlreadyAcquired, err)
+	}
 }
 
 func TestWithAcquireClosedPool(t *testing.T) {
@@ -143,6 +183,7 @@ func TestCreateProduct(t *testing.T) {
 		name    string
 		args    args
 		want    *inventory.Product
+		exists  bool
 		wantErr string
 	}{
 		{
@@ -161,8 +202,10 @@ func TestCreateProduct(t *testing.T) {
 				Name:        "A name",
 				Description: "A description",
 				Price:       14,
+				Status:      inventory.ProductStatusActive,
 				CreatedAt:   time.Now(),
 				ModifiedAt:  time.Now(),
+				Slug:        "a-name",
 			},
 		},
 		{
@@ -181,8 +224,33 @@ func TestCreateProduct(t *testing.T) {
 				Name:        "Earth",
 				Description: "Universe",
 				Price:       10,
+				Status:      inventory.ProductStatusActive,
+				CreatedAt:   time.Now(),
+				ModifiedAt:  time.Now(),
+				Slug:        "earth",
+			},
+		},
+		{
+			name: "draft",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.CreateProductParams{
+					ID:          "Draft",
+					Name:        "A draft",
+					Description: "Not ready yet",
+					Price:       12,
+					Status:      inventory.ProductStatusDraft,
+				},
+			},
+			want: &inventory.Product{
+				ID:          "Draft",
+				Name:        "A draft",
+				Description: "Not ready yet",
+				Price:       12,
+				Status:      inventory.ProductStatusDraft,
 				CreatedAt:   time.Now(),
 				ModifiedAt:  time.Now(),
+				Slug:        "a-draft",
 			},
 		},
 		{
@@ -191,12 +259,22 @@ func TestCreateProduct(t *testing.T) {
 				ctx: context.Background(),
 				params: inventory.CreateProductParams{
 					ID:          "World",
-					Name:        "Earth",
-					Description: "Universe",
-					Price:       10,
+					Name:        "Mars",
+					Description: "Red planet",
+					Price:       20,
 				},
 			},
-			wantErr: "product already exists",
+			want: &inventory.Product{
+				ID:          "World",
+				Name:        "Earth",
+				Description: "Universe",
+				Price:       10,
+				Status:      inventory.ProductStatusActive,
+				CreatedAt:   time.Now(),
+				ModifiedAt:  time.Now(),
+				Slug:        "earth",
+			},
+			exists: true,
 		},
 		{
 			name: "create_product_check_empty_id",
@@ -254,13 +332,19 @@ func TestCreateProduct(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := db.CreateProduct(tt.args.ctx, tt.args.params)
+			res, err := db.CreateProduct(tt.args.ctx, tt.args.params)
 			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
 				t.Errorf("DB.CreateProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err != nil {
 				return
 			}
+			if res.Created == tt.exists {
+				t.Errorf("DB.CreateProduct() returned Created = %v, want %v", res.Created, !tt.exists)
+			}
+			if !cmp.Equal(tt.want, res.Product, cmpopts.EquateApproxTime(time.Minute)) {
+				t.Errorf("value returned by DB.CreateProduct() doesn't match: %v", cmp.Diff(tt.want, res.Product))
+			}
 			// Reusing GetProduct to check if the product was created successfully.
 			got, err := db.GetProduct(tt.args.ctx, tt.args.params.ID)
 			if err != nil {
@@ -329,6 +413,8 @@ func TestUpdateProduct(t *testing.T) {
 				Name:        "A new name",
 				Description: "This is the original description",
 				Price:       250,
+				Status:      inventory.ProductStatusActive,
+				Slug:        "a-new-name",
 			},
 		},
 		{
@@ -345,6 +431,8 @@ func TestUpdateProduct(t *testing.T) {
 				Name:        "A new name",
 				Description: "A new description",
 				Price:       250,
+				Status:      inventory.ProductStatusActive,
+				Slug:        "a-new-name",
 			},
 		},
 		{
@@ -363,6 +451,8 @@ func TestUpdateProduct(t *testing.T) {
 				Name:        "Even another name",
 				Description: "yet another description",
 				Price:       400,
+				Status:      inventory.ProductStatusActive,
+				Slug:        "even-another-name",
 			},
 		},
 		{
@@ -415,13 +505,33 @@ func TestUpdateProduct(t *testing.T) {
 				Name:        "Is your SQL UPDATE call correct?",
 				Description: "Only the price of this one should be modified",
 				Price:       97,
+				Status:      inventory.ProductStatusActive,
+				Slug:        "is-your-sql-update-call-correct",
+			},
+		},
+		{
+			name: "another_product_status_change",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.UpdateProductParams{
+					ID:     "another",
+					Status: ptr(inventory.ProductStatusDiscontinued),
+				},
+			},
+			want: &inventory.Product{
+				ID:          "another",
+				Name:        "Is your SQL UPDATE call correct?",
+				Description: "Only the price of this one should be modified",
+				Price:       97,
+				Status:      inventory.ProductStatusDiscontinued,
+				Slug:        "is-your-sql-update-call-correct",
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := db.UpdateProduct(tt.args.ctx, tt.args.params)
+			updated, err := db.UpdateProduct(tt.args.ctx, tt.args.params)
 			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
 				t.Errorf("DB.UpdateProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -432,6 +542,10 @@ func TestUpdateProduct(t *testing.T) {
 			if err != nil {
 				t.Errorf("DB.GetProduct() error = %v", err)
 			}
+			// The product returned by UpdateProduct must match the persisted one.
+			if !cmp.Equal(updated, got) {
+				t.Errorf("value returned by DB.UpdateProduct() doesn't match: %v", cmp.Diff(updated, got))
+			}
 			if got.CreatedAt.IsZero() {
 				t.Error("DB.GetProduct() returned CreatedAt should not be zero")
 			}
@@ -458,6 +572,8 @@ func TestUpdateProduct(t *testing.T) {
 		Name:        "Do not change",
 		Description: "This should remain unchanged",
 		Price:       123,
+		Status:      inventory.ProductStatusActive,
+		Slug:        "do-not-change",
 	}
 	// Ignore or CreatedAt and ModifiedAt before comparing structs.
 	if !cmp.Equal(want, got, cmpopts.IgnoreFields(inventory.Product{}, "CreatedAt", "ModifiedAt")) {
@@ -504,8 +620,10 @@ func TestGetProduct(t *testing.T) {
 				Name:        "A product name",
 				Description: "A great description",
 				Price:       10000,
+				Status:      inventory.ProductStatusActive,
 				CreatedAt:   time.Now(),
 				ModifiedAt:  time.Now(),
+				Slug:        "a-product-name",
 			},
 		},
 		{
@@ -548,6 +666,228 @@ func TestGetProduct(t *testing.T) {
 	}
 }
 
+func TestGetProducts(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "book",
+			Name:        "Book",
+			Description: "A book",
+			Price:       20,
+		},
+		{
+			ID:          "lamp",
+			Name:        "Lamp",
+			Description: "A lamp",
+			Price:       45,
+		},
+		{
+			ID:          "chair",
+			Name:        "Chair",
+			Description: "A chair",
+			Price:       80,
+		},
+	})
+	got, err := db.GetProducts(context.Background(), []string{"lamp", "book", "not_found"})
+	if err != nil {
+		t.Fatalf("DB.GetProducts() error = %v", err)
+	}
+	var ids []string
+	for _, p := range got {
+		ids = append(ids, p.ID)
+	}
+	if want := []string{"book", "lamp"}; !cmp.Equal(want, ids, cmpopts.SortSlices(func(a, b string) bool { return a < b })) {
+		t.Errorf("DB.GetProducts() = %v, want %v", ids, want)
+	}
+}
+
+func TestGetProductAt(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	clock := func() time.Time {
+		t.Helper()
+		var now time.Time
+		if err := pool.QueryRow(context.Background(), "SELECT clock_timestamp()").Scan(&now); err != nil {
+			t.Fatalf("cannot get database clock: %v", err)
+		}
+		return now
+	}
+
+	beforeCreate := clock()
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "product",
+			Name:        "A product name",
+			Description: "A great description",
+			Price:       10000,
+		},
+	})
+	afterCreate := clock()
+	if _, err := db.UpdateProduct(context.Background(), inventory.UpdateProductParams{
+		ID:    "product",
+		Name:  ptr("New product name"),
+		Price: ptr(12000),
+	}); err != nil {
+		t.Fatalf("DB.UpdateProduct() error = %v", err)
+	}
+	afterUpdate := clock()
+	if err := db.DeleteProduct(context.Background(), inventory.DeleteProductParams{ID: "product"}); err != nil {
+		t.Fatalf("DB.DeleteProduct() error = %v", err)
+	}
+	afterDelete := clock()
+
+	// Products changed before the audit_log existed have no recorded history.
+	if _, err := pool.Exec(context.Background(), `INSERT INTO product ("id", "name", "description", "price", "slug", "created_at", "modified_at")
+	VALUES ('legacy', 'Legacy', 'Created before history', 100, 'legacy', $1, $2)`, beforeCreate.Add(-time.Hour), afterCreate); err != nil {
+		t.Fatalf("cannot create legacy product: %v", err)
+	}
+
+	type args struct {
+		ctx context.Context
+		id  string
+		at  time.Time
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *inventory.Product
+		wantErr string
+	}{
+		{
+			name: "before_create",
+			args: args{
+				ctx: context.Background(),
+				id:  "product",
+				at:  beforeCreate,
+			},
+			want: nil,
+		},
+		{
+			name: "created",
+			args: args{
+				ctx: context.Background(),
+				id:  "product",
+				at:  afterCreate,
+			},
+			want: &inventory.Product{
+				ID:          "product",
+				Name:        "A product name",
+				Description: "A great description",
+				Price:       10000,
+				Status:      inventory.ProductStatusActive,
+				CreatedAt:   time.Now(),
+				ModifiedAt:  time.Now(),
+				Slug:        "a-product-name",
+			},
+		},
+		{
+			name: "updated",
+			args: args{
+				ctx: context.Background(),
+				id:  "product",
+				at:  afterUpdate,
+			},
+			want: &inventory.Product{
+				ID:          "product",
+				Name:        "New product name",
+				Description: "A great description",
+				Price:       12000,
+				Status:      inventory.ProductStatusActive,
+				CreatedAt:   time.Now(),
+				ModifiedAt:  time.Now(),
+				Slug:        "new-product-name",
+			},
+		},
+		{
+			name: "deleted",
+			args: args{
+				ctx: context.Background(),
+				id:  "product",
+				at:  afterDelete,
+			},
+			want: nil,
+		},
+		{
+			name: "not_found",
+			args: args{
+				ctx: context.Background(),
+				id:  "not_found",
+				at:  afterDelete,
+			},
+			want: nil,
+		},
+		{
+			name: "legacy",
+			args: args{
+				ctx: context.Background(),
+				id:  "legacy",
+				at:  afterDelete,
+			},
+			want: &inventory.Product{
+				ID:          "legacy",
+				Name:        "Legacy",
+				Description: "Created before history",
+				Price:       100,
+				Status:      inventory.ProductStatusActive,
+				CreatedAt:   time.Now().Add(-time.Hour),
+				ModifiedAt:  time.Now(),
+				Slug:        "legacy",
+			},
+		},
+		{
+			name: "legacy_no_history",
+			args: args{
+				ctx: context.Background(),
+				id:  "legacy",
+				at:  beforeCreate,
+			},
+			wantErr: "product history not available",
+		},
+		{
+			name: "canceled_ctx",
+			args: args{
+				ctx: canceledContext(),
+			},
+			wantErr: "context canceled",
+		},
+		{
+			name: "deadline_exceeded_ctx",
+			args: args{
+				ctx: deadlineExceededContext(),
+			},
+			wantErr: "context deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.GetProductAt(tt.args.ctx, tt.args.id, tt.args.at)
+			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
+				t.Errorf("DB.GetProductAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !cmp.Equal(tt.want, got, cmpopts.EquateApproxTime(time.Minute)) {
+				t.Errorf("value returned by DB.GetProductAt() doesn't match: %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
 func TestSearchProducts(t *testing.T) {
 	t.Parallel()
 	migration := sqltest.New(t, sqltest.Options{
@@ -617,8 +957,10 @@ func TestSearchProducts(t *testing.T) {
 						Name:        "plain desk (home)",
 						Description: "A plain desk",
 						Price:       140,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "plain-desk-home",
 					},
 				},
 				Total: 1,
@@ -655,16 +997,20 @@ func TestSearchProducts(t *testing.T) {
 						Name:        "dining home table",
 						Description: "dining table",
 						Price:       120,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "dining-home-table",
 					},
 					{
 						ID:          "desk",
 						Name:        "plain desk (home)",
 						Description: "A plain desk",
 						Price:       140,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "plain-desk-home",
 					},
 				},
 				Total: 2,
@@ -690,8 +1036,10 @@ func TestSearchProducts(t *testing.T) {
 						Name:        "plain desk (home)",
 						Description: "A plain desk",
 						Price:       140,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "plain-desk-home",
 					},
 				},
 				Total: 2,
@@ -710,12 +1058,103 @@ func TestSearchProducts(t *testing.T) {
 			want: &inventory.SearchProductsResponse{
 				Items: []*inventory.Product{
 					{
-						ID:          "table",
-						Name:        "dining home table",
-						Description: "dining table",
-						Price:       120,
+						ID:          "table",
+						Name:        "dining home table",
+						Description: "dining table",
+						Price:       120,
+						Status:      inventory.ProductStatusActive,
+						CreatedAt:   time.Now(),
+						ModifiedAt:  time.Now(),
+						Slug:        "dining-home-table",
+					},
+				},
+				Total: 1,
+			},
+			wantErr: "",
+		},
+		{
+			name: "text",
+			args: args{
+				ctx: dbCtx,
+				params: inventory.SearchProductsParams{
+					Text: "small -table",
+				},
+			},
+			want: &inventory.SearchProductsResponse{
+				Items: []*inventory.Product{
+					{
+						ID:          "bed",
+						Name:        "bed",
+						Description: "small bed",
+						Price:       100,
+						Status:      inventory.ProductStatusActive,
+						CreatedAt:   time.Now(),
+						ModifiedAt:  time.Now(),
+						Slug:        "bed",
+					},
+				},
+				Total: 1,
+			},
+			wantErr: "",
+		},
+		{
+			name: "text_price_bands",
+			args: args{
+				ctx: dbCtx,
+				params: inventory.SearchProductsParams{
+					Text:            "desk or chair",
+					CountPriceBands: true,
+				},
+			},
+			want: &inventory.SearchProductsResponse{
+				Items: []*inventory.Product{
+					{
+						ID:          "desk",
+						Name:        "plain desk (home)",
+						Description: "A plain desk",
+						Price:       140,
+						Status:      inventory.ProductStatusActive,
+						CreatedAt:   time.Now(),
+						ModifiedAt:  time.Now(),
+						Slug:        "plain-desk-home",
+					},
+					{
+						ID:          "chair",
+						Name:        "office chair",
+						Description: "Office chair",
+						Price:       80,
+						Status:      inventory.ProductStatusActive,
+						CreatedAt:   time.Now(),
+						ModifiedAt:  time.Now(),
+						Slug:        "office-chair",
+					},
+				},
+				Total:      2,
+				PriceBands: []inventory.PriceBand{inventory.NewPriceBand(0, 2)},
+			},
+			wantErr: "",
+		},
+		{
+			name: "estimate_total_small",
+			args: args{
+				ctx: dbCtx,
+				params: inventory.SearchProductsParams{
+					Text:          "small -table",
+					EstimateTotal: true,
+				},
+			},
+			// Small totals are counted rather than estimated.
+			want: &inventory.SearchProductsResponse{
+				Items: []*inventory.Product{
+					{
+						ID:          "bed",
+						Name:        "bed",
+						Description: "small bed",
+						Price:       100,
+						Status:      inventory.ProductStatusActive,
 						CreatedAt:   time.Now(),
 						ModifiedAt:  time.Now(),
+						Slug:        "bed",
 					},
 				},
 				Total: 1,
@@ -767,6 +1206,29 @@ func TestSearchProducts(t *testing.T) {
 	}
 }
 
+func TestEstimateRows(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	var where conditions
+	where.add(`"price" >= ?`, 100)
+	n, err := db.estimateRows(context.Background(), "product", where)
+	if err != nil {
+		t.Fatalf("DB.estimateRows() error = %v", err)
+	}
+	if n < 0 {
+		t.Errorf("DB.estimateRows() = %d, want a non-negative estimate", n)
+	}
+	if _, err := db.estimateRows(canceledContext(), "product", where); !errors.Is(err, context.Canceled) {
+		t.Errorf("DB.estimateRows() error = %v, want context canceled", err)
+	}
+}
+
 func TestDeleteProduct(t *testing.T) {
 	t.Parallel()
 	migration := sqltest.New(t, sqltest.Options{
@@ -788,11 +1250,29 @@ func TestDeleteProduct(t *testing.T) {
 			Name:  "Do not erase",
 			Price: 123,
 		},
+		{
+			ID:          "reviewed",
+			Name:        "Reviewed product",
+			Description: "Product with reviews",
+			Price:       50,
+		},
+	})
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "review",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "reviewed",
+				ReviewerID:  "reviewer",
+				Score:       4,
+				Title:       "title",
+				Description: "review",
+			},
+		},
 	})
 
 	type args struct {
-		ctx context.Context
-		id  string
+		ctx    context.Context
+		params inventory.DeleteProductParams
 	}
 	tests := []struct {
 		name    string
@@ -802,8 +1282,8 @@ func TestDeleteProduct(t *testing.T) {
 		{
 			name: "product",
 			args: args{
-				ctx: context.Background(),
-				id:  "product",
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: "product"},
 			},
 			wantErr: "",
 		},
@@ -811,8 +1291,8 @@ func TestDeleteProduct(t *testing.T) {
 		{
 			name: "product_already_deleted",
 			args: args{
-				ctx: context.Background(),
-				id:  "product",
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: "product"},
 			},
 			wantErr: "",
 		},
@@ -820,8 +1300,23 @@ func TestDeleteProduct(t *testing.T) {
 		{
 			name: "not_found",
 			args: args{
-				ctx: context.Background(),
-				id:  "xyz",
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: "xyz"},
+			},
+		},
+		{
+			name: "has_reviews",
+			args: args{
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: "reviewed"},
+			},
+			wantErr: "product has 1 reviews",
+		},
+		{
+			name: "force",
+			args: args{
+				ctx:    context.Background(),
+				params: inventory.DeleteProductParams{ID: "reviewed", Force: true},
 			},
 		},
 		{
@@ -842,14 +1337,14 @@ func TestDeleteProduct(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := db.DeleteProduct(tt.args.ctx, tt.args.id)
+			err := db.DeleteProduct(tt.args.ctx, tt.args.params)
 			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
 				t.Errorf("DB.DeleteProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			if err != nil {
 				return
 			}
-			got, err := db.GetProduct(context.Background(), tt.args.id)
+			got, err := db.GetProduct(context.Background(), tt.args.params.ID)
 			if err != nil {
 				t.Errorf("DB.GetProduct() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -954,7 +1449,7 @@ func TestCreateProductReview(t *testing.T) {
 					},
 				},
 			},
-			wantErr: "invalid title",
+			wantErr: "missing review title",
 		},
 		{
 			name: "invalid_score",
@@ -973,6 +1468,40 @@ func TestCreateProductReview(t *testing.T) {
 			},
 			wantErr: "invalid score",
 		},
+		{
+			name: "title_too_long",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.CreateProductReviewDBParams{
+					ID: "xyz",
+					CreateProductReviewParams: inventory.CreateProductReviewParams{
+						ProductID:   "product",
+						ReviewerID:  "reviewer",
+						Score:       5,
+						Title:       strings.Repeat("é", inventory.MaxReviewTitleLength+1),
+						Description: "review",
+					},
+				},
+			},
+			wantErr: "review title is longer than 200 characters",
+		},
+		{
+			name: "description_too_long",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.CreateProductReviewDBParams{
+					ID: "xyz",
+					CreateProductReviewParams: inventory.CreateProductReviewParams{
+						ProductID:   "product",
+						ReviewerID:  "reviewer",
+						Score:       5,
+						Title:       "title",
+						Description: strings.Repeat("a", inventory.MaxReviewDescriptionLength+1),
+					},
+				},
+			},
+			wantErr: "review description is longer than 10000 characters",
+		},
 		{
 			name: "review1_already_exists",
 			args: args{
@@ -1187,7 +1716,7 @@ func TestUpdateProductReview(t *testing.T) {
 					Title: ptr(""),
 				},
 			},
-			wantErr: "invalid title",
+			wantErr: "missing review title",
 		},
 		{
 			name: "update_desc",
@@ -1302,6 +1831,53 @@ func TestUpdateProductReview(t *testing.T) {
 	}
 }
 
+func TestProductReviewFlagged(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "product",
+			Name:        "A product name",
+			Description: "A great description",
+			Price:       10000,
+		},
+	})
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "review",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "product",
+				ReviewerID:  "reviewer",
+				Score:       4,
+				Title:       "Buy it",
+				Description: "Cheaper at http://example.com",
+			},
+			Flagged: true,
+		},
+	})
+
+	// A flagged review stays flagged after an update considered allowed.
+	if err := db.UpdateProductReview(context.Background(), inventory.UpdateProductReviewParams{
+		ID:          "review",
+		Description: ptr("Cheaper elsewhere"),
+	}); err != nil {
+		t.Fatalf("DB.UpdateProductReview() error = %v", err)
+	}
+	got, err := db.GetProductReview(context.Background(), "review")
+	if err != nil {
+		t.Fatalf("DB.GetProductReview() error = %v", err)
+	}
+	if !got.Flagged {
+		t.Errorf("DB.GetProductReview() returned review not flagged, want flagged")
+	}
+}
+
 func TestGetProductReview(t *testing.T) {
 	t.Parallel()
 	migration := sqltest.New(t, sqltest.Options{
@@ -1426,6 +2002,118 @@ func TestGetProductReview(t *testing.T) {
 	}
 }
 
+func TestCountReviewerReviews(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "product",
+			Name:        "A product name",
+			Description: "A great description",
+			Price:       10000,
+		},
+	})
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "review1",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "product",
+				ReviewerID:  "reviewer",
+				Score:       4,
+				Title:       "Good",
+				Description: "A good product",
+			},
+		},
+		{
+			ID: "review2",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "product",
+				ReviewerID:  "reviewer",
+				Score:       5,
+				Title:       "Great",
+				Description: "A great product",
+			},
+		},
+		{
+			ID: "review3",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "product",
+				ReviewerID:  "another",
+				Score:       3,
+				Title:       "Ok",
+				Description: "An ok product",
+			},
+		},
+	})
+	if _, err := pool.Exec(context.Background(), `UPDATE "review" SET "created_at" = now() - interval '2 hours' WHERE "id" = 'review1'`); err != nil {
+		t.Fatalf("cannot update review: %v", err)
+	}
+
+	type args struct {
+		ctx        context.Context
+		reviewerID string
+		since      time.Time
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    int
+		wantErr string
+	}{
+		{
+			name: "last_hour",
+			args: args{
+				ctx:        context.Background(),
+				reviewerID: "reviewer",
+				since:      time.Now().Add(-time.Hour),
+			},
+			want: 1,
+		},
+		{
+			name: "last_day",
+			args: args{
+				ctx:        context.Background(),
+				reviewerID: "reviewer",
+				since:      time.Now().Add(-24 * time.Hour),
+			},
+			want: 2,
+		},
+		{
+			name: "not_found",
+			args: args{
+				ctx:        context.Background(),
+				reviewerID: "not_found",
+				since:      time.Now().Add(-24 * time.Hour),
+			},
+			want: 0,
+		},
+		{
+			name: "canceled_ctx",
+			args: args{
+				ctx: canceledContext(),
+			},
+			wantErr: "context canceled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.CountReviewerReviews(tt.args.ctx, tt.args.reviewerID, tt.args.since)
+			if err == nil && tt.wantErr != "" || err != nil && tt.wantErr != err.Error() {
+				t.Errorf("DB.CountReviewerReviews() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DB.CountReviewerReviews() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetProductReviews(t *testing.T) {
 	t.Parallel()
 	migration := sqltest.New(t, sqltest.Options{
@@ -1470,6 +2158,7 @@ func TestGetProductReviews(t *testing.T) {
 				Score:       5,
 				Title:       "little title",
 				Description: "a desc",
+				Language:    "en",
 			},
 		},
 		{
@@ -1519,6 +2208,7 @@ func TestGetProductReviews(t *testing.T) {
 						Score:       5,
 						Title:       "little title",
 						Description: "a desc",
+						Language:    "en",
 					},
 				},
 				Total: 2,
@@ -1545,6 +2235,7 @@ func TestGetProductReviews(t *testing.T) {
 						Score:       5,
 						Title:       "little title",
 						Description: "a desc",
+						Language:    "en",
 					},
 				},
 				Total: 2,
@@ -1575,6 +2266,30 @@ func TestGetProductReviews(t *testing.T) {
 				Total: 2,
 			},
 		},
+		{
+			name: "language",
+			args: args{
+				ctx: context.Background(),
+				params: inventory.ProductReviewsParams{
+					ProductID: "greatproduct",
+					Language:  "en",
+				},
+			},
+			want: &inventory.ProductReviewsResponse{
+				Reviews: []*inventory.ProductReview{
+					{
+						ID:          "review2",
+						ProductID:   "greatproduct",
+						ReviewerID:  "reviewerid2",
+						Score:       5,
+						Title:       "little title",
+						Description: "a desc",
+						Language:    "en",
+					},
+				},
+				Total: 1,
+			},
+		},
 		{
 			name: "other",
 			args: args{
@@ -1766,3 +2481,126 @@ func TestDeleteProductReview(t *testing.T) {
 		t.Errorf(`"review" table should have 1 row, but got %d`, total)
 	}
 }
+
+func TestInfraError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		err   error
+		infra bool
+	}{
+		{"connection failure", &pgconn.PgError{Code: pgerrcode.ConnectionFailure}, true},
+		{"too many connections", &pgconn.PgError{Code: pgerrcode.TooManyConnections}, true},
+		{"admin shutdown", &pgconn.PgError{Code: pgerrcode.AdminShutdown}, true},
+		{"statement timeout", &pgconn.PgError{Code: pgerrcode.QueryCanceled}, true},
+		{"serialization failure", fmt.Errorf("cannot commit: %w", &pgconn.PgError{Code: pgerrcode.SerializationFailure}), true},
+		{"connection refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"syntax error", &pgconn.PgError{Code: pgerrcode.SyntaxError}, false},
+		{"undefined column", &pgconn.PgError{Code: pgerrcode.UndefinedColumn}, false},
+		{"unique violation", &pgconn.PgError{Code: pgerrcode.UniqueViolation}, false},
+		{"scan error", errors.New("can't scan into dest[0]"), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := infraError("cannot get product from database", tc.err)
+			if got := errors.As(err, new(*inventory.InfrastructureError)); got != tc.infra {
+				t.Errorf("infraError() is *inventory.InfrastructureError = %v, want %v", got, tc.infra)
+			}
+			if err.Error() != "cannot get product from database" {
+				t.Errorf("unexpected error message: %q", err.Error())
+			}
+			if !errors.Is(err, tc.err) {
+				t.Errorf("infraError() doesn't wrap %v", tc.err)
+			}
+		})
+	}
+}
+
+func TestReviewSentiment(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{ID: "desk", Name: "Desk", Description: "A desk", Price: 140},
+	})
+	sentiments := map[string]*float64{"positive": ptr(0.8), "negative": ptr(-0.5), "neutral": ptr(0.0), "unscored": nil}
+	for _, id := range []string{"positive", "negative", "neutral", "unscored"} {
+		createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+			{
+				ID: id,
+				CreateProductReviewParams: inventory.CreateProductReviewParams{
+					ProductID:   "desk",
+					ReviewerID:  "reviewer",
+					Score:       3,
+					Title:       id,
+					Description: id,
+				},
+				Sentiment: sentiments[id],
+			},
+		})
+	}
+	if err := db.UpdateProductReview(ctx, inventory.UpdateProductReviewParams{
+		ID:        "neutral",
+		Title:     ptr("updated"),
+		Sentiment: ptr(0.2),
+	}); err != nil {
+		t.Fatalf("DB.UpdateProductReview() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params inventory.ProductReviewsParams
+		want   []string
+		total  int
+	}{
+		{
+			name:   "most positive",
+			params: inventory.ProductReviewsParams{ProductID: "desk", OrderBy: inventory.ReviewsByMostPositive},
+			want:   []string{"positive", "neutral", "negative", "unscored"},
+			total:  4,
+		},
+		{
+			name:   "most negative",
+			params: inventory.ProductReviewsParams{ProductID: "desk", OrderBy: inventory.ReviewsByMostNegative},
+			want:   []string{"negative", "neutral", "positive", "unscored"},
+			total:  4,
+		},
+		{
+			name:   "range",
+			params: inventory.ProductReviewsParams{ProductID: "desk", MinSentiment: ptr(0.0), MaxSentiment: ptr(0.5)},
+			want:   []string{"neutral"},
+			total:  1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := db.GetProductReviews(ctx, tc.params)
+			if err != nil {
+				t.Fatalf("DB.GetProductReviews() error = %v", err)
+			}
+			var got []string
+			for _, r := range resp.Reviews {
+				got = append(got, r.ID)
+			}
+			if !cmp.Equal(tc.want, got) || resp.Total != tc.total {
+				t.Errorf("DB.GetProductReviews() = %v (total %d), want %v (total %d)", got, resp.Total, tc.want, tc.total)
+			}
+		})
+	}
+
+	stats, err := db.GetProductStats(ctx, "desk")
+	if err != nil {
+		t.Fatalf("DB.GetProductStats() error = %v", err)
+	}
+	if want := (0.8 - 0.5 + 0.2) / 3; stats.AverageSentiment == nil || math.Abs(*stats.AverageSentiment-want) > 1e-9 {
+		t.Errorf("DB.GetProductStats() average sentiment = %v, want %v", stats.AverageSentiment, want)
+	}
+}
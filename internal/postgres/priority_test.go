@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestBatchPool(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	ctx := context.Background()
+	pool := migration.Setup(ctx, "")
+	conf := pool.Config()
+	conf.MaxConns = 1
+	batchPool, err := pgxpool.NewWithConfig(ctx, conf)
+	if err != nil {
+		t.Fatalf("cannot create batch pool: %v", err)
+	}
+	defer batchPool.Close()
+	// Use the primary as if it were a replica, so batch reads skipping it can be told apart.
+	replica, err := pgxpool.NewWithConfig(ctx, pool.Config())
+	if err != nil {
+		t.Fatalf("cannot create replica pool: %v", err)
+	}
+	defer replica.Close()
+	db := NewDB(pool, slog.Default(), WithBatchPool(batchPool), WithReplicas(Replicas{
+		Pools: []*pgxpool.Pool{replica},
+	}))
+	batch := database.WithPriority(ctx, database.PriorityBatch)
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:    "product",
+			Name:  "Product",
+			Price: 100,
+		},
+	})
+	primaryAcquired, batchAcquired, replicaAcquired := pool.Stat().AcquireCount(), batchPool.Stat().AcquireCount(), replica.Stat().AcquireCount()
+
+	if _, err := db.GetProduct(batch, "product"); err != nil {
+		t.Fatalf("DB.GetProduct() error = %v", err)
+	}
+	dbCtx, err := db.WithAcquire(batch)
+	if err != nil {
+		t.Fatalf("DB.WithAcquire() error = %v", err)
+	}
+	db.Release(dbCtx)
+	if _, err := db.Verify(batch); err != nil {
+		t.Fatalf("DB.Verify() error = %v", err)
+	}
+	if got := batchPool.Stat().AcquireCount() - batchAcquired; got != 3 {
+		t.Errorf("batch pool acquired %d connections, want 3", got)
+	}
+	if pool.Stat().AcquireCount() != primaryAcquired || replica.Stat().AcquireCount() != replicaAcquired {
+		t.Error("batch work must not use the primary or the replica pools")
+	}
+
+	// Interactive work doesn't use the batch pool.
+	batchAcquired = batchPool.Stat().AcquireCount()
+	if _, err := db.GetProduct(ctx, "product"); err != nil {
+		t.Fatalf("DB.GetProduct() error = %v", err)
+	}
+	if _, err := db.Verify(ctx); err != nil {
+		t.Fatalf("DB.Verify() error = %v", err)
+	}
+	if batchPool.Stat().AcquireCount() != batchAcquired {
+		t.Error("interactive work must not use the batch pool")
+	}
+	if replica.Stat().AcquireCount() == replicaAcquired {
+		t.Error("interactive reads must use the replica pool")
+	}
+}
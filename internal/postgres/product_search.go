@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RefreshProductSearch refreshes the product_search materialized view.
+//
+// It uses REFRESH MATERIALIZED VIEW CONCURRENTLY, so SearchProducts can still read from the view
+// while it is being refreshed.
+func (db DB) RefreshProductSearch(ctx context.Context) error {
+	switch _, err := db.conn(ctx).Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY "product_search"`); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot refresh product search view", slog.Any("error", err))
+		return infraError("cannot refresh product search view", err)
+	}
+	return nil
+}
+
+// RefreshProductSearchEvery refreshes the product_search materialized view once right away,
+// and then periodically, until the context is canceled.
+// Each refresh is traced as a new root span, as it isn't part of any request.
+func (db DB) RefreshProductSearchEvery(ctx context.Context, interval time.Duration) {
+	db.refreshProductSearchTraced(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			db.refreshProductSearchTraced(ctx)
+		}
+	}
+}
+
+func (db DB) refreshProductSearchTraced(ctx context.Context) {
+	ctx, span := db.tracer.Start(ctx, "postgres.RefreshProductSearch", trace.WithNewRoot())
+	defer span.End()
+	start := time.Now()
+	if err := db.RefreshProductSearch(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	db.log.Debug("product search view refreshed", slog.Duration("duration", time.Since(start)))
+}
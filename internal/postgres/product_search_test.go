@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestSearchProductsView(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default(), WithSearchView())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "desk",
+			Name:        "plain desk (home)",
+			Description: "A plain desk",
+			Price:       140,
+		},
+		{
+			ID:          "table",
+			Name:        "dining home table",
+			Description: "dining table",
+			Price:       120,
+		},
+	})
+
+	params := inventory.SearchProductsParams{
+		QueryString: "home",
+		MaxPrice:    130,
+	}
+
+	// The materialized view is only updated once it is refreshed.
+	got, err := db.SearchProducts(context.Background(), params)
+	if err != nil {
+		t.Fatalf("DB.SearchProducts() error = %v", err)
+	}
+	if want := (&inventory.SearchProductsResponse{Items: []*inventory.Product{}}); !cmp.Equal(want, got) {
+		t.Errorf("value returned by DB.SearchProducts() before refresh doesn't match: %v", cmp.Diff(want, got))
+	}
+
+	if err := db.RefreshProductSearch(context.Background()); err != nil {
+		t.Fatalf("DB.RefreshProductSearch() error = %v", err)
+	}
+	got, err = db.SearchProducts(context.Background(), params)
+	if err != nil {
+		t.Fatalf("DB.SearchProducts() error = %v", err)
+	}
+	want := &inventory.SearchProductsResponse{
+		Items: []*inventory.Product{
+			{
+				ID:          "table",
+				Name:        "dining home table",
+				Description: "dining table",
+				Price:       120,
+				Status:      inventory.ProductStatusActive,
+				CreatedAt:   time.Now(),
+				ModifiedAt:  time.Now(),
+				Slug:        "dining-home-table",
+			},
+		},
+		Total: 1,
+	}
+	if !cmp.Equal(want, got, cmpopts.EquateApproxTime(time.Minute)) {
+		t.Errorf("value returned by DB.SearchProducts() doesn't match: %v", cmp.Diff(want, got))
+	}
+}
+
+func TestRefreshProductSearchCanceled(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default(), WithSearchView())
+
+	if err := db.RefreshProductSearch(canceledContext()); !errors.Is(err, context.Canceled) {
+		t.Errorf("DB.RefreshProductSearch() error = %v, wantErr %v", err, context.Canceled)
+	}
+}
+
+func TestRefreshProductSearchEvery(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default(), WithSearchView())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "desk",
+			Name:        "plain desk (home)",
+			Description: "A plain desk",
+			Price:       140,
+		},
+	})
+
+	// The view is refreshed when starting, rather than only after the first interval.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		db.RefreshProductSearchEvery(ctx, time.Hour)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := db.SearchProducts(context.Background(), inventory.SearchProductsParams{QueryString: "desk"})
+		if err != nil {
+			t.Fatalf("DB.SearchProducts() error = %v", err)
+		}
+		if got.Total == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("product search view wasn't refreshed when starting")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
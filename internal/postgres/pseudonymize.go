@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// Pseudonymizer replaces identifiers with their keyed hash (HMAC-SHA256), so the database never stores raw identifiers.
+// Lookups remain possible by recomputing the hash of the identifier.
+//
+// To rotate keys, add the new key as the first one: new values are pseudonymized with the first key,
+// while lookups match values pseudonymized with any of the keys.
+// Values pseudonymized with a key are only found while the key is kept.
+type Pseudonymizer struct {
+	keys [][]byte
+}
+
+// NewPseudonymizer creates a Pseudonymizer with the given keys, from the newest to the oldest.
+// Keys must have at least 32 bytes.
+func NewPseudonymizer(keys ...[]byte) (*Pseudonymizer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("missing pseudonymization key")
+	}
+	for _, k := range keys {
+		if len(k) < 32 {
+			return nil, errors.New("pseudonymization key must have at least 32 bytes")
+		}
+	}
+	return &Pseudonymizer{keys: keys}, nil
+}
+
+// WithReviewerPseudonymizer stores the reviewer ID of reviews pseudonymized.
+// Reviews read from the database carry the pseudonymized reviewer ID.
+func WithReviewerPseudonymizer(p *Pseudonymizer) Option {
+	return func(db *DB) {
+		db.reviewerPseudonymizer = p
+	}
+}
+
+func (p *Pseudonymizer) hash(key []byte, id string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// pseudonymize the identifier with the current key.
+// If p is nil, the identifier is returned as is.
+func (p *Pseudonymizer) pseudonymize(id string) string {
+	if p == nil {
+		return id
+	}
+	return p.hash(p.keys[0], id)
+}
+
+// digest of the identifier, to refer to it in records kept after its data is erased, such as the audit log:
+// its pseudonym, or its SHA-256 hash if p is nil, so the raw identifier is never stored there.
+func (p *Pseudonymizer) digest(id string) string {
+	if p != nil {
+		return p.pseudonymize(id)
+	}
+	sum := sha256.Sum256([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// candidates returns the values the identifier might be stored as, one for each key.
+// If p is nil, it only returns the identifier.
+func (p *Pseudonymizer) candidates(id string) []string {
+	if p == nil {
+		return []string{id}
+	}
+	c := make([]string, 0, len(p.keys))
+	for _, k := range p.keys {
+		c = append(c, p.hash(k, id))
+	}
+	return c
+}
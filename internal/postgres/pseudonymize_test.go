@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestNewPseudonymizer(t *testing.T) {
+	t.Parallel()
+	if _, err := NewPseudonymizer(); err == nil {
+		t.Error("NewPseudonymizer() should fail without keys")
+	}
+	if _, err := NewPseudonymizer([]byte("short")); err == nil {
+		t.Error("NewPseudonymizer() should fail with a short key")
+	}
+}
+
+func TestPseudonymizerRotation(t *testing.T) {
+	t.Parallel()
+	oldKey, newKey := bytes.Repeat([]byte("a"), 32), bytes.Repeat([]byte("b"), 32)
+	before, err := NewPseudonymizer(oldKey)
+	if err != nil {
+		t.Fatalf("NewPseudonymizer() error = %v", err)
+	}
+	after, err := NewPseudonymizer(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("NewPseudonymizer() error = %v", err)
+	}
+
+	stored := before.pseudonymize("reviewer")
+	if stored == "reviewer" {
+		t.Error("pseudonymize() returned the raw identifier")
+	}
+	if stored != before.pseudonymize("reviewer") {
+		t.Error("pseudonymize() should be deterministic")
+	}
+	if after.pseudonymize("reviewer") == stored {
+		t.Error("pseudonymize() should use the newest key")
+	}
+	// Values stored with the old key must still be found after the rotation.
+	if c := after.candidates("reviewer"); len(c) != 2 || c[1] != stored {
+		t.Errorf("candidates() = %v, want to contain %v", c, stored)
+	}
+
+	var nilP *Pseudonymizer
+	if got := nilP.pseudonymize("reviewer"); got != "reviewer" {
+		t.Errorf("pseudonymize() = %v on nil Pseudonymizer, want reviewer", got)
+	}
+}
+
+func TestReviewerPseudonymizer(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	p, err := NewPseudonymizer(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatalf("NewPseudonymizer() error = %v", err)
+	}
+	db := NewDB(pool, slog.Default(), WithReviewerPseudonymizer(p))
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "sofa",
+			Name:        "Sofa",
+			Description: "A sofa",
+			Price:       500,
+		},
+	})
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "review",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "sofa",
+				ReviewerID:  "alice",
+				Score:       5,
+				Title:       "Comfortable",
+				Description: "Very comfortable",
+			},
+		},
+	})
+
+	var stored string
+	if err := pool.QueryRow(context.Background(), `SELECT "reviewer_id" FROM "review" WHERE "id" = 'review'`).Scan(&stored); err != nil {
+		t.Fatalf("cannot get reviewer_id: %v", err)
+	}
+	if stored == "alice" {
+		t.Error("reviewer ID was stored unpseudonymized")
+	}
+
+	got, err := db.GetProductReviews(context.Background(), inventory.ProductReviewsParams{
+		ReviewerID: "alice",
+		Pagination: inventory.Pagination{Limit: 10},
+	})
+	if err != nil {
+		t.Fatalf("DB.GetProductReviews() error = %v", err)
+	}
+	if got.Total != 1 || got.Reviews[0].ID != "review" {
+		t.Errorf("unexpected DB.GetProductReviews() value: %+v", got)
+	}
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// query is a SQL statement built from fragments with ? placeholders, which are numbered as $1, $2, and so on
+// as the fragments are appended, along with their arguments, so the placeholders always match the arguments.
+//
+// Fragments must not contain a ? other than the placeholders, such as in the jsonb ? operator or a string literal.
+type query struct {
+	sql  strings.Builder
+	args []any
+}
+
+// newQuery starts a query with a fragment of SQL.
+func newQuery(sql string, args ...any) *query {
+	return new(query).append(sql, args...)
+}
+
+// append a fragment of SQL, with an argument for each of its placeholders.
+// It panics if the number of placeholders and arguments differ.
+func (q *query) append(sql string, args ...any) *query {
+	if n := strings.Count(sql, "?"); n != len(args) {
+		panic(fmt.Sprintf("postgres: query fragment %q has %d placeholders, but %d arguments", sql, n, len(args)))
+	}
+	for {
+		before, after, found := strings.Cut(sql, "?")
+		q.sql.WriteString(before)
+		if !found {
+			break
+		}
+		q.args = append(q.args, args[0])
+		args = args[1:]
+		q.sql.WriteString("$" + strconv.Itoa(len(q.args)))
+		sql = after
+	}
+	return q
+}
+
+// where appends a WHERE clause with the conditions joined by AND, if any.
+func (q *query) where(c conditions) *query {
+	for i, cond := range c {
+		if i == 0 {
+			q.append(" WHERE ")
+		} else {
+			q.append(" AND ")
+		}
+		q.append(cond.sql, cond.args...)
+	}
+	return q
+}
+
+// page appends the LIMIT and OFFSET clauses of the pagination, if set.
+func (q *query) page(limit, offset int) *query {
+	if limit != 0 {
+		q.append(" LIMIT ?", limit)
+	}
+	if offset != 0 {
+		q.append(" OFFSET ?", offset)
+	}
+	return q
+}
+
+// String returns the SQL of the query.
+func (q *query) String() string {
+	return q.sql.String()
+}
+
+// conditions of a WHERE clause, each a fragment of SQL with ? placeholders and their arguments.
+type conditions []condition
+
+type condition struct {
+	sql  string
+	args []any
+}
+
+// add a condition, with an argument for each of its placeholders.
+func (c *conditions) add(sql string, args ...any) {
+	*c = append(*c, condition{sql: sql, args: args})
+}
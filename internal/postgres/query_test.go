@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+	var where conditions
+	where.add(`"name" LIKE ?`, "%desk%")
+	where.add(`"price" BETWEEN ? AND ?`, 10, 20)
+	tests := []struct {
+		name     string
+		q        *query
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:    "plain",
+			q:       newQuery(`SELECT 1`),
+			wantSQL: `SELECT 1`,
+		},
+		{
+			name:     "where",
+			q:        newQuery(`SELECT * FROM "product"`).where(where),
+			wantSQL:  `SELECT * FROM "product" WHERE "name" LIKE $1 AND "price" BETWEEN $2 AND $3`,
+			wantArgs: []any{"%desk%", 10, 20},
+		},
+		{
+			name:    "where_none",
+			q:       newQuery(`SELECT * FROM "product"`).where(nil).page(0, 0),
+			wantSQL: `SELECT * FROM "product"`,
+		},
+		{
+			name:     "page",
+			q:        newQuery(`SELECT * FROM "product" WHERE "id" <> ?`, "bed").where(where[:1]).page(10, 20),
+			wantSQL:  `SELECT * FROM "product" WHERE "id" <> $1 WHERE "name" LIKE $2 LIMIT $3 OFFSET $4`,
+			wantArgs: []any{"bed", "%desk%", 10, 20},
+		},
+		{
+			name:     "limit",
+			q:        newQuery(`SELECT * FROM "product"`).page(10, 0),
+			wantSQL:  `SELECT * FROM "product" LIMIT $1`,
+			wantArgs: []any{10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.q.String(); got != tt.wantSQL {
+				t.Errorf("query = %q, want %q", got, tt.wantSQL)
+			}
+			if !cmp.Equal(tt.wantArgs, tt.q.args) {
+				t.Errorf("query args mismatch: %v", cmp.Diff(tt.wantArgs, tt.q.args))
+			}
+		})
+	}
+}
+
+func TestQueryPlaceholderMismatch(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected query with missing argument to panic")
+		}
+	}()
+	newQuery(`SELECT * FROM "product" WHERE "id" = ? AND "price" > ?`, "desk")
+}
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// checkPlaceholders of the query, which must be numbered from $1 to the number of arguments, each used once.
+func checkPlaceholders(t *testing.T, q *query) {
+	t.Helper()
+	var got []int
+	for _, m := range placeholder.FindAllStringSubmatch(q.String(), -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, n)
+	}
+	slices.Sort(got)
+	want := make([]int, 0, len(q.args))
+	for i := range q.args {
+		want = append(want, i+1)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("query %q has placeholders %v for %d arguments", q.String(), got, len(q.args))
+	}
+}
+
+func FuzzSearchProductsQuery(f *testing.F) {
+	f.Add("desk", "", "", 0, 0, 10, 0, false)
+	f.Add("", "SKU-", "oak or walnut", 100, 500, 10, 20, true)
+	f.Add("what?", "?", "-?", 1, 0, 0, 5, true)
+	f.Fuzz(func(t *testing.T, q, sku, text string, minPrice, maxPrice, limit, offset int, byName bool) {
+		params := inventory.SearchProductsParams{
+			QueryString: q,
+			SKUPrefix:   sku,
+			Text:        text,
+			MinPrice:    minPrice,
+			MaxPrice:    maxPrice,
+			Pagination:  inventory.Pagination{Limit: limit, Offset: offset},
+		}
+		if byName {
+			params.OrderBy = inventory.ProductsByName
+			params.Locales = []string{"pt-BR"}
+		}
+		where := searchProductsConditions(params)
+		if len(where) == 0 {
+			t.Errorf("search without conditions for %+v", params)
+		}
+		checkPlaceholders(t, newQuery(`SELECT COUNT(*) FROM "product"`).where(where))
+		checkPlaceholders(t, searchProductsQuery(params, `"product"`, `*`, where))
+	})
+}
+
+func FuzzProductReviewsQuery(f *testing.F) {
+	f.Add("desk", "", "", false, 0.0, false, 0.0, 10, 0, "")
+	f.Add("", "reviewer", "en", true, -0.5, true, 0.5, 10, 20, string(inventory.ReviewsByMostPositive))
+	f.Fuzz(func(t *testing.T, productID, reviewerID, language string, hasMin bool, minSentiment float64, hasMax bool, maxSentiment float64,
+		limit, offset int, order string) {
+		params := inventory.ProductReviewsParams{
+			ProductID:  productID,
+			ReviewerID: reviewerID,
+			Language:   language,
+			Pagination: inventory.Pagination{Limit: limit, Offset: offset},
+			OrderBy:    inventory.ReviewOrder(order),
+		}
+		if hasMin {
+			params.MinSentiment = &minSentiment
+		}
+		if hasMax {
+			params.MaxSentiment = &maxSentiment
+		}
+		where := DB{}.productReviewsConditions(params)
+		checkPlaceholders(t, newQuery(`SELECT COUNT(*) FROM "review"`).where(where))
+		checkPlaceholders(t, productReviewsQuery(params, where))
+	})
+}
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5"
+)
+
+var _ inventory.QuotaStore = (*DB)(nil) // Check if methods expected by inventory.QuotaStore are implemented correctly.
+
+// WithQuotas enforces the quotas of the tenants on the products and reviews they create,
+// with the quota set for them on the tenant_quota table, or the default one otherwise.
+//
+// Products and reviews of a tenant are then created in a transaction holding an advisory lock,
+// so concurrent requests can't exceed the quota, at the cost of creating them one at a time per tenant or product.
+func WithQuotas(defaults inventory.Quota) Option {
+	return func(db *DB) {
+		db.quotas = &defaults
+	}
+}
+
+// GetTenantQuota returns the quota set for a tenant, or nil if the default quota applies.
+func (db DB) GetTenantQuota(ctx context.Context, tenant string) (*inventory.TenantQuota, error) {
+	const sql = `SELECT "tenant", "max_products", "max_reviews_per_product" FROM "tenant_quota" WHERE "tenant" = $1`
+	var q inventory.TenantQuota
+	err := db.conn(ctx).QueryRow(ctx, sql, tenant).Scan(&q.Tenant, &q.MaxProducts, &q.MaxReviewsPerProduct)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		db.log.Error("cannot get tenant quota from database", slog.String("tenant", tenant), slog.Any("error", err))
+		return nil, infraError("cannot get tenant quota from database", err)
+	}
+	return &q, nil
+}
+
+// SetTenantQuota sets the quota of a tenant, replacing the one set before, if any.
+func (db DB) SetTenantQuota(ctx context.Context, q inventory.TenantQuota) error {
+	const sql = `INSERT INTO "tenant_quota" ("tenant", "max_products", "max_reviews_per_product") VALUES ($1, $2, $3)
+	ON CONFLICT ("tenant") DO UPDATE SET
+		"max_products" = EXCLUDED."max_products",
+		"max_reviews_per_product" = EXCLUDED."max_reviews_per_product",
+		"modified_at" = now()`
+	_, err := db.conn(ctx).Exec(ctx, sql, q.Tenant, q.MaxProducts, q.MaxReviewsPerProduct)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot set tenant quota on database", slog.String("tenant", q.Tenant), slog.Any("error", err))
+		return infraError("cannot set tenant quota on database", err)
+	}
+	return nil
+}
+
+// DeleteTenantQuota deletes the quota set for a tenant, so the default quota applies again.
+func (db DB) DeleteTenantQuota(ctx context.Context, tenant string) error {
+	const sql = `DELETE FROM "tenant_quota" WHERE "tenant" = $1`
+	_, err := db.conn(ctx).Exec(ctx, sql, tenant)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot delete tenant quota on database", slog.String("tenant", tenant), slog.Any("error", err))
+		return infraError("cannot delete tenant quota on database", err)
+	}
+	return nil
+}
+
+// withinQuota runs fn in a transaction, once check passes.
+// The check runs in the transaction too, so it must lock what it counts until the transaction ends.
+// The transaction is committed if fn succeeds, and rolled back otherwise.
+func (db DB) withinQuota(ctx context.Context, op string, check, fn func(ctx context.Context) error) (err error) {
+	txCtx, err := db.TransactionContext(ctx)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot begin transaction to "+op, slog.Any("error", err))
+		return infraError("cannot "+op+" on database", err)
+	}
+	defer func() {
+		if err != nil {
+			if rerr := db.Rollback(txCtx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback transaction to "+op, slog.Any("error", rerr))
+			}
+		}
+	}()
+	switch err = check(txCtx); {
+	case errors.Is(err, inventory.ErrQuotaExceeded), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot check quota to "+op, slog.Any("error", err))
+		return infraError("cannot "+op+" on database", err)
+	}
+	if err = fn(txCtx); err != nil {
+		return err
+	}
+	if err = db.Commit(txCtx); err != nil && ctx.Err() == nil {
+		db.log.Error("cannot commit transaction to "+op, slog.Any("error", err))
+		return infraError("cannot "+op+" on database", err)
+	}
+	return err
+}
+
+// quotaLimit locks the advisory lock of key until the transaction of the context ends,
+// and then returns the limit of the column of the quota of the tenant.
+func (db DB) quotaLimit(ctx context.Context, key, tenant, column string, defaultLimit int) (int, error) {
+	conn := db.conn(ctx)
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, key); err != nil {
+		return 0, err
+	}
+	// The limit is read after the lock is held, so it's the one committed by the time the check runs.
+	sql := `SELECT COALESCE((SELECT "` + column + `" FROM "tenant_quota" WHERE "tenant" = $1), $2)` // #nosec G202
+	var limit int
+	err := conn.QueryRow(ctx, sql, tenant, defaultLimit).Scan(&limit)
+	return limit, err
+}
+
+// checkProductQuota returns a *inventory.QuotaExceededError if the tenant can't create another product.
+// Creating a product that already exists doesn't count towards the quota, as it isn't created again.
+func (db DB) checkProductQuota(ctx context.Context, tenant, id string) error {
+	limit, err := db.quotaLimit(ctx, "product_quota:"+tenant, tenant, "max_products", db.quotas.MaxProducts)
+	if err != nil || limit == 0 {
+		return err
+	}
+	const sql = `SELECT
+		(SELECT count(*) FROM (SELECT FROM "product" WHERE starts_with("id", $1::text || $2::text) LIMIT $3) AS "p"),
+		EXISTS (SELECT 1 FROM "product" WHERE "id" = $4)`
+	var (
+		n      int
+		exists bool
+	)
+	if err := db.conn(ctx).QueryRow(ctx, sql, tenant, inventory.TenantSeparator, limit, id).Scan(&n, &exists); err != nil {
+		return err
+	}
+	if !exists && n >= limit {
+		return &inventory.QuotaExceededError{Tenant: tenant, Resource: inventory.QuotaProducts, Limit: limit}
+	}
+	return nil
+}
+
+// checkReviewQuota returns a *inventory.QuotaExceededError if the product of the tenant can't have another review.
+func (db DB) checkReviewQuota(ctx context.Context, tenant, productID string) error {
+	limit, err := db.quotaLimit(ctx, "review_quota:"+productID, tenant, "max_reviews_per_product", db.quotas.MaxReviewsPerProduct)
+	if err != nil || limit == 0 {
+		return err
+	}
+	const sql = `SELECT count(*) FROM (SELECT FROM "review" WHERE "product_id" = $1 LIMIT $2) AS "r"`
+	var n int
+	if err := db.conn(ctx).QueryRow(ctx, sql, productID, limit).Scan(&n); err != nil {
+		return err
+	}
+	if n >= limit {
+		return &inventory.QuotaExceededError{Tenant: tenant, Resource: inventory.QuotaReviewsPerProduct, Limit: limit}
+	}
+	return nil
+}
+
+// quotaTenant returns the tenant of the record with the given ID if quotas are enforced on it.
+func (db DB) quotaTenant(id string) (tenant string, ok bool) {
+	if db.quotas == nil {
+		return "", false
+	}
+	tenant, _, err := inventory.ParseID(id)
+	return tenant, err == nil && tenant != ""
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestTenantQuota(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+
+	if q, err := db.GetTenantQuota(ctx, "acme"); err != nil || q != nil {
+		t.Errorf("DB.GetTenantQuota() = (%v, %v), want no quota", q, err)
+	}
+	for _, max := range []int{10, 20} {
+		if err := db.SetTenantQuota(ctx, inventory.TenantQuota{Tenant: "acme", Quota: inventory.Quota{MaxProducts: max, MaxReviewsPerProduct: 5}}); err != nil {
+			t.Fatalf("DB.SetTenantQuota() error = %v", err)
+		}
+	}
+	want := inventory.TenantQuota{Tenant: "acme", Quota: inventory.Quota{MaxProducts: 20, MaxReviewsPerProduct: 5}}
+	if q, err := db.GetTenantQuota(ctx, "acme"); err != nil || q == nil || *q != want {
+		t.Errorf("DB.GetTenantQuota() = (%v, %v), want %v", q, err, want)
+	}
+	if err := db.DeleteTenantQuota(ctx, "acme"); err != nil {
+		t.Fatalf("DB.DeleteTenantQuota() error = %v", err)
+	}
+	if q, err := db.GetTenantQuota(ctx, "acme"); err != nil || q != nil {
+		t.Errorf("DB.GetTenantQuota() after deleting it = (%v, %v), want no quota", q, err)
+	}
+	if err := db.SetTenantQuota(canceledContext(), want); err != context.Canceled {
+		t.Errorf("DB.SetTenantQuota() with canceled context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestQuotaEnforcement(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default(), WithQuotas(inventory.Quota{MaxProducts: 3, MaxReviewsPerProduct: 1}))
+	if err := db.SetTenantQuota(ctx, inventory.TenantQuota{Tenant: "globex", Quota: inventory.Quota{MaxProducts: 1}}); err != nil {
+		t.Fatalf("DB.SetTenantQuota() error = %v", err)
+	}
+
+	// Concurrent requests can't exceed the default quota of acme.
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		created  int
+		exceeded int
+	)
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := db.CreateProduct(ctx, inventory.CreateProductParams{
+				ID:          fmt.Sprintf("acme:product%d", i),
+				Name:        "Product",
+				Description: "A product",
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			var qe *inventory.QuotaExceededError
+			switch {
+			case err == nil:
+				created++
+			case errors.As(err, &qe) && qe.Resource == inventory.QuotaProducts && qe.Limit == 3:
+				exceeded++
+			default:
+				t.Errorf("DB.CreateProduct() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if created != 3 || exceeded != 7 {
+		t.Errorf("created %d products, %d exceeded the quota, want 3 and 7", created, exceeded)
+	}
+
+	// Creating a product that exists doesn't count towards the quota.
+	if res, err := db.CreateProduct(ctx, inventory.CreateProductParams{ID: "acme:product0", Name: "Product", Description: "A product"}); err != nil || res.Created {
+		t.Errorf("DB.CreateProduct() of an existing product = (%v, %v), want the existing one", res, err)
+	}
+
+	// The quota set for globex overrides the default one.
+	for i, want := range []error{nil, inventory.ErrQuotaExceeded} {
+		_, err := db.CreateProduct(ctx, inventory.CreateProductParams{ID: fmt.Sprintf("globex:product%d", i), Name: "Product", Description: "A product"})
+		if !errors.Is(err, want) {
+			t.Errorf("DB.CreateProduct() of globex product %d error = %v, want %v", i, err, want)
+		}
+	}
+
+	// Products without a tenant aren't limited.
+	createProducts(t, db, []inventory.CreateProductParams{
+		{ID: "product1", Name: "Product", Description: "A product"},
+		{ID: "product2", Name: "Product", Description: "A product"},
+		{ID: "product3", Name: "Product", Description: "A product"},
+		{ID: "product4", Name: "Product", Description: "A product"},
+	})
+
+	// Reviews are limited by product, and unlimited for globex, whose quota has no limit of reviews.
+	for _, tc := range []struct {
+		id, productID string
+		want          error
+	}{
+		{"acme:review1", "acme:product0", nil},
+		{"acme:review2", "acme:product0", inventory.ErrQuotaExceeded},
+		{"acme:review3", "acme:product1", nil},
+		{"globex:review1", "globex:product0", nil},
+		{"globex:review2", "globex:product0", nil},
+	} {
+		err := db.CreateProductReview(ctx, inventory.CreateProductReviewDBParams{
+			ID: tc.id,
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   tc.productID,
+				ReviewerID:  "reviewer",
+				Score:       5,
+				Title:       "Good",
+				Description: "A good product",
+			},
+		})
+		if !errors.Is(err, tc.want) {
+			t.Errorf("DB.CreateProductReview(%q) error = %v, want %v", tc.id, err, tc.want)
+		}
+	}
+}
+
+func TestQuotaConcurrentSlug(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default(), WithQuotas(inventory.Quota{MaxProducts: 10}))
+
+	// Take the slug in a transaction, so the concurrent product picks it too, and conflicts once it's committed.
+	txCtx, err := db.TransactionContext(ctx)
+	if err != nil {
+		t.Fatalf("DB.TransactionContext() error = %v", err)
+	}
+	defer db.Rollback(txCtx) // #nosec G104
+	if _, err := db.CreateProduct(txCtx, inventory.CreateProductParams{ID: "acme:desk", Name: "desk", Description: "A desk"}); err != nil {
+		t.Fatalf("DB.CreateProduct() error = %v", err)
+	}
+	type result struct {
+		res *inventory.CreateProductResult
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		res, err := db.CreateProduct(ctx, inventory.CreateProductParams{ID: "globex:desk", Name: "desk", Description: "A desk"})
+		done <- result{res, err}
+	}()
+	waitForLock(t, pool)
+	if err := db.Commit(txCtx); err != nil {
+		t.Fatalf("DB.Commit() error = %v", err)
+	}
+
+	// The product is created with the next free slug, rather than failing with the aborted transaction.
+	got := <-done
+	if got.err != nil {
+		t.Fatalf("DB.CreateProduct() error = %v", got.err)
+	}
+	if !got.res.Created || got.res.Product.Slug != "desk-2" {
+		t.Errorf("DB.CreateProduct() = %+v, want the product created with slug desk-2", got.res)
+	}
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RecordRecentlyViewed moves a product to the top of the recently viewed list of a user,
+// dropping the least recently viewed products beyond inventory.MaxRecentlyViewed.
+func (db DB) RecordRecentlyViewed(ctx context.Context, params inventory.RecentlyViewedParams) error {
+	tx, err := db.begin(ctx)
+	if err == nil {
+		defer func() {
+			if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback recently viewed record", slog.Any("error", rerr))
+			}
+		}()
+		err = recordRecentlyViewed(ctx, tx, params)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation:
+		return nil // The product doesn't exist (anymore).
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UndefinedTable:
+		// The recently_viewed table is created by a migration within the accepted schema window.
+		return nil
+	case err != nil:
+		db.log.Error("cannot record recently viewed product on database",
+			slog.String("product", params.ProductID),
+			slog.Any("error", err),
+		)
+		return infraError("cannot record recently viewed product on database", err)
+	}
+	return nil
+}
+
+func recordRecentlyViewed(ctx context.Context, tx pgx.Tx, params inventory.RecentlyViewedParams) error {
+	const (
+		upsert = `INSERT INTO "recently_viewed" ("user_id", "product_id") VALUES ($1, $2)
+	ON CONFLICT ("user_id", "product_id") DO UPDATE SET "viewed_at" = now()`
+		trim = `DELETE FROM "recently_viewed" WHERE "user_id" = $1 AND "product_id" IN (
+		SELECT "product_id" FROM "recently_viewed" WHERE "user_id" = $1
+		ORDER BY "viewed_at" DESC, "product_id"
+		OFFSET $2
+	)`
+	)
+	if _, err := tx.Exec(ctx, upsert, params.UserID, params.ProductID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, trim, params.UserID, inventory.MaxRecentlyViewed)
+	return err
+}
+
+// ListRecentlyViewed returns the products recently viewed by a user, the most recent first.
+func (db DB) ListRecentlyViewed(ctx context.Context, params inventory.ListRecentlyViewedParams) (*inventory.ListProductsResponse, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "product" p
+	JOIN "recently_viewed" v ON v."product_id" = p."id"
+	WHERE v."user_id" = $1
+	ORDER BY v."viewed_at" DESC, p."id"
+	LIMIT $2 OFFSET $3`, productColumns(params.View, "p")) // #nosec G201
+	return db.listProducts(ctx, "ListRecentlyViewed", params.View, sql, params.UserID, params.Pagination.Limit, params.Pagination.Offset)
+}
+
+// PruneRecentlyViewed removes the views recorded before a time from the recently viewed lists,
+// returning how many were removed.
+func (db DB) PruneRecentlyViewed(ctx context.Context, before time.Time) (int64, error) {
+	const sql = `DELETE FROM "recently_viewed" WHERE "viewed_at" < $1`
+	tag, err := db.conn(ctx).Exec(ctx, sql, before)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return 0, err
+	case err != nil:
+		db.log.Error("cannot prune recently viewed products from database", slog.Any("error", err))
+		return 0, infraError("cannot prune recently viewed products from database", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PruneRecentlyViewedEvery removes the views older than ttl from the recently viewed lists periodically,
+// until the context is canceled.
+func (db DB) PruneRecentlyViewedEvery(ctx context.Context, ttl, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := db.PruneRecentlyViewed(ctx, time.Now().Add(-ttl)); err == nil && n > 0 {
+				db.log.Debug("recently viewed products pruned", slog.Int64("removed", n))
+			}
+		}
+	}
+}
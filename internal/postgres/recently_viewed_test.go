@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestRecentlyViewed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+
+	var products []inventory.CreateProductParams
+	for i := range inventory.MaxRecentlyViewed + 1 {
+		products = append(products, inventory.CreateProductParams{
+			ID:          fmt.Sprintf("product%02d", i),
+			Name:        "Product",
+			Description: "A product",
+			Price:       100,
+		})
+	}
+	createProducts(t, db, products)
+	for _, p := range products {
+		if err := db.RecordRecentlyViewed(ctx, inventory.RecentlyViewedParams{UserID: "alice", ProductID: p.ID}); err != nil {
+			t.Fatalf("DB.RecordRecentlyViewed() error = %v", err)
+		}
+	}
+	// Viewing a product again moves it to the top.
+	for _, params := range []inventory.RecentlyViewedParams{
+		{UserID: "alice", ProductID: "product10"},
+		{UserID: "alice", ProductID: "not_found"}, // Views of a product that doesn't exist are ignored.
+		{UserID: "bob", ProductID: "product00"},
+	} {
+		if err := db.RecordRecentlyViewed(ctx, params); err != nil {
+			t.Fatalf("DB.RecordRecentlyViewed(%v) error = %v", params, err)
+		}
+	}
+
+	got, err := db.ListRecentlyViewed(ctx, inventory.ListRecentlyViewedParams{
+		UserID:     "alice",
+		Pagination: inventory.Pagination{Limit: 100},
+	})
+	if err != nil {
+		t.Fatalf("DB.ListRecentlyViewed() error = %v", err)
+	}
+	if len(got.Items) != inventory.MaxRecentlyViewed {
+		t.Fatalf("DB.ListRecentlyViewed() returned %d products, want %d", len(got.Items), inventory.MaxRecentlyViewed)
+	}
+	if got.Items[0].ID != "product10" {
+		t.Errorf("DB.ListRecentlyViewed() first product = %q, want product10", got.Items[0].ID)
+	}
+	for _, p := range got.Items {
+		if p.ID == "product00" {
+			t.Errorf("DB.ListRecentlyViewed() has product00, which should have been dropped")
+		}
+	}
+
+	// Pruning removes every view recorded before the given time.
+	if n, err := db.PruneRecentlyViewed(ctx, time.Now().Add(time.Minute)); err != nil || n != int64(inventory.MaxRecentlyViewed+1) {
+		t.Errorf("DB.PruneRecentlyViewed() = (%d, %v), want (%d, nil)", n, err, inventory.MaxRecentlyViewed+1)
+	}
+	if got, err := db.ListRecentlyViewed(ctx, inventory.ListRecentlyViewedParams{
+		UserID:     "bob",
+		Pagination: inventory.Pagination{Limit: 10},
+	}); err != nil || len(got.Items) != 0 {
+		t.Errorf("DB.ListRecentlyViewed() = (%v, %v), want no products", got, err)
+	}
+}
@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// Replicas used for read queries.
+type Replicas struct {
+	// Pools of read replicas. Read queries are distributed between them in a round-robin fashion.
+	Pools []*pgxpool.Pool
+
+	// HedgeAfter is the latency threshold after which a second attempt of a read query is issued to another replica.
+	// The first attempt to succeed is used, and the other one is canceled.
+	// Hedging is disabled if HedgeAfter is zero or if there is only one replica.
+	HedgeAfter time.Duration
+
+	// Meter to record hedging metrics.
+	Meter metric.Meter
+}
+
+// WithReplicas routes read queries that are not part of a transaction or acquired connection to read replicas.
+func WithReplicas(r Replicas) Option {
+	return func(db *DB) {
+		if len(r.Pools) == 0 {
+			return
+		}
+		meter := r.Meter
+		if meter == nil {
+			meter = noop.NewMeterProvider().Meter("postgres")
+		}
+		rs := &replicaSet{
+			pools:      r.Pools,
+			hedgeAfter: r.HedgeAfter,
+		}
+		var err error
+		if rs.hedges, err = meter.Int64Counter("db.hedge.attempts",
+			metric.WithDescription("Number of hedged read attempts issued to a second replica.")); err != nil {
+			db.log.Error("cannot create hedge attempts counter", slog.Any("error", err))
+		}
+		if rs.results, err = meter.Int64Counter("db.hedge.results",
+			metric.WithDescription("Number of read queries completed after hedging, by winning attempt.")); err != nil {
+			db.log.Error("cannot create hedge results counter", slog.Any("error", err))
+		}
+		db.replicas = rs
+	}
+}
+
+// replicaSet of read replicas.
+type replicaSet struct {
+	pools      []*pgxpool.Pool
+	hedgeAfter time.Duration
+	next       atomic.Uint64
+
+	hedges  metric.Int64Counter
+	results metric.Int64Counter
+}
+
+// pick the next replica.
+func (r *replicaSet) pick() (int, *pgxpool.Pool) {
+	i := int((r.next.Add(1) - 1) % uint64(len(r.pools)))
+	return i, r.pools[i]
+}
+
+// pickOther picks a replica other than the one at index used, such as for a hedged attempt.
+// Concurrent reads share the round-robin counter, so picking the next one might return the same replica again.
+func (r *replicaSet) pickOther(used int) *pgxpool.Pool {
+	i, pool := r.pick()
+	if i == used {
+		pool = r.pools[(used+1)%len(r.pools)]
+	}
+	return pool
+}
+
+// hedging returns whether hedged reads are enabled.
+func (r *replicaSet) hedging() bool {
+	return r.hedgeAfter > 0 && len(r.pools) > 1
+}
+
+func (r *replicaSet) addHedge(ctx context.Context) {
+	if r.hedges != nil {
+		r.hedges.Add(ctx, 1)
+	}
+}
+
+func (r *replicaSet) addResult(ctx context.Context, winner string) {
+	if r.results != nil {
+		r.results.Add(ctx, 1, metric.WithAttributes(attribute.String("winner", winner)))
+	}
+}
+
+// readsReplicas tells whether reads with the context go to the read replicas:
+// replicas are configured, and the context carries neither a transaction nor an acquired connection,
+// nor is it batch work while there is a batch pool.
+func readsReplicas(ctx context.Context, db DB) bool {
+	return db.replicas != nil && ctx.Value(txCtx{}) == nil && ctx.Value(connCtx{}) == nil &&
+		(db.batchPool == nil || db.poolFor(ctx) != db.batchPool)
+}
+
+// readConn returns a read replica if reads with the context go to the read replicas, or db.conn(ctx) otherwise.
+// Unlike read, it never hedges, so it's used for reads that can't be attempted twice,
+// such as streaming rows to a client as they're read.
+func readConn(ctx context.Context, db DB) database.PGXQuerier {
+	if !readsReplicas(ctx, db) {
+		return db.conn(ctx)
+	}
+	_, pool := db.replicas.pick()
+	return pool
+}
+
+// read executes fn against a read replica, if replicas are configured and the context carries neither
+// a transaction nor an acquired connection. Otherwise, fn is executed against db.conn(ctx),
+// as is batch work if there is a batch pool (see WithBatchPool).
+//
+// When hedging is enabled, a second attempt is issued to another replica if the first one
+// doesn't complete within the latency threshold or fails.
+// The result of the first attempt to succeed is returned, and the other attempt is canceled.
+func read[T any](ctx context.Context, db DB, fn func(ctx context.Context, conn database.PGXQuerier) (T, error)) (T, error) {
+	r := db.replicas
+	if !readsReplicas(ctx, db) || !r.hedging() {
+		return fn(ctx, readConn(ctx, db))
+	}
+
+	type result struct {
+		v      T
+		err    error
+		hedged bool
+	}
+	// Canceling the context on return cancels the losing attempt.
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch := make(chan result, 2) // Buffered so the losing attempt never blocks.
+	attempt := func(pool *pgxpool.Pool, hedged bool) {
+		go func() {
+			v, err := fn(attemptCtx, pool)
+			ch <- result{v: v, err: err, hedged: hedged}
+		}()
+	}
+
+	first, pool := r.pick()
+	attempt(pool, false)
+	timer := time.NewTimer(r.hedgeAfter)
+	defer timer.Stop()
+	var res result
+	select {
+	case res = <-ch:
+		// Return right away if the first attempt succeeded, or the error isn't worth retrying.
+		if res.err == nil || errors.Is(res.err, pgx.ErrNoRows) || ctx.Err() != nil {
+			return res.v, res.err
+		}
+	case <-timer.C:
+	}
+
+	r.addHedge(ctx)
+	attempt(r.pickOther(first), true)
+	pending := 1
+	if res.err == nil {
+		pending = 2 // The first attempt is still running.
+	}
+	for ; pending > 0; pending-- {
+		if res = <-ch; res.err == nil {
+			break
+		}
+	}
+	winner := "first"
+	if res.hedged {
+		winner = "hedge"
+	}
+	if res.err == nil {
+		r.addResult(ctx, winner)
+	}
+	return res.v, res.err
+}
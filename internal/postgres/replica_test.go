@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/telemetry/telemetrytest"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestReadHedge(t *testing.T) {
+	t.Parallel()
+	tel, mem := telemetrytest.Provider()
+	db := NewDB(nil, slog.Default(), WithReplicas(Replicas{
+		// The function passed to read doesn't use the connection, so nil pools are fine.
+		Pools:      []*pgxpool.Pool{nil, nil},
+		HedgeAfter: 10 * time.Millisecond,
+		Meter:      tel.Meter(),
+	}))
+
+	var attempts atomic.Int32
+	got, err := read(context.Background(), db, func(ctx context.Context, conn database.PGXQuerier) (string, error) {
+		if attempts.Add(1) == 1 {
+			// The first attempt is slow, and must be canceled once the hedged attempt wins.
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "hedge", nil
+	})
+	if err != nil {
+		t.Fatalf("read() error = %v", err)
+	}
+	if got != "hedge" {
+		t.Errorf("read() = %v, want hedge", got)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("expected 2 attempts, got %d instead", n)
+	}
+	if m := mem.Meter(); !strings.Contains(m, "db.hedge.attempts") || !strings.Contains(m, "db.hedge.results") {
+		t.Errorf("hedge metrics not recorded: %v", m)
+	}
+}
+
+func TestReadHedgeRetry(t *testing.T) {
+	t.Parallel()
+	db := NewDB(nil, slog.Default(), WithReplicas(Replicas{
+		Pools:      []*pgxpool.Pool{nil, nil},
+		HedgeAfter: time.Minute,
+	}))
+
+	// If the first attempt fails, the second one is issued right away.
+	var attempts atomic.Int32
+	got, err := read(context.Background(), db, func(ctx context.Context, conn database.PGXQuerier) (int32, error) {
+		n := attempts.Add(1)
+		if n == 1 {
+			return 0, errors.New("replica unavailable")
+		}
+		return n, nil
+	})
+	if err != nil {
+		t.Fatalf("read() error = %v", err)
+	}
+	if got != 2 {
+		t.Errorf("read() = %v, want 2", got)
+	}
+}
+
+func TestReplicaSetPickOther(t *testing.T) {
+	t.Parallel()
+	r := &replicaSet{
+		pools: []*pgxpool.Pool{{}, {}, {}},
+	}
+	for used := range r.pools {
+		// Simulate concurrent reads moving the round-robin counter back to the replica already in use.
+		r.next.Store(uint64(used))
+		if got := r.pickOther(used); got == r.pools[used] {
+			t.Errorf("pickOther(%d) picked the replica already in use", used)
+		}
+	}
+}
+
+func TestReplicas(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	// Use the primary as if it were two replicas.
+	db := NewDB(pool, slog.Default(), WithReplicas(Replicas{
+		Pools:      []*pgxpool.Pool{pool, pool},
+		HedgeAfter: time.Nanosecond,
+	}))
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "desk",
+			Name:        "plain desk (home)",
+			Description: "A plain desk",
+			Price:       140,
+		},
+	})
+
+	got, err := db.GetProduct(context.Background(), "desk")
+	if err != nil {
+		t.Fatalf("DB.GetProduct() error = %v", err)
+	}
+	if got == nil || got.ID != "desk" {
+		t.Errorf("unexpected DB.GetProduct() value: %v", got)
+	}
+	if got, err := db.GetProduct(context.Background(), "unknown"); got != nil || err != nil {
+		t.Errorf("DB.GetProduct() = (%v, %v), want (nil, nil)", got, err)
+	}
+
+	resp, err := db.SearchProducts(context.Background(), inventory.SearchProductsParams{
+		QueryString: "desk",
+	})
+	if err != nil {
+		t.Fatalf("DB.SearchProducts() error = %v", err)
+	}
+	if resp.Total != 1 || len(resp.Items) != 1 {
+		t.Errorf("unexpected DB.SearchProducts() value: %+v", resp)
+	}
+}
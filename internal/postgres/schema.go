@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+)
+
+// Range of schema versions (the number of the latest tern migration applied) this package works with.
+//
+// During a rollout, the schema must be compatible with both the running and the new binaries,
+// so migrations are applied before the new binaries start, while the old ones are still running.
+//
+// MinSchemaVersion is the oldest schema the queries work with: the latest migration introducing a table, view,
+// column, or function they use. It's increased along with the queries starting to use what a migration introduces,
+// as the migration is applied before the new binaries start.
+//
+// MaxSchemaVersion is the newest schema the queries are expected to keep working with.
+// It's kept ahead of the latest migration by SchemaVersionWindow, so the running binaries accept
+// the next migrations, and is increased along with every migration added.
+// Migrations within the window must be backward compatible, such as adding tables, nullable columns, or indexes.
+const (
+	MinSchemaVersion = 29
+	MaxSchemaVersion = 32
+
+	// SchemaVersionWindow is the number of migrations the running binaries accept ahead of the latest one they know.
+	SchemaVersionWindow = 2
+)
+
+// SchemaVersionError is returned by CheckSchemaVersion when the database schema is incompatible.
+type SchemaVersionError struct {
+	Version int
+}
+
+func (e *SchemaVersionError) Error() string {
+	return fmt.Sprintf("database schema version %d is incompatible: want between %d and %d",
+		e.Version, MinSchemaVersion, MaxSchemaVersion)
+}
+
+// CheckSchemaVersion checks if the version of the schema applied to the database by tern
+// is compatible with the package, returning a *SchemaVersionError otherwise.
+func CheckSchemaVersion(ctx context.Context, conn database.PGXQuerier) error {
+	var version int
+	if err := conn.QueryRow(ctx, `SELECT "version" FROM "schema_version"`).Scan(&version); err != nil {
+		return fmt.Errorf("cannot get database schema version: %w", err)
+	}
+	if version < MinSchemaVersion || version > MaxSchemaVersion {
+		return &SchemaVersionError{Version: version}
+	}
+	return nil
+}
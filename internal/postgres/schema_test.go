@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+)
+
+func TestCheckSchemaVersion(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+
+	if err := CheckSchemaVersion(context.Background(), pool); err != nil {
+		t.Errorf("CheckSchemaVersion() error = %v", err)
+	}
+
+	if _, err := pool.Exec(context.Background(), `UPDATE "schema_version" SET "version" = $1`, MaxSchemaVersion+1); err != nil {
+		t.Fatalf("cannot update schema version: %v", err)
+	}
+	err := CheckSchemaVersion(context.Background(), pool)
+	var sve *SchemaVersionError
+	if !errors.As(err, &sve) || sve.Version != MaxSchemaVersion+1 {
+		t.Errorf("CheckSchemaVersion() error = %v, want *SchemaVersionError", err)
+	}
+}
+
+func TestCheckSchemaVersionCanceledContext(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	if err := CheckSchemaVersion(canceledContext(), pool); !errors.Is(err, context.Canceled) {
+		t.Errorf("CheckSchemaVersion() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSchemaVersionWindow(t *testing.T) {
+	t.Parallel()
+	files, err := fs.Glob(os.DirFS("../../migrations"), "*.sql")
+	if err != nil {
+		t.Fatalf("cannot list migrations: %v", err)
+	}
+	var latest int
+	for _, f := range files {
+		n, err := strconv.Atoi(strings.SplitN(f, "_", 2)[0])
+		if err != nil {
+			t.Fatalf("cannot parse migration number of %q: %v", f, err)
+		}
+		latest = max(latest, n)
+	}
+	if MinSchemaVersion > latest {
+		t.Errorf("MinSchemaVersion = %d is newer than the latest migration %d", MinSchemaVersion, latest)
+	}
+	if want := latest + SchemaVersionWindow; MaxSchemaVersion != want {
+		t.Errorf("MaxSchemaVersion = %d, want %d: increase it along with every migration added", MaxSchemaVersion, want)
+	}
+}
+
+// migrationIdentifiers matches the tables, views, columns, and functions created by a migration.
+var migrationIdentifiers = regexp.MustCompile(`(?i)(?:CREATE (?:TABLE|VIEW|MATERIALIZED VIEW|FUNCTION)(?: IF NOT EXISTS)?|ADD COLUMN(?: IF NOT EXISTS)?) "?(\w+)"?`)
+
+func TestMinSchemaVersion(t *testing.T) {
+	t.Parallel()
+	migrations := os.DirFS("../../migrations")
+	files, err := fs.Glob(migrations, "*.sql")
+	if err != nil {
+		t.Fatalf("cannot list migrations: %v", err)
+	}
+	sources, err := fs.Glob(os.DirFS("."), "*.go")
+	if err != nil {
+		t.Fatalf("cannot list sources: %v", err)
+	}
+	var queries strings.Builder
+	for _, f := range sources {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("cannot read source: %v", err)
+		}
+		queries.Write(b)
+	}
+
+	// Queries quote identifiers, so what a migration creates is used if its quoted name is found on the sources.
+	for _, f := range files {
+		n, err := strconv.Atoi(strings.SplitN(f, "_", 2)[0])
+		if err != nil {
+			t.Fatalf("cannot parse migration number of %q: %v", f, err)
+		}
+		if n <= MinSchemaVersion {
+			continue
+		}
+		b, err := fs.ReadFile(migrations, f)
+		if err != nil {
+			t.Fatalf("cannot read migration: %v", err)
+		}
+		up, _, _ := strings.Cut(string(b), "---- create above / drop below ----")
+		for _, m := range migrationIdentifiers.FindAllStringSubmatch(up, -1) {
+			if strings.Contains(queries.String(), `"`+m[1]+`"`) {
+				t.Errorf("MinSchemaVersion = %d, but the queries use %q from migration %s: increase it to %d", MinSchemaVersion, m[1], f, n)
+			}
+		}
+	}
+}
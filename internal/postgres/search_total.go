@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5"
+)
+
+// WithWindowTotal makes SearchProducts count the products matching the search with the COUNT(*) OVER () window function,
+// returning the total along with each product of the page in a single statement, rather than counting them first.
+//
+// It saves a round trip to the database, and the total always matches the page, as both are read from the same snapshot.
+// However, the database finds every product matching the search to count them before returning the page,
+// which is slower than two statements on searches with many results, where the page might be read from an index.
+// Compare both with BenchmarkSearchProducts for the searches of the service.
+//
+// Searches with CountPriceBands or EstimateTotal, and pages past the last product, are still counted separately.
+func WithWindowTotal() Option {
+	return func(db *DB) {
+		db.windowTotal = true
+	}
+}
+
+// productWithTotal is a product along with the total number of products matching a search.
+type productWithTotal struct {
+	product
+	Total int
+}
+
+// productSummaryWithTotal is the productWithTotal of the inventory.ProductViewSummary view.
+type productSummaryWithTotal struct {
+	productSummary
+	Total int
+}
+
+// productTotal is a product of a view read along with the total number of products matching a search.
+type productTotal struct {
+	product *inventory.Product
+	total   int
+}
+
+// productTotalRow returns the function reading the products of the view from rows with the total column.
+func productTotalRow(view inventory.ProductView) pgx.RowToFunc[productTotal] {
+	if view == inventory.ProductViewSummary {
+		return func(row pgx.CollectableRow) (productTotal, error) {
+			p, err := pgx.RowToStructByName[productSummaryWithTotal](row)
+			if err != nil {
+				return productTotal{}, err
+			}
+			return productTotal{p.dto(), p.Total}, nil
+		}
+	}
+	return func(row pgx.CollectableRow) (productTotal, error) {
+		p, err := pgx.RowToStructByName[productWithTotal](row)
+		if err != nil {
+			return productTotal{}, err
+		}
+		return productTotal{p.dto(), p.Total}, nil
+	}
+}
+
+// searchProductsWindow returns the page of products of the table matching the search, counting them with a window function.
+func (db DB) searchProductsWindow(ctx context.Context, params inventory.SearchProductsParams, table string, where conditions) (*inventory.SearchProductsResponse, error) {
+	q := searchProductsQuery(params, pgx.Identifier{table}.Sanitize(), productColumns(params.View, table)+`, COUNT(*) OVER () AS "total"`, where)
+	sql, args := q.String(), q.args
+	db.explain(ctx, "SearchProducts", sql, args...)
+	products, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]productTotal, error) {
+		rows, err := conn.Query(ctx, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		products, err := pgx.CollectRows(rows, productTotalRow(params.View))
+		if err != nil || !rateLater(params.View, table) {
+			return products, err
+		}
+		rated := make([]*inventory.Product, 0, len(products))
+		for _, p := range products {
+			rated = append(rated, p.product)
+		}
+		return products, productRatings(ctx, conn, rated)
+	})
+	resp := inventory.SearchProductsResponse{
+		Items: []*inventory.Product{},
+	}
+	switch {
+	case len(products) != 0:
+		resp.Total = products[0].total
+	case err == nil && params.Pagination.Offset != 0:
+		// There are no rows to read the total from past the last product.
+		resp.Total, _, err = db.count(ctx, "SearchProductsCount", table, where, false)
+	}
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return nil, err
+	}
+	if err != nil {
+		db.log.Error("cannot get products from the database", slog.Any("error", err))
+		return nil, infraError("cannot get products", err)
+	}
+	for _, p := range products {
+		resp.Items = append(resp.Items, p.product)
+	}
+	return &resp, nil
+}
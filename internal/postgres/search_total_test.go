@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestWithWindowTotal(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+	window := NewDB(pool, slog.Default(), WithWindowTotal())
+
+	var products []inventory.CreateProductParams
+	for i := range 5 {
+		products = append(products, inventory.CreateProductParams{
+			ID:          fmt.Sprintf("desk%d", i),
+			Name:        fmt.Sprintf("desk %d", i),
+			Description: "A desk",
+			Price:       100 * i,
+		})
+	}
+	createProducts(t, db, products)
+
+	tests := []struct {
+		name   string
+		params inventory.SearchProductsParams
+	}{
+		{
+			name:   "first_page",
+			params: inventory.SearchProductsParams{QueryString: "desk", Pagination: inventory.Pagination{Limit: 2}},
+		},
+		{
+			name:   "last_page",
+			params: inventory.SearchProductsParams{QueryString: "desk", Pagination: inventory.Pagination{Limit: 2, Offset: 4}},
+		},
+		{
+			name:   "past_last_page",
+			params: inventory.SearchProductsParams{QueryString: "desk", Pagination: inventory.Pagination{Limit: 2, Offset: 10}},
+		},
+		{
+			name:   "by_name",
+			params: inventory.SearchProductsParams{QueryString: "desk", OrderBy: inventory.ProductsByName, MinPrice: 200},
+		},
+		{
+			name:   "not_found",
+			params: inventory.SearchProductsParams{QueryString: "chair"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := db.SearchProducts(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("DB.SearchProducts() error = %v", err)
+			}
+			got, err := window.SearchProducts(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("DB.SearchProducts() with window total error = %v", err)
+			}
+			if !cmp.Equal(want, got) {
+				t.Errorf("DB.SearchProducts() with window total doesn't match: %v", cmp.Diff(want, got))
+			}
+		})
+	}
+	if _, err := window.SearchProducts(canceledContext(), tests[0].params); !errors.Is(err, context.Canceled) {
+		t.Errorf("DB.SearchProducts() with window total error = %v, want context canceled", err)
+	}
+}
+
+// BenchmarkSearchProducts compares counting the products found with a separate statement against WithWindowTotal.
+func BenchmarkSearchProducts(b *testing.B) {
+	migration := sqltest.New(b, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	var products []inventory.CreateProductParams
+	for i := range 2000 {
+		products = append(products, inventory.CreateProductParams{
+			ID:          fmt.Sprintf("product%d", i),
+			Name:        fmt.Sprintf("product %d", i),
+			Description: "A product",
+			Price:       i,
+		})
+	}
+	createProducts(b, db, products)
+	if _, err := pool.Exec(context.Background(), `ANALYZE "product"`); err != nil {
+		b.Fatalf("cannot analyze products: %v", err)
+	}
+
+	for _, bb := range []struct {
+		name string
+		db   DB
+	}{
+		{"two_statements", db},
+		{"window_total", NewDB(pool, slog.Default(), WithWindowTotal())},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			params := inventory.SearchProductsParams{
+				QueryString: "product",
+				Pagination:  inventory.Pagination{Limit: 20},
+			}
+			for range b.N {
+				if _, err := bb.db.SearchProducts(context.Background(), params); err != nil {
+					b.Fatalf("DB.SearchProducts() error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,685 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"golang.org/x/sync/errgroup"
+)
+
+// ShardedDB routes the operations of the inventory service to one of several databases (shards)
+// by the hash of the product ID, so data can outgrow a single database.
+//
+// Reviews, translations, favorites, views, and stock levels of a product live on the shard of the product.
+// Queries that aren't bound to a product, such as SearchProducts, are sent to all shards, and their results merged.
+// Suppliers and warehouses are reference data written to every shard, and read from the first one.
+//
+// Constraints across shards aren't enforced: slugs, SKUs, and GTINs are only unique within a shard,
+// quotas of tenants are enforced by each shard on its own products, and writes to multiple shards aren't atomic.
+// Routing depends on the number of shards, so adding one requires moving data between them.
+type ShardedDB struct {
+	shards []DB
+}
+
+var _ inventory.DB = ShardedDB{}
+
+// NewShardedDB creates a ShardedDB routing operations to the given shards.
+// The order of the shards must be kept, as it determines where each product lives.
+func NewShardedDB(shards ...DB) ShardedDB {
+	if len(shards) == 0 {
+		panic("postgres: no shards")
+	}
+	return ShardedDB{shards: shards}
+}
+
+// shardIndex of the product with the given ID.
+func (s ShardedDB) shardIndex(productID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(productID))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// shard of the product with the given ID.
+func (s ShardedDB) shard(productID string) DB {
+	return s.shards[s.shardIndex(productID)]
+}
+
+// gather the results of fn executed on every shard concurrently, in the order of the shards.
+// Once a shard fails, the calls to the other ones are canceled.
+func gather[T any](ctx context.Context, shards []DB, fn func(ctx context.Context, db DB) (T, error)) ([]T, error) {
+	results := make([]T, len(shards))
+	g, ctx := errgroup.WithContext(ctx)
+	for i, db := range shards {
+		g.Go(func() (err error) {
+			results[i], err = fn(ctx, db)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// first non-nil result of fn executed on every shard, such as to find a record by a key other than the product ID.
+func first[T any](ctx context.Context, shards []DB, fn func(ctx context.Context, db DB) (*T, error)) (*T, error) {
+	results, err := gather(ctx, shards, fn)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range results {
+		if v != nil {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+// broadcast a write of reference data to every shard, one after the other, returning the result of the first shard.
+// It stops on the first error, so a failure on the first shard, such as a conflict, doesn't modify the others.
+func broadcast[T any](ctx context.Context, shards []DB, fn func(ctx context.Context, db DB) (T, error)) (T, error) {
+	var v T
+	for i, db := range shards {
+		r, err := fn(ctx, db)
+		if err != nil {
+			return r, err
+		}
+		if i == 0 {
+			v = r
+		}
+	}
+	return v, nil
+}
+
+// shardPage is the pagination used to query each shard for a page of the merged results.
+// As any shard might hold all the results of the page, each one returns everything up to its end.
+func shardPage(p inventory.Pagination) inventory.Pagination {
+	if p.Limit == 0 {
+		return inventory.Pagination{}
+	}
+	return inventory.Pagination{Limit: p.Offset + p.Limit}
+}
+
+// mergePage merges the results of each shard, sorted by compare, and returns the requested page.
+// The shards must sort the results the same way: IDs compared byte by byte, with COLLATE "C".
+func mergePage[T any](results [][]T, p inventory.Pagination, compare func(a, b T) int) []T {
+	merged := slices.Concat(results...)
+	slices.SortStableFunc(merged, compare)
+	merged = merged[min(p.Offset, len(merged)):]
+	if p.Limit != 0 && len(merged) > p.Limit {
+		merged = merged[:p.Limit]
+	}
+	return merged
+}
+
+// newestFirst compares timestamps in descending order, breaking ties by ID in ascending order.
+func newestFirst(at, bt time.Time, aid, bid string) int {
+	if c := bt.Compare(at); c != 0 {
+		return c
+	}
+	return cmp.Compare(aid, bid)
+}
+
+// CreateProduct on the shard of the product.
+func (s ShardedDB) CreateProduct(ctx context.Context, params inventory.CreateProductParams) (*inventory.CreateProductResult, error) {
+	return s.shard(params.ID).CreateProduct(ctx, params)
+}
+
+// UpdateProduct on the shard of the product.
+func (s ShardedDB) UpdateProduct(ctx context.Context, params inventory.UpdateProductParams) (*inventory.Product, error) {
+	return s.shard(params.ID).UpdateProduct(ctx, params)
+}
+
+// GetProduct from the shard of the product.
+func (s ShardedDB) GetProduct(ctx context.Context, id string) (*inventory.Product, error) {
+	return s.shard(id).GetProduct(ctx, id)
+}
+
+// GetProductBySlug from the shard where it's found.
+func (s ShardedDB) GetProductBySlug(ctx context.Context, slug string) (*inventory.Product, error) {
+	return first(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.Product, error) {
+		return db.GetProductBySlug(ctx, slug)
+	})
+}
+
+// GetProductBySKU from the shard where it's found.
+func (s ShardedDB) GetProductBySKU(ctx context.Context, sku string) (*inventory.Product, error) {
+	return first(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.Product, error) {
+		return db.GetProductBySKU(ctx, sku)
+	})
+}
+
+// GetProducts from the shards of the products.
+func (s ShardedDB) GetProducts(ctx context.Context, ids []string) ([]*inventory.Product, error) {
+	byShard := make([][]string, len(s.shards))
+	for _, id := range ids {
+		i := s.shardIndex(id)
+		byShard[i] = append(byShard[i], id)
+	}
+	results := make([][]*inventory.Product, len(s.shards))
+	g, ctx := errgroup.WithContext(ctx)
+	for i, db := range s.shards {
+		if len(byShard[i]) == 0 {
+			continue
+		}
+		g.Go(func() (err error) {
+			results[i], err = db.GetProducts(ctx, byShard[i])
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return slices.Concat(results...), nil
+}
+
+// GetProductAt from the shard of the product.
+func (s ShardedDB) GetProductAt(ctx context.Context, id string, at time.Time) (*inventory.Product, error) {
+	return s.shard(id).GetProductAt(ctx, id, at)
+}
+
+// UpsertProductTranslation on the shard of the product.
+func (s ShardedDB) UpsertProductTranslation(ctx context.Context, params inventory.ProductTranslation) error {
+	return s.shard(params.ProductID).UpsertProductTranslation(ctx, params)
+}
+
+// DeleteProductTranslation on the shard of the product.
+func (s ShardedDB) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	return s.shard(productID).DeleteProductTranslation(ctx, productID, locale)
+}
+
+// GetProductTranslation from the shard of the product.
+func (s ShardedDB) GetProductTranslation(ctx context.Context, productID string, locales []string) (*inventory.ProductTranslation, error) {
+	return s.shard(productID).GetProductTranslation(ctx, productID, locales)
+}
+
+// ProductHistory from the shard of the product.
+func (s ShardedDB) ProductHistory(ctx context.Context, id string) ([]inventory.Version, error) {
+	return s.shard(id).ProductHistory(ctx, id)
+}
+
+// SearchProducts on all shards, merging their results.
+func (s ShardedDB) SearchProducts(ctx context.Context, params inventory.SearchProductsParams) (*inventory.SearchProductsResponse, error) {
+	shardParams := params
+	shardParams.Pagination = shardPage(params.Pagination)
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.SearchProductsResponse, error) {
+		return db.SearchProducts(ctx, shardParams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		total     int
+		estimated bool
+		items     = make([][]*inventory.Product, 0, len(results))
+		bands     []inventory.PriceBand
+	)
+	for _, r := range results {
+		total += r.Total
+		estimated = estimated || r.TotalIsEstimate
+		items = append(items, r.Items)
+		bands = mergePriceBands(bands, r.PriceBands)
+	}
+	order := func(a, b *inventory.Product) int {
+		return cmp.Compare(b.ID, a.ID)
+	}
+	if params.OrderBy == inventory.ProductsByName {
+		order = productNameOrder(params.Locales)
+	}
+	return &inventory.SearchProductsResponse{
+		Items:           mergePage(items, params.Pagination, order),
+		Total:           total,
+		TotalIsEstimate: estimated,
+		PriceBands:      bands,
+	}, nil
+}
+
+// errExported stops exporting products from the shards once the page of the export is done.
+var errExported = errors.New("products exported")
+
+// ExportProducts from each shard in turn, rather than merging the products of all shards,
+// so they're in the order of the search within each shard only.
+// The page of the export applies to the products of all shards.
+func (s ShardedDB) ExportProducts(ctx context.Context, params inventory.SearchProductsParams, fn func(*inventory.Product) error) error {
+	skip, left := params.Pagination.Offset, params.Pagination.Limit
+	shardParams := params
+	shardParams.Pagination = shardPage(params.Pagination)
+	for _, db := range s.shards {
+		err := db.ExportProducts(ctx, shardParams, func(p *inventory.Product) error {
+			switch {
+			case skip > 0:
+				skip--
+				return nil
+			case left == 0 && params.Pagination.Limit != 0:
+				return errExported
+			}
+			left--
+			return fn(p)
+		})
+		if err == errExported {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergePriceBands adds the counts of the price bands of b to the ones of a, both sorted by band.
+func mergePriceBands(a, b []inventory.PriceBand) []inventory.PriceBand {
+	if len(b) == 0 {
+		return a
+	}
+	merged := make([]inventory.PriceBand, 0, max(len(a), len(b)))
+	for len(a) != 0 || len(b) != 0 {
+		switch {
+		case len(b) == 0 || (len(a) != 0 && a[0].Band < b[0].Band):
+			merged, a = append(merged, a[0]), a[1:]
+		case len(a) == 0 || b[0].Band < a[0].Band:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, inventory.NewPriceBand(a[0].Band, a[0].Count+b[0].Count)), a[1:], b[1:]
+		}
+	}
+	return merged
+}
+
+// FindSimilarProducts on all shards, merging their results.
+func (s ShardedDB) FindSimilarProducts(ctx context.Context, params inventory.FindSimilarProductsParams) ([]inventory.SimilarProduct, error) {
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) ([]inventory.SimilarProduct, error) {
+		return db.FindSimilarProducts(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergePage(results, inventory.Pagination{Limit: params.Limit}, func(a, b inventory.SimilarProduct) int {
+		return cmp.Or(cmp.Compare(b.Similarity, a.Similarity), cmp.Compare(a.Product.ID, b.Product.ID))
+	}), nil
+}
+
+// DeleteProduct from the shard of the product, along with its reviews if forced.
+func (s ShardedDB) DeleteProduct(ctx context.Context, params inventory.DeleteProductParams) error {
+	return s.shard(params.ID).DeleteProduct(ctx, params)
+}
+
+// ListTenantProducts from all shards, merging their results.
+func (s ShardedDB) ListTenantProducts(ctx context.Context, params inventory.ListTenantProductsParams) ([]*inventory.Product, error) {
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) ([]*inventory.Product, error) {
+		return db.ListTenantProducts(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergePage(results, inventory.Pagination{Limit: params.Limit}, func(a, b *inventory.Product) int {
+		return cmp.Compare(a.ID, b.ID)
+	}), nil
+}
+
+// ApplyProductChanges on the shards of the products, in a transaction on each shard.
+// Changes on different shards aren't applied atomically.
+func (s ShardedDB) ApplyProductChanges(ctx context.Context, changes []inventory.ProductChange) error {
+	byShard := make([][]inventory.ProductChange, len(s.shards))
+	for _, c := range changes {
+		i := s.shardIndex(c.ID)
+		byShard[i] = append(byShard[i], c)
+	}
+	for i, shard := range byShard {
+		if len(shard) == 0 {
+			continue
+		}
+		if err := s.shards[i].ApplyProductChanges(ctx, shard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountProducts on all shards, up to limit in total.
+func (s ShardedDB) CountProducts(ctx context.Context, filter inventory.ProductFilter, limit int) (int, error) {
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) (int, error) {
+		return db.CountProducts(ctx, filter, limit)
+	})
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	for _, n := range results {
+		count += n
+	}
+	return min(count, limit), nil
+}
+
+// DeleteProducts from one shard after the other, up to limit in total, in a transaction on each shard.
+// Products on different shards aren't deleted atomically.
+func (s ShardedDB) DeleteProducts(ctx context.Context, filter inventory.ProductFilter, limit int) (*inventory.DeleteProductsResult, error) {
+	var resp inventory.DeleteProductsResult
+	for _, db := range s.shards {
+		if resp.Deleted == limit {
+			break
+		}
+		r, err := db.DeleteProducts(ctx, filter, limit-resp.Deleted)
+		if err != nil {
+			return nil, err
+		}
+		resp.Deleted += r.Deleted
+		resp.Reviews += r.Reviews
+	}
+	return &resp, nil
+}
+
+// GetProductStats from the shard of the product.
+func (s ShardedDB) GetProductStats(ctx context.Context, id string) (*inventory.ProductStats, error) {
+	return s.shard(id).GetProductStats(ctx, id)
+}
+
+// AddFavorite on the shard of the product.
+func (s ShardedDB) AddFavorite(ctx context.Context, params inventory.FavoriteParams) error {
+	return s.shard(params.ProductID).AddFavorite(ctx, params)
+}
+
+// RemoveFavorite from the shard of the product.
+func (s ShardedDB) RemoveFavorite(ctx context.Context, params inventory.FavoriteParams) error {
+	return s.shard(params.ProductID).RemoveFavorite(ctx, params)
+}
+
+// ListFavorites from all shards, merging their results.
+func (s ShardedDB) ListFavorites(ctx context.Context, params inventory.ListFavoritesParams) (*inventory.ListFavoritesResponse, error) {
+	shardParams := params
+	shardParams.Pagination = shardPage(params.Pagination)
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.ListFavoritesResponse, error) {
+		return db.ListFavorites(ctx, shardParams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		total     int
+		favorites = make([][]*inventory.Favorite, 0, len(results))
+	)
+	for _, r := range results {
+		total += r.Total
+		favorites = append(favorites, r.Favorites)
+	}
+	return &inventory.ListFavoritesResponse{
+		Favorites: mergePage(favorites, params.Pagination, func(a, b *inventory.Favorite) int {
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ProductID, b.ProductID)
+		}),
+		Total: total,
+	}, nil
+}
+
+// RecordProductView on the shard of the product.
+func (s ShardedDB) RecordProductView(ctx context.Context, id string) error {
+	return s.shard(id).RecordProductView(ctx, id)
+}
+
+// ListTrendingProducts isn't supported, as merging the results of the shards requires their view counts.
+func (s ShardedDB) ListTrendingProducts(context.Context, inventory.ListTrendingProductsParams) (*inventory.ListProductsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// RecordRecentlyViewed on the shard of the product.
+// Each shard keeps up to inventory.MaxRecentlyViewed products of a user.
+func (s ShardedDB) RecordRecentlyViewed(ctx context.Context, params inventory.RecentlyViewedParams) error {
+	return s.shard(params.ProductID).RecordRecentlyViewed(ctx, params)
+}
+
+// ListRecentlyViewed isn't supported, as merging the results of the shards requires their view times.
+func (s ShardedDB) ListRecentlyViewed(context.Context, inventory.ListRecentlyViewedParams) (*inventory.ListProductsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// ListRecentProducts from all shards, merging their results.
+func (s ShardedDB) ListRecentProducts(ctx context.Context, params inventory.ListRecentProductsParams) (*inventory.ListProductsResponse, error) {
+	shardParams := params
+	shardParams.Pagination = shardPage(params.Pagination)
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.ListProductsResponse, error) {
+		return db.ListRecentProducts(ctx, shardParams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeProducts(results, params.Pagination, func(a, b *inventory.Product) int {
+		return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+	}), nil
+}
+
+// mergeProducts listed by each shard.
+func mergeProducts(results []*inventory.ListProductsResponse, p inventory.Pagination, compare func(a, b *inventory.Product) int) *inventory.ListProductsResponse {
+	items := make([][]*inventory.Product, 0, len(results))
+	for _, r := range results {
+		items = append(items, r.Items)
+	}
+	return &inventory.ListProductsResponse{
+		Items: mergePage(items, p, compare),
+	}
+}
+
+// CreateSupplier on every shard.
+func (s ShardedDB) CreateSupplier(ctx context.Context, params inventory.CreateSupplierParams) (*inventory.Supplier, error) {
+	return broadcast(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.Supplier, error) {
+		return db.CreateSupplier(ctx, params)
+	})
+}
+
+// UpdateSupplier on every shard.
+func (s ShardedDB) UpdateSupplier(ctx context.Context, params inventory.UpdateSupplierParams) (*inventory.Supplier, error) {
+	return broadcast(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.Supplier, error) {
+		return db.UpdateSupplier(ctx, params)
+	})
+}
+
+// DeleteSupplier from every shard.
+func (s ShardedDB) DeleteSupplier(ctx context.Context, id string) error {
+	_, err := broadcast(ctx, s.shards, func(ctx context.Context, db DB) (struct{}, error) {
+		return struct{}{}, db.DeleteSupplier(ctx, id)
+	})
+	return err
+}
+
+// GetSupplier from the first shard.
+func (s ShardedDB) GetSupplier(ctx context.Context, id string) (*inventory.Supplier, error) {
+	return s.shards[0].GetSupplier(ctx, id)
+}
+
+// SetProductSupplier on the shard of the product.
+func (s ShardedDB) SetProductSupplier(ctx context.Context, params inventory.ProductSupplier) error {
+	return s.shard(params.ProductID).SetProductSupplier(ctx, params)
+}
+
+// RemoveProductSupplier from the shard of the product.
+func (s ShardedDB) RemoveProductSupplier(ctx context.Context, productID, supplierID string) error {
+	return s.shard(productID).RemoveProductSupplier(ctx, productID, supplierID)
+}
+
+// ListProductSuppliers from the shard of the product.
+func (s ShardedDB) ListProductSuppliers(ctx context.Context, productID string) ([]*inventory.ProductSupplier, error) {
+	return s.shard(productID).ListProductSuppliers(ctx, productID)
+}
+
+// ListSupplierProducts from all shards, merging their results.
+func (s ShardedDB) ListSupplierProducts(ctx context.Context, params inventory.ListSupplierProductsParams) (*inventory.ListProductsResponse, error) {
+	shardParams := params
+	shardParams.Pagination = shardPage(params.Pagination)
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.ListProductsResponse, error) {
+		return db.ListSupplierProducts(ctx, shardParams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeProducts(results, params.Pagination, func(a, b *inventory.Product) int {
+		return cmp.Compare(a.ID, b.ID)
+	}), nil
+}
+
+// CreateWarehouse on every shard.
+func (s ShardedDB) CreateWarehouse(ctx context.Context, params inventory.CreateWarehouseParams) (*inventory.Warehouse, error) {
+	return broadcast(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.Warehouse, error) {
+		return db.CreateWarehouse(ctx, params)
+	})
+}
+
+// ListWarehouses from the first shard.
+func (s ShardedDB) ListWarehouses(ctx context.Context) ([]*inventory.Warehouse, error) {
+	return s.shards[0].ListWarehouses(ctx)
+}
+
+// SetProductStock on the shard of the product.
+func (s ShardedDB) SetProductStock(ctx context.Context, params inventory.SetProductStockParams) error {
+	return s.shard(params.ProductID).SetProductStock(ctx, params)
+}
+
+// TransferStock on the shard of the product.
+func (s ShardedDB) TransferStock(ctx context.Context, params inventory.TransferStockParams) error {
+	return s.shard(params.ProductID).TransferStock(ctx, params)
+}
+
+// GetProductStock from the shard of the product.
+func (s ShardedDB) GetProductStock(ctx context.Context, productID string) ([]*inventory.StockLevel, error) {
+	return s.shard(productID).GetProductStock(ctx, productID)
+}
+
+// CreateProductReview on the shard of the product.
+func (s ShardedDB) CreateProductReview(ctx context.Context, params inventory.CreateProductReviewDBParams) error {
+	return s.shard(params.ProductID).CreateProductReview(ctx, params)
+}
+
+// reviewShard returns the shard holding the review with the given ID.
+// Reviews are looked up by their own ID rather than by the product ID, so every shard is queried.
+// If the review isn't found, the first shard is returned, so the behavior is the same as with a single database.
+func (s ShardedDB) reviewShard(ctx context.Context, id string) (DB, error) {
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.ProductReview, error) {
+		return db.GetProductReview(ctx, id)
+	})
+	if err != nil {
+		return DB{}, err
+	}
+	for i, r := range results {
+		if r != nil {
+			return s.shards[i], nil
+		}
+	}
+	return s.shards[0], nil
+}
+
+// UpdateProductReview on the shard holding the review.
+func (s ShardedDB) UpdateProductReview(ctx context.Context, params inventory.UpdateProductReviewParams) error {
+	db, err := s.reviewShard(ctx, params.ID)
+	if err != nil {
+		return err
+	}
+	return db.UpdateProductReview(ctx, params)
+}
+
+// GetProductReview from the shard where it's found.
+func (s ShardedDB) GetProductReview(ctx context.Context, id string) (*inventory.ProductReview, error) {
+	return first(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.ProductReview, error) {
+		return db.GetProductReview(ctx, id)
+	})
+}
+
+// GetProductReviews from the shard of the product, or from all shards when listing the reviews of a reviewer.
+func (s ShardedDB) GetProductReviews(ctx context.Context, params inventory.ProductReviewsParams) (*inventory.ProductReviewsResponse, error) {
+	if params.ProductID != "" {
+		return s.shard(params.ProductID).GetProductReviews(ctx, params)
+	}
+	shardParams := params
+	shardParams.Pagination = shardPage(params.Pagination)
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.ProductReviewsResponse, error) {
+		return db.GetProductReviews(ctx, shardParams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		total     int
+		estimated bool
+		reviews   = make([][]*inventory.ProductReview, 0, len(results))
+	)
+	for _, r := range results {
+		total += r.Total
+		estimated = estimated || r.TotalIsEstimate
+		reviews = append(reviews, r.Reviews)
+	}
+	return &inventory.ProductReviewsResponse{
+		Reviews: mergePage(reviews, params.Pagination, func(a, b *inventory.ProductReview) int {
+			if c := bySentiment(params.OrderBy, a.Sentiment, b.Sentiment); c != 0 {
+				return c
+			}
+			return newestFirst(a.CreatedAt, b.CreatedAt, a.ID, b.ID)
+		}),
+		Total:           total,
+		TotalIsEstimate: estimated,
+	}, nil
+}
+
+// bySentiment compares the sentiments of reviews in the given order, with the ones without a sentiment last.
+// Reviews are equal when not ordered by sentiment.
+func bySentiment(order inventory.ReviewOrder, a, b *float64) int {
+	if order != inventory.ReviewsByMostPositive && order != inventory.ReviewsByMostNegative {
+		return 0
+	}
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	case order == inventory.ReviewsByMostPositive:
+		return cmp.Compare(*b, *a)
+	default:
+		return cmp.Compare(*a, *b)
+	}
+}
+
+// CountReviewerReviews on all shards.
+func (s ShardedDB) CountReviewerReviews(ctx context.Context, reviewerID string, since time.Time) (int, error) {
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) (int, error) {
+		return db.CountReviewerReviews(ctx, reviewerID, since)
+	})
+	var n int
+	for _, r := range results {
+		n += r
+	}
+	return n, err
+}
+
+// ReviewHistory from the shard holding the review.
+func (s ShardedDB) ReviewHistory(ctx context.Context, id string) ([]inventory.Version, error) {
+	db, err := s.reviewShard(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return db.ReviewHistory(ctx, id)
+}
+
+// DeleteProductReview from the shard holding the review.
+func (s ShardedDB) DeleteProductReview(ctx context.Context, id string) error {
+	db, err := s.reviewShard(ctx, id)
+	if err != nil {
+		return err
+	}
+	return db.DeleteProductReview(ctx, id)
+}
+
+// PurgeReviewerData on all shards, each one in its own transaction.
+func (s ShardedDB) PurgeReviewerData(ctx context.Context, params inventory.PurgeReviewerDataParams) (*inventory.PurgeReviewerDataResult, error) {
+	results, err := gather(ctx, s.shards, func(ctx context.Context, db DB) (*inventory.PurgeReviewerDataResult, error) {
+		return db.PurgeReviewerData(ctx, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resp inventory.PurgeReviewerDataResult
+	for _, r := range results {
+		resp.Reviews += r.Reviews
+	}
+	return &resp, nil
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestMergePage(t *testing.T) {
+	t.Parallel()
+	results := [][]int{{9, 5, 1}, {8, 7}, {}, {6, 2}}
+	desc := func(a, b int) int { return b - a }
+	tests := []struct {
+		name string
+		p    inventory.Pagination
+		want []int
+	}{
+		{"all", inventory.Pagination{}, []int{9, 8, 7, 6, 5, 2, 1}},
+		{"first page", inventory.Pagination{Limit: 3}, []int{9, 8, 7}},
+		{"second page", inventory.Pagination{Limit: 3, Offset: 3}, []int{6, 5, 2}},
+		{"last page", inventory.Pagination{Limit: 3, Offset: 6}, []int{1}},
+		{"past the end", inventory.Pagination{Limit: 3, Offset: 9}, []int{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := mergePage(results, tc.p, desc); !cmp.Equal(tc.want, got) {
+				t.Errorf("mergePage() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMergePriceBands(t *testing.T) {
+	t.Parallel()
+	var bands []inventory.PriceBand
+	for _, shard := range [][]inventory.PriceBand{
+		{inventory.NewPriceBand(0, 3), inventory.NewPriceBand(2, 1)},
+		nil,
+		{inventory.NewPriceBand(1, 2), inventory.NewPriceBand(2, 4), inventory.NewPriceBand(4, 1)},
+	} {
+		bands = mergePriceBands(bands, shard)
+	}
+	want := []inventory.PriceBand{
+		{Band: 0, MinPrice: 0, MaxPrice: 999, Count: 3},
+		{Band: 1, MinPrice: 1000, MaxPrice: 4999, Count: 2},
+		{Band: 2, MinPrice: 5000, MaxPrice: 9999, Count: 5},
+		{Band: 4, MinPrice: 50000, MaxPrice: 0, Count: 1},
+	}
+	if !cmp.Equal(want, bands) {
+		t.Errorf("mergePriceBands() doesn't match: %v", cmp.Diff(want, bands))
+	}
+	if got := mergePriceBands(nil, nil); got != nil {
+		t.Errorf("mergePriceBands(nil, nil) = %v, want nil", got)
+	}
+}
+
+func TestShardedDB(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pool := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	}).Setup(ctx, "")
+
+	// Each test gets its own database, so the second shard is created by a subtest.
+	t.Run("shards", func(t *testing.T) {
+		other := sqltest.New(t, sqltest.Options{
+			Force: *force,
+			Files: os.DirFS("../../migrations"),
+		}).Setup(ctx, "")
+		shards := []DB{NewDB(pool, slog.Default()), NewDB(other, slog.Default())}
+		db := NewShardedDB(shards...)
+
+		// Find a product ID for each shard.
+		ids := make([]string, len(shards))
+		for i := 0; ids[0] == "" || ids[1] == ""; i++ {
+			id := "product" + string(rune('a'+i))
+			if n := db.shardIndex(id); ids[n] == "" {
+				ids[n] = id
+			}
+		}
+		for i, id := range ids {
+			if _, err := db.CreateProduct(ctx, inventory.CreateProductParams{
+				ID:          id,
+				Name:        "table " + id,
+				Description: "A table",
+				Price:       100 + i,
+			}); err != nil {
+				t.Fatalf("ShardedDB.CreateProduct() error = %v", err)
+			}
+			if err := db.CreateProductReview(ctx, inventory.CreateProductReviewDBParams{
+				ID: "review" + id,
+				CreateProductReviewParams: inventory.CreateProductReviewParams{
+					ProductID:   id,
+					ReviewerID:  "reviewer",
+					Score:       5,
+					Title:       "Good",
+					Description: "Good table",
+				},
+			}); err != nil {
+				t.Fatalf("ShardedDB.CreateProductReview() error = %v", err)
+			}
+		}
+
+		// Each product and its review is on its own shard only.
+		for i, id := range ids {
+			for j, shard := range shards {
+				p, err := shard.GetProduct(ctx, id)
+				if err != nil {
+					t.Fatalf("DB.GetProduct() error = %v", err)
+				}
+				r, err := shard.GetProductReview(ctx, "review"+id)
+				if err != nil {
+					t.Fatalf("DB.GetProductReview() error = %v", err)
+				}
+				if found := i == j; (p != nil) != found || (r != nil) != found {
+					t.Errorf("product %q and its review found on shard %d = %v, %v", id, j, p != nil, r != nil)
+				}
+			}
+		}
+
+		resp, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+			QueryString: "table",
+			Pagination:  inventory.Pagination{Limit: 1, Offset: 1},
+		})
+		if err != nil {
+			t.Fatalf("ShardedDB.SearchProducts() error = %v", err)
+		}
+		// Products are sorted by ID in descending order.
+		want := min(ids[0], ids[1])
+		if resp.Total != 2 || len(resp.Items) != 1 || resp.Items[0].ID != want {
+			t.Errorf("ShardedDB.SearchProducts() = %+v, want total 2 and %q", resp, want)
+		}
+
+		reviews, err := db.GetProductReviews(ctx, inventory.ProductReviewsParams{
+			ReviewerID: "reviewer",
+			Pagination: inventory.Pagination{Limit: 10},
+		})
+		if err != nil {
+			t.Fatalf("ShardedDB.GetProductReviews() error = %v", err)
+		}
+		if reviews.Total != 2 || len(reviews.Reviews) != 2 {
+			t.Errorf("ShardedDB.GetProductReviews() = %+v, want 2 reviews", reviews)
+		}
+		if err := db.DeleteProductReview(ctx, "review"+ids[1]); err != nil {
+			t.Errorf("ShardedDB.DeleteProductReview() error = %v", err)
+		}
+		if r, err := db.GetProductReview(ctx, "review"+ids[1]); r != nil || err != nil {
+			t.Errorf("ShardedDB.GetProductReview() = (%v, %v), want (nil, nil)", r, err)
+		}
+
+		// Reference data is written to every shard.
+		if _, err := db.CreateWarehouse(ctx, inventory.CreateWarehouseParams{ID: "main", Name: "Main"}); err != nil {
+			t.Fatalf("ShardedDB.CreateWarehouse() error = %v", err)
+		}
+		for _, id := range ids {
+			if err := db.SetProductStock(ctx, inventory.SetProductStockParams{
+				ProductID:   id,
+				WarehouseID: "main",
+				Quantity:    3,
+			}); err != nil {
+				t.Errorf("ShardedDB.SetProductStock() error = %v", err)
+			}
+		}
+		if _, err := db.ListTrendingProducts(ctx, inventory.ListTrendingProductsParams{}); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("ShardedDB.ListTrendingProducts() error = %v, want %v", err, errors.ErrUnsupported)
+		}
+	})
+}
+
+func TestSearchProductsIDByteOrder(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pool := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	}).Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+	for _, id := range []string{"a", "B", "c", "D"} {
+		createProducts(t, db, []inventory.CreateProductParams{{ID: id, Name: "table " + id, Description: "A table"}})
+	}
+
+	// IDs are compared byte by byte, like mergePage does, rather than by the collation of the database.
+	resp, err := db.SearchProducts(ctx, inventory.SearchProductsParams{
+		QueryString: "table",
+		Pagination:  inventory.Pagination{Limit: 10},
+	})
+	if err != nil {
+		t.Fatalf("DB.SearchProducts() error = %v", err)
+	}
+	var got []string
+	for _, p := range resp.Items {
+		got = append(got, p.ID)
+	}
+	if want := []string{"c", "a", "D", "B"}; !slices.Equal(got, want) {
+		t.Errorf("DB.SearchProducts() IDs = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/henvic/pgtools"
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5"
+)
+
+type similarProduct struct {
+	product
+	Similarity float64
+}
+
+// FindSimilarProducts returns the products of params.Tenant with a name similar to params.Name by trigrams,
+// with a similarity of at least params.Threshold, the most similar first.
+//
+// The similarity threshold of the % operator is set for the transaction, so the product_name_trgm index is used.
+func (db DB) FindSimilarProducts(ctx context.Context, params inventory.FindSimilarProductsParams) ([]inventory.SimilarProduct, error) {
+	sql := fmt.Sprintf(`SELECT %s, similarity("name", $1) AS "similarity" FROM "product"
+	WHERE "name" %% $1 AND similarity("name", $1) >= $2 AND CASE
+		WHEN $3::text = '' THEN strpos("id", $4::text) = 0
+		ELSE starts_with("id", $3::text || $4::text)
+	END
+	ORDER BY "similarity" DESC, "id" COLLATE "C"
+	LIMIT $5`, pgtools.Wildcard(product{})) // #nosec G201
+	args := []any{params.Name, params.Threshold, params.Tenant, inventory.TenantSeparator, params.Limit}
+	db.explain(ctx, "FindSimilarProducts", sql, args...)
+	products, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]similarProduct, error) {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback(ctx) // Read-only, so there's nothing to commit.
+		threshold := strconv.FormatFloat(params.Threshold, 'f', -1, 64)
+		if _, err := tx.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1, true)`, threshold); err != nil {
+			return nil, err
+		}
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[similarProduct])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot find similar products on database", slog.Any("error", err))
+		return nil, infraError("cannot find similar products on database", err)
+	}
+	similar := make([]inventory.SimilarProduct, 0, len(products))
+	for _, p := range products {
+		similar = append(similar, inventory.SimilarProduct{
+			Product:    p.dto(),
+			Similarity: p.Similarity,
+		})
+	}
+	return similar, nil
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestFindSimilarProducts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{ID: "acme:desk", Name: "Oak office desk", Description: "A desk", Price: 100},
+		{ID: "acme:desk2", Name: "Office desk, oak", Description: "A desk", Price: 100},
+		{ID: "acme:chair", Name: "Office chair", Description: "A chair", Price: 40},
+		{ID: "other:desk", Name: "Oak office desk", Description: "A desk", Price: 100},
+		{ID: "desk", Name: "Oak office desk", Description: "A desk", Price: 100},
+	})
+	find := func(tenant string, threshold float64) []string {
+		t.Helper()
+		similar, err := db.FindSimilarProducts(ctx, inventory.FindSimilarProductsParams{
+			Tenant:    tenant,
+			Name:      "oak office desks",
+			Threshold: threshold,
+			Limit:     10,
+		})
+		if err != nil {
+			t.Fatalf("DB.FindSimilarProducts() error = %v", err)
+		}
+		var ids []string
+		for i, sp := range similar {
+			if sp.Similarity < threshold || sp.Similarity > 1 || i > 0 && sp.Similarity > similar[i-1].Similarity {
+				t.Errorf("DB.FindSimilarProducts() has unexpected similarity %v for %q", sp.Similarity, sp.Product.ID)
+			}
+			ids = append(ids, sp.Product.ID)
+		}
+		return ids
+	}
+	if got, want := find("acme", 0.6), []string{"acme:desk", "acme:desk2"}; !slices.Equal(got, want) {
+		t.Errorf("DB.FindSimilarProducts() = %v, want %v", got, want)
+	}
+	if got, want := find("acme", 0.2), []string{"acme:desk", "acme:desk2", "acme:chair"}; !slices.Equal(got, want) {
+		t.Errorf("DB.FindSimilarProducts() with a lower threshold = %v, want %v", got, want)
+	}
+	if got, want := find("", 0.6), []string{"desk"}; !slices.Equal(got, want) {
+		t.Errorf("DB.FindSimilarProducts() without tenant = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestProductSKU(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "table",
+			Name:        "Table",
+			Description: "An oak table",
+			Price:       300,
+			SKU:         "TBL_01",
+			GTIN:        "036000291452",
+		},
+		{
+			ID:          "chair",
+			Name:        "Chair",
+			Description: "An oak chair",
+			Price:       80,
+			SKU:         "TBLX01",
+		},
+		{
+			ID:          "lamp",
+			Name:        "Lamp",
+			Description: "A lamp without SKU",
+			Price:       20,
+		},
+	})
+
+	if _, err := db.CreateProduct(context.Background(), inventory.CreateProductParams{
+		ID:          "another",
+		Name:        "Another table",
+		Description: "Same SKU",
+		Price:       300,
+		SKU:         "TBL_01",
+	}); err != inventory.ErrDuplicateSKU {
+		t.Errorf("DB.CreateProduct() error = %v, want %v", err, inventory.ErrDuplicateSKU)
+	}
+	// The GTIN-13 form of a GTIN-12 is the same GTIN.
+	if _, err := db.UpdateProduct(context.Background(), inventory.UpdateProductParams{
+		ID:   "lamp",
+		GTIN: ptr("0036000291452"),
+	}); err != inventory.ErrDuplicateGTIN {
+		t.Errorf("DB.UpdateProduct() error = %v, want %v", err, inventory.ErrDuplicateGTIN)
+	}
+
+	got, err := db.GetProductBySKU(context.Background(), "TBL_01")
+	if err != nil {
+		t.Fatalf("DB.GetProductBySKU() error = %v", err)
+	}
+	if got == nil || got.ID != "table" || got.SKU != "TBL_01" || got.GTIN != "036000291452" {
+		t.Errorf("DB.GetProductBySKU() = %v, want product table", got)
+	}
+	if got, err := db.GetProductBySKU(context.Background(), "NOT_FOUND"); got != nil || err != nil {
+		t.Errorf("DB.GetProductBySKU() = %v, %v, want nil", got, err)
+	}
+
+	// The underscore in the prefix is matched literally rather than as a LIKE wildcard.
+	res, err := db.SearchProducts(context.Background(), inventory.SearchProductsParams{SKUPrefix: "TBL_"})
+	if err != nil {
+		t.Fatalf("DB.SearchProducts() error = %v", err)
+	}
+	var ids []string
+	for _, p := range res.Items {
+		ids = append(ids, p.ID)
+	}
+	if want := []string{"table"}; !cmp.Equal(want, ids) || res.Total != 1 {
+		t.Errorf("DB.SearchProducts() = %v (total %d), want %v", ids, res.Total, want)
+	}
+
+	// An empty SKU removes it, leaving the GTIN unchanged.
+	updated, err := db.UpdateProduct(context.Background(), inventory.UpdateProductParams{ID: "table", SKU: ptr("")})
+	if err != nil {
+		t.Fatalf("DB.UpdateProduct() error = %v", err)
+	}
+	if updated.SKU != "" || updated.GTIN != "036000291452" {
+		t.Errorf("DB.UpdateProduct() = %v, want product without SKU", updated)
+	}
+	if got, err := db.GetProductBySKU(context.Background(), "TBL_01"); got != nil || err != nil {
+		t.Errorf("DB.GetProductBySKU() = %v, %v, want nil", got, err)
+	}
+}
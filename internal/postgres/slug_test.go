@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestProductSlug(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "cafe",
+			Name:        "Café com leite",
+			Description: "Coffee with milk",
+			Price:       5,
+		},
+		{
+			ID:          "latte",
+			Name:        "Café com leite",
+			Description: "Also coffee with milk",
+			Price:       6,
+		},
+		{
+			ID:          "symbols",
+			Name:        "!!!",
+			Description: "Nothing to slugify",
+			Price:       1,
+		},
+	})
+
+	rename := func(id, name string) {
+		t.Helper()
+		if _, err := db.UpdateProduct(context.Background(), inventory.UpdateProductParams{ID: id, Name: &name}); err != nil {
+			t.Fatalf("DB.UpdateProduct(%q) error = %v", id, err)
+		}
+	}
+	check := func(slug, wantID, wantSlug string) {
+		t.Helper()
+		got, err := db.GetProductBySlug(context.Background(), slug)
+		if err != nil {
+			t.Fatalf("DB.GetProductBySlug(%q) error = %v", slug, err)
+		}
+		if wantID == "" {
+			if got != nil {
+				t.Errorf("DB.GetProductBySlug(%q) = %v, want nil", slug, got)
+			}
+			return
+		}
+		if got == nil || got.ID != wantID || got.Slug != wantSlug {
+			t.Errorf("DB.GetProductBySlug(%q) = %v, want product %q with slug %q", slug, got, wantID, wantSlug)
+		}
+	}
+
+	check("cafe-com-leite", "cafe", "cafe-com-leite")
+	check("cafe-com-leite-2", "latte", "cafe-com-leite-2")
+	check("product", "symbols", "product")
+	check("not-found", "", "")
+
+	// Renaming a product keeps its previous slug as a redirect.
+	rename("latte", "Latte")
+	check("latte", "latte", "latte")
+	check("cafe-com-leite-2", "latte", "latte")
+
+	// A name with the same base slug keeps the current slug.
+	rename("cafe", "Café com leite!")
+	check("cafe-com-leite", "cafe", "cafe-com-leite")
+
+	// A slug redirecting to another product isn't taken by a new product.
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "another",
+			Name:        "Café com leite",
+			Description: "Yet another coffee with milk",
+			Price:       7,
+		},
+	})
+	check("cafe-com-leite-3", "another", "cafe-com-leite-3")
+
+	// A product renamed back reclaims its previous slug.
+	rename("latte", "Café com leite")
+	check("cafe-com-leite-2", "latte", "cafe-com-leite-2")
+	check("latte", "latte", "cafe-com-leite-2")
+
+	check("", "", "")
+}
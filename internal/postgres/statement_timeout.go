@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+)
+
+// statementTimeout derives a PostgreSQL statement_timeout value from the context deadline.
+// It returns false if the context has no deadline.
+func statementTimeout(ctx context.Context) (string, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return "", false
+	}
+	// A statement_timeout of 0 disables the timeout, so round it up to at least 1ms.
+	// If the deadline was already exceeded, the statement fails with the context error anyway.
+	ms := time.Until(deadline).Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10), true
+}
+
+// setStatementTimeout sets the statement_timeout matching the context deadline, if any,
+// so PostgreSQL aborts work the client has already given up on.
+//
+// If local is true, the setting only lasts until the end of the current transaction (SET LOCAL).
+// Otherwise, it lasts for the rest of the session and must be reset before returning the connection to the pool.
+func setStatementTimeout(ctx context.Context, conn database.PGXQuerier, local bool) (bool, error) {
+	timeout, ok := statementTimeout(ctx)
+	if !ok {
+		return false, nil
+	}
+	if _, err := conn.Exec(ctx, `SELECT set_config('statement_timeout', $1, $2)`, timeout, local); err != nil {
+		return false, err
+	}
+	return true, nil
+}
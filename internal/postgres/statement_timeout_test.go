@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgtools/sqltest"
+)
+
+func TestStatementTimeout(t *testing.T) {
+	t.Parallel()
+	if _, ok := statementTimeout(context.Background()); ok {
+		t.Error("statementTimeout() should return false for a context without deadline")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, ok := statementTimeout(ctx)
+	if !ok {
+		t.Fatal("statementTimeout() should return true for a context with deadline")
+	}
+	if ms, err := strconv.Atoi(got); err != nil || ms < 4000 || ms > 5000 {
+		t.Errorf("statementTimeout() = %v, want value close to 5000", got)
+	}
+
+	if got, _ := statementTimeout(deadlineExceededContext()); got != "1" {
+		t.Errorf("statementTimeout() = %v, want 1 for exceeded deadline", got)
+	}
+}
+
+func TestTransactionContextStatementTimeout(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	txCtx, err := db.TransactionContext(ctx)
+	if err != nil {
+		t.Fatalf("cannot create transaction context: %v", err)
+	}
+	defer db.Rollback(txCtx)
+
+	var timeout string
+	if err := db.conn(txCtx).QueryRow(txCtx, "SHOW statement_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("cannot get statement_timeout: %v", err)
+	}
+	if timeout == "0" {
+		t.Error("expected statement_timeout to be set for transaction")
+	}
+	if err := db.Commit(txCtx); err != nil {
+		t.Errorf("cannot commit: %v", err)
+	}
+}
+
+func TestWithAcquireStatementTimeout(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	dbCtx, err := db.WithAcquire(ctx)
+	if err != nil {
+		t.Fatalf("unexpected DB.WithAcquire() error = %v", err)
+	}
+	var timeout string
+	if err := db.conn(dbCtx).QueryRow(dbCtx, "SHOW statement_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("cannot get statement_timeout: %v", err)
+	}
+	if timeout == "0" {
+		t.Error("expected statement_timeout to be set for acquired connection")
+	}
+	db.Release(dbCtx)
+
+	// Connections returned to the pool must not keep the statement_timeout.
+	for _, c := range pool.AcquireAllIdle(context.Background()) {
+		if err := c.QueryRow(context.Background(), "SHOW statement_timeout").Scan(&timeout); err != nil {
+			t.Errorf("cannot get statement_timeout: %v", err)
+		}
+		if timeout != "0" {
+			t.Errorf("expected statement_timeout to be reset, got %v instead", timeout)
+		}
+		c.Release()
+	}
+}
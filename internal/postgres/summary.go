@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/summary"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var _ summary.Store = (*DB)(nil) // Check if methods expected by summary.Store are implemented correctly.
+
+// reviewSummary of the review_summary table.
+type reviewSummary struct {
+	Summary     string
+	Reviews     int
+	GeneratedAt time.Time
+}
+
+// withReviewSummary sets the review summary of the product, if one was generated.
+func (db DB) withReviewSummary(ctx context.Context, p *inventory.Product) (*inventory.Product, error) {
+	const sql = `SELECT "summary", "reviews", "generated_at" FROM "review_summary" WHERE "product_id" = $1`
+	s, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (reviewSummary, error) {
+		rows, err := conn.Query(ctx, sql, p.ID)
+		if err != nil {
+			return reviewSummary{}, err
+		}
+		return pgx.CollectOneRow(rows, pgx.RowToStructByName[reviewSummary])
+	})
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return p, nil
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UndefinedTable:
+		// The review_summary table is created by a migration within the accepted schema window.
+		return p, nil
+	case err != nil:
+		db.log.Error("cannot get review summary from database",
+			slog.String("product", p.ID),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get review summary from database", err)
+	}
+	p.ReviewSummary = &inventory.ReviewSummary{
+		Text:        s.Summary,
+		Reviews:     s.Reviews,
+		GeneratedAt: s.GeneratedAt,
+	}
+	return p, nil
+}
+
+// withDetails sets the fields only set when getting a single product.
+func (db DB) withDetails(ctx context.Context, p *inventory.Product) (*inventory.Product, error) {
+	p, err := db.withAvailability(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return db.withReviewSummary(ctx, p)
+}
+
+// OutdatedReviewSummaries returns up to limit products whose review summary is missing or outdated,
+// as reviews were created, modified, or deleted since it was generated.
+func (db DB) OutdatedReviewSummaries(ctx context.Context, limit int) ([]summary.Product, error) {
+	const sql = `WITH "r" AS (
+		SELECT "product_id", count(*)::int AS "reviews", max("modified_at") AS "reviews_modified_at"
+		FROM "review" GROUP BY "product_id"
+	)
+	SELECT COALESCE("r"."product_id", "s"."product_id") AS "id", COALESCE("r"."reviews", 0) AS "reviews",
+		COALESCE("r"."reviews_modified_at", "s"."reviews_modified_at") AS "reviews_modified_at"
+	FROM "r" FULL JOIN "review_summary" "s" ON "s"."product_id" = "r"."product_id"
+	WHERE "s"."product_id" IS NULL OR "r"."product_id" IS NULL
+		OR "r"."reviews" <> "s"."reviews" OR "r"."reviews_modified_at" > "s"."reviews_modified_at"
+	ORDER BY 1
+	LIMIT $1`
+	db.explain(ctx, "OutdatedReviewSummaries", sql, limit)
+	products, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]summary.Product, error) {
+		rows, err := conn.Query(ctx, sql, limit)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[summary.Product])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot get outdated review summaries from database", slog.Any("error", err))
+		return nil, infraError("cannot get outdated review summaries from database", err)
+	}
+	return products, nil
+}
+
+// SaveReviewSummary of a product, or deletes it if the product has no reviews.
+// The summary of a product deleted meanwhile is discarded.
+func (db DB) SaveReviewSummary(ctx context.Context, p summary.Product, text string) error {
+	const (
+		upsert = `INSERT INTO "review_summary" ("product_id", "summary", "reviews", "reviews_modified_at")
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT ("product_id") DO UPDATE SET
+	"summary" = EXCLUDED."summary",
+	"reviews" = EXCLUDED."reviews",
+	"reviews_modified_at" = EXCLUDED."reviews_modified_at",
+	"generated_at" = now()`
+		del = `DELETE FROM "review_summary" WHERE "product_id" = $1`
+	)
+	var err error
+	if p.Reviews > 0 {
+		_, err = db.conn(ctx).Exec(ctx, upsert, p.ID, text, p.Reviews, p.ReviewsModifiedAt)
+	} else {
+		_, err = db.conn(ctx).Exec(ctx, del, p.ID)
+	}
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation:
+		return nil
+	case err != nil:
+		db.log.Error("cannot save review summary on database",
+			slog.String("product", p.ID),
+			slog.Any("error", err),
+		)
+		return infraError("cannot save review summary on database", err)
+	}
+	return nil
+}
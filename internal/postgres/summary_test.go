@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/summary"
+)
+
+func TestReviewSummaries(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{ID: "desk", Name: "Desk", Description: "A desk", Price: 140},
+		{ID: "chair", Name: "Chair", Description: "A chair", Price: 40},
+	})
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "r1",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "desk",
+				ReviewerID:  "alice",
+				Score:       5,
+				Title:       "Sturdy",
+				Description: "A sturdy desk",
+			},
+		},
+		{
+			ID: "r2",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "desk",
+				ReviewerID:  "bob",
+				Score:       2,
+				Title:       "Scratches easily",
+				Description: "The top scratches easily",
+			},
+		},
+	})
+
+	job := &summary.Job{
+		Store:      db,
+		Summarizer: summary.Scores{},
+		BatchSize:  10,
+		Log:        slog.Default(),
+	}
+	if n, err := job.RunOnce(ctx); n != 1 || err != nil {
+		t.Fatalf("Job.RunOnce() = (%d, %v), want (1, nil)", n, err)
+	}
+	p, err := db.GetProduct(ctx, "desk")
+	if err != nil {
+		t.Fatalf("DB.GetProduct() error = %v", err)
+	}
+	want := "Rated 3.5 out of 5 by 2 reviews. Pros: Sturdy. Cons: Scratches easily."
+	if p.ReviewSummary == nil || p.ReviewSummary.Text != want || p.ReviewSummary.Reviews != 2 {
+		t.Errorf("DB.GetProduct() review summary = %+v, want %q of 2 reviews", p.ReviewSummary, want)
+	}
+	if p, err := db.GetProduct(ctx, "chair"); err != nil {
+		t.Errorf("DB.GetProduct() error = %v", err)
+	} else if p.ReviewSummary != nil {
+		t.Errorf("DB.GetProduct() review summary = %+v, want none", p.ReviewSummary)
+	}
+
+	// Summaries are only generated again once outdated.
+	if n, err := job.RunOnce(ctx); n != 0 || err != nil {
+		t.Fatalf("Job.RunOnce() = (%d, %v), want (0, nil)", n, err)
+	}
+	if err := db.DeleteProductReview(ctx, "r2"); err != nil {
+		t.Fatalf("DB.DeleteProductReview() error = %v", err)
+	}
+	if n, err := job.RunOnce(ctx); n != 1 || err != nil {
+		t.Fatalf("Job.RunOnce() = (%d, %v), want (1, nil)", n, err)
+	}
+	if p, err := db.GetProduct(ctx, "desk"); err != nil {
+		t.Errorf("DB.GetProduct() error = %v", err)
+	} else if p.ReviewSummary == nil || p.ReviewSummary.Reviews != 1 {
+		t.Errorf("DB.GetProduct() review summary = %+v, want 1 review", p.ReviewSummary)
+	}
+
+	// The summary of a product without reviews is deleted.
+	if err := db.DeleteProductReview(ctx, "r1"); err != nil {
+		t.Fatalf("DB.DeleteProductReview() error = %v", err)
+	}
+	if n, err := job.RunOnce(ctx); n != 1 || err != nil {
+		t.Fatalf("Job.RunOnce() = (%d, %v), want (1, nil)", n, err)
+	}
+	if p, err := db.GetProduct(ctx, "desk"); err != nil {
+		t.Errorf("DB.GetProduct() error = %v", err)
+	} else if p.ReviewSummary != nil {
+		t.Errorf("DB.GetProduct() review summary = %+v, want none", p.ReviewSummary)
+	}
+}
@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgtools"
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// supplier table.
+type supplier struct {
+	ID         string
+	Name       string
+	Email      string
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+func (s *supplier) dto() *inventory.Supplier {
+	return &inventory.Supplier{
+		ID:         s.ID,
+		Name:       s.Name,
+		Email:      s.Email,
+		CreatedAt:  s.CreatedAt,
+		ModifiedAt: s.ModifiedAt,
+	}
+}
+
+// CreateSupplier creates a new supplier.
+func (db DB) CreateSupplier(ctx context.Context, params inventory.CreateSupplierParams) (*inventory.Supplier, error) {
+	sql := fmt.Sprintf(`INSERT INTO "supplier" ("id", "name", "email") VALUES ($1, $2, $3)
+	RETURNING %s`, pgtools.Wildcard(supplier{})) // #nosec G201
+	rows, err := db.conn(ctx).Query(ctx, sql, params.ID, params.Name, params.Email)
+	var s supplier
+	if err == nil {
+		s, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[supplier])
+	}
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == "supplier_pkey":
+		return nil, inventory.ErrSupplierExists
+	case err != nil:
+		db.log.Error("cannot create supplier on database", slog.Any("error", err))
+		return nil, infraError("cannot create supplier on database", err)
+	}
+	return s.dto(), nil
+}
+
+// UpdateSupplier updates an existing supplier.
+func (db DB) UpdateSupplier(ctx context.Context, params inventory.UpdateSupplierParams) (*inventory.Supplier, error) {
+	sql := fmt.Sprintf(`UPDATE "supplier" SET
+	"name" = COALESCE($1, "name"),
+	"email" = COALESCE($2, "email"),
+	"modified_at" = now()
+	WHERE "id" = $3
+	RETURNING %s`, pgtools.Wildcard(supplier{})) // #nosec G201
+	rows, err := db.conn(ctx).Query(ctx, sql, params.Name, params.Email, params.ID)
+	var s supplier
+	if err == nil {
+		s, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[supplier])
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, inventory.ErrSupplierNotFound
+	case err != nil:
+		db.log.Error("cannot update supplier on database", slog.Any("error", err))
+		return nil, infraError("cannot update supplier on database", err)
+	}
+	return s.dto(), nil
+}
+
+// DeleteSupplier deletes a supplier.
+// Its relations to products are deleted by ON DELETE CASCADE, as they're meaningless without the supplier.
+func (db DB) DeleteSupplier(ctx context.Context, id string) error {
+	const sql = `DELETE FROM "supplier" WHERE "id" = $1`
+	ct, err := db.conn(ctx).Exec(ctx, sql, id)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot delete supplier from database", slog.Any("error", err))
+		return infraError("cannot delete supplier from database", err)
+	case ct.RowsAffected() == 0:
+		return inventory.ErrSupplierNotFound
+	}
+	return nil
+}
+
+// GetSupplier returns a supplier.
+func (db DB) GetSupplier(ctx context.Context, id string) (*inventory.Supplier, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "supplier" WHERE "id" = $1 LIMIT 1`, pgtools.Wildcard(supplier{})) // #nosec G201
+	s, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (supplier, error) {
+		rows, err := conn.Query(ctx, sql, id)
+		if err != nil {
+			return supplier{}, err
+		}
+		return pgx.CollectOneRow(rows, pgx.RowToStructByName[supplier])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		db.log.Error("cannot get supplier from database",
+			slog.Any("id", id),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get supplier from database", err)
+	}
+	return s.dto(), nil
+}
+
+// SetProductSupplier adds a supplier to a product, or replaces its lead time and cost if it's already there.
+func (db DB) SetProductSupplier(ctx context.Context, params inventory.ProductSupplier) error {
+	const sql = `INSERT INTO "product_supplier" ("product_id", "supplier_id", "lead_time_days", "cost")
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT ("product_id", "supplier_id") DO UPDATE SET
+	"lead_time_days" = EXCLUDED."lead_time_days",
+	"cost" = EXCLUDED."cost",
+	"modified_at" = now()`
+	days := int(params.LeadTime / (24 * time.Hour))
+	_, err := db.conn(ctx).Exec(ctx, sql, params.ProductID, params.SupplierID, days, params.Cost)
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation && pgErr.ConstraintName == "product_supplier_product_id_fkey":
+		return inventory.ErrSupplierNoProduct
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation && pgErr.ConstraintName == "product_supplier_supplier_id_fkey":
+		return inventory.ErrSupplierNotFound
+	case err != nil:
+		db.log.Error("cannot set product supplier on database", slog.Any("error", err))
+		return infraError("cannot set product supplier on database", err)
+	}
+	return nil
+}
+
+// RemoveProductSupplier removes a supplier from a product.
+func (db DB) RemoveProductSupplier(ctx context.Context, productID, supplierID string) error {
+	const sql = `DELETE FROM "product_supplier" WHERE "product_id" = $1 AND "supplier_id" = $2`
+	switch _, err := db.conn(ctx).Exec(ctx, sql, productID, supplierID); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot remove product supplier from database", slog.Any("error", err))
+		return infraError("cannot remove product supplier from database", err)
+	}
+	return nil
+}
+
+// productSupplier table.
+type productSupplier struct {
+	ProductID    string
+	SupplierID   string
+	LeadTimeDays int
+	Cost         int
+	ModifiedAt   time.Time
+}
+
+// ListProductSuppliers returns the suppliers of a product, the cheapest first.
+func (db DB) ListProductSuppliers(ctx context.Context, productID string) ([]*inventory.ProductSupplier, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "product_supplier" WHERE "product_id" = $1
+	ORDER BY "cost", "lead_time_days", "supplier_id"`, pgtools.Wildcard(productSupplier{})) // #nosec G201
+	suppliers, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]productSupplier, error) {
+		rows, err := conn.Query(ctx, sql, productID)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[productSupplier])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot list product suppliers from database",
+			slog.String("product", productID),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot list product suppliers from database", err)
+	}
+	resp := make([]*inventory.ProductSupplier, 0, len(suppliers))
+	for _, s := range suppliers {
+		resp = append(resp, &inventory.ProductSupplier{
+			ProductID:  s.ProductID,
+			SupplierID: s.SupplierID,
+			LeadTime:   time.Duration(s.LeadTimeDays) * 24 * time.Hour,
+			Cost:       s.Cost,
+			ModifiedAt: s.ModifiedAt,
+		})
+	}
+	return resp, nil
+}
+
+// ListSupplierProducts returns the products of a supplier, regardless of their status, ordered by ID.
+func (db DB) ListSupplierProducts(ctx context.Context, params inventory.ListSupplierProductsParams) (*inventory.ListProductsResponse, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "product"
+	WHERE "id" IN (SELECT "product_id" FROM "product_supplier" WHERE "supplier_id" = $1)
+	ORDER BY "id" COLLATE "C"
+	LIMIT $2 OFFSET $3`, pgtools.Wildcard(product{})) // #nosec G201
+	return db.listProducts(ctx, "ListSupplierProducts", inventory.ProductViewFull, sql, params.SupplierID, params.Pagination.Limit, params.Pagination.Offset)
+}
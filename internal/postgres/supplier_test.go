@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestSuppliers(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "desk",
+			Name:        "Desk",
+			Description: "A desk",
+			Price:       140,
+			Status:      inventory.ProductStatusDraft,
+		},
+		{
+			ID:          "chair",
+			Name:        "Chair",
+			Description: "A chair",
+			Price:       80,
+		},
+	})
+	for _, params := range []inventory.CreateSupplierParams{
+		{ID: "acme", Name: "Acme", Email: "sales@example.com"},
+		{ID: "globex", Name: "Globex"},
+	} {
+		if _, err := db.CreateSupplier(context.Background(), params); err != nil {
+			t.Fatalf("DB.CreateSupplier() error = %v", err)
+		}
+	}
+	if _, err := db.CreateSupplier(context.Background(), inventory.CreateSupplierParams{ID: "acme", Name: "Acme"}); err != inventory.ErrSupplierExists {
+		t.Errorf("DB.CreateSupplier() error = %v, want %v", err, inventory.ErrSupplierExists)
+	}
+
+	updated, err := db.UpdateSupplier(context.Background(), inventory.UpdateSupplierParams{ID: "globex", Email: ptr("orders@example.com")})
+	if err != nil {
+		t.Fatalf("DB.UpdateSupplier() error = %v", err)
+	}
+	if updated.Name != "Globex" || updated.Email != "orders@example.com" {
+		t.Errorf("DB.UpdateSupplier() = %+v, want updated email", updated)
+	}
+	if _, err := db.UpdateSupplier(context.Background(), inventory.UpdateSupplierParams{ID: "initech", Name: ptr("Initech")}); err != inventory.ErrSupplierNotFound {
+		t.Errorf("DB.UpdateSupplier() error = %v, want %v", err, inventory.ErrSupplierNotFound)
+	}
+	if got, err := db.GetSupplier(context.Background(), "acme"); err != nil || got == nil || got.Name != "Acme" {
+		t.Errorf("DB.GetSupplier() = %+v, %v, want supplier acme", got, err)
+	}
+
+	for _, params := range []inventory.ProductSupplier{
+		{ProductID: "desk", SupplierID: "acme", LeadTime: 7 * 24 * time.Hour, Cost: 100},
+		{ProductID: "desk", SupplierID: "globex", LeadTime: 14 * 24 * time.Hour, Cost: 90},
+		{ProductID: "chair", SupplierID: "acme", LeadTime: 24 * time.Hour, Cost: 50},
+		{ProductID: "desk", SupplierID: "acme", LeadTime: 3 * 24 * time.Hour, Cost: 95}, // Replaces the first one.
+	} {
+		if err := db.SetProductSupplier(context.Background(), params); err != nil {
+			t.Errorf("DB.SetProductSupplier(%+v) error = %v", params, err)
+		}
+	}
+	if err := db.SetProductSupplier(context.Background(), inventory.ProductSupplier{ProductID: "lamp", SupplierID: "acme"}); err != inventory.ErrSupplierNoProduct {
+		t.Errorf("DB.SetProductSupplier() error = %v, want %v", err, inventory.ErrSupplierNoProduct)
+	}
+	if err := db.SetProductSupplier(context.Background(), inventory.ProductSupplier{ProductID: "desk", SupplierID: "initech"}); err != inventory.ErrSupplierNotFound {
+		t.Errorf("DB.SetProductSupplier() error = %v, want %v", err, inventory.ErrSupplierNotFound)
+	}
+
+	suppliers, err := db.ListProductSuppliers(context.Background(), "desk")
+	if err != nil {
+		t.Fatalf("DB.ListProductSuppliers() error = %v", err)
+	}
+	want := []*inventory.ProductSupplier{
+		{ProductID: "desk", SupplierID: "globex", LeadTime: 14 * 24 * time.Hour, Cost: 90},
+		{ProductID: "desk", SupplierID: "acme", LeadTime: 3 * 24 * time.Hour, Cost: 95},
+	}
+	if !cmp.Equal(want, suppliers, cmpopts.IgnoreFields(inventory.ProductSupplier{}, "ModifiedAt")) {
+		t.Errorf("value returned by DB.ListProductSuppliers() doesn't match: %v", cmp.Diff(want, suppliers))
+	}
+
+	// Products of a supplier are listed regardless of their status.
+	products, err := db.ListSupplierProducts(context.Background(), inventory.ListSupplierProductsParams{
+		SupplierID: "acme",
+		Pagination: inventory.Pagination{Limit: 10},
+	})
+	if err != nil {
+		t.Fatalf("DB.ListSupplierProducts() error = %v", err)
+	}
+	var ids []string
+	for _, p := range products.Items {
+		ids = append(ids, p.ID)
+	}
+	if want := []string{"chair", "desk"}; !cmp.Equal(want, ids) {
+		t.Errorf("DB.ListSupplierProducts() = %v, want %v", ids, want)
+	}
+
+	if err := db.RemoveProductSupplier(context.Background(), "desk", "globex"); err != nil {
+		t.Errorf("DB.RemoveProductSupplier() error = %v", err)
+	}
+	if err := db.DeleteSupplier(context.Background(), "acme"); err != nil {
+		t.Errorf("DB.DeleteSupplier() error = %v", err)
+	}
+	if err := db.DeleteSupplier(context.Background(), "acme"); err != inventory.ErrSupplierNotFound {
+		t.Errorf("DB.DeleteSupplier() error = %v, want %v", err, inventory.ErrSupplierNotFound)
+	}
+	if suppliers, err := db.ListProductSuppliers(context.Background(), "desk"); err != nil || len(suppliers) != 0 {
+		t.Errorf("DB.ListProductSuppliers() = %v, %v, want no suppliers", suppliers, err)
+	}
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/henvic/pgtools"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListTenantProducts returns up to params.Limit products of a tenant with an ID after params.After, ordered by ID.
+// IDs are compared byte by byte, regardless of the collation of the database, so the results of shards can be merged.
+func (db DB) ListTenantProducts(ctx context.Context, params inventory.ListTenantProductsParams) ([]*inventory.Product, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "product"
+	WHERE "id" COLLATE "C" > $1 AND CASE
+		WHEN $2::text = '' THEN strpos("id", $3::text) = 0
+		ELSE starts_with("id", $2::text || $3::text)
+	END
+	ORDER BY "id" COLLATE "C"
+	LIMIT $4`, pgtools.Wildcard(product{})) // #nosec G201
+	resp, err := db.listProducts(ctx, "ListTenantProducts", inventory.ProductViewFull, sql, params.After, params.Tenant, inventory.TenantSeparator, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Items, nil
+}
+
+// ApplyProductChanges creates, updates, and deletes products, in a single transaction.
+// A product created meanwhile is updated to match the one to create instead.
+// A slug taken meanwhile is retried by CreateProduct under a savepoint, so it doesn't fail the batch.
+func (db DB) ApplyProductChanges(ctx context.Context, changes []inventory.ProductChange) (err error) {
+	txCtx, err := db.TransactionContext(ctx)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot begin transaction to apply product changes", slog.Any("error", err))
+		return infraError("cannot apply product changes on database", err)
+	}
+	defer func() {
+		if err != nil {
+			if rerr := db.Rollback(txCtx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback product changes", slog.Any("error", rerr))
+			}
+		}
+	}()
+	for _, c := range changes {
+		if err = db.applyProductChange(txCtx, c); err != nil {
+			return err
+		}
+	}
+	if err = db.Commit(txCtx); err != nil && ctx.Err() == nil {
+		db.log.Error("cannot commit product changes", slog.Any("error", err))
+		return infraError("cannot apply product changes on database", err)
+	}
+	return err
+}
+
+func (db DB) applyProductChange(ctx context.Context, c inventory.ProductChange) error {
+	switch c.Kind {
+	case inventory.ProductCreate:
+		res, err := db.CreateProduct(ctx, *c.Create)
+		if err != nil || res.Created {
+			return err
+		}
+		status := c.Create.Status
+		if status == "" {
+			status = inventory.ProductStatusActive
+		}
+		_, err = db.UpdateProduct(ctx, inventory.UpdateProductParams{
+			ID:          c.Create.ID,
+			Name:        &c.Create.Name,
+			Description: &c.Create.Description,
+			Price:       &c.Create.Price,
+			Status:      &status,
+			SKU:         &c.Create.SKU,
+			GTIN:        &c.Create.GTIN,
+			TaxClass:    &c.Create.TaxClass,
+		})
+		return err
+	case inventory.ProductUpdate:
+		_, err := db.UpdateProduct(ctx, *c.Update)
+		return err
+	case inventory.ProductDelete:
+		return db.DeleteProduct(ctx, *c.Delete)
+	}
+	return fmt.Errorf("unknown product change kind %q", c.Kind)
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestApplyProductChanges(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{ID: "acme:chair", Name: "Chair", Description: "A chair", Price: 40},
+		{ID: "acme:desk", Name: "Desk", Description: "A desk", Price: 100},
+		{ID: "other:desk", Name: "Desk", Description: "A desk", Price: 100},
+		{ID: "lamp", Name: "Lamp", Description: "A lamp", Price: 20},
+	})
+	ids := func(tenant string) []string {
+		t.Helper()
+		products, err := db.ListTenantProducts(ctx, inventory.ListTenantProductsParams{Tenant: tenant, Limit: 10})
+		if err != nil {
+			t.Fatalf("DB.ListTenantProducts() error = %v", err)
+		}
+		var ids []string
+		for _, p := range products {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+	if got, want := ids("acme"), []string{"acme:chair", "acme:desk"}; !slices.Equal(got, want) {
+		t.Errorf("DB.ListTenantProducts() = %v, want %v", got, want)
+	}
+	if got, want := ids(""), []string{"lamp"}; !slices.Equal(got, want) {
+		t.Errorf("DB.ListTenantProducts() without tenant = %v, want %v", got, want)
+	}
+	if got, err := db.ListTenantProducts(ctx, inventory.ListTenantProductsParams{Tenant: "acme", After: "acme:chair", Limit: 10}); err != nil || len(got) != 1 || got[0].ID != "acme:desk" {
+		t.Errorf("DB.ListTenantProducts() after acme:chair = (%v, %v), want acme:desk", got, err)
+	}
+
+	err := db.ApplyProductChanges(ctx, []inventory.ProductChange{
+		{Kind: inventory.ProductDelete, ID: "acme:chair", Delete: &inventory.DeleteProductParams{ID: "acme:chair"}},
+		{Kind: inventory.ProductUpdate, ID: "acme:desk", Update: &inventory.UpdateProductParams{ID: "acme:desk", Price: ptr(120)}},
+		{Kind: inventory.ProductCreate, ID: "acme:table", Create: &inventory.CreateProductParams{ID: "acme:table", Name: "Table", Description: "A table", Price: 200}},
+	})
+	if err != nil {
+		t.Fatalf("DB.ApplyProductChanges() error = %v", err)
+	}
+	if got, want := ids("acme"), []string{"acme:desk", "acme:table"}; !slices.Equal(got, want) {
+		t.Errorf("DB.ListTenantProducts() = %v, want %v", got, want)
+	}
+	if p, err := db.GetProduct(ctx, "acme:desk"); err != nil || p.Price != 120 {
+		t.Errorf("DB.GetProduct() = (%v, %v), want price 120", p, err)
+	}
+
+	// The changes of a failed batch are rolled back.
+	err = db.ApplyProductChanges(ctx, []inventory.ProductChange{
+		{Kind: inventory.ProductDelete, ID: "acme:desk", Delete: &inventory.DeleteProductParams{ID: "acme:desk"}},
+		{Kind: inventory.ProductUpdate, ID: "acme:missing", Update: &inventory.UpdateProductParams{ID: "acme:missing", Price: ptr(1)}},
+	})
+	if err != ErrProductNotFound {
+		t.Errorf("DB.ApplyProductChanges() error = %v, want %v", err, ErrProductNotFound)
+	}
+	if got, want := ids("acme"), []string{"acme:desk", "acme:table"}; !slices.Equal(got, want) {
+		t.Errorf("DB.ListTenantProducts() = %v, want %v", got, want)
+	}
+}
+
+func TestApplyProductChangesConcurrentSlug(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	// With quotas, each product is created in a savepoint of the transaction of the changes.
+	db := NewDB(pool, slog.Default(), WithQuotas(inventory.Quota{MaxProducts: 10}))
+
+	// Take the slug in a transaction, so the synced product picks it too, and conflicts once it's committed.
+	txCtx, err := db.TransactionContext(ctx)
+	if err != nil {
+		t.Fatalf("DB.TransactionContext() error = %v", err)
+	}
+	defer db.Rollback(txCtx) // #nosec G104
+	if _, err := db.CreateProduct(txCtx, inventory.CreateProductParams{ID: "globex:desk", Name: "desk", Description: "A desk"}); err != nil {
+		t.Fatalf("DB.CreateProduct() error = %v", err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- db.ApplyProductChanges(ctx, []inventory.ProductChange{
+			{Kind: inventory.ProductCreate, Create: &inventory.CreateProductParams{ID: "acme:chair", Name: "chair", Description: "A chair"}},
+			{Kind: inventory.ProductCreate, Create: &inventory.CreateProductParams{ID: "acme:desk", Name: "desk", Description: "A desk"}},
+		})
+	}()
+	waitForLock(t, pool)
+	if err := db.Commit(txCtx); err != nil {
+		t.Fatalf("DB.Commit() error = %v", err)
+	}
+
+	// The whole batch is applied, with the next free slug for the conflicting product.
+	if err := <-done; err != nil {
+		t.Fatalf("DB.ApplyProductChanges() error = %v", err)
+	}
+	for id, slug := range map[string]string{"acme:chair": "chair", "acme:desk": "desk-2"} {
+		if p, err := db.GetProduct(ctx, id); err != nil || p == nil || p.Slug != slug {
+			t.Errorf("DB.GetProduct(%q) = (%v, %v), want product with slug %q", id, p, err, slug)
+		}
+	}
+}
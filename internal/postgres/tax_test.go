@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestProductTaxClass(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "book",
+			Name:        "Book",
+			Description: "A book",
+			Price:       20,
+			TaxClass:    "reduced",
+		},
+	})
+	got, err := db.GetProduct(context.Background(), "book")
+	if err != nil {
+		t.Fatalf("DB.GetProduct() error = %v", err)
+	}
+	if got.TaxClass != "reduced" {
+		t.Errorf("DB.GetProduct() tax class = %q, want reduced", got.TaxClass)
+	}
+
+	if _, err := db.UpdateProduct(context.Background(), inventory.UpdateProductParams{
+		ID:       "book",
+		TaxClass: ptr("Reduced"),
+	}); err == nil || err.Error() != "invalid tax class" {
+		t.Errorf("DB.UpdateProduct() error = %v, want invalid tax class", err)
+	}
+	// An empty tax class sets the default one.
+	updated, err := db.UpdateProduct(context.Background(), inventory.UpdateProductParams{ID: "book", TaxClass: ptr("")})
+	if err != nil {
+		t.Fatalf("DB.UpdateProduct() error = %v", err)
+	}
+	if updated.TaxClass != "" {
+		t.Errorf("DB.UpdateProduct() tax class = %q, want default", updated.TaxClass)
+	}
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/usage"
+	"github.com/jackc/pgx/v5"
+)
+
+// AddUsage adds the counts of the records to the ones stored for the same tenant, API key, and hour.
+// Records must not repeat the same tenant, API key, and hour, as written by usage.Meter.
+func (db DB) AddUsage(ctx context.Context, records []usage.Record) error {
+	const sql = `INSERT INTO "usage" ("tenant", "api_key", "hour", "requests", "bytes_in", "bytes_out")
+	SELECT * FROM unnest($1::text[], $2::text[], $3::timestamptz[], $4::bigint[], $5::bigint[], $6::bigint[])
+	ON CONFLICT ("tenant", "api_key", "hour") DO UPDATE SET
+		"requests" = "usage"."requests" + EXCLUDED."requests",
+		"bytes_in" = "usage"."bytes_in" + EXCLUDED."bytes_in",
+		"bytes_out" = "usage"."bytes_out" + EXCLUDED."bytes_out"`
+	var (
+		tenants  = make([]string, 0, len(records))
+		apiKeys  = make([]string, 0, len(records))
+		hours    = make([]time.Time, 0, len(records))
+		requests = make([]int64, 0, len(records))
+		bytesIn  = make([]int64, 0, len(records))
+		bytesOut = make([]int64, 0, len(records))
+	)
+	for _, r := range records {
+		tenants = append(tenants, r.Tenant)
+		apiKeys = append(apiKeys, r.APIKey)
+		hours = append(hours, r.Hour)
+		requests = append(requests, r.Requests)
+		bytesIn = append(bytesIn, r.BytesIn)
+		bytesOut = append(bytesOut, r.BytesOut)
+	}
+	_, err := db.conn(ctx).Exec(ctx, sql, tenants, apiKeys, hours, requests, bytesIn, bytesOut)
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case err != nil:
+		db.log.Error("cannot add usage on database", slog.Int("records", len(records)), slog.Any("error", err))
+		return infraError("cannot add usage on database", err)
+	}
+	return nil
+}
+
+// usageRow of the usage table.
+type usageRow struct {
+	Tenant   string
+	APIKey   string
+	Hour     time.Time
+	Requests int64
+	BytesIn  int64
+	BytesOut int64
+}
+
+func (u usageRow) dto() usage.Record {
+	return usage.Record{
+		Tenant:   u.Tenant,
+		APIKey:   u.APIKey,
+		Hour:     u.Hour.UTC(),
+		Requests: u.Requests,
+		BytesIn:  u.BytesIn,
+		BytesOut: u.BytesOut,
+	}
+}
+
+// ListUsage returns the usage records matching the query, ordered by hour, tenant, and API key.
+func (db DB) ListUsage(ctx context.Context, q usage.Query) ([]usage.Record, error) {
+	const sql = `SELECT "tenant", "api_key", "hour", "requests", "bytes_in", "bytes_out" FROM "usage"
+	WHERE "hour" >= $1 AND "hour" < $2
+	AND ($3::text = '' OR "tenant" = $3)
+	AND ($4::text = '' OR "api_key" = $4)
+	ORDER BY "hour", "tenant", "api_key"`
+	rows, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]usageRow, error) {
+		rows, err := conn.Query(ctx, sql, q.From, q.To, q.Tenant, q.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[usageRow])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot list usage from database", slog.Any("error", err))
+		return nil, infraError("cannot list usage from database", err)
+	}
+	records := make([]usage.Record, 0, len(rows))
+	for _, u := range rows {
+		records = append(records, u.dto())
+	}
+	return records, nil
+}
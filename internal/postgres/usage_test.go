@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/usage"
+)
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(ctx, "")
+	db := NewDB(pool, slog.Default())
+
+	hour := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	batches := [][]usage.Record{
+		{
+			{Tenant: "acme", APIKey: "key1", Hour: hour, Requests: 2, BytesIn: 10, BytesOut: 100},
+			{Tenant: "acme", APIKey: "key2", Hour: hour, Requests: 1, BytesOut: 20},
+			{Tenant: "globex", Hour: hour.Add(time.Hour), Requests: 1, BytesOut: 5},
+		},
+		// The counts of the same tenant, API key, and hour are added to the stored ones.
+		{
+			{Tenant: "acme", APIKey: "key1", Hour: hour, Requests: 1, BytesIn: 5, BytesOut: 50},
+		},
+	}
+	for _, records := range batches {
+		if err := db.AddUsage(ctx, records); err != nil {
+			t.Fatalf("DB.AddUsage() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		q    usage.Query
+		want []usage.Record
+	}{
+		{
+			name: "tenant",
+			q:    usage.Query{Tenant: "acme", From: hour, To: hour.Add(24 * time.Hour)},
+			want: []usage.Record{
+				{Tenant: "acme", APIKey: "key1", Hour: hour, Requests: 3, BytesIn: 15, BytesOut: 150},
+				{Tenant: "acme", APIKey: "key2", Hour: hour, Requests: 1, BytesOut: 20},
+			},
+		},
+		{
+			name: "api_key",
+			q:    usage.Query{Tenant: "acme", APIKey: "key2", From: hour, To: hour.Add(time.Hour)},
+			want: []usage.Record{
+				{Tenant: "acme", APIKey: "key2", Hour: hour, Requests: 1, BytesOut: 20},
+			},
+		},
+		{
+			name: "all_tenants",
+			q:    usage.Query{From: hour.Add(time.Hour), To: hour.Add(2 * time.Hour)},
+			want: []usage.Record{
+				{Tenant: "globex", Hour: hour.Add(time.Hour), Requests: 1, BytesOut: 5},
+			},
+		},
+		{
+			name: "period_without_usage",
+			q:    usage.Query{Tenant: "acme", From: hour.Add(-time.Hour), To: hour},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := db.ListUsage(ctx, tt.q)
+			if err != nil {
+				t.Fatalf("DB.ListUsage() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("DB.ListUsage() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("DB.ListUsage()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if err := db.AddUsage(canceledContext(), batches[1]); err != context.Canceled {
+		t.Errorf("DB.AddUsage() with canceled context error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Finding of an integrity check.
+type Finding struct {
+	// Check that found inconsistent rows.
+	Check string
+
+	// Description of the problem.
+	Description string
+
+	// Rows found.
+	Rows int
+}
+
+// integrityChecks counting inconsistent rows.
+var integrityChecks = []struct {
+	name, description, sql string
+}{
+	{
+		name:        "orphaned_reviews",
+		description: "reviews of products that don't exist",
+		sql: `SELECT COUNT(*) FROM "review" r
+		LEFT JOIN "product" p ON p."id" = r."product_id"
+		WHERE p."id" IS NULL`,
+	},
+	{
+		name:        "negative_prices",
+		description: "products with a negative price",
+		sql:         `SELECT COUNT(*) FROM "product" WHERE "price" < 0`,
+	},
+	{
+		name:        "invalid_scores",
+		description: "reviews with a score out of the 0 to 5 range",
+		sql:         `SELECT COUNT(*) FROM "review" WHERE "score" NOT BETWEEN 0 AND 5`,
+	},
+	{
+		name:        "stale_search_view",
+		description: "products missing from the product_search view or with an out of sync review count (refresh the view)",
+		sql: `SELECT COUNT(*) FROM "product" p
+		FULL JOIN "product_search" s ON s."id" = p."id"
+		LEFT JOIN (SELECT "product_id", COUNT(*) AS "count" FROM "review" GROUP BY "product_id") r
+			ON r."product_id" = COALESCE(p."id", s."id")
+		WHERE p."id" IS NULL OR s."id" IS NULL OR s."review_count" <> COALESCE(r."count", 0)`,
+	},
+}
+
+// Verify runs integrity checks on the data, returning the checks that found inconsistent rows.
+// It runs in a read-only transaction, so it's safe to run periodically against production databases.
+func (db DB) Verify(ctx context.Context) ([]Finding, error) {
+	tx, err := db.poolFor(ctx).BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	var findings []Finding
+	if err == nil {
+		defer func() {
+			if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback integrity checks", slog.Any("error", rerr))
+			}
+		}()
+		findings, err = db.verify(ctx, tx)
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot verify database integrity", slog.Any("error", err))
+		return nil, infraError("cannot verify database integrity", err)
+	}
+	return findings, nil
+}
+
+func (db DB) verify(ctx context.Context, tx pgx.Tx) ([]Finding, error) {
+	var findings []Finding
+	for _, c := range integrityChecks {
+		var rows int
+		if err := tx.QueryRow(ctx, c.sql).Scan(&rows); err != nil {
+			return nil, fmt.Errorf("%s: %w", c.name, err)
+		}
+		if rows > 0 {
+			findings = append(findings, Finding{
+				Check:       c.name,
+				Description: c.description,
+				Rows:        rows,
+			})
+		}
+	}
+	return findings, nil
+}
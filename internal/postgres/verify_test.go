@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "table",
+			Name:        "Table",
+			Description: "A table",
+			Price:       100,
+		},
+	})
+	if err := db.RefreshProductSearch(context.Background()); err != nil {
+		t.Fatalf("DB.RefreshProductSearch() error = %v", err)
+	}
+	findings, err := db.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("DB.Verify() error = %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("DB.Verify() = %v, want no findings", findings)
+	}
+
+	// The search view is out of sync until it's refreshed.
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{
+			ID: "review",
+			CreateProductReviewParams: inventory.CreateProductReviewParams{
+				ProductID:   "table",
+				ReviewerID:  "reviewer",
+				Score:       4,
+				Title:       "Sturdy",
+				Description: "Sturdy table",
+			},
+		},
+	})
+	findings, err = db.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("DB.Verify() error = %v", err)
+	}
+	want := []Finding{
+		{
+			Check:       "stale_search_view",
+			Description: "products missing from the product_search view or with an out of sync review count (refresh the view)",
+			Rows:        1,
+		},
+	}
+	if !cmp.Equal(want, findings) {
+		t.Errorf("DB.Verify() = %v", cmp.Diff(want, findings))
+	}
+}
+
+func TestVerifyCanceledContext(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+	if _, err := db.Verify(canceledContext()); err != context.Canceled {
+		t.Errorf("DB.Verify() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/henvic/pgtools"
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgx/v5"
+)
+
+// productSummary is the inventory.ProductViewSummary view of the product table.
+// The time of creation isn't part of the view, but is read to merge the products listed by each shard.
+type productSummary struct {
+	ID        string
+	Name      string
+	Price     int
+	Rating    float64
+	CreatedAt time.Time
+}
+
+func (p *productSummary) dto() *inventory.Product {
+	return &inventory.Product{
+		ID:        p.ID,
+		Name:      p.Name,
+		Price:     p.Price,
+		Rating:    p.Rating,
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+// productColumns returns the columns of the view of the products read from table, which is its name or alias in the query.
+// The product_search materialized view has the average score of each product. Otherwise, the rating of the summary view
+// is zero, and set by productRatings for all the products read at once, rather than by a subquery for each row.
+func productColumns(view inventory.ProductView, table string) string {
+	switch {
+	case view != inventory.ProductViewSummary:
+		return pgtools.Wildcard(product{})
+	case table == "product_search":
+		return `"id", "name", "price", "average_score" AS "rating", "created_at"`
+	}
+	t := pgx.Identifier{table}.Sanitize()
+	return fmt.Sprintf(`%[1]s."id", %[1]s."name", %[1]s."price", 0::double precision AS "rating", %[1]s."created_at"`, t)
+}
+
+// productRatingsJoin joins the product table to the rating of every product, aggregated once,
+// for reading the summary view of products that can't be rated afterwards by productRatings, such as on exports.
+// The rating is read by productRatingsColumns.
+const productRatingsJoin = `"product" LEFT JOIN (
+		SELECT "product_id", AVG("score")::double precision AS "rating" FROM "review" GROUP BY "product_id"
+	) AS "product_rating" ON "product_rating"."product_id" = "product"."id"`
+
+// productRatingsColumns are the columns of the summary view of the products read with productRatingsJoin.
+const productRatingsColumns = `"product"."id", "product"."name", "product"."price",
+	COALESCE("product_rating"."rating", 0) AS "rating", "product"."created_at"`
+
+// rateLater returns whether the products of the view read from table must be rated by productRatings.
+func rateLater(view inventory.ProductView, table string) bool {
+	return view == inventory.ProductViewSummary && table != "product_search"
+}
+
+// productRatings sets the rating of the products, the average score of their reviews, aggregated by a single statement.
+func productRatings(ctx context.Context, conn database.PGXQuerier, products []*inventory.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	rows, err := conn.Query(ctx, `SELECT "product_id", AVG("score")::double precision FROM "review"
+	WHERE "product_id" = ANY($1)
+	GROUP BY "product_id"`, ids)
+	if err != nil {
+		return err
+	}
+	ratings := make(map[string]float64, len(products))
+	var (
+		id     string
+		rating float64
+	)
+	if _, err := pgx.ForEachRow(rows, []any{&id, &rating}, func() error {
+		ratings[id] = rating
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, p := range products {
+		p.Rating = ratings[p.ID]
+	}
+	return nil
+}
+
+// productRow returns the function reading the products of the view from rows with the productColumns of the view.
+func productRow(view inventory.ProductView) pgx.RowToFunc[*inventory.Product] {
+	if view == inventory.ProductViewSummary {
+		return func(row pgx.CollectableRow) (*inventory.Product, error) {
+			p, err := pgx.RowToStructByName[productSummary](row)
+			if err != nil {
+				return nil, err
+			}
+			return p.dto(), nil
+		}
+	}
+	return func(row pgx.CollectableRow) (*inventory.Product, error) {
+		p, err := pgx.RowToStructByName[product](row)
+		if err != nil {
+			return nil, err
+		}
+		return p.dto(), nil
+	}
+}
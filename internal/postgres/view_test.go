@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+func TestProductViewSummary(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+	createProducts(t, db, []inventory.CreateProductParams{
+		{ID: "desk", Name: "Desk", Description: "A desk", Price: 300},
+		{ID: "chair", Name: "Desk chair", Description: "A chair", Price: 100},
+	})
+	createProductReviews(t, db, []inventory.CreateProductReviewDBParams{
+		{ID: "review1", CreateProductReviewParams: inventory.CreateProductReviewParams{
+			ProductID: "desk", ReviewerID: "reviewer1", Score: 4, Title: "Good", Description: "A good desk",
+		}},
+		{ID: "review2", CreateProductReviewParams: inventory.CreateProductReviewParams{
+			ProductID: "desk", ReviewerID: "reviewer2", Score: 5, Title: "Great", Description: "A great desk",
+		}},
+	})
+	want := []*inventory.Product{
+		{ID: "desk", Name: "Desk", Price: 300, Rating: 4.5},
+		{ID: "chair", Name: "Desk chair", Price: 100},
+	}
+	ignoreCreatedAt := cmpopts.IgnoreFields(inventory.Product{}, "CreatedAt")
+
+	params := inventory.SearchProductsParams{
+		QueryString: "Desk",
+		OrderBy:     inventory.ProductsByName,
+		Pagination:  inventory.Pagination{Limit: 10},
+		View:        inventory.ProductViewSummary,
+	}
+	searchView := NewDB(pool, slog.Default(), WithSearchView())
+	if err := searchView.RefreshProductSearch(context.Background()); err != nil {
+		t.Fatalf("DB.RefreshProductSearch() error = %v", err)
+	}
+	for _, tt := range []struct {
+		name string
+		db   DB
+	}{
+		{"product", db},
+		{"product_search", searchView},
+		{"window_total", NewDB(pool, slog.Default(), WithWindowTotal())},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.db.SearchProducts(context.Background(), params)
+			if err != nil {
+				t.Fatalf("DB.SearchProducts() error = %v", err)
+			}
+			if got.Total != 2 || !cmp.Equal(want, got.Items, ignoreCreatedAt) {
+				t.Errorf("DB.SearchProducts() summary doesn't match (total = %d): %v", got.Total, cmp.Diff(want, got.Items, ignoreCreatedAt))
+			}
+		})
+	}
+
+	recent, err := db.ListRecentProducts(context.Background(), inventory.ListRecentProductsParams{
+		Pagination: inventory.Pagination{Limit: 10},
+		View:       inventory.ProductViewSummary,
+	})
+	if err != nil {
+		t.Fatalf("DB.ListRecentProducts() error = %v", err)
+	}
+	for _, p := range recent.Items {
+		if p.CreatedAt.IsZero() || time.Since(p.CreatedAt) > time.Hour {
+			t.Errorf("product %q created at %v, want time of creation to merge shards by", p.ID, p.CreatedAt)
+		}
+	}
+	if !cmp.Equal([]*inventory.Product{want[1], want[0]}, recent.Items, ignoreCreatedAt) {
+		t.Errorf("DB.ListRecentProducts() summary doesn't match: %v", cmp.Diff([]*inventory.Product{want[1], want[0]}, recent.Items, ignoreCreatedAt))
+	}
+
+	// Exported products are rated as they're read.
+	var exported []*inventory.Product
+	if err := db.ExportProducts(context.Background(), params, func(p *inventory.Product) error {
+		exported = append(exported, p)
+		return nil
+	}); err != nil {
+		t.Fatalf("DB.ExportProducts() error = %v", err)
+	}
+	if !cmp.Equal(want, exported, ignoreCreatedAt) {
+		t.Errorf("DB.ExportProducts() summary doesn't match: %v", cmp.Diff(want, exported, ignoreCreatedAt))
+	}
+}
@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/henvic/pgtools"
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// warehouse table.
+type warehouse struct {
+	ID         string
+	Name       string
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+}
+
+func (w *warehouse) dto() *inventory.Warehouse {
+	return &inventory.Warehouse{
+		ID:         w.ID,
+		Name:       w.Name,
+		CreatedAt:  w.CreatedAt,
+		ModifiedAt: w.ModifiedAt,
+	}
+}
+
+// CreateWarehouse creates a new warehouse.
+func (db DB) CreateWarehouse(ctx context.Context, params inventory.CreateWarehouseParams) (*inventory.Warehouse, error) {
+	sql := fmt.Sprintf(`INSERT INTO "warehouse" ("id", "name") VALUES ($1, $2)
+	RETURNING %s`, pgtools.Wildcard(warehouse{})) // #nosec G201
+	rows, err := db.conn(ctx).Query(ctx, sql, params.ID, params.Name)
+	var w warehouse
+	if err == nil {
+		w, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[warehouse])
+	}
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == "warehouse_pkey":
+		return nil, inventory.ErrWarehouseExists
+	case err != nil:
+		db.log.Error("cannot create warehouse on database", slog.Any("error", err))
+		return nil, infraError("cannot create warehouse on database", err)
+	}
+	return w.dto(), nil
+}
+
+// ListWarehouses returns all warehouses, ordered by ID.
+func (db DB) ListWarehouses(ctx context.Context) ([]*inventory.Warehouse, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "warehouse" ORDER BY "id"`, pgtools.Wildcard(warehouse{})) // #nosec G201
+	warehouses, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]warehouse, error) {
+		rows, err := conn.Query(ctx, sql)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[warehouse])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot list warehouses from database", slog.Any("error", err))
+		return nil, infraError("cannot list warehouses from database", err)
+	}
+	resp := make([]*inventory.Warehouse, 0, len(warehouses))
+	for _, w := range warehouses {
+		resp = append(resp, w.dto())
+	}
+	return resp, nil
+}
+
+// stockPgError maps errors of writes to the product_stock table.
+func stockPgError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch {
+	case pgErr.Code == pgerrcode.ForeignKeyViolation && pgErr.ConstraintName == "product_stock_product_id_fkey":
+		return inventory.ErrStockNoProduct
+	case pgErr.Code == pgerrcode.ForeignKeyViolation && pgErr.ConstraintName == "product_stock_warehouse_id_fkey":
+		return inventory.ErrWarehouseNotFound
+	case pgErr.Code == pgerrcode.CheckViolation && pgErr.ConstraintName == "product_stock_reserved_check":
+		return inventory.ErrStockReserved
+	}
+	return err
+}
+
+// SetProductStock sets the quantity of a product in stock at a warehouse.
+func (db DB) SetProductStock(ctx context.Context, params inventory.SetProductStockParams) error {
+	const sql = `INSERT INTO "product_stock" ("product_id", "warehouse_id", "quantity") VALUES ($1, $2, $3)
+	ON CONFLICT ("product_id", "warehouse_id") DO UPDATE SET "quantity" = EXCLUDED."quantity", "modified_at" = now()`
+	_, err := db.conn(ctx).Exec(ctx, sql, params.ProductID, params.WarehouseID, params.Quantity)
+	switch err := stockPgError(err); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, inventory.ErrStockNoProduct),
+		errors.Is(err, inventory.ErrWarehouseNotFound),
+		errors.Is(err, inventory.ErrStockReserved):
+		return err
+	case err != nil:
+		db.log.Error("cannot set product stock on database",
+			slog.String("product", params.ProductID),
+			slog.String("warehouse", params.WarehouseID),
+			slog.Any("error", err),
+		)
+		return infraError("cannot set product stock on database", err)
+	}
+	return nil
+}
+
+// TransferStock moves available units of a product from a warehouse to another in a transaction.
+func (db DB) TransferStock(ctx context.Context, params inventory.TransferStockParams) error {
+	tx, err := db.begin(ctx)
+	if err == nil {
+		defer func() {
+			if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) && ctx.Err() == nil {
+				db.log.Error("cannot rollback stock transfer", slog.Any("error", rerr))
+			}
+		}()
+		err = transferStock(ctx, tx, params)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	switch err := stockPgError(err); {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, inventory.ErrInsufficientStock),
+		errors.Is(err, inventory.ErrWarehouseNotFound):
+		return err
+	case err != nil:
+		db.log.Error("cannot transfer product stock on database",
+			slog.String("product", params.ProductID),
+			slog.String("from", params.From),
+			slog.String("to", params.To),
+			slog.Any("error", err),
+		)
+		return infraError("cannot transfer product stock on database", err)
+	}
+	return nil
+}
+
+func transferStock(ctx context.Context, tx pgx.Tx, params inventory.TransferStockParams) error {
+	// Both stock rows are locked in the same order by every transfer to avoid deadlocks between
+	// concurrent transfers in opposite directions.
+	const lock = `SELECT "warehouse_id", "quantity" - "reserved" AS "available" FROM "product_stock"
+	WHERE "product_id" = $1 AND "warehouse_id" IN ($2, $3)
+	ORDER BY "warehouse_id"
+	FOR UPDATE`
+	type row struct {
+		WarehouseID string
+		Available   int
+	}
+	rows, err := tx.Query(ctx, lock, params.ProductID, params.From, params.To)
+	if err != nil {
+		return err
+	}
+	locked, err := pgx.CollectRows(rows, pgx.RowToStructByName[row])
+	if err != nil {
+		return err
+	}
+	var available int
+	for _, r := range locked {
+		if r.WarehouseID == params.From {
+			available = r.Available
+		}
+	}
+	if available < params.Quantity {
+		return inventory.ErrInsufficientStock
+	}
+
+	const deduct = `UPDATE "product_stock" SET "quantity" = "quantity" - $3, "modified_at" = now()
+	WHERE "product_id" = $1 AND "warehouse_id" = $2`
+	if _, err := tx.Exec(ctx, deduct, params.ProductID, params.From, params.Quantity); err != nil {
+		return err
+	}
+	const add = `INSERT INTO "product_stock" ("product_id", "warehouse_id", "quantity") VALUES ($1, $2, $3)
+	ON CONFLICT ("product_id", "warehouse_id") DO UPDATE SET
+	"quantity" = "product_stock"."quantity" + EXCLUDED."quantity",
+	"modified_at" = now()`
+	_, err = tx.Exec(ctx, add, params.ProductID, params.To, params.Quantity)
+	return err
+}
+
+// stockLevel of the product_stock table.
+type stockLevel struct {
+	ProductID   string
+	WarehouseID string
+	Quantity    int
+	Reserved    int
+	ModifiedAt  time.Time
+}
+
+// GetProductStock returns the stock levels of a product at each warehouse stocking it, ordered by warehouse ID.
+func (db DB) GetProductStock(ctx context.Context, productID string) ([]*inventory.StockLevel, error) {
+	sql := fmt.Sprintf(`SELECT %s FROM "product_stock" WHERE "product_id" = $1
+	ORDER BY "warehouse_id"`, pgtools.Wildcard(stockLevel{})) // #nosec G201
+	levels, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) ([]stockLevel, error) {
+		rows, err := conn.Query(ctx, sql, productID)
+		if err != nil {
+			return nil, err
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByName[stockLevel])
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot get product stock from database",
+			slog.String("product", productID),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get product stock from database", err)
+	}
+	resp := make([]*inventory.StockLevel, 0, len(levels))
+	for _, l := range levels {
+		resp = append(resp, &inventory.StockLevel{
+			ProductID:   l.ProductID,
+			WarehouseID: l.WarehouseID,
+			Quantity:    l.Quantity,
+			Reserved:    l.Reserved,
+			ModifiedAt:  l.ModifiedAt,
+		})
+	}
+	return resp, nil
+}
+
+// withAvailability sets the units of the product available across all warehouses.
+func (db DB) withAvailability(ctx context.Context, p *inventory.Product) (*inventory.Product, error) {
+	const sql = `SELECT COALESCE(sum("quantity" - "reserved"), 0) FROM "product_stock" WHERE "product_id" = $1`
+	available, err := read(ctx, db, func(ctx context.Context, conn database.PGXQuerier) (int, error) {
+		var n int
+		err := conn.QueryRow(ctx, sql, p.ID).Scan(&n)
+		return n, err
+	})
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return nil, err
+	case err != nil:
+		db.log.Error("cannot get product availability from database",
+			slog.String("product", p.ID),
+			slog.Any("error", err),
+		)
+		return nil, infraError("cannot get product availability from database", err)
+	}
+	p.Available = available
+	return p, nil
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/orders"
+)
+
+func TestWarehouses(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+	db := NewDB(pool, slog.Default())
+
+	createProducts(t, db, []inventory.CreateProductParams{
+		{
+			ID:          "desk",
+			Name:        "Desk",
+			Description: "A desk",
+			Price:       140,
+		},
+	})
+	if _, err := db.CreateWarehouse(context.Background(), inventory.CreateWarehouseParams{ID: "north", Name: "North"}); err != nil {
+		t.Fatalf("DB.CreateWarehouse() error = %v", err)
+	}
+	if _, err := db.CreateWarehouse(context.Background(), inventory.CreateWarehouseParams{ID: "north", Name: "North"}); err != inventory.ErrWarehouseExists {
+		t.Errorf("DB.CreateWarehouse() error = %v, want %v", err, inventory.ErrWarehouseExists)
+	}
+	warehouses, err := db.ListWarehouses(context.Background())
+	if err != nil {
+		t.Fatalf("DB.ListWarehouses() error = %v", err)
+	}
+	// The main warehouse is created by the migration.
+	if len(warehouses) != 2 || warehouses[0].ID != "main" || warehouses[1].ID != "north" {
+		t.Errorf("DB.ListWarehouses() = %v, want main and north", warehouses)
+	}
+
+	if err := db.SetProductStock(context.Background(), inventory.SetProductStockParams{
+		ProductID:   "desk",
+		WarehouseID: "main",
+		Quantity:    10,
+	}); err != nil {
+		t.Fatalf("DB.SetProductStock() error = %v", err)
+	}
+	if err := db.SetProductStock(context.Background(), inventory.SetProductStockParams{
+		ProductID:   "desk",
+		WarehouseID: "south",
+		Quantity:    1,
+	}); err != inventory.ErrWarehouseNotFound {
+		t.Errorf("DB.SetProductStock() error = %v, want %v", err, inventory.ErrWarehouseNotFound)
+	}
+	if err := db.SetProductStock(context.Background(), inventory.SetProductStockParams{
+		ProductID:   "chair",
+		WarehouseID: "main",
+		Quantity:    1,
+	}); err != inventory.ErrStockNoProduct {
+		t.Errorf("DB.SetProductStock() error = %v, want %v", err, inventory.ErrStockNoProduct)
+	}
+
+	// Reserved units cannot be transferred.
+	if warehouseID, _, err := db.ReserveStock(context.Background(), "desk", 3); err != nil || warehouseID != "main" {
+		t.Fatalf("DB.ReserveStock() = %q, %v, want main", warehouseID, err)
+	}
+	if err := db.TransferStock(context.Background(), inventory.TransferStockParams{
+		ProductID: "desk",
+		From:      "main",
+		To:        "north",
+		Quantity:  8,
+	}); err != inventory.ErrInsufficientStock {
+		t.Errorf("DB.TransferStock() error = %v, want %v", err, inventory.ErrInsufficientStock)
+	}
+	if err := db.TransferStock(context.Background(), inventory.TransferStockParams{
+		ProductID: "desk",
+		From:      "main",
+		To:        "south",
+		Quantity:  1,
+	}); err != inventory.ErrWarehouseNotFound {
+		t.Errorf("DB.TransferStock() error = %v, want %v", err, inventory.ErrWarehouseNotFound)
+	}
+	if err := db.TransferStock(context.Background(), inventory.TransferStockParams{
+		ProductID: "desk",
+		From:      "main",
+		To:        "north",
+		Quantity:  7,
+	}); err != nil {
+		t.Fatalf("DB.TransferStock() error = %v", err)
+	}
+	if err := db.SetProductStock(context.Background(), inventory.SetProductStockParams{
+		ProductID:   "desk",
+		WarehouseID: "main",
+		Quantity:    2,
+	}); err != inventory.ErrStockReserved {
+		t.Errorf("DB.SetProductStock() error = %v, want %v", err, inventory.ErrStockReserved)
+	}
+
+	levels, err := db.GetProductStock(context.Background(), "desk")
+	if err != nil {
+		t.Fatalf("DB.GetProductStock() error = %v", err)
+	}
+	if len(levels) != 2 ||
+		levels[0].WarehouseID != "main" || levels[0].Quantity != 3 || levels[0].Reserved != 3 ||
+		levels[1].WarehouseID != "north" || levels[1].Quantity != 7 || levels[1].Reserved != 0 {
+		for _, l := range levels {
+			t.Logf("%+v", l)
+		}
+		t.Errorf("DB.GetProductStock() returned unexpected stock levels")
+	}
+	p, err := db.GetProduct(context.Background(), "desk")
+	if err != nil {
+		t.Fatalf("DB.GetProduct() error = %v", err)
+	}
+	if p.Available != 7 {
+		t.Errorf("DB.GetProduct() available = %d, want 7", p.Available)
+	}
+
+	// The reservation is taken from the warehouse with most units available.
+	if warehouseID, _, err := db.ReserveStock(context.Background(), "desk", 2); err != nil || warehouseID != "north" {
+		t.Errorf("DB.ReserveStock() = %q, %v, want north", warehouseID, err)
+	}
+	if _, _, err := db.ReserveStock(context.Background(), "desk", 6); err != orders.ErrInsufficientStock {
+		t.Errorf("DB.ReserveStock() error = %v, want %v", err, orders.ErrInsufficientStock)
+	}
+}
@@ -0,0 +1,129 @@
+// Package profiling pushes profiles of the running program to a continuous profiling backend.
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pusher captures CPU and heap profiles periodically, and pushes them to a backend
+// implementing the Pyroscope ingestion API (POST /ingest with pprof data).
+type Pusher struct {
+	// URL of the backend, such as http://pyroscope:4040.
+	URL string
+
+	// Application name, used as the prefix of the profile names.
+	Application string
+
+	// Labels of the profiles, such as the version and region.
+	Labels map[string]string
+
+	// Interval between captures. The CPU is profiled for Duration at the beginning of each interval.
+	Interval time.Duration
+	Duration time.Duration
+
+	Client *http.Client
+	Log    *slog.Logger
+}
+
+// Run captures and pushes profiles until the context is canceled.
+func (p *Pusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.push(ctx); err != nil && ctx.Err() == nil {
+			p.Log.Error("cannot push profiles", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// push captures a CPU profile for the configured duration and a heap profile, and pushes them.
+func (p *Pusher) push(ctx context.Context) error {
+	var cpu bytes.Buffer
+	from := time.Now()
+	// StartCPUProfile fails if the CPU is already being profiled, such as through /debug/pprof/profile.
+	if err := pprof.StartCPUProfile(&cpu); err != nil {
+		return fmt.Errorf("cannot start CPU profile: %w", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(p.Duration):
+	}
+	pprof.StopCPUProfile()
+	until := time.Now()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := p.upload(ctx, "cpu", from, until, &cpu); err != nil {
+		return err
+	}
+
+	var heap bytes.Buffer
+	if err := pprof.Lookup("heap").WriteTo(&heap, 0); err != nil {
+		return fmt.Errorf("cannot write heap profile: %w", err)
+	}
+	return p.upload(ctx, "heap", from, until, &heap)
+}
+
+// upload a profile to the backend.
+func (p *Pusher) upload(ctx context.Context, kind string, from, until time.Time, profile io.Reader) error {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fw, err := w.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(fw, profile); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	q.Set("name", p.name(kind))
+	q.Set("from", strconv.FormatInt(from.Unix(), 10))
+	q.Set("until", strconv.FormatInt(until.Unix(), 10))
+	q.Set("format", "pprof")
+	q.Set("spyName", "gospy")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.URL, "/")+"/ingest?"+q.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("cannot push %s profile: unexpected status %s", kind, resp.Status)
+	}
+	return nil
+}
+
+// name of the profile, with its labels, such as app.cpu{region=eu,version=v1.0.0}.
+func (p *Pusher) name(kind string) string {
+	labels := make([]string, 0, len(p.Labels))
+	for k, v := range p.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	return p.Application + "." + kind + "{" + strings.Join(labels, ",") + "}"
+}
@@ -0,0 +1,71 @@
+package profiling
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPusher(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		names []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/ingest" || r.URL.Query().Get("format") != "pprof" {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL)
+		}
+		f, _, err := r.FormFile("profile")
+		if err != nil {
+			t.Errorf("cannot get profile: %v", err)
+		} else {
+			f.Close()
+		}
+		mu.Lock()
+		names = append(names, r.URL.Query().Get("name"))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	p := &Pusher{
+		URL:         srv.URL,
+		Application: "api",
+		Labels:      map[string]string{"version": "v1.0.0", "region": "eu"},
+		Interval:    time.Hour,
+		Duration:    10 * time.Millisecond,
+		Client:      srv.Client(),
+		Log:         slog.Default(),
+	}
+	if err := p.push(context.Background()); err != nil {
+		t.Fatalf("Pusher.push() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"api.cpu{region=eu,version=v1.0.0}", "api.heap{region=eu,version=v1.0.0}"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("pushed profiles = %v, want %v", names, want)
+	}
+}
+
+func TestPusherError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	p := &Pusher{
+		URL:         srv.URL,
+		Application: "api",
+		Duration:    time.Millisecond,
+		Client:      srv.Client(),
+	}
+	want := "cannot push cpu profile: unexpected status 503 Service Unavailable"
+	if err := p.push(context.Background()); err == nil || err.Error() != want {
+		t.Errorf("Pusher.push() error = %v, want %v", err, want)
+	}
+}
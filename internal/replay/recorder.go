@@ -0,0 +1,328 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the Recorder.
+const (
+	DefaultMaxBodySize = 64 << 10
+	DefaultMaxFileSize = 64 << 20
+	DefaultQueueSize   = 1024
+)
+
+// Recorder of a sample of the HTTP requests and responses of a handler, anonymized, to files.
+// Exchanges are written in the background by Run, so recording doesn't slow requests down,
+// and they're dropped if it falls behind.
+type Recorder struct {
+	// Dir to write the recordings to, as capture-<time>.jsonl files with an exchange per line.
+	Dir string
+
+	// SampleRate of the requests recorded, from 0 to 1.
+	SampleRate float64
+
+	// MaxBodySize recorded of request and response bodies (default: DefaultMaxBodySize).
+	// Longer bodies aren't recorded, and their requests aren't replayed.
+	MaxBodySize int
+
+	// MaxFileSize after which the recording continues on a new file (default: DefaultMaxFileSize).
+	MaxFileSize int64
+
+	Log *slog.Logger
+
+	initOnce sync.Once
+	key      []byte // Key of the pseudonyms.
+	queue    chan Exchange
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	recorded atomic.Int64
+	dropped  atomic.Int64
+}
+
+// init the recorder on its first use.
+func (r *Recorder) init() {
+	r.initOnce.Do(func() {
+		r.key = make([]byte, 32)
+		if _, err := crand.Read(r.key); err != nil {
+			panic(fmt.Sprintf("cannot generate pseudonym key: %v", err))
+		}
+		r.queue = make(chan Exchange, DefaultQueueSize)
+		r.stop = make(chan struct{})
+	})
+}
+
+// Handler records a sample of the requests to the next handler and their responses.
+func (r *Recorder) Handler(next http.Handler) http.Handler {
+	r.init()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if rand.Float64() >= r.SampleRate { // #nosec G404
+			next.ServeHTTP(w, req)
+			return
+		}
+		maxBody := r.maxBodySize()
+		e := Exchange{
+			Time: time.Now(),
+			Request: Request{
+				Method: req.Method,
+				URI:    req.URL.RequestURI(),
+				Header: r.header(req.Header),
+			},
+		}
+		if req.Body != nil {
+			// The body is read up to one byte past the limit to know if it's truncated, and then put back.
+			body, err := io.ReadAll(io.LimitReader(req.Body, int64(maxBody)+1))
+			if err != nil {
+				http.Error(w, "cannot read request body", http.StatusBadRequest)
+				return
+			}
+			req.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+			e.Request.Body, e.Request.Truncated = r.body(body, maxBody)
+		}
+
+		rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK, limit: maxBody}
+		next.ServeHTTP(rw, req)
+		e.Duration = time.Since(e.Time)
+		e.Response = Response{
+			Status: rw.status,
+			Header: r.header(w.Header()),
+		}
+		e.Response.Body, e.Response.Truncated = r.body(rw.body.Bytes(), maxBody)
+
+		select {
+		case r.queue <- e:
+		default:
+			r.dropped.Add(1)
+		}
+	})
+}
+
+func (r *Recorder) maxBodySize() int {
+	if r.MaxBodySize > 0 {
+		return r.MaxBodySize
+	}
+	return DefaultMaxBodySize
+}
+
+// header returns the recorded headers, with the pseudonymized ones.
+func (r *Recorder) header(h http.Header) http.Header {
+	recorded := http.Header{}
+	for _, k := range Headers {
+		if v := h.Values(k); len(v) != 0 {
+			recorded[k] = slices.Clone(v)
+		}
+	}
+	for _, k := range PseudonymizedHeaders {
+		for _, v := range h.Values(k) {
+			recorded.Add(k, r.pseudonym(v))
+		}
+	}
+	if len(recorded) == 0 {
+		return nil
+	}
+	return recorded
+}
+
+// body returns the recorded body, with the PseudonymizedFields pseudonymized.
+// Bodies longer than limit bytes or that aren't JSON can't be pseudonymized, so they're omitted, and flagged as truncated.
+func (r *Recorder) body(b []byte, limit int) (body string, truncated bool) {
+	if len(b) == 0 {
+		return "", false
+	}
+	var v any
+	if len(b) > limit || json.Unmarshal(b, &v) != nil {
+		return "", true
+	}
+	b, err := json.Marshal(r.pseudonymize(v))
+	if err != nil {
+		return "", true
+	}
+	return string(b), false
+}
+
+// pseudonymize the PseudonymizedFields of a decoded JSON value.
+func (r *Recorder) pseudonymize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, f := range v {
+			if s, ok := f.(string); ok && slices.Contains(PseudonymizedFields, k) {
+				v[k] = r.pseudonym(s)
+			} else {
+				v[k] = r.pseudonymize(f)
+			}
+		}
+	case []any:
+		for i, f := range v {
+			v[i] = r.pseudonymize(f)
+		}
+	}
+	return v
+}
+
+// pseudonym of a value, the same for the same value within a recording.
+func (r *Recorder) pseudonym(v string) string {
+	if v == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(v))
+	return "anon-" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Run writes the recorded exchanges to files until Shutdown is called.
+func (r *Recorder) Run(ctx context.Context) error {
+	r.init()
+	if err := os.MkdirAll(r.Dir, 0o750); err != nil {
+		return fmt.Errorf("cannot create recording directory: %w", err)
+	}
+	w := &rotatingWriter{dir: r.Dir, max: r.MaxFileSize}
+	if w.max <= 0 {
+		w.max = DefaultMaxFileSize
+	}
+	defer func() {
+		if err := w.Close(); err != nil {
+			r.Log.Error("cannot close recording", slog.Any("error", err))
+		}
+		r.Log.Info("request recording stopped",
+			slog.Int64("recorded", r.recorded.Load()),
+			slog.Int64("dropped", r.dropped.Load()))
+	}()
+	for {
+		select {
+		case e := <-r.queue:
+			if err := w.write(e); err != nil {
+				return fmt.Errorf("cannot write recording: %w", err)
+			}
+			r.recorded.Add(1)
+		case <-r.stop:
+			// Drain the exchanges of the last requests.
+			for {
+				select {
+				case e := <-r.queue:
+					if err := w.write(e); err != nil {
+						return fmt.Errorf("cannot write recording: %w", err)
+					}
+					r.recorded.Add(1)
+				default:
+					return nil
+				}
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Shutdown stops recording, writing the exchanges already recorded.
+func (r *Recorder) Shutdown(ctx context.Context) {
+	r.init()
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// rotatingWriter writes exchanges to files, starting a new one when the current one reaches its maximum size.
+type rotatingWriter struct {
+	dir string
+	max int64
+
+	f    *os.File
+	size int64
+}
+
+// write an exchange.
+func (w *rotatingWriter) write(e Exchange) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if w.f == nil || w.size >= w.max {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	// Unbuffered, so a recording can be replayed while it's still being written.
+	n, err := w.f.Write(append(b, '\n'))
+	w.size += int64(n)
+	return err
+}
+
+// rotate to a new file.
+func (w *rotatingWriter) rotate() error {
+	if err := w.Close(); err != nil {
+		return err
+	}
+	name := filepath.Join(w.dir, fmt.Sprintf("capture-%s.jsonl", time.Now().UTC().Format("20060102T150405.000000000Z")))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640) // #nosec G304
+	if err != nil {
+		return err
+	}
+	w.f, w.size = f, 0
+	return nil
+}
+
+// Close the current file, if any.
+func (w *rotatingWriter) Close() error {
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+// responseRecorder records the status and up to one byte past limit bytes of the body of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	limit       int
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	if rest := w.limit + 1 - w.body.Len(); rest > 0 {
+		w.body.Write(b[:min(len(b), rest)])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush the response, if supported.
+func (w *responseRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the ResponseWriter, for http.ResponseController.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,158 @@
+package replay_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/replay"
+)
+
+// record the requests made by fn to the handler, returning the recording files.
+func record(t *testing.T, sampleRate float64, handler http.Handler, fn func(server *httptest.Server)) []string {
+	t.Helper()
+	dir := t.TempDir()
+	r := &replay.Recorder{
+		Dir:         dir,
+		SampleRate:  sampleRate,
+		MaxBodySize: 100,
+		Log:         slog.Default(),
+	}
+	server := httptest.NewServer(r.Handler(handler))
+	done := make(chan error)
+	go func() {
+		done <- r.Run(context.Background())
+	}()
+	fn(server)
+	server.Close()
+	r.Shutdown(context.Background())
+	if err := <-done; err != nil {
+		t.Fatalf("Recorder.Run() error = %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "capture-*.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// echo the request body and X-User-ID header.
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Set-Cookie", "session=secret")
+	w.WriteHeader(http.StatusCreated)
+	if len(body) == 0 {
+		body = []byte(`{"user_id":"` + r.Header.Get("X-User-ID") + `"}`)
+	}
+	w.Write(body) // #nosec G104
+})
+
+// do a request to the server as the user, returning the response body.
+func do(t *testing.T, server *httptest.Server, method, path, userID, body string) string {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-User-ID", userID)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Accept-Language", "pt-BR")
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("cannot make request: %v", err)
+	}
+	defer resp.Body.Close()
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("cannot read response: %v", err)
+	}
+	return string(got)
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+	long := `{"reviewer_id":"bob","title":"` + strings.Repeat("a", 100) + `"}`
+	files := record(t, 1, echo, func(server *httptest.Server) {
+		body := `{"reviewer_id":"alice","score":5}`
+		if got := do(t, server, http.MethodPost, "/product/1/review", "alice", body); got != body {
+			t.Errorf("response body = %q, want the request body %q passed through the recorder", got, body)
+		}
+		do(t, server, http.MethodGet, "/favorites?limit=2", "alice", "")
+		do(t, server, http.MethodPost, "/product/1/review", "bob", long)
+		do(t, server, http.MethodPost, "/product/1/review", "bob", "reviewer_id=bob")
+	})
+	if len(files) != 1 {
+		t.Fatalf("recorded %d files, want 1", len(files))
+	}
+	var exchanges []replay.Exchange
+	if err := replay.ReadFile(files[0], func(e replay.Exchange) error {
+		exchanges = append(exchanges, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if len(exchanges) != 4 {
+		t.Fatalf("recorded %d exchanges, want 4", len(exchanges))
+	}
+	// Exchanges are queued once the response is written, so they might be recorded out of order.
+	slices.SortFunc(exchanges, func(a, b replay.Exchange) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	first, second, third, fourth := exchanges[0], exchanges[1], exchanges[2], exchanges[3]
+	alice := first.Request.Header.Get("X-User-ID")
+	if !strings.HasPrefix(alice, "anon-") || second.Request.Header.Get("X-User-ID") != alice {
+		t.Errorf("X-User-ID = %q, %q, want the same pseudonym", alice, second.Request.Header.Get("X-User-ID"))
+	}
+	if third.Request.Header.Get("X-User-ID") == alice {
+		t.Error("X-User-ID of different users has the same pseudonym")
+	}
+	if want := `{"reviewer_id":"` + alice + `","score":5}`; first.Request.Body != want || first.Response.Body != want {
+		t.Errorf("bodies = %q, %q, want %q", first.Request.Body, first.Response.Body, want)
+	}
+	if want := `{"user_id":"` + alice + `"}`; second.Response.Body != want {
+		t.Errorf("response body = %q, want %q", second.Response.Body, want)
+	}
+	if second.Request.URI != "/favorites?limit=2" || second.Response.Status != http.StatusCreated {
+		t.Errorf("request = %s, status %d, want /favorites?limit=2, 201", second.Request.URI, second.Response.Status)
+	}
+	if first.Request.Header.Get("Authorization") != "" || first.Response.Header.Get("Set-Cookie") != "" {
+		t.Error("recorded headers not on the allowlist")
+	}
+	if first.Request.Header.Get("Accept-Language") != "pt-BR" || first.Response.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v, %v, want Accept-Language and Content-Type", first.Request.Header, first.Response.Header)
+	}
+	// Bodies that can't be pseudonymized, as they're too long or aren't JSON, aren't recorded.
+	for _, e := range []replay.Exchange{third, fourth} {
+		if !e.Request.Truncated || e.Request.Body != "" || !e.Response.Truncated || e.Response.Body != "" {
+			t.Errorf("bodies = %q (truncated: %v), %q (truncated: %v), want them truncated and not recorded",
+				e.Request.Body, e.Request.Truncated, e.Response.Body, e.Response.Truncated)
+		}
+	}
+	capture, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(capture, []byte("bob")) {
+		t.Errorf("recording has the ID of a user: %s", capture)
+	}
+}
+
+func TestRecorderSampleRate(t *testing.T) {
+	t.Parallel()
+	files := record(t, 0, echo, func(server *httptest.Server) {
+		do(t, server, http.MethodGet, "/product/1", "alice", "")
+	})
+	if len(files) != 0 {
+		t.Errorf("recorded %d files, want none", len(files))
+	}
+}
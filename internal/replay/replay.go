@@ -0,0 +1,98 @@
+// Package replay records a sample of the HTTP requests and responses of the API to files, anonymized,
+// and replays them against another environment, such as staging, for regression and performance comparison.
+//
+// Only the headers the API reads or writes are recorded, and the IDs of users, such as the X-User-ID header
+// and the reviewer_id JSON field, are pseudonymized with a key generated by each Recorder, so the requests
+// of a user can be told apart within a recording, but not traced back to them.
+// Bodies that can't be pseudonymized, as they're too long or aren't JSON, aren't recorded.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Exchange of a request and its response, as recorded.
+type Exchange struct {
+	Time time.Time `json:"time"`
+
+	// Duration of the request, until the handler returned.
+	Duration time.Duration `json:"duration"`
+
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request recorded.
+type Request struct {
+	Method string      `json:"method"`
+	URI    string      `json:"uri"` // Path and query.
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+
+	// Truncated body, not recorded as it's longer than the MaxBodySize of the Recorder, or isn't JSON.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Response recorded.
+type Response struct {
+	Status    int         `json:"status"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      string      `json:"body,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// Headers recorded, as the ones the API reads or writes, besides the pseudonymized ones.
+var Headers = []string{
+	"Accept",
+	"Accept-Language",
+	"Content-Language",
+	"Content-Type",
+	"ETag",
+	"If-None-Match",
+	"X-Total-Count",
+}
+
+// PseudonymizedHeaders identifying users, whose values are replaced by pseudonyms.
+var PseudonymizedHeaders = []string{
+	"X-User-ID",
+}
+
+// PseudonymizedFields of JSON bodies identifying users, whose string values are replaced by pseudonyms.
+var PseudonymizedFields = []string{
+	"reviewer_id",
+	"user_id",
+}
+
+// ReadFile reads the exchanges recorded on a file, calling fn for each one, in order.
+func ReadFile(name string, fn func(Exchange) error) error {
+	f, err := os.Open(name) // #nosec G304
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return Read(f, fn)
+}
+
+// Read the exchanges recorded, one JSON object per line, calling fn for each one, in order.
+func Read(r io.Reader, fn func(Exchange) error) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for line := 1; ; line++ {
+		var e Exchange
+		switch err := dec.Decode(&e); {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return fmt.Errorf("cannot decode exchange %d: %w", line, err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxMismatches listed on a Report.
+const DefaultMaxMismatches = 10
+
+// Replayer issues recorded requests again against a target, such as another environment,
+// comparing their responses and latencies with the recorded ones.
+type Replayer struct {
+	// BaseURL of the target, such as http://staging.example.com:8080.
+	BaseURL string
+
+	// Client making the requests (default: http.DefaultClient).
+	Client *http.Client
+
+	// Methods of the requests replayed (default: GET and HEAD), so requests modifying data
+	// aren't repeated on the target unless asked for.
+	Methods []string
+
+	// Concurrency of the requests replayed at once (default: 1, in the recorded order).
+	Concurrency int
+
+	// CompareBodies of the responses with the recorded ones, besides their status, ignoring the PseudonymizedFields.
+	CompareBodies bool
+
+	// MaxMismatches listed on the Report (default: DefaultMaxMismatches).
+	MaxMismatches int
+}
+
+// Report of a replay.
+type Report struct {
+	// Replayed requests, and the ones Skipped, as their method isn't replayed or their body wasn't recorded.
+	Replayed int
+	Skipped  int
+
+	// Failed requests, without a response.
+	Failed int
+
+	// StatusMismatches and BodyMismatches of responses differing from the recorded ones.
+	StatusMismatches int
+	BodyMismatches   int
+
+	// Mismatches, up to MaxMismatches of them.
+	Mismatches []Mismatch
+
+	// Recorded latencies of the replayed requests, and their Latency on the target.
+	Recorded Percentiles
+	Latency  Percentiles
+}
+
+// Mismatch of a replayed request.
+type Mismatch struct {
+	Method string
+	URI    string
+
+	// Status of the response, and the recorded one.
+	Status     int
+	WantStatus int
+
+	// Err of a failed request, if any.
+	Err error
+}
+
+func (m Mismatch) String() string {
+	if m.Err != nil {
+		return fmt.Sprintf("%s %s: %v", m.Method, m.URI, m.Err)
+	}
+	if m.Status != m.WantStatus {
+		return fmt.Sprintf("%s %s: status %d, recorded %d", m.Method, m.URI, m.Status, m.WantStatus)
+	}
+	return fmt.Sprintf("%s %s: body differs", m.Method, m.URI)
+}
+
+// Percentiles of latencies.
+type Percentiles struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+// percentiles of the durations, sorting them.
+func percentiles(d []time.Duration) Percentiles {
+	if len(d) == 0 {
+		return Percentiles{}
+	}
+	slices.Sort(d)
+	at := func(p float64) time.Duration {
+		return d[min(int(p*float64(len(d))), len(d)-1)]
+	}
+	return Percentiles{
+		P50: at(0.50),
+		P95: at(0.95),
+		P99: at(0.99),
+	}
+}
+
+// Replay the exchanges recorded on the files, in order.
+func (r *Replayer) Replay(ctx context.Context, files ...string) (*Report, error) {
+	concurrency := max(r.Concurrency, 1)
+	var (
+		report   = &Report{}
+		recorded []time.Duration
+		latency  []time.Duration
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		queue    = make(chan Exchange)
+	)
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range queue {
+				status, matches, d, err := r.replay(ctx, e)
+				mu.Lock()
+				report.Replayed++
+				m := Mismatch{
+					Method:     e.Request.Method,
+					URI:        e.Request.URI,
+					Status:     status,
+					WantStatus: e.Response.Status,
+					Err:        err,
+				}
+				switch {
+				case err != nil:
+					report.Failed++
+				case status != e.Response.Status:
+					report.StatusMismatches++
+				case !matches:
+					report.BodyMismatches++
+				}
+				if (err != nil || status != e.Response.Status || !matches) && len(report.Mismatches) < r.maxMismatches() {
+					report.Mismatches = append(report.Mismatches, m)
+				}
+				if err == nil {
+					recorded = append(recorded, e.Duration)
+					latency = append(latency, d)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	var err error
+	for _, name := range files {
+		err = ReadFile(name, func(e Exchange) error {
+			if !r.replayed(e) {
+				mu.Lock()
+				report.Skipped++
+				mu.Unlock()
+				return nil
+			}
+			select {
+			case queue <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			err = fmt.Errorf("cannot replay %s: %w", name, err)
+			break
+		}
+	}
+	close(queue)
+	wg.Wait()
+	report.Recorded = percentiles(recorded)
+	report.Latency = percentiles(latency)
+	return report, err
+}
+
+func (r *Replayer) maxMismatches() int {
+	if r.MaxMismatches > 0 {
+		return r.MaxMismatches
+	}
+	return DefaultMaxMismatches
+}
+
+// replayed returns whether the exchange is replayed.
+func (r *Replayer) replayed(e Exchange) bool {
+	methods := r.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead}
+	}
+	return slices.Contains(methods, e.Request.Method) && !e.Request.Truncated
+}
+
+// replay a request, returning the status of its response, if its body matches the recorded one, and its duration.
+func (r *Replayer) replay(ctx context.Context, e Exchange) (status int, matches bool, d time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, e.Request.Method, strings.TrimSuffix(r.BaseURL, "/")+e.Request.URI, strings.NewReader(e.Request.Body))
+	if err != nil {
+		return 0, false, 0, err
+	}
+	for k, v := range e.Request.Header {
+		req.Header[k] = v
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	d = time.Since(start)
+	if err != nil {
+		return 0, false, 0, err
+	}
+	matches = !r.CompareBodies || e.Response.Truncated || sameBody(body, e.Response.Body)
+	return resp.StatusCode, matches, d, nil
+}
+
+// sameBody returns whether a body is the same as the recorded one, as JSON without the PseudonymizedFields,
+// or byte by byte if it isn't JSON.
+func sameBody(body []byte, recorded string) bool {
+	var got, want any
+	if json.Unmarshal(body, &got) != nil || json.Unmarshal([]byte(recorded), &want) != nil {
+		return string(body) == recorded
+	}
+	return reflect.DeepEqual(withoutPseudonymized(got), withoutPseudonymized(want))
+}
+
+// withoutPseudonymized removes the PseudonymizedFields of a decoded JSON value.
+func withoutPseudonymized(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, f := range v {
+			if slices.Contains(PseudonymizedFields, k) {
+				delete(v, k)
+			} else {
+				v[k] = withoutPseudonymized(f)
+			}
+		}
+	case []any:
+		for i, f := range v {
+			v[i] = withoutPseudonymized(f)
+		}
+	}
+	return v
+}
@@ -0,0 +1,68 @@
+package replay_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/replay"
+)
+
+func TestReplayer(t *testing.T) {
+	t.Parallel()
+	// The server responds with the reviewer of the X-User-ID header, and the target has regressed on some products.
+	products := func(regressed bool) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/product/missing":
+				http.NotFound(w, r)
+			case regressed && r.URL.Path == "/product/2":
+				w.Write([]byte(`{"id":"2","price":200}`)) // #nosec G104
+			case regressed && r.URL.Path == "/product/3":
+				http.Error(w, "internal error", http.StatusInternalServerError)
+			default:
+				w.Write([]byte(`{"id":"` + strings.TrimPrefix(r.URL.Path, "/product/") + `","price":100,"reviewer_id":"` + r.Header.Get("X-User-ID") + `"}`)) // #nosec G104
+			}
+		})
+	}
+	files := record(t, 1, products(false), func(server *httptest.Server) {
+		for _, path := range []string{"/product/1", "/product/2", "/product/3", "/product/missing"} {
+			do(t, server, http.MethodGet, path, "alice", "")
+		}
+		do(t, server, http.MethodPost, "/product", "alice", `{"name":"Chair"}`)
+	})
+	target := httptest.NewServer(products(true))
+	t.Cleanup(target.Close)
+
+	r := &replay.Replayer{
+		BaseURL:       target.URL,
+		Client:        target.Client(),
+		Concurrency:   2,
+		CompareBodies: true,
+	}
+	report, err := r.Replay(context.Background(), files...)
+	if err != nil {
+		t.Fatalf("Replayer.Replay() error = %v", err)
+	}
+	if report.Replayed != 4 || report.Skipped != 1 || report.Failed != 0 {
+		t.Errorf("replayed %d, skipped %d, failed %d, want 4, 1, 0", report.Replayed, report.Skipped, report.Failed)
+	}
+	// The pseudonymized reviewer_id differs, but it's ignored.
+	if report.StatusMismatches != 1 || report.BodyMismatches != 1 || len(report.Mismatches) != 2 {
+		t.Errorf("mismatches = %d status, %d body, %v, want 1, 1", report.StatusMismatches, report.BodyMismatches, report.Mismatches)
+	}
+	if report.Latency.P99 <= 0 || report.Latency.P50 > report.Latency.P99 {
+		t.Errorf("latency percentiles = %+v", report.Latency)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	target.Close()
+	r.Concurrency = 1
+	if report, err := r.Replay(ctx, files...); err != nil || report.Failed != 4 {
+		t.Errorf("Replayer.Replay() to a closed target = %+v, %v, want 4 failed requests", report, err)
+	}
+}
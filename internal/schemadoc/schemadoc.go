@@ -0,0 +1,174 @@
+// Package schemadoc generates a data dictionary of a PostgreSQL schema by introspecting the database.
+package schemadoc
+
+import (
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"text/template"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// Table of the schema.
+type Table struct {
+	Name        string
+	Comment     string
+	Columns     []Column
+	Constraints []Constraint
+	Indexes     []Index
+}
+
+// Column of a table.
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+	Comment  string
+}
+
+// Constraint of a table.
+type Constraint struct {
+	Name       string
+	Type       string
+	Definition string
+}
+
+// Index of a table.
+type Index struct {
+	Name       string
+	Definition string
+}
+
+// Tables of the schema, in alphabetical order.
+func Tables(ctx context.Context, conn database.PGXQuerier, schema string) ([]*Table, error) {
+	rows, err := conn.Query(ctx, `SELECT c.relname, COALESCE(obj_description(c.oid, 'pg_class'), '')
+	FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relkind IN ('r', 'p')
+	ORDER BY c.relname`, schema)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Table, error) {
+		var t Table
+		err := row.Scan(&t.Name, &t.Comment)
+		return &t, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get tables: %w", err)
+	}
+	for _, t := range tables {
+		if err := describe(ctx, conn, schema, t); err != nil {
+			return nil, fmt.Errorf("cannot describe table %q: %w", t.Name, err)
+		}
+	}
+	return tables, nil
+}
+
+// describe the columns, constraints, and indexes of the table.
+func describe(ctx context.Context, conn database.PGXQuerier, schema string, t *Table) error {
+	rows, err := conn.Query(ctx, `SELECT
+		c.column_name,
+		CASE WHEN c.data_type = 'USER-DEFINED' THEN c.udt_name ELSE c.data_type END,
+		c.is_nullable = 'YES',
+		COALESCE(c.column_default, ''),
+		COALESCE(col_description(format('%I.%I', c.table_schema, c.table_name)::regclass, c.ordinal_position), '')
+	FROM information_schema.columns c
+	WHERE c.table_schema = $1 AND c.table_name = $2
+	ORDER BY c.ordinal_position`, schema, t.Name)
+	if err != nil {
+		return err
+	}
+	if t.Columns, err = pgx.CollectRows(rows, pgx.RowToStructByPos[Column]); err != nil {
+		return err
+	}
+
+	rows, err = conn.Query(ctx, `SELECT
+		con.conname,
+		CASE con.contype
+			WHEN 'p' THEN 'PRIMARY KEY'
+			WHEN 'f' THEN 'FOREIGN KEY'
+			WHEN 'u' THEN 'UNIQUE'
+			WHEN 'c' THEN 'CHECK'
+			WHEN 'x' THEN 'EXCLUDE'
+			ELSE con.contype::text
+		END,
+		pg_get_constraintdef(con.oid)
+	FROM pg_constraint con
+	JOIN pg_class c ON c.oid = con.conrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relname = $2
+	ORDER BY con.conname`, schema, t.Name)
+	if err != nil {
+		return err
+	}
+	if t.Constraints, err = pgx.CollectRows(rows, pgx.RowToStructByPos[Constraint]); err != nil {
+		return err
+	}
+
+	rows, err = conn.Query(ctx, `SELECT indexname, indexdef FROM pg_indexes
+	WHERE schemaname = $1 AND tablename = $2
+	ORDER BY indexname`, schema, t.Name)
+	if err != nil {
+		return err
+	}
+	t.Indexes, err = pgx.CollectRows(rows, pgx.RowToStructByPos[Index])
+	return err
+}
+
+var markdown = template.Must(template.New("markdown").Parse(`# Data dictionary
+{{range .}}
+## {{.Name}}
+{{with .Comment}}
+{{.}}
+{{end}}
+| Column | Type | Nullable | Default | Description |
+| - | - | - | - | - |
+{{range .Columns}}| {{.Name}} | {{.Type}} | {{if .Nullable}}yes{{else}}no{{end}} | {{with .Default}}` + "`{{.}}`" + `{{end}} | {{.Comment}} |
+{{end}}{{with .Constraints}}
+| Constraint | Type | Definition |
+| - | - | - |
+{{range .}}| {{.Name}} | {{.Type}} | ` + "`{{.Definition}}`" + ` |
+{{end}}{{end}}{{with .Indexes}}
+| Index | Definition |
+| - | - |
+{{range .}}| {{.Name}} | ` + "`{{.Definition}}`" + ` |
+{{end}}{{end}}{{end}}`))
+
+var html = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Data dictionary</title></head>
+<body>
+<h1>Data dictionary</h1>
+{{range .}}<h2 id="{{.Name}}">{{.Name}}</h2>
+{{with .Comment}}<p>{{.}}</p>
+{{end}}<table>
+<tr><th>Column</th><th>Type</th><th>Nullable</th><th>Default</th><th>Description</th></tr>
+{{range .Columns}}<tr><td>{{.Name}}</td><td>{{.Type}}</td><td>{{if .Nullable}}yes{{else}}no{{end}}</td><td><code>{{.Default}}</code></td><td>{{.Comment}}</td></tr>
+{{end}}</table>
+{{with .Constraints}}<table>
+<tr><th>Constraint</th><th>Type</th><th>Definition</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{.Type}}</td><td><code>{{.Definition}}</code></td></tr>
+{{end}}</table>
+{{end}}{{with .Indexes}}<table>
+<tr><th>Index</th><th>Definition</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td><code>{{.Definition}}</code></td></tr>
+{{end}}</table>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// Write the data dictionary of the tables in the given format: "markdown" or "html".
+func Write(w io.Writer, tables []*Table, format string) error {
+	switch format {
+	case "markdown":
+		return markdown.Execute(w, tables)
+	case "html":
+		return html.Execute(w, tables)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
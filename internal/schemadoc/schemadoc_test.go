@@ -0,0 +1,67 @@
+package schemadoc
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+)
+
+var force = flag.Bool("force", false, "Force cleaning the database before starting")
+
+func TestMain(m *testing.M) {
+	if os.Getenv("INTEGRATION_TESTDB") != "true" {
+		log.Printf("Skipping tests that require database connection")
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestTables(t *testing.T) {
+	t.Parallel()
+	migration := sqltest.New(t, sqltest.Options{
+		Force: *force,
+		Files: os.DirFS("../../migrations"),
+	})
+	pool := migration.Setup(context.Background(), "")
+
+	tables, err := Tables(context.Background(), pool, "public")
+	if err != nil {
+		t.Fatalf("Tables() error = %v", err)
+	}
+	var product *Table
+	for _, tt := range tables {
+		if tt.Name == "product" {
+			product = tt
+		}
+	}
+	if product == nil {
+		t.Fatalf("Tables() = %v, missing product table", tables)
+	}
+	if len(product.Columns) == 0 || product.Columns[0].Name != "id" || product.Columns[0].Comment != "assume id is the barcode" {
+		t.Errorf("unexpected product columns: %+v", product.Columns)
+	}
+
+	for _, format := range []string{"markdown", "html"} {
+		var buf bytes.Buffer
+		if err := Write(&buf, tables, format); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		for _, want := range []string{"product_price_check", "product_name", "product_status"} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s data dictionary is missing %q", format, want)
+			}
+		}
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, nil, "pdf"); err == nil || err.Error() != `unknown format "pdf"` {
+		t.Errorf("Write() error = %v, want unknown format", err)
+	}
+}
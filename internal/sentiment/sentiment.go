@@ -0,0 +1,64 @@
+// Package sentiment scores the sentiment of short English texts, such as product reviews,
+// by counting their positive and negative words, flipping the ones following a negation, as in "not good".
+//
+// It's a lightweight default for inventory.SentimentScorer.
+// For better accuracy, or for other languages, use a statistical model or an external service instead.
+package sentiment
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// polarity of words: 1 for positive and -1 for negative.
+var polarity = func() map[string]int {
+	m := map[string]int{}
+	for _, w := range []string{
+		"good", "great", "excellent", "amazing", "awesome", "love", "loved", "like", "liked", "perfect",
+		"nice", "best", "beautiful", "sturdy", "solid", "comfortable", "recommend", "happy", "fantastic", "easy",
+	} {
+		m[w] = 1
+	}
+	for _, w := range []string{
+		"bad", "poor", "terrible", "awful", "horrible", "hate", "hated", "worst", "broken", "broke",
+		"cheap", "flimsy", "uncomfortable", "disappointed", "disappointing", "useless", "ugly", "wobbly", "hard", "return",
+	} {
+		m[w] = -1
+	}
+	return m
+}()
+
+// negations flip the polarity of the next word.
+var negations = map[string]bool{
+	"not": true, "no": true, "never": true, "don't": true, "doesn't": true, "didn't": true, "isn't": true, "wasn't": true,
+}
+
+// Lexicon scores the sentiment of texts by their positive and negative words.
+type Lexicon struct{}
+
+// ScoreSentiment returns the difference between the positive and negative words of the text,
+// relative to their sum, from -1 (negative) to 1 (positive), or 0 if it has none.
+func (Lexicon) ScoreSentiment(ctx context.Context, text string) (float64, error) {
+	var positive, negative int
+	negated := false
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		p := polarity[word]
+		if negated {
+			p = -p
+		}
+		switch p {
+		case 1:
+			positive++
+		case -1:
+			negative++
+		}
+		negated = negations[word]
+	}
+	if positive+negative == 0 {
+		return 0, nil
+	}
+	return float64(positive-negative) / float64(positive+negative), nil
+}
@@ -0,0 +1,33 @@
+package sentiment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/sentiment"
+)
+
+func TestLexicon(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{"", 0},
+		{"A desk.", 0},
+		{"Great desk, I love it!", 1},
+		{"Terrible. It broke after a week.", -1},
+		{"Beautiful, but wobbly.", 0},
+		{"Not good, not bad.", 0},
+		{"Sturdy and comfortable, but I didn't like the color.", 1.0 / 3},
+	}
+	for _, tc := range tests {
+		got, err := sentiment.Lexicon{}.ScoreSentiment(context.Background(), tc.text)
+		if err != nil {
+			t.Errorf("Lexicon.ScoreSentiment(%q) error = %v", tc.text, err)
+		}
+		if got != tc.want {
+			t.Errorf("Lexicon.ScoreSentiment(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+}
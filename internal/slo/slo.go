@@ -0,0 +1,156 @@
+// Package slo tracks service level objectives of endpoints over a rolling window.
+package slo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Objective of an endpoint.
+type Objective struct {
+	// Availability is the target ratio of good calls, such as 0.999.
+	Availability float64
+
+	// Latency threshold. Successful calls slower than it are bad calls too.
+	// Zero disables the latency objective.
+	Latency time.Duration
+
+	// MinCalls to an endpoint within the window for its error budget to count as exhausted,
+	// so a few failed calls to a rarely called endpoint don't exhaust it (default: DefaultMinCalls).
+	MinCalls int64
+}
+
+// DefaultMinCalls to an endpoint within the window for its error budget to count as exhausted.
+const DefaultMinCalls = 100
+
+// buckets in a window.
+const buckets = 60
+
+// MinWindow of a Tracker, so each of its buckets covers at least a second.
+const MinWindow = buckets * time.Second
+
+// bucket of calls in a fraction of the window.
+type bucket struct {
+	start time.Time
+	total int64
+	bad   int64
+}
+
+// Tracker of the objective of endpoints over a rolling window.
+type Tracker struct {
+	objective Objective
+	window    time.Duration
+
+	mu        sync.Mutex
+	endpoints map[string]*[buckets]bucket
+
+	now func() time.Time
+}
+
+// NewTracker creates a Tracker of the objective over a rolling window, such as one hour.
+// The window must be at least MinWindow.
+func NewTracker(objective Objective, window time.Duration) (*Tracker, error) {
+	if window < MinWindow {
+		return nil, fmt.Errorf("SLO window must be at least %v", MinWindow)
+	}
+	if objective.MinCalls == 0 {
+		objective.MinCalls = DefaultMinCalls
+	}
+	return &Tracker{
+		objective: objective,
+		window:    window,
+		endpoints: map[string]*[buckets]bucket{},
+		now:       time.Now,
+	}, nil
+}
+
+// Record a call to an endpoint, whether it failed, and its duration.
+func (t *Tracker) Record(endpoint string, failed bool, d time.Duration) {
+	bad := failed || t.objective.Latency != 0 && d > t.objective.Latency
+	now := t.now()
+	size := t.window / buckets
+	start := now.Truncate(size)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.endpoints[endpoint]
+	if !ok {
+		e = new([buckets]bucket)
+		t.endpoints[endpoint] = e
+	}
+	b := &e[int(now.UnixNano()/int64(size))%buckets]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	b.total++
+	if bad {
+		b.bad++
+	}
+}
+
+// BurnRate of the error budget of an endpoint over the window.
+// A burn rate of 1 consumes the budget exactly by the end of the window, and greater values exhaust it earlier.
+func (t *Tracker) BurnRate(endpoint string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.burnRate(t.calls(t.endpoints[endpoint]))
+}
+
+// calls to an endpoint within the window, and how many of them were bad.
+func (t *Tracker) calls(e *[buckets]bucket) (total, bad int64) {
+	if e == nil {
+		return 0, 0
+	}
+	oldest := t.now().Add(-t.window)
+	for _, b := range e {
+		if b.start.After(oldest) {
+			total += b.total
+			bad += b.bad
+		}
+	}
+	return total, bad
+}
+
+func (t *Tracker) burnRate(total, bad int64) float64 {
+	budget := 1 - t.objective.Availability
+	if total == 0 || budget <= 0 {
+		return 0
+	}
+	return float64(bad) / float64(total) / budget
+}
+
+// Exhausted returns the endpoints that consumed their error budget over the window (burn rate of at least 1),
+// out of the ones called at least MinCalls times within it.
+func (t *Tracker) Exhausted() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var exhausted []string
+	for name, e := range t.endpoints {
+		if total, bad := t.calls(e); total >= t.objective.MinCalls && t.burnRate(total, bad) >= 1 {
+			exhausted = append(exhausted, name)
+		}
+	}
+	sort.Strings(exhausted)
+	return exhausted
+}
+
+// RegisterMetrics exposes the burn rate of every endpoint as the slo.burn_rate gauge.
+func (t *Tracker) RegisterMetrics(meter metric.Meter) error {
+	_, err := meter.Float64ObservableGauge("slo.burn_rate",
+		metric.WithDescription("Error budget burn rate over the SLO window."),
+		metric.WithFloat64Callback(func(ctx context.Context, o metric.Float64Observer) error {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			for name, e := range t.endpoints {
+				o.Observe(t.burnRate(t.calls(e)), metric.WithAttributes(attribute.String("endpoint", name)))
+			}
+			return nil
+		}))
+	return err
+}
@@ -0,0 +1,78 @@
+package slo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tr, err := NewTracker(Objective{Availability: 0.9, Latency: time.Second, MinCalls: 20}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+	tr.now = func() time.Time { return now }
+
+	for i := 0; i < 18; i++ {
+		tr.Record("/api.v1.Inventory/GetProduct", false, time.Millisecond)
+	}
+	tr.Record("/api.v1.Inventory/GetProduct", true, time.Millisecond)
+	tr.Record("/api.v1.Inventory/GetProduct", false, 2*time.Second) // Too slow.
+	tr.Record("/api.v1.Inventory/SearchProducts", false, time.Millisecond)
+
+	if got := tr.BurnRate("/api.v1.Inventory/GetProduct"); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Tracker.BurnRate() = %v, want 1", got)
+	}
+	if got := tr.BurnRate("/api.v1.Inventory/SearchProducts"); got != 0 {
+		t.Errorf("Tracker.BurnRate() = %v, want 0", got)
+	}
+	if got := tr.Exhausted(); len(got) != 1 || got[0] != "/api.v1.Inventory/GetProduct" {
+		t.Errorf("Tracker.Exhausted() = %v, want GetProduct", got)
+	}
+
+	// Calls out of the window are forgotten.
+	now = now.Add(2 * time.Hour)
+	if got := tr.BurnRate("/api.v1.Inventory/GetProduct"); got != 0 {
+		t.Errorf("Tracker.BurnRate() = %v after the window, want 0", got)
+	}
+	if got := tr.Exhausted(); len(got) != 0 {
+		t.Errorf("Tracker.Exhausted() = %v after the window, want none", got)
+	}
+}
+
+func TestTrackerMinCalls(t *testing.T) {
+	t.Parallel()
+	tr, err := NewTracker(Objective{Availability: 0.999}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+
+	// A single failed call of a rarely called endpoint burns its budget fast, but doesn't exhaust it.
+	tr.Record("/api.v1.Inventory/GetProductReview", true, time.Millisecond)
+	if got := tr.BurnRate("/api.v1.Inventory/GetProductReview"); got < 1 {
+		t.Errorf("Tracker.BurnRate() = %v, want at least 1", got)
+	}
+	if got := tr.Exhausted(); len(got) != 0 {
+		t.Errorf("Tracker.Exhausted() = %v with fewer than DefaultMinCalls calls, want none", got)
+	}
+	for range DefaultMinCalls - 1 {
+		tr.Record("/api.v1.Inventory/GetProductReview", false, time.Millisecond)
+	}
+	if got := tr.Exhausted(); len(got) != 1 {
+		t.Errorf("Tracker.Exhausted() = %v with DefaultMinCalls calls, want GetProductReview", got)
+	}
+}
+
+func TestNewTrackerWindow(t *testing.T) {
+	t.Parallel()
+	for _, window := range []time.Duration{0, 59 * time.Nanosecond, time.Second} {
+		if _, err := NewTracker(Objective{Availability: 0.999}, window); err == nil {
+			t.Errorf("NewTracker() with window %v error = nil, want an error", window)
+		}
+	}
+	if _, err := NewTracker(Objective{Availability: 0.999}, MinWindow); err != nil {
+		t.Errorf("NewTracker() with MinWindow error = %v", err)
+	}
+}
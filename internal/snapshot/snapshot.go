@@ -0,0 +1,242 @@
+// Package snapshot dumps the products and reviews of the database to a compressed archive, and restores them,
+// to clone an environment, such as to reproduce an issue with production data on a staging database.
+//
+// The archive is a ZIP file with the rows of each table in the CSV format of COPY,
+// and a manifest.json file with the schema version of the database it was dumped from.
+// An archive is only restored to a database with the same schema version, as the columns must match.
+package snapshot
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// Tables in the archive, in the order they're restored to satisfy their foreign keys.
+var Tables = []string{"product", "review"}
+
+const manifestName = "manifest.json"
+
+// Manifest of an archive.
+type Manifest struct {
+	// SchemaVersion of the database the archive was dumped from.
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Tables        []Table   `json:"tables"`
+}
+
+// Table in an archive.
+type Table struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// Progress of copying a table, called as its data is read or written.
+// Total is the size of the data of the table in bytes, or -1 if it's unknown, as when dumping.
+type Progress func(table string, done, total int64)
+
+// Dump the tables to a compressed archive written to w, from a consistent snapshot of the database.
+func Dump(ctx context.Context, db database.PGX, w io.Writer, progress Progress) (*Manifest, error) {
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot begin transaction: %w", err)
+	}
+	defer tx.Rollback(context.Background()) // #nosec G104
+
+	m := &Manifest{
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := tx.QueryRow(ctx, `SELECT "version" FROM "schema_version"`).Scan(&m.SchemaVersion); err != nil {
+		return nil, fmt.Errorf("cannot get database schema version: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	for _, table := range Tables {
+		f, err := zw.Create(table + ".csv")
+		if err != nil {
+			return nil, err
+		}
+		cw := &progressWriter{w: f, table: table, progress: progress}
+		tag, err := tx.Conn().PgConn().CopyTo(ctx, cw,
+			fmt.Sprintf("COPY %s TO STDOUT (FORMAT csv, HEADER)", pgx.Identifier{table}.Sanitize()))
+		if err != nil {
+			return nil, fmt.Errorf("cannot dump %s: %w", table, err)
+		}
+		m.Tables = append(m.Tables, Table{Name: table, Rows: tag.RowsAffected()})
+	}
+
+	f, err := zw.Create(manifestName)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.NewEncoder(f).Encode(m); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("cannot write archive: %w", err)
+	}
+	return m, nil
+}
+
+// SchemaVersionError is returned by Restore when the schema version of the database
+// is different from the one of the archive.
+type SchemaVersionError struct {
+	Archive  int
+	Database int
+}
+
+func (e *SchemaVersionError) Error() string {
+	return fmt.Sprintf("archive has schema version %d, but the database has %d", e.Archive, e.Database)
+}
+
+// ErrNotEmpty is returned by Restore when the tables of the database already have rows.
+var ErrNotEmpty = errors.New("database tables to restore must be empty")
+
+// Restore the tables from a compressed archive, all of them or none.
+// The tables of the database must be empty.
+func Restore(ctx context.Context, db database.PGX, r io.ReaderAt, size int64, progress Progress) (*Manifest, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read archive: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	m, err := readManifest(files[manifestName])
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot begin transaction: %w", err)
+	}
+	defer tx.Rollback(context.Background()) // #nosec G104
+
+	var version int
+	if err := tx.QueryRow(ctx, `SELECT "version" FROM "schema_version"`).Scan(&version); err != nil {
+		return nil, fmt.Errorf("cannot get database schema version: %w", err)
+	}
+	if version != m.SchemaVersion {
+		return nil, &SchemaVersionError{Archive: m.SchemaVersion, Database: version}
+	}
+
+	for _, table := range Tables {
+		var exists bool
+		if err := tx.QueryRow(ctx,
+			fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s)", pgx.Identifier{table}.Sanitize())).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("cannot check if %s is empty: %w", table, err)
+		}
+		if exists {
+			return nil, fmt.Errorf("%w: %s has rows", ErrNotEmpty, table)
+		}
+	}
+
+	for _, t := range m.Tables {
+		f, ok := files[t.Name+".csv"]
+		if !ok {
+			return nil, fmt.Errorf("archive is missing table %s", t.Name)
+		}
+		if err := restoreTable(ctx, tx, f, t, progress); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("cannot commit transaction: %w", err)
+	}
+	return m, nil
+}
+
+// readManifest of an archive, checking it only has known tables.
+func readManifest(f *zip.File) (*Manifest, error) {
+	if f == nil {
+		return nil, errors.New("archive is missing its manifest")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read manifest: %w", err)
+	}
+	defer rc.Close()
+	var m Manifest
+	if err := json.NewDecoder(rc).Decode(&m); err != nil {
+		return nil, fmt.Errorf("cannot decode manifest: %w", err)
+	}
+	if len(m.Tables) != len(Tables) {
+		return nil, fmt.Errorf("archive has %d tables, want %d", len(m.Tables), len(Tables))
+	}
+	for i, t := range m.Tables {
+		if t.Name != Tables[i] {
+			return nil, fmt.Errorf("unexpected table %q in archive", t.Name)
+		}
+	}
+	return &m, nil
+}
+
+func restoreTable(ctx context.Context, tx pgx.Tx, f *zip.File, t Table, progress Progress) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", t.Name, err)
+	}
+	defer rc.Close()
+	cr := &progressReader{
+		r:        rc,
+		table:    t.Name,
+		total:    int64(f.UncompressedSize64), // #nosec G115
+		progress: progress,
+	}
+	tag, err := tx.Conn().PgConn().CopyFrom(ctx, cr,
+		fmt.Sprintf("COPY %s FROM STDIN (FORMAT csv, HEADER)", pgx.Identifier{t.Name}.Sanitize()))
+	if err != nil {
+		return fmt.Errorf("cannot restore %s: %w", t.Name, err)
+	}
+	if rows := tag.RowsAffected(); rows != t.Rows {
+		return fmt.Errorf("restored %d rows of %s, but the manifest has %d", rows, t.Name, t.Rows)
+	}
+	return nil
+}
+
+// progressWriter reports the progress of the data written.
+type progressWriter struct {
+	w        io.Writer
+	table    string
+	done     int64
+	progress Progress
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.done += int64(n)
+	if pw.progress != nil {
+		pw.progress(pw.table, pw.done, -1)
+	}
+	return n, err
+}
+
+// progressReader reports the progress of the data read.
+type progressReader struct {
+	r        io.Reader
+	table    string
+	done     int64
+	total    int64
+	progress Progress
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.done += int64(n)
+	if pr.progress != nil {
+		pr.progress(pr.table, pr.done, pr.total)
+	}
+	return n, err
+}
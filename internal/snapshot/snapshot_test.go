@@ -0,0 +1,95 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/henvic/pgtools/sqltest"
+)
+
+var force = flag.Bool("force", false, "Force cleaning the database before starting")
+
+func TestMain(m *testing.M) {
+	if os.Getenv("INTEGRATION_TESTDB") != "true" {
+		log.Printf("Skipping tests that require database connection")
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestDumpRestore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	source := sqltest.New(t, sqltest.Options{
+		Force:                   *force,
+		TemporaryDatabasePrefix: "test_snapshot_pkg", // Avoid a clash between database names of packages on parallel execution.
+		Files:                   os.DirFS("../../migrations"),
+	}).Setup(ctx, "")
+	if _, err := source.Exec(ctx, `INSERT INTO product (id, name, description, price, slug) VALUES
+		('product', 'Product', 'A product, with "quotes"
+and a new line', 100, 'product');
+		INSERT INTO review (id, product_id, reviewer_id, title, description, score) VALUES
+		('review', 'product', 'reviewer', 'Review', '', 5)`); err != nil {
+		t.Fatalf("cannot insert rows: %v", err)
+	}
+
+	var archive bytes.Buffer
+	m, err := Dump(ctx, source, &archive, nil)
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	if len(m.Tables) != 2 || m.Tables[0].Rows != 1 || m.Tables[1].Rows != 1 {
+		t.Errorf("Dump() tables = %+v, want one product and one review", m.Tables)
+	}
+
+	t.Run("restore", func(t *testing.T) {
+		target := sqltest.New(t, sqltest.Options{
+			Force:                   *force,
+			TemporaryDatabasePrefix: "test_snapshot_pkg",
+			Files:                   os.DirFS("../../migrations"),
+		}).Setup(ctx, "")
+		var progressed bool
+		if _, err := Restore(ctx, target, bytes.NewReader(archive.Bytes()), int64(archive.Len()),
+			func(table string, done, total int64) {
+				progressed = true
+			}); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		if !progressed {
+			t.Error("Restore() didn't report progress")
+		}
+		var description string
+		var score int
+		if err := target.QueryRow(ctx, `SELECT p.description, r.score FROM product p JOIN review r ON r.product_id = p.id`).
+			Scan(&description, &score); err != nil {
+			t.Fatalf("cannot get restored rows: %v", err)
+		}
+		if description != "A product, with \"quotes\"\nand a new line" || score != 5 {
+			t.Errorf("restored description = %q and score = %d", description, score)
+		}
+
+		// Restoring again must fail, as the tables aren't empty anymore.
+		if _, err := Restore(ctx, target, bytes.NewReader(archive.Bytes()), int64(archive.Len()), nil); !errors.Is(err, ErrNotEmpty) {
+			t.Errorf("Restore() error = %v, want %v", err, ErrNotEmpty)
+		}
+		if _, err := target.Exec(ctx, `DELETE FROM product; UPDATE schema_version SET version = version - 1`); err != nil {
+			t.Fatalf("cannot change schema version: %v", err)
+		}
+		var sve *SchemaVersionError
+		if _, err := Restore(ctx, target, bytes.NewReader(archive.Bytes()), int64(archive.Len()), nil); !errors.As(err, &sve) {
+			t.Errorf("Restore() error = %v, want *SchemaVersionError", err)
+		}
+	})
+}
+
+func TestRestoreInvalidArchive(t *testing.T) {
+	t.Parallel()
+	if _, err := Restore(context.Background(), nil, bytes.NewReader([]byte("not a zip")), 9, nil); err == nil {
+		t.Error("Restore() should fail with an invalid archive")
+	}
+}
@@ -0,0 +1,190 @@
+// Package summary generates the summaries of the reviews of products in the background, such as their pros and cons.
+//
+// Summaries are written by a Summarizer, which is the wiring point for external services, such as a language model.
+// Scores is the default, writing a deterministic summary from the scores and titles of the reviews.
+//
+// A Job generates the summaries missing or outdated by the reviews created, modified, or deleted since then,
+// so reviews are summarized asynchronously, without slowing down or failing their requests.
+package summary
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+)
+
+// MaxReviews summarized, the most recent ones.
+const MaxReviews = 100
+
+// Summarizer of the reviews of a product.
+type Summarizer interface {
+	// Summarize the reviews of a product, given the most recent first.
+	Summarize(ctx context.Context, productID string, reviews []*inventory.ProductReview) (string, error)
+}
+
+// Product whose review summary is missing or outdated.
+type Product struct {
+	ID string
+
+	// Reviews of the product, and the last time one of them was modified, recorded along with the summary
+	// to find when it's outdated. A product without reviews has its summary deleted.
+	Reviews           int
+	ReviewsModifiedAt time.Time
+}
+
+// Store of review summaries.
+type Store interface {
+	// OutdatedReviewSummaries returns up to limit products whose review summary is missing or outdated.
+	OutdatedReviewSummaries(ctx context.Context, limit int) ([]Product, error)
+
+	// GetProductReviews gets a list of reviews, the most recent first.
+	GetProductReviews(ctx context.Context, params inventory.ProductReviewsParams) (*inventory.ProductReviewsResponse, error)
+
+	// SaveReviewSummary of a product, or deletes it if the product has no reviews.
+	SaveReviewSummary(ctx context.Context, p Product, summary string) error
+}
+
+// Job generates the review summaries periodically.
+type Job struct {
+	Store      Store
+	Summarizer Summarizer
+
+	// BatchSize is the maximum number of summaries generated by a run.
+	BatchSize int
+
+	// Interval between runs.
+	Interval time.Duration
+
+	Log *slog.Logger
+
+	initOnce sync.Once
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func (j *Job) init() {
+	j.initOnce.Do(func() {
+		j.stop = make(chan struct{})
+	})
+}
+
+// Run generates summaries until the context is canceled or Shutdown is called.
+// A run generating a full batch is followed by another without waiting, to catch up with a backlog.
+func (j *Job) Run(ctx context.Context) error {
+	j.init()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-j.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+	for {
+		n, err := j.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			j.Log.Error("cannot generate review summaries", slog.Any("error", err))
+		}
+		if err == nil && n == j.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops the job, canceling the summaries in progress, as they're generated again by the next run.
+func (j *Job) Shutdown(ctx context.Context) {
+	j.init()
+	j.stopOnce.Do(func() {
+		close(j.stop)
+	})
+}
+
+// RunOnce generates a batch of summaries, returning how many were generated or deleted.
+// A product failing to be summarized is logged and skipped, so it doesn't hold back the others.
+func (j *Job) RunOnce(ctx context.Context) (n int, err error) {
+	products, err := j.Store.OutdatedReviewSummaries(ctx, j.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range products {
+		var summary string
+		if p.Reviews > 0 {
+			if summary, err = j.summarize(ctx, p.ID); err != nil {
+				if ctx.Err() != nil {
+					return n, ctx.Err()
+				}
+				j.Log.Error("cannot summarize product reviews", slog.String("product", p.ID), slog.Any("error", err))
+				continue
+			}
+		}
+		if err := j.Store.SaveReviewSummary(ctx, p, summary); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (j *Job) summarize(ctx context.Context, productID string) (string, error) {
+	resp, err := j.Store.GetProductReviews(ctx, inventory.ProductReviewsParams{
+		ProductID:  productID,
+		Pagination: inventory.Pagination{Limit: MaxReviews},
+	})
+	if err != nil {
+		return "", err
+	}
+	summary, err := j.Summarizer.Summarize(ctx, productID, resp.Reviews)
+	if err != nil {
+		return "", fmt.Errorf("summarizer error: %w", err)
+	}
+	return summary, nil
+}
+
+// Scores summarizes reviews by their average score, with the title of the best review as a pro
+// and the title of the worst as a con.
+// It's deterministic: the same reviews always get the same summary.
+type Scores struct{}
+
+// Summarize the reviews of a product.
+func (Scores) Summarize(ctx context.Context, productID string, reviews []*inventory.ProductReview) (string, error) {
+	if len(reviews) == 0 {
+		return "", nil
+	}
+	var total int
+	for _, r := range reviews {
+		total += r.Score
+	}
+	// Ties are broken by ID, so the summary doesn't depend on the order of the reviews.
+	sorted := slices.Clone(reviews)
+	slices.SortFunc(sorted, func(a, b *inventory.ProductReview) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.ID, b.ID))
+	})
+	noun := "reviews"
+	if len(reviews) == 1 {
+		noun = "review"
+	}
+	summary := fmt.Sprintf("Rated %.1f out of 5 by %d %s.", float64(total)/float64(len(reviews)), len(reviews), noun)
+	if best := sorted[0]; best.Score >= 4 {
+		summary += fmt.Sprintf(" Pros: %s.", strings.TrimRight(best.Title, "."))
+	}
+	if worst := sorted[len(sorted)-1]; worst.Score <= 2 {
+		summary += fmt.Sprintf(" Cons: %s.", strings.TrimRight(worst.Title, "."))
+	}
+	return summary, nil
+}
@@ -0,0 +1,60 @@
+package summary_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/henvic/pgxtutorial/internal/inventory"
+	"github.com/henvic/pgxtutorial/internal/summary"
+)
+
+func TestScores(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		reviews []*inventory.ProductReview
+		want    string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "one",
+			reviews: []*inventory.ProductReview{
+				{ID: "a", Score: 5, Title: "Sturdy."},
+			},
+			want: "Rated 5.0 out of 5 by 1 review. Pros: Sturdy.",
+		},
+		{
+			name: "mixed",
+			reviews: []*inventory.ProductReview{
+				{ID: "c", Score: 1, Title: "Wobbly"},
+				{ID: "b", Score: 5, Title: "Sturdy"},
+				{ID: "a", Score: 5, Title: "Beautiful"},
+				{ID: "d", Score: 3, Title: "Fine"},
+			},
+			want: "Rated 3.5 out of 5 by 4 reviews. Pros: Beautiful. Cons: Wobbly.",
+		},
+		{
+			name: "average",
+			reviews: []*inventory.ProductReview{
+				{ID: "a", Score: 3, Title: "Fine"},
+				{ID: "b", Score: 2, Title: "Small"},
+				{ID: "c", Score: 2, Title: "Heavy"},
+			},
+			want: "Rated 2.3 out of 5 by 3 reviews. Cons: Heavy.",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := summary.Scores{}.Summarize(context.Background(), "product", tc.reviews)
+			if err != nil {
+				t.Errorf("Scores.Summarize() error = %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Scores.Summarize() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BaggageKeys are the OpenTelemetry baggage members extracted as request attributes by default.
+// They are meant for breakdowns by sales channel, A/B experiment, or tenant.
+var BaggageKeys = []string{"channel", "experiment", "tenant"}
+
+// requestAttributesKey is the context key of the request attributes.
+type requestAttributesKey struct{}
+
+// ContextWithBaggageAttributes extracts the given members of the baggage in the context as request attributes.
+// They are set on the current span and carried by the returned context, where RequestAttributes reads them from.
+//
+// Only known keys should be extracted, as baggage is set by the caller.
+func ContextWithBaggageAttributes(ctx context.Context, keys ...string) context.Context {
+	b := baggage.FromContext(ctx)
+	var attrs []attribute.KeyValue
+	for _, k := range keys {
+		if m := b.Member(k); m.Value() != "" {
+			attrs = append(attrs, attribute.String(k, m.Value()))
+		}
+	}
+	if len(attrs) == 0 {
+		return ctx
+	}
+	return contextWithRequestAttributes(ctx, attrs...)
+}
+
+// contextWithRequestAttributes sets the attributes on the current span,
+// and returns a context carrying them along with the request attributes already in ctx.
+func contextWithRequestAttributes(ctx context.Context, attrs ...attribute.KeyValue) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	return context.WithValue(ctx, requestAttributesKey{}, append(slices.Clip(RequestAttributes(ctx)), attrs...))
+}
+
+// RequestAttributes returns the request attributes carried by the context.
+func RequestAttributes(ctx context.Context) []attribute.KeyValue {
+	attrs, _ := ctx.Value(requestAttributesKey{}).([]attribute.KeyValue)
+	return attrs
+}
+
+// RequestLogAttrs returns the request attributes carried by the context as slog attributes.
+func RequestLogAttrs(ctx context.Context) []slog.Attr {
+	attrs := RequestAttributes(ctx)
+	if len(attrs) == 0 {
+		return nil
+	}
+	la := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		la = append(la, slog.String(string(a.Key), a.Value.Emit()))
+	}
+	return la
+}
@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestContextWithBaggageAttributes(t *testing.T) {
+	t.Parallel()
+	tenant, err := baggage.NewMember("tenant", "acme")
+	if err != nil {
+		t.Fatalf("baggage.NewMember() error = %v", err)
+	}
+	secret, err := baggage.NewMember("secret", "value")
+	if err != nil {
+		t.Fatalf("baggage.NewMember() error = %v", err)
+	}
+	b, err := baggage.New(tenant, secret)
+	if err != nil {
+		t.Fatalf("baggage.New() error = %v", err)
+	}
+
+	ctx := ContextWithBaggageAttributes(baggage.ContextWithBaggage(context.Background(), b), BaggageKeys...)
+	if want, got := []attribute.KeyValue{attribute.String("tenant", "acme")}, RequestAttributes(ctx); !slices.Equal(want, got) {
+		t.Errorf("RequestAttributes() = %v, want %v", got, want)
+	}
+	if want, got := []slog.Attr{slog.String("tenant", "acme")}, RequestLogAttrs(ctx); len(got) != 1 || !got[0].Equal(want[0]) {
+		t.Errorf("RequestLogAttrs() = %v, want %v", got, want)
+	}
+
+	if got := RequestAttributes(ContextWithBaggageAttributes(context.Background(), BaggageKeys...)); got != nil {
+		t.Errorf("RequestAttributes() = %v without baggage, want nil", got)
+	}
+}
@@ -0,0 +1,28 @@
+package telemetry
+
+import (
+	"context"
+	"net/netip"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// clientIPKey is the context key of the client IP address.
+type clientIPKey struct{}
+
+// ContextWithClientIP sets the IP address of the client of the request as the client.address request attribute.
+// The address should be resolved from the trusted proxies in front of the server, rather than be the address of the peer.
+// ClientIP reads it from the returned context.
+func ContextWithClientIP(ctx context.Context, ip netip.Addr) context.Context {
+	if !ip.IsValid() {
+		return ctx
+	}
+	ctx = contextWithRequestAttributes(ctx, attribute.String("client.address", ip.String()))
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the IP address of the client of the request carried by the context, if any.
+func ClientIP(ctx context.Context) (netip.Addr, bool) {
+	ip, ok := ctx.Value(clientIPKey{}).(netip.Addr)
+	return ip, ok
+}
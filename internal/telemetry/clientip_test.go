@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestContextWithClientIP(t *testing.T) {
+	t.Parallel()
+	if _, ok := ClientIP(context.Background()); ok {
+		t.Error("ClientIP() ok = true without client IP, want false")
+	}
+	if ctx := ContextWithClientIP(context.Background(), netip.Addr{}); RequestAttributes(ctx) != nil {
+		t.Errorf("RequestAttributes() = %v with invalid client IP, want nil", RequestAttributes(ctx))
+	}
+
+	tenant, err := baggage.NewMember("tenant", "acme")
+	if err != nil {
+		t.Fatalf("baggage.NewMember() error = %v", err)
+	}
+	b, err := baggage.New(tenant)
+	if err != nil {
+		t.Fatalf("baggage.New() error = %v", err)
+	}
+	ctx := ContextWithBaggageAttributes(baggage.ContextWithBaggage(context.Background(), b), BaggageKeys...)
+	ctx = ContextWithClientIP(ctx, netip.MustParseAddr("203.0.113.7"))
+	if ip, ok := ClientIP(ctx); !ok || ip != netip.MustParseAddr("203.0.113.7") {
+		t.Errorf("ClientIP() = %v, %v, want 203.0.113.7, true", ip, ok)
+	}
+	want := []attribute.KeyValue{
+		attribute.String("tenant", "acme"),
+		attribute.String("client.address", "203.0.113.7"),
+	}
+	if got := RequestAttributes(ctx); !slices.Equal(want, got) {
+		t.Errorf("RequestAttributes() = %v, want %v", got, want)
+	}
+}
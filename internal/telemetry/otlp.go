@@ -0,0 +1,172 @@
+//go:build !notelemetry
+
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// OTLPConfig configures the OTLP gRPC exporters of traces and metrics.
+type OTLPConfig struct {
+	// Endpoint of the collector, either as host:port or as a URL.
+	// The default is localhost:4317.
+	Endpoint string
+
+	// Headers sent with every export, such as authentication tokens.
+	Headers map[string]string
+
+	// Insecure disables TLS.
+	Insecure bool
+
+	// CACertificate is the path of a PEM file with the certificate authorities to verify the collector with.
+	// By default, the system certificate pool is used.
+	CACertificate string
+
+	// ClientCertificate and ClientKey are the paths of PEM files for mutual TLS.
+	ClientCertificate string
+	ClientKey         string
+
+	// Compression of exports: "gzip", or empty for none.
+	Compression string
+
+	// Timeout of each export.
+	Timeout time.Duration
+}
+
+// OTLPConfigFromEnv reads the OTLP configuration from the standard OpenTelemetry environment variables:
+// OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_HEADERS, OTEL_EXPORTER_OTLP_INSECURE,
+// OTEL_EXPORTER_OTLP_CERTIFICATE, OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE, OTEL_EXPORTER_OTLP_CLIENT_KEY,
+// OTEL_EXPORTER_OTLP_COMPRESSION, and OTEL_EXPORTER_OTLP_TIMEOUT (in milliseconds).
+//
+// Without an endpoint, TLS is disabled to export to a local collector, unless a certificate is set.
+// With an http:// endpoint URL, TLS is disabled too.
+func OTLPConfigFromEnv() (OTLPConfig, error) {
+	c := OTLPConfig{
+		Endpoint:          os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		CACertificate:     os.Getenv("OTEL_EXPORTER_OTLP_CERTIFICATE"),
+		ClientCertificate: os.Getenv("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE"),
+		ClientKey:         os.Getenv("OTEL_EXPORTER_OTLP_CLIENT_KEY"),
+		Compression:       os.Getenv("OTEL_EXPORTER_OTLP_COMPRESSION"),
+	}
+	c.Insecure = strings.HasPrefix(c.Endpoint, "http://") ||
+		c.Endpoint == "" && c.CACertificate == "" && c.ClientCertificate == ""
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_INSECURE"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_INSECURE: %w", err)
+		}
+		c.Insecure = b
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"); v != "" {
+		c.Headers = map[string]string{}
+		for _, h := range strings.Split(v, ",") {
+			k, v, ok := strings.Cut(h, "=")
+			if !ok {
+				return c, errors.New("invalid OTEL_EXPORTER_OTLP_HEADERS: headers must be in the key=value format")
+			}
+			// Values are URL encoded, so they might contain commas.
+			uv, err := url.PathUnescape(strings.TrimSpace(v))
+			if err != nil {
+				return c, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+			}
+			c.Headers[strings.TrimSpace(k)] = uv
+		}
+	}
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_TIMEOUT"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil {
+			return c, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_TIMEOUT: %w", err)
+		}
+		c.Timeout = time.Duration(ms) * time.Millisecond
+	}
+	switch c.Compression {
+	case "", "none":
+		c.Compression = ""
+	case "gzip":
+	default:
+		return c, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_COMPRESSION: unsupported compression %q", c.Compression)
+	}
+	return c, nil
+}
+
+// credentials for the gRPC connection to the collector.
+func (c OTLPConfig) credentials() (credentials.TransportCredentials, error) {
+	if c.Insecure {
+		return insecure.NewCredentials(), nil
+	}
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.CACertificate != "" {
+		pem, err := os.ReadFile(c.CACertificate)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA certificate: %w", err)
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("cannot parse CA certificate")
+		}
+	}
+	if c.ClientCertificate != "" || c.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCertificate, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(conf), nil
+}
+
+// NewOTLPExporters creates OTLP gRPC exporters of traces and metrics.
+func NewOTLPExporters(ctx context.Context, c OTLPConfig) (sdktrace.SpanExporter, sdkmetric.Exporter, error) {
+	creds, err := c.credentials()
+	if err != nil {
+		return nil, nil, err
+	}
+	traceOptions := []otlptracegrpc.Option{otlptracegrpc.WithTLSCredentials(creds)}
+	metricOptions := []otlpmetricgrpc.Option{otlpmetricgrpc.WithTLSCredentials(creds)}
+	switch {
+	case strings.Contains(c.Endpoint, "://"):
+		traceOptions = append(traceOptions, otlptracegrpc.WithEndpointURL(c.Endpoint))
+		metricOptions = append(metricOptions, otlpmetricgrpc.WithEndpointURL(c.Endpoint))
+	case c.Endpoint != "":
+		traceOptions = append(traceOptions, otlptracegrpc.WithEndpoint(c.Endpoint))
+		metricOptions = append(metricOptions, otlpmetricgrpc.WithEndpoint(c.Endpoint))
+	}
+	if c.Headers != nil {
+		traceOptions = append(traceOptions, otlptracegrpc.WithHeaders(c.Headers))
+		metricOptions = append(metricOptions, otlpmetricgrpc.WithHeaders(c.Headers))
+	}
+	if c.Compression != "" {
+		traceOptions = append(traceOptions, otlptracegrpc.WithCompressor(c.Compression))
+		metricOptions = append(metricOptions, otlpmetricgrpc.WithCompressor(c.Compression))
+	}
+	if c.Timeout != 0 {
+		traceOptions = append(traceOptions, otlptracegrpc.WithTimeout(c.Timeout))
+		metricOptions = append(metricOptions, otlpmetricgrpc.WithTimeout(c.Timeout))
+	}
+
+	tr, err := otlptracegrpc.New(ctx, traceOptions...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("otlptracegrpc: %w", err)
+	}
+	mt, err := otlpmetricgrpc.New(ctx, metricOptions...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("otlpmetricgrpc: %w", err)
+	}
+	return tr, mt, nil
+}
@@ -0,0 +1,120 @@
+//go:build !notelemetry
+
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOTLPConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    OTLPConfig
+		wantErr string
+	}{
+		{
+			name: "default",
+			want: OTLPConfig{Insecure: true},
+		},
+		{
+			name: "collector",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT":    "https://collector.example.com:4317",
+				"OTEL_EXPORTER_OTLP_HEADERS":     "authorization=Bearer%20token,x-tenant=acme",
+				"OTEL_EXPORTER_OTLP_COMPRESSION": "gzip",
+				"OTEL_EXPORTER_OTLP_TIMEOUT":     "2500",
+			},
+			want: OTLPConfig{
+				Endpoint: "https://collector.example.com:4317",
+				Headers: map[string]string{
+					"authorization": "Bearer token",
+					"x-tenant":      "acme",
+				},
+				Compression: "gzip",
+				Timeout:     2500 * time.Millisecond,
+			},
+		},
+		{
+			name: "http_endpoint",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4317",
+			},
+			want: OTLPConfig{
+				Endpoint: "http://collector:4317",
+				Insecure: true,
+			},
+		},
+		{
+			name: "ca_certificate",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_CERTIFICATE": "/etc/ssl/collector.pem",
+			},
+			want: OTLPConfig{
+				CACertificate: "/etc/ssl/collector.pem",
+			},
+		},
+		{
+			name: "insecure_override",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "collector:4317",
+				"OTEL_EXPORTER_OTLP_INSECURE": "true",
+			},
+			want: OTLPConfig{
+				Endpoint: "collector:4317",
+				Insecure: true,
+			},
+		},
+		{
+			name: "invalid_headers",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "authorization",
+			},
+			wantErr: "invalid OTEL_EXPORTER_OTLP_HEADERS: headers must be in the key=value format",
+		},
+		{
+			name: "invalid_compression",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_COMPRESSION": "zstd",
+			},
+			wantErr: `invalid OTEL_EXPORTER_OTLP_COMPRESSION: unsupported compression "zstd"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT",
+				"OTEL_EXPORTER_OTLP_HEADERS",
+				"OTEL_EXPORTER_OTLP_INSECURE",
+				"OTEL_EXPORTER_OTLP_CERTIFICATE",
+				"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE",
+				"OTEL_EXPORTER_OTLP_CLIENT_KEY",
+				"OTEL_EXPORTER_OTLP_COMPRESSION",
+				"OTEL_EXPORTER_OTLP_TIMEOUT",
+			} {
+				t.Setenv(k, tt.env[k])
+			}
+			got, err := OTLPConfigFromEnv()
+			if err == nil && tt.wantErr != "" || err != nil && err.Error() != tt.wantErr {
+				t.Errorf("OTLPConfigFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("OTLPConfigFromEnv() = %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestOTLPConfigCredentialsMissingCA(t *testing.T) {
+	t.Parallel()
+	c := OTLPConfig{CACertificate: "testdata/missing.pem"}
+	if _, err := c.credentials(); err == nil {
+		t.Error("OTLPConfig.credentials() should fail with a missing CA certificate")
+	}
+}
@@ -0,0 +1,59 @@
+//go:build !notelemetry
+
+package telemetry
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// NewResource creates an OpenTelemetry resource describing the entity producing telemetry.
+//
+// The given attributes, such as the service name and version, are merged with the ones detected from
+// the host, operating system, process, container, and Kubernetes environment.
+// Attributes from the OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME environment variables take precedence.
+//
+// If some detectors fail, it returns the partial resource along with an error wrapping resource.ErrPartialResource.
+func NewResource(ctx context.Context, attrs ...attribute.KeyValue) (*resource.Resource, error) {
+	return resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithOS(),
+		resource.WithProcess(),
+		resource.WithContainer(),
+		resource.WithDetectors(k8sDetector{}),
+		resource.WithAttributes(attrs...),
+		resource.WithFromEnv(),
+	)
+}
+
+// k8sDetector detects Kubernetes attributes exposed to the container as environment variables with the Downward API:
+//
+//	env:
+//	- name: K8S_POD_NAME
+//	  valueFrom:
+//	    fieldRef:
+//	      fieldPath: metadata.name
+//
+// K8S_NAMESPACE_NAME (metadata.namespace) and K8S_NODE_NAME (spec.nodeName) are read likewise.
+type k8sDetector struct{}
+
+// Detect Kubernetes attributes.
+func (k8sDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	var attrs []attribute.KeyValue
+	for env, key := range map[string]attribute.Key{
+		"K8S_POD_NAME":       semconv.K8SPodNameKey,
+		"K8S_NAMESPACE_NAME": semconv.K8SNamespaceNameKey,
+		"K8S_NODE_NAME":      semconv.K8SNodeNameKey,
+	} {
+		if v := os.Getenv(env); v != "" {
+			attrs = append(attrs, key.String(v))
+		}
+	}
+	// Schemaless, so it merges with resources from detectors using a different semantic conventions version.
+	return resource.NewSchemaless(attrs...), nil
+}
@@ -0,0 +1,45 @@
+//go:build !notelemetry
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+func TestNewResource(t *testing.T) {
+	t.Setenv("K8S_POD_NAME", "api-7d9c")
+	t.Setenv("K8S_NAMESPACE_NAME", "")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=staging,service.version=override")
+
+	res, err := NewResource(context.Background(),
+		semconv.ServiceName("api"),
+		semconv.ServiceVersion("v1.0.0"),
+	)
+	if err != nil && !errors.Is(err, resource.ErrPartialResource) {
+		t.Fatalf("NewResource() error = %v", err)
+	}
+
+	want := map[attribute.Key]string{
+		semconv.ServiceNameKey:    "api",
+		semconv.ServiceVersionKey: "override", // OTEL_RESOURCE_ATTRIBUTES takes precedence.
+		semconv.K8SPodNameKey:     "api-7d9c",
+		"deployment.environment":  "staging",
+	}
+	for k, v := range want {
+		if got, ok := res.Set().Value(k); !ok || got.AsString() != v {
+			t.Errorf("resource attribute %v = %q, want %q", k, got.AsString(), v)
+		}
+	}
+	if _, ok := res.Set().Value(semconv.K8SNamespaceNameKey); ok {
+		t.Errorf("resource attribute %v should not be set", semconv.K8SNamespaceNameKey)
+	}
+	if _, ok := res.Set().Value(semconv.HostNameKey); !ok {
+		t.Errorf("resource attribute %v should be detected", semconv.HostNameKey)
+	}
+}
@@ -4,12 +4,30 @@ import (
 	"log/slog"
 
 	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
 )
 
 // Provider for telemetry services.
-type Provider struct {
+// It's implemented by NewProvider, and by Noop for logging only.
+type Provider interface {
+	// Logger returns the slog logger.
+	Logger() *slog.Logger
+
+	// Tracer returns the OpenTelemetry tracer.
+	Tracer() trace.Tracer
+
+	// Meter returns the OpenTelemetry meter.
+	Meter() metric.Meter
+
+	// Propagator returns the OpenTelemetry propagator.
+	Propagator() propagation.TextMapPropagator
+}
+
+// provider of telemetry services.
+type provider struct {
 	log        *slog.Logger
 	tracer     trace.Tracer
 	meter      metric.Meter
@@ -17,8 +35,8 @@ type Provider struct {
 }
 
 // NewProvider creates a new telemetry provider.
-func NewProvider(log *slog.Logger, tracer trace.Tracer, meter metric.Meter, propagator propagation.TextMapPropagator) *Provider {
-	return &Provider{
+func NewProvider(log *slog.Logger, tracer trace.Tracer, meter metric.Meter, propagator propagation.TextMapPropagator) Provider {
+	return provider{
 		log:        log,
 		tracer:     tracer,
 		meter:      meter,
@@ -26,22 +44,26 @@ func NewProvider(log *slog.Logger, tracer trace.Tracer, meter metric.Meter, prop
 	}
 }
 
-// Logger returns the slog logger.
-func (p Provider) Logger() *slog.Logger {
+// Noop creates a telemetry provider that only logs, discarding traces and metrics.
+func Noop(log *slog.Logger) Provider {
+	return NewProvider(log,
+		tracenoop.NewTracerProvider().Tracer("noop"),
+		metricnoop.NewMeterProvider().Meter("noop"),
+		propagation.NewCompositeTextMapPropagator())
+}
+
+func (p provider) Logger() *slog.Logger {
 	return p.log
 }
 
-// Tracer returns the OpenTelemetry tracer.
-func (p Provider) Tracer() trace.Tracer {
+func (p provider) Tracer() trace.Tracer {
 	return p.tracer
 }
 
-// Meter returns the OpenTelemetry meter.
-func (p Provider) Meter() metric.Meter {
+func (p provider) Meter() metric.Meter {
 	return p.meter
 }
 
-// Propagator returns the OpenTelemetry propagator.
-func (p Provider) Propagator() propagation.TextMapPropagator {
+func (p provider) Propagator() propagation.TextMapPropagator {
 	return p.propagator
 }
@@ -55,3 +55,14 @@ func TestNewProvider(t *testing.T) {
 		t.Errorf("Expected Propagator() to be set correctly")
 	}
 }
+
+func TestNoop(t *testing.T) {
+	logger := slog.Default()
+	provider := Noop(logger)
+	if provider.Logger() != logger {
+		t.Errorf("Expected Logger() to return the correct logger")
+	}
+	if provider.Tracer() == nil || provider.Meter() == nil || provider.Propagator() == nil {
+		t.Errorf("Expected Tracer(), Meter(), and Propagator() to be set")
+	}
+}
@@ -9,22 +9,16 @@ import (
 
 	"github.com/henvic/pgxtutorial/internal/telemetry"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
-	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/propagation"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
-	tracenoop "go.opentelemetry.io/otel/trace/noop"
 )
 
 // Discard all telemetry.
-func Discard() *telemetry.Provider {
-	tracer := tracenoop.NewTracerProvider().Tracer("discard")
-	meter := metricnoop.NewMeterProvider().Meter("discard")
-	propagator := propagation.NewCompositeTextMapPropagator()
-	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
-	return telemetry.NewProvider(logger, tracer, meter, propagator)
+func Discard() telemetry.Provider {
+	return telemetry.Noop(slog.New(slog.NewJSONHandler(io.Discard, nil)))
 }
 
 // Record of the telemetry.
@@ -61,7 +55,7 @@ func (mem *Memory) Log() string {
 }
 
 // Provider for telemetrytest.
-func Provider() (provider *telemetry.Provider, mem *Memory) {
+func Provider() (provider telemetry.Provider, mem *Memory) {
 	mem = &Memory{}
 	mem.trace = tracetest.NewInMemoryExporter()
 	logger := slog.New(slog.NewJSONHandler(&mem.log, nil))
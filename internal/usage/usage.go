@@ -0,0 +1,228 @@
+// Package usage accounts the requests and bytes of the callers of the API, by tenant and API key,
+// such as for enforcing quotas and billing.
+package usage
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults of the Meter.
+const (
+	DefaultInterval   = 10 * time.Second
+	DefaultMaxPending = 1000
+)
+
+// MaxQueryRange of a usage query.
+const MaxQueryRange = 93 * 24 * time.Hour
+
+// Record of the usage of a caller in an hour.
+type Record struct {
+	Tenant string
+	APIKey string
+
+	// Hour the usage is accounted to, in UTC.
+	Hour time.Time
+
+	Requests int64
+
+	// BytesIn and BytesOut of the bodies of the requests and responses.
+	BytesIn  int64
+	BytesOut int64
+}
+
+// Query of the usage of a tenant, or of all tenants if Tenant is empty, from From (inclusive) to To (exclusive).
+type Query struct {
+	Tenant string
+
+	// APIKey filters the usage of an API key, if set.
+	APIKey string
+
+	From time.Time
+	To   time.Time
+}
+
+// ErrInvalidQuery is returned by Query.Validate for a query with an invalid range.
+var ErrInvalidQuery = errors.New("invalid usage query: from must be before to, up to 93 days apart")
+
+// Validate the query.
+func (q Query) Validate() error {
+	if q.From.IsZero() || !q.From.Before(q.To) || q.To.Sub(q.From) > MaxQueryRange {
+		return ErrInvalidQuery
+	}
+	return nil
+}
+
+// Store of usage records.
+//
+//go:generate mockgen --build_flags=--mod=mod -package usage -destination mock_usage_test.go . Store
+type Store interface {
+	// AddUsage adds the counts of the records to the ones already stored for the same tenant, API key, and hour.
+	AddUsage(ctx context.Context, records []Record) error
+
+	// ListUsage returns the records matching the query, ordered by hour, tenant, and API key.
+	ListUsage(ctx context.Context, q Query) ([]Record, error)
+}
+
+// key of the records aggregated by a Meter.
+type key struct {
+	tenant string
+	apiKey string
+	hour   time.Time
+}
+
+// Meter aggregates the usage of the callers in memory, writing it to the Store in batches,
+// so accounting doesn't add a database write to every request.
+// Usage is written every Interval, or earlier once MaxPending records are pending, by Run.
+type Meter struct {
+	Store Store
+
+	// Interval between writes (default: DefaultInterval).
+	Interval time.Duration
+
+	// MaxPending records after which they're written before the Interval (default: DefaultMaxPending).
+	MaxPending int
+
+	Log *slog.Logger
+
+	mu      sync.Mutex
+	pending map[key]*Record
+
+	initOnce sync.Once
+	full     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// init the meter on its first use.
+func (m *Meter) init() {
+	m.initOnce.Do(func() {
+		m.pending = map[key]*Record{}
+		m.full = make(chan struct{}, 1)
+		m.stop = make(chan struct{})
+	})
+}
+
+// Record a request of a caller. Requests without a tenant or API key aren't accounted.
+func (m *Meter) Record(tenant, apiKey string, bytesIn, bytesOut int64) {
+	if tenant == "" && apiKey == "" {
+		return
+	}
+	m.init()
+	k := key{tenant: tenant, apiKey: apiKey, hour: time.Now().UTC().Truncate(time.Hour)}
+	m.mu.Lock()
+	r, ok := m.pending[k]
+	if !ok {
+		r = &Record{Tenant: tenant, APIKey: apiKey, Hour: k.hour}
+		m.pending[k] = r
+	}
+	r.Requests++
+	r.BytesIn += bytesIn
+	r.BytesOut += bytesOut
+	n := len(m.pending)
+	m.mu.Unlock()
+	if n >= m.maxPending() {
+		select {
+		case m.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (m *Meter) maxPending() int {
+	if m.MaxPending > 0 {
+		return m.MaxPending
+	}
+	return DefaultMaxPending
+}
+
+// Run writes the usage to the Store until Shutdown is called, writing what's pending before returning.
+func (m *Meter) Run(ctx context.Context) error {
+	m.init()
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.full:
+		case <-m.stop:
+			m.flush(ctx)
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+		m.flush(ctx)
+	}
+}
+
+// Shutdown stops accounting, once the pending usage is written.
+func (m *Meter) Shutdown(ctx context.Context) {
+	m.init()
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// flush the pending usage to the Store.
+// If it fails, the records are kept to be written along with the next ones.
+func (m *Meter) flush(ctx context.Context) {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = map[key]*Record{}
+	m.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+	records := make([]Record, 0, len(pending))
+	for _, r := range pending {
+		records = append(records, *r)
+	}
+	err := m.Store.AddUsage(ctx, records)
+	if err == nil {
+		return
+	}
+	m.Log.Error("cannot write usage", slog.Int("records", len(records)), slog.Any("error", err))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, r := range pending {
+		if p, ok := m.pending[k]; ok {
+			p.Requests += r.Requests
+			p.BytesIn += r.BytesIn
+			p.BytesOut += r.BytesOut
+			continue
+		}
+		m.pending[k] = r
+	}
+}
+
+// WriteCSV writes the records as CSV, with a header row.
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"tenant", "api_key", "hour", "requests", "bytes_in", "bytes_out"}); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := cw.Write([]string{
+			r.Tenant,
+			r.APIKey,
+			r.Hour.UTC().Format(time.RFC3339),
+			strconv.FormatInt(r.Requests, 10),
+			strconv.FormatInt(r.BytesIn, 10),
+			strconv.FormatInt(r.BytesOut, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
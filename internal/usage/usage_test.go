@@ -0,0 +1,203 @@
+package usage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/henvic/pgxtutorial/internal/usage"
+	"go.uber.org/mock/gomock"
+)
+
+// memoryStore of usage records, failing the writes while fail is set.
+type memoryStore struct {
+	mu      sync.Mutex
+	records []usage.Record
+	writes  int
+	fail    bool
+}
+
+func (s *memoryStore) AddUsage(ctx context.Context, records []usage.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("database unavailable")
+	}
+	s.writes++
+	for _, r := range records {
+		i := slices.IndexFunc(s.records, func(v usage.Record) bool {
+			return v.Tenant == r.Tenant && v.APIKey == r.APIKey && v.Hour.Equal(r.Hour)
+		})
+		if i == -1 {
+			s.records = append(s.records, r)
+			continue
+		}
+		s.records[i].Requests += r.Requests
+		s.records[i].BytesIn += r.BytesIn
+		s.records[i].BytesOut += r.BytesOut
+	}
+	return nil
+}
+
+func (s *memoryStore) ListUsage(ctx context.Context, q usage.Query) ([]usage.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.records), nil
+}
+
+// total of the records of a tenant.
+func (s *memoryStore) total(tenant string) (r usage.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range s.records {
+		if v.Tenant == tenant {
+			r.Requests += v.Requests
+			r.BytesIn += v.BytesIn
+			r.BytesOut += v.BytesOut
+		}
+	}
+	return r
+}
+
+func TestMeter(t *testing.T) {
+	t.Parallel()
+	store := &memoryStore{fail: true}
+	m := &usage.Meter{
+		Store:    store,
+		Interval: time.Hour,
+		Log:      slog.Default(),
+	}
+	done := make(chan error)
+	go func() {
+		done <- m.Run(context.Background())
+	}()
+	m.Record("acme", "key1", 10, 100)
+	m.Record("acme", "key1", 5, 50)
+	m.Record("acme", "key2", 0, 20)
+	m.Record("", "", 1, 1) // Anonymous requests aren't accounted.
+
+	// Records failing to be written are kept, and written on shutdown.
+	m.Shutdown(context.Background())
+	if err := <-done; err != nil {
+		t.Fatalf("Meter.Run() error = %v", err)
+	}
+	store.fail = false
+	go func() {
+		done <- m.Run(context.Background())
+	}()
+	if err := <-done; err != nil {
+		t.Fatalf("Meter.Run() error = %v", err)
+	}
+	if got := store.total("acme"); got.Requests != 3 || got.BytesIn != 15 || got.BytesOut != 170 {
+		t.Errorf("usage of acme = %+v, want 3 requests, 15 bytes in, 170 bytes out", got)
+	}
+	if len(store.records) != 2 {
+		t.Errorf("stored %d records, want 2", len(store.records))
+	}
+	for _, r := range store.records {
+		if !r.Hour.Equal(r.Hour.Truncate(time.Hour)) || r.Hour.Location() != time.UTC {
+			t.Errorf("record hour = %v, want an hour in UTC", r.Hour)
+		}
+	}
+}
+
+func TestMeterShutdown(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	store := usage.NewMockStore(ctrl)
+	// The pending records are written once, on shutdown.
+	store.EXPECT().AddUsage(gomock.Any(), gomock.Len(1)).Return(nil)
+	m := &usage.Meter{
+		Store:    store,
+		Interval: time.Hour,
+		Log:      slog.Default(),
+	}
+	done := make(chan error)
+	go func() {
+		done <- m.Run(context.Background())
+	}()
+	m.Record("acme", "key1", 1, 1)
+	m.Shutdown(context.Background())
+	if err := <-done; err != nil {
+		t.Fatalf("Meter.Run() error = %v", err)
+	}
+
+	// Nothing is written when no usage is pending.
+	go func() {
+		done <- m.Run(context.Background())
+	}()
+	if err := <-done; err != nil {
+		t.Fatalf("Meter.Run() error = %v", err)
+	}
+}
+
+func TestMeterMaxPending(t *testing.T) {
+	t.Parallel()
+	store := &memoryStore{}
+	m := &usage.Meter{
+		Store:      store,
+		Interval:   time.Hour,
+		MaxPending: 2,
+		Log:        slog.Default(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx) // #nosec G104
+	m.Record("acme", "key1", 1, 1)
+	m.Record("acme", "key2", 1, 1)
+	deadline := time.Now().Add(5 * time.Second)
+	for store.total("acme").Requests != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("pending records not written before the interval")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestQueryValidate(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	tests := []struct {
+		name string
+		q    usage.Query
+		want error
+	}{
+		{"valid", usage.Query{From: now.Add(-time.Hour), To: now}, nil},
+		{"no_range", usage.Query{Tenant: "acme"}, usage.ErrInvalidQuery},
+		{"reversed", usage.Query{From: now, To: now.Add(-time.Hour)}, usage.ErrInvalidQuery},
+		{"too_long", usage.Query{From: now.Add(-usage.MaxQueryRange - time.Hour), To: now}, usage.ErrInvalidQuery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.q.Validate(); err != tt.want {
+				t.Errorf("Query.Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	err := usage.WriteCSV(&buf, []usage.Record{
+		{Tenant: "acme", APIKey: "key,1", Hour: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), Requests: 3, BytesIn: 15, BytesOut: 170},
+	})
+	if err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := strings.Join([]string{
+		"tenant,api_key,hour,requests,bytes_in,bytes_out",
+		`acme,"key,1",2024-06-01T12:00:00Z,3,15,170`,
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV() = %q, want %q", got, want)
+	}
+}
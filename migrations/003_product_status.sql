@@ -0,0 +1,59 @@
+-- Write your migrate up statements here
+
+-- product_status is loaded and registered on pgx connections by database.RegisterTypes.
+CREATE TYPE product_status AS ENUM ('draft', 'active', 'discontinued');
+
+ALTER TABLE product ADD COLUMN status product_status NOT NULL DEFAULT 'active';
+
+-- product_search must expose the same columns as the product table, so it's recreated with the status column.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	p.status,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+
+ALTER TABLE product DROP COLUMN status;
+DROP TYPE product_status;
@@ -0,0 +1,18 @@
+-- Write your migrate up statements here
+
+-- audit_log records administrative operations, such as data deletion requests.
+CREATE TABLE audit_log (
+	id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	action text NOT NULL CHECK (action != ''),
+	subject text NOT NULL,
+	details jsonb NOT NULL DEFAULT '{}',
+	created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_log_subject ON audit_log(subject);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE audit_log;
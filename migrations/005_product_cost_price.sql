@@ -0,0 +1,11 @@
+-- Write your migrate up statements here
+
+-- cost_price is the price paid to the supplier.
+-- It's encrypted by the application (see postgres.Codec), so it cannot be used in queries.
+ALTER TABLE product ADD COLUMN cost_price bytea;
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+ALTER TABLE product DROP COLUMN cost_price;
@@ -0,0 +1,46 @@
+-- Write your migrate up statements here
+
+-- product_stock keeps the quantity available of each product.
+-- Units of pending orders are reserved until they're paid (and deducted from quantity) or canceled.
+CREATE TABLE product_stock (
+	product_id text PRIMARY KEY REFERENCES product(id) ON DELETE CASCADE,
+	quantity int NOT NULL CHECK (quantity >= 0),
+	reserved int NOT NULL DEFAULT 0 CHECK (reserved >= 0 AND reserved <= quantity),
+	modified_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- orders placed by the orders.Service saga (see internal/orders).
+-- product_id isn't a foreign key so orders are kept when a product is deleted.
+CREATE TABLE orders (
+	id text PRIMARY KEY CHECK (id != ''),
+	product_id text NOT NULL,
+	quantity int NOT NULL CHECK (quantity > 0),
+	amount int NOT NULL CHECK (amount >= 0),
+	status text NOT NULL CHECK (status IN ('pending', 'paid', 'canceled')),
+	payment_id text NOT NULL DEFAULT '',
+	created_at timestamp with time zone NOT NULL DEFAULT now(),
+	modified_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX orders_product_id ON orders(product_id);
+CREATE INDEX orders_pending ON orders(created_at) WHERE status = 'pending';
+
+-- outbox of events written in the same transaction as the changes they describe.
+-- A relay publishes them, setting published_at.
+CREATE TABLE outbox (
+	id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	topic text NOT NULL CHECK (topic != ''),
+	payload jsonb NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT now(),
+	published_at timestamp with time zone
+);
+
+CREATE INDEX outbox_unpublished ON outbox(id) WHERE published_at IS NULL;
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE outbox;
+DROP TABLE orders;
+DROP TABLE product_stock;
@@ -0,0 +1,19 @@
+-- Write your migrate up statements here
+
+-- favorite products of users.
+CREATE TABLE favorite (
+	user_id text NOT NULL CHECK (user_id != ''),
+	product_id text NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, product_id)
+);
+
+-- favorite_product is used to count the favorites of a product.
+CREATE INDEX favorite_product ON favorite(product_id);
+CREATE INDEX favorite_user_created_at ON favorite(user_id, created_at DESC);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE favorite;
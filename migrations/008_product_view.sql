@@ -0,0 +1,21 @@
+-- Write your migrate up statements here
+
+-- product_view counts the views of each product per day, used to list trending products.
+CREATE TABLE product_view (
+	product_id text NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+	day date NOT NULL DEFAULT current_date,
+	views bigint NOT NULL DEFAULT 0 CHECK (views >= 0),
+	PRIMARY KEY (product_id, day)
+);
+
+CREATE INDEX product_view_day ON product_view(day) INCLUDE (product_id, views);
+
+-- product_recent is used to list the most recently added active products.
+CREATE INDEX product_recent ON product(created_at DESC, id) WHERE status = 'active';
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP INDEX product_recent;
+DROP TABLE product_view;
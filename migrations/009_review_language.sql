@@ -0,0 +1,12 @@
+-- Write your migrate up statements here
+
+-- language is the ISO 639-1 code of the language of the review, or empty if unknown.
+ALTER TABLE review ADD COLUMN language text NOT NULL DEFAULT '';
+
+CREATE INDEX review_product_language ON review(product_id, language);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+ALTER TABLE review DROP COLUMN language;
@@ -0,0 +1,10 @@
+-- Write your migrate up statements here
+
+-- flagged is true if the review was flagged for moderation by the content filter.
+ALTER TABLE review ADD COLUMN flagged boolean NOT NULL DEFAULT false;
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+ALTER TABLE review DROP COLUMN flagged;
@@ -0,0 +1,10 @@
+-- Write your migrate up statements here
+
+-- Used to list the reviews of a reviewer, and to count their recent reviews for the review quota.
+CREATE INDEX review_reviewer_created_at ON review(reviewer_id, created_at);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP INDEX review_reviewer_created_at;
@@ -0,0 +1,17 @@
+-- Write your migrate up statements here
+
+-- review_attachment keeps the URLs of files attached to reviews, such as photos of the product, in order.
+CREATE TABLE review_attachment (
+	id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	review_id text NOT NULL REFERENCES review(id) ON DELETE CASCADE,
+	url text NOT NULL CHECK (url <> ''),
+	content_type text NOT NULL
+);
+
+CREATE INDEX review_attachment_review_id ON review_attachment(review_id);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE review_attachment;
@@ -0,0 +1,18 @@
+-- Write your migrate up statements here
+
+-- product_i18n keeps the translations of the name and description of products, by BCP 47 language tag (locale).
+CREATE TABLE product_i18n (
+	product_id text NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+	locale text NOT NULL CHECK (locale <> ''),
+	name text NOT NULL CHECK (name <> ''),
+	description text NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT now(),
+	modified_at timestamp with time zone NOT NULL DEFAULT now(),
+	PRIMARY KEY (product_id, locale)
+);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE product_i18n;
@@ -0,0 +1,108 @@
+-- Write your migrate up statements here
+
+-- slug is the URL-safe form of the product name, unique across products, such as "cafe-com-leite" for "Café com leite".
+ALTER TABLE product ADD COLUMN slug text;
+
+-- product_slug_redirect keeps the previous slugs of products, so old URLs redirect to the current one.
+CREATE TABLE product_slug_redirect (
+	slug text PRIMARY KEY,
+	product_id text NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX product_slug_redirect_product_id ON product_slug_redirect(product_id);
+
+-- product_slug returns the base slug of a product name: lowercase ASCII letters and digits separated by hyphens.
+-- Accents are removed from common Latin letters, and other characters are treated as separators.
+CREATE FUNCTION product_slug(name text) RETURNS text AS $$
+	SELECT COALESCE(NULLIF(trim(BOTH '-' FROM left(regexp_replace(
+		lower(translate(name, 'áàâãäåāÁÀÂÃÄÅĀçćčÇĆČéèêëēęÉÈÊËĒĘíìîïīÍÌÎÏĪłŁñńÑŃóòôõöøōÓÒÔÕÖØŌśšŚŠúùûüūÚÙÛÜŪýÿÝŸźżžŹŻŽ', 'aaaaaaaaaaaaaacccccceeeeeeeeeeeeiiiiiiiiiillnnnnoooooooooooooossssuuuuuuuuuuyyyyzzzzzz')),
+		'[^a-z0-9]+', '-', 'g'), 80)), ''), 'product')
+$$ LANGUAGE sql IMMUTABLE;
+
+-- product_free_slug returns the base slug, or the first one suffixed by a number (such as chair-2),
+-- that isn't used by another product, currently or as a previous slug.
+CREATE FUNCTION product_free_slug(base text, owner text) RETURNS text AS $$
+DECLARE
+	candidate text := base;
+	n int := 1;
+BEGIN
+	WHILE EXISTS (SELECT 1 FROM product p WHERE p.slug = candidate AND p.id <> owner)
+		OR EXISTS (SELECT 1 FROM product_slug_redirect r WHERE r.slug = candidate AND r.product_id <> owner) LOOP
+		n := n + 1;
+		candidate := base || '-' || n;
+	END LOOP;
+	RETURN candidate;
+END
+$$ LANGUAGE plpgsql STABLE;
+
+-- Each product is updated by a separate statement, so it sees the slugs taken by the previous ones.
+DO $$
+DECLARE
+	p record;
+BEGIN
+	FOR p IN SELECT id, name FROM product ORDER BY created_at, id LOOP
+		UPDATE product SET slug = product_free_slug(product_slug(p.name), p.id) WHERE id = p.id;
+	END LOOP;
+END
+$$;
+
+ALTER TABLE product ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE product ADD CONSTRAINT product_slug_key UNIQUE (slug);
+
+-- product_search must expose the same columns as the product table, so it's recreated with the slug column.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	p.status,
+	p.slug,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	p.status,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+
+DROP FUNCTION product_free_slug;
+DROP FUNCTION product_slug;
+DROP TABLE product_slug_redirect;
+ALTER TABLE product DROP COLUMN slug;
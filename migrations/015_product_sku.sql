@@ -0,0 +1,71 @@
+-- Write your migrate up statements here
+
+-- sku (stock keeping unit) and gtin (barcode number, such as an EAN-13) are optional, and unique across products.
+-- A GTIN is unique regardless of its format, as a UPC-A is the same as the EAN-13 with a leading zero.
+ALTER TABLE product ADD COLUMN sku text CHECK (sku ~ '^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$');
+ALTER TABLE product ADD COLUMN gtin text CHECK (gtin ~ '^([0-9]{8}|[0-9]{12,14})$');
+
+ALTER TABLE product ADD CONSTRAINT product_sku_key UNIQUE (sku);
+CREATE UNIQUE INDEX product_gtin_key ON product(lpad(gtin, 14, '0'));
+
+-- product_sku_prefix is used for searching products by the beginning of their SKU.
+CREATE INDEX product_sku_prefix ON product(sku text_pattern_ops);
+
+-- product_search is recreated with the sku and gtin columns.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	p.status,
+	p.slug,
+	p.sku,
+	p.gtin,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+CREATE INDEX product_search_sku ON product_search(sku text_pattern_ops);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	p.status,
+	p.slug,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+
+ALTER TABLE product DROP COLUMN gtin;
+ALTER TABLE product DROP COLUMN sku;
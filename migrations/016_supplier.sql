@@ -0,0 +1,32 @@
+-- Write your migrate up statements here
+
+-- supplier of products.
+CREATE TABLE supplier (
+	id text PRIMARY KEY CHECK (id <> ''),
+	name text NOT NULL CHECK (name <> ''),
+	email text NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT now(),
+	modified_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- product_supplier relates products to their suppliers, with the lead time and cost of ordering from each.
+CREATE TABLE product_supplier (
+	product_id text NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+	supplier_id text NOT NULL REFERENCES supplier(id) ON DELETE CASCADE,
+	lead_time_days int NOT NULL CHECK (lead_time_days >= 0),
+	cost int NOT NULL CHECK (cost >= 0),
+	modified_at timestamp with time zone NOT NULL DEFAULT now(),
+	PRIMARY KEY (product_id, supplier_id)
+);
+
+COMMENT ON COLUMN product_supplier.cost IS 'cost in the smaller subdivision possible (such as cents)';
+
+-- product_supplier_supplier is used to list the products of a supplier.
+CREATE INDEX product_supplier_supplier ON product_supplier(supplier_id, product_id);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE product_supplier;
+DROP TABLE supplier;
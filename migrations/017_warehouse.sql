@@ -0,0 +1,43 @@
+-- Write your migrate up statements here
+
+-- warehouse where products are stocked.
+CREATE TABLE warehouse (
+	id text PRIMARY KEY CHECK (id <> ''),
+	name text NOT NULL CHECK (name <> ''),
+	created_at timestamp with time zone NOT NULL DEFAULT now(),
+	modified_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- Existing stock is moved to the main warehouse.
+INSERT INTO warehouse (id, name) VALUES ('main', 'Main');
+
+ALTER TABLE product_stock ADD COLUMN warehouse_id text NOT NULL DEFAULT 'main' REFERENCES warehouse(id);
+ALTER TABLE product_stock ALTER COLUMN warehouse_id DROP DEFAULT;
+ALTER TABLE product_stock DROP CONSTRAINT product_stock_pkey;
+ALTER TABLE product_stock ADD PRIMARY KEY (product_id, warehouse_id);
+
+-- product_stock_warehouse is used to check if a warehouse has stock.
+CREATE INDEX product_stock_warehouse ON product_stock(warehouse_id);
+
+-- orders reserve their units from a single warehouse.
+ALTER TABLE orders ADD COLUMN warehouse_id text NOT NULL DEFAULT 'main';
+ALTER TABLE orders ALTER COLUMN warehouse_id DROP DEFAULT;
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+
+-- Stock is merged back into a single row per product.
+CREATE TEMPORARY TABLE product_stock_total ON COMMIT DROP AS
+	SELECT product_id, sum(quantity)::int AS quantity, sum(reserved)::int AS reserved, max(modified_at) AS modified_at
+	FROM product_stock GROUP BY product_id;
+DELETE FROM product_stock;
+ALTER TABLE product_stock DROP CONSTRAINT product_stock_pkey;
+ALTER TABLE product_stock DROP COLUMN warehouse_id;
+INSERT INTO product_stock (product_id, quantity, reserved, modified_at)
+	SELECT product_id, quantity, reserved, modified_at FROM product_stock_total;
+ALTER TABLE product_stock ADD PRIMARY KEY (product_id);
+
+ALTER TABLE orders DROP COLUMN warehouse_id;
+DROP TABLE warehouse;
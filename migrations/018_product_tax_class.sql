@@ -0,0 +1,66 @@
+-- Write your migrate up statements here
+
+-- tax_class of the product, used to compute its taxes. Products without one have the default tax class.
+ALTER TABLE product ADD COLUMN tax_class text CHECK (tax_class ~ '^[a-z0-9_-]{1,32}$');
+
+-- product_search is recreated with the tax_class column.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	p.status,
+	p.slug,
+	p.sku,
+	p.gtin,
+	p.tax_class,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+CREATE INDEX product_search_sku ON product_search(sku text_pattern_ops);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	p.status,
+	p.slug,
+	p.sku,
+	p.gtin,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+CREATE INDEX product_search_sku ON product_search(sku text_pattern_ops);
+
+ALTER TABLE product DROP COLUMN tax_class;
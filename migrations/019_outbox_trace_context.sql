@@ -0,0 +1,11 @@
+-- Write your migrate up statements here
+
+-- trace_context of the request that enqueued the event, such as its W3C traceparent header,
+-- so the span publishing the event can link to its trace.
+ALTER TABLE outbox ADD COLUMN trace_context jsonb;
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+ALTER TABLE outbox DROP COLUMN trace_context;
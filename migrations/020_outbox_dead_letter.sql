@@ -0,0 +1,23 @@
+-- Write your migrate up statements here
+
+-- attempts to publish the event that failed, and the error of the last one.
+-- Events failing too many times are moved to the dead letter queue by setting dead_lettered_at,
+-- so they stop blocking the events after them until they're replayed.
+ALTER TABLE outbox ADD COLUMN attempts int NOT NULL DEFAULT 0 CHECK (attempts >= 0);
+ALTER TABLE outbox ADD COLUMN last_error text;
+ALTER TABLE outbox ADD COLUMN dead_lettered_at timestamp with time zone;
+
+DROP INDEX outbox_unpublished;
+CREATE INDEX outbox_unpublished ON outbox(id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
+CREATE INDEX outbox_dead_lettered ON outbox(id) WHERE dead_lettered_at IS NOT NULL;
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP INDEX outbox_dead_lettered;
+DROP INDEX outbox_unpublished;
+CREATE INDEX outbox_unpublished ON outbox(id) WHERE published_at IS NULL;
+ALTER TABLE outbox DROP COLUMN dead_lettered_at;
+ALTER TABLE outbox DROP COLUMN last_error;
+ALTER TABLE outbox DROP COLUMN attempts;
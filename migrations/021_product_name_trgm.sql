@@ -0,0 +1,14 @@
+-- Write your migrate up statements here
+
+-- product_name_trgm is used for searching products by a substring of their name, such as with "name" LIKE '%oak%',
+-- which the product_name index can't be used for, as it only works for prefixes.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX product_name_trgm ON product USING gin(name gin_trgm_ops);
+CREATE INDEX product_search_name_trgm ON product_search USING gin(name gin_trgm_ops);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP INDEX product_search_name_trgm;
+DROP INDEX product_name_trgm;
@@ -0,0 +1,18 @@
+-- Write your migrate up statements here
+
+-- review_summary of the reviews of a product, such as their pros and cons, generated in the background.
+-- reviews and reviews_modified_at are the count and the latest modification of the reviews summarized,
+-- used to find the summaries outdated by reviews created, modified, or deleted since then.
+CREATE TABLE review_summary (
+	product_id text PRIMARY KEY REFERENCES product(id) ON DELETE CASCADE,
+	summary text NOT NULL,
+	reviews int NOT NULL CHECK (reviews > 0),
+	reviews_modified_at timestamp with time zone NOT NULL,
+	generated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE review_summary;
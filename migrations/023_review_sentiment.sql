@@ -0,0 +1,14 @@
+-- Write your migrate up statements here
+
+-- sentiment of the review, from -1 (negative) to 1 (positive), or NULL if it wasn't scored.
+ALTER TABLE review ADD COLUMN sentiment double precision CHECK (sentiment BETWEEN -1 AND 1);
+
+-- review_product_sentiment is used for listing the reviews of a product by sentiment.
+CREATE INDEX review_product_sentiment ON review(product_id, sentiment);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP INDEX review_product_sentiment;
+ALTER TABLE review DROP COLUMN sentiment;
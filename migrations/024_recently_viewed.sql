@@ -0,0 +1,23 @@
+-- Write your migrate up statements here
+
+-- recently_viewed products of users, bounded to the most recent ones of each user.
+CREATE TABLE recently_viewed (
+	user_id text NOT NULL CHECK (user_id != ''),
+	product_id text NOT NULL REFERENCES product(id) ON DELETE CASCADE,
+	viewed_at timestamp with time zone NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, product_id)
+);
+
+CREATE INDEX recently_viewed_user_viewed_at ON recently_viewed(user_id, viewed_at DESC);
+
+-- recently_viewed_viewed_at is used to prune the views older than the retention period.
+CREATE INDEX recently_viewed_viewed_at ON recently_viewed(viewed_at);
+
+-- recently_viewed_product is used to delete the views of a product along with it.
+CREATE INDEX recently_viewed_product ON recently_viewed(product_id);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE recently_viewed;
@@ -0,0 +1,13 @@
+-- Write your migrate up statements here
+
+-- Keep in sync with inventory.MaxReviewTitleLength and inventory.MaxReviewDescriptionLength.
+-- NOT VALID skips checking the existing reviews, so adding the constraints doesn't lock the table for long.
+ALTER TABLE review ADD CONSTRAINT review_title_length CHECK (char_length(title) <= 200) NOT VALID;
+ALTER TABLE review ADD CONSTRAINT review_description_length CHECK (char_length(description) <= 10000) NOT VALID;
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+ALTER TABLE review DROP CONSTRAINT review_description_length;
+ALTER TABLE review DROP CONSTRAINT review_title_length;
@@ -0,0 +1,66 @@
+-- Write your migrate up statements here
+
+-- modified_at is maintained by triggers rather than only by the queries of the application,
+-- so every write path keeps it, including COPY, upserts, and changes made with external tools.
+-- The queries still set it while MinSchemaVersion is older than this migration, as the triggers override it anyway.
+--
+-- Restoring a data-only dump fires the triggers too, so dump it with --disable-triggers to keep the original times.
+
+-- set_modified_at sets modified_at to the time of the transaction when a row is updated.
+CREATE FUNCTION set_modified_at() RETURNS trigger AS $$
+BEGIN
+	NEW.modified_at := now();
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- keep_created_at starts modified_at from created_at when a row is inserted, so a row is unmodified while both are equal,
+-- and keeps created_at when a row is updated.
+CREATE FUNCTION keep_created_at() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		NEW.modified_at := NEW.created_at;
+	ELSE
+		NEW.created_at := OLD.created_at;
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_created_at BEFORE INSERT OR UPDATE ON product FOR EACH ROW EXECUTE FUNCTION keep_created_at();
+CREATE TRIGGER product_modified_at BEFORE UPDATE ON product FOR EACH ROW EXECUTE FUNCTION set_modified_at();
+CREATE TRIGGER review_created_at BEFORE INSERT OR UPDATE ON review FOR EACH ROW EXECUTE FUNCTION keep_created_at();
+CREATE TRIGGER review_modified_at BEFORE UPDATE ON review FOR EACH ROW EXECUTE FUNCTION set_modified_at();
+CREATE TRIGGER product_i18n_created_at BEFORE INSERT OR UPDATE ON product_i18n FOR EACH ROW EXECUTE FUNCTION keep_created_at();
+CREATE TRIGGER product_i18n_modified_at BEFORE UPDATE ON product_i18n FOR EACH ROW EXECUTE FUNCTION set_modified_at();
+CREATE TRIGGER orders_created_at BEFORE INSERT OR UPDATE ON orders FOR EACH ROW EXECUTE FUNCTION keep_created_at();
+CREATE TRIGGER orders_modified_at BEFORE UPDATE ON orders FOR EACH ROW EXECUTE FUNCTION set_modified_at();
+CREATE TRIGGER supplier_created_at BEFORE INSERT OR UPDATE ON supplier FOR EACH ROW EXECUTE FUNCTION keep_created_at();
+CREATE TRIGGER supplier_modified_at BEFORE UPDATE ON supplier FOR EACH ROW EXECUTE FUNCTION set_modified_at();
+CREATE TRIGGER warehouse_created_at BEFORE INSERT OR UPDATE ON warehouse FOR EACH ROW EXECUTE FUNCTION keep_created_at();
+CREATE TRIGGER warehouse_modified_at BEFORE UPDATE ON warehouse FOR EACH ROW EXECUTE FUNCTION set_modified_at();
+
+-- product_stock and product_supplier have no created_at.
+CREATE TRIGGER product_stock_modified_at BEFORE UPDATE ON product_stock FOR EACH ROW EXECUTE FUNCTION set_modified_at();
+CREATE TRIGGER product_supplier_modified_at BEFORE UPDATE ON product_supplier FOR EACH ROW EXECUTE FUNCTION set_modified_at();
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TRIGGER product_supplier_modified_at ON product_supplier;
+DROP TRIGGER product_stock_modified_at ON product_stock;
+DROP TRIGGER warehouse_modified_at ON warehouse;
+DROP TRIGGER warehouse_created_at ON warehouse;
+DROP TRIGGER supplier_modified_at ON supplier;
+DROP TRIGGER supplier_created_at ON supplier;
+DROP TRIGGER orders_modified_at ON orders;
+DROP TRIGGER orders_created_at ON orders;
+DROP TRIGGER product_i18n_modified_at ON product_i18n;
+DROP TRIGGER product_i18n_created_at ON product_i18n;
+DROP TRIGGER review_modified_at ON review;
+DROP TRIGGER review_created_at ON review;
+DROP TRIGGER product_modified_at ON product;
+DROP TRIGGER product_created_at ON product;
+DROP FUNCTION keep_created_at();
+DROP FUNCTION set_modified_at();
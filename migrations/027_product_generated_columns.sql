@@ -0,0 +1,95 @@
+-- Write your migrate up statements here
+
+-- Generated columns are computed from the other columns of the row when it's written, so no write path can forget them.
+-- Adding a stored generated column rewrites the table, locking it while the existing rows are computed.
+
+-- search is the document matched by the full-text search of products: the words of the name, ranked above the ones of
+-- the description. The 'simple' configuration doesn't stem words, as products have names in different languages.
+ALTER TABLE product ADD COLUMN search tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', name), 'A') || setweight(to_tsvector('simple', description), 'B')
+) STORED;
+
+-- price_band groups products by price, to count the products of a search by price range without computing it from the
+-- price of each product matched.
+-- Keep in sync with inventory.PriceBandLimits.
+ALTER TABLE product ADD COLUMN price_band smallint GENERATED ALWAYS AS (
+	CASE
+		WHEN price < 1000 THEN 0
+		WHEN price < 5000 THEN 1
+		WHEN price < 10000 THEN 2
+		WHEN price < 50000 THEN 3
+		ELSE 4
+	END
+) STORED;
+
+CREATE INDEX product_search_document ON product USING gin(search);
+
+-- product_search is recreated with the search and price_band columns.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	p.status,
+	p.slug,
+	p.sku,
+	p.gtin,
+	p.tax_class,
+	p.search,
+	p.price_band,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+CREATE INDEX product_search_sku ON product_search(sku text_pattern_ops);
+CREATE INDEX product_search_name_trgm ON product_search USING gin(name gin_trgm_ops);
+CREATE INDEX product_search_search ON product_search USING gin(search);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP MATERIALIZED VIEW product_search;
+CREATE MATERIALIZED VIEW product_search AS
+SELECT
+	p.id,
+	p.name,
+	p.description,
+	p.price,
+	p.created_at,
+	p.modified_at,
+	p.status,
+	p.slug,
+	p.sku,
+	p.gtin,
+	p.tax_class,
+	COALESCE(r.review_count, 0) AS review_count,
+	COALESCE(r.average_score, 0) AS average_score
+FROM product p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS review_count, AVG(score)::double precision AS average_score
+	FROM review
+	GROUP BY product_id
+) r ON r.product_id = p.id;
+
+CREATE UNIQUE INDEX product_search_id ON product_search(id);
+CREATE INDEX product_search_name ON product_search(name text_pattern_ops);
+CREATE INDEX product_search_price ON product_search(price);
+CREATE INDEX product_search_sku ON product_search(sku text_pattern_ops);
+CREATE INDEX product_search_name_trgm ON product_search USING gin(name gin_trgm_ops);
+
+DROP INDEX product_search_document;
+ALTER TABLE product DROP COLUMN price_band;
+ALTER TABLE product DROP COLUMN search;
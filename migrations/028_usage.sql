@@ -0,0 +1,23 @@
+-- Write your migrate up statements here
+
+-- usage of the API by tenant and API key, aggregated by hour, for enforcing quotas and billing.
+-- An empty tenant or api_key is a caller identified only by the other one.
+CREATE TABLE usage (
+	tenant text NOT NULL,
+	api_key text NOT NULL,
+	hour timestamp with time zone NOT NULL,
+	requests bigint NOT NULL DEFAULT 0 CHECK (requests >= 0),
+	bytes_in bigint NOT NULL DEFAULT 0 CHECK (bytes_in >= 0),
+	bytes_out bigint NOT NULL DEFAULT 0 CHECK (bytes_out >= 0),
+	PRIMARY KEY (tenant, api_key, hour),
+	CHECK (tenant != '' OR api_key != '')
+);
+
+-- usage_hour is used to query the usage of all tenants in a period.
+CREATE INDEX usage_hour ON usage(hour);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE usage;
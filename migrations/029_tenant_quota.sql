@@ -0,0 +1,15 @@
+-- Write your migrate up statements here
+
+-- tenant_quota of the products and reviews a tenant can create, overriding the default quota. Zero is unlimited.
+CREATE TABLE tenant_quota (
+	tenant text PRIMARY KEY CHECK (tenant != ''),
+	max_products integer NOT NULL CHECK (max_products >= 0),
+	max_reviews_per_product integer NOT NULL CHECK (max_reviews_per_product >= 0),
+	modified_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TABLE tenant_quota;
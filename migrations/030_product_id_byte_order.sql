@@ -0,0 +1,13 @@
+-- Write your migrate up statements here
+
+-- Products are ordered by ID byte by byte, regardless of the collation of the database,
+-- so the results of shards can be merged (see SearchProducts).
+CREATE INDEX product_id_byte_order ON product(id COLLATE "C");
+CREATE INDEX product_search_id_byte_order ON product_search(id COLLATE "C");
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP INDEX product_search_id_byte_order;
+DROP INDEX product_id_byte_order;
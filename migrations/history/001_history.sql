@@ -0,0 +1,59 @@
+-- Write your migrate up statements here
+
+-- Optional history tables keeping every version of products and reviews, maintained by triggers.
+-- They're an alternative to recording changes on the audit_log from the application, and are applied with:
+-- tern migrate -m ./migrations/history --version-table schema_version_history
+
+-- record_history inserts the new row (or the deleted one) on the <table>_history table.
+-- Encrypted columns are left out of the history.
+CREATE FUNCTION record_history() RETURNS trigger AS $$
+DECLARE
+	r record;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		r := OLD;
+	ELSE
+		r := NEW;
+	END IF;
+	EXECUTE format('INSERT INTO %I ("id", "operation", "data") VALUES ($1, $2, $3)', TG_TABLE_NAME || '_history')
+	USING r.id, TG_OP, to_jsonb(r) - 'cost_price';
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE product_history (
+	history_id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	id text NOT NULL,
+	operation text NOT NULL CHECK (operation IN ('INSERT', 'UPDATE', 'DELETE')),
+	data jsonb NOT NULL,
+	changed_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX product_history_id ON product_history(id, history_id);
+
+CREATE TABLE review_history (
+	history_id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	id text NOT NULL,
+	operation text NOT NULL CHECK (operation IN ('INSERT', 'UPDATE', 'DELETE')),
+	data jsonb NOT NULL,
+	changed_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX review_history_id ON review_history(id, history_id);
+CREATE INDEX review_history_reviewer_id ON review_history((data->>'reviewer_id'));
+
+CREATE TRIGGER product_history AFTER INSERT OR UPDATE OR DELETE ON product
+FOR EACH ROW EXECUTE FUNCTION record_history();
+
+CREATE TRIGGER review_history AFTER INSERT OR UPDATE OR DELETE ON review
+FOR EACH ROW EXECUTE FUNCTION record_history();
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+DROP TRIGGER review_history ON review;
+DROP TRIGGER product_history ON product;
+DROP TABLE review_history;
+DROP TABLE product_history;
+DROP FUNCTION record_history();
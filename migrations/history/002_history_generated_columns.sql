@@ -0,0 +1,36 @@
+-- Write your migrate up statements here
+
+-- record_history leaves the generated columns of products out of the history too, as they're derived from the others.
+CREATE OR REPLACE FUNCTION record_history() RETURNS trigger AS $$
+DECLARE
+	r record;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		r := OLD;
+	ELSE
+		r := NEW;
+	END IF;
+	EXECUTE format('INSERT INTO %I ("id", "operation", "data") VALUES ($1, $2, $3)', TG_TABLE_NAME || '_history')
+	USING r.id, TG_OP, to_jsonb(r) - 'cost_price' - 'search' - 'price_band';
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+---- create above / drop below ----
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
+CREATE OR REPLACE FUNCTION record_history() RETURNS trigger AS $$
+DECLARE
+	r record;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		r := OLD;
+	ELSE
+		r := NEW;
+	END IF;
+	EXECUTE format('INSERT INTO %I ("id", "operation", "data") VALUES ($1, $2, $3)', TG_TABLE_NAME || '_history')
+	USING r.id, TG_OP, to_jsonb(r) - 'cost_price';
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
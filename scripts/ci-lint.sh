@@ -10,6 +10,7 @@ source scripts/ci-lint-fmt.sh
 
 set -x
 go vet ./...
+go vet -tags notelemetry ./...
 staticcheck ./...
 gosec -quiet ./...
 # Run govulncheck only informationally for the time being.